@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/config"
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+)
+
+func main() {
+	var (
+		provider string
+		file     string
+		report   bool
+	)
+
+	flag.StringVar(&provider, "provider", "", "Settlement provider code")
+	flag.StringVar(&file, "file", "", "Path to the settlement CSV file")
+	flag.BoolVar(&report, "report", false, "Print the unreconciled items report")
+	flag.Parse()
+
+	if !report && (provider == "" || file == "") {
+		fmt.Println("Usage: reconcile -provider <code> -file <settlement.csv>")
+		fmt.Println("       reconcile -report")
+		fmt.Println("\nOptions:")
+		fmt.Println("  -provider string  Settlement provider code")
+		fmt.Println("  -file string      Path to the settlement CSV file")
+		fmt.Println("  -report           Print the unreconciled items report")
+		fmt.Println("\nExamples:")
+		fmt.Println("  reconcile -provider stripe -file stripe_2024_03_01.csv")
+		fmt.Println("  reconcile -report")
+		os.Exit(1)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Connect to database
+	db, err := database.New(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	repos := repository.NewRepositories(db)
+	settlementService := service.NewSettlementService(repos.Settlement)
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("Failed to read settlement file: %v", err)
+		}
+
+		imp, err := settlementService.ImportSettlement(ctx, provider, filepath.Base(file), data, nil)
+		if err != nil {
+			log.Fatalf("Failed to import settlement file: %v", err)
+		}
+
+		fmt.Printf("Imported %s (%d rows)\n", imp.FileName, imp.RowCount)
+		fmt.Printf("  Matched:          %d\n", imp.MatchedCount)
+		fmt.Printf("  Fee deducted:     %d\n", imp.FeeDeductedCount)
+		fmt.Printf("  Amount mismatch:  %d\n", imp.AmountMismatchCount)
+		fmt.Printf("  Unknown:          %d\n", imp.UnknownCount)
+		fmt.Printf("  Duplicate:        %d\n", imp.DuplicateCount)
+		fmt.Printf("  Missing payouts:  %d\n", imp.MissingPayoutCount)
+
+		for _, line := range imp.Lines {
+			fmt.Printf("  row %d %s: %s (gross %s, fee %s, variance %s)\n",
+				line.RowNumber, line.GatewayTransactionID, line.MatchStatus,
+				line.GrossAmount, line.FeeAmount, line.AmountVariance)
+		}
+	}
+
+	if report {
+		r, err := settlementService.GetUnreconciledReport(ctx)
+		if err != nil {
+			log.Fatalf("Failed to build unreconciled report: %v", err)
+		}
+
+		fmt.Printf("Unreconciled items as of %s\n", r.GeneratedAt.Format(time.RFC3339))
+		for status, count := range r.CountByStatus {
+			fmt.Printf("  %-20s %d\n", status, count)
+		}
+		fmt.Printf("  Total variance:      %s\n", r.TotalVariance)
+		fmt.Printf("  Total fees:          %s\n", r.TotalFees)
+
+		for _, p := range r.Payments {
+			gatewayID := ""
+			if p.GatewayTransactionID != nil {
+				gatewayID = *p.GatewayTransactionID
+			}
+			fmt.Printf("  payment %s %s %s %s %s: %s\n",
+				p.PaymentID, p.BookingReference, gatewayID, p.Amount, p.Currency, p.ReconciliationStatus)
+		}
+		for _, line := range r.UnknownLines {
+			fmt.Printf("  settlement line %s %s %s: %s\n",
+				line.ID, line.GatewayTransactionID, line.GrossAmount, line.MatchStatus)
+		}
+	}
+}
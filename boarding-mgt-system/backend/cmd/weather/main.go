@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/config"
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/ferryflow/boarding-mgt-system/internal/weather"
+)
+
+// The weather job checks the sailings leaving in the coming hours against
+// the forecasts and raises advisories for operators to review. It is meant
+// to run daily, e.g. from cron:
+//
+//	0 5 * * * weather -hours 72
+func main() {
+	var hours int
+
+	flag.IntVar(&hours, "hours", int(weather.DefaultHorizon/time.Hour), "How many hours ahead to check sailings")
+	flag.Parse()
+
+	if hours <= 0 {
+		log.Fatalf("-hours must be positive")
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	provider, err := weather.NewProvider(&cfg.Weather)
+	if err != nil {
+		log.Fatalf("Failed to load weather provider: %v", err)
+	}
+	if provider == nil {
+		log.Fatalf("WEATHER_PROVIDER is not set, nothing to check")
+	}
+
+	// Connect to database
+	db, err := database.New(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// Advisories are only raised here; delays and cancellations are made
+	// when operators review them through the API
+	repos := repository.NewRepositories(db)
+	weatherService := service.NewWeatherService(repos.Weather, repos.Schedule, provider, nil, nil)
+
+	from := time.Now()
+	result, err := weatherService.CheckForecasts(ctx, from, from.Add(time.Duration(hours)*time.Hour))
+	if err != nil {
+		log.Fatalf("Failed to check forecasts: %v", err)
+	}
+
+	fmt.Printf("Checked sailings leaving %s to %s\n", result.From.Format(time.RFC3339), result.Until.Format(time.RFC3339))
+	fmt.Printf("  Sailings checked:     %d\n", result.Checked)
+	fmt.Printf("  Vessel has no limits: %d\n", result.NoLimits)
+	fmt.Printf("  Advisories opened:    %d\n", result.Opened)
+	fmt.Printf("  Advisories updated:   %d\n", result.Updated)
+	fmt.Printf("  Advisories cleared:   %d\n", result.Cleared)
+	fmt.Printf("  Already reviewed:     %d\n", result.Reviewed)
+}
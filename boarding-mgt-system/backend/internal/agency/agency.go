@@ -0,0 +1,139 @@
+// Package agency works out travel agency commission, the credit an agency has
+// left to sell with and its periodic statements.
+package agency
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// CommissionRate returns the rate an agency earns on a fare. The most specific
+// rule wins: route and fare class, then route, then fare class, then the
+// agency's default rate.
+func CommissionRate(agency *models.Agency, rules []models.AgencyCommissionRule, routeID uuid.UUID, fareClass string) float64 {
+	best, bestScore := agency.DefaultCommissionRate, 0
+	for _, rule := range rules {
+		score := 0
+		if rule.RouteID != nil {
+			if *rule.RouteID != routeID {
+				continue
+			}
+			score += 2
+		}
+		if rule.FareClass != nil {
+			if *rule.FareClass != fareClass {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rule.CommissionRate, score
+		}
+	}
+	return best
+}
+
+// Commission returns the commission on a fare, rounded half up to the cent
+func Commission(fare money.Money, rate float64) money.Money {
+	return fare.Mul(rate, money.HalfUp)
+}
+
+// Available returns what an agency can still sell before reaching its credit
+// limit. Prepaid agencies have no limit and can spend their balance.
+func Available(agency *models.Agency) money.Money {
+	return agency.Balance.Add(agency.CreditLimit)
+}
+
+// CheckCredit rejects a charge that would take an agency past its credit limit
+func CheckCredit(agency *models.Agency, charge money.Money) error {
+	if !agency.IsActive {
+		return fmt.Errorf("agency %s is not active", agency.Code)
+	}
+
+	available := Available(agency)
+	if charge.Cmp(available) > 0 {
+		if agency.AccountType == "prepaid" {
+			return fmt.Errorf("agency %s has %s prepaid, %s is needed", agency.Code, available, charge)
+		}
+		return fmt.Errorf("agency %s credit limit exceeded: %s available, %s is needed", agency.Code, available, charge)
+	}
+	return nil
+}
+
+// Statement summarizes an agency's account movements over a period. Opening is
+// the balance at the start of the period; transactions must be ordered oldest
+// first.
+func Statement(agency *models.Agency, start, end time.Time, opening money.Money, transactions []models.AgencyTransaction) *models.AgencyStatement {
+	statement := &models.AgencyStatement{
+		AgencyID:       agency.ID,
+		AgencyCode:     agency.Code,
+		AgencyName:     agency.Name,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		OpeningBalance: opening,
+		ClosingBalance: opening,
+		Bookings:       []models.AgencyStatementLine{},
+		Transactions:   transactions,
+	}
+	if statement.Transactions == nil {
+		statement.Transactions = []models.AgencyTransaction{}
+	}
+
+	lines := map[uuid.UUID]int{}
+	for _, txn := range transactions {
+		statement.ClosingBalance = statement.ClosingBalance.Add(txn.Amount)
+
+		switch txn.TransactionType {
+		case "booking_charge":
+			statement.Sales = statement.Sales.Sub(txn.Amount)
+		case "refund":
+			statement.Refunds = statement.Refunds.Add(txn.Amount)
+		case "commission", "commission_reversal":
+			statement.Commission = statement.Commission.Add(txn.Amount)
+		case "top_up", "payment":
+			statement.Payments = statement.Payments.Add(txn.Amount)
+		default:
+			statement.Adjustments = statement.Adjustments.Add(txn.Amount)
+		}
+
+		if txn.BookingID == nil {
+			continue
+		}
+
+		i, ok := lines[*txn.BookingID]
+		if !ok {
+			line := models.AgencyStatementLine{BookingID: *txn.BookingID}
+			if txn.BookingReference != nil {
+				line.BookingReference = *txn.BookingReference
+			}
+			statement.Bookings = append(statement.Bookings, line)
+			i = len(statement.Bookings) - 1
+			lines[*txn.BookingID] = i
+		}
+
+		line := &statement.Bookings[i]
+		switch txn.TransactionType {
+		case "booking_charge":
+			line.Sales = line.Sales.Sub(txn.Amount)
+		case "refund":
+			line.Refunds = line.Refunds.Add(txn.Amount)
+		case "commission", "commission_reversal":
+			line.Commission = line.Commission.Add(txn.Amount)
+		}
+	}
+
+	return statement
+}
+
+// MonthBounds returns the start and end of a month given as YYYY-MM
+func MonthBounds(month string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid month %q, expected YYYY-MM", month)
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
@@ -0,0 +1,107 @@
+package agency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommissionRate(t *testing.T) {
+	routeID, otherRoute := uuid.New(), uuid.New()
+	child := "child"
+	agency := &models.Agency{DefaultCommissionRate: 0.05}
+	rules := []models.AgencyCommissionRule{
+		{FareClass: &child, CommissionRate: 0.03},
+		{RouteID: &routeID, CommissionRate: 0.08},
+		{RouteID: &routeID, FareClass: &child, CommissionRate: 0.06},
+	}
+
+	assert.Equal(t, 0.06, CommissionRate(agency, rules, routeID, "child"))
+	assert.Equal(t, 0.08, CommissionRate(agency, rules, routeID, "adult"))
+	assert.Equal(t, 0.03, CommissionRate(agency, rules, otherRoute, "child"))
+	assert.Equal(t, 0.05, CommissionRate(agency, rules, otherRoute, "adult"))
+	assert.Equal(t, 0.05, CommissionRate(agency, nil, routeID, "adult"))
+}
+
+func TestCommission(t *testing.T) {
+	assert.Equal(t, money.MustParse("3.68"), Commission(money.MustParse("45.95"), 0.08))
+	assert.Equal(t, money.MustParse("0.00"), Commission(money.MustParse("45.95"), 0))
+}
+
+func TestCheckCredit(t *testing.T) {
+	t.Run("Prepaid agency spends its balance", func(t *testing.T) {
+		agency := &models.Agency{Code: "SUN", AccountType: "prepaid", Balance: money.MustParse("100.00"), IsActive: true}
+
+		assert.NoError(t, CheckCredit(agency, money.MustParse("100.00")))
+		assert.Error(t, CheckCredit(agency, money.MustParse("100.01")))
+	})
+
+	t.Run("Credit agency can owe up to its limit", func(t *testing.T) {
+		agency := &models.Agency{
+			Code:        "SEA",
+			AccountType: "credit",
+			Balance:     money.MustParse("-450.00"),
+			CreditLimit: money.MustParse("500.00"),
+			IsActive:    true,
+		}
+
+		assert.Equal(t, money.MustParse("50.00"), Available(agency))
+		assert.NoError(t, CheckCredit(agency, money.MustParse("50.00")))
+		assert.Error(t, CheckCredit(agency, money.MustParse("50.01")))
+
+		// A lowered limit leaves the agency over it until it pays
+		agency.CreditLimit = money.MustParse("400.00")
+		assert.Error(t, CheckCredit(agency, money.MustParse("0.01")))
+	})
+
+	t.Run("Inactive agency cannot sell", func(t *testing.T) {
+		agency := &models.Agency{Code: "OLD", AccountType: "prepaid", Balance: money.MustParse("100.00")}
+		assert.Error(t, CheckCredit(agency, money.MustParse("1.00")))
+	})
+}
+
+func TestStatement(t *testing.T) {
+	agency := &models.Agency{ID: uuid.New(), Code: "SEA", Name: "Sea Travel"}
+	start, end, err := MonthBounds("2024-03")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), end)
+
+	first, second := uuid.New(), uuid.New()
+	firstRef, secondRef := "FFAAAA1111", "FFBBBB2222"
+	txn := func(kind string, bookingID *uuid.UUID, ref *string, amount string) models.AgencyTransaction {
+		return models.AgencyTransaction{TransactionType: kind, BookingID: bookingID, BookingReference: ref, Amount: money.MustParse(amount)}
+	}
+
+	statement := Statement(agency, start, end, money.MustParse("-100.00"), []models.AgencyTransaction{
+		txn("booking_charge", &first, &firstRef, "-200.00"),
+		txn("commission", &first, &firstRef, "16.00"),
+		txn("booking_charge", &second, &secondRef, "-50.00"),
+		txn("commission", &second, &secondRef, "4.00"),
+		txn("payment", nil, nil, "300.00"),
+		txn("refund", &second, &secondRef, "50.00"),
+		txn("commission_reversal", &second, &secondRef, "-4.00"),
+		txn("adjustment", nil, nil, "-1.50"),
+	})
+
+	assert.Equal(t, money.MustParse("250.00"), statement.Sales)
+	assert.Equal(t, money.MustParse("50.00"), statement.Refunds)
+	assert.Equal(t, money.MustParse("16.00"), statement.Commission)
+	assert.Equal(t, money.MustParse("300.00"), statement.Payments)
+	assert.Equal(t, money.MustParse("-1.50"), statement.Adjustments)
+	assert.Equal(t, money.MustParse("14.50"), statement.ClosingBalance)
+
+	require.Len(t, statement.Bookings, 2)
+	assert.Equal(t, firstRef, statement.Bookings[0].BookingReference)
+	assert.Equal(t, money.MustParse("200.00"), statement.Bookings[0].Sales)
+	assert.Equal(t, money.MustParse("16.00"), statement.Bookings[0].Commission)
+	assert.Equal(t, money.MustParse("50.00"), statement.Bookings[1].Refunds)
+	assert.True(t, statement.Bookings[1].Commission.IsZero())
+
+	_, _, err = MonthBounds("March")
+	assert.Error(t, err)
+}
@@ -0,0 +1,528 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AgencyHandler struct {
+	agencyService  service.AgencyService
+	bookingService service.BookingService
+}
+
+func NewAgencyHandler(agencyService service.AgencyService, bookingService service.BookingService) *AgencyHandler {
+	return &AgencyHandler{
+		agencyService:  agencyService,
+		bookingService: bookingService,
+	}
+}
+
+// ListAgencies lists the operator's travel agencies
+// @Summary List agencies
+// @Description List travel agencies with their balances. Use outstanding=true to list only agencies that owe money, largest debt first.
+// @Tags Agencies
+// @Security BearerAuth
+// @Produce json
+// @Param outstanding query bool false "Only agencies with a negative balance"
+// @Param is_active query bool false "Filter by active status"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Router /agencies [get]
+func (h *AgencyHandler) ListAgencies(c *gin.Context) {
+	filter := &models.AgencyFilter{
+		Outstanding: c.Query("outstanding") == "true",
+	}
+
+	if currentUserType(c) != "system_admin" || c.Query("operator_id") != "" {
+		operatorID, err := scopedOperatorID(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		filter.OperatorID = &operatorID
+	}
+
+	if param := c.Query("is_active"); param != "" {
+		isActive := param == "true"
+		filter.IsActive = &isActive
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+
+	agencies, total, err := h.agencyService.ListAgencies(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"agencies": agencies,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// CreateAgency creates a travel agency
+// @Summary Create agency
+// @Description Create a prepaid or credit travel agency with a default commission rate. System admins choose the operator with operator_id.
+// @Tags Agencies
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateAgencyRequest true "Agency details"
+// @Success 201 {object} models.Agency
+// @Failure 400 {object} ErrorResponse
+// @Router /agencies [post]
+func (h *AgencyHandler) CreateAgency(c *gin.Context) {
+	var req models.CreateAgencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Staff create agencies for their own operator
+	operatorID := req.OperatorID
+	if currentUserType(c) != "system_admin" || operatorID == nil {
+		id, err := currentOperatorID(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		operatorID = &id
+	}
+
+	agency, err := h.agencyService.CreateAgency(c.Request.Context(), *operatorID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, agency)
+}
+
+// GetAgency gets an agency with its commission rules
+// @Summary Get agency
+// @Description Get an agency's balance, available credit and commission rules
+// @Tags Agencies
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Agency ID"
+// @Success 200 {object} models.Agency
+// @Failure 404 {object} ErrorResponse
+// @Router /agencies/{id} [get]
+func (h *AgencyHandler) GetAgency(c *gin.Context) {
+	agency, ok := h.authorizedAgency(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, agency)
+}
+
+// UpdateAgency updates an agency
+// @Summary Update agency
+// @Description Update an agency's details, credit limit or default commission rate
+// @Tags Agencies
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Agency ID"
+// @Param request body models.UpdateAgencyRequest true "Agency updates"
+// @Success 200 {object} models.Agency
+// @Failure 400 {object} ErrorResponse
+// @Router /agencies/{id} [put]
+func (h *AgencyHandler) UpdateAgency(c *gin.Context) {
+	agency, ok := h.authorizedAgency(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAgencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.agencyService.UpdateAgency(c.Request.Context(), agency.ID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// AddCommissionRule adds a commission rate for a route or fare class
+// @Summary Add commission rule
+// @Description Set the agency's commission rate for a route, a fare class or both. The most specific rule applies.
+// @Tags Agencies
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Agency ID"
+// @Param request body models.CreateCommissionRuleRequest true "Commission rule"
+// @Success 201 {object} models.AgencyCommissionRule
+// @Failure 400 {object} ErrorResponse
+// @Router /agencies/{id}/commission-rules [post]
+func (h *AgencyHandler) AddCommissionRule(c *gin.Context) {
+	agency, ok := h.authorizedAgency(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCommissionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.agencyService.AddCommissionRule(c.Request.Context(), agency.ID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteCommissionRule removes a commission rule
+// @Summary Delete commission rule
+// @Description Remove one of the agency's commission rules
+// @Tags Agencies
+// @Security BearerAuth
+// @Param id path string true "Agency ID"
+// @Param rule_id path string true "Rule ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /agencies/{id}/commission-rules/{rule_id} [delete]
+func (h *AgencyHandler) DeleteCommissionRule(c *gin.Context) {
+	agency, ok := h.authorizedAgency(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := parseIDParam(c, "rule_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.agencyService.DeleteCommissionRule(c.Request.Context(), agency.ID, ruleID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AssignAgent links an agent to an agency
+// @Summary Assign agent
+// @Description Link one of the operator's agents to the agency so their bookings are charged to it
+// @Tags Agencies
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Agency ID"
+// @Param request body models.AssignAgentRequest true "Agent"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Router /agencies/{id}/agents [post]
+func (h *AgencyHandler) AssignAgent(c *gin.Context) {
+	agency, ok := h.authorizedAgency(c)
+	if !ok {
+		return
+	}
+
+	var req models.AssignAgentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.agencyService.AssignAgent(c.Request.Context(), agency.ID, req.UserID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "agent assigned"})
+}
+
+// RemoveAgent unlinks an agent from an agency
+// @Summary Remove agent
+// @Description Unlink an agent from the agency
+// @Tags Agencies
+// @Security BearerAuth
+// @Param id path string true "Agency ID"
+// @Param user_id path string true "Agent user ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /agencies/{id}/agents/{user_id} [delete]
+func (h *AgencyHandler) RemoveAgent(c *gin.Context) {
+	agency, ok := h.authorizedAgency(c)
+	if !ok {
+		return
+	}
+
+	userID, err := parseIDParam(c, "user_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.agencyService.RemoveAgent(c.Request.Context(), agency.ID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RecordTransaction records a payment, top-up or adjustment on an agency account
+// @Summary Record agency transaction
+// @Description Record money received from an agency (payment or top-up) or a signed manual adjustment
+// @Tags Agencies
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Agency ID"
+// @Param request body models.AgencyTransactionRequest true "Transaction"
+// @Success 201 {object} models.AgencyTransaction
+// @Failure 400 {object} ErrorResponse
+// @Router /agencies/{id}/transactions [post]
+func (h *AgencyHandler) RecordTransaction(c *gin.Context) {
+	agency, ok := h.authorizedAgency(c)
+	if !ok {
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.AgencyTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	txn, err := h.agencyService.RecordTransaction(c.Request.Context(), agency.ID, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, txn)
+}
+
+// ListTransactions lists movements on an agency account
+// @Summary List agency transactions
+// @Description List bookings, commission, refunds and payments on an agency account. Defaults to the current month.
+// @Tags Agencies
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Agency ID"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date, inclusive (YYYY-MM-DD)"
+// @Success 200 {array} models.AgencyTransaction
+// @Failure 400 {object} ErrorResponse
+// @Router /agencies/{id}/transactions [get]
+func (h *AgencyHandler) ListTransactions(c *gin.Context) {
+	agency, ok := h.authorizedAgency(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, 0)
+
+	if param := c.Query("start_date"); param != "" {
+		parsed, err := time.Parse("2006-01-02", param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format"})
+			return
+		}
+		startDate = parsed
+	}
+	if param := c.Query("end_date"); param != "" {
+		parsed, err := time.Parse("2006-01-02", param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format"})
+			return
+		}
+		endDate = parsed.AddDate(0, 0, 1)
+	}
+
+	txns, err := h.agencyService.ListTransactions(c.Request.Context(), agency.ID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, txns)
+}
+
+// GetStatement gets an agency's monthly commission statement
+// @Summary Get agency statement
+// @Description Get an agency's opening and closing balance, sales, refunds, commission and payments for a month
+// @Tags Agencies
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Agency ID"
+// @Param month query string true "Month (YYYY-MM)"
+// @Success 200 {object} models.AgencyStatement
+// @Failure 400 {object} ErrorResponse
+// @Router /agencies/{id}/statements [get]
+func (h *AgencyHandler) GetStatement(c *gin.Context) {
+	agency, ok := h.authorizedAgency(c)
+	if !ok {
+		return
+	}
+
+	h.writeStatement(c, agency)
+}
+
+// GetMyAgency gets the current agent's agency
+// @Summary Get my agency
+// @Description Get the balance, available credit and commission rules of the agency the current agent sells for
+// @Tags Agencies
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.Agency
+// @Failure 404 {object} ErrorResponse
+// @Router /agency [get]
+func (h *AgencyHandler) GetMyAgency(c *gin.Context) {
+	agency, ok := h.agentAgency(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, agency)
+}
+
+// GetMyStatement gets the current agent's agency statement
+// @Summary Get my agency statement
+// @Description Get the monthly commission statement of the agency the current agent sells for
+// @Tags Agencies
+// @Security BearerAuth
+// @Produce json
+// @Param month query string true "Month (YYYY-MM)"
+// @Success 200 {object} models.AgencyStatement
+// @Failure 400 {object} ErrorResponse
+// @Router /agency/statements [get]
+func (h *AgencyHandler) GetMyStatement(c *gin.Context) {
+	agency, ok := h.agentAgency(c)
+	if !ok {
+		return
+	}
+
+	h.writeStatement(c, agency)
+}
+
+// CreateAgencyBooking sells a booking on the agency's account
+// @Summary Create agency booking
+// @Description Create a booking sold by the current agent's agency. The fare less commission is drawn from the agency's balance; the booking is refused if the agency's credit limit would be exceeded.
+// @Tags Agencies
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateAgencyBookingRequest true "Booking details"
+// @Success 201 {object} models.Booking
+// @Failure 400 {object} ErrorResponse
+// @Router /agency/bookings [post]
+func (h *AgencyHandler) CreateAgencyBooking(c *gin.Context) {
+	agentID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.CreateAgencyBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := h.bookingService.CreateAgencyBooking(c.Request.Context(), agentID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+func (h *AgencyHandler) writeStatement(c *gin.Context, agency *models.Agency) {
+	month := c.Query("month")
+	if month == "" {
+		month = time.Now().UTC().Format("2006-01")
+	}
+
+	statement, err := h.agencyService.GetStatement(c.Request.Context(), agency.ID, month)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+// agentAgency loads the agency the current agent sells for
+func (h *AgencyHandler) agentAgency(c *gin.Context) (*models.Agency, bool) {
+	agentID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	agency, err := h.agencyService.GetAgentAgency(c.Request.Context(), agentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return agency, true
+}
+
+// authorizedAgency loads the agency in the path and checks it belongs to the
+// caller's operator
+func (h *AgencyHandler) authorizedAgency(c *gin.Context) (*models.Agency, bool) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	agency, err := h.agencyService.GetAgency(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if currentUserType(c) != "system_admin" {
+		operatorID, err := currentOperatorID(c)
+		if err != nil || agency.OperatorID != operatorID {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("access to agency %s is not allowed", agency.Code)})
+			return nil, false
+		}
+	}
+
+	return agency, true
+}
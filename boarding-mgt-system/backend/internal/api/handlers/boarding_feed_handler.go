@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/boardingfeed"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval keeps idle streams from being closed by proxies
+const heartbeatInterval = 15 * time.Second
+
+type BoardingFeedHandler struct {
+	feedService     service.BoardingFeedService
+	scheduleService service.ScheduleService
+}
+
+func NewBoardingFeedHandler(feedService service.BoardingFeedService, scheduleService service.ScheduleService) *BoardingFeedHandler {
+	return &BoardingFeedHandler{
+		feedService:     feedService,
+		scheduleService: scheduleService,
+	}
+}
+
+// StreamSchedule streams a sailing's boarding progress
+// @Summary Stream sailing boarding progress
+// @Description Server-Sent Events stream of a sailing's boarding. It opens with a "counts" event and then sends a "scan" event for every gate scan, a "counts" event after each scan and "alert" events (near_capacity, over_capacity, all_aboard) as they are raised, with "heartbeat" events while idle.
+// @Tags Gate
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param schedule_id path string true "Schedule ID"
+// @Success 200 {object} models.BoardingEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /gate/schedules/{schedule_id}/live [get]
+func (h *BoardingFeedHandler) StreamSchedule(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "schedule_id")
+	if !ok {
+		return
+	}
+
+	// Subscribe before reading the counts so no scan falls between them
+	sub := h.feedService.Subscribe(boardingfeed.Filter{ScheduleID: &schedule.ID})
+	defer sub.Close()
+
+	counts, err := h.feedService.ScheduleCounts(c.Request.Context(), schedule.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	stream(c, sub, []*models.BoardingCounts{counts})
+}
+
+// StreamPort streams the boarding progress of a port's sailings
+// @Summary Stream port boarding progress
+// @Description Server-Sent Events stream of boarding at a port. It opens with a "counts" event for each of today's sailings from the port and then sends the same events as the sailing stream for every sailing leaving the port. Operator staff only see their own operator's sailings.
+// @Tags Gate
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param port_id path string true "Port ID"
+// @Success 200 {object} models.BoardingEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /gate/ports/{port_id}/live [get]
+func (h *BoardingFeedHandler) StreamPort(c *gin.Context) {
+	portID, err := parseIDParam(c, "port_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := boardingfeed.Filter{PortID: &portID}
+	if currentUserType(c) != "system_admin" {
+		operatorID, err := currentOperatorID(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		filter.OperatorID = &operatorID
+	}
+
+	sub := h.feedService.Subscribe(filter)
+	defer sub.Close()
+
+	counts, err := h.feedService.PortCounts(c.Request.Context(), portID, filter.OperatorID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	stream(c, sub, counts)
+}
+
+// stream sends the initial counts and then the subscription's events until
+// the client goes away
+func stream(c *gin.Context, sub *boardingfeed.Subscription, initial []*models.BoardingCounts) {
+	// Streams outlive the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "streaming is not supported"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	now := time.Now()
+	for _, counts := range initial {
+		event := boardingfeed.CountsEvent(counts, now)
+		c.SSEvent(event.Type, event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case t := <-heartbeat.C:
+			c.SSEvent("heartbeat", gin.H{"at": t.UTC()})
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/api/middleware"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentUserID returns the authenticated user's ID set by the auth middleware
+func currentUserID(c *gin.Context) (uuid.UUID, error) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok || userID == "" {
+		return uuid.Nil, fmt.Errorf("unauthorized")
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user ID")
+	}
+
+	return id, nil
+}
+
+// currentOperatorID returns the operator the authenticated staff user belongs to
+func currentOperatorID(c *gin.Context) (uuid.UUID, error) {
+	operatorID, ok := middleware.GetOperatorID(c)
+	if !ok || operatorID == "" {
+		return uuid.Nil, fmt.Errorf("operator access required")
+	}
+
+	id, err := uuid.Parse(operatorID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid operator ID")
+	}
+
+	return id, nil
+}
+
+// parseIDParam parses a UUID path parameter
+func parseIDParam(c *gin.Context, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+// currentUserType returns the authenticated user's role
+func currentUserType(c *gin.Context) string {
+	return c.GetString("user_type")
+}
+
+// scopedOperatorID returns the operator a request acts on. Staff are limited to
+// their own operator; system admins choose one with the operator_id query parameter.
+func scopedOperatorID(c *gin.Context) (uuid.UUID, error) {
+	if currentUserType(c) == "system_admin" {
+		if param := c.Query("operator_id"); param != "" {
+			id, err := uuid.Parse(param)
+			if err != nil {
+				return uuid.Nil, fmt.Errorf("invalid operator_id")
+			}
+			return id, nil
+		}
+	}
+
+	return currentOperatorID(c)
+}
+
+// authorizeBooking allows the booking's customer, staff of the operator running
+// the sailing and system admins
+func authorizeBooking(c *gin.Context, booking *models.Booking) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if booking.CustomerID == userID {
+		return nil
+	}
+
+	switch currentUserType(c) {
+	case "system_admin":
+		return nil
+	case "agent", "operator_admin":
+		operatorID, err := currentOperatorID(c)
+		if err == nil && booking.Schedule != nil && booking.Schedule.OperatorID == operatorID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("access to this booking is not allowed")
+}
+
+// authorizeSchedule loads the schedule in the path parameter param and checks
+// operator staff belong to its operator, responding with the error if not
+func authorizeSchedule(c *gin.Context, schedules service.ScheduleService, param string) (*models.Schedule, bool) {
+	id, err := parseIDParam(c, param)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return authorizeScheduleID(c, schedules, id)
+}
+
+// authorizeScheduleID is authorizeSchedule for a schedule ID taken from
+// elsewhere in the request, such as its body
+func authorizeScheduleID(c *gin.Context, schedules service.ScheduleService, id uuid.UUID) (*models.Schedule, bool) {
+	schedule, err := schedules.GetSchedule(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if currentUserType(c) != "system_admin" {
+		operatorID, err := currentOperatorID(c)
+		if err != nil || schedule.OperatorID != operatorID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access to this schedule is not allowed"})
+			return nil, false
+		}
+	}
+
+	return schedule, true
+}
+
+// respondScheduleError reports a failed schedule write with status, or as a
+// conflict naming the clashing sailing when the vessel is already allocated
+func respondScheduleError(c *gin.Context, status int, err error) {
+	var conflict *models.VesselConflictError
+	if errors.As(err, &conflict) {
+		body := gin.H{"error": err.Error()}
+		if conflict.Conflicting != nil {
+			body["conflicting_schedule_id"] = conflict.Conflicting.ID
+		}
+		c.JSON(http.StatusConflict, body)
+		return
+	}
+
+	c.JSON(status, gin.H{"error": err.Error()})
+}
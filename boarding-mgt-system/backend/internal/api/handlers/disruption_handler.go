@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type DisruptionHandler struct {
+	disruptionService service.DisruptionService
+	scheduleService   service.ScheduleService
+}
+
+func NewDisruptionHandler(disruptionService service.DisruptionService, scheduleService service.ScheduleService) *DisruptionHandler {
+	return &DisruptionHandler{
+		disruptionService: disruptionService,
+		scheduleService:   scheduleService,
+	}
+}
+
+// RecordDisruption records a delay or gate change
+// @Summary Record schedule disruption
+// @Description Record a delay (new estimated departure and, optionally, arrival) or gate change against a sailing that has not departed, with a reason code (weather, technical, operational, crew, port_congestion, late_inbound, security, medical or other) and free text. Passengers see the new times or gate, are notified and, if the delay outlasts their ticket codes, get new ones. A delay that runs into the vessel's next sailing is rejected with 409.
+// @Tags Schedules
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param request body models.CreateDisruptionRequest true "Disruption"
+// @Success 201 {object} models.ScheduleDisruption
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedules/{id}/disruptions [post]
+func (h *DisruptionHandler) RecordDisruption(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateDisruptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	disruption, err := h.disruptionService.RecordDisruption(c.Request.Context(), schedule, &req, &userID)
+	if err != nil {
+		respondScheduleError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, disruption)
+}
+
+// ChangeVessel substitutes another vessel on a sailing
+// @Summary Change schedule vessel
+// @Description Substitute another active vessel of the operator on a sailing that has not departed, with a reason code. The sailing's capacity and available seats are recomputed for the new vessel; a vessel with fewer seats than are booked is rejected unless override_capacity is set, which leaves the sailing oversold. Seats are carried over to the new vessel's seat map where possible, otherwise to the next free seat in the same class, and passengers whose seats changed are reported and get new tickets. A vessel already sailing at the time is rejected with 409.
+// @Tags Schedules
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param request body models.ChangeVesselRequest true "New vessel"
+// @Success 200 {object} models.VesselChangeResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedules/{id}/vessel [put]
+func (h *DisruptionHandler) ChangeVessel(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ChangeVesselRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.disruptionService.ChangeVessel(c.Request.Context(), schedule, &req, &userID)
+	if err != nil {
+		respondScheduleError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListDisruptions lists a sailing's disruptions
+// @Summary List schedule disruptions
+// @Description List the delays, gate changes and vessel substitutions recorded against a sailing, oldest first
+// @Tags Schedules
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {array} models.ScheduleDisruption
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedules/{id}/disruptions [get]
+func (h *DisruptionHandler) ListDisruptions(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	disruptions, err := h.disruptionService.ListDisruptions(c.Request.Context(), schedule.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, disruptions)
+}
+
+// MoveBookings moves bookings to another sailing
+// @Summary Move bookings to another sailing
+// @Description Move confirmed bookings (all of them when booking_ids is omitted) to another open sailing of the same route while it has room. Passengers keep their seat numbers where free, otherwise get the next free seat in the same class; bookings whose seat class is full stay put. Moved customers get new tickets and are notified.
+// @Tags Schedules
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID to move bookings from"
+// @Param request body models.MoveBookingsRequest true "Target sailing and bookings"
+// @Success 200 {object} models.MoveBookingsResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedules/{id}/rebookings [post]
+func (h *DisruptionHandler) MoveBookings(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	var req models.MoveBookingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.disruptionService.MoveBookings(c.Request.Context(), schedule, &req, &userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetOnTimeReport gets on-time performance
+// @Summary Get on-time performance report
+// @Description Get the on-time performance of sailings departing in a date range that have left or been cancelled, by route and by delay reason. Sailings leaving within the operator's on_time_threshold_minutes setting (15 by default) of the timetable are on time.
+// @Tags Reports
+// @Security BearerAuth
+// @Produce json
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Success 200 {object} models.OnTimeReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /reports/on-time-performance [get]
+func (h *DisruptionHandler) GetOnTimeReport(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format"})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", c.Query("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format"})
+		return
+	}
+
+	report, err := h.disruptionService.GetOnTimeReport(c.Request.Context(), operatorID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/gate"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GateHandler struct {
+	gateService     service.GateService
+	scheduleService service.ScheduleService
+}
+
+func NewGateHandler(gateService service.GateService, scheduleService service.ScheduleService) *GateHandler {
+	return &GateHandler{
+		gateService:     gateService,
+		scheduleService: scheduleService,
+	}
+}
+
+// CheckIn checks in a scanned ticket
+// @Summary Gate check-in scan
+// @Description Check in the ticket a scanned QR code belongs to. Refused scans return 200 with result "denied" and a deny_reason (invalid_code, ticket_not_found, cancelled_booking, wrong_schedule, schedule_cancelled, already_checked_in, already_boarded, unpaid, check_in_closed). Supervisors may set override to accept an overridable refusal.
+// @Tags Gate
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.GateScanRequest true "Scan details"
+// @Success 200 {object} models.BoardingScan
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /gate/check-in [post]
+func (h *GateHandler) CheckIn(c *gin.Context) {
+	h.scan(c, gate.ActionCheckIn)
+}
+
+// Board boards a scanned ticket
+// @Summary Gate boarding scan
+// @Description Board the ticket a scanned QR code belongs to, checking it in if it was not. Refused scans return 200 with result "denied" and a deny_reason (invalid_code, ticket_not_found, cancelled_booking, wrong_schedule, schedule_cancelled, already_boarded, unpaid, boarding_closed). Supervisors may set override to accept an overridable refusal.
+// @Tags Gate
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.GateScanRequest true "Scan details"
+// @Success 200 {object} models.BoardingScan
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /gate/board [post]
+func (h *GateHandler) Board(c *gin.Context) {
+	h.scan(c, gate.ActionBoard)
+}
+
+// ListScans lists the gate scans of a sailing
+// @Summary List gate scans
+// @Description List check-in and boarding scans for a schedule, newest first
+// @Tags Gate
+// @Security BearerAuth
+// @Produce json
+// @Param schedule_id path string true "Schedule ID"
+// @Param result query string false "Filter by result (accepted, denied, overridden)"
+// @Param gate query string false "Filter by gate"
+// @Param ticket_id query string false "Filter by ticket"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Router /gate/schedules/{schedule_id}/scans [get]
+func (h *GateHandler) ListScans(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "schedule_id")
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+
+	filter := &models.BoardingScanFilter{
+		ScheduleID: schedule.ID,
+		Result:     c.Query("result"),
+		Gate:       c.Query("gate"),
+		Limit:      limit,
+		Offset:     (page - 1) * limit,
+	}
+	if param := c.Query("ticket_id"); param != "" {
+		ticketID, err := uuid.Parse(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ticket_id"})
+			return
+		}
+		filter.TicketID = &ticketID
+	}
+
+	scans, total, err := h.gateService.ListScans(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scans": scans,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+func (h *GateHandler) scan(c *gin.Context, action string) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.GateScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Gate supervisors are the operator's admins
+	if req.Override && currentUserType(c) != "operator_admin" && currentUserType(c) != "system_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "overriding a gate decision requires a supervisor"})
+		return
+	}
+
+	if _, ok := authorizeScheduleID(c, h.scheduleService, req.ScheduleID); !ok {
+		return
+	}
+
+	scan, err := h.gateService.Scan(c.Request.Context(), userID, action, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, scan)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxGTFSFeedSize limits uploaded GTFS feeds to 50 MB
+const maxGTFSFeedSize = 50 << 20
+
+type GTFSHandler struct {
+	gtfsService service.GTFSService
+}
+
+func NewGTFSHandler(gtfsService service.GTFSService) *GTFSHandler {
+	return &GTFSHandler{gtfsService: gtfsService}
+}
+
+// ExportFeed downloads the operator's timetable as a GTFS feed
+// @Summary Export GTFS feed
+// @Description Export the operator's routes and the sailings departing in a date range as a zipped GTFS feed (agency, stops, routes, trips, stop_times and calendar) for journey planners and maps. Ports are stops under their codes and routes have the ferry route_type. The operator's website setting is the agency URL; its timezone setting, or else the time zone of its first route's departure port, is the agency time zone.
+// @Tags Timetables
+// @Security BearerAuth
+// @Produce application/zip
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /timetables/gtfs [get]
+func (h *GTFSHandler) ExportFeed(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format"})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", c.Query("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format"})
+		return
+	}
+
+	export, err := h.gtfsService.Export(c.Request.Context(), operatorID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	c.Data(http.StatusOK, export.ContentType, export.Data)
+}
+
+// ImportFeed seeds routes and schedules from a GTFS feed
+// @Summary Import GTFS feed
+// @Description Upload a zipped GTFS feed and create the operator's schedules from the sailings of its ferry trips between two dates (from today at the earliest), one per pair of consecutive stops. Stops are matched to ports by stop_code or stop_id; routes are created for port pairs the operator does not sail yet. Sailings get the vessel given for their trip's block_id or route_id in vessels (a JSON object), or else vessel_id, and the base price. Importing a feed again only adds sailings it has not imported before. A dry run reports what would be created. Sailings clashing with the vessels' other sailings are rejected with 409.
+// @Tags Timetables
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Param file formData file true "GTFS zip"
+// @Param vessel_id formData string true "Vessel for sailings without one in vessels"
+// @Param vessels formData string false "Vessels by GTFS block_id or route_id, as a JSON object"
+// @Param base_price formData string true "Base price"
+// @Param from formData string true "First service date (YYYY-MM-DD)"
+// @Param until formData string true "Last service date (YYYY-MM-DD)"
+// @Param dry_run formData bool false "Only report what would be created"
+// @Success 201 {object} models.GTFSImport
+// @Success 200 {object} models.GTFSImport "Dry run"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /timetables/gtfs [post]
+func (h *GTFSHandler) ImportFeed(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	req := models.GTFSImportRequest{OperatorID: operatorID}
+
+	if req.VesselID, err = uuid.Parse(c.PostForm("vessel_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vessel_id"})
+		return
+	}
+	if vessels := c.PostForm("vessels"); vessels != "" {
+		if err := json.Unmarshal([]byte(vessels), &req.Vessels); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vessels"})
+			return
+		}
+	}
+	if req.BasePrice, err = money.Parse(c.PostForm("base_price")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid base_price"})
+		return
+	}
+	if req.From, err = time.Parse("2006-01-02", c.PostForm("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date format"})
+		return
+	}
+	if req.Until, err = time.Parse("2006-01-02", c.PostForm("until")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until date format"})
+		return
+	}
+	if dryRun := c.PostForm("dry_run"); dryRun != "" {
+		if req.DryRun, err = strconv.ParseBool(dryRun); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GTFS feed is required"})
+		return
+	}
+
+	if fileHeader.Size > maxGTFSFeedSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GTFS feed is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read GTFS feed"})
+		return
+	}
+	defer file.Close()
+
+	if req.Feed, err = io.ReadAll(io.LimitReader(file, maxGTFSFeedSize)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read GTFS feed"})
+		return
+	}
+
+	result, err := h.gtfsService.Import(c.Request.Context(), &req)
+	if err != nil {
+		respondScheduleError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if req.DryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, result)
+}
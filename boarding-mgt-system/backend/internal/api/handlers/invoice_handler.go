@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler struct {
+	invoiceService service.InvoiceService
+	bookingService service.BookingService
+}
+
+func NewInvoiceHandler(invoiceService service.InvoiceService, bookingService service.BookingService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		bookingService: bookingService,
+	}
+}
+
+// GetBookingInvoice downloads a booking's invoice
+// @Summary Download booking invoice
+// @Description Download the invoice of a paid booking as PDF, issuing it on first request. Available to the booking owner and operator staff.
+// @Tags Invoices
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path string true "Booking ID"
+// @Param format query string false "Response format (pdf or json)" default(pdf)
+// @Success 200 {file} file
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bookings/{id}/invoice [get]
+func (h *InvoiceHandler) GetBookingInvoice(c *gin.Context) {
+	booking, ok := h.authorizedBooking(c)
+	if !ok {
+		return
+	}
+
+	doc, err := h.invoiceService.GetBookingInvoice(c.Request.Context(), booking.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.writeDocument(c, doc)
+}
+
+// ListBookingInvoices lists a booking's invoice and credit notes
+// @Summary List booking invoices
+// @Description List the invoice and credit notes issued for a booking
+// @Tags Invoices
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {array} models.Invoice
+// @Failure 403 {object} ErrorResponse
+// @Router /bookings/{id}/invoices [get]
+func (h *InvoiceHandler) ListBookingInvoices(c *gin.Context) {
+	booking, ok := h.authorizedBooking(c)
+	if !ok {
+		return
+	}
+
+	docs, err := h.invoiceService.ListBookingInvoices(c.Request.Context(), booking.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, docs)
+}
+
+// GetBookingDocument downloads an invoice or credit note of a booking
+// @Summary Download booking invoice or credit note
+// @Description Download an invoice or credit note issued for a booking as PDF
+// @Tags Invoices
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path string true "Booking ID"
+// @Param invoice_id path string true "Invoice or credit note ID"
+// @Param format query string false "Response format (pdf or json)" default(pdf)
+// @Success 200 {file} file
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bookings/{id}/invoices/{invoice_id} [get]
+func (h *InvoiceHandler) GetBookingDocument(c *gin.Context) {
+	booking, ok := h.authorizedBooking(c)
+	if !ok {
+		return
+	}
+
+	invoiceID, err := parseIDParam(c, "invoice_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	doc, err := h.invoiceService.GetInvoice(c.Request.Context(), invoiceID)
+	if err != nil || doc.BookingID != booking.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+		return
+	}
+
+	h.writeDocument(c, doc)
+}
+
+// authorizedBooking loads the booking in the path and checks the caller may see it
+func (h *InvoiceHandler) authorizedBooking(c *gin.Context) (*models.Booking, bool) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	booking, err := h.bookingService.GetBooking(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if err := authorizeBooking(c, booking); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return booking, true
+}
+
+func (h *InvoiceHandler) writeDocument(c *gin.Context, doc *models.Invoice) {
+	if c.DefaultQuery("format", "pdf") == "json" {
+		c.JSON(http.StatusOK, doc)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.invoiceService.WritePDF(&buf, doc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileName := fmt.Sprintf("%s.pdf", doc.InvoiceNumber)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerHandler struct {
+	ledgerService service.LedgerService
+}
+
+func NewLedgerHandler(ledgerService service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+	}
+}
+
+// ListAccounts lists the operator's ledger accounts
+// @Summary List ledger accounts
+// @Description List the operator's chart of accounts
+// @Tags Ledger
+// @Security BearerAuth
+// @Produce json
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Success 200 {array} models.LedgerAccount
+// @Failure 403 {object} ErrorResponse
+// @Router /ledger/accounts [get]
+func (h *LedgerHandler) ListAccounts(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	accounts, err := h.ledgerService.ListAccounts(c.Request.Context(), operatorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// GetTrialBalance gets account balances
+// @Summary Get trial balance
+// @Description Get debit and credit totals per ledger account as of a date
+// @Tags Ledger
+// @Security BearerAuth
+// @Produce json
+// @Param as_of query string false "As-of date (YYYY-MM-DD), defaults to today"
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Success 200 {object} models.TrialBalance
+// @Failure 400 {object} ErrorResponse
+// @Router /ledger/trial-balance [get]
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	asOf := time.Now()
+	if param := c.Query("as_of"); param != "" {
+		asOf, err = time.Parse("2006-01-02", param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid as_of date format"})
+			return
+		}
+	}
+
+	balance, err := h.ledgerService.GetTrialBalance(c.Request.Context(), operatorID, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// ExportJournal exports journal entries for a date range
+// @Summary Export journal
+// @Description Export journal entries as CSV or QuickBooks IIF
+// @Tags Ledger
+// @Security BearerAuth
+// @Produce text/csv
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param format query string false "Export format (csv or iif)" default(csv)
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Router /ledger/export [get]
+func (h *LedgerHandler) ExportJournal(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format"})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", c.Query("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+
+	var buf bytes.Buffer
+	if err := h.ledgerService.ExportJournal(c.Request.Context(), operatorID, startDate, endDate, format, &buf); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType := "text/csv"
+	if format == "iif" {
+		contentType = "application/vnd.intuit.iif"
+	}
+
+	fileName := fmt.Sprintf("journal_%s_%s.%s", startDate.Format("20060102"), endDate.Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
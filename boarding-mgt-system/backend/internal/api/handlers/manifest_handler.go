@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ManifestHandler struct {
+	manifestService service.ManifestService
+	scheduleService service.ScheduleService
+}
+
+func NewManifestHandler(manifestService service.ManifestService, scheduleService service.ScheduleService) *ManifestHandler {
+	return &ManifestHandler{
+		manifestService: manifestService,
+		scheduleService: scheduleService,
+	}
+}
+
+// ExportManifest downloads a sailing's passenger manifest
+// @Summary Export passenger manifest
+// @Description Export the passenger list of a sailing for the port authority as CSV, XLSX or a PDF laid out like IMO FAL Form 6 (fal)
+// @Tags Reports
+// @Security BearerAuth
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Param schedule_id path string true "Schedule ID"
+// @Param format query string false "Export format (csv, xlsx or fal)" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /reports/manifest/{schedule_id}/export [get]
+func (h *ManifestHandler) ExportManifest(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "schedule_id")
+	if !ok {
+		return
+	}
+
+	export, err := h.manifestService.Export(c.Request.Context(), schedule.ID, c.DefaultQuery("format", "csv"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, export.ContentType, export.Data)
+}
+
+// GetCompleteness checks a sailing's manifest for missing identity details
+// @Summary Check manifest completeness
+// @Description List the passengers of a sailing missing identity details the operator's manifest policy makes mandatory, and whether that blocks departure
+// @Tags Reports
+// @Security BearerAuth
+// @Produce json
+// @Param schedule_id path string true "Schedule ID"
+// @Success 200 {object} models.ManifestCompleteness
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /reports/manifest/{schedule_id}/completeness [get]
+func (h *ManifestHandler) GetCompleteness(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "schedule_id")
+	if !ok {
+		return
+	}
+
+	completeness, err := h.manifestService.CheckCompleteness(c.Request.Context(), schedule.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, completeness)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type NoShowHandler struct {
+	noShowService   service.NoShowService
+	scheduleService service.ScheduleService
+}
+
+func NewNoShowHandler(noShowService service.NoShowService, scheduleService service.ScheduleService) *NoShowHandler {
+	return &NoShowHandler{
+		noShowService:   noShowService,
+		scheduleService: scheduleService,
+	}
+}
+
+// ProcessNoShows processes a departed sailing's no-shows
+// @Summary Process no-shows
+// @Description Mark the unboarded tickets of a departed sailing as no-shows and apply the operator's no-show policy. This runs when a sailing departs; calling it again returns the counts already recorded.
+// @Tags Schedules
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} models.DepartureSummary
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedules/{id}/no-shows [post]
+func (h *NoShowHandler) ProcessNoShows(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	summary, err := h.noShowService.ProcessDeparture(c.Request.Context(), schedule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetDepartureSummary gets a sailing's final passenger counts
+// @Summary Get departure summary
+// @Description Get the passenger, boarded and no-show counts recorded when a sailing departed
+// @Tags Schedules
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} models.DepartureSummary
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedules/{id}/departure-summary [get]
+func (h *NoShowHandler) GetDepartureSummary(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	summary, err := h.noShowService.GetDepartureSummary(c.Request.Context(), schedule.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetNoShowReport gets no-show rates
+// @Summary Get no-show report
+// @Description Get no-show rates by route and booking channel for sailings departing in a date range
+// @Tags Reports
+// @Security BearerAuth
+// @Produce json
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Success 200 {object} models.NoShowReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /reports/no-shows [get]
+func (h *NoShowHandler) GetNoShowReport(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date format"})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", c.Query("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date format"})
+		return
+	}
+
+	report, err := h.noShowService.GetReport(c.Request.Context(), operatorID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetMyTravelCredits lists the current user's travel credits
+// @Summary Get my travel credits
+// @Description List travel credit issued to the current user, such as part of the fare of a missed sailing
+// @Tags Tickets
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.TravelCredit
+// @Failure 401 {object} ErrorResponse
+// @Router /travel-credits/my [get]
+func (h *NoShowHandler) GetMyTravelCredits(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	credits, err := h.noShowService.GetCustomerCredits(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, credits)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PaymentHandler struct {
+	bookingService service.BookingService
+}
+
+func NewPaymentHandler(bookingService service.BookingService) *PaymentHandler {
+	return &PaymentHandler{
+		bookingService: bookingService,
+	}
+}
+
+// GetBookingBalance gets a booking's payments and balance due
+// @Summary Get booking balance
+// @Description Get the tenders taken on a booking, the amount paid and refunded, and the balance due
+// @Tags Payments
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Success 200 {object} models.BookingBalance
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bookings/{id}/payments [get]
+func (h *PaymentHandler) GetBookingBalance(c *gin.Context) {
+	booking, ok := h.authorizedBooking(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.bookingService.GetBookingBalance(c.Request.Context(), booking.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// AddPayment takes a further payment against a booking
+// @Summary Add booking payment
+// @Description Pay part or all of a booking's balance due with another tender. Payments taken by staff are recorded against their open shift.
+// @Tags Payments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body models.PaymentTender true "Payment tender"
+// @Success 201 {object} models.Booking
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /bookings/{id}/payments [post]
+func (h *PaymentHandler) AddPayment(c *gin.Context) {
+	booking, ok := h.authorizedBooking(c)
+	if !ok {
+		return
+	}
+
+	var req models.PaymentTender
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var takenBy *uuid.UUID
+	switch currentUserType(c) {
+	case "agent", "operator_admin", "system_admin":
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		takenBy = &userID
+	}
+
+	updated, err := h.bookingService.AddPayment(c.Request.Context(), booking.ID, takenBy, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, updated)
+}
+
+// ProcessRefund records a pending refund as paid back
+// @Summary Process refund
+// @Description Record that a pending refund, such as one to a card, was paid back by the gateway. The payout is posted to the ledger and the booking is marked refunded once no refunds are pending.
+// @Tags Payments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Refund ID"
+// @Param request body models.ProcessRefundRequest true "Gateway refund reference"
+// @Success 200 {object} models.Refund
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /refunds/{id}/process [post]
+func (h *PaymentHandler) ProcessRefund(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	refundID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ProcessRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refund, err := h.bookingService.ProcessRefund(c.Request.Context(), operatorID, userID, refundID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, refund)
+}
+
+// authorizedBooking loads the booking in the path and checks the caller may see it
+func (h *PaymentHandler) authorizedBooking(c *gin.Context) (*models.Booking, bool) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	booking, err := h.bookingService.GetBooking(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if err := authorizeBooking(c, booking); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return booking, true
+}
@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ScannerHandler struct {
+	scannerService service.ScannerService
+}
+
+func NewScannerHandler(scannerService service.ScannerService) *ScannerHandler {
+	return &ScannerHandler{
+		scannerService: scannerService,
+	}
+}
+
+// ListDevices lists the operator's scanner devices
+// @Summary List scanner devices
+// @Description List gate and onboard scanners registered to the operator
+// @Tags Scanner Devices
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Router /scanner-devices [get]
+func (h *ScannerHandler) ListDevices(c *gin.Context) {
+	var operatorID *uuid.UUID
+	if currentUserType(c) != "system_admin" || c.Query("operator_id") != "" {
+		id, err := scopedOperatorID(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		operatorID = &id
+	}
+
+	devices, err := h.scannerService.ListDevices(c.Request.Context(), operatorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"devices": devices})
+}
+
+// RegisterDevice registers a scanner device
+// @Summary Register scanner device
+// @Description Register a gate or onboard scanner. The response holds the device key, which is shown only once.
+// @Tags Scanner Devices
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.RegisterDeviceRequest true "Device details"
+// @Success 201 {object} models.RegisteredDevice
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /scanner-devices [post]
+func (h *ScannerHandler) RegisterDevice(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	registered, err := h.scannerService.RegisterDevice(c.Request.Context(), operatorID, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, registered)
+}
+
+// GetDevice gets a scanner device
+// @Summary Get scanner device
+// @Description Get a scanner device with the schedules assigned to it
+// @Tags Scanner Devices
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 200 {object} models.ScannerDevice
+// @Failure 404 {object} ErrorResponse
+// @Router /scanner-devices/{id} [get]
+func (h *ScannerHandler) GetDevice(c *gin.Context) {
+	device, ok := h.authorizedDevice(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, device)
+}
+
+// UpdateDevice updates a scanner device
+// @Summary Update scanner device
+// @Description Rename a scanner device or deactivate it. A deactivated device can no longer sync.
+// @Tags Scanner Devices
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param request body models.UpdateDeviceRequest true "Device updates"
+// @Success 200 {object} models.ScannerDevice
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /scanner-devices/{id} [put]
+func (h *ScannerHandler) UpdateDevice(c *gin.Context) {
+	device, ok := h.authorizedDevice(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.scannerService.UpdateDevice(c.Request.Context(), device.ID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// RotateKey issues a scanner device a new key
+// @Summary Rotate device key
+// @Description Issue a new device key. The old key stops working immediately.
+// @Tags Scanner Devices
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 200 {object} models.RegisteredDevice
+// @Failure 404 {object} ErrorResponse
+// @Router /scanner-devices/{id}/rotate-key [post]
+func (h *ScannerHandler) RotateKey(c *gin.Context) {
+	device, ok := h.authorizedDevice(c)
+	if !ok {
+		return
+	}
+
+	registered, err := h.scannerService.RotateKey(c.Request.Context(), device.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, registered)
+}
+
+// AssignSchedules assigns schedules to a scanner device
+// @Summary Assign schedules to device
+// @Description Let a device download manifests and upload scans for the given schedules of its operator
+// @Tags Scanner Devices
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param request body models.AssignSchedulesRequest true "Schedules"
+// @Success 200 {object} models.ScannerDevice
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /scanner-devices/{id}/schedules [post]
+func (h *ScannerHandler) AssignSchedules(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	device, ok := h.authorizedDevice(c)
+	if !ok {
+		return
+	}
+
+	var req models.AssignSchedulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.scannerService.AssignSchedules(c.Request.Context(), device.ID, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// UnassignSchedule removes a schedule from a scanner device
+// @Summary Unassign schedule from device
+// @Description Stop a device syncing a schedule
+// @Tags Scanner Devices
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Device ID"
+// @Param schedule_id path string true "Schedule ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} ErrorResponse
+// @Router /scanner-devices/{id}/schedules/{schedule_id} [delete]
+func (h *ScannerHandler) UnassignSchedule(c *gin.Context) {
+	device, ok := h.authorizedDevice(c)
+	if !ok {
+		return
+	}
+
+	scheduleID, err := parseIDParam(c, "schedule_id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.scannerService.UnassignSchedule(c.Request.Context(), device.ID, scheduleID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule unassigned successfully"})
+}
+
+// GetBundle downloads the calling device's manifest bundle
+// @Summary Download scanner bundle
+// @Description Download the signed manifests of the schedules assigned to the calling device, with the public keys to verify ticket codes offline. Authenticate with the X-Device-ID and X-Device-Key headers.
+// @Tags Scanner Sync
+// @Produce json
+// @Param X-Device-ID header string true "Device ID"
+// @Param X-Device-Key header string true "Device key"
+// @Success 200 {object} models.SignedBundle
+// @Failure 401 {object} ErrorResponse
+// @Router /device/bundle [get]
+func (h *ScannerHandler) GetBundle(c *gin.Context) {
+	device, ok := h.authenticatedDevice(c)
+	if !ok {
+		return
+	}
+
+	bundle, err := h.scannerService.GetBundle(c.Request.Context(), device)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, bundle)
+}
+
+// UploadScans uploads the calling device's offline scans
+// @Summary Upload offline scans
+// @Description Upload scans recorded offline. Uploads are idempotent on event_id. Each event is reported as applied, duplicate or rejected, with any conflict with the server's state (already_boarded, server_denied, superseded). Authenticate with the X-Device-ID and X-Device-Key headers.
+// @Tags Scanner Sync
+// @Accept json
+// @Produce json
+// @Param X-Device-ID header string true "Device ID"
+// @Param X-Device-Key header string true "Device key"
+// @Param request body models.ScanUploadRequest true "Scan events"
+// @Success 200 {object} models.ScanUploadResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /device/scans [post]
+func (h *ScannerHandler) UploadScans(c *gin.Context) {
+	device, ok := h.authenticatedDevice(c)
+	if !ok {
+		return
+	}
+
+	var req models.ScanUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.scannerService.UploadScans(c.Request.Context(), device, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// authorizedDevice loads the device in the path and checks the caller's
+// operator owns it
+func (h *ScannerHandler) authorizedDevice(c *gin.Context) (*models.ScannerDevice, bool) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	device, err := h.scannerService.GetDevice(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if currentUserType(c) != "system_admin" {
+		operatorID, err := currentOperatorID(c)
+		if err != nil || device.OperatorID != operatorID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "scanner device not found"})
+			return nil, false
+		}
+	}
+
+	return device, true
+}
+
+// authenticatedDevice authenticates a scanner by its X-Device-ID and
+// X-Device-Key headers
+func (h *ScannerHandler) authenticatedDevice(c *gin.Context) (*models.ScannerDevice, bool) {
+	id, err := uuid.Parse(c.GetHeader("X-Device-ID"))
+	key := c.GetHeader("X-Device-Key")
+	if err != nil || key == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "device credentials required"})
+		return nil, false
+	}
+
+	device, err := h.scannerService.Authenticate(c.Request.Context(), id, key)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return device, true
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleCancellationHandler struct {
+	cancellationService service.ScheduleCancellationService
+	scheduleService     service.ScheduleService
+}
+
+func NewScheduleCancellationHandler(cancellationService service.ScheduleCancellationService, scheduleService service.ScheduleService) *ScheduleCancellationHandler {
+	return &ScheduleCancellationHandler{
+		cancellationService: cancellationService,
+		scheduleService:     scheduleService,
+	}
+}
+
+// CancelSchedule cancels a sailing and its bookings
+// @Summary Cancel schedule and its bookings
+// @Description Cancel a sailing and every confirmed booking on it. Each passenger is offered a refund, a place on an alternative sailing or travel credit, and notified. If the run stops partway, calling this again resumes it; it also refunds passengers whose offer has lapsed.
+// @Tags Schedules
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param request body models.CancelScheduleRequest true "Cancellation reason"
+// @Success 200 {object} models.ScheduleCancellationReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedules/{id}/cancellation [post]
+func (h *ScheduleCancellationHandler) CancelSchedule(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CancelScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if schedule.Status == "departed" || schedule.Status == "arrived" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a sailing that has departed cannot be cancelled"})
+		return
+	}
+
+	report, err := h.cancellationService.CancelSchedule(c.Request.Context(), schedule.ID, req.Reason, &userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCancellationReport gets a cancelled sailing's summary report
+// @Summary Get schedule cancellation report
+// @Description Get the progress of a sailing's cancellation and what its passengers chose: refunds, rebookings, travel credit and offers still pending
+// @Tags Schedules
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} models.ScheduleCancellationReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedules/{id}/cancellation [get]
+func (h *ScheduleCancellationHandler) GetCancellationReport(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.cancellationService.GetReport(c.Request.Context(), schedule.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetMyCancellationOffers lists the current user's cancellation offers
+// @Summary Get my cancellation offers
+// @Description List the bookings of the current user on cancelled sailings and the refund, rebooking or credit offered for each
+// @Tags Bookings
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.CancellationOffer
+// @Failure 401 {object} ErrorResponse
+// @Router /cancellation-offers/my [get]
+func (h *ScheduleCancellationHandler) GetMyCancellationOffers(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	offers, err := h.cancellationService.GetCustomerOffers(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+// ChooseCancellationOption takes up an option on a cancelled booking
+// @Summary Choose cancellation option
+// @Description Choose a refund, rebooking onto another sailing of the same route (schedule_id required) or travel credit for a booking on a cancelled sailing. Staff of the operator can choose on the customer's behalf.
+// @Tags Bookings
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Cancellation offer ID"
+// @Param request body models.ChooseCancellationOptionRequest true "Chosen option"
+// @Success 200 {object} models.CancellationOffer
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /cancellation-offers/{id}/choose [post]
+func (h *ScheduleCancellationHandler) ChooseCancellationOption(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	offer, err := h.cancellationService.GetOffer(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if offer.CustomerID != userID && !h.staffOf(c, offer) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access to this booking is not allowed"})
+		return
+	}
+
+	var req models.ChooseCancellationOptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.cancellationService.ChooseOption(c.Request.Context(), offer, &userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// staffOf reports whether the current user is a system admin or staff of the
+// operator that ran the offer's cancelled sailing
+func (h *ScheduleCancellationHandler) staffOf(c *gin.Context, offer *models.CancellationOffer) bool {
+	switch currentUserType(c) {
+	case "system_admin":
+		return true
+	case "agent", "operator_admin":
+		operatorID, err := currentOperatorID(c)
+		if err != nil {
+			return false
+		}
+		schedule, err := h.scheduleService.GetSchedule(c.Request.Context(), offer.ScheduleID)
+		return err == nil && schedule.OperatorID == operatorID
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleLifecycleHandler struct {
+	lifecycleService service.ScheduleLifecycleService
+	scheduleService  service.ScheduleService
+}
+
+func NewScheduleLifecycleHandler(lifecycleService service.ScheduleLifecycleService, scheduleService service.ScheduleService) *ScheduleLifecycleHandler {
+	return &ScheduleLifecycleHandler{
+		lifecycleService: lifecycleService,
+		scheduleService:  scheduleService,
+	}
+}
+
+// OpenBoarding opens boarding on a sailing
+// @Summary Open boarding
+// @Description Open boarding on a scheduled sailing before the operator's boarding_opens_minutes setting would. Sales close once boarding opens.
+// @Tags Schedules
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} models.Schedule
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedules/{id}/boarding [post]
+func (h *ScheduleLifecycleHandler) OpenBoarding(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	updated, err := h.lifecycleService.OpenBoarding(c.Request.Context(), schedule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// ConfirmDeparture confirms a sailing has left
+// @Summary Confirm departure
+// @Description Record when a sailing left, now unless a time is given. A sailing not yet departed departs, which posts its fares to the ledger and processes its no-shows, unless its manifest blocks departure. A departed sailing has its departure time corrected.
+// @Tags Schedules
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param request body models.ConfirmDepartureRequest true "Departure time"
+// @Success 200 {object} models.Schedule
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedules/{id}/departure [post]
+func (h *ScheduleLifecycleHandler) ConfirmDeparture(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ConfirmDepartureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.lifecycleService.ConfirmDeparture(c.Request.Context(), schedule, req.DepartedAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// ConfirmArrival confirms a sailing has arrived
+// @Summary Confirm arrival
+// @Description Record when a departed sailing arrived, now unless a time is given
+// @Tags Schedules
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param request body models.ConfirmArrivalRequest true "Arrival time"
+// @Success 200 {object} models.Schedule
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedules/{id}/arrival [post]
+func (h *ScheduleLifecycleHandler) ConfirmArrival(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ConfirmArrivalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.lifecycleService.ConfirmArrival(c.Request.Context(), schedule, req.ArrivedAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
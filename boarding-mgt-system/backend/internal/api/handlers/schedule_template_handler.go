@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ScheduleTemplateHandler struct {
+	templateService service.ScheduleTemplateService
+}
+
+func NewScheduleTemplateHandler(templateService service.ScheduleTemplateService) *ScheduleTemplateHandler {
+	return &ScheduleTemplateHandler{templateService: templateService}
+}
+
+// ListTemplates lists schedule templates
+// @Summary List schedule templates
+// @Description List the operator's recurring timetables
+// @Tags Schedule Templates
+// @Security BearerAuth
+// @Produce json
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Router /schedule-templates [get]
+func (h *ScheduleTemplateHandler) ListTemplates(c *gin.Context) {
+	var operatorID *uuid.UUID
+	if currentUserType(c) != "system_admin" || c.Query("operator_id") != "" {
+		id, err := scopedOperatorID(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		operatorID = &id
+	}
+
+	templates, err := h.templateService.ListTemplates(c.Request.Context(), operatorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// CreateTemplate creates a schedule template
+// @Summary Create schedule template
+// @Description Create a recurring timetable for a route and vessel: daily departure times, the days of the week it runs on, the period it is valid for and dates it does not run. Schedules are created from it with the generate endpoint.
+// @Tags Schedule Templates
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Param request body models.CreateScheduleTemplateRequest true "Template details"
+// @Success 201 {object} models.ScheduleTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /schedule-templates [post]
+func (h *ScheduleTemplateHandler) CreateTemplate(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.CreateScheduleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.OperatorID = operatorID
+
+	template, err := h.templateService.CreateTemplate(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// GetTemplate gets a schedule template
+// @Summary Get schedule template
+// @Description Get a recurring timetable
+// @Tags Schedule Templates
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} models.ScheduleTemplate
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule-templates/{id} [get]
+func (h *ScheduleTemplateHandler) GetTemplate(c *gin.Context) {
+	template, ok := h.authorizedTemplate(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate updates a schedule template
+// @Summary Update schedule template
+// @Description Change a recurring timetable. Future schedules already generated from it that are unbooked are updated or removed to match; booked ones are left alone and listed as kept. Deactivating a template removes its unbooked future schedules.
+// @Tags Schedule Templates
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param request body models.UpdateScheduleTemplateRequest true "Template updates"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedule-templates/{id} [put]
+func (h *ScheduleTemplateHandler) UpdateTemplate(c *gin.Context) {
+	template, ok := h.authorizedTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateScheduleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, generation, err := h.templateService.UpdateTemplate(c.Request.Context(), template.ID, &req)
+	if err != nil {
+		respondScheduleError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": updated, "generation": generation})
+}
+
+// GenerateSchedules generates a template's schedules
+// @Summary Generate schedules from template
+// @Description Create the template's schedules from today up to a date and bring its unbooked future schedules up to date. Runs are idempotent. With dry_run the changes are reported but not made.
+// @Tags Schedule Templates
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param request body models.GenerateSchedulesRequest true "Generation horizon"
+// @Success 200 {object} models.ScheduleGeneration
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedule-templates/{id}/generate [post]
+func (h *ScheduleTemplateHandler) GenerateSchedules(c *gin.Context) {
+	template, ok := h.authorizedTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req models.GenerateSchedulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	until, err := time.Parse("2006-01-02", req.Until)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until date format"})
+		return
+	}
+
+	generation, err := h.templateService.Generate(c.Request.Context(), template.ID, until, req.DryRun)
+	if err != nil {
+		respondScheduleError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, generation)
+}
+
+// authorizedTemplate loads the template in the path and checks operator
+// staff belong to its operator
+func (h *ScheduleTemplateHandler) authorizedTemplate(c *gin.Context) (*models.ScheduleTemplate, bool) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	template, err := h.templateService.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if currentUserType(c) != "system_admin" {
+		operatorID, err := currentOperatorID(c)
+		if err != nil || template.OperatorID != operatorID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access to this schedule template is not allowed"})
+			return nil, false
+		}
+	}
+
+	return template, true
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSettlementFileSize limits uploaded settlement files to 20 MB
+const maxSettlementFileSize = 20 << 20
+
+type SettlementHandler struct {
+	settlementService service.SettlementService
+}
+
+func NewSettlementHandler(settlementService service.SettlementService) *SettlementHandler {
+	return &SettlementHandler{
+		settlementService: settlementService,
+	}
+}
+
+// ListProviders lists settlement providers
+// @Summary List settlement providers
+// @Description List payment gateways and their settlement file layouts
+// @Tags Settlements
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.SettlementProvider
+// @Failure 500 {object} ErrorResponse
+// @Router /settlements/providers [get]
+func (h *SettlementHandler) ListProviders(c *gin.Context) {
+	providers, err := h.settlementService.ListProviders(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, providers)
+}
+
+// CreateProvider registers a settlement provider
+// @Summary Create settlement provider
+// @Description Register a payment gateway with its settlement CSV column mapping
+// @Tags Settlements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateSettlementProviderRequest true "Provider details"
+// @Success 201 {object} models.SettlementProvider
+// @Failure 400 {object} ErrorResponse
+// @Router /settlements/providers [post]
+func (h *SettlementHandler) CreateProvider(c *gin.Context) {
+	var req models.CreateSettlementProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	provider, err := h.settlementService.CreateProvider(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, provider)
+}
+
+// UpdateProvider updates a settlement provider
+// @Summary Update settlement provider
+// @Description Update a provider's column mapping or settlement settings
+// @Tags Settlements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param code path string true "Provider code"
+// @Param request body models.UpdateSettlementProviderRequest true "Provider updates"
+// @Success 200 {object} models.SettlementProvider
+// @Failure 400 {object} ErrorResponse
+// @Router /settlements/providers/{code} [put]
+func (h *SettlementHandler) UpdateProvider(c *gin.Context) {
+	var req models.UpdateSettlementProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	provider, err := h.settlementService.UpdateProvider(c.Request.Context(), c.Param("code"), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, provider)
+}
+
+// ImportSettlement imports a gateway settlement file
+// @Summary Import settlement file
+// @Description Upload a settlement CSV and reconcile its rows against payments
+// @Tags Settlements
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param provider formData string true "Provider code"
+// @Param file formData file true "Settlement CSV"
+// @Success 201 {object} models.SettlementImport
+// @Failure 400 {object} ErrorResponse
+// @Router /settlements/imports [post]
+func (h *SettlementHandler) ImportSettlement(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	providerCode := c.PostForm("provider")
+	if providerCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider is required"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "settlement file is required"})
+		return
+	}
+
+	if fileHeader.Size > maxSettlementFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "settlement file is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read settlement file"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSettlementFileSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read settlement file"})
+		return
+	}
+
+	imp, err := h.settlementService.ImportSettlement(c.Request.Context(), providerCode, fileHeader.Filename, data, &userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, imp)
+}
+
+// ListImports lists settlement imports
+// @Summary List settlement imports
+// @Description List imported settlement files with reconciliation totals
+// @Tags Settlements
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {array} models.SettlementImport
+// @Failure 500 {object} ErrorResponse
+// @Router /settlements/imports [get]
+func (h *SettlementHandler) ListImports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	imports, err := h.settlementService.ListImports(c.Request.Context(), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, imports)
+}
+
+// GetImport gets a settlement import with its lines
+// @Summary Get settlement import
+// @Description Get an imported settlement file and how each row matched
+// @Tags Settlements
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Import ID"
+// @Success 200 {object} models.SettlementImport
+// @Failure 404 {object} ErrorResponse
+// @Router /settlements/imports/{id} [get]
+func (h *SettlementHandler) GetImport(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	imp, err := h.settlementService.GetImport(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, imp)
+}
+
+// GetUnreconciledReport gets items that still need reconciling
+// @Summary Unreconciled items report
+// @Description List flagged payments (missing payouts, amount differences, fee deductions) and unknown settlement rows
+// @Tags Settlements
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.UnreconciledReport
+// @Failure 500 {object} ErrorResponse
+// @Router /settlements/unreconciled [get]
+func (h *SettlementHandler) GetUnreconciledReport(c *gin.Context) {
+	report, err := h.settlementService.GetUnreconciledReport(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ResolvePayment closes a flagged payment
+// @Summary Resolve flagged payment
+// @Description Mark a flagged payment as resolved after manual review
+// @Tags Settlements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body models.ResolveReconciliationRequest true "Resolution notes"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /settlements/payments/{id}/resolve [post]
+func (h *SettlementHandler) ResolvePayment(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ResolveReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.settlementService.ResolvePayment(c.Request.Context(), id, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payment resolved"})
+}
+
+// ResolveLine closes a flagged settlement line
+// @Summary Resolve settlement line
+// @Description Mark an unknown or duplicate settlement row as resolved after manual review
+// @Tags Settlements
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Settlement line ID"
+// @Param request body models.ResolveReconciliationRequest true "Resolution notes"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /settlements/lines/{id}/resolve [post]
+func (h *SettlementHandler) ResolveLine(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ResolveReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.settlementService.ResolveLine(c.Request.Context(), id, userID, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "settlement line resolved"})
+}
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ShiftHandler struct {
+	shiftService   service.ShiftService
+	bookingService service.BookingService
+}
+
+func NewShiftHandler(shiftService service.ShiftService, bookingService service.BookingService) *ShiftHandler {
+	return &ShiftHandler{
+		shiftService:   shiftService,
+		bookingService: bookingService,
+	}
+}
+
+// OpenShift opens a cash drawer for the current agent
+// @Summary Open agent shift
+// @Description Open a cash drawer on a POS terminal with an opening float
+// @Tags Shifts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.OpenShiftRequest true "Shift details"
+// @Success 201 {object} models.AgentShift
+// @Failure 400 {object} ErrorResponse
+// @Router /shifts [post]
+func (h *ShiftHandler) OpenShift(c *gin.Context) {
+	agentID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.OpenShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shift, err := h.shiftService.OpenShift(c.Request.Context(), agentID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, shift)
+}
+
+// GetCurrentShift gets the current agent's open shift
+// @Summary Get current shift
+// @Description Get the open cash drawer held by the current agent
+// @Tags Shifts
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.AgentShift
+// @Failure 404 {object} ErrorResponse
+// @Router /shifts/current [get]
+func (h *ShiftHandler) GetCurrentShift(c *gin.Context) {
+	agentID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	shift, err := h.shiftService.GetCurrentShift(c.Request.Context(), agentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
+// CloseShift closes the agent's shift with the counted cash
+// @Summary Close agent shift
+// @Description Close a cash drawer and produce the variance report
+// @Tags Shifts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Param request body models.CloseShiftRequest true "Counted cash"
+// @Success 200 {object} models.ShiftVarianceReport
+// @Failure 400 {object} ErrorResponse
+// @Router /shifts/{id}/close [post]
+func (h *ShiftHandler) CloseShift(c *gin.Context) {
+	agentID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	shiftID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.CloseShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.shiftService.CloseShift(c.Request.Context(), agentID, shiftID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetShiftReport gets the variance report of a shift
+// @Summary Get shift report
+// @Description Get cash sales, refunds and variance for a shift
+// @Tags Shifts
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Success 200 {object} models.ShiftVarianceReport
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /shifts/{id}/report [get]
+func (h *ShiftHandler) GetShiftReport(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	shiftID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.shiftService.GetShiftReport(c.Request.Context(), operatorID, shiftID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ListOpenShifts lists open shifts for the admin's operator
+// @Summary List open shifts
+// @Description List all cash drawers currently open for the operator
+// @Tags Shifts
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.AgentShift
+// @Failure 403 {object} ErrorResponse
+// @Router /shifts/open [get]
+func (h *ShiftHandler) ListOpenShifts(c *gin.Context) {
+	operatorID, err := currentOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	shifts, err := h.shiftService.ListOpenShifts(c.Request.Context(), operatorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, shifts)
+}
+
+// ListUnreconciledShifts lists closed shifts with an unexplained variance
+// @Summary List unreconciled shifts
+// @Description List closed cash drawers whose counted cash differs from expected
+// @Tags Shifts
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.AgentShift
+// @Failure 403 {object} ErrorResponse
+// @Router /shifts/unreconciled [get]
+func (h *ShiftHandler) ListUnreconciledShifts(c *gin.Context) {
+	operatorID, err := currentOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	shifts, err := h.shiftService.ListUnreconciledShifts(c.Request.Context(), operatorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, shifts)
+}
+
+// ReconcileShift signs off a shift variance
+// @Summary Reconcile shift
+// @Description Record an admin's explanation of a closed shift's variance
+// @Tags Shifts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Param request body models.ReconcileShiftRequest true "Reconciliation notes"
+// @Success 200 {object} models.AgentShift
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /shifts/{id}/reconcile [post]
+func (h *ShiftHandler) ReconcileShift(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	shiftID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.ReconcileShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shift, err := h.shiftService.ReconcileShift(c.Request.Context(), operatorID, shiftID, adminID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, shift)
+}
+
+// CreatePOSBooking sells a booking at the counter against the agent's shift
+// @Summary Create POS booking
+// @Description Create a counter booking tied to the agent's open shift
+// @Tags Shifts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreatePOSBookingRequest true "Booking details"
+// @Success 201 {object} models.Booking
+// @Failure 400 {object} ErrorResponse
+// @Router /pos/bookings [post]
+func (h *ShiftHandler) CreatePOSBooking(c *gin.Context) {
+	agentID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.CreatePOSBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := h.bookingService.CreatePOSBooking(c.Request.Context(), agentID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+// RefundPOSBooking cancels a booking and refunds each tender it was paid with
+// @Summary Refund POS booking
+// @Description Cancel a booking and record a refund per tender against the agent's open shift. Cash is paid out of the drawer and agency accounts credited straight away; card and other tender refunds stay pending until they are paid out.
+// @Tags Shifts
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Booking ID"
+// @Param request body models.POSRefundRequest true "Refund reason"
+// @Success 200 {array} models.Refund
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /pos/bookings/{id}/refund [post]
+func (h *ShiftHandler) RefundPOSBooking(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	agentID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookingID, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.POSRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refunds, err := h.bookingService.RefundPOSBooking(c.Request.Context(), operatorID, agentID, bookingID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, refunds)
+}
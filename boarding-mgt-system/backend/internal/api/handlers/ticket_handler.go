@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/boardingpass"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type TicketHandler struct {
+	ticketService  service.TicketService
+	bookingService service.BookingService
+}
+
+func NewTicketHandler(ticketService service.TicketService, bookingService service.BookingService) *TicketHandler {
+	return &TicketHandler{
+		ticketService:  ticketService,
+		bookingService: bookingService,
+	}
+}
+
+// GetMyTickets lists the current user's tickets
+// @Summary Get my tickets
+// @Description List the tickets on the current user's bookings, latest sailing first
+// @Tags Tickets
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Maximum number of tickets" default(50)
+// @Success 200 {array} models.Ticket
+// @Failure 401 {object} ErrorResponse
+// @Router /tickets/my [get]
+func (h *TicketHandler) GetMyTickets(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
+	}
+
+	tickets, err := h.ticketService.GetCustomerTickets(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tickets)
+}
+
+// GetTicket gets a ticket
+// @Summary Get ticket
+// @Description Get a ticket with its booking. Available to the booking owner and operator staff.
+// @Tags Tickets
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Success 200 {object} models.Ticket
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tickets/{id} [get]
+func (h *TicketHandler) GetTicket(c *gin.Context) {
+	ticket, ok := h.authorizedTicket(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
+
+// UpdatePassengerIdentity records a passenger's travel document details
+// @Summary Update passenger identity
+// @Description Set the nationality, date of birth, sex and travel document of a ticket's passenger for the port authority manifest. Replaces any details given before. Available to the booking owner and operator staff until the sailing departs.
+// @Tags Tickets
+// @Security BearerAuth
+// @Accept json
+// @Param id path string true "Ticket ID"
+// @Param request body models.PassengerIdentity true "Passenger identity"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tickets/{id}/identity [put]
+func (h *TicketHandler) UpdatePassengerIdentity(c *gin.Context) {
+	ticket, ok := h.authorizedTicket(c)
+	if !ok {
+		return
+	}
+
+	var req models.PassengerIdentity
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.ticketService.UpdatePassengerIdentity(c.Request.Context(), ticket, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTicketQR renders a ticket's QR code
+// @Summary Get ticket QR code image
+// @Description Render a ticket's signed QR code as a square PNG or SVG image
+// @Tags Tickets
+// @Security BearerAuth
+// @Produce image/png
+// @Produce image/svg+xml
+// @Param id path string true "Ticket ID"
+// @Param format query string false "Image format (png or svg)" default(png)
+// @Param size query int false "Image size in pixels (64 to 2048)" default(256)
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tickets/{id}/qr [get]
+func (h *TicketHandler) GetTicketQR(c *gin.Context) {
+	ticket, ok := h.authorizedTicket(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", boardingpass.FormatPNG)
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(boardingpass.DefaultSize)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid size"})
+		return
+	}
+
+	image, err := h.ticketService.RenderQRCode(ticket, format, size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, boardingpass.ContentType(format), image)
+}
+
+// GetTicketBoardingPass downloads a ticket's boarding pass
+// @Summary Download ticket boarding pass
+// @Description Download a printable boarding pass for one ticket as PDF. Available to the booking owner and operator staff.
+// @Tags Tickets
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path string true "Ticket ID"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tickets/{id}/boarding-pass [get]
+func (h *TicketHandler) GetTicketBoardingPass(c *gin.Context) {
+	ticket, ok := h.authorizedTicket(c)
+	if !ok {
+		return
+	}
+
+	passes, err := h.ticketService.GetBoardingPasses(c.Request.Context(), ticket.Booking, []models.Ticket{*ticket})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.writeBoardingPasses(c, fmt.Sprintf("boarding-pass-%s", ticket.ID), passes)
+}
+
+// GetBookingBoardingPasses downloads the boarding passes of a booking
+// @Summary Download booking boarding passes
+// @Description Download printable boarding passes for every ticket on a booking as one PDF, a page per passenger
+// @Tags Tickets
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path string true "Booking ID"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bookings/{id}/boarding-passes [get]
+func (h *TicketHandler) GetBookingBoardingPasses(c *gin.Context) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, err := h.bookingService.GetBooking(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := authorizeBooking(c, booking); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	passes, err := h.ticketService.GetBoardingPasses(c.Request.Context(), booking, booking.Tickets)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.writeBoardingPasses(c, fmt.Sprintf("boarding-passes-%s", booking.BookingReference), passes)
+}
+
+// VerifyQRCode verifies a scanned ticket code
+// @Summary Verify ticket QR code
+// @Description Check a ticket code's signature and validity window. This is the same check gate scanners run offline and does not look the ticket up.
+// @Tags Tickets
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.VerifyQRCodeRequest true "Scanned code"
+// @Success 200 {object} models.QRCodeVerification
+// @Failure 400 {object} ErrorResponse
+// @Router /tickets/verify [post]
+func (h *TicketHandler) VerifyQRCode(c *gin.Context) {
+	var req models.VerifyQRCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.ticketService.VerifyQRCode(c.Request.Context(), req.QRCode))
+}
+
+// ListVerificationKeys lists the public keys ticket codes are signed with
+// @Summary List ticket verification keys
+// @Description List the Ed25519 public keys gate scanners need to verify ticket codes offline. The active key signs new codes; the others still verify codes issued before a rotation.
+// @Tags Tickets
+// @Produce json
+// @Success 200 {array} ticketqr.PublicKey
+// @Router /tickets/verification-keys [get]
+func (h *TicketHandler) ListVerificationKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, h.ticketService.ListVerificationKeys(c.Request.Context()))
+}
+
+// authorizedTicket loads the ticket in the path and checks the caller may see
+// its booking
+func (h *TicketHandler) authorizedTicket(c *gin.Context) (*models.Ticket, bool) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	ticket, err := h.ticketService.GetTicket(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if err := authorizeBooking(c, ticket.Booking); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return ticket, true
+}
+
+func (h *TicketHandler) writeBoardingPasses(c *gin.Context, name string, passes []*models.BoardingPass) {
+	var buf bytes.Buffer
+	if err := h.ticketService.WriteBoardingPassPDF(&buf, passes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".pdf"))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TimetableVersionHandler struct {
+	timetableService service.TimetableVersionService
+}
+
+func NewTimetableVersionHandler(timetableService service.TimetableVersionService) *TimetableVersionHandler {
+	return &TimetableVersionHandler{timetableService: timetableService}
+}
+
+// ListVersions lists timetable versions
+// @Summary List timetable versions
+// @Description List the operator's seasonal timetables, latest season first, with their status: draft, review, published or superseded
+// @Tags Timetables
+// @Security BearerAuth
+// @Produce json
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Router /timetable-versions [get]
+func (h *TimetableVersionHandler) ListVersions(c *gin.Context) {
+	var operatorID *uuid.UUID
+	if currentUserType(c) != "system_admin" || c.Query("operator_id") != "" {
+		id, err := scopedOperatorID(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		operatorID = &id
+	}
+
+	versions, err := h.timetableService.ListVersions(c.Request.Context(), operatorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// CreateVersion starts a draft timetable version
+// @Summary Create timetable version
+// @Description Start a draft timetable for a season, optionally copying the sailings another version has in the season. Sailings are added with the create schedule endpoint and its timetable_version_id; they stay off search and booking until the version is published.
+// @Tags Timetables
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Param request body models.CreateTimetableVersionRequest true "Season details"
+// @Success 201 {object} models.TimetableVersion
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /timetable-versions [post]
+func (h *TimetableVersionHandler) CreateVersion(c *gin.Context) {
+	operatorID, err := scopedOperatorID(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.CreateTimetableVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.OperatorID = operatorID
+
+	version, err := h.timetableService.CreateVersion(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, version)
+}
+
+// GetVersion gets a timetable version
+// @Summary Get timetable version
+// @Description Get a seasonal timetable with all its sailings, on sale or not
+// @Tags Timetables
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Timetable version ID"
+// @Success 200 {object} models.TimetableVersion
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /timetable-versions/{id} [get]
+func (h *TimetableVersionHandler) GetVersion(c *gin.Context) {
+	version, ok := h.authorizedVersion(c)
+	if !ok {
+		return
+	}
+
+	sailings, err := h.timetableService.ListSailings(c.Request.Context(), version.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	version.Sailings = sailings
+
+	c.JSON(http.StatusOK, version)
+}
+
+// SubmitVersion submits a draft for review
+// @Summary Submit timetable version for review
+// @Description Put a draft timetable with sailings up for review. Its sailings cannot be changed while it is in review.
+// @Tags Timetables
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Timetable version ID"
+// @Success 200 {object} models.TimetableVersion
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /timetable-versions/{id}/submit [post]
+func (h *TimetableVersionHandler) SubmitVersion(c *gin.Context) {
+	version, ok := h.authorizedVersion(c)
+	if !ok {
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	submitted, err := h.timetableService.Submit(c.Request.Context(), version.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, submitted)
+}
+
+// ReturnVersion returns a version in review to draft
+// @Summary Return timetable version to draft
+// @Description Send a timetable in review back to draft so its sailings can be changed
+// @Tags Timetables
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Timetable version ID"
+// @Success 200 {object} models.TimetableVersion
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /timetable-versions/{id}/return [post]
+func (h *TimetableVersionHandler) ReturnVersion(c *gin.Context) {
+	version, ok := h.authorizedVersion(c)
+	if !ok {
+		return
+	}
+
+	returned, err := h.timetableService.ReturnToDraft(c.Request.Context(), version.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, returned)
+}
+
+// DiffVersion compares a version with the published timetable
+// @Summary Compare timetable version with the published timetable
+// @Description Show what publishing a draft or a timetable in review would change against the upcoming sailings on sale in its season, matched by route, date and departure time: sailings added, changed and removed, and booked sailings kept as they are
+// @Tags Timetables
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Timetable version ID"
+// @Success 200 {object} models.TimetableDiff
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /timetable-versions/{id}/diff [get]
+func (h *TimetableVersionHandler) DiffVersion(c *gin.Context) {
+	version, ok := h.authorizedVersion(c)
+	if !ok {
+		return
+	}
+
+	diff, err := h.timetableService.Diff(c.Request.Context(), version.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// PublishVersion publishes a reviewed version
+// @Summary Publish timetable version
+// @Description Put a timetable in review on sale. The published timetables its season overlaps are superseded and their unbooked upcoming sailings taken off sale; booked sailings stay on sale in place of the new version's sailing for the departure. The changes are returned as a diff. Sailings clashing with their vessel's other sailings are rejected with 409.
+// @Tags Timetables
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Timetable version ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /timetable-versions/{id}/publish [post]
+func (h *TimetableVersionHandler) PublishVersion(c *gin.Context) {
+	version, ok := h.authorizedVersion(c)
+	if !ok {
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	published, diff, err := h.timetableService.Publish(c.Request.Context(), version.ID, userID)
+	if err != nil {
+		respondScheduleError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"version": published, "diff": diff})
+}
+
+// RollbackVersion rolls back a published version
+// @Summary Roll back timetable version
+// @Description Take a published timetable's upcoming sailings off sale, return it to draft and publish again the timetables it replaced. Refused while any of its upcoming departures have bookings. Restored sailings clashing with their vessel's other sailings are rejected with 409.
+// @Tags Timetables
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Timetable version ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /timetable-versions/{id}/rollback [post]
+func (h *TimetableVersionHandler) RollbackVersion(c *gin.Context) {
+	version, ok := h.authorizedVersion(c)
+	if !ok {
+		return
+	}
+
+	rolledBack, restored, err := h.timetableService.Rollback(c.Request.Context(), version.ID)
+	if err != nil {
+		respondScheduleError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"version": rolledBack, "restored": restored})
+}
+
+// authorizedVersion loads the timetable version in the path and checks
+// operator staff belong to its operator
+func (h *TimetableVersionHandler) authorizedVersion(c *gin.Context) (*models.TimetableVersion, bool) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	version, err := h.timetableService.GetVersion(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if currentUserType(c) != "system_admin" {
+		operatorID, err := currentOperatorID(c)
+		if err != nil || version.OperatorID != operatorID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access to this timetable version is not allowed"})
+			return nil, false
+		}
+	}
+
+	return version, true
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/ferryflow/boarding-mgt-system/internal/walletpass"
+	"github.com/gin-gonic/gin"
+)
+
+// Limits on what one wallet log request may write to the server log
+const (
+	walletLogMaxBody    = 64 << 10
+	walletLogMaxEntries = 20
+	walletLogMaxLength  = 500
+)
+
+type WalletHandler struct {
+	walletService   service.WalletService
+	ticketService   service.TicketService
+	scheduleService service.ScheduleService
+}
+
+func NewWalletHandler(walletService service.WalletService, ticketService service.TicketService, scheduleService service.ScheduleService) *WalletHandler {
+	return &WalletHandler{
+		walletService:   walletService,
+		ticketService:   ticketService,
+		scheduleService: scheduleService,
+	}
+}
+
+// GetTicketWalletPass downloads a ticket's wallet pass
+// @Summary Download wallet pass
+// @Description Download a signed mobile wallet pass for a ticket on a confirmed booking. The pass shows the ticket's QR code and is kept up to date by the wallet.
+// @Tags Tickets
+// @Security BearerAuth
+// @Produce application/vnd.apple.pkpass
+// @Param id path string true "Ticket ID"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tickets/{id}/wallet-pass [get]
+func (h *WalletHandler) GetTicketWalletPass(c *gin.Context) {
+	ticket, ok := h.authorizedTicket(c)
+	if !ok {
+		return
+	}
+
+	file, err := h.walletService.GetTicketPass(c.Request.Context(), ticket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "ticket-"+file.SerialNumber+".pkpass"))
+	h.writePass(c, file)
+}
+
+// UpdateSchedulePasses pushes a new version of a sailing's wallet passes
+// @Summary Update wallet passes
+// @Description Set the boarding gate or a notice (such as a delay) on every wallet pass of a sailing. Each pass gets a new version and the wallets holding it are notified. Departure times are always taken from the schedule.
+// @Tags Schedules
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param request body models.WalletPassUpdateRequest true "Pass changes"
+// @Success 200 {object} models.WalletPassUpdateResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /schedules/{id}/wallet-pass-updates [post]
+func (h *WalletHandler) UpdateSchedulePasses(c *gin.Context) {
+	schedule, ok := authorizeSchedule(c, h.scheduleService, "id")
+	if !ok {
+		return
+	}
+
+	var req models.WalletPassUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Gate == nil && req.Notice == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "gate or notice is required"})
+		return
+	}
+
+	result, err := h.walletService.UpdateSchedulePasses(c.Request.Context(), schedule.ID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RegisterDevice registers a wallet for a pass's update notifications
+// @Summary Register wallet device
+// @Description Wallet web service: called by a wallet when the pass is added. Authenticate with "Authorization: ApplePass <token>".
+// @Tags Wallet
+// @Accept json
+// @Param device_id path string true "Device library identifier"
+// @Param pass_type_id path string true "Pass type identifier"
+// @Param serial path string true "Serial number"
+// @Param request body models.WalletRegistrationRequest true "Push token"
+// @Success 201
+// @Success 200
+// @Failure 401 {object} ErrorResponse
+// @Router /wallet/v1/devices/{device_id}/registrations/{pass_type_id}/{serial} [post]
+func (h *WalletHandler) RegisterDevice(c *gin.Context) {
+	pass, ok := h.authorizedPass(c)
+	if !ok {
+		return
+	}
+
+	var req models.WalletRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.walletService.RegisterDevice(c.Request.Context(), c.Param("device_id"), pass, req.PushToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if created {
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// UnregisterDevice stops update notifications for a pass on a wallet
+// @Summary Unregister wallet device
+// @Description Wallet web service: called by a wallet when the pass is removed. Authenticate with "Authorization: ApplePass <token>".
+// @Tags Wallet
+// @Param device_id path string true "Device library identifier"
+// @Param pass_type_id path string true "Pass type identifier"
+// @Param serial path string true "Serial number"
+// @Success 200
+// @Failure 401 {object} ErrorResponse
+// @Router /wallet/v1/devices/{device_id}/registrations/{pass_type_id}/{serial} [delete]
+func (h *WalletHandler) UnregisterDevice(c *gin.Context) {
+	pass, ok := h.authorizedPass(c)
+	if !ok {
+		return
+	}
+
+	if err := h.walletService.UnregisterDevice(c.Request.Context(), c.Param("device_id"), pass); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ListUpdatedPasses lists the passes on a wallet that changed
+// @Summary List updated passes
+// @Description Wallet web service: serial numbers of the device's passes changed since the passesUpdatedSince tag. Returns 204 when none changed.
+// @Tags Wallet
+// @Produce json
+// @Param device_id path string true "Device library identifier"
+// @Param pass_type_id path string true "Pass type identifier"
+// @Param passesUpdatedSince query string false "Tag from the previous response"
+// @Success 200 {object} models.WalletSerialNumbers
+// @Success 204
+// @Router /wallet/v1/devices/{device_id}/registrations/{pass_type_id} [get]
+func (h *WalletHandler) ListUpdatedPasses(c *gin.Context) {
+	serials, err := h.walletService.ListUpdatedPasses(c.Request.Context(), c.Param("device_id"), c.Param("pass_type_id"), c.Query("passesUpdatedSince"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(serials.SerialNumbers) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, serials)
+}
+
+// GetLatestPass downloads the latest version of a pass
+// @Summary Get latest pass
+// @Description Wallet web service: the current version of a pass, or 304 when it has not changed since If-Modified-Since. Authenticate with "Authorization: ApplePass <token>".
+// @Tags Wallet
+// @Produce application/vnd.apple.pkpass
+// @Param pass_type_id path string true "Pass type identifier"
+// @Param serial path string true "Serial number"
+// @Success 200 {file} file
+// @Success 304
+// @Failure 401 {object} ErrorResponse
+// @Router /wallet/v1/passes/{pass_type_id}/{serial} [get]
+func (h *WalletHandler) GetLatestPass(c *gin.Context) {
+	pass, ok := h.authorizedPass(c)
+	if !ok {
+		return
+	}
+
+	// HTTP dates have whole seconds
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil {
+		if !pass.UpdatedAt.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	file, err := h.walletService.GetPass(c.Request.Context(), pass)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.writePass(c, file)
+}
+
+// Log records errors wallets report about the web service
+// @Summary Wallet log
+// @Description Wallet web service: error messages from wallets
+// @Tags Wallet
+// @Accept json
+// @Param request body models.WalletLogRequest true "Log messages"
+// @Success 200
+// @Router /wallet/v1/log [post]
+func (h *WalletHandler) Log(c *gin.Context) {
+	// Anyone can post here, so only a bounded amount of cleaned up text is
+	// written to the log
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, walletLogMaxBody)
+
+	var req models.WalletLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i, message := range req.Logs {
+		if i == walletLogMaxEntries {
+			log.Printf("wallet log: %d more messages dropped", len(req.Logs)-i)
+			break
+		}
+		log.Printf("wallet log: %s", cleanWalletLog(message))
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// cleanWalletLog drops control characters, so a message cannot forge log
+// lines, and shortens it to walletLogMaxLength characters
+func cleanWalletLog(message string) string {
+	message = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, message)
+
+	if runes := []rune(message); len(runes) > walletLogMaxLength {
+		message = string(runes[:walletLogMaxLength]) + "..."
+	}
+	return message
+}
+
+// authorizedTicket loads the ticket in the path and checks the caller may see
+// its booking
+func (h *WalletHandler) authorizedTicket(c *gin.Context) (*models.Ticket, bool) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	ticket, err := h.ticketService.GetTicket(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if err := authorizeBooking(c, ticket.Booking); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return ticket, true
+}
+
+// authorizedPass loads the pass in the path for a wallet presenting its
+// "ApplePass" authentication token
+func (h *WalletHandler) authorizedPass(c *gin.Context) (*models.WalletPass, bool) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "ApplePass ")
+	if !ok || token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "pass authentication token required"})
+		return nil, false
+	}
+
+	pass, err := h.walletService.AuthorizePass(c.Request.Context(), c.Param("pass_type_id"), c.Param("serial"), token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return pass, true
+}
+
+func (h *WalletHandler) writePass(c *gin.Context, file *models.WalletPassFile) {
+	c.Header("Last-Modified", file.LastModified.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, walletpass.ContentType, file.Data)
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/ferryflow/boarding-mgt-system/internal/weather"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WeatherHandler struct {
+	weatherService service.WeatherService
+}
+
+func NewWeatherHandler(weatherService service.WeatherService) *WeatherHandler {
+	return &WeatherHandler{weatherService: weatherService}
+}
+
+// CheckForecasts checks upcoming sailings against the forecasts
+// @Summary Check sailings against weather forecasts
+// @Description Check the sailings expected to leave in a period against the forecasts for their ports and route and their vessel's operating limits, as the daily weather job does. Sailings beyond limits get an advisory; open advisories of sailings back within limits are cleared.
+// @Tags Weather
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start of the period (RFC 3339, default now)"
+// @Param until query string false "End of the period (RFC 3339, default 72 hours after from)"
+// @Success 200 {object} models.WeatherCheckResult
+// @Failure 400 {object} ErrorResponse
+// @Router /weather-advisories/check [post]
+func (h *WeatherHandler) CheckForecasts(c *gin.Context) {
+	from := time.Now()
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from, expected RFC 3339"})
+			return
+		}
+		from = t
+	}
+
+	until := from.Add(weather.DefaultHorizon)
+	if v := c.Query("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until, expected RFC 3339"})
+			return
+		}
+		until = t
+	}
+
+	result, err := h.weatherService.CheckForecasts(c.Request.Context(), from, until)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListAdvisories lists weather advisories
+// @Summary List weather advisories
+// @Description List the weather advisories of the operator's sailings, soonest departure first, optionally only those with a status: open, cleared, dismissed or actioned
+// @Tags Weather
+// @Security BearerAuth
+// @Produce json
+// @Param operator_id query string false "Operator ID (system admins only)"
+// @Param status query string false "Advisory status"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} ErrorResponse
+// @Router /weather-advisories [get]
+func (h *WeatherHandler) ListAdvisories(c *gin.Context) {
+	var operatorID *uuid.UUID
+	if currentUserType(c) != "system_admin" || c.Query("operator_id") != "" {
+		id, err := scopedOperatorID(c)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		operatorID = &id
+	}
+
+	advisories, err := h.weatherService.ListAdvisories(c.Request.Context(), operatorID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"advisories": advisories})
+}
+
+// GetAdvisory gets a weather advisory
+// @Summary Get weather advisory
+// @Description Get a sailing's weather advisory with the worst forecast beyond each of its vessel's limits
+// @Tags Weather
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Weather advisory ID"
+// @Success 200 {object} models.WeatherAdvisory
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /weather-advisories/{id} [get]
+func (h *WeatherHandler) GetAdvisory(c *gin.Context) {
+	advisory, ok := h.authorizedAdvisory(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, advisory)
+}
+
+// DismissAdvisory dismisses a weather advisory
+// @Summary Dismiss weather advisory
+// @Description Close an open weather advisory without acting on it. Later forecast checks leave it closed.
+// @Tags Weather
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Weather advisory ID"
+// @Param request body models.DismissWeatherAdvisoryRequest true "Review notes"
+// @Success 200 {object} models.WeatherAdvisory
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /weather-advisories/{id}/dismiss [post]
+func (h *WeatherHandler) DismissAdvisory(c *gin.Context) {
+	advisory, ok := h.authorizedAdvisory(c)
+	if !ok {
+		return
+	}
+
+	var req models.DismissWeatherAdvisoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	dismissed, err := h.weatherService.Dismiss(c.Request.Context(), advisory, &req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dismissed)
+}
+
+// DelayForWeather delays a weather advisory's sailing
+// @Summary Delay sailing for weather
+// @Description Delay an open weather advisory's sailing with the weather reason code, notifying its passengers as any recorded delay does, and close the advisory
+// @Tags Weather
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Weather advisory ID"
+// @Param request body models.DelayForWeatherRequest true "New estimated times"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /weather-advisories/{id}/delay [post]
+func (h *WeatherHandler) DelayForWeather(c *gin.Context) {
+	advisory, ok := h.authorizedAdvisory(c)
+	if !ok {
+		return
+	}
+
+	var req models.DelayForWeatherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	actioned, delay, err := h.weatherService.Delay(c.Request.Context(), advisory, &req, userID)
+	if err != nil {
+		respondScheduleError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"advisory": actioned, "disruption": delay})
+}
+
+// CancelForWeather cancels a weather advisory's sailing
+// @Summary Cancel sailing for weather
+// @Description Cancel an open weather advisory's sailing and its bookings, offering each passenger a refund, rebooking or travel credit as any sailing cancellation does, and close the advisory
+// @Tags Weather
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Weather advisory ID"
+// @Param request body models.CancelForWeatherRequest true "Cancellation reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /weather-advisories/{id}/cancel [post]
+func (h *WeatherHandler) CancelForWeather(c *gin.Context) {
+	advisory, ok := h.authorizedAdvisory(c)
+	if !ok {
+		return
+	}
+
+	var req models.CancelForWeatherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	actioned, report, err := h.weatherService.Cancel(c.Request.Context(), advisory, &req, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"advisory": actioned, "cancellation": report})
+}
+
+// authorizedAdvisory loads the weather advisory in the path and checks
+// operator staff belong to its operator
+func (h *WeatherHandler) authorizedAdvisory(c *gin.Context) (*models.WeatherAdvisory, bool) {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	advisory, err := h.weatherService.GetAdvisory(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if currentUserType(c) != "system_admin" {
+		operatorID, err := currentOperatorID(c)
+		if err != nil || advisory.OperatorID != operatorID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access to this weather advisory is not allowed"})
+			return nil, false
+		}
+	}
+
+	return advisory, true
+}
@@ -1,14 +1,20 @@
 package api
 
 import (
+	"context"
+	"log"
 	"time"
 
 	"github.com/ferryflow/boarding-mgt-system/internal/api/handlers"
 	"github.com/ferryflow/boarding-mgt-system/internal/api/middleware"
+	"github.com/ferryflow/boarding-mgt-system/internal/boardingfeed"
 	"github.com/ferryflow/boarding-mgt-system/internal/config"
 	"github.com/ferryflow/boarding-mgt-system/internal/database"
 	"github.com/ferryflow/boarding-mgt-system/internal/repository"
 	"github.com/ferryflow/boarding-mgt-system/internal/service"
+	"github.com/ferryflow/boarding-mgt-system/internal/ticketqr"
+	"github.com/ferryflow/boarding-mgt-system/internal/walletpass"
+	"github.com/ferryflow/boarding-mgt-system/internal/weather"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -34,7 +40,7 @@ func NewServer(cfg *config.Config, db *database.DB) *Server {
 	repos := repository.NewRepositories(db)
 	
 	// Initialize services
-	services := service.NewServices(repos, cfg)
+	services := service.NewServices(repos, cfg, loadTicketKeys(cfg), loadWalletIssuer(cfg), loadBoardingBroker(cfg, db), loadWeatherProvider(cfg))
 	
 	server := &Server{
 		Router:   router,
@@ -46,9 +52,130 @@ func NewServer(cfg *config.Config, db *database.DB) *Server {
 	server.setupMiddleware()
 	server.setupRoutes()
 	
+	startScheduleLifecycle(cfg, services.Lifecycle, services.Cancellation)
+	
 	return server
 }
 
+// loadTicketKeys loads the ticket QR signing keys. Outside production a
+// throwaway key is generated when none are configured.
+func loadTicketKeys(cfg *config.Config) *ticketqr.Keyring {
+	keyring, err := ticketqr.LoadKeyring(cfg.QR.SigningKeys, cfg.QR.ActiveKeyID, cfg.QR.VerifyKeys)
+	if err == nil {
+		return keyring
+	}
+	if cfg.App.Environment == "production" || cfg.QR.SigningKeys != "" {
+		log.Fatalf("Failed to load ticket signing keys: %v", err)
+	}
+
+	log.Printf("Warning: QR_SIGNING_KEYS is not set, signing ticket codes with a temporary key")
+	keyring, err = ticketqr.GenerateKeyring("dev")
+	if err != nil {
+		log.Fatalf("Failed to generate ticket signing key: %v", err)
+	}
+	return keyring
+}
+
+// loadWalletIssuer loads the wallet pass certificate. Outside production a
+// self-signed certificate is generated when none is configured, which wallet
+// apps will not accept. Without one in production passes are not offered.
+func loadWalletIssuer(cfg *config.Config) *walletpass.Issuer {
+	issuer := &walletpass.Issuer{
+		PassTypeID:    cfg.Wallet.PassTypeID,
+		TeamID:        cfg.Wallet.TeamID,
+		WebServiceURL: cfg.Wallet.WebServiceURL,
+	}
+
+	if cfg.Wallet.CertFile != "" {
+		cert, err := walletpass.LoadCertificate(cfg.Wallet.CertFile, cfg.Wallet.KeyFile, cfg.Wallet.IntermediateFile)
+		if err != nil {
+			log.Fatalf("Failed to load wallet pass certificate: %v", err)
+		}
+		issuer.Certificate = cert
+		issuer.Notifier = walletpass.NewAPNsNotifier(cert, walletpass.APNsHost)
+		return issuer
+	}
+
+	if cfg.App.Environment == "production" {
+		log.Printf("Warning: WALLET_CERT_FILE is not set, wallet passes are disabled")
+		return nil
+	}
+
+	log.Printf("Warning: WALLET_CERT_FILE is not set, signing wallet passes with a self-signed certificate")
+	cert, err := walletpass.SelfSigned(issuer.PassTypeID, issuer.TeamID)
+	if err != nil {
+		log.Fatalf("Failed to generate wallet pass certificate: %v", err)
+	}
+	issuer.Certificate = cert
+	return issuer
+}
+
+// loadBoardingBroker picks the broker live boarding events go through. The
+// Postgres broker listens for events for as long as the process runs.
+func loadBoardingBroker(cfg *config.Config, db *database.DB) boardingfeed.Broker {
+	switch cfg.Feed.Broker {
+	case "memory":
+		return boardingfeed.NewHub()
+	case "postgres":
+		broker := boardingfeed.NewPostgresBroker(db.Pool)
+		go broker.Listen(context.Background())
+		return broker
+	}
+
+	log.Fatalf("Unknown BOARDING_FEED_BROKER %q, use memory or postgres", cfg.Feed.Broker)
+	return nil
+}
+
+// loadWeatherProvider picks where forecasts come from, or nil when they are
+// not checked
+func loadWeatherProvider(cfg *config.Config) weather.Provider {
+	provider, err := weather.NewProvider(&cfg.Weather)
+	if err != nil {
+		log.Fatalf("Failed to load weather provider: %v", err)
+	}
+	return provider
+}
+
+// startScheduleLifecycle opens boarding on and departs sailings on the clock
+// for as long as the process runs, and refunds passengers on cancelled
+// sailings whose offer has lapsed. Every API instance may run it; each
+// sailing only moves once and each offer is only refunded once.
+func startScheduleLifecycle(cfg *config.Config, lifecycle service.ScheduleLifecycleService, cancellations service.ScheduleCancellationService) {
+	if cfg.Lifecycle.Interval == "off" {
+		return
+	}
+
+	interval, err := time.ParseDuration(cfg.Lifecycle.Interval)
+	if err != nil || interval <= 0 {
+		log.Fatalf("Invalid SCHEDULE_LIFECYCLE_INTERVAL %q, use a duration such as 1m or off", cfg.Lifecycle.Interval)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			_, err := cancellations.RefundLapsedOffers(ctx, now)
+			cancel()
+			if err != nil {
+				log.Printf("Failed to refund lapsed cancellation offers: %v", err)
+			}
+
+			ctx, cancel = context.WithTimeout(context.Background(), interval)
+			run, err := lifecycle.Advance(ctx, now)
+			cancel()
+			if err != nil {
+				log.Printf("Failed to advance sailings: %v", err)
+				continue
+			}
+			for _, failure := range run.Failures {
+				log.Printf("Failed to move sailing %s to %s: %s", failure.ScheduleID, failure.Status, failure.Error)
+			}
+		}
+	}()
+}
+
 func (s *Server) setupMiddleware() {
 	// Recovery middleware
 	s.Router.Use(gin.Recovery())
@@ -90,9 +217,29 @@ func (s *Server) setupRoutes() {
 	vesselHandler := handlers.NewVesselHandler(s.services.Vessel)
 	routeHandler := handlers.NewRouteHandler(s.services.Route)
 	scheduleHandler := handlers.NewScheduleHandler(s.services.Schedule)
+	templateHandler := handlers.NewScheduleTemplateHandler(s.services.Template)
+	timetableHandler := handlers.NewTimetableVersionHandler(s.services.Timetable)
+	gtfsHandler := handlers.NewGTFSHandler(s.services.GTFS)
 	bookingHandler := handlers.NewBookingHandler(s.services.Booking)
-	ticketHandler := handlers.NewTicketHandler(s.services.Ticket)
+	ticketHandler := handlers.NewTicketHandler(s.services.Ticket, s.services.Booking)
+	gateHandler := handlers.NewGateHandler(s.services.Gate, s.services.Schedule)
+	feedHandler := handlers.NewBoardingFeedHandler(s.services.Feed, s.services.Schedule)
+	scannerHandler := handlers.NewScannerHandler(s.services.Scanner)
+	walletHandler := handlers.NewWalletHandler(s.services.Wallet, s.services.Ticket, s.services.Schedule)
+	manifestHandler := handlers.NewManifestHandler(s.services.Manifest, s.services.Schedule)
+	lifecycleHandler := handlers.NewScheduleLifecycleHandler(s.services.Lifecycle, s.services.Schedule)
+	noShowHandler := handlers.NewNoShowHandler(s.services.NoShow, s.services.Schedule)
+	cancellationHandler := handlers.NewScheduleCancellationHandler(s.services.Cancellation, s.services.Schedule)
+	notificationHandler := handlers.NewNotificationHandler(s.services.Notification)
+	disruptionHandler := handlers.NewDisruptionHandler(s.services.Disruption, s.services.Schedule)
 	userHandler := handlers.NewUserHandler(s.services.User)
+	shiftHandler := handlers.NewShiftHandler(s.services.Shift, s.services.Booking)
+	settlementHandler := handlers.NewSettlementHandler(s.services.Settlement)
+	ledgerHandler := handlers.NewLedgerHandler(s.services.Ledger)
+	invoiceHandler := handlers.NewInvoiceHandler(s.services.Invoice, s.services.Booking)
+	paymentHandler := handlers.NewPaymentHandler(s.services.Booking)
+	agencyHandler := handlers.NewAgencyHandler(s.services.Agency, s.services.Booking)
+	weatherHandler := handlers.NewWeatherHandler(s.services.Weather)
 	
 	// Public routes (no authentication required)
 	public := v1.Group("")
@@ -113,6 +260,9 @@ func (s *Server) setupRoutes() {
 		// Public route information
 		public.GET("/routes", routeHandler.ListRoutes)
 		public.GET("/routes/:id", routeHandler.GetRoute)
+		
+		// Ticket code verification keys for offline scanners
+		public.GET("/tickets/verification-keys", ticketHandler.ListVerificationKeys)
 	}
 	
 	// Protected routes (authentication required)
@@ -129,11 +279,33 @@ func (s *Server) setupRoutes() {
 		protected.POST("/bookings", bookingHandler.CreateBooking)
 		protected.GET("/bookings/:id", bookingHandler.GetBooking)
 		protected.POST("/bookings/:id/cancel", bookingHandler.CancelBooking)
+
+		// Booking payments
+		protected.GET("/bookings/:id/payments", paymentHandler.GetBookingBalance)
+		protected.POST("/bookings/:id/payments", paymentHandler.AddPayment)
+		
+		// Invoices and credit notes (booking owner or operator staff)
+		protected.GET("/bookings/:id/invoice", invoiceHandler.GetBookingInvoice)
+		protected.GET("/bookings/:id/invoices", invoiceHandler.ListBookingInvoices)
+		protected.GET("/bookings/:id/invoices/:invoice_id", invoiceHandler.GetBookingDocument)
 		
 		// Tickets
 		protected.GET("/tickets/my", ticketHandler.GetMyTickets)
 		protected.GET("/tickets/:id", ticketHandler.GetTicket)
 		protected.GET("/tickets/:id/qr", ticketHandler.GetTicketQR)
+		protected.GET("/tickets/:id/boarding-pass", ticketHandler.GetTicketBoardingPass)
+		protected.GET("/tickets/:id/wallet-pass", walletHandler.GetTicketWalletPass)
+		protected.PUT("/tickets/:id/identity", ticketHandler.UpdatePassengerIdentity)
+		protected.GET("/bookings/:id/boarding-passes", ticketHandler.GetBookingBoardingPasses)
+		protected.GET("/travel-credits/my", noShowHandler.GetMyTravelCredits)
+
+		// Bookings on cancelled sailings
+		protected.GET("/cancellation-offers/my", cancellationHandler.GetMyCancellationOffers)
+		protected.POST("/cancellation-offers/:id/choose", cancellationHandler.ChooseCancellationOption)
+
+		// Notifications
+		protected.GET("/notifications/my", notificationHandler.ListMyNotifications)
+		protected.PUT("/notifications/:id/read", notificationHandler.MarkNotificationRead)
 	}
 	
 	// Admin routes (operator/admin authentication required)
@@ -171,11 +343,97 @@ func (s *Server) setupRoutes() {
 		admin.PUT("/schedules/:id", scheduleHandler.UpdateSchedule)
 		admin.DELETE("/schedules/:id", scheduleHandler.DeleteSchedule)
 		admin.POST("/schedules/:id/cancel", scheduleHandler.CancelSchedule)
+		admin.POST("/schedules/:id/boarding", lifecycleHandler.OpenBoarding)
+		admin.POST("/schedules/:id/departure", lifecycleHandler.ConfirmDeparture)
+		admin.POST("/schedules/:id/arrival", lifecycleHandler.ConfirmArrival)
+		admin.POST("/schedules/:id/wallet-pass-updates", middleware.RequireRole("operator_admin", "system_admin"), walletHandler.UpdateSchedulePasses)
+		admin.POST("/schedules/:id/no-shows", middleware.RequireRole("operator_admin", "system_admin"), noShowHandler.ProcessNoShows)
+		admin.GET("/schedules/:id/departure-summary", noShowHandler.GetDepartureSummary)
+		admin.POST("/schedules/:id/cancellation", middleware.RequireRole("operator_admin", "system_admin"), cancellationHandler.CancelSchedule)
+		admin.GET("/schedules/:id/cancellation", cancellationHandler.GetCancellationReport)
+		admin.POST("/schedules/:id/disruptions", disruptionHandler.RecordDisruption)
+		admin.GET("/schedules/:id/disruptions", disruptionHandler.ListDisruptions)
+		admin.POST("/schedules/:id/rebookings", middleware.RequireRole("operator_admin", "system_admin"), disruptionHandler.MoveBookings)
+		admin.PUT("/schedules/:id/vessel", middleware.RequireRole("operator_admin", "system_admin"), disruptionHandler.ChangeVessel)
+		
+		// Recurring timetables
+		admin.GET("/schedule-templates", middleware.RequireRole("operator_admin", "system_admin"), templateHandler.ListTemplates)
+		admin.POST("/schedule-templates", middleware.RequireRole("operator_admin", "system_admin"), templateHandler.CreateTemplate)
+		admin.GET("/schedule-templates/:id", middleware.RequireRole("operator_admin", "system_admin"), templateHandler.GetTemplate)
+		admin.PUT("/schedule-templates/:id", middleware.RequireRole("operator_admin", "system_admin"), templateHandler.UpdateTemplate)
+		admin.POST("/schedule-templates/:id/generate", middleware.RequireRole("operator_admin", "system_admin"), templateHandler.GenerateSchedules)
+		admin.GET("/timetables/gtfs", middleware.RequireRole("operator_admin", "system_admin"), gtfsHandler.ExportFeed)
+		admin.POST("/timetables/gtfs", middleware.RequireRole("operator_admin", "system_admin"), gtfsHandler.ImportFeed)
+		
+		// Seasonal timetable versions
+		admin.GET("/timetable-versions", middleware.RequireRole("operator_admin", "system_admin"), timetableHandler.ListVersions)
+		admin.POST("/timetable-versions", middleware.RequireRole("operator_admin", "system_admin"), timetableHandler.CreateVersion)
+		admin.GET("/timetable-versions/:id", middleware.RequireRole("operator_admin", "system_admin"), timetableHandler.GetVersion)
+		admin.POST("/timetable-versions/:id/submit", middleware.RequireRole("operator_admin", "system_admin"), timetableHandler.SubmitVersion)
+		admin.POST("/timetable-versions/:id/return", middleware.RequireRole("operator_admin", "system_admin"), timetableHandler.ReturnVersion)
+		admin.GET("/timetable-versions/:id/diff", middleware.RequireRole("operator_admin", "system_admin"), timetableHandler.DiffVersion)
+		admin.POST("/timetable-versions/:id/publish", middleware.RequireRole("operator_admin", "system_admin"), timetableHandler.PublishVersion)
+		admin.POST("/timetable-versions/:id/rollback", middleware.RequireRole("operator_admin", "system_admin"), timetableHandler.RollbackVersion)
+		
+		// Weather advisories
+		admin.GET("/weather-advisories", weatherHandler.ListAdvisories)
+		admin.POST("/weather-advisories/check", middleware.RequireRole("system_admin"), weatherHandler.CheckForecasts)
+		admin.GET("/weather-advisories/:id", weatherHandler.GetAdvisory)
+		admin.POST("/weather-advisories/:id/dismiss", middleware.RequireRole("operator_admin", "system_admin"), weatherHandler.DismissAdvisory)
+		admin.POST("/weather-advisories/:id/delay", middleware.RequireRole("operator_admin", "system_admin"), weatherHandler.DelayForWeather)
+		admin.POST("/weather-advisories/:id/cancel", middleware.RequireRole("operator_admin", "system_admin"), weatherHandler.CancelForWeather)
 		
 		// Booking management
 		admin.GET("/bookings", bookingHandler.ListBookings)
 		admin.PUT("/bookings/:id", bookingHandler.UpdateBooking)
 		
+		// Ticket code verification
+		admin.POST("/tickets/verify", ticketHandler.VerifyQRCode)
+		
+		// Boarding gate
+		admin.POST("/gate/check-in", gateHandler.CheckIn)
+		admin.POST("/gate/board", gateHandler.Board)
+		admin.GET("/gate/schedules/:schedule_id/scans", gateHandler.ListScans)
+		admin.GET("/gate/schedules/:schedule_id/live", feedHandler.StreamSchedule)
+		admin.GET("/gate/ports/:port_id/live", feedHandler.StreamPort)
+		
+		// Scanner devices
+		admin.GET("/scanner-devices", middleware.RequireRole("operator_admin", "system_admin"), scannerHandler.ListDevices)
+		admin.POST("/scanner-devices", middleware.RequireRole("operator_admin", "system_admin"), scannerHandler.RegisterDevice)
+		admin.GET("/scanner-devices/:id", middleware.RequireRole("operator_admin", "system_admin"), scannerHandler.GetDevice)
+		admin.PUT("/scanner-devices/:id", middleware.RequireRole("operator_admin", "system_admin"), scannerHandler.UpdateDevice)
+		admin.POST("/scanner-devices/:id/rotate-key", middleware.RequireRole("operator_admin", "system_admin"), scannerHandler.RotateKey)
+		admin.POST("/scanner-devices/:id/schedules", middleware.RequireRole("operator_admin", "system_admin"), scannerHandler.AssignSchedules)
+		admin.DELETE("/scanner-devices/:id/schedules/:schedule_id", middleware.RequireRole("operator_admin", "system_admin"), scannerHandler.UnassignSchedule)
+		
+		// Agent shifts and POS sales
+		admin.POST("/shifts", shiftHandler.OpenShift)
+		admin.GET("/shifts/current", shiftHandler.GetCurrentShift)
+		admin.POST("/shifts/:id/close", shiftHandler.CloseShift)
+		admin.GET("/shifts/:id/report", shiftHandler.GetShiftReport)
+		admin.GET("/shifts/open", middleware.RequireRole("operator_admin", "system_admin"), shiftHandler.ListOpenShifts)
+		admin.GET("/shifts/unreconciled", middleware.RequireRole("operator_admin", "system_admin"), shiftHandler.ListUnreconciledShifts)
+		admin.POST("/shifts/:id/reconcile", middleware.RequireRole("operator_admin", "system_admin"), shiftHandler.ReconcileShift)
+		admin.POST("/pos/bookings", shiftHandler.CreatePOSBooking)
+		admin.POST("/pos/bookings/:id/refund", shiftHandler.RefundPOSBooking)
+		admin.POST("/refunds/:id/process", middleware.RequireRole("operator_admin", "system_admin"), paymentHandler.ProcessRefund)
+		
+		// Travel agencies
+		admin.GET("/agencies", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.ListAgencies)
+		admin.POST("/agencies", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.CreateAgency)
+		admin.GET("/agencies/:id", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.GetAgency)
+		admin.PUT("/agencies/:id", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.UpdateAgency)
+		admin.POST("/agencies/:id/commission-rules", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.AddCommissionRule)
+		admin.DELETE("/agencies/:id/commission-rules/:rule_id", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.DeleteCommissionRule)
+		admin.POST("/agencies/:id/agents", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.AssignAgent)
+		admin.DELETE("/agencies/:id/agents/:user_id", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.RemoveAgent)
+		admin.GET("/agencies/:id/transactions", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.ListTransactions)
+		admin.POST("/agencies/:id/transactions", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.RecordTransaction)
+		admin.GET("/agencies/:id/statements", middleware.RequireRole("operator_admin", "system_admin"), agencyHandler.GetStatement)
+		admin.GET("/agency", middleware.RequireRole("agent"), agencyHandler.GetMyAgency)
+		admin.GET("/agency/statements", middleware.RequireRole("agent"), agencyHandler.GetMyStatement)
+		admin.POST("/agency/bookings", middleware.RequireRole("agent"), agencyHandler.CreateAgencyBooking)
+		
 		// User management
 		admin.GET("/users", middleware.RequireRole("operator_admin", "system_admin"), userHandler.ListUsers)
 		admin.GET("/users/:id", userHandler.GetUser)
@@ -186,6 +444,32 @@ func (s *Server) setupRoutes() {
 		admin.GET("/reports/bookings", bookingHandler.GetBookingReport)
 		admin.GET("/reports/revenue", bookingHandler.GetRevenueReport)
 		admin.GET("/reports/manifest/:schedule_id", scheduleHandler.GetManifest)
+		admin.GET("/reports/manifest/:schedule_id/export", manifestHandler.ExportManifest)
+		admin.GET("/reports/manifest/:schedule_id/completeness", manifestHandler.GetCompleteness)
+		admin.GET("/reports/no-shows", middleware.RequireRole("operator_admin", "system_admin"), noShowHandler.GetNoShowReport)
+		admin.GET("/reports/on-time-performance", middleware.RequireRole("operator_admin", "system_admin"), disruptionHandler.GetOnTimeReport)
+		
+		// Ledger and accounting export
+		admin.GET("/ledger/accounts", middleware.RequireRole("operator_admin", "system_admin"), ledgerHandler.ListAccounts)
+		admin.GET("/ledger/trial-balance", middleware.RequireRole("operator_admin", "system_admin"), ledgerHandler.GetTrialBalance)
+		admin.GET("/ledger/export", middleware.RequireRole("operator_admin", "system_admin"), ledgerHandler.ExportJournal)
+	}
+	
+	// Scanner device sync (device key authentication)
+	device := v1.Group("/device")
+	{
+		device.GET("/bundle", scannerHandler.GetBundle)
+		device.POST("/scans", scannerHandler.UploadScans)
+	}
+	
+	// Wallet pass web service (pass authentication token)
+	wallet := v1.Group("/wallet/v1")
+	{
+		wallet.POST("/devices/:device_id/registrations/:pass_type_id/:serial", walletHandler.RegisterDevice)
+		wallet.DELETE("/devices/:device_id/registrations/:pass_type_id/:serial", walletHandler.UnregisterDevice)
+		wallet.GET("/devices/:device_id/registrations/:pass_type_id", walletHandler.ListUpdatedPasses)
+		wallet.GET("/passes/:pass_type_id/:serial", walletHandler.GetLatestPass)
+		wallet.POST("/log", walletHandler.Log)
 	}
 	
 	// System admin only routes
@@ -198,6 +482,17 @@ func (s *Server) setupRoutes() {
 		
 		// System stats
 		systemAdmin.GET("/system/stats", s.getSystemStats)
+		
+		// Settlement reconciliation
+		systemAdmin.GET("/settlements/providers", settlementHandler.ListProviders)
+		systemAdmin.POST("/settlements/providers", settlementHandler.CreateProvider)
+		systemAdmin.PUT("/settlements/providers/:code", settlementHandler.UpdateProvider)
+		systemAdmin.POST("/settlements/imports", settlementHandler.ImportSettlement)
+		systemAdmin.GET("/settlements/imports", settlementHandler.ListImports)
+		systemAdmin.GET("/settlements/imports/:id", settlementHandler.GetImport)
+		systemAdmin.GET("/settlements/unreconciled", settlementHandler.GetUnreconciledReport)
+		systemAdmin.POST("/settlements/payments/:id/resolve", settlementHandler.ResolvePayment)
+		systemAdmin.POST("/settlements/lines/:id/resolve", settlementHandler.ResolveLine)
 	}
 }
 
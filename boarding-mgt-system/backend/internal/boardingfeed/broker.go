@@ -0,0 +1,87 @@
+package boardingfeed
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// SubscriberBuffer is how many events a subscriber may fall behind by before
+// it starts missing them
+const SubscriberBuffer = 64
+
+// Broker delivers boarding events to the dashboards subscribed to them
+type Broker interface {
+	Publish(ctx context.Context, event *models.BoardingEvent) error
+	Subscribe(filter Filter) *Subscription
+}
+
+// Subscription receives the events matching its filter until it is closed
+type Subscription struct {
+	Events <-chan *models.BoardingEvent
+
+	filter Filter
+	events chan *models.BoardingEvent
+	hub    *Hub
+}
+
+// Close stops the subscription and closes its Events channel
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// Hub is a Broker within a single API instance
+type Hub struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: map[*Subscription]struct{}{}}
+}
+
+// Publish delivers an event to this instance's subscribers
+func (h *Hub) Publish(ctx context.Context, event *models.BoardingEvent) error {
+	h.Deliver(event)
+	return nil
+}
+
+// Deliver hands an event to every matching subscriber. Subscribers that have
+// fallen SubscriberBuffer events behind miss it rather than hold up the
+// gate; the next counts event brings them up to date.
+func (h *Hub) Deliver(event *models.BoardingEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+func (h *Hub) Subscribe(filter Filter) *Subscription {
+	events := make(chan *models.BoardingEvent, SubscriberBuffer)
+	sub := &Subscription{Events: events, filter: filter, events: events, hub: h}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,118 @@
+// Package boardingfeed pushes live boarding progress to supervisors'
+// dashboards: each sailing's counts, every gate scan and headcount alerts,
+// for one sailing or every sailing leaving a port.
+package boardingfeed
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+)
+
+// Event types
+const (
+	EventCounts = "counts"
+	EventScan   = "scan"
+	EventAlert  = "alert"
+)
+
+// Alert kinds
+const (
+	// AlertNearCapacity is raised when boarding reaches NearCapacityRate of
+	// the sailing's capacity
+	AlertNearCapacity = "near_capacity"
+	// AlertOverCapacity is raised when more passengers have boarded than the
+	// sailing has room for, which supervisor overrides can cause
+	AlertOverCapacity = "over_capacity"
+	// AlertAllAboard is raised when every booked passenger has boarded
+	AlertAllAboard = "all_aboard"
+)
+
+// NearCapacityRate is the share of capacity boarded that raises
+// AlertNearCapacity
+const NearCapacityRate = 0.9
+
+// Filter selects the events a dashboard receives. Nil fields match anything.
+type Filter struct {
+	ScheduleID *uuid.UUID
+	PortID     *uuid.UUID
+	OperatorID *uuid.UUID
+}
+
+// Match reports whether an event passes the filter
+func (f Filter) Match(event *models.BoardingEvent) bool {
+	switch {
+	case f.ScheduleID != nil && *f.ScheduleID != event.ScheduleID:
+		return false
+	case f.PortID != nil && *f.PortID != event.PortID:
+		return false
+	case f.OperatorID != nil && *f.OperatorID != event.OperatorID:
+		return false
+	}
+	return true
+}
+
+// CountsEvent wraps a sailing's counts in an event
+func CountsEvent(counts *models.BoardingCounts, at time.Time) *models.BoardingEvent {
+	event := newEvent(EventCounts, counts, at)
+	event.Counts = counts
+	return event
+}
+
+// ScanEvent wraps a gate scan of a sailing in an event
+func ScanEvent(counts *models.BoardingCounts, scan *models.BoardingScan, at time.Time) *models.BoardingEvent {
+	event := newEvent(EventScan, counts, at)
+	event.Scan = scan
+	return event
+}
+
+// AlertEvent wraps an alert about a sailing in an event
+func AlertEvent(counts *models.BoardingCounts, alert models.BoardingAlert, at time.Time) *models.BoardingEvent {
+	event := newEvent(EventAlert, counts, at)
+	event.Alert = &alert
+	return event
+}
+
+func newEvent(eventType string, counts *models.BoardingCounts, at time.Time) *models.BoardingEvent {
+	return &models.BoardingEvent{
+		Type:       eventType,
+		ScheduleID: counts.ScheduleID,
+		OperatorID: counts.OperatorID,
+		PortID:     counts.PortID,
+		At:         at,
+	}
+}
+
+// Alerts returns the alerts raised by a sailing's counts moving from before
+// to after. An alert is raised once, as its threshold is crossed.
+func Alerts(before, after models.BoardingCounts) []models.BoardingAlert {
+	alerts := []models.BoardingAlert{}
+
+	if after.Capacity > 0 {
+		near := int(math.Ceil(float64(after.Capacity) * NearCapacityRate))
+		if before.Boarded < near && after.Boarded >= near && after.Boarded <= after.Capacity {
+			alerts = append(alerts, models.BoardingAlert{
+				Kind:    AlertNearCapacity,
+				Message: fmt.Sprintf("%d of %d places boarded", after.Boarded, after.Capacity),
+			})
+		}
+		if before.Boarded <= after.Capacity && after.Boarded > after.Capacity {
+			alerts = append(alerts, models.BoardingAlert{
+				Kind:    AlertOverCapacity,
+				Message: fmt.Sprintf("%d passengers boarded, capacity is %d", after.Boarded, after.Capacity),
+			})
+		}
+	}
+
+	if after.TotalPassengers > 0 && before.Boarded < after.TotalPassengers && after.Boarded >= after.TotalPassengers {
+		alerts = append(alerts, models.BoardingAlert{
+			Kind:    AlertAllAboard,
+			Message: fmt.Sprintf("All %d booked passengers have boarded", after.TotalPassengers),
+		})
+	}
+
+	return alerts
+}
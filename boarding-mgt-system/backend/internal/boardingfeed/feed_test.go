@@ -0,0 +1,86 @@
+package boardingfeed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func kinds(alerts []models.BoardingAlert) []string {
+	out := []string{}
+	for _, alert := range alerts {
+		out = append(out, alert.Kind)
+	}
+	return out
+}
+
+func TestAlerts(t *testing.T) {
+	counts := func(boarded int) models.BoardingCounts {
+		return models.BoardingCounts{Capacity: 100, TotalPassengers: 95, Boarded: boarded}
+	}
+
+	assert.Empty(t, Alerts(counts(50), counts(51)))
+	assert.Equal(t, []string{AlertNearCapacity}, kinds(Alerts(counts(89), counts(90))))
+	assert.Empty(t, Alerts(counts(90), counts(91)), "near capacity is raised once")
+	assert.Equal(t, []string{AlertAllAboard}, kinds(Alerts(counts(94), counts(95))))
+	assert.Equal(t, []string{AlertOverCapacity}, kinds(Alerts(counts(100), counts(101))))
+	assert.Empty(t, Alerts(counts(101), counts(102)))
+
+	// A sailing with no capacity set only reports its headcount
+	assert.Equal(t, []string{AlertAllAboard}, kinds(Alerts(
+		models.BoardingCounts{TotalPassengers: 2, Boarded: 1},
+		models.BoardingCounts{TotalPassengers: 2, Boarded: 2},
+	)))
+}
+
+func TestFilter(t *testing.T) {
+	scheduleID, portID, operatorID := uuid.New(), uuid.New(), uuid.New()
+	counts := &models.BoardingCounts{ScheduleID: scheduleID, PortID: portID, OperatorID: operatorID}
+	event := CountsEvent(counts, time.Now())
+
+	other := uuid.New()
+	assert.True(t, Filter{}.Match(event))
+	assert.True(t, Filter{ScheduleID: &scheduleID}.Match(event))
+	assert.True(t, Filter{PortID: &portID, OperatorID: &operatorID}.Match(event))
+	assert.False(t, Filter{ScheduleID: &other}.Match(event))
+	assert.False(t, Filter{PortID: &portID, OperatorID: &other}.Match(event))
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	scheduleID := uuid.New()
+	counts := &models.BoardingCounts{ScheduleID: scheduleID, PortID: uuid.New()}
+
+	mine := hub.Subscribe(Filter{ScheduleID: &scheduleID})
+	defer mine.Close()
+	other := uuid.New()
+	theirs := hub.Subscribe(Filter{ScheduleID: &other})
+
+	require.NoError(t, hub.Publish(context.Background(), CountsEvent(counts, time.Now())))
+
+	select {
+	case event := <-mine.Events:
+		assert.Equal(t, EventCounts, event.Type)
+		assert.Equal(t, scheduleID, event.Counts.ScheduleID)
+	default:
+		t.Fatal("subscriber did not receive the event")
+	}
+	assert.Len(t, theirs.Events, 0)
+
+	// A closed subscription's channel is closed and closing again is harmless
+	theirs.Close()
+	theirs.Close()
+	_, ok := <-theirs.Events
+	assert.False(t, ok)
+
+	// A subscriber that falls behind misses events instead of blocking
+	for i := 0; i < SubscriberBuffer+10; i++ {
+		hub.Deliver(CountsEvent(counts, time.Now()))
+	}
+	assert.Len(t, mine.Events, SubscriberBuffer)
+}
@@ -0,0 +1,93 @@
+package boardingfeed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Channel is the Postgres notification channel boarding events are sent on
+const Channel = "boarding_events"
+
+// maxPayload is kept under Postgres' 8000 byte NOTIFY payload limit
+const maxPayload = 7900
+
+// PostgresBroker sends events through Postgres NOTIFY and delivers what it
+// hears on LISTEN to this instance's subscribers, so dashboards connected to
+// any API instance see scans made through every instance
+type PostgresBroker struct {
+	pool *pgxpool.Pool
+	hub  *Hub
+}
+
+func NewPostgresBroker(pool *pgxpool.Pool) *PostgresBroker {
+	return &PostgresBroker{pool: pool, hub: NewHub()}
+}
+
+// Publish notifies every listening instance, this one included
+func (b *PostgresBroker) Publish(ctx context.Context, event *models.BoardingEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode boarding event: %w", err)
+	}
+	if len(payload) > maxPayload {
+		return fmt.Errorf("boarding event is too large to send (%d bytes)", len(payload))
+	}
+
+	if _, err := b.pool.Exec(ctx, "SELECT pg_notify($1, $2)", Channel, string(payload)); err != nil {
+		return fmt.Errorf("failed to send boarding event: %w", err)
+	}
+	return nil
+}
+
+func (b *PostgresBroker) Subscribe(filter Filter) *Subscription {
+	return b.hub.Subscribe(filter)
+}
+
+// Listen holds a connection listening on Channel until ctx is done,
+// reconnecting after errors. Events sent while it reconnects are lost.
+func (b *PostgresBroker) Listen(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := b.listen(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Boarding event listener stopped, reconnecting: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+func (b *PostgresBroker) listen(ctx context.Context) error {
+	conn, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// The connection is listening, so it is closed rather than returned to
+	// the pool
+	pgConn := conn.Hijack()
+	defer pgConn.Close(context.Background())
+
+	if _, err := pgConn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	for {
+		notification, err := pgConn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		event := &models.BoardingEvent{}
+		if err := json.Unmarshal([]byte(notification.Payload), event); err != nil {
+			log.Printf("Ignoring malformed boarding event: %v", err)
+			continue
+		}
+		b.hub.Deliver(event)
+	}
+}
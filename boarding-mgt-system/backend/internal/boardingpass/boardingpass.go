@@ -0,0 +1,120 @@
+// Package boardingpass renders ticket QR codes as images and prints boarding
+// passes.
+package boardingpass
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Image sizes in pixels accepted for QR codes
+const (
+	DefaultSize = 256
+	MinSize     = 64
+	MaxSize     = 2048
+)
+
+// Image formats
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// DefaultBrandColor is used when an operator has not set brand_color
+const DefaultBrandColor = "#1F3A5F"
+
+// ContentType returns the MIME type of an image format
+func ContentType(format string) string {
+	if format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// QRCode renders a ticket code as a square PNG or SVG image of size pixels
+func QRCode(content, format string, size int) ([]byte, error) {
+	if size < MinSize || size > MaxSize {
+		return nil, fmt.Errorf("size must be between %d and %d pixels", MinSize, MaxSize)
+	}
+
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	switch format {
+	case FormatPNG:
+		image, err := code.PNG(size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render QR code: %w", err)
+		}
+		return image, nil
+	case FormatSVG:
+		return svg(code.Bitmap(), size), nil
+	default:
+		return nil, fmt.Errorf("unsupported image format %q", format)
+	}
+}
+
+// svg draws a QR bitmap, quiet zone included, one unit per module and scaled
+// to size by the viewBox
+func svg(bitmap [][]bool, size int) []byte {
+	modules := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/><path fill="#000000" d="`, modules, modules)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			// Merge horizontal runs of dark modules into one rectangle
+			run := 1
+			for x+run < len(row) && row[x+run] {
+				run++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", x, y, run, run)
+			x += run - 1
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
+
+// LocalTime combines a schedule's date and clock time in a port's time zone.
+// Unknown zones fall back to UTC.
+func LocalTime(date, clock time.Time, timezone string) time.Time {
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		location = time.UTC
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, location)
+}
+
+// BrandColor reads an operator's brand_color setting, a "#RRGGBB" hex color
+func BrandColor(settings map[string]interface{}) string {
+	if color, ok := settings["brand_color"].(string); ok {
+		if _, _, _, err := ParseColor(color); err == nil {
+			return strings.ToUpper(color)
+		}
+	}
+	return DefaultBrandColor
+}
+
+// ParseColor splits a "#RRGGBB" hex color into its red, green and blue parts
+func ParseColor(color string) (int, int, int, error) {
+	if len(color) != 7 || color[0] != '#' {
+		return 0, 0, 0, fmt.Errorf("invalid color %q", color)
+	}
+	value, err := strconv.ParseUint(color[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid color %q", color)
+	}
+	return int(value >> 16 & 0xFF), int(value >> 8 & 0xFF), int(value & 0xFF), nil
+}
@@ -0,0 +1,90 @@
+package boardingpass
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCode = "k1.AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyA.c2lnbmF0dXJl"
+
+func TestQRCode(t *testing.T) {
+	image, err := QRCode(testCode, FormatPNG, 300)
+	require.NoError(t, err)
+	decoded, err := png.Decode(bytes.NewReader(image))
+	require.NoError(t, err)
+	assert.Equal(t, 300, decoded.Bounds().Dx())
+	assert.Equal(t, 300, decoded.Bounds().Dy())
+
+	svg, err := QRCode(testCode, FormatSVG, 200)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(svg, []byte("<svg ")))
+	assert.Contains(t, string(svg), `width="200" height="200"`)
+	assert.True(t, bytes.HasSuffix(svg, []byte("</svg>")))
+
+	_, err = QRCode(testCode, FormatPNG, MaxSize+1)
+	assert.Error(t, err)
+	_, err = QRCode(testCode, FormatPNG, MinSize-1)
+	assert.Error(t, err)
+	_, err = QRCode(testCode, "gif", DefaultSize)
+	assert.Error(t, err)
+}
+
+func TestLocalTime(t *testing.T) {
+	date := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	clock := time.Date(0, 1, 1, 8, 30, 0, 0, time.UTC)
+
+	local := LocalTime(date, clock, "Europe/Athens")
+	assert.Equal(t, "2024-07-01 08:30 EEST", local.Format("2006-01-02 15:04 MST"))
+	assert.Equal(t, time.Date(2024, 7, 1, 5, 30, 0, 0, time.UTC), local.UTC())
+
+	assert.Equal(t, time.UTC, LocalTime(date, clock, "Nowhere/Unknown").Location())
+}
+
+func TestBrandColor(t *testing.T) {
+	assert.Equal(t, "#00AA33", BrandColor(map[string]interface{}{"brand_color": "#00aa33"}))
+	assert.Equal(t, DefaultBrandColor, BrandColor(map[string]interface{}{"brand_color": "green"}))
+	assert.Equal(t, DefaultBrandColor, BrandColor(nil))
+}
+
+func TestWritePDF(t *testing.T) {
+	athens, err := time.LoadLocation("Europe/Athens")
+	require.NoError(t, err)
+
+	pass := &models.BoardingPass{
+		TicketID:          uuid.New(),
+		BookingReference:  "FF-ABC123",
+		OperatorName:      "Égée Lines",
+		OperatorCode:      "EGL",
+		BrandColor:        "#0055A4",
+		RouteName:         "Piraeus - Mykonos",
+		DeparturePort:     "Piraeus",
+		DeparturePortCode: "PIR",
+		ArrivalPort:       "Mykonos",
+		ArrivalPortCode:   "JMK",
+		VesselName:        "Blue Star",
+		Departure:         time.Date(2024, 7, 1, 7, 30, 0, 0, athens),
+		Arrival:           time.Date(2024, 7, 1, 12, 5, 0, 0, athens),
+		PassengerName:     "Zoë Papadopoulou",
+		PassengerType:     "adult",
+		SeatNumber:        "12A",
+		QRCode:            testCode,
+	}
+	child := *pass
+	child.TicketID = uuid.New()
+	child.PassengerType = "child"
+	child.SeatNumber = ""
+	child.QRCode = testCode + "x"
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, []*models.BoardingPass{pass, &child}))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+
+	assert.Error(t, WritePDF(&buf, nil))
+}
@@ -0,0 +1,136 @@
+package boardingpass
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/go-pdf/fpdf"
+)
+
+// WritePDF renders boarding passes as an A4 PDF document, one pass per page
+func WritePDF(w io.Writer, passes []*models.BoardingPass) error {
+	if len(passes) == 0 {
+		return fmt.Errorf("no boarding passes to print")
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Boarding pass %s", passes[0].BookingReference), true)
+	pdf.SetAuthor(passes[0].OperatorName, true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(false, 15)
+
+	// Core fonts are cp1252; translate names from UTF-8
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for i, pass := range passes {
+		image, err := QRCode(pass.QRCode, FormatPNG, 512)
+		if err != nil {
+			return err
+		}
+		imageName := fmt.Sprintf("qr%d", i)
+		options := fpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(imageName, options, bytes.NewReader(image))
+
+		pdf.AddPage()
+		writePass(pdf, tr, pass, imageName, options)
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to render PDF: %w", err)
+	}
+	return nil
+}
+
+func writePass(pdf *fpdf.Fpdf, tr func(string) string, pass *models.BoardingPass, imageName string, options fpdf.ImageOptions) {
+	left, top, _, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	width := pageWidth - 2*left
+
+	// Operator header in the brand color
+	red, green, blue, err := ParseColor(pass.BrandColor)
+	if err != nil {
+		red, green, blue, _ = ParseColor(DefaultBrandColor)
+	}
+	pdf.SetFillColor(red, green, blue)
+	pdf.Rect(left, top, width, 18, "F")
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetXY(left+5, top)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(width/2, 18, tr(pass.OperatorName), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(width/2-10, 18, "BOARDING PASS", "", 1, "R", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+
+	// Route
+	pdf.SetXY(left, top+26)
+	pdf.SetFont("Helvetica", "B", 28)
+	pdf.CellFormat(width, 12, tr(pass.DeparturePortCode+"  >  "+pass.ArrivalPortCode), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(width, 6, tr(pass.DeparturePort+" to "+pass.ArrivalPort), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(width, 5, tr(pass.RouteName), "", 1, "L", false, 0, "")
+
+	// Details on the left, code on the right
+	codeSize := 70.0
+	detailsTop := pdf.GetY() + 8
+	seat := pass.SeatNumber
+	if seat == "" {
+		seat = "Unassigned"
+	}
+	details := [][2]string{
+		{"Passenger", pass.PassengerName},
+		{"Passenger type", capitalize(pass.PassengerType)},
+		{"Seat", seat},
+		{"Departure", formatLocal(pass.Departure)},
+		{"Arrival", formatLocal(pass.Arrival)},
+		{"Vessel", pass.VesselName},
+		{"Booking reference", pass.BookingReference},
+	}
+	pdf.SetXY(left, detailsTop)
+	for _, detail := range details {
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.CellFormat(width-codeSize-10, 4, strings.ToUpper(detail[0]), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(width-codeSize-10, 7, tr(detail[1]), "", 1, "L", false, 0, "")
+		pdf.Ln(2)
+	}
+	detailsBottom := pdf.GetY()
+
+	codeX := left + width - codeSize
+	pdf.ImageOptions(imageName, codeX, detailsTop, codeSize, codeSize, false, options, 0, "")
+	pdf.SetXY(codeX, detailsTop+codeSize+1)
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.CellFormat(codeSize, 4, pass.TicketID.String(), "", 1, "C", false, 0, "")
+
+	// Tear-off line and footer
+	y := detailsBottom + 6
+	if codeBottom := detailsTop + codeSize + 10; codeBottom > y {
+		y = codeBottom
+	}
+	pdf.SetDrawColor(160, 160, 160)
+	pdf.SetDashPattern([]float64{2, 2}, 0)
+	pdf.Line(left, y, left+width, y)
+	pdf.SetDashPattern([]float64{}, 0)
+
+	pdf.SetXY(left, y+4)
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.MultiCell(width, 5, "Present this pass with photo ID at the gate. Times are local to each port. Boarding closes before departure; please arrive in good time.", "", "L", false)
+}
+
+// formatLocal prints a time with its zone abbreviation, e.g. "Mon 2 Jun 2024 08:30 CEST"
+func formatLocal(t time.Time) string {
+	return t.Format("Mon 2 Jan 2006 15:04 MST")
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
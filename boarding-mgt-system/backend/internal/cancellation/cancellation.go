@@ -0,0 +1,193 @@
+// Package cancellation decides what passengers on a cancelled sailing are
+// offered: the alternative sailings suggested for rebooking, how long they
+// have to choose and how the fare they paid is split into travel credit.
+package cancellation
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+)
+
+// Options a passenger on a cancelled sailing can choose from
+const (
+	OptionRefund = "refund"
+	OptionRebook = "rebook"
+	OptionCredit = "credit"
+)
+
+// Offer statuses; a chosen offer is marked with the outcome of the option
+const (
+	StatusPending  = "pending"
+	StatusRefunded = "refunded"
+	StatusRebooked = "rebooked"
+	StatusCredited = "credited"
+)
+
+// Defaults used when operator settings do not say otherwise
+const (
+	DefaultResponseWindow = 14 * 24 * time.Hour
+	DefaultRebookWindow   = 7 * 24 * time.Hour
+	DefaultAlternatives   = 3
+	DefaultCreditValidity = 365 * 24 * time.Hour
+)
+
+// Policy is an operator's terms for passengers on cancelled sailings
+type Policy struct {
+	// ResponseWindow is how long passengers have to choose before they are
+	// refunded
+	ResponseWindow time.Duration
+	// RebookWindow is how far either side of the cancelled departure
+	// alternative sailings are looked for
+	RebookWindow time.Duration
+	// Alternatives is the most sailings suggested to each passenger
+	Alternatives   int
+	CreditValidity time.Duration
+}
+
+// PolicyFromSettings reads the cancellation policy from operator settings:
+// "cancellation_response_days", "cancellation_rebook_days",
+// "cancellation_alternatives" and "cancellation_credit_days"
+func PolicyFromSettings(settings map[string]interface{}) Policy {
+	policy := Policy{
+		ResponseWindow: DefaultResponseWindow,
+		RebookWindow:   DefaultRebookWindow,
+		Alternatives:   DefaultAlternatives,
+		CreditValidity: DefaultCreditValidity,
+	}
+
+	if days := number(settings["cancellation_response_days"]); days >= 1 {
+		policy.ResponseWindow = time.Duration(days) * 24 * time.Hour
+	}
+	if days := number(settings["cancellation_rebook_days"]); days >= 1 {
+		policy.RebookWindow = time.Duration(days) * 24 * time.Hour
+	}
+	if count := number(settings["cancellation_alternatives"]); count >= 1 {
+		policy.Alternatives = int(count)
+	}
+	if days := number(settings["cancellation_credit_days"]); days >= 1 {
+		policy.CreditValidity = time.Duration(days) * 24 * time.Hour
+	}
+
+	return policy
+}
+
+func number(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	default:
+		return 0
+	}
+}
+
+// Alternatives picks the sailings to suggest to a booking on a cancelled
+// sailing: scheduled departures of the same route, still in the future, with
+// room for the whole party, closest in time to the cancelled departure first
+func Alternatives(cancelled *models.Schedule, candidates []*models.Schedule, passengers, limit int, now time.Time) []*models.Schedule {
+	suitable := []*models.Schedule{}
+	for _, candidate := range candidates {
+		if candidate.ID == cancelled.ID || candidate.RouteID != cancelled.RouteID {
+			continue
+		}
+		if candidate.Status != "scheduled" || !candidate.DepartureAt.After(now) {
+			continue
+		}
+		if candidate.AvailableSeats < passengers {
+			continue
+		}
+		suitable = append(suitable, candidate)
+	}
+
+	distance := func(s *models.Schedule) time.Duration {
+		d := s.DepartureAt.Sub(cancelled.DepartureAt)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	sort.SliceStable(suitable, func(i, j int) bool {
+		di, dj := distance(suitable[i]), distance(suitable[j])
+		if di != dj {
+			return di < dj
+		}
+		return suitable[i].DepartureAt.Before(suitable[j].DepartureAt)
+	})
+
+	if limit > 0 && len(suitable) > limit {
+		suitable = suitable[:limit]
+	}
+	return suitable
+}
+
+// SplitCredit divides the amount paid for a booking over its tickets in
+// proportion to their fares, so each passenger gets their own credit. The
+// last ticket takes any rounding remainder.
+func SplitCredit(paid money.Money, fares []money.Money) []money.Money {
+	amounts := make([]money.Money, len(fares))
+	if len(fares) == 0 || !paid.IsPositive() {
+		return amounts
+	}
+
+	total := money.Sum(fares...)
+	remaining := paid
+	for i, fare := range fares {
+		switch {
+		case i == len(fares)-1:
+			amounts[i] = remaining
+		case total.IsPositive():
+			amounts[i] = fare.Prorate(paid, total, money.HalfUp)
+		default:
+			// Free tickets share the amount equally
+			amounts[i] = paid.MulDiv(1, float64(len(fares)), money.HalfUp)
+		}
+		if amounts[i].Cmp(remaining) > 0 {
+			amounts[i] = remaining
+		}
+		remaining = remaining.Sub(amounts[i])
+	}
+
+	return amounts
+}
+
+// Summarize reports what passengers on a cancelled sailing have chosen
+func Summarize(run *models.ScheduleCancellation, offers []*models.CancellationOffer) *models.ScheduleCancellationReport {
+	report := &models.ScheduleCancellationReport{
+		Cancellation: run,
+		Offers:       offers,
+	}
+	if report.Offers == nil {
+		report.Offers = []*models.CancellationOffer{}
+	}
+
+	for _, offer := range offers {
+		report.Bookings++
+		report.Passengers += offer.PassengerCount
+		report.AmountPaid = report.AmountPaid.Add(offer.AmountPaid)
+
+		switch offer.Status {
+		case StatusPending:
+			report.Pending++
+		case StatusRefunded:
+			report.Refunded++
+			report.RefundedAmount = report.RefundedAmount.Add(offer.AmountPaid)
+		case StatusRebooked:
+			report.Rebooked++
+			report.RebookedAmount = report.RebookedAmount.Add(offer.AmountPaid)
+		case StatusCredited:
+			report.Credited++
+			report.CreditedAmount = report.CreditedAmount.Add(offer.AmountPaid)
+		}
+	}
+
+	return report
+}
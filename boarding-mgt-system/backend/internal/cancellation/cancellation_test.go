@@ -0,0 +1,132 @@
+package cancellation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func utc(s string) time.Time {
+	t, _ := time.Parse("2006-01-02 15:04", s)
+	return t
+}
+
+func TestPolicyFromSettings(t *testing.T) {
+	policy := PolicyFromSettings(nil)
+	assert.Equal(t, DefaultResponseWindow, policy.ResponseWindow)
+	assert.Equal(t, DefaultRebookWindow, policy.RebookWindow)
+	assert.Equal(t, DefaultAlternatives, policy.Alternatives)
+	assert.Equal(t, DefaultCreditValidity, policy.CreditValidity)
+
+	policy = PolicyFromSettings(map[string]interface{}{
+		"cancellation_response_days": float64(3),
+		"cancellation_rebook_days":   "2",
+		"cancellation_alternatives":  float64(5),
+		"cancellation_credit_days":   float64(0),
+	})
+	assert.Equal(t, 3*24*time.Hour, policy.ResponseWindow)
+	assert.Equal(t, 2*24*time.Hour, policy.RebookWindow)
+	assert.Equal(t, 5, policy.Alternatives)
+	assert.Equal(t, DefaultCreditValidity, policy.CreditValidity, "zero days ignored")
+}
+
+func TestAlternatives(t *testing.T) {
+	routeID := uuid.New()
+	cancelled := &models.Schedule{ID: uuid.New(), RouteID: routeID, DepartureAt: utc("2025-07-01 09:00")}
+	now := utc("2025-06-30 12:00")
+
+	sailing := func(departs string, seats int) *models.Schedule {
+		return &models.Schedule{
+			ID:             uuid.New(),
+			RouteID:        routeID,
+			DepartureAt:    utc(departs),
+			AvailableSeats: seats,
+			Status:         "scheduled",
+		}
+	}
+
+	later := sailing("2025-07-01 15:00", 50)
+	earlier := sailing("2025-07-01 07:00", 50)
+	nextDay := sailing("2025-07-02 09:00", 50)
+	full := sailing("2025-07-01 10:00", 2)
+	past := sailing("2025-06-30 09:00", 50)
+	otherRoute := sailing("2025-07-01 09:30", 50)
+	otherRoute.RouteID = uuid.New()
+	cancelledToo := sailing("2025-07-01 11:00", 50)
+	cancelledToo.Status = "cancelled"
+
+	candidates := []*models.Schedule{cancelled, nextDay, later, full, past, otherRoute, cancelledToo, earlier}
+
+	alternatives := Alternatives(cancelled, candidates, 4, 0, now)
+	require.Len(t, alternatives, 3)
+	assert.Equal(t, earlier.ID, alternatives[0].ID, "closest departure first")
+	assert.Equal(t, later.ID, alternatives[1].ID)
+	assert.Equal(t, nextDay.ID, alternatives[2].ID)
+
+	assert.Len(t, Alternatives(cancelled, candidates, 4, 2, now), 2)
+	assert.Len(t, Alternatives(cancelled, candidates, 2, 0, now), 4, "small party fits the nearly full sailing")
+}
+
+func TestSplitCredit(t *testing.T) {
+	fares := []money.Money{money.MustParse("30.00"), money.MustParse("15.00"), money.MustParse("15.00")}
+
+	t.Run("Fully paid", func(t *testing.T) {
+		amounts := SplitCredit(money.MustParse("60.00"), fares)
+		assert.Equal(t, []money.Money{money.MustParse("30.00"), money.MustParse("15.00"), money.MustParse("15.00")}, amounts)
+	})
+
+	t.Run("Part paid keeps proportions and total", func(t *testing.T) {
+		amounts := SplitCredit(money.MustParse("10.00"), fares)
+		assert.Equal(t, money.MustParse("5.00"), amounts[0])
+		assert.Equal(t, money.MustParse("2.50"), amounts[1])
+		assert.Equal(t, money.MustParse("10.00"), money.Sum(amounts...))
+	})
+
+	t.Run("Rounding remainder on the last ticket", func(t *testing.T) {
+		amounts := SplitCredit(money.MustParse("10.00"), []money.Money{money.MustParse("1.00"), money.MustParse("1.00"), money.MustParse("1.00")})
+		assert.Equal(t, money.MustParse("3.33"), amounts[0])
+		assert.Equal(t, money.MustParse("3.34"), amounts[2])
+	})
+
+	t.Run("Free tickets share equally", func(t *testing.T) {
+		amounts := SplitCredit(money.MustParse("5.00"), []money.Money{money.Zero("USD"), money.Zero("USD")})
+		assert.Equal(t, money.MustParse("2.50"), amounts[0])
+		assert.Equal(t, money.MustParse("5.00"), money.Sum(amounts...))
+	})
+
+	t.Run("Nothing paid", func(t *testing.T) {
+		for _, amount := range SplitCredit(money.Zero("USD"), fares) {
+			assert.True(t, amount.IsZero())
+		}
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	run := &models.ScheduleCancellation{ID: uuid.New(), Status: "completed"}
+	offers := []*models.CancellationOffer{
+		{PassengerCount: 2, AmountPaid: money.MustParse("40.00"), Status: StatusPending},
+		{PassengerCount: 1, AmountPaid: money.MustParse("20.00"), Status: StatusRefunded},
+		{PassengerCount: 3, AmountPaid: money.MustParse("60.00"), Status: StatusRebooked},
+		{PassengerCount: 1, AmountPaid: money.MustParse("25.00"), Status: StatusCredited},
+	}
+
+	report := Summarize(run, offers)
+	assert.Equal(t, run, report.Cancellation)
+	assert.Equal(t, 4, report.Bookings)
+	assert.Equal(t, 7, report.Passengers)
+	assert.Equal(t, 1, report.Pending)
+	assert.Equal(t, 1, report.Refunded)
+	assert.Equal(t, 1, report.Rebooked)
+	assert.Equal(t, 1, report.Credited)
+	assert.Equal(t, money.MustParse("145.00"), report.AmountPaid)
+	assert.Equal(t, money.MustParse("20.00"), report.RefundedAmount)
+	assert.Equal(t, money.MustParse("60.00"), report.RebookedAmount)
+	assert.Equal(t, money.MustParse("25.00"), report.CreditedAmount)
+
+	assert.NotNil(t, Summarize(run, nil).Offers)
+}
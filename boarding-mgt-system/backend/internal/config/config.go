@@ -9,9 +9,14 @@ import (
 )
 
 type Config struct {
-	Database DatabaseConfig
-	App      AppConfig
-	JWT      JWTConfig
+	Database  DatabaseConfig
+	App       AppConfig
+	JWT       JWTConfig
+	QR        QRConfig
+	Wallet    WalletConfig
+	Feed      FeedConfig
+	Weather   WeatherConfig
+	Lifecycle LifecycleConfig
 }
 
 type DatabaseConfig struct {
@@ -34,6 +39,48 @@ type JWTConfig struct {
 	Expiry string
 }
 
+// QRConfig holds the Ed25519 keys ticket QR codes are signed with. Keys are
+// comma-separated "keyid:base64" lists.
+type QRConfig struct {
+	SigningKeys string
+	ActiveKeyID string
+	VerifyKeys  string
+}
+
+// WalletConfig identifies the mobile wallet pass type and the PEM files of its
+// certificate, private key and issuing intermediate
+type WalletConfig struct {
+	PassTypeID       string
+	TeamID           string
+	CertFile         string
+	KeyFile          string
+	IntermediateFile string
+	WebServiceURL    string
+}
+
+// FeedConfig selects how live boarding events reach dashboards: "memory"
+// within one API instance, or "postgres" through LISTEN/NOTIFY so that every
+// instance behind a load balancer sees all scans
+type FeedConfig struct {
+	Broker string
+}
+
+// WeatherConfig selects where forecasts come from: "none", "file" to read
+// them from a JSON file for local use, or "http" to get them from a forecast
+// service at URL
+type WeatherConfig struct {
+	Provider string
+	File     string
+	URL      string
+}
+
+// LifecycleConfig sets how often the API moves sailings through boarding
+// and departure and refunds lapsed cancellation offers, as a duration such
+// as "1m", or "off" to leave it to staff
+type LifecycleConfig struct {
+	Interval string
+}
+
 func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		// It's okay if .env doesn't exist in production
@@ -70,6 +117,30 @@ func Load() (*Config, error) {
 			Secret: getEnv("JWT_SECRET", ""),
 			Expiry: getEnv("JWT_EXPIRY", "24h"),
 		},
+		QR: QRConfig{
+			SigningKeys: getEnv("QR_SIGNING_KEYS", ""),
+			ActiveKeyID: getEnv("QR_ACTIVE_KEY_ID", ""),
+			VerifyKeys:  getEnv("QR_VERIFY_KEYS", ""),
+		},
+		Wallet: WalletConfig{
+			PassTypeID:       getEnv("WALLET_PASS_TYPE_ID", "pass.com.ferryflow.ticket"),
+			TeamID:           getEnv("WALLET_TEAM_ID", ""),
+			CertFile:         getEnv("WALLET_CERT_FILE", ""),
+			KeyFile:          getEnv("WALLET_KEY_FILE", ""),
+			IntermediateFile: getEnv("WALLET_INTERMEDIATE_FILE", ""),
+			WebServiceURL:    getEnv("WALLET_WEB_SERVICE_URL", ""),
+		},
+		Feed: FeedConfig{
+			Broker: getEnv("BOARDING_FEED_BROKER", "memory"),
+		},
+		Weather: WeatherConfig{
+			Provider: getEnv("WEATHER_PROVIDER", "none"),
+			File:     getEnv("WEATHER_FILE", ""),
+			URL:      getEnv("WEATHER_URL", ""),
+		},
+		Lifecycle: LifecycleConfig{
+			Interval: getEnv("SCHEDULE_LIFECYCLE_INTERVAL", "1m"),
+		},
 	}, nil
 }
 
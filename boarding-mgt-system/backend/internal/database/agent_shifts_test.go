@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentShiftsTable(t *testing.T) {
+	cfg, err := config.LoadTest()
+	require.NoError(t, err, "Failed to load test config")
+
+	db, err := New(&cfg.Database)
+	require.NoError(t, err, "Failed to connect to database")
+	defer db.Close()
+
+	ctx := context.Background()
+
+	databaseURL := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.Database.Host,
+		cfg.Database.Port,
+		cfg.Database.Name,
+		cfg.Database.SSLMode,
+	)
+
+	migrator, err := NewMigrator(databaseURL)
+	require.NoError(t, err, "Failed to create migrator")
+	defer migrator.Close()
+
+	err = migrator.Up()
+	assert.NoError(t, err, "Failed to run migrations")
+
+	t.Run("Verify shift_id columns", func(t *testing.T) {
+		for _, table := range []string{"bookings", "refunds"} {
+			var columnExists bool
+			err := db.Pool.QueryRow(ctx, `
+				SELECT EXISTS (
+					SELECT 1 FROM information_schema.columns
+					WHERE table_name = $1 AND column_name = 'shift_id'
+				)
+			`, table).Scan(&columnExists)
+			assert.NoError(t, err)
+			assert.True(t, columnExists, "%s should have shift_id column", table)
+		}
+	})
+
+	t.Run("Test shift constraints", func(t *testing.T) {
+		var operatorID, agentID string
+		err := db.Pool.QueryRow(ctx, `
+			INSERT INTO operators (name, code, contact_email)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, "Shift Test Operator", "STO001", "shift@test.com").Scan(&operatorID)
+		require.NoError(t, err)
+
+		err = db.Pool.QueryRow(ctx, `
+			INSERT INTO users (email, password_hash, first_name, last_name, user_type, operator_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`, "shift_agent@example.com", "$2a$10$hash", "Shift", "Agent", "agent", operatorID).Scan(&agentID)
+		require.NoError(t, err)
+
+		// Open a shift
+		var shiftID, status string
+		err = db.Pool.QueryRow(ctx, `
+			INSERT INTO agent_shifts (operator_id, agent_id, terminal_id, opening_float)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, status
+		`, operatorID, agentID, "POS-01", 100.00).Scan(&shiftID, &status)
+		require.NoError(t, err, "Should open shift")
+		assert.Equal(t, "open", status)
+
+		// A second open shift for the same agent is rejected
+		_, err = db.Pool.Exec(ctx, `
+			INSERT INTO agent_shifts (operator_id, agent_id, terminal_id, opening_float)
+			VALUES ($1, $2, $3, $4)
+		`, operatorID, agentID, "POS-02", 50.00)
+		assert.Error(t, err, "Should not allow two open shifts for one agent")
+
+		// Negative opening float is rejected
+		_, err = db.Pool.Exec(ctx, `
+			UPDATE agent_shifts SET opening_float = -1 WHERE id = $1
+		`, shiftID)
+		assert.Error(t, err, "Should not allow negative opening float")
+
+		// Closing without a cash count is rejected
+		_, err = db.Pool.Exec(ctx, `
+			UPDATE agent_shifts SET status = 'closed', closed_at = CURRENT_TIMESTAMP WHERE id = $1
+		`, shiftID)
+		assert.Error(t, err, "Should not allow closing without counted cash")
+
+		// Close with a count
+		_, err = db.Pool.Exec(ctx, `
+			UPDATE agent_shifts SET
+				status = 'closed', closed_at = CURRENT_TIMESTAMP,
+				expected_cash = 150.00, counted_cash = 145.00, cash_variance = -5.00
+			WHERE id = $1
+		`, shiftID)
+		assert.NoError(t, err, "Should close shift with counted cash")
+
+		// The agent can open a new shift once the previous one is closed
+		_, err = db.Pool.Exec(ctx, `
+			INSERT INTO agent_shifts (operator_id, agent_id, terminal_id, opening_float)
+			VALUES ($1, $2, $3, $4)
+		`, operatorID, agentID, "POS-01", 100.00)
+		assert.NoError(t, err, "Should open a new shift after closing")
+
+		// Cleanup
+		_, err = db.Pool.Exec(ctx, "DELETE FROM operators WHERE id = $1", operatorID)
+		assert.NoError(t, err)
+		_, err = db.Pool.Exec(ctx, "DELETE FROM users WHERE id = $1", agentID)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,10 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_agent_shifts ON agent_shifts;
+DROP TRIGGER IF EXISTS update_agent_shifts_updated_at ON agent_shifts;
+
+-- Drop shift references
+ALTER TABLE refunds DROP COLUMN IF EXISTS shift_id;
+ALTER TABLE bookings DROP COLUMN IF EXISTS shift_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS agent_shifts CASCADE;
@@ -0,0 +1,61 @@
+-- Create agent shifts table (cash drawer sessions for POS sales)
+CREATE TABLE agent_shifts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operator_id UUID NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES users(id),
+    terminal_id VARCHAR(50) NOT NULL,
+    status VARCHAR(20) DEFAULT 'open',
+    opening_float DECIMAL(10,2) NOT NULL,
+    expected_cash DECIMAL(10,2),
+    counted_cash DECIMAL(10,2),
+    cash_variance DECIMAL(10,2),
+    opened_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    closing_notes TEXT,
+    reconciled_by UUID REFERENCES users(id),
+    reconciled_at TIMESTAMP WITH TIME ZONE,
+    reconciliation_notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT agent_shifts_opening_float_check CHECK (opening_float >= 0),
+    CONSTRAINT agent_shifts_counted_cash_check CHECK (counted_cash IS NULL OR counted_cash >= 0),
+    CONSTRAINT valid_shift_status CHECK (status IN ('open', 'closed', 'reconciled')),
+    CONSTRAINT closed_shift_has_count CHECK (
+        (status = 'open' AND closed_at IS NULL) OR
+        (status IN ('closed', 'reconciled') AND closed_at IS NOT NULL AND counted_cash IS NOT NULL)
+    )
+);
+
+-- Create indexes on agent_shifts
+CREATE INDEX idx_agent_shifts_operator_id ON agent_shifts(operator_id);
+CREATE INDEX idx_agent_shifts_agent_id ON agent_shifts(agent_id);
+CREATE INDEX idx_agent_shifts_status ON agent_shifts(operator_id, status) WHERE status != 'reconciled';
+CREATE INDEX idx_agent_shifts_opened_at ON agent_shifts(opened_at DESC);
+-- An agent can only hold one open drawer, and a terminal can only have one open drawer
+CREATE UNIQUE INDEX idx_agent_shifts_one_open_per_agent ON agent_shifts(agent_id) WHERE status = 'open';
+CREATE UNIQUE INDEX idx_agent_shifts_one_open_per_terminal ON agent_shifts(operator_id, terminal_id) WHERE status = 'open';
+
+-- Create trigger for agent_shifts updated_at
+CREATE TRIGGER update_agent_shifts_updated_at BEFORE UPDATE ON agent_shifts
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tie POS bookings and refunds to the shift that handled the cash
+ALTER TABLE bookings ADD COLUMN shift_id UUID REFERENCES agent_shifts(id);
+ALTER TABLE refunds ADD COLUMN shift_id UUID REFERENCES agent_shifts(id);
+
+CREATE INDEX idx_bookings_shift_id ON bookings(shift_id) WHERE shift_id IS NOT NULL;
+CREATE INDEX idx_refunds_shift_id ON refunds(shift_id) WHERE shift_id IS NOT NULL;
+
+-- Create audit trigger for agent_shifts
+CREATE TRIGGER audit_agent_shifts AFTER INSERT OR UPDATE OR DELETE ON agent_shifts
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON TABLE agent_shifts IS 'Agent cash drawer shifts at POS terminals';
+COMMENT ON COLUMN agent_shifts.terminal_id IS 'Identifier of the POS terminal the drawer belongs to';
+COMMENT ON COLUMN agent_shifts.opening_float IS 'Cash placed in the drawer when the shift was opened';
+COMMENT ON COLUMN agent_shifts.expected_cash IS 'Opening float plus cash sales minus cash refunds, computed at close';
+COMMENT ON COLUMN agent_shifts.counted_cash IS 'Cash counted by the agent when closing the shift';
+COMMENT ON COLUMN agent_shifts.cash_variance IS 'Counted cash minus expected cash (negative means shortage)';
+COMMENT ON COLUMN bookings.shift_id IS 'Agent shift that took the booking (POS channel only)';
+COMMENT ON COLUMN refunds.shift_id IS 'Agent shift that paid out the refund (POS channel only)';
@@ -0,0 +1,17 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_settlement_imports ON settlement_imports;
+DROP TRIGGER IF EXISTS audit_settlement_providers ON settlement_providers;
+DROP TRIGGER IF EXISTS update_settlement_providers_updated_at ON settlement_providers;
+
+-- Drop reconciliation columns from payments
+DROP INDEX IF EXISTS idx_payments_reconciliation_status;
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS valid_reconciliation_status;
+ALTER TABLE payments DROP COLUMN IF EXISTS reconciliation_notes;
+ALTER TABLE payments DROP COLUMN IF EXISTS reconciled_at;
+ALTER TABLE payments DROP COLUMN IF EXISTS settlement_line_id;
+ALTER TABLE payments DROP COLUMN IF EXISTS reconciliation_status;
+
+-- Drop tables (in reverse order due to foreign keys)
+DROP TABLE IF EXISTS settlement_lines CASCADE;
+DROP TABLE IF EXISTS settlement_imports CASCADE;
+DROP TABLE IF EXISTS settlement_providers CASCADE;
@@ -0,0 +1,104 @@
+-- Create settlement providers table (payment gateways that send settlement files)
+CREATE TABLE settlement_providers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    column_mapping JSONB NOT NULL,
+    delimiter VARCHAR(1) DEFAULT ',',
+    date_format VARCHAR(50) DEFAULT '2006-01-02',
+    payment_methods VARCHAR(20)[] NOT NULL DEFAULT '{}',
+    settlement_delay_days INTEGER DEFAULT 3,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT settlement_providers_code_check CHECK (code ~ '^[a-z0-9_]+$'),
+    CONSTRAINT settlement_providers_delay_check CHECK (settlement_delay_days >= 0),
+    CONSTRAINT column_mapping_has_transaction_id CHECK (column_mapping ? 'transaction_id')
+);
+
+-- Create settlement imports table (one row per uploaded settlement file)
+CREATE TABLE settlement_imports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider_id UUID NOT NULL REFERENCES settlement_providers(id),
+    file_name VARCHAR(255) NOT NULL,
+    file_hash VARCHAR(64) NOT NULL,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    matched_count INTEGER NOT NULL DEFAULT 0,
+    fee_deducted_count INTEGER NOT NULL DEFAULT 0,
+    amount_mismatch_count INTEGER NOT NULL DEFAULT 0,
+    unknown_count INTEGER NOT NULL DEFAULT 0,
+    duplicate_count INTEGER NOT NULL DEFAULT 0,
+    missing_payout_count INTEGER NOT NULL DEFAULT 0,
+    period_start TIMESTAMP WITH TIME ZONE,
+    period_end TIMESTAMP WITH TIME ZONE,
+    imported_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_settlement_file UNIQUE (provider_id, file_hash)
+);
+
+-- Create settlement lines table (individual rows from a settlement file)
+CREATE TABLE settlement_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    import_id UUID NOT NULL REFERENCES settlement_imports(id) ON DELETE CASCADE,
+    row_number INTEGER NOT NULL,
+    gateway_transaction_id VARCHAR(255) NOT NULL,
+    currency VARCHAR(3),
+    gross_amount DECIMAL(10,2) NOT NULL,
+    fee_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    net_amount DECIMAL(10,2) NOT NULL,
+    settled_at TIMESTAMP WITH TIME ZONE,
+    payout_reference VARCHAR(255),
+    payment_id UUID REFERENCES payments(id),
+    match_status VARCHAR(20) NOT NULL,
+    amount_variance DECIMAL(10,2) NOT NULL DEFAULT 0,
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolution_notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_match_status CHECK (match_status IN ('matched', 'fee_deducted', 'amount_mismatch', 'unknown_transaction', 'duplicate'))
+);
+
+-- Create indexes on settlement tables
+CREATE INDEX idx_settlement_imports_provider_id ON settlement_imports(provider_id);
+CREATE INDEX idx_settlement_imports_created_at ON settlement_imports(created_at DESC);
+CREATE INDEX idx_settlement_lines_import_id ON settlement_lines(import_id);
+CREATE INDEX idx_settlement_lines_payment_id ON settlement_lines(payment_id) WHERE payment_id IS NOT NULL;
+CREATE INDEX idx_settlement_lines_gateway_transaction_id ON settlement_lines(gateway_transaction_id);
+CREATE INDEX idx_settlement_lines_open_items ON settlement_lines(match_status)
+    WHERE match_status IN ('unknown_transaction', 'duplicate') AND resolved_at IS NULL;
+
+-- Track reconciliation state on each payment
+ALTER TABLE payments ADD COLUMN reconciliation_status VARCHAR(20) DEFAULT 'unreconciled';
+ALTER TABLE payments ADD COLUMN settlement_line_id UUID REFERENCES settlement_lines(id) ON DELETE SET NULL;
+ALTER TABLE payments ADD COLUMN reconciled_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE payments ADD COLUMN reconciliation_notes TEXT;
+ALTER TABLE payments ADD CONSTRAINT valid_reconciliation_status CHECK (
+    reconciliation_status IN ('unreconciled', 'matched', 'fee_deducted', 'amount_mismatch', 'missing_payout', 'resolved')
+);
+
+CREATE INDEX idx_payments_reconciliation_status ON payments(reconciliation_status)
+    WHERE reconciliation_status IN ('fee_deducted', 'amount_mismatch', 'missing_payout');
+
+-- Create trigger for settlement_providers updated_at
+CREATE TRIGGER update_settlement_providers_updated_at BEFORE UPDATE ON settlement_providers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create audit triggers for settlement tables
+CREATE TRIGGER audit_settlement_providers AFTER INSERT OR UPDATE OR DELETE ON settlement_providers
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+CREATE TRIGGER audit_settlement_imports AFTER INSERT OR UPDATE OR DELETE ON settlement_imports
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON TABLE settlement_providers IS 'Payment gateways and the layout of their settlement files';
+COMMENT ON COLUMN settlement_providers.column_mapping IS 'Maps settlement fields (transaction_id, gross_amount, fee_amount, net_amount, currency, settled_at, payout_reference) to CSV header names';
+COMMENT ON COLUMN settlement_providers.date_format IS 'Go time layout used to parse the settled_at column';
+COMMENT ON COLUMN settlement_providers.payment_methods IS 'Payment methods settled through this provider, used to detect missing payouts';
+COMMENT ON COLUMN settlement_providers.settlement_delay_days IS 'Days after capture before a payment is expected in a settlement file';
+COMMENT ON TABLE settlement_imports IS 'Imported gateway settlement files with reconciliation totals';
+COMMENT ON COLUMN settlement_imports.file_hash IS 'SHA-256 of the file contents, prevents importing the same file twice';
+COMMENT ON TABLE settlement_lines IS 'Rows of imported settlement files and how they matched our payments';
+COMMENT ON COLUMN settlement_lines.amount_variance IS 'Gross settled amount minus the captured payment amount';
+COMMENT ON COLUMN payments.reconciliation_status IS 'Result of matching the payment against gateway settlement files';
+COMMENT ON COLUMN payments.settlement_line_id IS 'Settlement line the payment was last matched to';
@@ -0,0 +1,14 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_ledger_accounts ON ledger_accounts;
+DROP TRIGGER IF EXISTS journal_lines_balanced ON journal_lines;
+DROP TRIGGER IF EXISTS journal_lines_append_only ON journal_lines;
+DROP TRIGGER IF EXISTS journal_entries_append_only ON journal_entries;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS check_journal_entry_balanced();
+DROP FUNCTION IF EXISTS prevent_ledger_modification();
+
+-- Drop tables (in reverse order due to foreign keys)
+DROP TABLE IF EXISTS journal_lines CASCADE;
+DROP TABLE IF EXISTS journal_entries CASCADE;
+DROP TABLE IF EXISTS ledger_accounts CASCADE;
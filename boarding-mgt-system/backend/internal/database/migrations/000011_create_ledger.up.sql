@@ -0,0 +1,110 @@
+-- Create ledger accounts table (chart of accounts per operator)
+CREATE TABLE ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operator_id UUID NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    account_type VARCHAR(20) NOT NULL,
+    system_key VARCHAR(50),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_account_type CHECK (account_type IN ('asset', 'liability', 'equity', 'revenue', 'expense')),
+    CONSTRAINT unique_operator_account_code UNIQUE (operator_id, code),
+    CONSTRAINT unique_operator_system_key UNIQUE (operator_id, system_key)
+);
+
+-- Create journal entries table (append-only)
+CREATE TABLE journal_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_number BIGSERIAL UNIQUE,
+    operator_id UUID NOT NULL REFERENCES operators(id),
+    entry_date DATE NOT NULL,
+    event_type VARCHAR(20) NOT NULL,
+    source_id UUID NOT NULL,
+    booking_id UUID REFERENCES bookings(id),
+    schedule_id UUID REFERENCES schedules(id),
+    description TEXT NOT NULL,
+    currency VARCHAR(3) DEFAULT 'USD',
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_event_type CHECK (event_type IN ('booking', 'payment', 'refund', 'refund_payment', 'departure')),
+    CONSTRAINT valid_journal_currency CHECK (currency ~ '^[A-Z]{3}$'),
+    -- Each business event is posted exactly once
+    CONSTRAINT unique_journal_event UNIQUE (event_type, source_id)
+);
+
+-- Create journal lines table (append-only)
+CREATE TABLE journal_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id UUID NOT NULL REFERENCES journal_entries(id),
+    line_number INTEGER NOT NULL,
+    account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+    booking_id UUID REFERENCES bookings(id),
+    debit DECIMAL(12,2) NOT NULL DEFAULT 0,
+    credit DECIMAL(12,2) NOT NULL DEFAULT 0,
+    memo TEXT,
+    CONSTRAINT journal_lines_amounts_check CHECK (debit >= 0 AND credit >= 0),
+    CONSTRAINT journal_lines_one_side CHECK ((debit = 0) <> (credit = 0)),
+    CONSTRAINT unique_journal_line UNIQUE (entry_id, line_number)
+);
+
+-- Create indexes on ledger tables
+CREATE INDEX idx_ledger_accounts_operator_id ON ledger_accounts(operator_id);
+CREATE INDEX idx_journal_entries_operator_date ON journal_entries(operator_id, entry_date);
+CREATE INDEX idx_journal_entries_booking_id ON journal_entries(booking_id) WHERE booking_id IS NOT NULL;
+CREATE INDEX idx_journal_entries_schedule_id ON journal_entries(schedule_id) WHERE schedule_id IS NOT NULL;
+CREATE INDEX idx_journal_lines_entry_id ON journal_lines(entry_id);
+CREATE INDEX idx_journal_lines_account_id ON journal_lines(account_id);
+CREATE INDEX idx_journal_lines_booking_id ON journal_lines(booking_id) WHERE booking_id IS NOT NULL;
+
+-- Reject updates and deletes so the ledger stays append-only
+CREATE OR REPLACE FUNCTION prevent_ledger_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger is append-only: % on % is not allowed', TG_OP, TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER journal_entries_append_only BEFORE UPDATE OR DELETE ON journal_entries
+    FOR EACH ROW EXECUTE FUNCTION prevent_ledger_modification();
+
+CREATE TRIGGER journal_lines_append_only BEFORE UPDATE OR DELETE ON journal_lines
+    FOR EACH ROW EXECUTE FUNCTION prevent_ledger_modification();
+
+-- Check that every journal entry balances once its transaction commits
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced()
+RETURNS TRIGGER AS $$
+DECLARE
+    total_debit DECIMAL(12,2);
+    total_credit DECIMAL(12,2);
+BEGIN
+    SELECT COALESCE(SUM(debit), 0), COALESCE(SUM(credit), 0)
+    INTO total_debit, total_credit
+    FROM journal_lines
+    WHERE entry_id = NEW.entry_id;
+
+    IF total_debit <> total_credit THEN
+        RAISE EXCEPTION 'journal entry % is not balanced: debits % credits %',
+            NEW.entry_id, total_debit, total_credit;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER journal_lines_balanced AFTER INSERT ON journal_lines
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- Create audit trigger for ledger accounts
+CREATE TRIGGER audit_ledger_accounts AFTER INSERT OR UPDATE OR DELETE ON ledger_accounts
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON TABLE ledger_accounts IS 'Chart of accounts for each operator';
+COMMENT ON COLUMN ledger_accounts.system_key IS 'Stable key used by automatic postings (receivables, deferred_revenue, ...)';
+COMMENT ON TABLE journal_entries IS 'Append-only double-entry journal posted from booking, payment, refund and departure events';
+COMMENT ON COLUMN journal_entries.source_id IS 'ID of the record that triggered the posting (booking, payment, refund or schedule)';
+COMMENT ON TABLE journal_lines IS 'Debit and credit lines of journal entries; each entry must balance';
+COMMENT ON FUNCTION prevent_ledger_modification() IS 'Keeps the journal append-only; corrections are posted as new entries';
+COMMENT ON FUNCTION check_journal_entry_balanced() IS 'Rejects journal entries whose debits and credits differ';
@@ -0,0 +1,19 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_invoices ON invoices;
+DROP TRIGGER IF EXISTS invoice_lines_append_only ON invoice_lines;
+DROP TRIGGER IF EXISTS invoices_append_only ON invoices;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS prevent_invoice_modification();
+
+-- Drop tables (in reverse order due to foreign keys)
+DROP TABLE IF EXISTS invoice_lines CASCADE;
+DROP TABLE IF EXISTS invoices CASCADE;
+DROP TABLE IF EXISTS invoice_sequences CASCADE;
+
+-- Remove operator legal details
+ALTER TABLE operators
+    DROP CONSTRAINT IF EXISTS valid_fiscal_year_start_month,
+    DROP COLUMN IF EXISTS fiscal_year_start_month,
+    DROP COLUMN IF EXISTS tax_id,
+    DROP COLUMN IF EXISTS legal_name;
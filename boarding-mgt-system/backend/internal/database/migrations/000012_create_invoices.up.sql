@@ -0,0 +1,104 @@
+-- Add legal details used on invoices to operators
+ALTER TABLE operators
+    ADD COLUMN legal_name VARCHAR(255),
+    ADD COLUMN tax_id VARCHAR(50),
+    ADD COLUMN fiscal_year_start_month SMALLINT NOT NULL DEFAULT 1,
+    ADD CONSTRAINT valid_fiscal_year_start_month CHECK (fiscal_year_start_month BETWEEN 1 AND 12);
+
+-- Create invoice sequences table (one counter per operator, fiscal year and document type)
+CREATE TABLE invoice_sequences (
+    operator_id UUID NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+    fiscal_year INTEGER NOT NULL,
+    document_type VARCHAR(20) NOT NULL,
+    last_number INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (operator_id, fiscal_year, document_type),
+    CONSTRAINT valid_sequence_document_type CHECK (document_type IN ('invoice', 'credit_note'))
+);
+
+-- Create invoices table (append-only)
+CREATE TABLE invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operator_id UUID NOT NULL REFERENCES operators(id),
+    booking_id UUID NOT NULL REFERENCES bookings(id),
+    customer_id UUID NOT NULL REFERENCES users(id),
+    document_type VARCHAR(20) NOT NULL DEFAULT 'invoice',
+    fiscal_year INTEGER NOT NULL,
+    sequence_number INTEGER NOT NULL,
+    invoice_number VARCHAR(50) NOT NULL,
+    original_invoice_id UUID REFERENCES invoices(id),
+    source_id UUID NOT NULL,
+    issue_date DATE NOT NULL,
+    currency VARCHAR(3) DEFAULT 'USD',
+    seller JSONB NOT NULL,
+    buyer JSONB NOT NULL,
+    subtotal DECIMAL(12,2) NOT NULL,
+    tax_total DECIMAL(12,2) NOT NULL,
+    total DECIMAL(12,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_invoice_document_type CHECK (document_type IN ('invoice', 'credit_note')),
+    CONSTRAINT valid_invoice_currency CHECK (currency ~ '^[A-Z]{3}$'),
+    CONSTRAINT invoices_amounts_check CHECK (subtotal >= 0 AND tax_total >= 0 AND total = subtotal + tax_total),
+    CONSTRAINT credit_note_requires_original CHECK (
+        (document_type = 'invoice' AND original_invoice_id IS NULL) OR
+        (document_type = 'credit_note' AND original_invoice_id IS NOT NULL)
+    ),
+    CONSTRAINT unique_invoice_sequence UNIQUE (operator_id, fiscal_year, document_type, sequence_number),
+    CONSTRAINT unique_invoice_number UNIQUE (operator_id, invoice_number),
+    -- Each booking or refund is invoiced exactly once
+    CONSTRAINT unique_invoice_source UNIQUE (document_type, source_id)
+);
+
+-- Create invoice lines table (append-only)
+CREATE TABLE invoice_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    line_number INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit_price DECIMAL(10,2) NOT NULL,
+    tax_rate DECIMAL(6,4) NOT NULL DEFAULT 0,
+    net_amount DECIMAL(12,2) NOT NULL,
+    tax_amount DECIMAL(12,2) NOT NULL,
+    total_amount DECIMAL(12,2) NOT NULL,
+    CONSTRAINT invoice_lines_quantity_check CHECK (quantity > 0),
+    CONSTRAINT invoice_lines_amounts_check CHECK (unit_price >= 0 AND net_amount >= 0 AND tax_amount >= 0),
+    CONSTRAINT unique_invoice_line UNIQUE (invoice_id, line_number)
+);
+
+-- Create indexes on invoice tables
+CREATE INDEX idx_invoices_booking_id ON invoices(booking_id);
+CREATE INDEX idx_invoices_customer_id ON invoices(customer_id);
+CREATE INDEX idx_invoices_operator_issue_date ON invoices(operator_id, issue_date);
+CREATE INDEX idx_invoices_original_invoice_id ON invoices(original_invoice_id) WHERE original_invoice_id IS NOT NULL;
+CREATE INDEX idx_invoice_lines_invoice_id ON invoice_lines(invoice_id);
+
+-- Reject updates and deletes so issued documents stay immutable
+CREATE OR REPLACE FUNCTION prevent_invoice_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'invoices are immutable: % on % is not allowed', TG_OP, TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER invoices_append_only BEFORE UPDATE OR DELETE ON invoices
+    FOR EACH ROW EXECUTE FUNCTION prevent_invoice_modification();
+
+CREATE TRIGGER invoice_lines_append_only BEFORE UPDATE OR DELETE ON invoice_lines
+    FOR EACH ROW EXECUTE FUNCTION prevent_invoice_modification();
+
+-- Create audit trigger for invoices
+CREATE TRIGGER audit_invoices AFTER INSERT ON invoices
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON COLUMN operators.legal_name IS 'Registered company name printed on invoices; defaults to the trading name';
+COMMENT ON COLUMN operators.tax_id IS 'VAT or tax registration number printed on invoices';
+COMMENT ON COLUMN operators.fiscal_year_start_month IS 'Month (1-12) the fiscal year starts; invoice numbering restarts each fiscal year';
+COMMENT ON TABLE invoice_sequences IS 'Gap-free invoice number counters; rows are locked by the issuing transaction';
+COMMENT ON TABLE invoices IS 'Issued invoices and credit notes with a snapshot of seller and buyer details';
+COMMENT ON COLUMN invoices.fiscal_year IS 'Calendar year in which the fiscal year starts';
+COMMENT ON COLUMN invoices.source_id IS 'Booking for invoices; refund (or cancelled booking) for credit notes';
+COMMENT ON COLUMN invoices.seller IS 'Operator legal details at the time of issue';
+COMMENT ON COLUMN invoices.buyer IS 'Customer details at the time of issue';
+COMMENT ON TABLE invoice_lines IS 'Priced lines of an invoice; unit prices include tax';
+COMMENT ON FUNCTION prevent_invoice_modification() IS 'Keeps issued invoices immutable; corrections are issued as credit notes';
@@ -0,0 +1,55 @@
+-- Restore the single tender booking payment status trigger
+CREATE OR REPLACE FUNCTION update_booking_payment_status()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR TG_OP = 'UPDATE' THEN
+        -- Update booking payment status based on payment status
+        IF NEW.payment_status = 'completed' THEN
+            UPDATE bookings 
+            SET payment_status = 'paid',
+                booking_status = CASE 
+                    WHEN booking_status = 'pending' THEN 'confirmed'
+                    ELSE booking_status
+                END,
+                updated_at = CURRENT_TIMESTAMP
+            WHERE id = NEW.booking_id;
+        ELSIF NEW.payment_status = 'failed' THEN
+            UPDATE bookings 
+            SET payment_status = 'failed',
+                updated_at = CURRENT_TIMESTAMP
+            WHERE id = NEW.booking_id;
+        END IF;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Restore booking payment statuses
+UPDATE bookings SET payment_status = 'pending' WHERE payment_status = 'partially_paid';
+UPDATE bookings SET payment_status = 'refunded' WHERE payment_status = 'refund_pending';
+
+ALTER TABLE bookings DROP CONSTRAINT valid_payment_status;
+ALTER TABLE bookings ADD CONSTRAINT valid_payment_status
+    CHECK (payment_status IN ('pending', 'paid', 'failed', 'refunded'));
+
+-- Drop split tender columns
+DROP INDEX IF EXISTS idx_payments_shift_id;
+
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS payments_refunded_amount_check,
+    DROP COLUMN IF EXISTS shift_id,
+    DROP COLUMN IF EXISTS refunded_amount,
+    DROP COLUMN IF EXISTS tender_reference;
+
+-- Restore payment statuses and methods; vouchers have no equivalent and become bank transfers
+UPDATE payments SET payment_status = 'completed' WHERE payment_status IN ('partially_refunded', 'refunded');
+UPDATE payments SET payment_method = 'bank_transfer' WHERE payment_method = 'voucher';
+
+ALTER TABLE payments DROP CONSTRAINT valid_payment_status;
+ALTER TABLE payments ADD CONSTRAINT valid_payment_status
+    CHECK (payment_status IN ('pending', 'completed', 'failed', 'cancelled'));
+
+ALTER TABLE payments DROP CONSTRAINT valid_payment_method;
+ALTER TABLE payments ADD CONSTRAINT valid_payment_method
+    CHECK (payment_method IN ('credit_card', 'debit_card', 'cash', 'bank_transfer', 'mobile_money', 'paypal'));
@@ -0,0 +1,67 @@
+-- Allow vouchers as a tender and refund statuses on payments
+ALTER TABLE payments DROP CONSTRAINT valid_payment_method;
+ALTER TABLE payments ADD CONSTRAINT valid_payment_method
+    CHECK (payment_method IN ('credit_card', 'debit_card', 'cash', 'bank_transfer', 'mobile_money', 'paypal', 'voucher'));
+
+ALTER TABLE payments DROP CONSTRAINT valid_payment_status;
+ALTER TABLE payments ADD CONSTRAINT valid_payment_status
+    CHECK (payment_status IN ('pending', 'completed', 'failed', 'cancelled', 'partially_refunded', 'refunded'));
+
+-- Track each tender's reference, refunded share and the drawer that took it
+ALTER TABLE payments
+    ADD COLUMN tender_reference VARCHAR(100),
+    ADD COLUMN refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN shift_id UUID REFERENCES agent_shifts(id),
+    ADD CONSTRAINT payments_refunded_amount_check CHECK (refunded_amount >= 0 AND refunded_amount <= amount);
+
+UPDATE payments p SET shift_id = b.shift_id
+FROM bookings b
+WHERE p.booking_id = b.id AND b.shift_id IS NOT NULL;
+
+CREATE INDEX idx_payments_shift_id ON payments(shift_id) WHERE shift_id IS NOT NULL;
+
+-- Bookings can be part paid while a balance is due
+ALTER TABLE bookings DROP CONSTRAINT valid_payment_status;
+ALTER TABLE bookings ADD CONSTRAINT valid_payment_status
+    CHECK (payment_status IN ('pending', 'partially_paid', 'paid', 'failed', 'refund_pending', 'refunded'));
+
+-- A completed tender only confirms the booking once the tenders collected
+-- cover its total; until then the booking is part paid. A failed tender
+-- only fails a booking nothing has been collected for.
+CREATE OR REPLACE FUNCTION update_booking_payment_status()
+RETURNS TRIGGER AS $$
+DECLARE
+    total_collected DECIMAL(10,2);
+BEGIN
+    SELECT COALESCE(SUM(amount - refunded_amount), 0) INTO total_collected
+    FROM payments
+    WHERE booking_id = NEW.booking_id
+    AND payment_status IN ('completed', 'partially_refunded', 'refunded');
+
+    IF NEW.payment_status = 'completed' THEN
+        UPDATE bookings
+        SET payment_status = CASE
+                WHEN total_collected >= total_amount THEN 'paid'
+                ELSE 'partially_paid'
+            END,
+            booking_status = CASE
+                WHEN total_collected >= total_amount AND booking_status = 'pending' THEN 'confirmed'
+                ELSE booking_status
+            END,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = NEW.booking_id;
+    ELSIF NEW.payment_status = 'failed' AND total_collected = 0 THEN
+        UPDATE bookings
+        SET payment_status = 'failed',
+            updated_at = CURRENT_TIMESTAMP
+        WHERE id = NEW.booking_id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Add comments for documentation
+COMMENT ON COLUMN payments.tender_reference IS 'Voucher code, card slip or other reference for the tender';
+COMMENT ON COLUMN payments.refunded_amount IS 'Part of this tender refunded so far; refunds are allocated across tenders';
+COMMENT ON COLUMN payments.shift_id IS 'Agent shift whose drawer took the tender (POS only)';
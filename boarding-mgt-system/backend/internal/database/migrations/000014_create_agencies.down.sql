@@ -0,0 +1,29 @@
+-- Restore payment methods; agency account payments have no equivalent and become bank transfers
+UPDATE payments SET payment_method = 'bank_transfer' WHERE payment_method = 'agency_account';
+
+ALTER TABLE payments DROP CONSTRAINT valid_payment_method;
+ALTER TABLE payments ADD CONSTRAINT valid_payment_method
+    CHECK (payment_method IN ('credit_card', 'debit_card', 'cash', 'bank_transfer', 'mobile_money', 'paypal', 'voucher'));
+
+-- Drop agency columns
+DROP INDEX IF EXISTS idx_bookings_agency_id;
+DROP INDEX IF EXISTS idx_users_agency_id;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS commission_amount,
+    DROP COLUMN IF EXISTS agency_id;
+ALTER TABLE users DROP COLUMN IF EXISTS agency_id;
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_agency_commission_rules ON agency_commission_rules;
+DROP TRIGGER IF EXISTS audit_agencies ON agencies;
+DROP TRIGGER IF EXISTS agency_transactions_append_only ON agency_transactions;
+DROP TRIGGER IF EXISTS update_agencies_updated_at ON agencies;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS prevent_agency_transaction_modification();
+
+-- Drop tables (in reverse order due to foreign keys)
+DROP TABLE IF EXISTS agency_transactions CASCADE;
+DROP TABLE IF EXISTS agency_commission_rules CASCADE;
+DROP TABLE IF EXISTS agencies CASCADE;
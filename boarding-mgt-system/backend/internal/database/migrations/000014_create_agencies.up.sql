@@ -0,0 +1,111 @@
+-- Create agencies table (travel agencies selling an operator's sailings)
+CREATE TABLE agencies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operator_id UUID NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    contact_email VARCHAR(255),
+    contact_phone VARCHAR(50),
+    account_type VARCHAR(20) NOT NULL DEFAULT 'prepaid',
+    credit_limit DECIMAL(12,2) NOT NULL DEFAULT 0,
+    balance DECIMAL(12,2) NOT NULL DEFAULT 0,
+    default_commission_rate DECIMAL(5,4) NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT agencies_operator_code_unique UNIQUE (operator_id, code),
+    CONSTRAINT valid_agency_account_type CHECK (account_type IN ('prepaid', 'credit')),
+    CONSTRAINT agencies_credit_limit_check CHECK (
+        credit_limit >= 0 AND (account_type = 'credit' OR credit_limit = 0)
+    ),
+    CONSTRAINT agencies_commission_rate_check CHECK (default_commission_rate >= 0 AND default_commission_rate <= 1)
+);
+
+-- Commission rules by route and/or fare class; the most specific rule wins
+CREATE TABLE agency_commission_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agency_id UUID NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    route_id UUID REFERENCES routes(id) ON DELETE CASCADE,
+    fare_class VARCHAR(20),
+    commission_rate DECIMAL(5,4) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_commission_fare_class CHECK (fare_class IS NULL OR fare_class IN ('adult', 'child', 'infant', 'senior')),
+    CONSTRAINT agency_commission_rules_rate_check CHECK (commission_rate >= 0 AND commission_rate <= 1),
+    CONSTRAINT agency_commission_rules_has_scope CHECK (route_id IS NOT NULL OR fare_class IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX idx_agency_commission_rules_scope
+    ON agency_commission_rules(agency_id, COALESCE(route_id, '00000000-0000-0000-0000-000000000000'::uuid), COALESCE(fare_class, ''));
+
+-- Append-only movements on an agency's account
+CREATE TABLE agency_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agency_id UUID NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    booking_id UUID REFERENCES bookings(id),
+    transaction_type VARCHAR(20) NOT NULL,
+    amount DECIMAL(12,2) NOT NULL,
+    balance_after DECIMAL(12,2) NOT NULL,
+    reference VARCHAR(100),
+    description TEXT,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_agency_transaction_type CHECK (transaction_type IN (
+        'booking_charge', 'commission', 'refund', 'commission_reversal', 'top_up', 'payment', 'adjustment'
+    )),
+    CONSTRAINT agency_transactions_amount_check CHECK (amount != 0)
+);
+
+CREATE INDEX idx_agencies_operator_id ON agencies(operator_id);
+CREATE INDEX idx_agency_commission_rules_agency_id ON agency_commission_rules(agency_id);
+CREATE INDEX idx_agency_transactions_agency_created ON agency_transactions(agency_id, created_at);
+CREATE INDEX idx_agency_transactions_booking_id ON agency_transactions(booking_id) WHERE booking_id IS NOT NULL;
+
+-- Agency staff and the bookings they sell
+ALTER TABLE users ADD COLUMN agency_id UUID REFERENCES agencies(id);
+ALTER TABLE bookings
+    ADD COLUMN agency_id UUID REFERENCES agencies(id),
+    ADD COLUMN commission_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_users_agency_id ON users(agency_id) WHERE agency_id IS NOT NULL;
+CREATE INDEX idx_bookings_agency_id ON bookings(agency_id, created_at) WHERE agency_id IS NOT NULL;
+
+-- Bookings sold by an agency are paid from the agency's account
+ALTER TABLE payments DROP CONSTRAINT valid_payment_method;
+ALTER TABLE payments ADD CONSTRAINT valid_payment_method
+    CHECK (payment_method IN ('credit_card', 'debit_card', 'cash', 'bank_transfer', 'mobile_money', 'paypal', 'voucher', 'agency_account'));
+
+-- Create trigger for agencies updated_at
+CREATE TRIGGER update_agencies_updated_at BEFORE UPDATE ON agencies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Agency account movements are never changed; corrections are adjustments
+CREATE OR REPLACE FUNCTION prevent_agency_transaction_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'agency transactions are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER agency_transactions_append_only BEFORE UPDATE OR DELETE ON agency_transactions
+    FOR EACH ROW EXECUTE FUNCTION prevent_agency_transaction_modification();
+
+-- Create audit triggers
+CREATE TRIGGER audit_agencies AFTER INSERT OR UPDATE OR DELETE ON agencies
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+CREATE TRIGGER audit_agency_commission_rules AFTER INSERT OR UPDATE OR DELETE ON agency_commission_rules
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON TABLE agencies IS 'Travel agencies that sell sailings on commission';
+COMMENT ON COLUMN agencies.account_type IS 'prepaid agencies draw down funds paid in advance; credit agencies may owe up to their credit limit';
+COMMENT ON COLUMN agencies.balance IS 'Running account balance; negative means the agency owes the operator';
+COMMENT ON COLUMN agencies.default_commission_rate IS 'Commission rate (fraction of the fare) when no rule matches';
+COMMENT ON TABLE agency_commission_rules IS 'Commission rates by route and/or fare class';
+COMMENT ON COLUMN agency_commission_rules.fare_class IS 'Passenger type the rate applies to; NULL for all';
+COMMENT ON TABLE agency_transactions IS 'Append-only movements on agency accounts';
+COMMENT ON COLUMN agency_transactions.amount IS 'Signed amount; positive credits the agency, negative charges it';
+COMMENT ON COLUMN users.agency_id IS 'Agency an agent user sells for';
+COMMENT ON COLUMN bookings.agency_id IS 'Agency that sold the booking';
+COMMENT ON COLUMN bookings.commission_amount IS 'Commission earned by the agency on the booking';
+COMMENT ON FUNCTION prevent_agency_transaction_modification() IS 'Keeps agency account movements append-only';
@@ -0,0 +1,11 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS boarding_scans_append_only ON boarding_scans;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS prevent_boarding_scan_modification();
+
+-- Drop tables
+DROP TABLE IF EXISTS boarding_scans CASCADE;
+
+-- Drop ticket columns
+ALTER TABLE tickets DROP COLUMN IF EXISTS boarding_time;
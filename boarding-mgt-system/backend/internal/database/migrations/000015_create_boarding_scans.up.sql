@@ -0,0 +1,51 @@
+-- Record when a passenger boards, separately from check-in
+ALTER TABLE tickets ADD COLUMN boarding_time TIMESTAMP WITH TIME ZONE;
+
+-- Create boarding scans table (every check-in and boarding scan at a gate)
+CREATE TABLE boarding_scans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticket_id UUID REFERENCES tickets(id),
+    schedule_id UUID NOT NULL REFERENCES schedules(id),
+    action VARCHAR(20) NOT NULL,
+    result VARCHAR(20) NOT NULL,
+    deny_reason VARCHAR(30),
+    check_in_status VARCHAR(20),
+    gate VARCHAR(50) NOT NULL,
+    device_id VARCHAR(100) NOT NULL,
+    scanned_by UUID REFERENCES users(id),
+    override_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_boarding_scan_action CHECK (action IN ('check_in', 'board')),
+    CONSTRAINT valid_boarding_scan_result CHECK (result IN ('accepted', 'denied', 'overridden')),
+    CONSTRAINT valid_boarding_scan_deny_reason CHECK (deny_reason IS NULL OR deny_reason IN (
+        'invalid_code', 'ticket_not_found', 'cancelled_booking', 'wrong_schedule', 'schedule_cancelled',
+        'already_boarded', 'already_checked_in', 'unpaid', 'check_in_closed', 'boarding_closed'
+    )),
+    CONSTRAINT boarding_scans_reason_check CHECK ((result = 'accepted') = (deny_reason IS NULL)),
+    CONSTRAINT boarding_scans_override_check CHECK ((result = 'overridden') = (override_reason IS NOT NULL))
+);
+
+CREATE INDEX idx_boarding_scans_schedule_created ON boarding_scans(schedule_id, created_at);
+CREATE INDEX idx_boarding_scans_ticket_id ON boarding_scans(ticket_id) WHERE ticket_id IS NOT NULL;
+CREATE INDEX idx_boarding_scans_denied ON boarding_scans(schedule_id) WHERE result != 'accepted';
+
+-- Gate scans are evidence of who let whom on board and are never changed
+CREATE OR REPLACE FUNCTION prevent_boarding_scan_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'boarding scans are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER boarding_scans_append_only BEFORE UPDATE OR DELETE ON boarding_scans
+    FOR EACH ROW EXECUTE FUNCTION prevent_boarding_scan_modification();
+
+-- Add comments for documentation
+COMMENT ON COLUMN tickets.boarding_time IS 'When the passenger was scanned on board';
+COMMENT ON TABLE boarding_scans IS 'Append-only log of check-in and boarding scans at the gate';
+COMMENT ON COLUMN boarding_scans.ticket_id IS 'Scanned ticket; NULL when the code could not be matched to a ticket';
+COMMENT ON COLUMN boarding_scans.result IS 'accepted, denied, or overridden by a supervisor despite deny_reason';
+COMMENT ON COLUMN boarding_scans.check_in_status IS 'Ticket check-in status after the scan';
+COMMENT ON COLUMN boarding_scans.device_id IS 'Scanner device the scan was made on';
+COMMENT ON COLUMN boarding_scans.override_reason IS 'Why the supervisor overrode the deny reason';
+COMMENT ON FUNCTION prevent_boarding_scan_modification() IS 'Keeps gate scans append-only';
@@ -0,0 +1,20 @@
+-- Drop boarding scan device columns
+DROP INDEX IF EXISTS idx_boarding_scans_conflicts;
+DROP INDEX IF EXISTS idx_boarding_scans_device_event;
+
+ALTER TABLE boarding_scans
+    DROP CONSTRAINT IF EXISTS boarding_scans_event_check,
+    DROP CONSTRAINT IF EXISTS valid_boarding_scan_conflict,
+    DROP COLUMN IF EXISTS conflict_detail,
+    DROP COLUMN IF EXISTS conflict,
+    DROP COLUMN IF EXISTS device_scanned_at,
+    DROP COLUMN IF EXISTS event_id,
+    DROP COLUMN IF EXISTS scanner_device_id;
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_scanner_devices ON scanner_devices;
+DROP TRIGGER IF EXISTS update_scanner_devices_updated_at ON scanner_devices;
+
+-- Drop tables (in reverse order due to foreign keys)
+DROP TABLE IF EXISTS scanner_device_schedules CASCADE;
+DROP TABLE IF EXISTS scanner_devices CASCADE;
@@ -0,0 +1,58 @@
+-- Create scanner devices table (gate and onboard scanners that sync offline)
+CREATE TABLE scanner_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operator_id UUID NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    device_type VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    is_active BOOLEAN DEFAULT true,
+    registered_by UUID REFERENCES users(id),
+    last_sync_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT scanner_devices_operator_name_unique UNIQUE (operator_id, name),
+    CONSTRAINT valid_scanner_device_type CHECK (device_type IN ('gate', 'onboard'))
+);
+
+-- Schedules a device downloads manifests for
+CREATE TABLE scanner_device_schedules (
+    device_id UUID NOT NULL REFERENCES scanner_devices(id) ON DELETE CASCADE,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (device_id, schedule_id)
+);
+
+CREATE INDEX idx_scanner_devices_operator_id ON scanner_devices(operator_id);
+CREATE INDEX idx_scanner_device_schedules_schedule_id ON scanner_device_schedules(schedule_id);
+
+-- Scans uploaded by devices keep the device's event ID and clock
+ALTER TABLE boarding_scans
+    ADD COLUMN scanner_device_id UUID REFERENCES scanner_devices(id),
+    ADD COLUMN event_id UUID,
+    ADD COLUMN device_scanned_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN conflict VARCHAR(30),
+    ADD COLUMN conflict_detail TEXT,
+    ADD CONSTRAINT valid_boarding_scan_conflict
+        CHECK (conflict IS NULL OR conflict IN ('already_boarded', 'server_denied', 'superseded')),
+    ADD CONSTRAINT boarding_scans_event_check CHECK ((event_id IS NULL) = (scanner_device_id IS NULL));
+
+CREATE UNIQUE INDEX idx_boarding_scans_device_event ON boarding_scans(scanner_device_id, event_id)
+    WHERE event_id IS NOT NULL;
+CREATE INDEX idx_boarding_scans_conflicts ON boarding_scans(schedule_id) WHERE conflict IS NOT NULL;
+
+-- Create trigger for scanner devices updated_at
+CREATE TRIGGER update_scanner_devices_updated_at BEFORE UPDATE ON scanner_devices
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create audit triggers
+CREATE TRIGGER audit_scanner_devices AFTER INSERT OR UPDATE OR DELETE ON scanner_devices
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON TABLE scanner_devices IS 'Gate and onboard scanners authorized to sync for an operator';
+COMMENT ON COLUMN scanner_devices.key_hash IS 'SHA256 hash of the device key';
+COMMENT ON TABLE scanner_device_schedules IS 'Schedules a scanner device may download manifests and upload scans for';
+COMMENT ON COLUMN boarding_scans.event_id IS 'Device-generated ID of an uploaded scan; uploads are idempotent on it';
+COMMENT ON COLUMN boarding_scans.device_scanned_at IS 'When the device recorded the scan, by its own clock';
+COMMENT ON COLUMN boarding_scans.conflict IS 'How an uploaded scan disagreed with the server: already_boarded, server_denied or superseded';
@@ -0,0 +1,7 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_wallet_pass_registrations_updated_at ON wallet_pass_registrations;
+DROP TRIGGER IF EXISTS update_wallet_passes_updated_at ON wallet_passes;
+
+-- Drop tables (in reverse order due to foreign keys)
+DROP TABLE IF EXISTS wallet_pass_registrations CASCADE;
+DROP TABLE IF EXISTS wallet_passes CASCADE;
@@ -0,0 +1,40 @@
+-- Create wallet passes table (one mobile wallet pass per ticket)
+CREATE TABLE wallet_passes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticket_id UUID NOT NULL UNIQUE REFERENCES tickets(id) ON DELETE CASCADE,
+    serial_number VARCHAR(64) NOT NULL UNIQUE,
+    authentication_token VARCHAR(64) NOT NULL,
+    gate VARCHAR(50),
+    notice TEXT,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_wallet_pass_version CHECK (version > 0)
+);
+
+-- Wallets that hold a pass and want to hear when it changes
+CREATE TABLE wallet_pass_registrations (
+    device_library_id VARCHAR(100) NOT NULL,
+    pass_id UUID NOT NULL REFERENCES wallet_passes(id) ON DELETE CASCADE,
+    push_token VARCHAR(200) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (device_library_id, pass_id)
+);
+
+CREATE INDEX idx_wallet_passes_updated_at ON wallet_passes(updated_at);
+CREATE INDEX idx_wallet_pass_registrations_pass_id ON wallet_pass_registrations(pass_id);
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_wallet_passes_updated_at BEFORE UPDATE ON wallet_passes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_wallet_pass_registrations_updated_at BEFORE UPDATE ON wallet_pass_registrations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Add comments for documentation
+COMMENT ON TABLE wallet_passes IS 'Mobile wallet passes issued for tickets';
+COMMENT ON COLUMN wallet_passes.authentication_token IS 'Token wallets present to the pass web service; embedded in the pass';
+COMMENT ON COLUMN wallet_passes.version IS 'Incremented whenever the pass changes';
+COMMENT ON COLUMN wallet_passes.updated_at IS 'When the pass last changed; wallets poll for passes updated since a tag';
+COMMENT ON TABLE wallet_pass_registrations IS 'Devices holding a wallet pass and their push tokens';
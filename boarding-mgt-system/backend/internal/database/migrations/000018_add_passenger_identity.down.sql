@@ -0,0 +1,14 @@
+-- Drop passenger identity constraints
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS valid_ticket_document_issuing_country;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS valid_ticket_document_type;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS valid_ticket_sex;
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS valid_ticket_nationality;
+
+-- Drop passenger identity columns
+ALTER TABLE tickets DROP COLUMN IF EXISTS document_expiry;
+ALTER TABLE tickets DROP COLUMN IF EXISTS document_issuing_country;
+ALTER TABLE tickets DROP COLUMN IF EXISTS document_number;
+ALTER TABLE tickets DROP COLUMN IF EXISTS document_type;
+ALTER TABLE tickets DROP COLUMN IF EXISTS sex;
+ALTER TABLE tickets DROP COLUMN IF EXISTS date_of_birth;
+ALTER TABLE tickets DROP COLUMN IF EXISTS nationality;
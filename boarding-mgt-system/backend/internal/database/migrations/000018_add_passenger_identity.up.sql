@@ -0,0 +1,26 @@
+-- Passenger identity details port authorities require on passenger lists
+-- (IMO FAL Form 6). All are optional at booking; operators decide which are
+-- mandatory before departure.
+ALTER TABLE tickets ADD COLUMN nationality VARCHAR(2);
+ALTER TABLE tickets ADD COLUMN date_of_birth DATE;
+ALTER TABLE tickets ADD COLUMN sex VARCHAR(1);
+ALTER TABLE tickets ADD COLUMN document_type VARCHAR(20);
+ALTER TABLE tickets ADD COLUMN document_number VARCHAR(50);
+ALTER TABLE tickets ADD COLUMN document_issuing_country VARCHAR(2);
+ALTER TABLE tickets ADD COLUMN document_expiry DATE;
+
+ALTER TABLE tickets ADD CONSTRAINT valid_ticket_nationality CHECK (nationality IS NULL OR nationality ~ '^[A-Z]{2}$');
+ALTER TABLE tickets ADD CONSTRAINT valid_ticket_sex CHECK (sex IS NULL OR sex IN ('F', 'M', 'X'));
+ALTER TABLE tickets ADD CONSTRAINT valid_ticket_document_type CHECK (
+    document_type IS NULL OR document_type IN ('passport', 'id_card', 'residence_permit', 'other')
+);
+ALTER TABLE tickets ADD CONSTRAINT valid_ticket_document_issuing_country CHECK (
+    document_issuing_country IS NULL OR document_issuing_country ~ '^[A-Z]{2}$'
+);
+
+-- Add comments for documentation
+COMMENT ON COLUMN tickets.nationality IS 'ISO 3166-1 alpha-2 nationality of the passenger';
+COMMENT ON COLUMN tickets.sex IS 'F, M or X as shown on the travel document';
+COMMENT ON COLUMN tickets.document_type IS 'Type of travel document: passport, id_card, residence_permit or other';
+COMMENT ON COLUMN tickets.document_number IS 'Serial number of the travel document';
+COMMENT ON COLUMN tickets.document_issuing_country IS 'ISO 3166-1 alpha-2 country that issued the travel document';
@@ -0,0 +1,21 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_travel_credits ON travel_credits;
+DROP TRIGGER IF EXISTS audit_departure_summaries ON departure_summaries;
+DROP TRIGGER IF EXISTS update_travel_credits_updated_at ON travel_credits;
+
+-- Restore ledger event types. The journal is append-only, so entries
+-- already posted for credits are kept and the constraint is not revalidated.
+ALTER TABLE journal_entries DROP CONSTRAINT IF EXISTS valid_event_type;
+ALTER TABLE journal_entries ADD CONSTRAINT valid_event_type
+    CHECK (event_type IN ('booking', 'payment', 'refund', 'refund_payment', 'departure')) NOT VALID;
+
+-- Drop tables
+DROP TABLE IF EXISTS travel_credits CASCADE;
+DROP TABLE IF EXISTS departure_summaries CASCADE;
+
+-- Restore check-in statuses
+UPDATE tickets SET check_in_status = CASE WHEN check_in_time IS NULL THEN 'not_checked_in' ELSE 'checked_in' END
+    WHERE check_in_status = 'no_show';
+ALTER TABLE tickets DROP CONSTRAINT IF EXISTS valid_check_in_status;
+ALTER TABLE tickets ADD CONSTRAINT valid_check_in_status
+    CHECK (check_in_status IN ('not_checked_in', 'checked_in', 'boarded'));
@@ -0,0 +1,69 @@
+-- Tickets that never board are marked as no-shows once the sailing departs
+ALTER TABLE tickets DROP CONSTRAINT valid_check_in_status;
+ALTER TABLE tickets ADD CONSTRAINT valid_check_in_status
+    CHECK (check_in_status IN ('not_checked_in', 'checked_in', 'boarded', 'no_show'));
+
+-- Create departure summaries table (final passenger counts of a sailing)
+CREATE TABLE departure_summaries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL UNIQUE REFERENCES schedules(id) ON DELETE CASCADE,
+    total_passengers INTEGER NOT NULL DEFAULT 0,
+    checked_in INTEGER NOT NULL DEFAULT 0,
+    boarded INTEGER NOT NULL DEFAULT 0,
+    no_shows INTEGER NOT NULL DEFAULT 0,
+    no_show_policy VARCHAR(20) NOT NULL,
+    forfeited_amount DECIMAL(12,2) NOT NULL DEFAULT 0,
+    credited_amount DECIMAL(12,2) NOT NULL DEFAULT 0,
+    processed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_no_show_policy CHECK (no_show_policy IN ('forfeit', 'credit')),
+    CONSTRAINT departure_summaries_counts_check CHECK (
+        boarded + no_shows = total_passengers AND checked_in >= boarded
+    )
+);
+
+-- Create travel credits table (fare value customers can use on a later booking)
+CREATE TABLE travel_credits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operator_id UUID NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES users(id),
+    booking_id UUID NOT NULL REFERENCES bookings(id),
+    ticket_id UUID NOT NULL UNIQUE REFERENCES tickets(id),
+    code VARCHAR(20) NOT NULL UNIQUE,
+    amount DECIMAL(10,2) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT travel_credits_amount_check CHECK (amount > 0),
+    CONSTRAINT valid_travel_credit_reason CHECK (reason IN ('no_show')),
+    CONSTRAINT valid_travel_credit_status CHECK (status IN ('open', 'redeemed', 'expired', 'void'))
+);
+
+-- Create indexes
+CREATE INDEX idx_travel_credits_customer_id ON travel_credits(customer_id);
+CREATE INDEX idx_travel_credits_operator_id ON travel_credits(operator_id);
+
+-- Credit given to no-shows is posted to the ledger
+ALTER TABLE journal_entries DROP CONSTRAINT valid_event_type;
+ALTER TABLE journal_entries ADD CONSTRAINT valid_event_type
+    CHECK (event_type IN ('booking', 'payment', 'refund', 'refund_payment', 'departure', 'no_show_credit'));
+
+-- Create trigger for travel_credits updated_at
+CREATE TRIGGER update_travel_credits_updated_at BEFORE UPDATE ON travel_credits
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create audit triggers
+CREATE TRIGGER audit_departure_summaries AFTER INSERT OR UPDATE OR DELETE ON departure_summaries
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+CREATE TRIGGER audit_travel_credits AFTER INSERT OR UPDATE OR DELETE ON travel_credits
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON TABLE departure_summaries IS 'Final passenger counts of departed sailings, recorded when no-shows are processed';
+COMMENT ON COLUMN departure_summaries.checked_in IS 'Passengers who checked in, whether or not they boarded';
+COMMENT ON COLUMN departure_summaries.forfeited_amount IS 'No-show fares kept by the operator';
+COMMENT ON COLUMN departure_summaries.credited_amount IS 'No-show fares given back as travel credit';
+COMMENT ON TABLE travel_credits IS 'Fare value customers can put towards a later booking';
+COMMENT ON COLUMN travel_credits.code IS 'Code the customer quotes to use the credit';
@@ -0,0 +1,12 @@
+-- Drop trigger
+DROP TRIGGER IF EXISTS audit_schedule_templates ON schedule_templates;
+DROP TRIGGER IF EXISTS update_schedule_templates_updated_at ON schedule_templates;
+
+-- Generated schedules stay as standalone schedules
+DROP INDEX IF EXISTS idx_schedules_template_departure;
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS template_version,
+    DROP COLUMN IF EXISTS template_id;
+
+-- Drop table
+DROP TABLE IF EXISTS schedule_templates CASCADE;
@@ -0,0 +1,55 @@
+-- Create schedule templates table (recurring timetables schedules are generated from)
+CREATE TABLE schedule_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operator_id UUID NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+    route_id UUID NOT NULL REFERENCES routes(id) ON DELETE CASCADE,
+    vessel_id UUID NOT NULL REFERENCES vessels(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    base_price DECIMAL(10,2) NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_until DATE,
+    weekdays SMALLINT[] NOT NULL DEFAULT '{}',
+    departures JSONB NOT NULL,
+    exception_dates DATE[] NOT NULL DEFAULT '{}',
+    skip_holidays BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    generated_until DATE,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT schedule_templates_base_price_check CHECK (base_price >= 0),
+    CONSTRAINT valid_template_period CHECK (valid_until IS NULL OR valid_until >= valid_from),
+    CONSTRAINT valid_template_weekdays CHECK (weekdays <@ ARRAY[1, 2, 3, 4, 5, 6, 7]::SMALLINT[]),
+    CONSTRAINT valid_template_departures CHECK (
+        jsonb_typeof(departures) = 'array' AND jsonb_array_length(departures) > 0
+    )
+);
+
+-- Schedules remember the template and template version they were generated from
+ALTER TABLE schedules
+    ADD COLUMN template_id UUID REFERENCES schedule_templates(id) ON DELETE SET NULL,
+    ADD COLUMN template_version INTEGER;
+
+-- Create indexes
+CREATE INDEX idx_schedule_templates_operator_id ON schedule_templates(operator_id);
+CREATE INDEX idx_schedule_templates_route_id ON schedule_templates(route_id);
+-- A template generates each departure once, however often it is run
+CREATE UNIQUE INDEX idx_schedules_template_departure ON schedules(template_id, departure_date, departure_time)
+    WHERE template_id IS NOT NULL;
+
+-- Create trigger for schedule_templates updated_at
+CREATE TRIGGER update_schedule_templates_updated_at BEFORE UPDATE ON schedule_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create audit trigger
+CREATE TRIGGER audit_schedule_templates AFTER INSERT OR UPDATE OR DELETE ON schedule_templates
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON TABLE schedule_templates IS 'Recurring timetables that concrete schedules are generated from';
+COMMENT ON COLUMN schedule_templates.weekdays IS 'ISO days of the week the template runs on (1 = Monday); empty for every day';
+COMMENT ON COLUMN schedule_templates.departures IS 'Daily departures as [{"departure_time": "07:00", "arrival_time": "08:30"}]';
+COMMENT ON COLUMN schedule_templates.exception_dates IS 'Dates the template does not run on';
+COMMENT ON COLUMN schedule_templates.skip_holidays IS 'Skip the holidays listed in the operator settings';
+COMMENT ON COLUMN schedule_templates.generated_until IS 'Last date schedules have been generated up to';
+COMMENT ON COLUMN schedules.template_version IS 'Version of the template the schedule was last generated from';
@@ -0,0 +1,16 @@
+-- Drop constraint and triggers
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS no_vessel_double_allocation;
+DROP TRIGGER IF EXISTS set_schedules_periods ON schedules;
+DROP TRIGGER IF EXISTS refresh_vessels_periods ON vessels;
+
+-- Drop columns
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS vessel_period,
+    DROP COLUMN IF EXISTS sailing_period;
+ALTER TABLE vessels DROP COLUMN IF EXISTS turnaround_minutes;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS refresh_vessel_periods();
+DROP FUNCTION IF EXISTS set_schedule_periods();
+DROP FUNCTION IF EXISTS schedule_vessel_period(UUID, TSTZRANGE);
+DROP FUNCTION IF EXISTS schedule_sailing_period(UUID, DATE, TIME, TIME);
@@ -0,0 +1,113 @@
+-- Time a vessel needs at port after arriving before it can sail again
+ALTER TABLE vessels ADD COLUMN turnaround_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE vessels ADD CONSTRAINT vessels_turnaround_minutes_check CHECK (turnaround_minutes >= 0);
+
+-- Real departure and arrival instants of a sailing. Times are local to the
+-- departure port; an arrival time before the departure time is the next day.
+CREATE OR REPLACE FUNCTION schedule_sailing_period(
+    p_route_id UUID,
+    p_departure_date DATE,
+    p_departure_time TIME,
+    p_arrival_time TIME
+)
+RETURNS TSTZRANGE AS $$
+DECLARE
+    port_timezone TEXT;
+    departs_at TIMESTAMP WITH TIME ZONE;
+    arrives_at TIMESTAMP WITH TIME ZONE;
+BEGIN
+    SELECT p.timezone INTO port_timezone
+    FROM routes r
+    JOIN ports p ON p.id = r.departure_port_id
+    WHERE r.id = p_route_id;
+
+    departs_at := (p_departure_date + p_departure_time) AT TIME ZONE COALESCE(port_timezone, 'UTC');
+    arrives_at := (p_departure_date + p_arrival_time) AT TIME ZONE COALESCE(port_timezone, 'UTC');
+    IF arrives_at <= departs_at THEN
+        arrives_at := arrives_at + INTERVAL '1 day';
+    END IF;
+
+    RETURN tstzrange(departs_at, arrives_at, '[)');
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- Time a vessel is taken up by a sailing: the sailing plus its turnaround
+CREATE OR REPLACE FUNCTION schedule_vessel_period(p_vessel_id UUID, p_sailing_period TSTZRANGE)
+RETURNS TSTZRANGE AS $$
+    SELECT tstzrange(
+        lower(p_sailing_period),
+        upper(p_sailing_period) + make_interval(mins => COALESCE(
+            (SELECT turnaround_minutes FROM vessels WHERE id = p_vessel_id), 0
+        )),
+        '[)'
+    );
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE schedules
+    ADD COLUMN sailing_period TSTZRANGE,
+    ADD COLUMN vessel_period TSTZRANGE;
+
+-- Fill in existing schedules without touching their updated_at or audit trail
+ALTER TABLE schedules DISABLE TRIGGER update_schedules_updated_at;
+ALTER TABLE schedules DISABLE TRIGGER audit_schedules;
+
+UPDATE schedules
+SET sailing_period = schedule_sailing_period(route_id, departure_date, departure_time, arrival_time);
+UPDATE schedules
+SET vessel_period = schedule_vessel_period(vessel_id, sailing_period);
+
+ALTER TABLE schedules ENABLE TRIGGER update_schedules_updated_at;
+ALTER TABLE schedules ENABLE TRIGGER audit_schedules;
+
+ALTER TABLE schedules
+    ALTER COLUMN sailing_period SET NOT NULL,
+    ALTER COLUMN vessel_period SET NOT NULL;
+
+-- Create function to keep the periods in step with the timetable
+CREATE OR REPLACE FUNCTION set_schedule_periods()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.sailing_period := schedule_sailing_period(
+        NEW.route_id, NEW.departure_date, NEW.departure_time, NEW.arrival_time
+    );
+    NEW.vessel_period := schedule_vessel_period(NEW.vessel_id, NEW.sailing_period);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_schedules_periods
+    BEFORE INSERT OR UPDATE OF route_id, vessel_id, departure_date, departure_time, arrival_time
+    ON schedules
+    FOR EACH ROW EXECUTE FUNCTION set_schedule_periods();
+
+-- Create function to reallocate a vessel's sailings when its turnaround
+-- changes. Sailings whose turnaround is over are left as they were.
+CREATE OR REPLACE FUNCTION refresh_vessel_periods()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.turnaround_minutes IS DISTINCT FROM OLD.turnaround_minutes THEN
+        UPDATE schedules
+        SET vessel_period = schedule_vessel_period(NEW.id, sailing_period)
+        WHERE vessel_id = NEW.id
+            AND upper(sailing_period) + make_interval(mins => GREATEST(OLD.turnaround_minutes, NEW.turnaround_minutes)) > CURRENT_TIMESTAMP;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER refresh_vessels_periods
+    AFTER UPDATE OF turnaround_minutes ON vessels
+    FOR EACH ROW EXECUTE FUNCTION refresh_vessel_periods();
+
+-- A vessel cannot be on two sailings, or inside its turnaround, at once.
+-- Cancelled sailings release the vessel. Existing clashes must be resolved
+-- before this migration can run.
+ALTER TABLE schedules ADD CONSTRAINT no_vessel_double_allocation
+    EXCLUDE USING gist (vessel_id WITH =, vessel_period WITH &&)
+    WHERE (status <> 'cancelled');
+
+-- Add comments
+COMMENT ON COLUMN vessels.turnaround_minutes IS 'Minutes the vessel needs at port after arriving before its next departure';
+COMMENT ON COLUMN schedules.sailing_period IS 'Departure to arrival instants of the sailing';
+COMMENT ON COLUMN schedules.vessel_period IS 'Time the vessel is taken up by the sailing, turnaround included';
+COMMENT ON CONSTRAINT no_vessel_double_allocation ON schedules IS 'Prevents allocating a vessel to overlapping sailings';
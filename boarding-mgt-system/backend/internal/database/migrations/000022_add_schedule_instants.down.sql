@@ -0,0 +1,51 @@
+-- Periods go back to being worked out from the local dates and times
+CREATE OR REPLACE FUNCTION schedule_sailing_period(
+    p_route_id UUID,
+    p_departure_date DATE,
+    p_departure_time TIME,
+    p_arrival_time TIME
+)
+RETURNS TSTZRANGE AS $$
+DECLARE
+    port_timezone TEXT;
+    departs_at TIMESTAMP WITH TIME ZONE;
+    arrives_at TIMESTAMP WITH TIME ZONE;
+BEGIN
+    SELECT p.timezone INTO port_timezone
+    FROM routes r
+    JOIN ports p ON p.id = r.departure_port_id
+    WHERE r.id = p_route_id;
+
+    departs_at := (p_departure_date + p_departure_time) AT TIME ZONE COALESCE(port_timezone, 'UTC');
+    arrives_at := (p_departure_date + p_arrival_time) AT TIME ZONE COALESCE(port_timezone, 'UTC');
+    IF arrives_at <= departs_at THEN
+        arrives_at := arrives_at + INTERVAL '1 day';
+    END IF;
+
+    RETURN tstzrange(departs_at, arrives_at, '[)');
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+CREATE OR REPLACE FUNCTION set_schedule_periods()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.sailing_period := schedule_sailing_period(
+        NEW.route_id, NEW.departure_date, NEW.departure_time, NEW.arrival_time
+    );
+    NEW.vessel_period := schedule_vessel_period(NEW.vessel_id, NEW.sailing_period);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_schedules_periods ON schedules;
+CREATE TRIGGER set_schedules_periods
+    BEFORE INSERT OR UPDATE OF route_id, vessel_id, departure_date, departure_time, arrival_time
+    ON schedules
+    FOR EACH ROW EXECUTE FUNCTION set_schedule_periods();
+
+-- Drop index and columns
+DROP INDEX IF EXISTS idx_schedules_departure_at;
+ALTER TABLE schedules
+    DROP CONSTRAINT IF EXISTS valid_schedule_instants,
+    DROP COLUMN IF EXISTS arrival_at,
+    DROP COLUMN IF EXISTS departure_at;
@@ -0,0 +1,69 @@
+-- Departure and arrival instants of each sailing. Departure times are local
+-- to the departure port and arrival times to the arrival port; the instants
+-- are what the rest of the system compares and orders by.
+ALTER TABLE schedules
+    ADD COLUMN departure_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN arrival_at TIMESTAMP WITH TIME ZONE;
+
+-- Create function to keep the periods in step with the instants
+CREATE OR REPLACE FUNCTION set_schedule_periods()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.sailing_period := tstzrange(NEW.departure_at, NEW.arrival_at, '[)');
+    NEW.vessel_period := schedule_vessel_period(NEW.vessel_id, NEW.sailing_period);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_schedules_periods ON schedules;
+CREATE TRIGGER set_schedules_periods
+    BEFORE INSERT OR UPDATE OF vessel_id, departure_at, arrival_at
+    ON schedules
+    FOR EACH ROW EXECUTE FUNCTION set_schedule_periods();
+
+DROP FUNCTION IF EXISTS schedule_sailing_period(UUID, DATE, TIME, TIME);
+
+-- Fill in existing schedules without touching their updated_at or audit trail.
+-- The arrival is the first time after departure the arrival port's clocks
+-- show the arrival time.
+ALTER TABLE schedules DISABLE TRIGGER update_schedules_updated_at;
+ALTER TABLE schedules DISABLE TRIGGER audit_schedules;
+
+UPDATE schedules s
+SET
+    departure_at = z.departs_at,
+    arrival_at = CASE
+        WHEN ((s.departure_date - 1) + s.arrival_time) AT TIME ZONE z.arrival_timezone > z.departs_at
+            THEN ((s.departure_date - 1) + s.arrival_time) AT TIME ZONE z.arrival_timezone
+        WHEN (s.departure_date + s.arrival_time) AT TIME ZONE z.arrival_timezone > z.departs_at
+            THEN (s.departure_date + s.arrival_time) AT TIME ZONE z.arrival_timezone
+        ELSE ((s.departure_date + 1) + s.arrival_time) AT TIME ZONE z.arrival_timezone
+    END
+FROM (
+    SELECT
+        sc.id,
+        (sc.departure_date + sc.departure_time) AT TIME ZONE dp.timezone AS departs_at,
+        ap.timezone AS arrival_timezone
+    FROM schedules sc
+    JOIN routes r ON sc.route_id = r.id
+    JOIN ports dp ON r.departure_port_id = dp.id
+    JOIN ports ap ON r.arrival_port_id = ap.id
+) z
+WHERE s.id = z.id;
+
+ALTER TABLE schedules ENABLE TRIGGER update_schedules_updated_at;
+ALTER TABLE schedules ENABLE TRIGGER audit_schedules;
+
+ALTER TABLE schedules
+    ALTER COLUMN departure_at SET NOT NULL,
+    ALTER COLUMN arrival_at SET NOT NULL,
+    ADD CONSTRAINT valid_schedule_instants CHECK (arrival_at > departure_at);
+
+-- Create indexes
+CREATE INDEX idx_schedules_departure_at ON schedules(departure_at);
+
+-- Add comments
+COMMENT ON COLUMN schedules.departure_time IS 'Departure time local to the departure port';
+COMMENT ON COLUMN schedules.arrival_time IS 'Arrival time local to the arrival port';
+COMMENT ON COLUMN schedules.departure_at IS 'Departure instant';
+COMMENT ON COLUMN schedules.arrival_at IS 'Arrival instant, possibly on a later date than departure_date';
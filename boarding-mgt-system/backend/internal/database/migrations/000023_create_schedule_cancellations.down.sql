@@ -0,0 +1,20 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_cancellation_offers ON cancellation_offers;
+DROP TRIGGER IF EXISTS audit_schedule_cancellations ON schedule_cancellations;
+DROP TRIGGER IF EXISTS update_cancellation_offers_updated_at ON cancellation_offers;
+DROP TRIGGER IF EXISTS update_schedule_cancellations_updated_at ON schedule_cancellations;
+
+-- Restore ledger event types and credit reasons. Journal entries and credits
+-- already recorded are kept, so the constraints are not revalidated.
+ALTER TABLE journal_entries DROP CONSTRAINT IF EXISTS valid_event_type;
+ALTER TABLE journal_entries ADD CONSTRAINT valid_event_type
+    CHECK (event_type IN ('booking', 'payment', 'refund', 'refund_payment', 'departure', 'no_show_credit')) NOT VALID;
+
+ALTER TABLE travel_credits DROP CONSTRAINT IF EXISTS valid_travel_credit_reason;
+ALTER TABLE travel_credits ADD CONSTRAINT valid_travel_credit_reason
+    CHECK (reason IN ('no_show')) NOT VALID;
+
+-- Drop tables
+DROP TABLE IF EXISTS notifications CASCADE;
+DROP TABLE IF EXISTS cancellation_offers CASCADE;
+DROP TABLE IF EXISTS schedule_cancellations CASCADE;
@@ -0,0 +1,88 @@
+-- Create schedule cancellations table (runs that cancel a sailing's bookings)
+CREATE TABLE schedule_cancellations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL UNIQUE REFERENCES schedules(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress',
+    started_by UUID REFERENCES users(id),
+    last_error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_cancellation_status CHECK (status IN ('in_progress', 'completed', 'failed'))
+);
+
+-- Create cancellation offers table (what each booking on a cancelled sailing is offered)
+CREATE TABLE cancellation_offers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cancellation_id UUID NOT NULL REFERENCES schedule_cancellations(id) ON DELETE CASCADE,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES users(id),
+    passenger_count INTEGER NOT NULL,
+    amount_paid DECIMAL(10,2) NOT NULL DEFAULT 0,
+    alternative_schedule_ids UUID[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    rebooked_schedule_id UUID REFERENCES schedules(id),
+    respond_by TIMESTAMP WITH TIME ZONE NOT NULL,
+    chosen_by UUID REFERENCES users(id),
+    chosen_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT cancellation_offers_booking_unique UNIQUE (cancellation_id, booking_id),
+    CONSTRAINT cancellation_offers_amount_paid_check CHECK (amount_paid >= 0),
+    CONSTRAINT valid_cancellation_offer_status CHECK (status IN ('pending', 'refunded', 'rebooked', 'credited')),
+    CONSTRAINT valid_cancellation_offer_rebooking CHECK ((status = 'rebooked') = (rebooked_schedule_id IS NOT NULL))
+);
+
+-- Create notifications table (messages shown in a user's notification inbox)
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    dedupe_key VARCHAR(255) UNIQUE,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Passengers on cancelled sailings can take their fare as travel credit
+ALTER TABLE travel_credits DROP CONSTRAINT valid_travel_credit_reason;
+ALTER TABLE travel_credits ADD CONSTRAINT valid_travel_credit_reason
+    CHECK (reason IN ('no_show', 'schedule_change'));
+
+ALTER TABLE journal_entries DROP CONSTRAINT valid_event_type;
+ALTER TABLE journal_entries ADD CONSTRAINT valid_event_type
+    CHECK (event_type IN ('booking', 'payment', 'refund', 'refund_payment', 'departure', 'no_show_credit', 'cancellation_credit'));
+
+-- Create indexes
+CREATE INDEX idx_cancellation_offers_booking_id ON cancellation_offers(booking_id);
+CREATE INDEX idx_cancellation_offers_customer_id ON cancellation_offers(customer_id);
+CREATE INDEX idx_cancellation_offers_pending ON cancellation_offers(respond_by) WHERE status = 'pending';
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Create triggers for updated_at
+CREATE TRIGGER update_schedule_cancellations_updated_at BEFORE UPDATE ON schedule_cancellations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_cancellation_offers_updated_at BEFORE UPDATE ON cancellation_offers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create audit triggers
+CREATE TRIGGER audit_schedule_cancellations AFTER INSERT OR UPDATE OR DELETE ON schedule_cancellations
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+CREATE TRIGGER audit_cancellation_offers AFTER INSERT OR UPDATE OR DELETE ON cancellation_offers
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON TABLE schedule_cancellations IS 'Runs that cancel the bookings on a cancelled sailing; a failed run is resumed by cancelling again';
+COMMENT ON COLUMN schedule_cancellations.last_error IS 'Why the last attempt stopped before every booking was cancelled';
+COMMENT ON TABLE cancellation_offers IS 'Refund, rebooking or credit offered to each booking on a cancelled sailing';
+COMMENT ON COLUMN cancellation_offers.alternative_schedule_ids IS 'Sailings suggested for rebooking when the offer was made';
+COMMENT ON COLUMN cancellation_offers.respond_by IS 'Offers still pending after this are refunded';
+COMMENT ON TABLE notifications IS 'Messages shown in a user''s notification inbox';
+COMMENT ON COLUMN notifications.dedupe_key IS 'Identifies the event notified about so resumed runs do not notify twice';
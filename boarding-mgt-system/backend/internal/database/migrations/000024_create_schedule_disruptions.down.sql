@@ -0,0 +1,83 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_booking_moves ON booking_moves;
+DROP TRIGGER IF EXISTS audit_schedule_disruptions ON schedule_disruptions;
+
+-- Seat availability goes back to ignoring moved bookings
+CREATE OR REPLACE FUNCTION update_schedule_availability()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        -- Decrease available seats
+        UPDATE schedules 
+        SET available_seats = available_seats - NEW.passenger_count,
+            version = version + 1
+        WHERE id = NEW.schedule_id
+        AND available_seats >= NEW.passenger_count;
+        
+        IF NOT FOUND THEN
+            RAISE EXCEPTION 'Insufficient seats available for booking';
+        END IF;
+    ELSIF TG_OP = 'DELETE' THEN
+        -- Increase available seats when booking is cancelled
+        IF OLD.booking_status = 'confirmed' THEN
+            UPDATE schedules 
+            SET available_seats = available_seats + OLD.passenger_count,
+                version = version + 1
+            WHERE id = OLD.schedule_id;
+        END IF;
+    ELSIF TG_OP = 'UPDATE' THEN
+        -- Handle booking status changes
+        IF OLD.booking_status != 'cancelled' AND NEW.booking_status = 'cancelled' THEN
+            -- Booking cancelled, return seats
+            UPDATE schedules 
+            SET available_seats = available_seats + NEW.passenger_count,
+                version = version + 1
+            WHERE id = NEW.schedule_id;
+        ELSIF OLD.booking_status = 'cancelled' AND NEW.booking_status = 'confirmed' THEN
+            -- Booking restored, decrease seats
+            UPDATE schedules 
+            SET available_seats = available_seats - NEW.passenger_count,
+                version = version + 1
+            WHERE id = NEW.schedule_id
+            AND available_seats >= NEW.passenger_count;
+            
+            IF NOT FOUND THEN
+                RAISE EXCEPTION 'Insufficient seats available for booking restoration';
+            END IF;
+        END IF;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Drop tables
+DROP TABLE IF EXISTS booking_moves CASCADE;
+DROP TABLE IF EXISTS schedule_disruptions CASCADE;
+
+-- Periods go back to the timetabled instants
+CREATE OR REPLACE FUNCTION set_schedule_periods()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.sailing_period := tstzrange(NEW.departure_at, NEW.arrival_at, '[)');
+    NEW.vessel_period := schedule_vessel_period(NEW.vessel_id, NEW.sailing_period);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_schedules_periods ON schedules;
+CREATE TRIGGER set_schedules_periods
+    BEFORE INSERT OR UPDATE OF vessel_id, departure_at, arrival_at
+    ON schedules
+    FOR EACH ROW EXECUTE FUNCTION set_schedule_periods();
+
+UPDATE schedules SET sailing_period = tstzrange(departure_at, arrival_at, '[)'),
+    vessel_period = schedule_vessel_period(vessel_id, tstzrange(departure_at, arrival_at, '[)'))
+WHERE estimated_departure_at IS NOT NULL OR estimated_arrival_at IS NOT NULL;
+
+-- Drop columns
+ALTER TABLE schedules
+    DROP CONSTRAINT IF EXISTS valid_schedule_estimates,
+    DROP COLUMN IF EXISTS departure_gate,
+    DROP COLUMN IF EXISTS estimated_arrival_at,
+    DROP COLUMN IF EXISTS estimated_departure_at;
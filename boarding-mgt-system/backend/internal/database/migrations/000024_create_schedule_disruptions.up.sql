@@ -0,0 +1,160 @@
+-- Latest estimated times and departure gate of each sailing. The timetabled
+-- departure_at and arrival_at are kept so delays can be measured against them.
+ALTER TABLE schedules
+    ADD COLUMN estimated_departure_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN estimated_arrival_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN departure_gate VARCHAR(20),
+    ADD CONSTRAINT valid_schedule_estimates CHECK (
+        COALESCE(estimated_arrival_at, arrival_at) > COALESCE(estimated_departure_at, departure_at)
+    );
+
+-- A delayed sailing holds its vessel for as long as it is now expected to
+CREATE OR REPLACE FUNCTION set_schedule_periods()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.sailing_period := tstzrange(
+        COALESCE(NEW.estimated_departure_at, NEW.departure_at),
+        COALESCE(NEW.estimated_arrival_at, NEW.arrival_at),
+        '[)'
+    );
+    NEW.vessel_period := schedule_vessel_period(NEW.vessel_id, NEW.sailing_period);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_schedules_periods ON schedules;
+CREATE TRIGGER set_schedules_periods
+    BEFORE INSERT OR UPDATE OF vessel_id, departure_at, arrival_at, estimated_departure_at, estimated_arrival_at
+    ON schedules
+    FOR EACH ROW EXECUTE FUNCTION set_schedule_periods();
+
+-- Create schedule disruptions table (every delay and gate change, kept for
+-- on-time performance reporting)
+CREATE TABLE schedule_disruptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    reason_code VARCHAR(30) NOT NULL,
+    description TEXT,
+    scheduled_departure_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    scheduled_arrival_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    estimated_departure_at TIMESTAMP WITH TIME ZONE,
+    estimated_arrival_at TIMESTAMP WITH TIME ZONE,
+    delay_minutes INTEGER NOT NULL DEFAULT 0,
+    previous_gate VARCHAR(20),
+    gate VARCHAR(20),
+    recorded_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_disruption_kind CHECK (kind IN ('delay', 'gate_change')),
+    CONSTRAINT valid_disruption_reason CHECK (reason_code IN (
+        'weather', 'technical', 'operational', 'crew', 'port_congestion',
+        'late_inbound', 'security', 'medical', 'other'
+    )),
+    CONSTRAINT valid_disruption_delay CHECK (
+        kind <> 'delay' OR (estimated_departure_at IS NOT NULL AND estimated_arrival_at IS NOT NULL)
+    ),
+    CONSTRAINT valid_disruption_gate CHECK (kind <> 'gate_change' OR gate IS NOT NULL),
+    CONSTRAINT schedule_disruptions_delay_minutes_check CHECK (delay_minutes >= 0)
+);
+
+-- Create booking moves table (bookings moved from one sailing to another)
+CREATE TABLE booking_moves (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    from_schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    to_schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    disruption_id UUID REFERENCES schedule_disruptions(id) ON DELETE SET NULL,
+    passenger_count INTEGER NOT NULL,
+    seats_changed INTEGER NOT NULL DEFAULT 0,
+    moved_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_booking_move CHECK (from_schedule_id <> to_schedule_id)
+);
+
+-- Seats follow a confirmed booking moved to another sailing
+CREATE OR REPLACE FUNCTION update_schedule_availability()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        -- Decrease available seats
+        UPDATE schedules 
+        SET available_seats = available_seats - NEW.passenger_count,
+            version = version + 1
+        WHERE id = NEW.schedule_id
+        AND available_seats >= NEW.passenger_count;
+        
+        IF NOT FOUND THEN
+            RAISE EXCEPTION 'Insufficient seats available for booking';
+        END IF;
+    ELSIF TG_OP = 'DELETE' THEN
+        -- Increase available seats when booking is cancelled
+        IF OLD.booking_status = 'confirmed' THEN
+            UPDATE schedules 
+            SET available_seats = available_seats + OLD.passenger_count,
+                version = version + 1
+            WHERE id = OLD.schedule_id;
+        END IF;
+    ELSIF TG_OP = 'UPDATE' THEN
+        -- Handle booking status changes
+        IF OLD.booking_status != 'cancelled' AND NEW.booking_status = 'cancelled' THEN
+            -- Booking cancelled, return seats
+            UPDATE schedules 
+            SET available_seats = available_seats + NEW.passenger_count,
+                version = version + 1
+            WHERE id = NEW.schedule_id;
+        ELSIF OLD.booking_status = 'cancelled' AND NEW.booking_status = 'confirmed' THEN
+            -- Booking restored, decrease seats
+            UPDATE schedules 
+            SET available_seats = available_seats - NEW.passenger_count,
+                version = version + 1
+            WHERE id = NEW.schedule_id
+            AND available_seats >= NEW.passenger_count;
+            
+            IF NOT FOUND THEN
+                RAISE EXCEPTION 'Insufficient seats available for booking restoration';
+            END IF;
+        ELSIF OLD.booking_status != 'cancelled' AND NEW.booking_status != 'cancelled'
+            AND OLD.schedule_id != NEW.schedule_id THEN
+            -- Booking moved to another sailing, seats move with it
+            UPDATE schedules 
+            SET available_seats = available_seats - NEW.passenger_count,
+                version = version + 1
+            WHERE id = NEW.schedule_id
+            AND available_seats >= NEW.passenger_count;
+            
+            IF NOT FOUND THEN
+                RAISE EXCEPTION 'Insufficient seats available for booking move';
+            END IF;
+            
+            UPDATE schedules 
+            SET available_seats = available_seats + OLD.passenger_count,
+                version = version + 1
+            WHERE id = OLD.schedule_id;
+        END IF;
+    END IF;
+    
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create indexes
+CREATE INDEX idx_schedule_disruptions_schedule_id ON schedule_disruptions(schedule_id, created_at);
+CREATE INDEX idx_schedule_disruptions_created_at ON schedule_disruptions(created_at);
+CREATE INDEX idx_booking_moves_booking_id ON booking_moves(booking_id);
+CREATE INDEX idx_booking_moves_to_schedule_id ON booking_moves(to_schedule_id);
+
+-- Create audit triggers
+CREATE TRIGGER audit_schedule_disruptions AFTER INSERT OR UPDATE OR DELETE ON schedule_disruptions
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+CREATE TRIGGER audit_booking_moves AFTER INSERT OR UPDATE OR DELETE ON booking_moves
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON COLUMN schedules.estimated_departure_at IS 'Latest expected departure instant of a delayed sailing';
+COMMENT ON COLUMN schedules.estimated_arrival_at IS 'Latest expected arrival instant of a delayed sailing';
+COMMENT ON COLUMN schedules.departure_gate IS 'Gate passengers board from';
+COMMENT ON TABLE schedule_disruptions IS 'Delays and gate changes recorded against sailings, kept for on-time performance reporting';
+COMMENT ON COLUMN schedule_disruptions.delay_minutes IS 'Minutes the sailing is expected to leave after its timetabled departure';
+COMMENT ON TABLE booking_moves IS 'Bookings moved from one sailing to another, usually because of a disruption';
+COMMENT ON COLUMN booking_moves.seats_changed IS 'Passengers who could not keep their seat number on the new sailing';
@@ -0,0 +1,23 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_schedule_disruptions_vessel_id;
+
+-- Restore constraints. Oversold sailings and recorded vessel substitutions
+-- are kept, so the constraints are not revalidated.
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS valid_capacity;
+ALTER TABLE schedules ADD CONSTRAINT valid_capacity
+    CHECK (available_seats <= total_capacity AND available_seats >= 0) NOT VALID;
+
+ALTER TABLE schedule_disruptions DROP CONSTRAINT IF EXISTS valid_disruption_capacity;
+ALTER TABLE schedule_disruptions DROP CONSTRAINT IF EXISTS valid_disruption_kind;
+ALTER TABLE schedule_disruptions ADD CONSTRAINT valid_disruption_kind
+    CHECK (kind IN ('delay', 'gate_change')) NOT VALID;
+
+COMMENT ON COLUMN schedules.available_seats IS 'Current number of seats available for booking';
+
+-- Drop columns
+ALTER TABLE schedule_disruptions
+    DROP COLUMN IF EXISTS capacity_override,
+    DROP COLUMN IF EXISTS capacity,
+    DROP COLUMN IF EXISTS previous_capacity,
+    DROP COLUMN IF EXISTS vessel_id,
+    DROP COLUMN IF EXISTS previous_vessel_id;
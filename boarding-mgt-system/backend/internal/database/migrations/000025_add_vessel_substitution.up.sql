@@ -0,0 +1,26 @@
+-- Vessel substitutions are recorded as disruptions of the sailing
+ALTER TABLE schedule_disruptions
+    ADD COLUMN previous_vessel_id UUID REFERENCES vessels(id) ON DELETE SET NULL,
+    ADD COLUMN vessel_id UUID REFERENCES vessels(id) ON DELETE SET NULL,
+    ADD COLUMN previous_capacity INTEGER,
+    ADD COLUMN capacity INTEGER,
+    ADD COLUMN capacity_override BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE schedule_disruptions DROP CONSTRAINT valid_disruption_kind;
+ALTER TABLE schedule_disruptions ADD CONSTRAINT valid_disruption_kind
+    CHECK (kind IN ('delay', 'gate_change', 'vessel_change'));
+ALTER TABLE schedule_disruptions ADD CONSTRAINT valid_disruption_capacity
+    CHECK (kind <> 'vessel_change' OR (previous_capacity IS NOT NULL AND capacity IS NOT NULL));
+
+-- A smaller vessel substituted with a capacity override can carry more
+-- passengers than it has seats. Available seats then stay below zero, and
+-- nothing more is sold, until enough bookings are cancelled.
+ALTER TABLE schedules DROP CONSTRAINT valid_capacity;
+ALTER TABLE schedules ADD CONSTRAINT valid_capacity CHECK (available_seats <= total_capacity);
+
+-- Create indexes
+CREATE INDEX idx_schedule_disruptions_vessel_id ON schedule_disruptions(vessel_id) WHERE vessel_id IS NOT NULL;
+
+-- Add comments for documentation
+COMMENT ON COLUMN schedule_disruptions.capacity_override IS 'Vessel was substituted even though it has fewer seats than passengers booked';
+COMMENT ON COLUMN schedules.available_seats IS 'Current number of seats available for booking; negative while an overridden vessel substitution leaves the sailing oversold';
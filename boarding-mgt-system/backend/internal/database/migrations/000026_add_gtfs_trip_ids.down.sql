@@ -0,0 +1,3 @@
+-- Imported schedules stay as standalone schedules
+DROP INDEX IF EXISTS idx_schedules_gtfs_trip;
+ALTER TABLE schedules DROP COLUMN IF EXISTS gtfs_trip_id;
@@ -0,0 +1,10 @@
+-- Schedules imported from a GTFS feed remember the trip they came from
+ALTER TABLE schedules ADD COLUMN gtfs_trip_id VARCHAR(255);
+
+-- Create indexes
+-- A feed imports each sailing of a trip once, however often it is imported
+CREATE UNIQUE INDEX idx_schedules_gtfs_trip ON schedules(operator_id, gtfs_trip_id, route_id, departure_date)
+    WHERE gtfs_trip_id IS NOT NULL;
+
+-- Add comments for documentation
+COMMENT ON COLUMN schedules.gtfs_trip_id IS 'trip_id of the GTFS feed the schedule was imported from';
@@ -0,0 +1,24 @@
+-- Unpublished sailings are removed so the vessel constraint can cover every
+-- sailing again
+DELETE FROM schedules WHERE unpublished;
+
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS no_vessel_double_allocation;
+ALTER TABLE schedules ADD CONSTRAINT no_vessel_double_allocation
+    EXCLUDE USING gist (vessel_id WITH =, vessel_period WITH &&)
+    WHERE (status <> 'cancelled');
+COMMENT ON CONSTRAINT no_vessel_double_allocation ON schedules IS 'Prevents allocating a vessel to overlapping sailings';
+
+-- Drop index
+DROP INDEX IF EXISTS idx_schedules_timetable_version_id;
+
+-- Drop columns
+ALTER TABLE schedules
+    DROP COLUMN IF EXISTS unpublished,
+    DROP COLUMN IF EXISTS timetable_version_id;
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_timetable_versions ON timetable_versions;
+DROP TRIGGER IF EXISTS update_timetable_versions_updated_at ON timetable_versions;
+
+-- Drop table
+DROP TABLE IF EXISTS timetable_versions CASCADE;
@@ -0,0 +1,57 @@
+-- Create timetable versions table (an operator's timetable for a season,
+-- drafted and reviewed before its sailings are put on sale)
+CREATE TABLE timetable_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operator_id UUID NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_until DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    superseded_by UUID REFERENCES timetable_versions(id) ON DELETE SET NULL,
+    submitted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    published_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_timetable_version_period CHECK (valid_until >= valid_from),
+    CONSTRAINT valid_timetable_version_status CHECK (status IN ('draft', 'review', 'published', 'superseded')),
+    -- Only one timetable is published for any day of an operator's season
+    CONSTRAINT no_overlapping_published_timetables
+        EXCLUDE USING gist (operator_id WITH =, daterange(valid_from, valid_until, '[]') WITH &&)
+        WHERE (status = 'published')
+);
+
+-- Schedules belong to the timetable version they were drafted in. Unpublished
+-- sailings are off sale and do not hold their vessel; schedules created
+-- outside a timetable version are published as before.
+ALTER TABLE schedules
+    ADD COLUMN timetable_version_id UUID REFERENCES timetable_versions(id) ON DELETE CASCADE,
+    ADD COLUMN unpublished BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE schedules DROP CONSTRAINT no_vessel_double_allocation;
+ALTER TABLE schedules ADD CONSTRAINT no_vessel_double_allocation
+    EXCLUDE USING gist (vessel_id WITH =, vessel_period WITH &&)
+    WHERE (status <> 'cancelled' AND NOT unpublished);
+
+-- Create indexes
+CREATE INDEX idx_timetable_versions_operator_id ON timetable_versions(operator_id);
+CREATE INDEX idx_schedules_timetable_version_id ON schedules(timetable_version_id)
+    WHERE timetable_version_id IS NOT NULL;
+
+-- Create trigger for timetable_versions updated_at
+CREATE TRIGGER update_timetable_versions_updated_at BEFORE UPDATE ON timetable_versions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create audit trigger
+CREATE TRIGGER audit_timetable_versions AFTER INSERT OR UPDATE OR DELETE ON timetable_versions
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON TABLE timetable_versions IS 'Seasonal timetables moving through draft, review and published';
+COMMENT ON COLUMN timetable_versions.status IS 'draft, review, published, or superseded by a later published version';
+COMMENT ON COLUMN timetable_versions.superseded_by IS 'Published version that replaced this one; rolling it back restores this one';
+COMMENT ON COLUMN schedules.timetable_version_id IS 'Timetable version the sailing was drafted in';
+COMMENT ON COLUMN schedules.unpublished IS 'Sailing is a draft or was withdrawn by a later timetable version, so it is off sale';
+COMMENT ON CONSTRAINT no_vessel_double_allocation ON schedules IS 'Prevents allocating a vessel to overlapping published sailings';
@@ -0,0 +1,9 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS audit_weather_advisories ON weather_advisories;
+DROP TRIGGER IF EXISTS update_weather_advisories_updated_at ON weather_advisories;
+
+-- Drop table
+DROP TABLE IF EXISTS weather_advisories CASCADE;
+
+-- Drop column
+ALTER TABLE vessels DROP COLUMN IF EXISTS weather_limits;
@@ -0,0 +1,45 @@
+-- Operating limits of each vessel: max_wind_knots, max_wave_height_m and
+-- min_visibility_nm, each left out when not checked
+ALTER TABLE vessels
+    ADD COLUMN weather_limits JSONB NOT NULL DEFAULT '{}';
+
+-- Create weather advisories table (sailings whose forecast exceeds their
+-- vessel's limits, for operators to review)
+CREATE TABLE weather_advisories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL UNIQUE REFERENCES schedules(id) ON DELETE CASCADE,
+    operator_id UUID NOT NULL REFERENCES operators(id) ON DELETE CASCADE,
+    vessel_id UUID NOT NULL REFERENCES vessels(id) ON DELETE CASCADE,
+    exceedances JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    action VARCHAR(20),
+    disruption_id UUID REFERENCES schedule_disruptions(id) ON DELETE SET NULL,
+    notes TEXT,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_weather_advisory_status CHECK (status IN ('open', 'cleared', 'dismissed', 'actioned')),
+    CONSTRAINT valid_weather_advisory_action CHECK (
+        (status = 'actioned' AND action IN ('delay', 'cancel')) OR (status <> 'actioned' AND action IS NULL)
+    )
+);
+
+-- Create indexes
+CREATE INDEX idx_weather_advisories_operator_status ON weather_advisories(operator_id, status);
+
+-- Create trigger for weather_advisories updated_at
+CREATE TRIGGER update_weather_advisories_updated_at BEFORE UPDATE ON weather_advisories
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create audit trigger
+CREATE TRIGGER audit_weather_advisories AFTER INSERT OR UPDATE OR DELETE ON weather_advisories
+    FOR EACH ROW EXECUTE FUNCTION audit_trigger_function();
+
+-- Add comments for documentation
+COMMENT ON COLUMN vessels.weather_limits IS 'Wind, wave height and visibility limits the vessel is cleared to sail in';
+COMMENT ON TABLE weather_advisories IS 'Sailings whose forecast exceeds their vessel''s operating limits';
+COMMENT ON COLUMN weather_advisories.exceedances IS 'Worst forecast beyond each limit at the ports and along the route';
+COMMENT ON COLUMN weather_advisories.status IS 'open, cleared by a later forecast, dismissed, or actioned with a delay or cancellation';
+COMMENT ON COLUMN weather_advisories.checked_at IS 'Last forecast check that found the limits exceeded';
@@ -0,0 +1,12 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_schedules_lifecycle;
+
+-- Drop trigger and function
+DROP TRIGGER IF EXISTS check_schedules_status_transition ON schedules;
+DROP FUNCTION IF EXISTS check_schedule_status_transition();
+
+-- Drop columns
+ALTER TABLE schedules
+    DROP CONSTRAINT IF EXISTS valid_schedule_actual_times,
+    DROP COLUMN IF EXISTS actual_arrival_at,
+    DROP COLUMN IF EXISTS actual_departure_at;
@@ -0,0 +1,40 @@
+-- When each sailing actually left and arrived, as confirmed by staff
+ALTER TABLE schedules
+    ADD COLUMN actual_departure_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN actual_arrival_at TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT valid_schedule_actual_times CHECK (
+        actual_arrival_at IS NULL OR actual_departure_at IS NULL OR actual_arrival_at > actual_departure_at
+    );
+
+-- Sailings move scheduled -> boarding -> departed -> arrived, may depart
+-- without boarding having been opened, and can only be cancelled before
+-- they depart
+CREATE OR REPLACE FUNCTION check_schedule_status_transition()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = OLD.status THEN
+        RETURN NEW;
+    END IF;
+
+    IF (OLD.status = 'scheduled' AND NEW.status IN ('boarding', 'departed', 'cancelled'))
+        OR (OLD.status = 'boarding' AND NEW.status IN ('departed', 'cancelled'))
+        OR (OLD.status = 'departed' AND NEW.status = 'arrived') THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'Schedule status cannot change from % to %', OLD.status, NEW.status;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER check_schedules_status_transition
+    BEFORE UPDATE OF status ON schedules
+    FOR EACH ROW EXECUTE FUNCTION check_schedule_status_transition();
+
+-- Create index for the sailings the lifecycle moves along
+CREATE INDEX idx_schedules_lifecycle ON schedules(departure_at)
+    WHERE status IN ('scheduled', 'boarding') AND NOT unpublished;
+
+-- Add comments for documentation
+COMMENT ON COLUMN schedules.actual_departure_at IS 'When the sailing left, as confirmed by staff';
+COMMENT ON COLUMN schedules.actual_arrival_at IS 'When the sailing arrived, as confirmed by staff';
+COMMENT ON FUNCTION check_schedule_status_transition() IS 'Refuses schedule status changes the sailing lifecycle does not allow';
@@ -0,0 +1,206 @@
+// Package disruption checks the delays and gate changes recorded against
+// sailings and measures on-time performance from them.
+//
+// Delays are measured against a sailing's timetabled departure. A sailing
+// leaving within the operator's threshold of it counts as on time.
+package disruption
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// Disruption kinds
+const (
+	KindDelay        = "delay"
+	KindGateChange   = "gate_change"
+	KindVesselChange = "vessel_change"
+)
+
+// Reasons are the reason codes a disruption can be recorded with
+var Reasons = []string{
+	"weather", "technical", "operational", "crew", "port_congestion",
+	"late_inbound", "security", "medical", "other",
+}
+
+// DefaultOnTimeThreshold is how late a sailing can leave and still be on time
+const DefaultOnTimeThreshold = 15 * time.Minute
+
+// ValidReason reports whether code is a known reason code
+func ValidReason(code string) bool {
+	for _, reason := range Reasons {
+		if reason == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy is an operator's on-time performance policy
+type Policy struct {
+	OnTimeThreshold time.Duration
+}
+
+// PolicyFromSettings reads the on-time policy from operator settings.
+// "on_time_threshold_minutes" is how late a sailing can leave and still be
+// on time.
+func PolicyFromSettings(settings map[string]interface{}) Policy {
+	policy := Policy{OnTimeThreshold: DefaultOnTimeThreshold}
+
+	if v, ok := settings["on_time_threshold_minutes"]; ok {
+		if minutes := number(v); minutes >= 0 {
+			policy.OnTimeThreshold = time.Duration(minutes) * time.Minute
+		}
+	}
+
+	return policy
+}
+
+// number reads a numeric setting, or -1 when it is not a number
+func number(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return -1
+		}
+		return f
+	default:
+		return -1
+	}
+}
+
+// Estimate works out when a delayed sailing is now expected to leave and
+// arrive. Without a new arrival the sailing keeps its timetabled duration.
+// A delay cannot bring a sailing forward.
+func Estimate(departureAt, arrivalAt, newDeparture time.Time, newArrival *time.Time) (time.Time, time.Time, error) {
+	if newDeparture.Before(departureAt) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the new departure is before the timetabled departure")
+	}
+
+	arrival := arrivalAt.Add(newDeparture.Sub(departureAt))
+	if newArrival != nil {
+		arrival = *newArrival
+	}
+	if !arrival.After(newDeparture) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the new arrival must be after the new departure")
+	}
+
+	return newDeparture.UTC(), arrival.UTC(), nil
+}
+
+// DelayMinutes is how many whole minutes after its timetabled departure a
+// sailing is expected to leave
+func DelayMinutes(departureAt, expected time.Time) int {
+	if !expected.After(departureAt) {
+		return 0
+	}
+	return int(expected.Sub(departureAt) / time.Minute)
+}
+
+// OnTime reports whether a sailing expected to leave at expected is on time
+func (p Policy) OnTime(departureAt, expected time.Time) bool {
+	return expected.Sub(departureAt) <= p.OnTimeThreshold
+}
+
+// Rate is the share of sailings that ran on time, from 0 to 1
+func Rate(onTime, ran int) float64 {
+	if ran == 0 {
+		return 0
+	}
+	return float64(onTime) / float64(ran)
+}
+
+// tally counts sailings into a rate, keeping the total delay of the late
+// ones to average later
+type tally struct {
+	rate  models.OnTimeRate
+	delay int
+}
+
+func (t *tally) add(s models.OnTimeSailing, policy Policy) {
+	t.rate.Sailings++
+	switch {
+	case s.Cancelled:
+		t.rate.Cancelled++
+	case policy.OnTime(s.DepartureAt, s.ExpectedDepartureAt):
+		t.rate.OnTime++
+	default:
+		t.rate.Delayed++
+		t.delay += DelayMinutes(s.DepartureAt, s.ExpectedDepartureAt)
+	}
+}
+
+func (t *tally) finish() models.OnTimeRate {
+	t.rate.Rate = Rate(t.rate.OnTime, t.rate.OnTime+t.rate.Delayed)
+	if t.rate.Delayed > 0 {
+		t.rate.AverageDelayMinutes = float64(t.delay) / float64(t.rate.Delayed)
+	}
+	return t.rate
+}
+
+// Summarize builds an on-time performance report. Late sailings are put
+// down to the reason of the latest delay recorded against them.
+func Summarize(sailings []models.OnTimeSailing, policy Policy, start, end time.Time) *models.OnTimeReport {
+	overall := &tally{}
+	routes := map[string]*tally{}
+	reasons := map[string]*tally{}
+
+	for _, s := range sailings {
+		overall.add(s, policy)
+
+		key := s.RouteID.String()
+		if routes[key] == nil {
+			routes[key] = &tally{rate: models.OnTimeRate{Key: key, Name: s.RouteName}}
+		}
+		routes[key].add(s, policy)
+
+		if s.Cancelled || policy.OnTime(s.DepartureAt, s.ExpectedDepartureAt) {
+			continue
+		}
+		reason := s.ReasonCode
+		if reason == "" {
+			reason = "unrecorded"
+		}
+		if reasons[reason] == nil {
+			reasons[reason] = &tally{rate: models.OnTimeRate{Key: reason, Name: reason}}
+		}
+		reasons[reason].add(s, policy)
+	}
+
+	total := overall.finish()
+	return &models.OnTimeReport{
+		PeriodStart:         start,
+		PeriodEnd:           end,
+		ThresholdMinutes:    int(policy.OnTimeThreshold / time.Minute),
+		Sailings:            total.Sailings,
+		OnTime:              total.OnTime,
+		Delayed:             total.Delayed,
+		Cancelled:           total.Cancelled,
+		Rate:                total.Rate,
+		AverageDelayMinutes: total.AverageDelayMinutes,
+		ByRoute:             rates(routes),
+		ByReason:            rates(reasons),
+	}
+}
+
+// rates sorts rates by name and fills in each rate
+func rates(byKey map[string]*tally) []models.OnTimeRate {
+	list := make([]models.OnTimeRate, 0, len(byKey))
+	for _, t := range byKey {
+		list = append(list, t.finish())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
@@ -0,0 +1,119 @@
+package disruption
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestPolicyFromSettings(t *testing.T) {
+	assert.Equal(t, DefaultOnTimeThreshold, PolicyFromSettings(nil).OnTimeThreshold)
+
+	policy := PolicyFromSettings(map[string]interface{}{"on_time_threshold_minutes": float64(5)})
+	assert.Equal(t, 5*time.Minute, policy.OnTimeThreshold)
+
+	policy = PolicyFromSettings(map[string]interface{}{"on_time_threshold_minutes": "0"})
+	assert.Equal(t, time.Duration(0), policy.OnTimeThreshold)
+
+	policy = PolicyFromSettings(map[string]interface{}{"on_time_threshold_minutes": "soon"})
+	assert.Equal(t, DefaultOnTimeThreshold, policy.OnTimeThreshold)
+}
+
+func TestValidReason(t *testing.T) {
+	assert.True(t, ValidReason("weather"))
+	assert.True(t, ValidReason("late_inbound"))
+	assert.False(t, ValidReason("Weather"))
+	assert.False(t, ValidReason(""))
+}
+
+func TestEstimate(t *testing.T) {
+	departs, arrives := at("2025-07-01 09:00"), at("2025-07-01 10:30")
+
+	t.Run("Keeps the sailing's duration", func(t *testing.T) {
+		departure, arrival, err := Estimate(departs, arrives, at("2025-07-01 09:45"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, at("2025-07-01 09:45"), departure)
+		assert.Equal(t, at("2025-07-01 11:15"), arrival)
+	})
+
+	t.Run("Takes a new arrival", func(t *testing.T) {
+		newArrival := at("2025-07-01 11:00")
+		_, arrival, err := Estimate(departs, arrives, at("2025-07-01 09:45"), &newArrival)
+		require.NoError(t, err)
+		assert.Equal(t, newArrival, arrival)
+	})
+
+	t.Run("Cannot bring a sailing forward", func(t *testing.T) {
+		_, _, err := Estimate(departs, arrives, at("2025-07-01 08:45"), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Arrival must follow departure", func(t *testing.T) {
+		newArrival := at("2025-07-01 09:30")
+		_, _, err := Estimate(departs, arrives, at("2025-07-01 09:45"), &newArrival)
+		assert.Error(t, err)
+	})
+}
+
+func TestDelayMinutes(t *testing.T) {
+	assert.Equal(t, 0, DelayMinutes(at("2025-07-01 09:00"), at("2025-07-01 09:00")))
+	assert.Equal(t, 45, DelayMinutes(at("2025-07-01 09:00"), at("2025-07-01 09:45")))
+	assert.Equal(t, 0, DelayMinutes(at("2025-07-01 09:00"), at("2025-07-01 08:55")))
+}
+
+func TestSummarize(t *testing.T) {
+	north, south := uuid.New(), uuid.New()
+	policy := Policy{OnTimeThreshold: 15 * time.Minute}
+	departs := at("2025-07-01 09:00")
+
+	sailings := []models.OnTimeSailing{
+		{RouteID: north, RouteName: "North", DepartureAt: departs, ExpectedDepartureAt: departs},
+		{RouteID: north, RouteName: "North", DepartureAt: departs, ExpectedDepartureAt: departs.Add(15 * time.Minute), ReasonCode: "crew"},
+		{RouteID: north, RouteName: "North", DepartureAt: departs, ExpectedDepartureAt: departs.Add(40 * time.Minute), ReasonCode: "weather"},
+		{RouteID: south, RouteName: "South", DepartureAt: departs, ExpectedDepartureAt: departs.Add(20 * time.Minute), ReasonCode: "weather"},
+		{RouteID: south, RouteName: "South", DepartureAt: departs, ExpectedDepartureAt: departs, Cancelled: true},
+	}
+
+	report := Summarize(sailings, policy, at("2025-07-01 00:00"), at("2025-07-02 00:00"))
+	assert.Equal(t, 15, report.ThresholdMinutes)
+	assert.Equal(t, 5, report.Sailings)
+	assert.Equal(t, 2, report.OnTime)
+	assert.Equal(t, 2, report.Delayed)
+	assert.Equal(t, 1, report.Cancelled)
+	assert.Equal(t, 0.5, report.Rate)
+	assert.Equal(t, 30.0, report.AverageDelayMinutes)
+
+	require.Len(t, report.ByRoute, 2)
+	assert.Equal(t, "North", report.ByRoute[0].Name)
+	assert.Equal(t, 2, report.ByRoute[0].OnTime)
+	assert.InDelta(t, 2.0/3.0, report.ByRoute[0].Rate, 1e-9)
+	assert.Equal(t, "South", report.ByRoute[1].Name)
+	assert.Equal(t, 1, report.ByRoute[1].Cancelled)
+	assert.Equal(t, 0.0, report.ByRoute[1].Rate)
+
+	// Only late sailings are put down to a reason
+	require.Len(t, report.ByReason, 1)
+	assert.Equal(t, "weather", report.ByReason[0].Key)
+	assert.Equal(t, 2, report.ByReason[0].Delayed)
+	assert.Equal(t, 30.0, report.ByReason[0].AverageDelayMinutes)
+}
+
+func TestSummarizeEmpty(t *testing.T) {
+	report := Summarize(nil, Policy{OnTimeThreshold: DefaultOnTimeThreshold}, at("2025-07-01 00:00"), at("2025-07-02 00:00"))
+	assert.Equal(t, 0, report.Sailings)
+	assert.Equal(t, 0.0, report.Rate)
+	assert.Empty(t, report.ByRoute)
+	assert.NotNil(t, report.ByReason)
+}
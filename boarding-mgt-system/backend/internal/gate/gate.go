@@ -0,0 +1,152 @@
+// Package gate decides whether a ticket scanned at a boarding gate may check
+// in or board, and why not when it may not.
+package gate
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scan actions
+const (
+	ActionCheckIn = "check_in"
+	ActionBoard   = "board"
+)
+
+// Scan results
+const (
+	ResultAccepted   = "accepted"
+	ResultDenied     = "denied"
+	ResultOverridden = "overridden"
+)
+
+// Ticket check-in statuses
+const (
+	StatusNotCheckedIn = "not_checked_in"
+	StatusCheckedIn    = "checked_in"
+	StatusBoarded      = "boarded"
+	// StatusNoShow marks tickets that had not boarded when the sailing departed
+	StatusNoShow = "no_show"
+)
+
+// Deny reasons
+const (
+	ReasonInvalidCode       = "invalid_code"
+	ReasonTicketNotFound    = "ticket_not_found"
+	ReasonCancelledBooking  = "cancelled_booking"
+	ReasonWrongSchedule     = "wrong_schedule"
+	ReasonScheduleCancelled = "schedule_cancelled"
+	ReasonAlreadyBoarded    = "already_boarded"
+	ReasonAlreadyCheckedIn  = "already_checked_in"
+	ReasonUnpaid            = "unpaid"
+	ReasonCheckInClosed     = "check_in_closed"
+	ReasonBoardingClosed    = "boarding_closed"
+)
+
+var messages = map[string]string{
+	ReasonInvalidCode:       "Ticket code is not valid",
+	ReasonTicketNotFound:    "Ticket does not exist",
+	ReasonCancelledBooking:  "Booking has been cancelled",
+	ReasonWrongSchedule:     "Ticket is for a different sailing",
+	ReasonScheduleCancelled: "Sailing has been cancelled",
+	ReasonAlreadyBoarded:    "Passenger has already boarded",
+	ReasonAlreadyCheckedIn:  "Passenger is already checked in",
+	ReasonUnpaid:            "Booking has not been paid in full",
+	ReasonCheckInClosed:     "Check-in has closed for this sailing",
+	ReasonBoardingClosed:    "Boarding has closed for this sailing",
+}
+
+// Message describes a deny reason for gate staff
+func Message(reason string) string {
+	return messages[reason]
+}
+
+// Overridable reports whether a supervisor may let a passenger through
+// despite a deny reason. Problems with the ticket itself cannot be overridden.
+func Overridable(reason string) bool {
+	switch reason {
+	case ReasonUnpaid, ReasonCheckInClosed, ReasonBoardingClosed:
+		return true
+	}
+	return false
+}
+
+// StatusAfter returns the check-in status a ticket has after an accepted scan
+func StatusAfter(action string) string {
+	if action == ActionBoard {
+		return StatusBoarded
+	}
+	return StatusCheckedIn
+}
+
+// Ticket is the state of a scanned ticket, its booking and its sailing
+type Ticket struct {
+	ScheduleID     uuid.UUID
+	CheckInStatus  string
+	BookingStatus  string
+	PaymentStatus  string
+	ScheduleStatus string
+	Departure      time.Time
+}
+
+// Policy sets when check-in and boarding close relative to departure
+type Policy struct {
+	CheckInClosesBefore  time.Duration
+	BoardingClosesBefore time.Duration
+}
+
+// DefaultPolicy closes check-in half an hour and boarding five minutes
+// before departure
+var DefaultPolicy = Policy{
+	CheckInClosesBefore:  30 * time.Minute,
+	BoardingClosesBefore: 5 * time.Minute,
+}
+
+// Decision is the outcome of a scan: the ticket's new check-in status, or the
+// reason it was refused
+type Decision struct {
+	Status string
+	Reason string
+}
+
+// Allowed reports whether the scan may proceed
+func (d Decision) Allowed() bool {
+	return d.Reason == ""
+}
+
+// Evaluate decides a scan of a ticket at the gate of scheduleID. Boarding a
+// ticket that was never checked in is allowed and checks it in on the way.
+func (p Policy) Evaluate(t Ticket, action string, scheduleID uuid.UUID, now time.Time) Decision {
+	closes := t.Departure.Add(-p.CheckInClosesBefore)
+	closedReason := ReasonCheckInClosed
+	if action == ActionBoard {
+		closes = t.Departure.Add(-p.BoardingClosesBefore)
+		closedReason = ReasonBoardingClosed
+	}
+
+	deny := func(reason string) Decision {
+		return Decision{Status: t.CheckInStatus, Reason: reason}
+	}
+
+	switch {
+	case t.BookingStatus == "cancelled" || t.BookingStatus == "refunded":
+		return deny(ReasonCancelledBooking)
+	case t.ScheduleID != scheduleID:
+		return deny(ReasonWrongSchedule)
+	case t.ScheduleStatus == "cancelled":
+		return deny(ReasonScheduleCancelled)
+	case t.CheckInStatus == StatusBoarded:
+		return deny(ReasonAlreadyBoarded)
+	case action == ActionCheckIn && t.CheckInStatus == StatusCheckedIn:
+		return deny(ReasonAlreadyCheckedIn)
+	case t.PaymentStatus != "paid":
+		return deny(ReasonUnpaid)
+	case t.ScheduleStatus == "departed" || t.ScheduleStatus == "arrived":
+		return deny(ReasonBoardingClosed)
+	case now.After(closes):
+		return deny(closedReason)
+	}
+
+	return Decision{Status: StatusAfter(action)}
+}
@@ -0,0 +1,82 @@
+package gate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	scheduleID := uuid.New()
+	departure := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	ticket := Ticket{
+		ScheduleID:     scheduleID,
+		CheckInStatus:  StatusNotCheckedIn,
+		BookingStatus:  "confirmed",
+		PaymentStatus:  "paid",
+		ScheduleStatus: "scheduled",
+		Departure:      departure,
+	}
+	early := departure.Add(-2 * time.Hour)
+
+	t.Run("Check in then board", func(t *testing.T) {
+		d := DefaultPolicy.Evaluate(ticket, ActionCheckIn, scheduleID, early)
+		assert.True(t, d.Allowed())
+		assert.Equal(t, StatusCheckedIn, d.Status)
+
+		checkedIn := ticket
+		checkedIn.CheckInStatus = StatusCheckedIn
+		d = DefaultPolicy.Evaluate(checkedIn, ActionBoard, scheduleID, departure.Add(-10*time.Minute))
+		assert.True(t, d.Allowed())
+		assert.Equal(t, StatusBoarded, d.Status)
+
+		// Boarding directly checks in on the way
+		d = DefaultPolicy.Evaluate(ticket, ActionBoard, scheduleID, early)
+		assert.Equal(t, StatusBoarded, d.Status)
+	})
+
+	tests := []struct {
+		name   string
+		modify func(*Ticket)
+		action string
+		at     time.Time
+		reason string
+	}{
+		{"Cancelled booking", func(t *Ticket) { t.BookingStatus = "cancelled" }, ActionCheckIn, early, ReasonCancelledBooking},
+		{"Refunded booking", func(t *Ticket) { t.BookingStatus = "refunded" }, ActionBoard, early, ReasonCancelledBooking},
+		{"Other sailing", func(t *Ticket) { t.ScheduleID = uuid.New() }, ActionBoard, early, ReasonWrongSchedule},
+		{"Cancelled sailing", func(t *Ticket) { t.ScheduleStatus = "cancelled" }, ActionCheckIn, early, ReasonScheduleCancelled},
+		{"Boarded twice", func(t *Ticket) { t.CheckInStatus = StatusBoarded }, ActionBoard, early, ReasonAlreadyBoarded},
+		{"Checked in twice", func(t *Ticket) { t.CheckInStatus = StatusCheckedIn }, ActionCheckIn, early, ReasonAlreadyCheckedIn},
+		{"Balance due", func(t *Ticket) { t.PaymentStatus = "partially_paid" }, ActionCheckIn, early, ReasonUnpaid},
+		{"Departed", func(t *Ticket) { t.ScheduleStatus = "departed" }, ActionBoard, early, ReasonBoardingClosed},
+		{"Check-in closed", func(t *Ticket) {}, ActionCheckIn, departure.Add(-20 * time.Minute), ReasonCheckInClosed},
+		{"Boarding closed", func(t *Ticket) {}, ActionBoard, departure.Add(-time.Minute), ReasonBoardingClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanned := ticket
+			tt.modify(&scanned)
+
+			d := DefaultPolicy.Evaluate(scanned, tt.action, scheduleID, tt.at)
+			assert.False(t, d.Allowed())
+			assert.Equal(t, tt.reason, d.Reason)
+			assert.Equal(t, scanned.CheckInStatus, d.Status, "a denied scan leaves the ticket as it was")
+			assert.NotEmpty(t, Message(d.Reason))
+		})
+	}
+}
+
+func TestOverridable(t *testing.T) {
+	assert.True(t, Overridable(ReasonUnpaid))
+	assert.True(t, Overridable(ReasonBoardingClosed))
+	assert.True(t, Overridable(ReasonCheckInClosed))
+
+	assert.False(t, Overridable(ReasonInvalidCode))
+	assert.False(t, Overridable(ReasonCancelledBooking))
+	assert.False(t, Overridable(ReasonWrongSchedule))
+	assert.False(t, Overridable(ReasonAlreadyBoarded))
+}
@@ -0,0 +1,95 @@
+package gtfs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// Export builds the feed of an operator's sailings. Ports are stops under
+// their codes and sailings are trips under their schedule IDs. Each day with
+// sailings, reckoned in the agency's time zone, is a service of its own, so
+// the feed carries exactly the sailings it was built from. Cancelled
+// sailings are left out.
+func Export(agency Agency, ports []*models.Port, routes []*models.Route, schedules []*models.Schedule) (*Feed, error) {
+	location, err := time.LoadLocation(agency.Timezone)
+	if err != nil || agency.Timezone == "" {
+		return nil, fmt.Errorf("unknown agency time zone %q", agency.Timezone)
+	}
+
+	feed := &Feed{
+		Agencies:      []Agency{agency},
+		Stops:         []Stop{},
+		Routes:        []Route{},
+		Trips:         []Trip{},
+		StopTimes:     []StopTime{},
+		Calendars:     []Calendar{},
+		CalendarDates: []CalendarDate{},
+	}
+
+	stops := make(map[string]string, len(ports))
+	for _, port := range ports {
+		stop := Stop{ID: port.Code, Code: port.Code, Name: port.Name, Timezone: port.Timezone}
+		if port.Coordinates != nil {
+			stop.Lat, stop.Lon = &port.Coordinates.Latitude, &port.Coordinates.Longitude
+		}
+		feed.Stops = append(feed.Stops, stop)
+		stops[port.ID.String()] = port.Code
+	}
+	sort.Slice(feed.Stops, func(i, j int) bool { return feed.Stops[i].ID < feed.Stops[j].ID })
+
+	byID := make(map[string]*models.Route, len(routes))
+	for _, route := range routes {
+		feed.Routes = append(feed.Routes, Route{
+			ID:       route.ID.String(),
+			AgencyID: agency.ID,
+			LongName: route.Name,
+			Type:     RouteTypeFerry,
+		})
+		byID[route.ID.String()] = route
+	}
+	sort.Slice(feed.Routes, func(i, j int) bool { return feed.Routes[i].LongName < feed.Routes[j].LongName })
+
+	sailings := append([]*models.Schedule{}, schedules...)
+	sort.SliceStable(sailings, func(i, j int) bool { return sailings[i].DepartureAt.Before(sailings[j].DepartureAt) })
+
+	services := map[string]bool{}
+	for _, schedule := range sailings {
+		route, ok := byID[schedule.RouteID.String()]
+		if !ok || schedule.Status == "cancelled" {
+			continue
+		}
+		from, to := stops[route.DeparturePortID.String()], stops[route.ArrivalPortID.String()]
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("route %s calls at a port missing from the export", route.Name)
+		}
+
+		local := schedule.DepartureAt.In(location)
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+		serviceID := date.Format(DateFormat)
+		if !services[serviceID] {
+			services[serviceID] = true
+			calendar := Calendar{ServiceID: serviceID, Start: date, End: date}
+			calendar.Weekdays[(int(date.Weekday())+6)%7] = true
+			feed.Calendars = append(feed.Calendars, calendar)
+		}
+
+		start := ServiceDayStart(date, location)
+		tripID := schedule.ID.String()
+		headsign := ""
+		if route.ArrivalPort != nil {
+			headsign = route.ArrivalPort.Name
+		}
+		feed.Trips = append(feed.Trips, Trip{ID: tripID, RouteID: route.ID.String(), ServiceID: serviceID, Headsign: headsign})
+
+		departure, arrival := schedule.DepartureAt.Sub(start), schedule.ArrivalAt.Sub(start)
+		feed.StopTimes = append(feed.StopTimes,
+			StopTime{TripID: tripID, Sequence: 1, StopID: from, Arrival: departure, Departure: departure, Timed: true},
+			StopTime{TripID: tripID, Sequence: 2, StopID: to, Arrival: arrival, Departure: arrival, Timed: true},
+		)
+	}
+
+	return feed, nil
+}
@@ -0,0 +1,303 @@
+// Package gtfs reads and writes timetables in the static General Transit
+// Feed Specification (GTFS) that journey planners and maps consume.
+//
+// A feed is a zip of CSV files. Only the files and fields a ferry timetable
+// needs are handled: agency, stops, routes, trips, stop_times, calendar and
+// calendar_dates.
+//
+// Stop times are durations since the start of a service day in the agency's
+// time zone, which is noon less twelve hours so days with a daylight saving
+// change still add up, and run past 24:00:00 for sailings after midnight.
+package gtfs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RouteTypeFerry is the route_type of ferry routes
+const RouteTypeFerry = 4
+
+// DateFormat is the layout of GTFS dates
+const DateFormat = "20060102"
+
+// Agency is the operator running the routes of a feed
+type Agency struct {
+	ID       string
+	Name     string
+	URL      string
+	Timezone string
+	Lang     string
+	Phone    string
+}
+
+// Stop is a port. Feeds made for this platform use port codes as stop IDs.
+type Stop struct {
+	ID       string
+	Code     string
+	Name     string
+	Lat      *float64
+	Lon      *float64
+	Timezone string
+}
+
+// Route is a route of a feed. Only ferry routes are imported.
+type Route struct {
+	ID        string
+	AgencyID  string
+	ShortName string
+	LongName  string
+	Type      int
+}
+
+// Name is the route's long name, or its short one when it has none
+func (r Route) Name() string {
+	if r.LongName != "" {
+		return r.LongName
+	}
+	return r.ShortName
+}
+
+// Trip is one run of a route on the days of a service
+type Trip struct {
+	ID        string
+	RouteID   string
+	ServiceID string
+	Headsign  string
+	BlockID   string
+}
+
+// StopTime is a trip's call at a stop. Untimed calls, whose times journey
+// planners interpolate, have Timed unset.
+type StopTime struct {
+	TripID    string
+	Sequence  int
+	StopID    string
+	Arrival   time.Duration
+	Departure time.Duration
+	Timed     bool
+}
+
+// Calendar is a service running on some days of the week between two dates
+type Calendar struct {
+	ServiceID string
+	Weekdays  [7]bool // Monday first
+	Start     time.Time
+	End       time.Time
+}
+
+// CalendarDate adds a service on a date or, unless Added, removes it
+type CalendarDate struct {
+	ServiceID string
+	Date      time.Time
+	Added     bool
+}
+
+// Feed is a GTFS feed
+type Feed struct {
+	Agencies      []Agency
+	Stops         []Stop
+	Routes        []Route
+	Trips         []Trip
+	StopTimes     []StopTime
+	Calendars     []Calendar
+	CalendarDates []CalendarDate
+}
+
+// Leg is a sailing a trip makes between two consecutive stops on a service
+// date
+type Leg struct {
+	TripID      string
+	RouteID     string
+	BlockID     string
+	FromStopID  string
+	ToStopID    string
+	ServiceDate time.Time
+	DepartureAt time.Time
+	ArrivalAt   time.Time
+}
+
+// SkippedTrip is a trip of a feed that cannot be imported
+type SkippedTrip struct {
+	TripID string
+	Reason string
+}
+
+// IsFerry reports whether a route type is a ferry: the basic type or the
+// extended water transport and ferry service types
+func IsFerry(routeType int) bool {
+	return routeType == RouteTypeFerry || routeType == 1000 || routeType == 1200
+}
+
+// ParseTime reads a stop time such as "7:05:00" or "25:30:00"
+func ParseTime(value string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	var fields [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (i > 0 && (len(part) != 2 || n > 59)) {
+			return 0, fmt.Errorf("invalid time %q", value)
+		}
+		fields[i] = n
+	}
+
+	return time.Duration(fields[0])*time.Hour + time.Duration(fields[1])*time.Minute + time.Duration(fields[2])*time.Second, nil
+}
+
+// FormatTime writes a stop time, going past 24:00:00 when it falls on the
+// next day
+func FormatTime(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// ServiceDayStart is the instant stop times on a service date count from:
+// noon less twelve hours in the agency's time zone
+func ServiceDayStart(date time.Time, location *time.Location) time.Time {
+	noon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, location)
+	return noon.Add(-12 * time.Hour)
+}
+
+// Location loads the agency time zone all of the feed's stop times are in
+func (f *Feed) Location() (*time.Location, error) {
+	if len(f.Agencies) == 0 || f.Agencies[0].Timezone == "" {
+		return nil, fmt.Errorf("feed has no agency time zone")
+	}
+	location, err := time.LoadLocation(f.Agencies[0].Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown agency time zone %q", f.Agencies[0].Timezone)
+	}
+	return location, nil
+}
+
+// ServiceDates lists the dates each service runs on between two dates,
+// inclusive, from its calendar and calendar dates
+func (f *Feed) ServiceDates(from, until time.Time) map[string][]time.Time {
+	running := map[string]map[string]time.Time{}
+	run := func(serviceID string, date time.Time) {
+		if running[serviceID] == nil {
+			running[serviceID] = map[string]time.Time{}
+		}
+		running[serviceID][date.Format(DateFormat)] = date
+	}
+
+	for _, calendar := range f.Calendars {
+		start, end := calendar.Start, calendar.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(until) {
+			end = until
+		}
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			if calendar.Weekdays[(int(date.Weekday())+6)%7] {
+				run(calendar.ServiceID, date)
+			}
+		}
+	}
+
+	for _, exception := range f.CalendarDates {
+		if exception.Date.Before(from) || exception.Date.After(until) {
+			continue
+		}
+		if exception.Added {
+			run(exception.ServiceID, exception.Date)
+		} else if running[exception.ServiceID] != nil {
+			delete(running[exception.ServiceID], exception.Date.Format(DateFormat))
+		}
+	}
+
+	dates := make(map[string][]time.Time, len(running))
+	for serviceID, days := range running {
+		for _, date := range days {
+			dates[serviceID] = append(dates[serviceID], date)
+		}
+		sort.Slice(dates[serviceID], func(i, j int) bool { return dates[serviceID][i].Before(dates[serviceID][j]) })
+	}
+	return dates
+}
+
+// Legs expands the trips of the feed's ferry routes into the sailings they
+// make between two service dates, inclusive, one per pair of consecutive
+// stops. Trips that cannot be sailed as timetabled are skipped.
+func (f *Feed) Legs(from, until time.Time) ([]Leg, []SkippedTrip, error) {
+	location, err := f.Location()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	routes := make(map[string]Route, len(f.Routes))
+	for _, route := range f.Routes {
+		routes[route.ID] = route
+	}
+
+	calls := map[string][]StopTime{}
+	for _, stopTime := range f.StopTimes {
+		calls[stopTime.TripID] = append(calls[stopTime.TripID], stopTime)
+	}
+
+	dates := f.ServiceDates(from, until)
+
+	legs := []Leg{}
+	skipped := []SkippedTrip{}
+	for _, trip := range f.Trips {
+		route, ok := routes[trip.RouteID]
+		if !ok {
+			skipped = append(skipped, SkippedTrip{trip.ID, fmt.Sprintf("unknown route %q", trip.RouteID)})
+			continue
+		}
+		if !IsFerry(route.Type) {
+			skipped = append(skipped, SkippedTrip{trip.ID, fmt.Sprintf("route %q is not a ferry route", route.ID)})
+			continue
+		}
+
+		stops := calls[trip.ID]
+		sort.Slice(stops, func(i, j int) bool { return stops[i].Sequence < stops[j].Sequence })
+		if reason := checkCalls(stops); reason != "" {
+			skipped = append(skipped, SkippedTrip{trip.ID, reason})
+			continue
+		}
+
+		for _, date := range dates[trip.ServiceID] {
+			start := ServiceDayStart(date, location)
+			for i := 1; i < len(stops); i++ {
+				legs = append(legs, Leg{
+					TripID:      trip.ID,
+					RouteID:     trip.RouteID,
+					BlockID:     trip.BlockID,
+					FromStopID:  stops[i-1].StopID,
+					ToStopID:    stops[i].StopID,
+					ServiceDate: date,
+					DepartureAt: start.Add(stops[i-1].Departure).UTC(),
+					ArrivalAt:   start.Add(stops[i].Arrival).UTC(),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(legs, func(i, j int) bool { return legs[i].DepartureAt.Before(legs[j].DepartureAt) })
+	return legs, skipped, nil
+}
+
+// checkCalls explains why a trip's calls, in sequence, cannot be sailed
+func checkCalls(stops []StopTime) string {
+	if len(stops) < 2 {
+		return "trip calls at fewer than two stops"
+	}
+	for i, stop := range stops {
+		if !stop.Timed {
+			return "trip has calls without times"
+		}
+		if i > 0 && stop.Arrival <= stops[i-1].Departure {
+			return fmt.Sprintf("trip arrives at stop %q before it leaves the one before", stop.StopID)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,190 @@
+package gtfs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse(DateFormat, s)
+	return d
+}
+
+func instant(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t.UTC()
+}
+
+func TestParseTime(t *testing.T) {
+	d, err := ParseTime("7:05:00")
+	require.NoError(t, err)
+	assert.Equal(t, 7*time.Hour+5*time.Minute, d)
+
+	d, err = ParseTime("25:30:00")
+	require.NoError(t, err)
+	assert.Equal(t, "25:30:00", FormatTime(d))
+
+	for _, invalid := range []string{"", "7:05", "07:5:00", "07:60:00", "aa:00:00"} {
+		_, err := ParseTime(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestServiceDates(t *testing.T) {
+	feed := &Feed{
+		Calendars: []Calendar{
+			// Weekends in June 2025
+			{ServiceID: "weekend", Weekdays: [7]bool{5: true, 6: true}, Start: date("20250601"), End: date("20250630")},
+		},
+		CalendarDates: []CalendarDate{
+			{ServiceID: "weekend", Date: date("20250607"), Added: false},
+			{ServiceID: "weekend", Date: date("20250609"), Added: true},
+			{ServiceID: "holiday", Date: date("20250610"), Added: true},
+			{ServiceID: "holiday", Date: date("20250720"), Added: true},
+		},
+	}
+
+	dates := feed.ServiceDates(date("20250601"), date("20250615"))
+	assert.Equal(t, []time.Time{date("20250601"), date("20250608"), date("20250609"), date("20250614"), date("20250615")}, dates["weekend"])
+	assert.Equal(t, []time.Time{date("20250610")}, dates["holiday"])
+}
+
+func TestLegs(t *testing.T) {
+	feed := &Feed{
+		Agencies: []Agency{{ID: "FF", Name: "FerryFlow", Timezone: "Europe/Athens"}},
+		Routes: []Route{
+			{ID: "island-hop", LongName: "Piraeus - Syros - Tinos", Type: RouteTypeFerry},
+			{ID: "bus", LongName: "Port shuttle", Type: 3},
+		},
+		Trips: []Trip{
+			{ID: "night", RouteID: "island-hop", ServiceID: "daily", BlockID: "v1"},
+			{ID: "shuttle", RouteID: "bus", ServiceID: "daily"},
+			{ID: "untimed", RouteID: "island-hop", ServiceID: "daily"},
+		},
+		StopTimes: []StopTime{
+			{TripID: "night", Sequence: 2, StopID: "SYR", Arrival: 26 * time.Hour, Departure: 26*time.Hour + 30*time.Minute, Timed: true},
+			{TripID: "night", Sequence: 1, StopID: "PIR", Arrival: 23 * time.Hour, Departure: 23 * time.Hour, Timed: true},
+			{TripID: "night", Sequence: 3, StopID: "TIN", Arrival: 28 * time.Hour, Departure: 28 * time.Hour, Timed: true},
+			{TripID: "shuttle", Sequence: 1, StopID: "PIR", Timed: true},
+			{TripID: "shuttle", Sequence: 2, StopID: "SYR", Arrival: time.Hour, Timed: true},
+			{TripID: "untimed", Sequence: 1, StopID: "PIR", Arrival: time.Hour, Departure: time.Hour, Timed: true},
+			{TripID: "untimed", Sequence: 2, StopID: "SYR"},
+		},
+		Calendars: []Calendar{
+			{ServiceID: "daily", Weekdays: [7]bool{true, true, true, true, true, true, true}, Start: date("20250601"), End: date("20251231")},
+		},
+	}
+
+	legs, skipped, err := feed.Legs(date("20250601"), date("20250601"))
+	require.NoError(t, err)
+
+	require.Len(t, legs, 2)
+	assert.Equal(t, Leg{
+		TripID: "night", RouteID: "island-hop", BlockID: "v1", FromStopID: "PIR", ToStopID: "SYR",
+		ServiceDate: date("20250601"),
+		DepartureAt: instant("2025-06-01T20:00:00Z"), // 23:00 in Athens (UTC+3)
+		ArrivalAt:   instant("2025-06-01T23:00:00Z"),
+	}, legs[0])
+	assert.Equal(t, "TIN", legs[1].ToStopID)
+	assert.Equal(t, instant("2025-06-01T23:30:00Z"), legs[1].DepartureAt)
+	assert.Equal(t, instant("2025-06-02T01:00:00Z"), legs[1].ArrivalAt)
+
+	assert.ElementsMatch(t, []SkippedTrip{
+		{"shuttle", `route "bus" is not a ferry route`},
+		{"untimed", "trip has calls without times"},
+	}, skipped)
+
+	// On the day the clocks go back, times after the change still read as
+	// the clock does: 23:00 is 21:00 UTC once Athens is back on UTC+2
+	legs, _, err = feed.Legs(date("20251026"), date("20251026"))
+	require.NoError(t, err)
+	assert.Equal(t, instant("2025-10-26T21:00:00Z"), legs[0].DepartureAt)
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	piraeus := &models.Port{ID: uuid.New(), Code: "PIR", Name: "Piraeus", Timezone: "Europe/Athens",
+		Coordinates: &models.Coordinates{Latitude: 37.9422, Longitude: 23.6465}}
+	syros := &models.Port{ID: uuid.New(), Code: "SYR", Name: "Syros", Timezone: "Europe/Athens"}
+	route := &models.Route{ID: uuid.New(), Name: "Piraeus - Syros", DeparturePortID: piraeus.ID, ArrivalPortID: syros.ID, ArrivalPort: syros}
+
+	overnight := &models.Schedule{ID: uuid.New(), RouteID: route.ID, Status: "scheduled",
+		DepartureAt: instant("2025-06-01T20:30:00Z"), ArrivalAt: instant("2025-06-01T22:15:00Z")}
+	morning := &models.Schedule{ID: uuid.New(), RouteID: route.ID, Status: "scheduled",
+		DepartureAt: instant("2025-06-01T04:00:00Z"), ArrivalAt: instant("2025-06-01T07:00:00Z")}
+	cancelled := &models.Schedule{ID: uuid.New(), RouteID: route.ID, Status: "cancelled",
+		DepartureAt: instant("2025-06-02T04:00:00Z"), ArrivalAt: instant("2025-06-02T07:00:00Z")}
+
+	agency := Agency{ID: "FF", Name: "FerryFlow", URL: "https://ferryflow.example", Timezone: "Europe/Athens"}
+	feed, err := Export(agency, []*models.Port{syros, piraeus}, []*models.Route{route}, []*models.Schedule{overnight, morning, cancelled})
+	require.NoError(t, err)
+
+	assert.Len(t, feed.Trips, 2)
+	assert.Len(t, feed.Calendars, 1, "both sailings leave on 1 June in Athens")
+	assert.Equal(t, "PIR", feed.Stops[0].ID)
+	assert.Equal(t, "07:00:00", FormatTime(feed.StopTimes[0].Departure))
+	assert.Equal(t, "23:30:00", FormatTime(feed.StopTimes[2].Departure))
+	assert.Equal(t, "25:15:00", FormatTime(feed.StopTimes[3].Arrival), "the overnight sailing arrives after midnight")
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, feed))
+	read, err := Read(buf.Bytes())
+	require.NoError(t, err)
+
+	assert.Equal(t, feed.Agencies, read.Agencies)
+	assert.Equal(t, feed.Stops, read.Stops)
+	assert.Equal(t, feed.Routes, read.Routes)
+	assert.Equal(t, feed.Trips, read.Trips)
+	assert.Equal(t, feed.StopTimes, read.StopTimes)
+	assert.Equal(t, feed.Calendars, read.Calendars)
+
+	legs, skipped, err := read.Legs(date("20250601"), date("20250602"))
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+	require.Len(t, legs, 2)
+	assert.Equal(t, morning.DepartureAt, legs[0].DepartureAt)
+	assert.Equal(t, overnight.ArrivalAt, legs[1].ArrivalAt)
+	assert.Equal(t, overnight.ID.String(), legs[1].TripID)
+}
+
+func TestReadRequiresCalendar(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, &Feed{}))
+
+	_, err := Read(buf.Bytes())
+	assert.NoError(t, err, "an empty calendar.txt still counts")
+
+	_, err = Read([]byte("not a zip"))
+	assert.Error(t, err)
+}
+
+func TestReadLimitsContent(t *testing.T) {
+	feed := &Feed{Agencies: []Agency{{ID: "FF", Name: "FerryFlow", URL: "https://ferryflow.example", Timezone: "Europe/Athens"}}}
+	for i := 0; i < 1000; i++ {
+		feed.Stops = append(feed.Stops, Stop{ID: fmt.Sprintf("S%d", i), Name: "Stop"})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, feed))
+
+	_, err := read(buf.Bytes(), 1<<20)
+	assert.NoError(t, err)
+
+	_, err = read(buf.Bytes(), 4<<10)
+	assert.ErrorContains(t, err, "too large")
+
+	// A file that unzips to more than its header claims is still stopped
+	budget := int64(4 << 10)
+	_, err = io.ReadAll(&budgetReader{reader: bytes.NewReader(make([]byte, 4<<10)), budget: &budget})
+	assert.NoError(t, err, "the budget may be used up exactly")
+
+	budget = 4 << 10
+	_, err = io.ReadAll(&budgetReader{reader: bytes.NewReader(make([]byte, 5<<10)), budget: &budget})
+	assert.ErrorIs(t, err, errFeedTooLarge)
+}
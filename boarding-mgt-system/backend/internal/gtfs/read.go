@@ -0,0 +1,313 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFeedContent limits how much a feed's files may unzip to, 200 MB in all,
+// so a small zip cannot expand to fill memory
+const maxFeedContent = 200 << 20
+
+// table is a CSV file of a feed read by column name
+type table struct {
+	name    string
+	columns map[string]int
+	rows    [][]string
+}
+
+func (t *table) get(row []string, column string) string {
+	i, ok := t.columns[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// Read reads a zipped feed, whose files may sit in a folder of the zip. The
+// agency, stops, routes, trips and stop_times files are required, as is one
+// of calendar and calendar_dates.
+func Read(data []byte) (*Feed, error) {
+	return read(data, maxFeedContent)
+}
+
+// read reads a feed whose files unzip to at most budget bytes in all
+func read(data []byte, budget int64) (*Feed, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("feed is not a zip file: %w", err)
+	}
+
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[path.Base(file.Name)] = file
+	}
+
+	readers := []struct {
+		name string
+		read func(*Feed, *table) error
+	}{
+		{"agency", readAgencies},
+		{"stops", readStops},
+		{"routes", readRoutes},
+		{"trips", readTrips},
+		{"stop_times", readStopTimes},
+		{"calendar", readCalendars},
+		{"calendar_dates", readCalendarDates},
+	}
+
+	feed := &Feed{}
+	calendars := false
+	for _, reader := range readers {
+		file, ok := files[reader.name+".txt"]
+		if !ok {
+			if strings.HasPrefix(reader.name, "calendar") {
+				continue
+			}
+			return nil, fmt.Errorf("feed has no %s.txt", reader.name)
+		}
+
+		t, err := readTable(file, reader.name, &budget)
+		if err != nil {
+			return nil, err
+		}
+		if err := reader.read(feed, t); err != nil {
+			return nil, err
+		}
+		calendars = calendars || strings.HasPrefix(reader.name, "calendar")
+	}
+	if !calendars {
+		return nil, fmt.Errorf("feed has neither calendar.txt nor calendar_dates.txt")
+	}
+
+	return feed, nil
+}
+
+// readTable reads a file of the feed, taking what it unzips to from budget
+func readTable(file *zip.File, name string, budget *int64) (*table, error) {
+	// The size in the zip is only what the file claims, so the reader is
+	// held to the budget as well
+	if file.UncompressedSize64 > uint64(*budget) {
+		return nil, fmt.Errorf("feed is too large to read %s.txt", name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s.txt: %w", name, err)
+	}
+	defer reader.Close()
+
+	records := csv.NewReader(&budgetReader{reader: reader, budget: budget})
+	records.FieldsPerRecord = -1
+
+	header, err := records.Read()
+	if err == io.EOF {
+		return &table{name: name, columns: map[string]int{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s.txt: %w", name, err)
+	}
+
+	t := &table{name: name, columns: make(map[string]int, len(header))}
+	for i, column := range header {
+		t.columns[strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))] = i
+	}
+
+	for {
+		row, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s.txt: %w", name, err)
+		}
+		t.rows = append(t.rows, row)
+	}
+
+	return t, nil
+}
+
+var errFeedTooLarge = errors.New("feed is too large")
+
+// budgetReader fails once more than budget bytes have been read, counting
+// them off budget
+type budgetReader struct {
+	reader io.Reader
+	budget *int64
+}
+
+func (r *budgetReader) Read(p []byte) (int, error) {
+	// Read one byte past the budget so reaching it exactly is not an error
+	if int64(len(p)) > *r.budget+1 {
+		p = p[:*r.budget+1]
+	}
+	n, err := r.reader.Read(p)
+	*r.budget -= int64(n)
+	if *r.budget < 0 {
+		return n, errFeedTooLarge
+	}
+	return n, err
+}
+
+// rowError names the file and line of a bad row; line 1 is the header
+func rowError(t *table, i int, format string, args ...interface{}) error {
+	return fmt.Errorf("%s.txt line %d: %s", t.name, i+2, fmt.Sprintf(format, args...))
+}
+
+func readAgencies(feed *Feed, t *table) error {
+	for _, row := range t.rows {
+		feed.Agencies = append(feed.Agencies, Agency{
+			ID:       t.get(row, "agency_id"),
+			Name:     t.get(row, "agency_name"),
+			URL:      t.get(row, "agency_url"),
+			Timezone: t.get(row, "agency_timezone"),
+			Lang:     t.get(row, "agency_lang"),
+			Phone:    t.get(row, "agency_phone"),
+		})
+	}
+	return nil
+}
+
+func readStops(feed *Feed, t *table) error {
+	for i, row := range t.rows {
+		stop := Stop{
+			ID:       t.get(row, "stop_id"),
+			Code:     t.get(row, "stop_code"),
+			Name:     t.get(row, "stop_name"),
+			Timezone: t.get(row, "stop_timezone"),
+		}
+		for column, field := range map[string]**float64{"stop_lat": &stop.Lat, "stop_lon": &stop.Lon} {
+			value := t.get(row, column)
+			if value == "" {
+				continue
+			}
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return rowError(t, i, "invalid %s %q", column, value)
+			}
+			*field = &n
+		}
+		feed.Stops = append(feed.Stops, stop)
+	}
+	return nil
+}
+
+func readRoutes(feed *Feed, t *table) error {
+	for i, row := range t.rows {
+		routeType, err := strconv.Atoi(t.get(row, "route_type"))
+		if err != nil {
+			return rowError(t, i, "invalid route_type %q", t.get(row, "route_type"))
+		}
+		feed.Routes = append(feed.Routes, Route{
+			ID:        t.get(row, "route_id"),
+			AgencyID:  t.get(row, "agency_id"),
+			ShortName: t.get(row, "route_short_name"),
+			LongName:  t.get(row, "route_long_name"),
+			Type:      routeType,
+		})
+	}
+	return nil
+}
+
+func readTrips(feed *Feed, t *table) error {
+	for _, row := range t.rows {
+		feed.Trips = append(feed.Trips, Trip{
+			ID:        t.get(row, "trip_id"),
+			RouteID:   t.get(row, "route_id"),
+			ServiceID: t.get(row, "service_id"),
+			Headsign:  t.get(row, "trip_headsign"),
+			BlockID:   t.get(row, "block_id"),
+		})
+	}
+	return nil
+}
+
+func readStopTimes(feed *Feed, t *table) error {
+	for i, row := range t.rows {
+		sequence, err := strconv.Atoi(t.get(row, "stop_sequence"))
+		if err != nil {
+			return rowError(t, i, "invalid stop_sequence %q", t.get(row, "stop_sequence"))
+		}
+		stopTime := StopTime{
+			TripID:   t.get(row, "trip_id"),
+			Sequence: sequence,
+			StopID:   t.get(row, "stop_id"),
+		}
+
+		// A call with only one of its times arrives and leaves at once
+		arrival, departure := t.get(row, "arrival_time"), t.get(row, "departure_time")
+		if arrival == "" {
+			arrival = departure
+		}
+		if departure == "" {
+			departure = arrival
+		}
+		if arrival != "" {
+			if stopTime.Arrival, err = ParseTime(arrival); err != nil {
+				return rowError(t, i, "%v", err)
+			}
+			if stopTime.Departure, err = ParseTime(departure); err != nil {
+				return rowError(t, i, "%v", err)
+			}
+			stopTime.Timed = true
+		}
+		feed.StopTimes = append(feed.StopTimes, stopTime)
+	}
+	return nil
+}
+
+func readCalendars(feed *Feed, t *table) error {
+	days := []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+	for i, row := range t.rows {
+		calendar := Calendar{ServiceID: t.get(row, "service_id")}
+		for day, column := range days {
+			calendar.Weekdays[day] = t.get(row, column) == "1"
+		}
+
+		var err error
+		if calendar.Start, err = readDate(t, i, row, "start_date"); err != nil {
+			return err
+		}
+		if calendar.End, err = readDate(t, i, row, "end_date"); err != nil {
+			return err
+		}
+		feed.Calendars = append(feed.Calendars, calendar)
+	}
+	return nil
+}
+
+func readCalendarDates(feed *Feed, t *table) error {
+	for i, row := range t.rows {
+		date, err := readDate(t, i, row, "date")
+		if err != nil {
+			return err
+		}
+
+		exception := CalendarDate{ServiceID: t.get(row, "service_id"), Date: date}
+		switch t.get(row, "exception_type") {
+		case "1":
+			exception.Added = true
+		case "2":
+		default:
+			return rowError(t, i, "invalid exception_type %q", t.get(row, "exception_type"))
+		}
+		feed.CalendarDates = append(feed.CalendarDates, exception)
+	}
+	return nil
+}
+
+func readDate(t *table, i int, row []string, column string) (time.Time, error) {
+	date, err := time.Parse(DateFormat, t.get(row, column))
+	if err != nil {
+		return time.Time{}, rowError(t, i, "invalid %s %q", column, t.get(row, column))
+	}
+	return date, nil
+}
@@ -0,0 +1,134 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ContentType is the media type of a zipped feed
+const ContentType = "application/zip"
+
+// feedFile is a CSV file of a feed as a header row and data rows
+type feedFile struct {
+	name string
+	rows [][]string
+}
+
+// Write writes a zipped feed. calendar_dates.txt is left out when the feed
+// has no calendar dates.
+func Write(w io.Writer, feed *Feed) error {
+	archive := zip.NewWriter(w)
+
+	files := []feedFile{
+		{"agency.txt", agencyRows(feed)},
+		{"stops.txt", stopRows(feed)},
+		{"routes.txt", routeRows(feed)},
+		{"trips.txt", tripRows(feed)},
+		{"stop_times.txt", stopTimeRows(feed)},
+		{"calendar.txt", calendarRows(feed)},
+	}
+	if len(feed.CalendarDates) > 0 {
+		files = append(files, feedFile{"calendar_dates.txt", calendarDateRows(feed)})
+	}
+
+	for _, file := range files {
+		part, err := archive.Create(file.name)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+		writer := csv.NewWriter(part)
+		if err := writer.WriteAll(file.rows); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write GTFS feed: %w", err)
+	}
+	return nil
+}
+
+func agencyRows(feed *Feed) [][]string {
+	rows := [][]string{{"agency_id", "agency_name", "agency_url", "agency_timezone", "agency_lang", "agency_phone"}}
+	for _, a := range feed.Agencies {
+		rows = append(rows, []string{a.ID, a.Name, a.URL, a.Timezone, a.Lang, a.Phone})
+	}
+	return rows
+}
+
+func stopRows(feed *Feed) [][]string {
+	rows := [][]string{{"stop_id", "stop_code", "stop_name", "stop_lat", "stop_lon", "stop_timezone"}}
+	for _, s := range feed.Stops {
+		rows = append(rows, []string{s.ID, s.Code, s.Name, coordinate(s.Lat), coordinate(s.Lon), s.Timezone})
+	}
+	return rows
+}
+
+func coordinate(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 6, 64)
+}
+
+func routeRows(feed *Feed) [][]string {
+	rows := [][]string{{"route_id", "agency_id", "route_short_name", "route_long_name", "route_type"}}
+	for _, r := range feed.Routes {
+		rows = append(rows, []string{r.ID, r.AgencyID, r.ShortName, r.LongName, strconv.Itoa(r.Type)})
+	}
+	return rows
+}
+
+func tripRows(feed *Feed) [][]string {
+	rows := [][]string{{"route_id", "service_id", "trip_id", "trip_headsign", "block_id"}}
+	for _, t := range feed.Trips {
+		rows = append(rows, []string{t.RouteID, t.ServiceID, t.ID, t.Headsign, t.BlockID})
+	}
+	return rows
+}
+
+func stopTimeRows(feed *Feed) [][]string {
+	rows := [][]string{{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence"}}
+	for _, st := range feed.StopTimes {
+		var arrival, departure string
+		if st.Timed {
+			arrival, departure = FormatTime(st.Arrival), FormatTime(st.Departure)
+		}
+		rows = append(rows, []string{st.TripID, arrival, departure, st.StopID, strconv.Itoa(st.Sequence)})
+	}
+	return rows
+}
+
+func calendarRows(feed *Feed) [][]string {
+	rows := [][]string{{
+		"service_id", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
+		"start_date", "end_date",
+	}}
+	for _, c := range feed.Calendars {
+		row := []string{c.ServiceID}
+		for _, runs := range c.Weekdays {
+			flag := "0"
+			if runs {
+				flag = "1"
+			}
+			row = append(row, flag)
+		}
+		rows = append(rows, append(row, c.Start.Format(DateFormat), c.End.Format(DateFormat)))
+	}
+	return rows
+}
+
+func calendarDateRows(feed *Feed) [][]string {
+	rows := [][]string{{"service_id", "date", "exception_type"}}
+	for _, d := range feed.CalendarDates {
+		exception := "2"
+		if d.Added {
+			exception = "1"
+		}
+		rows = append(rows, []string{d.ServiceID, d.Date.Format(DateFormat), exception})
+	}
+	return rows
+}
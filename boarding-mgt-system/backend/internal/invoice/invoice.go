@@ -0,0 +1,194 @@
+// Package invoice builds invoice and credit note documents and renders them to PDF.
+package invoice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/ledger"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+)
+
+// Document types
+const (
+	TypeInvoice    = "invoice"
+	TypeCreditNote = "credit_note"
+)
+
+// FiscalYear returns the fiscal year a date falls in for a fiscal year that
+// starts on the first day of startMonth. Fiscal years are named after the
+// calendar year in which they start.
+func FiscalYear(t time.Time, startMonth int) int {
+	if startMonth < 1 || startMonth > 12 {
+		startMonth = 1
+	}
+
+	year := t.Year()
+	if int(t.Month()) < startMonth {
+		year--
+	}
+	return year
+}
+
+// NumberPrefix returns the prefix of an operator's document numbers, which
+// are issued as PREFIX-YEAR-NNNNNN. Credit notes are numbered in their own
+// sequence.
+func NumberPrefix(operatorCode, documentType string) string {
+	prefix := strings.ToUpper(operatorCode)
+	if documentType == TypeCreditNote {
+		prefix += "-CN"
+	}
+	return prefix
+}
+
+// TicketLines prices a booking's tickets as invoice lines, one line per
+// passenger type and fare. Ticket prices include tax at taxRate.
+func TicketLines(schedule *models.Schedule, tickets []models.Ticket, taxRate float64) []models.InvoiceLine {
+	trip := tripDescription(schedule)
+
+	type fare struct {
+		passengerType string
+		price         money.Money
+	}
+	quantities := make(map[fare]int)
+	order := []fare{}
+	for _, ticket := range tickets {
+		key := fare{passengerType: ticket.PassengerType, price: ticket.TicketPrice}
+		if _, ok := quantities[key]; !ok {
+			order = append(order, key)
+		}
+		quantities[key]++
+	}
+
+	lines := make([]models.InvoiceLine, 0, len(order))
+	for _, key := range order {
+		quantity := quantities[key]
+		total := key.price.Times(quantity)
+		net, tax := ledger.SplitTax(total, taxRate)
+
+		lines = append(lines, models.InvoiceLine{
+			LineNumber:  len(lines) + 1,
+			Description: fmt.Sprintf("%s fare, %s", passengerLabel(key.passengerType), trip),
+			Quantity:    quantity,
+			UnitPrice:   key.price,
+			TaxRate:     taxRate,
+			NetAmount:   net,
+			TaxAmount:   tax,
+			TotalAmount: total,
+		})
+	}
+
+	return lines
+}
+
+// CreditLines builds the lines of a credit note refunding amount of an
+// invoice. A full refund credits every original line; a partial refund is a
+// single line whose tax is proportional to the tax on the original invoice.
+func CreditLines(original *models.Invoice, amount money.Money) ([]models.InvoiceLine, error) {
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("credit amount must be positive")
+	}
+	if amount.Cmp(original.Total) > 0 {
+		return nil, fmt.Errorf("credit amount %s exceeds invoice total %s", amount, original.Total)
+	}
+
+	if amount.Cmp(original.Total) == 0 {
+		lines := make([]models.InvoiceLine, len(original.Lines))
+		for i, line := range original.Lines {
+			lines[i] = models.InvoiceLine{
+				LineNumber:  i + 1,
+				Description: line.Description,
+				Quantity:    line.Quantity,
+				UnitPrice:   line.UnitPrice,
+				TaxRate:     line.TaxRate,
+				NetAmount:   line.NetAmount,
+				TaxAmount:   line.TaxAmount,
+				TotalAmount: line.TotalAmount,
+			}
+		}
+		return lines, nil
+	}
+
+	tax := original.TaxTotal.Prorate(amount, original.Total, money.HalfUp)
+
+	return []models.InvoiceLine{{
+		LineNumber:  1,
+		Description: fmt.Sprintf("Partial refund of invoice %s", original.InvoiceNumber),
+		Quantity:    1,
+		UnitPrice:   amount,
+		TaxRate:     uniformTaxRate(original.Lines),
+		NetAmount:   amount.Sub(tax),
+		TaxAmount:   tax,
+		TotalAmount: amount,
+	}}, nil
+}
+
+// SetTotals fills in a document's subtotal, tax and total from its lines
+func SetTotals(doc *models.Invoice) {
+	var subtotal, tax money.Money
+	for _, line := range doc.Lines {
+		subtotal = subtotal.Add(line.NetAmount)
+		tax = tax.Add(line.TaxAmount)
+	}
+
+	doc.Subtotal = subtotal
+	doc.TaxTotal = tax
+	doc.Total = subtotal.Add(tax)
+}
+
+// Seller snapshots an operator's legal details
+func Seller(operator *models.Operator) models.InvoiceSeller {
+	seller := models.InvoiceSeller{
+		LegalName:   operator.Name,
+		TradingName: operator.Name,
+		TaxID:       operator.TaxID,
+		Address:     operator.Address,
+		Email:       operator.ContactEmail,
+		Phone:       operator.ContactPhone,
+	}
+	if operator.LegalName != nil && *operator.LegalName != "" {
+		seller.LegalName = *operator.LegalName
+	}
+	return seller
+}
+
+// Buyer snapshots a customer's details
+func Buyer(customer *models.User) models.InvoiceBuyer {
+	return models.InvoiceBuyer{
+		Name:  strings.TrimSpace(customer.FirstName + " " + customer.LastName),
+		Email: customer.Email,
+		Phone: customer.Phone,
+	}
+}
+
+func tripDescription(schedule *models.Schedule) string {
+	trip := "ferry crossing"
+	if schedule.Route != nil && schedule.Route.Name != "" {
+		trip = schedule.Route.Name
+	}
+	return fmt.Sprintf("%s departing %s %s", trip,
+		schedule.DepartureDate.Format("2006-01-02"), schedule.DepartureTime.Format("15:04"))
+}
+
+func passengerLabel(passengerType string) string {
+	if passengerType == "" {
+		return "Passenger"
+	}
+	return strings.ToUpper(passengerType[:1]) + passengerType[1:]
+}
+
+// uniformTaxRate returns the tax rate shared by all lines, or zero when they differ
+func uniformTaxRate(lines []models.InvoiceLine) float64 {
+	if len(lines) == 0 {
+		return 0
+	}
+	rate := lines[0].TaxRate
+	for _, line := range lines[1:] {
+		if line.TaxRate != rate {
+			return 0
+		}
+	}
+	return rate
+}
@@ -0,0 +1,130 @@
+package invoice
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiscalYear(t *testing.T) {
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 2024, FiscalYear(date, 1))
+	assert.Equal(t, 2024, FiscalYear(date, 3))
+	assert.Equal(t, 2023, FiscalYear(date, 4))
+	assert.Equal(t, 2024, FiscalYear(date, 0), "invalid start month falls back to January")
+}
+
+func TestNumberPrefix(t *testing.T) {
+	assert.Equal(t, "BLUE", NumberPrefix("blue", TypeInvoice))
+	assert.Equal(t, "BLUE-CN", NumberPrefix("BLUE", TypeCreditNote))
+}
+
+func sampleInvoice() *models.Invoice {
+	schedule := &models.Schedule{
+		DepartureDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		DepartureTime: time.Date(0, 1, 1, 8, 30, 0, 0, time.UTC),
+		Route:         &models.Route{Name: "Harbour - Island"},
+	}
+	tickets := []models.Ticket{
+		{PassengerType: "adult", TicketPrice: money.MustParse("56.00")},
+		{PassengerType: "child", TicketPrice: money.MustParse("28.00")},
+		{PassengerType: "adult", TicketPrice: money.MustParse("56.00")},
+	}
+
+	doc := &models.Invoice{
+		DocumentType:  TypeInvoice,
+		FiscalYear:    2024,
+		InvoiceNumber: "BLUE-2024-000042",
+		IssueDate:     time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC),
+		Currency:      "USD",
+		Seller:        models.InvoiceSeller{LegalName: "Blue Ferries Ltd", TradingName: "Blue Ferries", Email: "billing@blue.example"},
+		Buyer:         models.InvoiceBuyer{Name: "Zoë Müller", Email: "zoe@example.com"},
+		Lines:         TicketLines(schedule, tickets, 0.12),
+	}
+	SetTotals(doc)
+	return doc
+}
+
+func TestTicketLines(t *testing.T) {
+	doc := sampleInvoice()
+
+	require.Len(t, doc.Lines, 2)
+	assert.Equal(t, "Adult fare, Harbour - Island departing 2024-03-01 08:30", doc.Lines[0].Description)
+	assert.Equal(t, 2, doc.Lines[0].Quantity)
+	assert.Equal(t, money.MustParse("112.00"), doc.Lines[0].TotalAmount)
+	assert.Equal(t, money.MustParse("100.00"), doc.Lines[0].NetAmount)
+	assert.Equal(t, money.MustParse("12.00"), doc.Lines[0].TaxAmount)
+	assert.Equal(t, 1, doc.Lines[1].Quantity)
+	assert.Equal(t, 2, doc.Lines[1].LineNumber)
+
+	assert.Equal(t, money.MustParse("125.00"), doc.Subtotal)
+	assert.Equal(t, money.MustParse("15.00"), doc.TaxTotal)
+	assert.Equal(t, money.MustParse("140.00"), doc.Total)
+}
+
+func TestCreditLines(t *testing.T) {
+	original := sampleInvoice()
+
+	t.Run("Full refund credits every line", func(t *testing.T) {
+		lines, err := CreditLines(original, money.MustParse("140.00"))
+		require.NoError(t, err)
+		require.Len(t, lines, 2)
+
+		credit := &models.Invoice{Lines: lines}
+		SetTotals(credit)
+		assert.Equal(t, original.Total, credit.Total)
+		assert.Equal(t, original.TaxTotal, credit.TaxTotal)
+	})
+
+	t.Run("Partial refund credits proportional tax", func(t *testing.T) {
+		lines, err := CreditLines(original, money.MustParse("70.00"))
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		assert.Equal(t, money.MustParse("7.50"), lines[0].TaxAmount)
+		assert.Equal(t, money.MustParse("62.50"), lines[0].NetAmount)
+		assert.Equal(t, 0.12, lines[0].TaxRate)
+		assert.Contains(t, lines[0].Description, "BLUE-2024-000042")
+	})
+
+	t.Run("Credit larger than invoice is rejected", func(t *testing.T) {
+		_, err := CreditLines(original, money.MustParse("140.01"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Zero credit is rejected", func(t *testing.T) {
+		_, err := CreditLines(original, money.Money{})
+		assert.Error(t, err)
+	})
+}
+
+func TestWritePDF(t *testing.T) {
+	doc := sampleInvoice()
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePDF(&buf, doc))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+
+	number := "BLUE-2024-000042"
+	credit := &models.Invoice{
+		DocumentType:          TypeCreditNote,
+		FiscalYear:            2024,
+		InvoiceNumber:         "BLUE-CN-2024-000001",
+		OriginalInvoiceNumber: &number,
+		IssueDate:             time.Date(2024, 2, 25, 0, 0, 0, 0, time.UTC),
+		Currency:              "USD",
+		Seller:                doc.Seller,
+		Buyer:                 doc.Buyer,
+	}
+	credit.Lines, _ = CreditLines(doc, money.MustParse("70.00"))
+	SetTotals(credit)
+
+	buf.Reset()
+	require.NoError(t, WritePDF(&buf, credit))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
@@ -0,0 +1,159 @@
+package invoice
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/go-pdf/fpdf"
+)
+
+// WritePDF renders an invoice or credit note as an A4 PDF document
+func WritePDF(w io.Writer, doc *models.Invoice) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("%s %s", documentTitle(doc), doc.InvoiceNumber), true)
+	pdf.SetAuthor(doc.Seller.LegalName, true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	// Core fonts are cp1252; translate names and addresses from UTF-8
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	// Seller block
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(110, 7, tr(doc.Seller.LegalName), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(70, 7, tr(documentTitle(doc)), "", 1, "R", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	sellerLines := []string{}
+	if doc.Seller.TradingName != doc.Seller.LegalName {
+		sellerLines = append(sellerLines, "Trading as "+doc.Seller.TradingName)
+	}
+	if doc.Seller.Address != nil {
+		sellerLines = append(sellerLines, *doc.Seller.Address)
+	}
+	sellerLines = append(sellerLines, doc.Seller.Email)
+	if doc.Seller.Phone != nil {
+		sellerLines = append(sellerLines, *doc.Seller.Phone)
+	}
+	if doc.Seller.TaxID != nil {
+		sellerLines = append(sellerLines, "Tax ID: "+*doc.Seller.TaxID)
+	}
+
+	details := [][2]string{
+		{"Number", doc.InvoiceNumber},
+		{"Issue date", doc.IssueDate.Format("2006-01-02")},
+		{"Fiscal year", strconv.Itoa(doc.FiscalYear)},
+	}
+	if doc.OriginalInvoiceNumber != nil {
+		details = append(details, [2]string{"Corrects invoice", *doc.OriginalInvoiceNumber})
+	}
+
+	rows := len(sellerLines)
+	if len(details) > rows {
+		rows = len(details)
+	}
+	for i := 0; i < rows; i++ {
+		left := ""
+		if i < len(sellerLines) {
+			left = sellerLines[i]
+		}
+		pdf.CellFormat(110, 5, tr(left), "", 0, "L", false, 0, "")
+		if i < len(details) {
+			pdf.SetFont("Helvetica", "B", 9)
+			pdf.CellFormat(35, 5, details[i][0], "", 0, "R", false, 0, "")
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.CellFormat(35, 5, tr(details[i][1]), "", 0, "R", false, 0, "")
+		}
+		pdf.Ln(5)
+	}
+
+	// Buyer block
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(0, 6, "Bill to", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(0, 5, tr(doc.Buyer.Name), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, tr(doc.Buyer.Email), "", 1, "L", false, 0, "")
+	if doc.Buyer.Phone != nil {
+		pdf.CellFormat(0, 5, tr(*doc.Buyer.Phone), "", 1, "L", false, 0, "")
+	}
+
+	// Lines
+	pdf.Ln(8)
+	widths := []float64{70, 14, 24, 16, 28, 28}
+	headers := []string{"Description", "Qty", "Unit price", "Tax", "Net", "Total"}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(230, 230, 230)
+	for i, header := range headers {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 7, header, "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	for _, line := range doc.Lines {
+		description := tr(line.Description)
+		descLines := pdf.SplitLines([]byte(description), widths[0])
+		height := 5 * float64(len(descLines))
+
+		x, y := pdf.GetXY()
+		pdf.MultiCell(widths[0], 5, description, "", "L", false)
+		pdf.SetXY(x+widths[0], y)
+
+		pdf.CellFormat(widths[1], height, strconv.Itoa(line.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], height, line.UnitPrice.String(), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], height, formatRate(line.TaxRate), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], height, line.NetAmount.String(), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[5], height, line.TotalAmount.String(), "", 1, "R", false, 0, "")
+	}
+
+	// Totals
+	pdf.Ln(4)
+	totals := [][2]string{
+		{"Subtotal", doc.Subtotal.String()},
+		{"Tax", doc.TaxTotal.String()},
+		{"Total " + doc.Currency, doc.Total.String()},
+	}
+	for i, total := range totals {
+		style := ""
+		if i == len(totals)-1 {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.CellFormat(124, 6, "", "", 0, "R", false, 0, "")
+		pdf.CellFormat(28, 6, total[0], "T", 0, "R", false, 0, "")
+		pdf.CellFormat(28, 6, total[1], "T", 1, "R", false, 0, "")
+	}
+
+	if doc.DocumentType == TypeCreditNote {
+		pdf.Ln(8)
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.MultiCell(0, 5, "This credit note reduces the amount owed under the invoice referenced above. Amounts include tax.", "", "L", false)
+	} else {
+		pdf.Ln(8)
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.MultiCell(0, 5, "Prices include tax. Thank you for travelling with us.", "", "L", false)
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to render PDF: %w", err)
+	}
+	return nil
+}
+
+func documentTitle(doc *models.Invoice) string {
+	if doc.DocumentType == TypeCreditNote {
+		return "Credit Note"
+	}
+	return "Invoice"
+}
+
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,67 @@
+// Package ledger builds double-entry journal postings and exports them for accounting tools.
+package ledger
+
+import (
+	"strconv"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+)
+
+// System account keys used by automatic postings
+const (
+	AccountCash              = "cash"
+	AccountReceivables       = "receivables"
+	AccountDeferredRevenue   = "deferred_revenue"
+	AccountRevenue           = "recognized_revenue"
+	AccountRefundsPayable    = "refunds_payable"
+	AccountTaxesPayable      = "taxes_payable"
+	AccountCommissionPayable = "commission_payable"
+	AccountCommissionExpense = "commission_expense"
+	AccountTravelCredits     = "travel_credits"
+)
+
+// DefaultAccounts is the chart of accounts created for every operator
+var DefaultAccounts = []models.LedgerAccount{
+	{Code: "1000", Name: "Cash and Gateway Clearing", AccountType: "asset", SystemKey: key(AccountCash)},
+	{Code: "1100", Name: "Accounts Receivable", AccountType: "asset", SystemKey: key(AccountReceivables)},
+	{Code: "2100", Name: "Deferred Revenue", AccountType: "liability", SystemKey: key(AccountDeferredRevenue)},
+	{Code: "2200", Name: "Refunds Payable", AccountType: "liability", SystemKey: key(AccountRefundsPayable)},
+	{Code: "2300", Name: "Sales Tax Payable", AccountType: "liability", SystemKey: key(AccountTaxesPayable)},
+	{Code: "2400", Name: "Agent Commission Payable", AccountType: "liability", SystemKey: key(AccountCommissionPayable)},
+	{Code: "2500", Name: "Travel Credits", AccountType: "liability", SystemKey: key(AccountTravelCredits)},
+	{Code: "4000", Name: "Ticket Revenue", AccountType: "revenue", SystemKey: key(AccountRevenue)},
+	{Code: "5100", Name: "Agent Commission Expense", AccountType: "expense", SystemKey: key(AccountCommissionExpense)},
+}
+
+func key(s string) *string {
+	return &s
+}
+
+// Rates reads the tax and agent commission rates from operator settings.
+// Rates are fractions (0.12 for 12%) and default to zero when not configured.
+func Rates(settings map[string]interface{}) (taxRate, commissionRate float64) {
+	return settingRate(settings, "tax_rate"), settingRate(settings, "agent_commission_rate")
+}
+
+// settingRate reads a rate from operator settings. Rates that could not
+// scale an amount, such as negative or non-numeric ones, read as zero.
+func settingRate(settings map[string]interface{}, name string) float64 {
+	var rate float64
+	switch v := settings[name].(type) {
+	case float64:
+		rate = v
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0
+		}
+		rate = parsed
+	default:
+		return 0
+	}
+	if money.CheckFactor(rate) != nil {
+		return 0
+	}
+	return rate
+}
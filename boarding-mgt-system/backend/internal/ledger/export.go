@@ -0,0 +1,140 @@
+package ledger
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// WriteCSV writes journal entries as one CSV row per journal line
+func WriteCSV(w io.Writer, entries []*models.JournalEntry) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"entry_number", "entry_date", "event_type", "description", "currency",
+		"account_code", "account_name", "debit", "credit", "booking_id", "memo",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			bookingID := ""
+			if line.BookingID != nil {
+				bookingID = line.BookingID.String()
+			}
+			memo := ""
+			if line.Memo != nil {
+				memo = *line.Memo
+			}
+
+			record := []string{
+				strconv.FormatInt(entry.EntryNumber, 10),
+				entry.EntryDate.Format("2006-01-02"),
+				entry.EventType,
+				entry.Description,
+				entry.Currency,
+				line.AccountCode,
+				line.AccountName,
+				line.Debit.String(),
+				line.Credit.String(),
+				bookingID,
+				memo,
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteIIF writes the chart of accounts and journal entries in QuickBooks
+// Intuit Interchange Format as general journal transactions
+func WriteIIF(w io.Writer, accounts []*models.LedgerAccount, entries []*models.JournalEntry) error {
+	bw := bufio.NewWriter(w)
+
+	row := func(fields ...string) {
+		for i, f := range fields {
+			fields[i] = iifField(f)
+		}
+		bw.WriteString(strings.Join(fields, "\t"))
+		bw.WriteString("\r\n")
+	}
+
+	row("!ACCNT", "NAME", "ACCNTTYPE", "ACCNUM")
+	for _, account := range accounts {
+		row("ACCNT", account.Name, iifAccountType(account), account.Code)
+	}
+
+	row("!TRNS", "TRNSID", "TRNSTYPE", "DATE", "ACCNT", "AMOUNT", "DOCNUM", "MEMO")
+	row("!SPL", "SPLID", "TRNSTYPE", "DATE", "ACCNT", "AMOUNT", "DOCNUM", "MEMO")
+	row("!ENDTRNS")
+
+	for _, entry := range entries {
+		date := entry.EntryDate.Format("01/02/2006")
+		docNum := strconv.FormatInt(entry.EntryNumber, 10)
+
+		for i, line := range entry.Lines {
+			// Debits are positive and credits negative in IIF
+			amount := line.Debit
+			if line.Credit.IsPositive() {
+				amount = line.Credit.Neg()
+			}
+
+			memo := entry.Description
+			if line.Memo != nil {
+				memo = *line.Memo
+			}
+
+			kind := "SPL"
+			if i == 0 {
+				kind = "TRNS"
+			}
+			row(kind, "", "GENERAL JOURNAL", date, line.AccountName, amount.String(), docNum, memo)
+		}
+		row("ENDTRNS")
+	}
+
+	return bw.Flush()
+}
+
+// iifAccountType maps ledger accounts to QuickBooks account types
+func iifAccountType(account *models.LedgerAccount) string {
+	if account.SystemKey != nil {
+		switch *account.SystemKey {
+		case AccountCash:
+			return "BANK"
+		case AccountReceivables:
+			return "AR"
+		}
+	}
+
+	switch account.AccountType {
+	case "asset":
+		return "OCASSET"
+	case "liability":
+		return "OCLIAB"
+	case "equity":
+		return "EQUITY"
+	case "revenue":
+		return "INC"
+	case "expense":
+		return "EXP"
+	default:
+		return "OCASSET"
+	}
+}
+
+// iifField strips characters that would break the tab-delimited layout
+func iifField(value string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ", "\"", "'").Replace(value)
+}
@@ -0,0 +1,192 @@
+package ledger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sumBy(lines []models.JournalLine, key string) (debit, credit money.Money) {
+	for _, line := range lines {
+		if line.AccountKey == key {
+			debit = debit.Add(line.Debit)
+			credit = credit.Add(line.Credit)
+		}
+	}
+	return debit, credit
+}
+
+func TestRates(t *testing.T) {
+	tax, commission := Rates(map[string]interface{}{"tax_rate": "0.12", "agent_commission_rate": 0.05})
+	assert.Equal(t, 0.12, tax)
+	assert.Equal(t, 0.05, commission)
+
+	// Rates that cannot scale a fare read as unset rather than failing postings
+	tax, commission = Rates(map[string]interface{}{"tax_rate": "NaN", "agent_commission_rate": -0.1})
+	assert.Equal(t, 0.0, tax)
+	assert.Equal(t, 0.0, commission)
+}
+
+func TestSplitTax(t *testing.T) {
+	net, tax := SplitTax(money.MustParse("112.00"), 0.12)
+	assert.Equal(t, money.MustParse("100.00"), net)
+	assert.Equal(t, money.MustParse("12.00"), tax)
+
+	net, tax = SplitTax(money.MustParse("45.50"), 0)
+	assert.Equal(t, money.MustParse("45.50"), net)
+	assert.Equal(t, money.MustParse("0.00"), tax)
+}
+
+func TestPostings(t *testing.T) {
+	bookingID := uuid.New()
+
+	t.Run("Booking with tax and commission", func(t *testing.T) {
+		lines := BookingLines(bookingID, money.MustParse("112.00"), 0.12, money.MustParse("11.20"))
+		require.NoError(t, Validate(lines))
+
+		debit, _ := sumBy(lines, AccountReceivables)
+		assert.Equal(t, money.MustParse("112.00"), debit)
+		_, credit := sumBy(lines, AccountDeferredRevenue)
+		assert.Equal(t, money.MustParse("100.00"), credit)
+		_, credit = sumBy(lines, AccountTaxesPayable)
+		assert.Equal(t, money.MustParse("12.00"), credit)
+		_, credit = sumBy(lines, AccountCommissionPayable)
+		assert.Equal(t, money.MustParse("11.20"), credit)
+	})
+
+	t.Run("Payment clears receivable", func(t *testing.T) {
+		lines := PaymentLines(bookingID, money.MustParse("112.00"))
+		require.NoError(t, Validate(lines))
+		_, credit := sumBy(lines, AccountReceivables)
+		assert.Equal(t, money.MustParse("112.00"), credit)
+	})
+
+	t.Run("Partial refund reverses proportionally", func(t *testing.T) {
+		sale := BookingLines(bookingID, money.MustParse("112.00"), 0.12, money.MustParse("11.20"))
+
+		lines, err := RefundLines(bookingID, sale, money.MustParse("56.00"), false)
+		require.NoError(t, err)
+		require.NoError(t, Validate(lines))
+
+		debit, _ := sumBy(lines, AccountTaxesPayable)
+		assert.Equal(t, money.MustParse("6.00"), debit)
+		debit, _ = sumBy(lines, AccountDeferredRevenue)
+		assert.Equal(t, money.MustParse("50.00"), debit)
+		_, credit := sumBy(lines, AccountRefundsPayable)
+		assert.Equal(t, money.MustParse("56.00"), credit)
+		_, credit = sumBy(lines, AccountCommissionExpense)
+		assert.Equal(t, money.MustParse("5.60"), credit)
+	})
+
+	t.Run("Refund after departure comes out of recognized revenue", func(t *testing.T) {
+		sale := BookingLines(bookingID, money.MustParse("112.00"), 0.12, money.Money{})
+
+		lines, err := RefundLines(bookingID, sale, money.MustParse("112.00"), true)
+		require.NoError(t, err)
+		require.NoError(t, Validate(lines))
+
+		debit, _ := sumBy(lines, AccountRevenue)
+		assert.Equal(t, money.MustParse("100.00"), debit)
+		debit, _ = sumBy(lines, AccountDeferredRevenue)
+		assert.True(t, debit.IsZero())
+	})
+
+	t.Run("Refund larger than sale is rejected", func(t *testing.T) {
+		sale := BookingLines(bookingID, money.MustParse("50.00"), 0, money.Money{})
+		_, err := RefundLines(bookingID, sale, money.MustParse("60.00"), false)
+		assert.Error(t, err)
+	})
+
+	t.Run("Departure recognizes deferred revenue", func(t *testing.T) {
+		other := uuid.New()
+		lines := DepartureLines(map[uuid.UUID]money.Money{bookingID: money.MustParse("100.00"), other: money.MustParse("44.50")})
+		require.NoError(t, Validate(lines))
+
+		_, credit := sumBy(lines, AccountRevenue)
+		assert.Equal(t, money.MustParse("144.50"), credit)
+	})
+
+	t.Run("No-show credit comes out of revenue", func(t *testing.T) {
+		lines := NoShowCreditLines(bookingID, money.MustParse("12.50"))
+		require.NoError(t, Validate(lines))
+
+		debit, _ := sumBy(lines, AccountRevenue)
+		assert.Equal(t, money.MustParse("12.50"), debit)
+		_, credit := sumBy(lines, AccountTravelCredits)
+		assert.Equal(t, money.MustParse("12.50"), credit)
+	})
+
+	t.Run("Cancellation credit is owed as travel credit", func(t *testing.T) {
+		sale := BookingLines(bookingID, money.MustParse("112.00"), 0.12, money.Money{})
+
+		lines, err := CancellationCreditLines(bookingID, sale, money.MustParse("112.00"))
+		require.NoError(t, err)
+		require.NoError(t, Validate(lines))
+
+		debit, _ := sumBy(lines, AccountDeferredRevenue)
+		assert.Equal(t, money.MustParse("100.00"), debit)
+		_, credit := sumBy(lines, AccountTravelCredits)
+		assert.Equal(t, money.MustParse("112.00"), credit)
+		_, credit = sumBy(lines, AccountRefundsPayable)
+		assert.True(t, credit.IsZero())
+	})
+
+	t.Run("Unbalanced entry is rejected", func(t *testing.T) {
+		lines := []models.JournalLine{
+			{AccountKey: AccountCash, Debit: money.MustParse("10.00")},
+			{AccountKey: AccountReceivables, Credit: money.MustParse("9.99")},
+		}
+		assert.Error(t, Validate(lines))
+	})
+}
+
+func TestExport(t *testing.T) {
+	bookingID := uuid.New()
+	entry := &models.JournalEntry{
+		EntryNumber: 42,
+		EntryDate:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		EventType:   "payment",
+		Description: "Payment for booking FFABC123",
+		Currency:    "USD",
+		Lines: []models.JournalLine{
+			{AccountCode: "1000", AccountName: "Cash and Gateway Clearing", BookingID: &bookingID, Debit: money.MustParse("25.00")},
+			{AccountCode: "1100", AccountName: "Accounts Receivable", BookingID: &bookingID, Credit: money.MustParse("25.00")},
+		},
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteCSV(&buf, []*models.JournalEntry{entry}))
+
+		rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, rows, 3)
+		assert.True(t, strings.HasPrefix(rows[0], "entry_number,entry_date"))
+		assert.Contains(t, rows[1], "42,2024-03-01,payment")
+		assert.Contains(t, rows[1], "25.00,0.00")
+		assert.Contains(t, rows[2], "0.00,25.00")
+	})
+
+	t.Run("IIF", func(t *testing.T) {
+		accounts := []*models.LedgerAccount{
+			{Code: "1000", Name: "Cash and Gateway Clearing", AccountType: "asset", SystemKey: key(AccountCash)},
+			{Code: "1100", Name: "Accounts Receivable", AccountType: "asset", SystemKey: key(AccountReceivables)},
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, WriteIIF(&buf, accounts, []*models.JournalEntry{entry}))
+
+		out := buf.String()
+		assert.Contains(t, out, "ACCNT\tCash and Gateway Clearing\tBANK\t1000")
+		assert.Contains(t, out, "ACCNT\tAccounts Receivable\tAR\t1100")
+		assert.Contains(t, out, "TRNS\t\tGENERAL JOURNAL\t03/01/2024\tCash and Gateway Clearing\t25.00\t42")
+		assert.Contains(t, out, "SPL\t\tGENERAL JOURNAL\t03/01/2024\tAccounts Receivable\t-25.00\t42")
+		assert.Contains(t, out, "ENDTRNS\r\n")
+	})
+}
@@ -0,0 +1,201 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// SplitTax splits a tax-inclusive amount into its net and tax parts. Tax is
+// rounded half up and the net amount takes the remainder.
+func SplitTax(gross money.Money, taxRate float64) (net, tax money.Money) {
+	if taxRate <= 0 {
+		return gross, money.Zero(gross.Currency())
+	}
+	tax = gross.MulDiv(taxRate, 1+taxRate, money.HalfUp)
+	return gross.Sub(tax), tax
+}
+
+// BookingLines posts a sale: the customer owes the full fare, which is held as
+// deferred revenue (net of tax) until the sailing departs. Agent commission is
+// accrued when a commission is given.
+func BookingLines(bookingID uuid.UUID, total money.Money, taxRate float64, commission money.Money) []models.JournalLine {
+	net, tax := SplitTax(total, taxRate)
+
+	lines := []models.JournalLine{
+		debit(AccountReceivables, bookingID, total),
+		credit(AccountDeferredRevenue, bookingID, net),
+	}
+	if tax.IsPositive() {
+		lines = append(lines, credit(AccountTaxesPayable, bookingID, tax))
+	}
+
+	if commission.IsPositive() {
+		lines = append(lines,
+			debit(AccountCommissionExpense, bookingID, commission),
+			credit(AccountCommissionPayable, bookingID, commission),
+		)
+	}
+
+	return lines
+}
+
+// PaymentLines posts money received against a booking's receivable
+func PaymentLines(bookingID uuid.UUID, amount money.Money) []models.JournalLine {
+	return []models.JournalLine{
+		debit(AccountCash, bookingID, amount),
+		credit(AccountReceivables, bookingID, amount),
+	}
+}
+
+// RefundLines reverses the refunded share of a booking posting into refunds
+// payable. Tax and commission are reversed in proportion to the original
+// booking entry so partial refunds stay consistent with what was posted.
+// The fare comes out of deferred revenue, or out of recognized revenue once
+// the sailing's departure has recognized it.
+func RefundLines(bookingID uuid.UUID, bookingEntry []models.JournalLine, refund money.Money, recognized bool) ([]models.JournalLine, error) {
+	var total, tax, commission money.Money
+	for _, line := range bookingEntry {
+		switch line.AccountKey {
+		case AccountReceivables:
+			total = total.Add(line.Debit)
+		case AccountTaxesPayable:
+			tax = tax.Add(line.Credit)
+		case AccountCommissionPayable:
+			commission = commission.Add(line.Credit)
+		}
+	}
+
+	if !total.IsPositive() {
+		return nil, fmt.Errorf("booking has no posted sale to refund")
+	}
+	if !refund.IsPositive() || refund.Cmp(total) > 0 {
+		return nil, fmt.Errorf("refund amount %s must be between 0 and %s", refund, total)
+	}
+
+	refundTax := tax.Prorate(refund, total, money.HalfUp)
+	refundCommission := commission.Prorate(refund, total, money.HalfUp)
+
+	revenue := AccountDeferredRevenue
+	if recognized {
+		revenue = AccountRevenue
+	}
+
+	lines := []models.JournalLine{
+		debit(revenue, bookingID, refund.Sub(refundTax)),
+	}
+	if refundTax.IsPositive() {
+		lines = append(lines, debit(AccountTaxesPayable, bookingID, refundTax))
+	}
+	lines = append(lines, credit(AccountRefundsPayable, bookingID, refund))
+
+	if refundCommission.IsPositive() {
+		lines = append(lines,
+			debit(AccountCommissionPayable, bookingID, refundCommission),
+			credit(AccountCommissionExpense, bookingID, refundCommission),
+		)
+	}
+
+	return lines, nil
+}
+
+// RefundPayoutLines posts a refund paid back to the customer
+func RefundPayoutLines(bookingID uuid.UUID, amount money.Money) []models.JournalLine {
+	return []models.JournalLine{
+		debit(AccountRefundsPayable, bookingID, amount),
+		credit(AccountCash, bookingID, amount),
+	}
+}
+
+// DepartureLines recognizes the deferred revenue still held for each booking
+// on a sailing once it departs
+func DepartureLines(deferred map[uuid.UUID]money.Money) []models.JournalLine {
+	bookingIDs := make([]uuid.UUID, 0, len(deferred))
+	for id := range deferred {
+		bookingIDs = append(bookingIDs, id)
+	}
+	sort.Slice(bookingIDs, func(i, j int) bool {
+		return bookingIDs[i].String() < bookingIDs[j].String()
+	})
+
+	lines := []models.JournalLine{}
+	var total money.Money
+	for _, id := range bookingIDs {
+		amount := deferred[id]
+		if !amount.IsPositive() {
+			continue
+		}
+		lines = append(lines, debit(AccountDeferredRevenue, id, amount))
+		total = total.Add(amount)
+	}
+
+	if total.IsPositive() {
+		lines = append(lines, models.JournalLine{AccountKey: AccountRevenue, Credit: total})
+	}
+
+	return lines
+}
+
+// NoShowCreditLines moves the share of a no-show fare given back as travel
+// credit out of recognized revenue into a liability owed to the customer
+func NoShowCreditLines(bookingID uuid.UUID, amount money.Money) []models.JournalLine {
+	return []models.JournalLine{
+		debit(AccountRevenue, bookingID, amount),
+		credit(AccountTravelCredits, bookingID, amount),
+	}
+}
+
+// CancellationCreditLines posts fare paid for a cancelled sailing that is
+// given back as travel credit. It reverses the sale like a refund, but the
+// amount is owed to the customer as credit rather than paid out. Cancelled
+// sailings never depart, so the fare is still deferred.
+func CancellationCreditLines(bookingID uuid.UUID, bookingEntry []models.JournalLine, amount money.Money) ([]models.JournalLine, error) {
+	lines, err := RefundLines(bookingID, bookingEntry, amount, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lines {
+		if lines[i].AccountKey == AccountRefundsPayable {
+			lines[i].AccountKey = AccountTravelCredits
+		}
+	}
+	return lines, nil
+}
+
+// Validate checks that lines are one-sided, non-negative and balance
+func Validate(lines []models.JournalLine) error {
+	if len(lines) < 2 {
+		return fmt.Errorf("journal entry needs at least two lines")
+	}
+
+	var debits, credits money.Money
+	for i, line := range lines {
+		if line.Debit.IsNegative() || line.Credit.IsNegative() {
+			return fmt.Errorf("line %d has a negative amount", i+1)
+		}
+		if line.Debit.IsZero() == line.Credit.IsZero() {
+			return fmt.Errorf("line %d must have either a debit or a credit", i+1)
+		}
+		debits = debits.Add(line.Debit)
+		credits = credits.Add(line.Credit)
+	}
+
+	if debits.Cmp(credits) != 0 {
+		return fmt.Errorf("journal entry is not balanced: debits %s credits %s", debits, credits)
+	}
+
+	return nil
+}
+
+func debit(account string, bookingID uuid.UUID, amount money.Money) models.JournalLine {
+	id := bookingID
+	return models.JournalLine{AccountKey: account, BookingID: &id, Debit: amount}
+}
+
+func credit(account string, bookingID uuid.UUID, amount money.Money) models.JournalLine {
+	id := bookingID
+	return models.JournalLine{AccountKey: account, BookingID: &id, Credit: amount}
+}
@@ -0,0 +1,146 @@
+// Package lifecycle moves sailings through their statuses: scheduled,
+// boarding, departed and arrived.
+//
+// Boarding opens a set time before a sailing is expected to leave and the
+// sailing departs when its gate closes, unless staff open boarding or
+// confirm the departure first. Sailings are cancelled through the
+// cancellation workflow rather than by a status change.
+package lifecycle
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/gate"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// Sailing statuses
+const (
+	StatusScheduled = "scheduled"
+	StatusBoarding  = "boarding"
+	StatusDeparted  = "departed"
+	StatusArrived   = "arrived"
+	StatusCancelled = "cancelled"
+)
+
+// DefaultBoardingOpensBefore is how long before departure boarding opens
+// unless the operator sets otherwise
+const DefaultBoardingOpensBefore = 45 * time.Minute
+
+// MaxBoardingOpensBefore is the earliest boarding can open before departure
+const MaxBoardingOpensBefore = 6 * time.Hour
+
+// CatchUp is how long after its gate closed a sailing is still moved along
+// automatically. Older sailings are left to staff, so that restarting after
+// an outage does not depart old sailings in bulk.
+const CatchUp = 12 * time.Hour
+
+// transitions lists the statuses each status may move to. A sailing can be
+// confirmed departed without boarding having been opened.
+var transitions = map[string][]string{
+	StatusScheduled: {StatusBoarding, StatusDeparted},
+	StatusBoarding:  {StatusDeparted},
+	StatusDeparted:  {StatusArrived},
+}
+
+// TransitionError is returned for a status change the lifecycle does not allow
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	if e.To == StatusCancelled {
+		return "sailings are cancelled through the schedule cancellation, not a status change"
+	}
+	return fmt.Sprintf("a %s sailing cannot become %s", e.From, e.To)
+}
+
+// Check returns a TransitionError unless a sailing may move from one status
+// to the other
+func Check(from, to string) error {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to}
+}
+
+// Policy is an operator's lifecycle policy
+type Policy struct {
+	BoardingOpensBefore time.Duration
+	GateClosesBefore    time.Duration
+}
+
+// PolicyFromSettings reads the lifecycle policy from operator settings.
+// "boarding_opens_minutes" is how long before departure boarding opens, at
+// most MaxBoardingOpensBefore. The gate closes when boarding closes at the
+// gate.
+func PolicyFromSettings(settings map[string]interface{}) Policy {
+	policy := Policy{
+		BoardingOpensBefore: DefaultBoardingOpensBefore,
+		GateClosesBefore:    gate.DefaultPolicy.BoardingClosesBefore,
+	}
+
+	if minutes := number(settings["boarding_opens_minutes"]); minutes >= 0 {
+		policy.BoardingOpensBefore = time.Duration(minutes) * time.Minute
+		if policy.BoardingOpensBefore > MaxBoardingOpensBefore {
+			policy.BoardingOpensBefore = MaxBoardingOpensBefore
+		}
+		if policy.BoardingOpensBefore < policy.GateClosesBefore {
+			policy.BoardingOpensBefore = policy.GateClosesBefore
+		}
+	}
+
+	return policy
+}
+
+// number reads a numeric setting, or -1 when it is not a number
+func number(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return -1
+		}
+		return f
+	default:
+		return -1
+	}
+}
+
+// Due returns the status a sailing on sale is due to move to at now, or ""
+// if it is not due to move. Times are taken from when the sailing is now
+// expected to leave, so a delay holds back boarding and departure.
+func (p Policy) Due(schedule *models.Schedule, now time.Time) string {
+	if schedule.Unpublished {
+		return ""
+	}
+
+	departure := schedule.ExpectedDepartureAt()
+	gateCloses := departure.Add(-p.GateClosesBefore)
+	if now.After(gateCloses.Add(CatchUp)) {
+		return ""
+	}
+
+	switch schedule.Status {
+	case StatusScheduled:
+		if !now.Before(gateCloses) {
+			return StatusDeparted
+		}
+		if !now.Before(departure.Add(-p.BoardingOpensBefore)) {
+			return StatusBoarding
+		}
+	case StatusBoarding:
+		if !now.Before(gateCloses) {
+			return StatusDeparted
+		}
+	}
+
+	return ""
+}
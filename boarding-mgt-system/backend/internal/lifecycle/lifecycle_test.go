@@ -0,0 +1,61 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check(StatusScheduled, StatusBoarding))
+	assert.NoError(t, Check(StatusScheduled, StatusDeparted))
+	assert.NoError(t, Check(StatusBoarding, StatusDeparted))
+	assert.NoError(t, Check(StatusDeparted, StatusArrived))
+
+	assert.EqualError(t, Check(StatusBoarding, StatusScheduled), "a boarding sailing cannot become scheduled")
+	assert.EqualError(t, Check(StatusScheduled, StatusArrived), "a scheduled sailing cannot become arrived")
+	assert.Error(t, Check(StatusArrived, StatusDeparted))
+	assert.Error(t, Check(StatusCancelled, StatusScheduled))
+	assert.Error(t, Check(StatusScheduled, "completed"))
+
+	err := Check(StatusScheduled, StatusCancelled)
+	assert.IsType(t, &TransitionError{}, err)
+	assert.Contains(t, err.Error(), "cancellation")
+}
+
+func TestPolicyFromSettings(t *testing.T) {
+	assert.Equal(t, Policy{BoardingOpensBefore: 45 * time.Minute, GateClosesBefore: 5 * time.Minute}, PolicyFromSettings(nil))
+	assert.Equal(t, 30*time.Minute, PolicyFromSettings(map[string]interface{}{"boarding_opens_minutes": 30.0}).BoardingOpensBefore)
+	assert.Equal(t, 20*time.Minute, PolicyFromSettings(map[string]interface{}{"boarding_opens_minutes": "20"}).BoardingOpensBefore)
+	assert.Equal(t, MaxBoardingOpensBefore, PolicyFromSettings(map[string]interface{}{"boarding_opens_minutes": 1000.0}).BoardingOpensBefore)
+	assert.Equal(t, 5*time.Minute, PolicyFromSettings(map[string]interface{}{"boarding_opens_minutes": 0.0}).BoardingOpensBefore, "boarding opens by the time the gate closes")
+	assert.Equal(t, DefaultBoardingOpensBefore, PolicyFromSettings(map[string]interface{}{"boarding_opens_minutes": "soon"}).BoardingOpensBefore)
+}
+
+func TestDue(t *testing.T) {
+	policy := Policy{BoardingOpensBefore: 30 * time.Minute, GateClosesBefore: 5 * time.Minute}
+	departure := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	sailing := func(status string) *models.Schedule {
+		return &models.Schedule{Status: status, DepartureAt: departure, ArrivalAt: departure.Add(90 * time.Minute)}
+	}
+
+	assert.Equal(t, "", policy.Due(sailing(StatusScheduled), departure.Add(-31*time.Minute)))
+	assert.Equal(t, StatusBoarding, policy.Due(sailing(StatusScheduled), departure.Add(-30*time.Minute)))
+	assert.Equal(t, "", policy.Due(sailing(StatusBoarding), departure.Add(-6*time.Minute)))
+	assert.Equal(t, StatusDeparted, policy.Due(sailing(StatusBoarding), departure.Add(-5*time.Minute)))
+	assert.Equal(t, StatusDeparted, policy.Due(sailing(StatusScheduled), departure), "boarding never opened")
+	assert.Equal(t, "", policy.Due(sailing(StatusDeparted), departure.Add(2*time.Hour)))
+	assert.Equal(t, "", policy.Due(sailing(StatusScheduled), departure.Add(CatchUp)), "left to staff after an outage")
+
+	delayed := sailing(StatusScheduled)
+	estimated := departure.Add(time.Hour)
+	delayed.EstimatedDepartureAt = &estimated
+	assert.Equal(t, "", policy.Due(delayed, departure.Add(-10*time.Minute)), "a delay holds back boarding")
+	assert.Equal(t, StatusBoarding, policy.Due(delayed, estimated.Add(-20*time.Minute)))
+
+	unpublished := sailing(StatusScheduled)
+	unpublished.Unpublished = true
+	assert.Equal(t, "", policy.Due(unpublished, departure))
+}
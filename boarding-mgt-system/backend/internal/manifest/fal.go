@@ -0,0 +1,194 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/go-pdf/fpdf"
+)
+
+// falColumns are the passenger columns of an IMO FAL Form 6 passenger list,
+// numbered as on the form. Place of birth and visa number are not captured
+// and are left out.
+var falColumns = []struct {
+	heading string
+	width   float64
+}{
+	{"No.", 9},
+	{"6 Family name, given names", 62},
+	{"Gender", 14},
+	{"7 Nationality", 18},
+	{"8 Date of birth", 20},
+	{"10 Type of identity document", 26},
+	{"11 Serial number of identity document", 30},
+	{"12 Issuing State of identity document", 20},
+	{"13 Expiry date of identity document", 20},
+	{"14 Port of embarkation", 20},
+	{"15 Port of disembarkation", 22},
+	{"16 Transit passenger", 16},
+}
+
+var documentTypes = map[string]string{
+	"passport":         "Passport",
+	"id_card":          "Identity card",
+	"residence_permit": "Residence permit",
+	"other":            "Other",
+}
+
+// FAL writes a printable passenger list laid out like IMO FAL Form 6, for
+// port authorities that take the form on paper or as a PDF
+type FAL struct{}
+
+func (FAL) ContentType() string { return "application/pdf" }
+
+func (FAL) Extension() string { return "pdf" }
+
+func (FAL) Write(w io.Writer, m *models.Manifest) error {
+	if m.Voyage == nil {
+		return fmt.Errorf("manifest has no voyage details")
+	}
+	voyage := m.Voyage
+
+	voyageNumber := ""
+	if m.Schedule != nil {
+		voyageNumber = strings.ToUpper(m.Schedule.ID.String()[:8])
+	}
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Passenger list %s %s", voyage.VesselName, voyage.Departure.Format("2006-01-02")), true)
+	pdf.SetAuthor(voyage.OperatorName, true)
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(false, 10)
+	pdf.AliasNbPages("")
+
+	// Core fonts are cp1252; translate names from UTF-8
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	left, _, _, bottom := pdf.GetMargins()
+	pageWidth, pageHeight := pdf.GetPageSize()
+	width := pageWidth - 2*left
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetXY(left, pageHeight-bottom-4)
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.CellFormat(width, 4, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(width/2, 8, "PASSENGER LIST", "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(width/2, 8, "IMO FAL Form 6", "", 1, "R", false, 0, "")
+	pdf.Ln(2)
+
+	// Ship and voyage boxes, two rows of four
+	boxes := [][2]string{
+		{"1.1 Name of ship", voyage.VesselName},
+		{"1.2 Registration number", voyage.VesselRegistration},
+		{"1.4 Voyage number", voyageNumber},
+		{"Operator", voyage.OperatorName},
+		{"2 Port of departure", portName(voyage.DeparturePort, voyage.DeparturePortCode, voyage.DepartureCountry)},
+		{"3 Date of departure", voyage.Departure.Format("2006-01-02 15:04 MST")},
+		{"Port of arrival", portName(voyage.ArrivalPort, voyage.ArrivalPortCode, voyage.ArrivalCountry)},
+		{"Number of passengers", strconv.Itoa(len(m.Passengers))},
+	}
+	boxWidth := width / 4
+	for i, box := range boxes {
+		x := left + float64(i%4)*boxWidth
+		y := pdf.GetY()
+		pdf.Rect(x, y, boxWidth, 12, "D")
+		pdf.SetXY(x+1, y+1)
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.CellFormat(boxWidth-2, 4, box[0], "", 2, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(boxWidth-2, 6, tr(box[1]), "", 0, "L", false, 0, "")
+		if i%4 == 3 {
+			pdf.SetXY(left, y+12)
+		} else {
+			pdf.SetXY(x+boxWidth, y)
+		}
+	}
+	pdf.Ln(4)
+
+	writeFALHeadings(pdf, left)
+
+	rowHeight := 6.0
+	for i, entry := range m.Passengers {
+		if pdf.GetY()+rowHeight > pageHeight-bottom-6 {
+			pdf.AddPage()
+			writeFALHeadings(pdf, left)
+		}
+
+		documentType := documentTypes[entry.DocumentType]
+		if documentType == "" {
+			documentType = entry.DocumentType
+		}
+		values := []string{
+			strconv.Itoa(i + 1),
+			entry.PassengerName,
+			entry.Sex,
+			entry.Nationality,
+			entry.DateOfBirth,
+			documentType,
+			entry.DocumentNumber,
+			entry.DocumentIssuingCountry,
+			entry.DocumentExpiry,
+			voyage.DeparturePortCode,
+			voyage.ArrivalPortCode,
+			"No",
+		}
+
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetX(left)
+		for c, column := range falColumns {
+			pdf.CellFormat(column.width, rowHeight, tr(values[c]), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(rowHeight)
+	}
+
+	// Attestation
+	if pdf.GetY()+16 > pageHeight-bottom-6 {
+		pdf.AddPage()
+	}
+	pdf.Ln(8)
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.CellFormat(width/2, 5, "Date and signature by master, authorized agent or officer", "T", 1, "L", false, 0, "")
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to render FAL manifest: %w", err)
+	}
+	return nil
+}
+
+// writeFALHeadings draws the column headings, wrapping each inside its cell
+func writeFALHeadings(pdf *fpdf.Fpdf, left float64) {
+	height := 10.0
+	y := pdf.GetY()
+	x := left
+
+	pdf.SetFont("Helvetica", "B", 6.5)
+	pdf.SetFillColor(230, 230, 230)
+	for _, column := range falColumns {
+		pdf.Rect(x, y, column.width, height, "FD")
+		pdf.SetXY(x, y+0.5)
+		pdf.MultiCell(column.width, 3, column.heading, "", "L", false)
+		x += column.width
+	}
+	pdf.SetXY(left, y+height)
+}
+
+// portName formats a port as "Piraeus (PIR), Greece"
+func portName(name, code, country string) string {
+	s := name
+	if code != "" {
+		s += " (" + code + ")"
+	}
+	if country != "" {
+		s += ", " + country
+	}
+	return s
+}
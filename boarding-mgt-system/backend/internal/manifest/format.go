@@ -0,0 +1,105 @@
+package manifest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// Formatter renders a manifest in one export format
+type Formatter interface {
+	// ContentType is the media type of the rendered file
+	ContentType() string
+	// Extension is the file name extension, without the dot
+	Extension() string
+	Write(w io.Writer, m *models.Manifest) error
+}
+
+var formatters = map[string]Formatter{
+	"csv":  CSV{},
+	"xlsx": XLSX{},
+	"fal":  FAL{},
+}
+
+// Register adds or replaces the formatter for a format name. It is meant to
+// be called during program initialization.
+func Register(name string, f Formatter) {
+	formatters[name] = f
+}
+
+// Lookup returns the formatter for a format name
+func Lookup(name string) (Formatter, error) {
+	f, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported manifest format: %s", name)
+	}
+	return f, nil
+}
+
+// Formats lists the registered format names
+func Formats() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// header is the column layout of tabular exports
+var header = []string{
+	"no", "passenger_name", "passenger_type", "sex", "date_of_birth", "nationality",
+	"document_type", "document_number", "document_issuing_country", "document_expiry",
+	"port_of_embarkation", "port_of_disembarkation", "seat_number", "booking_ref", "check_in_status",
+}
+
+// table lays out a manifest as a header row and one row per passenger.
+// Contact details are left out; authorities do not ask for them.
+func table(m *models.Manifest) [][]string {
+	var embarkation, disembarkation string
+	if m.Voyage != nil {
+		embarkation, disembarkation = m.Voyage.DeparturePortCode, m.Voyage.ArrivalPortCode
+	}
+
+	rows := make([][]string, 0, len(m.Passengers)+1)
+	rows = append(rows, header)
+	for i, entry := range m.Passengers {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			entry.PassengerName,
+			entry.PassengerType,
+			entry.Sex,
+			entry.DateOfBirth,
+			entry.Nationality,
+			entry.DocumentType,
+			entry.DocumentNumber,
+			entry.DocumentIssuingCountry,
+			entry.DocumentExpiry,
+			embarkation,
+			disembarkation,
+			entry.SeatNumber,
+			entry.BookingRef,
+			entry.CheckInStatus,
+		})
+	}
+	return rows
+}
+
+// CSV writes one comma-separated row per passenger
+type CSV struct{}
+
+func (CSV) ContentType() string { return "text/csv" }
+
+func (CSV) Extension() string { return "csv" }
+
+func (CSV) Write(w io.Writer, m *models.Manifest) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(table(m)); err != nil {
+		return fmt.Errorf("failed to write CSV manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Package manifest exports passenger manifests in the formats port
+// authorities accept and checks that the identity details they require have
+// been captured for every passenger.
+package manifest
+
+import (
+	"strings"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// Identity fields a policy can make mandatory
+const (
+	FieldNationality            = "nationality"
+	FieldDateOfBirth            = "date_of_birth"
+	FieldSex                    = "sex"
+	FieldDocumentType           = "document_type"
+	FieldDocumentNumber         = "document_number"
+	FieldDocumentIssuingCountry = "document_issuing_country"
+	FieldDocumentExpiry         = "document_expiry"
+)
+
+// Fields lists every identity field in manifest column order
+var Fields = []string{
+	FieldNationality, FieldDateOfBirth, FieldSex, FieldDocumentType,
+	FieldDocumentNumber, FieldDocumentIssuingCountry, FieldDocumentExpiry,
+}
+
+// DefaultRequiredFields are the identity details of an IMO FAL Form 6
+// passenger list
+var DefaultRequiredFields = []string{
+	FieldNationality, FieldDateOfBirth, FieldSex, FieldDocumentType, FieldDocumentNumber,
+}
+
+// Policy sets which identity fields every passenger must have and whether a
+// sailing may depart without them
+type Policy struct {
+	RequiredFields []string
+	BlockDeparture bool
+}
+
+// PolicyFromSettings reads the manifest policy from operator settings.
+// "manifest_required_fields" is a list (or comma-separated string) of field
+// names and defaults to DefaultRequiredFields; unknown names are ignored.
+// Departure is blocked only when "manifest_blocks_departure" is true.
+func PolicyFromSettings(settings map[string]interface{}) Policy {
+	policy := Policy{RequiredFields: DefaultRequiredFields}
+
+	var names []string
+	switch v := settings["manifest_required_fields"].(type) {
+	case []interface{}:
+		names = []string{}
+		for _, name := range v {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+	case []string:
+		names = v
+	case string:
+		names = []string{}
+		for _, name := range strings.Split(v, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	if names != nil {
+		policy.RequiredFields = []string{}
+		for _, field := range Fields {
+			for _, name := range names {
+				if name == field {
+					policy.RequiredFields = append(policy.RequiredFields, field)
+					break
+				}
+			}
+		}
+	}
+
+	switch v := settings["manifest_blocks_departure"].(type) {
+	case bool:
+		policy.BlockDeparture = v
+	case string:
+		policy.BlockDeparture = v == "true"
+	}
+
+	return policy
+}
+
+// Value returns a passenger's identity field by name
+func Value(identity models.PassengerIdentity, field string) string {
+	switch field {
+	case FieldNationality:
+		return identity.Nationality
+	case FieldDateOfBirth:
+		return identity.DateOfBirth
+	case FieldSex:
+		return identity.Sex
+	case FieldDocumentType:
+		return identity.DocumentType
+	case FieldDocumentNumber:
+		return identity.DocumentNumber
+	case FieldDocumentIssuingCountry:
+		return identity.DocumentIssuingCountry
+	case FieldDocumentExpiry:
+		return identity.DocumentExpiry
+	default:
+		return ""
+	}
+}
+
+// Missing returns the required fields a passenger has not provided
+func Missing(identity models.PassengerIdentity, required []string) []string {
+	missing := []string{}
+	for _, field := range required {
+		if strings.TrimSpace(Value(identity, field)) == "" {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// Check lists the passengers on a manifest missing any field the policy
+// requires. An incomplete manifest blocks departure when the policy says so.
+func Check(m *models.Manifest, policy Policy) *models.ManifestCompleteness {
+	result := &models.ManifestCompleteness{
+		RequiredFields: policy.RequiredFields,
+		Incomplete:     []models.ManifestGap{},
+	}
+	if m.Schedule != nil {
+		result.ScheduleID = m.Schedule.ID
+	}
+
+	for _, entry := range m.Passengers {
+		missing := Missing(entry.PassengerIdentity, policy.RequiredFields)
+		if len(missing) == 0 {
+			continue
+		}
+		result.Incomplete = append(result.Incomplete, models.ManifestGap{
+			TicketID:      entry.TicketID,
+			PassengerName: entry.PassengerName,
+			BookingRef:    entry.BookingRef,
+			Missing:       missing,
+		})
+	}
+
+	result.Complete = len(result.Incomplete) == 0
+	result.BlocksDeparture = !result.Complete && policy.BlockDeparture
+	return result
+}
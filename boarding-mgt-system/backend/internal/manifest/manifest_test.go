@@ -0,0 +1,180 @@
+package manifest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testManifest() *models.Manifest {
+	return &models.Manifest{
+		Schedule: &models.Schedule{ID: uuid.New()},
+		Voyage: &models.ManifestVoyage{
+			OperatorName:       "Blue Star",
+			VesselName:         "Delos",
+			VesselRegistration: "9565039",
+			DeparturePort:      "Piraeus",
+			DeparturePortCode:  "PIR",
+			DepartureCountry:   "GR",
+			ArrivalPort:        "Naxos",
+			ArrivalPortCode:    "NAX",
+			ArrivalCountry:     "GR",
+			Departure:          time.Date(2024, 6, 1, 9, 30, 0, 0, time.FixedZone("EEST", 3*60*60)),
+		},
+		TotalPassengers: 2,
+		Passengers: []models.ManifestEntry{
+			{
+				TicketID:      uuid.New(),
+				PassengerName: "Ana Pappás",
+				PassengerType: "adult",
+				SeatNumber:    "12A",
+				BookingRef:    "FFABC123",
+				CheckInStatus: "checked_in",
+				CustomerEmail: "ana@example.com",
+				PassengerIdentity: models.PassengerIdentity{
+					Nationality:    "GR",
+					DateOfBirth:    "1990-04-12",
+					Sex:            "F",
+					DocumentType:   "passport",
+					DocumentNumber: "AB1234567",
+				},
+			},
+			{
+				TicketID:      uuid.New(),
+				PassengerName: "Tom <Smith> & Co",
+				PassengerType: "child",
+				BookingRef:    "FFABC123",
+				CheckInStatus: "not_checked_in",
+				PassengerIdentity: models.PassengerIdentity{
+					Nationality: "GB",
+					Sex:         "M",
+				},
+			},
+		},
+	}
+}
+
+func TestPolicyFromSettings(t *testing.T) {
+	policy := PolicyFromSettings(map[string]interface{}{})
+	assert.Equal(t, DefaultRequiredFields, policy.RequiredFields)
+	assert.False(t, policy.BlockDeparture)
+
+	policy = PolicyFromSettings(map[string]interface{}{
+		"manifest_required_fields":  []interface{}{"document_number", "nationality", "shoe_size"},
+		"manifest_blocks_departure": true,
+	})
+	assert.Equal(t, []string{FieldNationality, FieldDocumentNumber}, policy.RequiredFields, "known fields in column order")
+	assert.True(t, policy.BlockDeparture)
+
+	policy = PolicyFromSettings(map[string]interface{}{"manifest_required_fields": "sex, date_of_birth"})
+	assert.Equal(t, []string{FieldDateOfBirth, FieldSex}, policy.RequiredFields)
+
+	policy = PolicyFromSettings(map[string]interface{}{"manifest_required_fields": []interface{}{}})
+	assert.Empty(t, policy.RequiredFields, "an empty list requires nothing")
+}
+
+func TestCheck(t *testing.T) {
+	m := testManifest()
+
+	result := Check(m, Policy{RequiredFields: DefaultRequiredFields, BlockDeparture: true})
+	assert.False(t, result.Complete)
+	assert.True(t, result.BlocksDeparture)
+	require.Len(t, result.Incomplete, 1)
+	assert.Equal(t, m.Passengers[1].TicketID, result.Incomplete[0].TicketID)
+	assert.Equal(t, []string{FieldDateOfBirth, FieldDocumentType, FieldDocumentNumber}, result.Incomplete[0].Missing)
+
+	t.Run("Not blocking", func(t *testing.T) {
+		result := Check(m, Policy{RequiredFields: DefaultRequiredFields})
+		assert.False(t, result.Complete)
+		assert.False(t, result.BlocksDeparture)
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		result := Check(m, Policy{RequiredFields: []string{FieldNationality, FieldSex}, BlockDeparture: true})
+		assert.True(t, result.Complete)
+		assert.False(t, result.BlocksDeparture)
+		assert.Empty(t, result.Incomplete)
+	})
+}
+
+func TestLookup(t *testing.T) {
+	assert.Equal(t, []string{"csv", "fal", "xlsx"}, Formats())
+
+	f, err := Lookup("xlsx")
+	require.NoError(t, err)
+	assert.Equal(t, "xlsx", f.Extension())
+
+	_, err = Lookup("docx")
+	assert.Error(t, err)
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, CSV{}.Write(&buf, testManifest()))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, header, rows[0])
+	assert.Equal(t, []string{
+		"1", "Ana Pappás", "adult", "F", "1990-04-12", "GR", "passport", "AB1234567", "", "",
+		"PIR", "NAX", "12A", "FFABC123", "checked_in",
+	}, rows[1])
+	assert.NotContains(t, buf.String(), "ana@example.com", "contact details are not exported")
+}
+
+func TestXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, XLSX{}.Write(&buf, testManifest()))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+		files[f.Name] = string(data)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		assert.Contains(t, files, name)
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Equal(t, 3, strings.Count(sheet, "<row "))
+	assert.Contains(t, sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">Ana Pappás</t></is></c>`)
+	assert.Contains(t, sheet, "Tom &lt;Smith&gt; &amp; Co", "values are escaped")
+	assert.Contains(t, sheet, `r="O3"`, "fifteen columns")
+
+	assert.Equal(t, "A", column(0))
+	assert.Equal(t, "Z", column(25))
+	assert.Equal(t, "AA", column(26))
+}
+
+func TestFAL(t *testing.T) {
+	m := testManifest()
+	// Enough passengers to run onto a second page
+	for i := 0; i < 40; i++ {
+		m.Passengers = append(m.Passengers, m.Passengers[0])
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, FAL{}.Write(&buf, m))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	assert.Contains(t, buf.String(), "/Count 2")
+
+	m.Voyage = nil
+	assert.Error(t, FAL{}.Write(&buf, m))
+}
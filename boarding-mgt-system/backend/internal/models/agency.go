@@ -0,0 +1,148 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// Agency represents a travel agency selling an operator's sailings on commission
+type Agency struct {
+	ID                    uuid.UUID   `json:"id" db:"id"`
+	OperatorID            uuid.UUID   `json:"operator_id" db:"operator_id"`
+	Code                  string      `json:"code" db:"code"`
+	Name                  string      `json:"name" db:"name"`
+	ContactEmail          *string     `json:"contact_email,omitempty" db:"contact_email"`
+	ContactPhone          *string     `json:"contact_phone,omitempty" db:"contact_phone"`
+	AccountType           string      `json:"account_type" db:"account_type"`
+	CreditLimit           money.Money `json:"credit_limit" db:"credit_limit"`
+	Balance               money.Money `json:"balance" db:"balance"`
+	DefaultCommissionRate float64     `json:"default_commission_rate" db:"default_commission_rate"`
+	IsActive              bool        `json:"is_active" db:"is_active"`
+	CreatedAt             time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time   `json:"updated_at" db:"updated_at"`
+
+	// Computed from the balance and credit limit
+	AvailableCredit money.Money `json:"available_credit" db:"-"`
+
+	// Joined fields
+	CommissionRules []AgencyCommissionRule `json:"commission_rules,omitempty" db:"-"`
+}
+
+// AgencyCommissionRule represents a commission rate for a route and/or fare class
+type AgencyCommissionRule struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	AgencyID       uuid.UUID  `json:"agency_id" db:"agency_id"`
+	RouteID        *uuid.UUID `json:"route_id,omitempty" db:"route_id"`
+	FareClass      *string    `json:"fare_class,omitempty" db:"fare_class"`
+	CommissionRate float64    `json:"commission_rate" db:"commission_rate"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AgencyTransaction represents a movement on an agency's account. Positive
+// amounts credit the agency and negative amounts charge it.
+type AgencyTransaction struct {
+	ID              uuid.UUID   `json:"id" db:"id"`
+	AgencyID        uuid.UUID   `json:"agency_id" db:"agency_id"`
+	BookingID       *uuid.UUID  `json:"booking_id,omitempty" db:"booking_id"`
+	TransactionType string      `json:"transaction_type" db:"transaction_type"`
+	Amount          money.Money `json:"amount" db:"amount"`
+	BalanceAfter    money.Money `json:"balance_after" db:"balance_after"`
+	Reference       *string     `json:"reference,omitempty" db:"reference"`
+	Description     *string     `json:"description,omitempty" db:"description"`
+	CreatedBy       *uuid.UUID  `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt       time.Time   `json:"created_at" db:"created_at"`
+
+	// Joined fields
+	BookingReference *string `json:"booking_reference,omitempty" db:"-"`
+}
+
+// AgencyStatementLine represents the sales and commission on one booking in a statement
+type AgencyStatementLine struct {
+	BookingID        uuid.UUID   `json:"booking_id"`
+	BookingReference string      `json:"booking_reference"`
+	Sales            money.Money `json:"sales"`
+	Refunds          money.Money `json:"refunds"`
+	Commission       money.Money `json:"commission"`
+}
+
+// AgencyStatement represents an agency's account activity and commission for a period
+type AgencyStatement struct {
+	AgencyID       uuid.UUID             `json:"agency_id"`
+	AgencyCode     string                `json:"agency_code"`
+	AgencyName     string                `json:"agency_name"`
+	PeriodStart    time.Time             `json:"period_start"`
+	PeriodEnd      time.Time             `json:"period_end"`
+	OpeningBalance money.Money           `json:"opening_balance"`
+	Sales          money.Money           `json:"sales"`
+	Refunds        money.Money           `json:"refunds"`
+	Commission     money.Money           `json:"commission"`
+	Payments       money.Money           `json:"payments"`
+	Adjustments    money.Money           `json:"adjustments"`
+	ClosingBalance money.Money           `json:"closing_balance"`
+	Bookings       []AgencyStatementLine `json:"bookings"`
+	Transactions   []AgencyTransaction   `json:"transactions"`
+}
+
+// CreateAgencyRequest represents agency creation data
+type CreateAgencyRequest struct {
+	OperatorID            *uuid.UUID  `json:"operator_id,omitempty"`
+	Code                  string      `json:"code" binding:"required,max=20"`
+	Name                  string      `json:"name" binding:"required,max=255"`
+	ContactEmail          string      `json:"contact_email,omitempty" binding:"omitempty,email"`
+	ContactPhone          string      `json:"contact_phone,omitempty"`
+	AccountType           string      `json:"account_type" binding:"required,oneof=prepaid credit"`
+	CreditLimit           money.Money `json:"credit_limit"`
+	DefaultCommissionRate float64     `json:"default_commission_rate" binding:"min=0,max=1"`
+}
+
+// UpdateAgencyRequest represents agency update data
+type UpdateAgencyRequest struct {
+	Name                  *string      `json:"name,omitempty"`
+	ContactEmail          *string      `json:"contact_email,omitempty" binding:"omitempty,email"`
+	ContactPhone          *string      `json:"contact_phone,omitempty"`
+	AccountType           *string      `json:"account_type,omitempty" binding:"omitempty,oneof=prepaid credit"`
+	CreditLimit           *money.Money `json:"credit_limit,omitempty"`
+	DefaultCommissionRate *float64     `json:"default_commission_rate,omitempty" binding:"omitempty,min=0,max=1"`
+	IsActive              *bool        `json:"is_active,omitempty"`
+}
+
+// CreateCommissionRuleRequest represents a commission rate for a route and/or fare class
+type CreateCommissionRuleRequest struct {
+	RouteID        *uuid.UUID `json:"route_id,omitempty"`
+	FareClass      *string    `json:"fare_class,omitempty" binding:"omitempty,oneof=adult child infant senior"`
+	CommissionRate float64    `json:"commission_rate" binding:"min=0,max=1"`
+}
+
+// AgencyTransactionRequest represents money received from an agency or a
+// manual correction to its account
+type AgencyTransactionRequest struct {
+	TransactionType string      `json:"transaction_type" binding:"required,oneof=top_up payment adjustment"`
+	Amount          money.Money `json:"amount"`
+	Reference       string      `json:"reference,omitempty" binding:"max=100"`
+	Description     string      `json:"description,omitempty"`
+}
+
+// AssignAgentRequest represents linking an agent user to an agency
+type AssignAgentRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// CreateAgencyBookingRequest represents a booking sold by an agency agent and
+// paid from the agency's account
+type CreateAgencyBookingRequest struct {
+	ScheduleID          uuid.UUID       `json:"schedule_id" binding:"required"`
+	CustomerID          uuid.UUID       `json:"customer_id" binding:"required"`
+	Passengers          []PassengerInfo `json:"passengers" binding:"required,min=1,dive"`
+	SpecialRequirements string          `json:"special_requirements,omitempty"`
+}
+
+// AgencyFilter represents filters for listing agencies
+type AgencyFilter struct {
+	OperatorID  *uuid.UUID `json:"operator_id,omitempty"`
+	IsActive    *bool      `json:"is_active,omitempty"`
+	Outstanding bool       `json:"outstanding,omitempty"`
+	Limit       int        `json:"limit,omitempty"`
+	Offset      int        `json:"offset,omitempty"`
+}
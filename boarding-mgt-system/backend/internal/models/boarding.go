@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BoardingScan represents a check-in or boarding scan at a gate
+type BoardingScan struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	TicketID       *uuid.UUID `json:"ticket_id,omitempty" db:"ticket_id"`
+	ScheduleID     uuid.UUID  `json:"schedule_id" db:"schedule_id"`
+	Action         string     `json:"action" db:"action"`
+	Result         string     `json:"result" db:"result"`
+	DenyReason     *string    `json:"deny_reason,omitempty" db:"deny_reason"`
+	CheckInStatus  *string    `json:"check_in_status,omitempty" db:"check_in_status"`
+	Gate           string     `json:"gate" db:"gate"`
+	DeviceID       string     `json:"device_id" db:"device_id"`
+	ScannedBy      *uuid.UUID `json:"scanned_by,omitempty" db:"scanned_by"`
+	OverrideReason *string    `json:"override_reason,omitempty" db:"override_reason"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+
+	// Set on scans uploaded by a registered scanner device
+	ScannerDeviceID *uuid.UUID `json:"scanner_device_id,omitempty" db:"scanner_device_id"`
+	EventID         *uuid.UUID `json:"event_id,omitempty" db:"event_id"`
+	DeviceScannedAt *time.Time `json:"device_scanned_at,omitempty" db:"device_scanned_at"`
+	Conflict        *string    `json:"conflict,omitempty" db:"conflict"`
+	ConflictDetail  *string    `json:"conflict_detail,omitempty" db:"conflict_detail"`
+
+	// Joined fields
+	PassengerName    string  `json:"passenger_name,omitempty" db:"-"`
+	PassengerType    string  `json:"passenger_type,omitempty" db:"-"`
+	SeatNumber       *string `json:"seat_number,omitempty" db:"-"`
+	BookingReference string  `json:"booking_reference,omitempty" db:"-"`
+
+	// Set on scan responses
+	Message     string `json:"message,omitempty" db:"-"`
+	Overridable bool   `json:"overridable,omitempty" db:"-"`
+}
+
+// GateScanRequest represents a ticket scanned at a gate. Override lets a
+// supervisor accept a scan despite an overridable deny reason.
+type GateScanRequest struct {
+	QRCode         string    `json:"qr_code" binding:"required"`
+	ScheduleID     uuid.UUID `json:"schedule_id" binding:"required"`
+	Gate           string    `json:"gate" binding:"required,max=50"`
+	DeviceID       string    `json:"device_id" binding:"required,max=100"`
+	Override       bool      `json:"override,omitempty"`
+	OverrideReason string    `json:"override_reason,omitempty" binding:"required_if=Override true"`
+}
+
+// BoardingScanFilter represents filters for listing gate scans
+type BoardingScanFilter struct {
+	ScheduleID uuid.UUID  `json:"schedule_id"`
+	Result     string     `json:"result,omitempty"`
+	Gate       string     `json:"gate,omitempty"`
+	TicketID   *uuid.UUID `json:"ticket_id,omitempty"`
+	Limit      int        `json:"limit,omitempty"`
+	Offset     int        `json:"offset,omitempty"`
+}
+
+// BoardingCounts is a sailing's check-in and boarding progress
+type BoardingCounts struct {
+	ScheduleID      uuid.UUID `json:"schedule_id"`
+	OperatorID      uuid.UUID `json:"operator_id"`
+	PortID          uuid.UUID `json:"port_id"`
+	Departure       time.Time `json:"departure"` // Expected departure, after any delay
+	Status          string    `json:"status"`
+	Capacity        int       `json:"capacity"`
+	TotalPassengers int       `json:"total_passengers"`
+	CheckedIn       int       `json:"checked_in"`
+	Boarded         int       `json:"boarded"`
+}
+
+// BoardingAlert warns boarding supervisors about a sailing's headcount
+type BoardingAlert struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// BoardingEvent is pushed to live boarding dashboards: a sailing's counts, a
+// gate scan or an alert
+type BoardingEvent struct {
+	Type       string          `json:"type"`
+	ScheduleID uuid.UUID       `json:"schedule_id"`
+	OperatorID uuid.UUID       `json:"operator_id"`
+	PortID     uuid.UUID       `json:"port_id"`
+	Counts     *BoardingCounts `json:"counts,omitempty"`
+	Scan       *BoardingScan   `json:"scan,omitempty"`
+	Alert      *BoardingAlert  `json:"alert,omitempty"`
+	At         time.Time       `json:"at"`
+}
@@ -1,8 +1,10 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -15,7 +17,20 @@ type Schedule struct {
 	DepartureDate     time.Time  `json:"departure_date" db:"departure_date"`
 	DepartureTime     time.Time  `json:"departure_time" db:"departure_time"`
 	ArrivalTime       time.Time  `json:"arrival_time" db:"arrival_time"`
-	BasePrice         float64    `json:"base_price" db:"base_price"`
+	DepartureAt       time.Time  `json:"departure_at" db:"departure_at"` // UTC
+	ArrivalAt         time.Time  `json:"arrival_at" db:"arrival_at"`     // UTC
+	DepartureLocal    *time.Time `json:"departure_local,omitempty" db:"-"` // At the departure port, with its UTC offset
+	ArrivalLocal      *time.Time `json:"arrival_local,omitempty" db:"-"`   // At the arrival port, with its UTC offset
+	DepartureTimezone string     `json:"departure_timezone,omitempty" db:"-"`
+	ArrivalTimezone   string     `json:"arrival_timezone,omitempty" db:"-"`
+	EstimatedDepartureAt *time.Time `json:"estimated_departure_at,omitempty" db:"estimated_departure_at"` // Set while the sailing is delayed
+	EstimatedArrivalAt   *time.Time `json:"estimated_arrival_at,omitempty" db:"estimated_arrival_at"`
+	EstimatedDepartureLocal *time.Time `json:"estimated_departure_local,omitempty" db:"-"`
+	EstimatedArrivalLocal   *time.Time `json:"estimated_arrival_local,omitempty" db:"-"`
+	ActualDepartureAt *time.Time `json:"actual_departure_at,omitempty" db:"actual_departure_at"` // Confirmed by staff
+	ActualArrivalAt   *time.Time `json:"actual_arrival_at,omitempty" db:"actual_arrival_at"`
+	DepartureGate     *string    `json:"departure_gate,omitempty" db:"departure_gate"`
+	BasePrice         money.Money `json:"base_price" db:"base_price"`
 	TotalCapacity     int        `json:"total_capacity" db:"total_capacity"`
 	AvailableSeats    int        `json:"available_seats" db:"available_seats"`
 	Status            string     `json:"status" db:"status"`
@@ -23,6 +38,10 @@ type Schedule struct {
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
 	Version           int        `json:"version" db:"version"`
+	TemplateID        *uuid.UUID `json:"template_id,omitempty" db:"template_id"`
+	TemplateVersion   *int       `json:"template_version,omitempty" db:"template_version"`
+	TimetableVersionID *uuid.UUID `json:"timetable_version_id,omitempty" db:"timetable_version_id"`
+	Unpublished       bool       `json:"unpublished,omitempty" db:"unpublished"` // Off sale: a draft, or withdrawn by a later timetable version
 	
 	// Joined fields
 	Operator *Operator `json:"operator,omitempty" db:"-"`
@@ -30,6 +49,41 @@ type Schedule struct {
 	Vessel   *Vessel   `json:"vessel,omitempty" db:"-"`
 }
 
+// ExpectedDepartureAt is when the sailing is now expected to leave: its
+// estimate while delayed, otherwise its timetabled departure
+func (s *Schedule) ExpectedDepartureAt() time.Time {
+	if s.EstimatedDepartureAt != nil {
+		return *s.EstimatedDepartureAt
+	}
+	return s.DepartureAt
+}
+
+// ExpectedArrivalAt is when the sailing is now expected to arrive
+func (s *Schedule) ExpectedArrivalAt() time.Time {
+	if s.EstimatedArrivalAt != nil {
+		return *s.EstimatedArrivalAt
+	}
+	return s.ArrivalAt
+}
+
+// VesselConflictError reports that a schedule's vessel is already allocated
+// to another sailing, turnaround included, at that time
+type VesselConflictError struct {
+	VesselID uuid.UUID
+	// Conflicting is the sailing the vessel is already on, when it could be
+	// identified
+	Conflicting *Schedule
+}
+
+func (e *VesselConflictError) Error() string {
+	if e.Conflicting == nil {
+		return "vessel is already allocated to an overlapping sailing"
+	}
+	return fmt.Sprintf("vessel is already allocated to schedule %s departing %s %s and arriving %s, turnaround included",
+		e.Conflicting.ID, e.Conflicting.DepartureDate.Format("2006-01-02"),
+		e.Conflicting.DepartureTime.Format("15:04"), e.Conflicting.ArrivalTime.Format("15:04"))
+}
+
 // Booking represents a customer booking
 type Booking struct {
 	ID                uuid.UUID  `json:"id" db:"id"`
@@ -37,12 +91,15 @@ type Booking struct {
 	ScheduleID        uuid.UUID  `json:"schedule_id" db:"schedule_id"`
 	CustomerID        uuid.UUID  `json:"customer_id" db:"customer_id"`
 	PassengerCount    int        `json:"passenger_count" db:"passenger_count"`
-	TotalAmount       float64    `json:"total_amount" db:"total_amount"`
+	TotalAmount       money.Money `json:"total_amount" db:"total_amount"`
 	BookingStatus     string     `json:"booking_status" db:"booking_status"`
 	PaymentStatus     string     `json:"payment_status" db:"payment_status"`
 	BookingChannel    string     `json:"booking_channel" db:"booking_channel"`
 	SpecialRequirements *string  `json:"special_requirements,omitempty" db:"special_requirements"`
 	BookingAgentID    *uuid.UUID `json:"booking_agent_id,omitempty" db:"booking_agent_id"`
+	ShiftID           *uuid.UUID `json:"shift_id,omitempty" db:"shift_id"`
+	AgencyID          *uuid.UUID `json:"agency_id,omitempty" db:"agency_id"`
+	CommissionAmount  money.Money `json:"commission_amount" db:"commission_amount"`
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
 	
@@ -50,7 +107,11 @@ type Booking struct {
 	Schedule *Schedule `json:"schedule,omitempty" db:"-"`
 	Customer *User     `json:"customer,omitempty" db:"-"`
 	Tickets  []Ticket  `json:"tickets,omitempty" db:"-"`
-	Payment  *Payment  `json:"payment,omitempty" db:"-"`
+	Payments []Payment `json:"payments,omitempty" db:"-"`
+
+	// Computed from the booking's payments
+	AmountPaid money.Money `json:"amount_paid" db:"-"`
+	BalanceDue money.Money `json:"balance_due" db:"-"`
 }
 
 // Ticket represents an individual passenger ticket
@@ -60,13 +121,18 @@ type Ticket struct {
 	PassengerName string     `json:"passenger_name" db:"passenger_name"`
 	PassengerType string     `json:"passenger_type" db:"passenger_type"`
 	SeatNumber    *string    `json:"seat_number,omitempty" db:"seat_number"`
-	TicketPrice   float64    `json:"ticket_price" db:"ticket_price"`
+	TicketPrice   money.Money `json:"ticket_price" db:"ticket_price"`
 	QRCode        string     `json:"qr_code" db:"qr_code"`
 	CheckInStatus string     `json:"check_in_status" db:"check_in_status"`
 	CheckInTime   *time.Time `json:"check_in_time,omitempty" db:"check_in_time"`
+	BoardingTime  *time.Time `json:"boarding_time,omitempty" db:"boarding_time"`
 	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 	
+	// Identity is written when tickets are created and read back through
+	// the manifest
+	Identity      PassengerIdentity `json:"-" db:"-"`
+	
 	// Joined fields
 	Booking *Booking `json:"booking,omitempty" db:"-"`
 }
@@ -76,33 +142,62 @@ type Payment struct {
 	ID                   uuid.UUID              `json:"id" db:"id"`
 	BookingID            uuid.UUID              `json:"booking_id" db:"booking_id"`
 	PaymentMethod        string                 `json:"payment_method" db:"payment_method"`
-	Amount               float64                `json:"amount" db:"amount"`
+	Amount               money.Money            `json:"amount" db:"amount"`
 	Currency             string                 `json:"currency" db:"currency"`
 	PaymentStatus        string                 `json:"payment_status" db:"payment_status"`
 	GatewayTransactionID *string                `json:"gateway_transaction_id,omitempty" db:"gateway_transaction_id"`
 	GatewayResponse      map[string]interface{} `json:"gateway_response,omitempty" db:"gateway_response"`
+	TenderReference      *string                `json:"tender_reference,omitempty" db:"tender_reference"`
+	RefundedAmount       money.Money            `json:"refunded_amount" db:"refunded_amount"`
+	ShiftID              *uuid.UUID             `json:"shift_id,omitempty" db:"shift_id"`
+	ReconciliationStatus string                 `json:"reconciliation_status" db:"reconciliation_status"`
+	ReconciledAt         *time.Time             `json:"reconciled_at,omitempty" db:"reconciled_at"`
 	ProcessedAt          *time.Time             `json:"processed_at,omitempty" db:"processed_at"`
 	CreatedAt            time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time              `json:"updated_at" db:"updated_at"`
 }
 
+// Refund represents a refund paid out against a payment
+type Refund struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	BookingID       uuid.UUID  `json:"booking_id" db:"booking_id"`
+	PaymentID       uuid.UUID  `json:"payment_id" db:"payment_id"`
+	RefundAmount    money.Money `json:"refund_amount" db:"refund_amount"`
+	RefundReason    string     `json:"refund_reason" db:"refund_reason"`
+	RefundStatus    string     `json:"refund_status" db:"refund_status"`
+	ProcessedBy     *uuid.UUID `json:"processed_by,omitempty" db:"processed_by"`
+	GatewayRefundID *string    `json:"gateway_refund_id,omitempty" db:"gateway_refund_id"`
+	ShiftID         *uuid.UUID `json:"shift_id,omitempty" db:"shift_id"`
+	ProcessedAt     *time.Time `json:"processed_at,omitempty" db:"processed_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ProcessRefundRequest records a pending refund as paid back
+type ProcessRefundRequest struct {
+	GatewayRefundID *string `json:"gateway_refund_id,omitempty"`
+}
+
 // CreateScheduleRequest represents schedule creation data
 type CreateScheduleRequest struct {
 	OperatorID    uuid.UUID `json:"operator_id" binding:"required"`
 	RouteID       uuid.UUID `json:"route_id" binding:"required"`
 	VesselID      uuid.UUID `json:"vessel_id" binding:"required"`
-	DepartureDate string    `json:"departure_date" binding:"required"` // Format: "2006-01-02"
-	DepartureTime string    `json:"departure_time" binding:"required"` // Format: "15:04"
-	ArrivalTime   string    `json:"arrival_time" binding:"required"`   // Format: "15:04"
-	BasePrice     float64   `json:"base_price" binding:"required,min=0"`
+	DepartureDate string    `json:"departure_date" binding:"required"` // Format: "2006-01-02", at the departure port
+	DepartureTime string    `json:"departure_time" binding:"required"` // Format: "15:04", local to the departure port
+	ArrivalTime   string    `json:"arrival_time" binding:"required"`   // Format: "15:04", local to the arrival port; may be the next day
+	BasePrice     money.Money `json:"base_price"`
+	TimetableVersionID *uuid.UUID `json:"timetable_version_id,omitempty"` // Draft timetable version to add the sailing to, off sale until published
 }
 
-// UpdateScheduleRequest represents schedule update data
+// UpdateScheduleRequest represents schedule update data. Times are local to
+// their ports, as when creating a schedule. The vessel is changed with
+// ChangeVesselRequest, which resizes the sailing and reseats its passengers.
 type UpdateScheduleRequest struct {
 	DepartureDate *string  `json:"departure_date,omitempty"`
 	DepartureTime *string  `json:"departure_time,omitempty"`
 	ArrivalTime   *string  `json:"arrival_time,omitempty"`
-	BasePrice     *float64 `json:"base_price,omitempty"`
+	BasePrice     *money.Money `json:"base_price,omitempty"`
 	Status        *string  `json:"status,omitempty"`
 }
 
@@ -116,19 +211,39 @@ type SearchScheduleRequest struct {
 	Offset          int       `json:"offset,omitempty"`
 }
 
-// CreateBookingRequest represents booking creation data
+// CreateBookingRequest represents booking creation data. The booking is paid
+// either in full with PaymentMethod or with one or more Payments (split tender).
 type CreateBookingRequest struct {
 	ScheduleID          uuid.UUID            `json:"schedule_id" binding:"required"`
 	Passengers          []PassengerInfo      `json:"passengers" binding:"required,min=1"`
-	PaymentMethod       string               `json:"payment_method" binding:"required"`
+	PaymentMethod       string               `json:"payment_method,omitempty" binding:"required_without=Payments"`
+	Payments            []PaymentTender      `json:"payments,omitempty" binding:"omitempty,dive"`
 	SpecialRequirements string               `json:"special_requirements,omitempty"`
 }
 
+// PaymentTender represents one tender of a booking payment
+type PaymentTender struct {
+	PaymentMethod string      `json:"payment_method" binding:"required,oneof=credit_card debit_card cash bank_transfer mobile_money paypal voucher"`
+	Amount        money.Money `json:"amount"`
+	Reference     string      `json:"reference,omitempty"`
+}
+
+// BookingBalance represents the payments taken against a booking
+type BookingBalance struct {
+	BookingID      uuid.UUID   `json:"booking_id"`
+	TotalAmount    money.Money `json:"total_amount"`
+	AmountPaid     money.Money `json:"amount_paid"`
+	AmountRefunded money.Money `json:"amount_refunded"`
+	BalanceDue     money.Money `json:"balance_due"`
+	Payments       []Payment   `json:"payments"`
+}
+
 // PassengerInfo represents passenger information for booking
 type PassengerInfo struct {
 	Name          string  `json:"name" binding:"required"`
 	Type          string  `json:"type" binding:"required,oneof=adult child infant senior"`
 	SeatNumber    string  `json:"seat_number,omitempty"`
+	PassengerIdentity
 }
 
 // CancelBookingRequest represents booking cancellation
@@ -141,6 +256,50 @@ type CheckInRequest struct {
 	QRCode string `json:"qr_code" binding:"required"`
 }
 
+// VerifyQRCodeRequest represents a scanned ticket QR code to verify
+type VerifyQRCodeRequest struct {
+	QRCode string `json:"qr_code" binding:"required"`
+}
+
+// QRCodeVerification represents the result of checking a ticket QR code's
+// signature and validity window. Reason is set when the code is refused:
+// malformed, unknown_key, bad_signature, not_yet_valid or expired.
+type QRCodeVerification struct {
+	Valid         bool       `json:"valid"`
+	Reason        string     `json:"reason,omitempty"`
+	Message       string     `json:"message,omitempty"`
+	KeyID         string     `json:"key_id,omitempty"`
+	TicketID      *uuid.UUID `json:"ticket_id,omitempty"`
+	ScheduleID    *uuid.UUID `json:"schedule_id,omitempty"`
+	PassengerType string     `json:"passenger_type,omitempty"`
+	SeatNumber    string     `json:"seat_number,omitempty"`
+	ValidFrom     *time.Time `json:"valid_from,omitempty"`
+	ValidUntil    *time.Time `json:"valid_until,omitempty"`
+	CheckedAt     time.Time  `json:"checked_at"`
+}
+
+// BoardingPass holds what is printed on a ticket's boarding pass. Departure
+// and Arrival are in the local time of their ports.
+type BoardingPass struct {
+	TicketID         uuid.UUID `json:"ticket_id"`
+	BookingReference string    `json:"booking_reference"`
+	OperatorName     string    `json:"operator_name"`
+	OperatorCode     string    `json:"operator_code"`
+	BrandColor       string    `json:"brand_color,omitempty"`
+	RouteName        string    `json:"route_name"`
+	DeparturePort    string    `json:"departure_port"`
+	DeparturePortCode string   `json:"departure_port_code"`
+	ArrivalPort      string    `json:"arrival_port"`
+	ArrivalPortCode  string    `json:"arrival_port_code"`
+	VesselName       string    `json:"vessel_name"`
+	Departure        time.Time `json:"departure"`
+	Arrival          time.Time `json:"arrival"`
+	PassengerName    string    `json:"passenger_name"`
+	PassengerType    string    `json:"passenger_type"`
+	SeatNumber       string    `json:"seat_number,omitempty"`
+	QRCode           string    `json:"qr_code"`
+}
+
 // BookingFilter represents filters for listing bookings
 type BookingFilter struct {
 	CustomerID     *uuid.UUID `json:"customer_id,omitempty"`
@@ -156,7 +315,7 @@ type BookingFilter struct {
 // BookingReport represents booking statistics
 type BookingReport struct {
 	TotalBookings   int     `json:"total_bookings"`
-	TotalRevenue    float64 `json:"total_revenue"`
+	TotalRevenue    money.Money `json:"total_revenue"`
 	TotalPassengers int     `json:"total_passengers"`
 	PeriodStart     time.Time `json:"period_start"`
 	PeriodEnd       time.Time `json:"period_end"`
@@ -166,21 +325,25 @@ type BookingReport struct {
 
 // RevenueReport represents revenue statistics
 type RevenueReport struct {
-	TotalRevenue     float64            `json:"total_revenue"`
-	RefundedAmount   float64            `json:"refunded_amount"`
-	NetRevenue       float64            `json:"net_revenue"`
-	PeriodStart      time.Time          `json:"period_start"`
-	PeriodEnd        time.Time          `json:"period_end"`
-	ByOperator       map[string]float64 `json:"by_operator,omitempty"`
-	ByRoute          map[string]float64 `json:"by_route,omitempty"`
-	ByPaymentMethod  map[string]float64 `json:"by_payment_method"`
+	TotalRevenue     money.Money            `json:"total_revenue"`
+	RefundedAmount   money.Money            `json:"refunded_amount"`
+	NetRevenue       money.Money            `json:"net_revenue"`
+	PeriodStart      time.Time              `json:"period_start"`
+	PeriodEnd        time.Time              `json:"period_end"`
+	ByOperator       map[string]money.Money `json:"by_operator,omitempty"`
+	ByRoute          map[string]money.Money `json:"by_route,omitempty"`
+	ByPaymentMethod  map[string]money.Money `json:"by_payment_method"`
+	RefundsByPaymentMethod map[string]money.Money `json:"refunds_by_payment_method"`
 }
 
 // Manifest represents passenger manifest for a schedule
 type Manifest struct {
 	Schedule       *Schedule `json:"schedule"`
+	Voyage         *ManifestVoyage `json:"voyage"`
 	TotalPassengers int      `json:"total_passengers"`
 	CheckedIn      int       `json:"checked_in"`
+	Boarded        int       `json:"boarded"`
+	NoShows        int       `json:"no_shows"`
 	Passengers     []ManifestEntry `json:"passengers"`
 }
 
@@ -194,4 +357,5 @@ type ManifestEntry struct {
 	CheckInStatus  string    `json:"check_in_status"`
 	CustomerEmail  string    `json:"customer_email"`
 	CustomerPhone  string    `json:"customer_phone"`
+	PassengerIdentity
 }
\ No newline at end of file
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// ScheduleCancellation is the run that cancels a sailing's bookings and
+// offers each passenger a refund, rebooking or travel credit. A run that
+// fails partway is picked up where it stopped by cancelling again.
+type ScheduleCancellation struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	ScheduleID  uuid.UUID  `json:"schedule_id" db:"schedule_id"`
+	Reason      string     `json:"reason" db:"reason"`
+	Status      string     `json:"status" db:"status"`
+	StartedBy   *uuid.UUID `json:"started_by,omitempty" db:"started_by"`
+	LastError   *string    `json:"last_error,omitempty" db:"last_error"`
+	StartedAt   time.Time  `json:"started_at" db:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CancellationOffer is the choice a passenger on a cancelled sailing is given
+// for their booking
+type CancellationOffer struct {
+	ID                 uuid.UUID   `json:"id" db:"id"`
+	CancellationID     uuid.UUID   `json:"cancellation_id" db:"cancellation_id"`
+	ScheduleID         uuid.UUID   `json:"schedule_id" db:"schedule_id"`
+	BookingID          uuid.UUID   `json:"booking_id" db:"booking_id"`
+	BookingReference   string      `json:"booking_reference" db:"booking_reference"`
+	CustomerID         uuid.UUID   `json:"customer_id" db:"customer_id"`
+	PassengerCount     int         `json:"passenger_count" db:"passenger_count"`
+	AmountPaid         money.Money `json:"amount_paid" db:"amount_paid"`
+	Alternatives       []uuid.UUID `json:"alternatives" db:"alternative_schedule_ids"` // Sailings suggested for rebooking
+	Status             string      `json:"status" db:"status"`
+	RebookedScheduleID *uuid.UUID  `json:"rebooked_schedule_id,omitempty" db:"rebooked_schedule_id"`
+	RespondBy          time.Time   `json:"respond_by" db:"respond_by"` // Lapsed offers are refunded
+	ChosenBy           *uuid.UUID  `json:"chosen_by,omitempty" db:"chosen_by"`
+	ChosenAt           *time.Time  `json:"chosen_at,omitempty" db:"chosen_at"`
+	CreatedAt          time.Time   `json:"created_at" db:"created_at"`
+
+	// Credits issued when the passenger chose travel credit
+	Credits []*TravelCredit `json:"credits,omitempty" db:"-"`
+}
+
+// ScheduleCancellationReport summarizes a cancellation run and what
+// passengers chose
+type ScheduleCancellationReport struct {
+	Cancellation   *ScheduleCancellation `json:"cancellation"`
+	Bookings       int                   `json:"bookings"`
+	Passengers     int                   `json:"passengers"`
+	Pending        int                   `json:"pending"`
+	Refunded       int                   `json:"refunded"`
+	Rebooked       int                   `json:"rebooked"`
+	Credited       int                   `json:"credited"`
+	AmountPaid     money.Money           `json:"amount_paid"`
+	RefundedAmount money.Money           `json:"refunded_amount"`
+	CreditedAmount money.Money           `json:"credited_amount"`
+	RebookedAmount money.Money           `json:"rebooked_amount"`
+	Offers         []*CancellationOffer  `json:"offers"`
+}
+
+// CancelScheduleRequest represents a schedule cancellation
+type CancelScheduleRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ChooseCancellationOptionRequest represents a passenger's choice on a
+// cancelled sailing
+type ChooseCancellationOptionRequest struct {
+	Option     string     `json:"option" binding:"required,oneof=refund rebook credit"`
+	ScheduleID *uuid.UUID `json:"schedule_id,omitempty"` // Sailing to rebook onto
+}
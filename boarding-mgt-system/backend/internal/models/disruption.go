@@ -0,0 +1,159 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScheduleDisruption is a delay, gate change or vessel substitution recorded
+// against a sailing.
+// Disruptions are never changed or removed, so on-time performance can be
+// reported from them.
+type ScheduleDisruption struct {
+	ID                   uuid.UUID  `json:"id" db:"id"`
+	ScheduleID           uuid.UUID  `json:"schedule_id" db:"schedule_id"`
+	Kind                 string     `json:"kind" db:"kind"`
+	ReasonCode           string     `json:"reason_code" db:"reason_code"`
+	Description          *string    `json:"description,omitempty" db:"description"`
+	ScheduledDepartureAt time.Time  `json:"scheduled_departure_at" db:"scheduled_departure_at"`
+	ScheduledArrivalAt   time.Time  `json:"scheduled_arrival_at" db:"scheduled_arrival_at"`
+	EstimatedDepartureAt *time.Time `json:"estimated_departure_at,omitempty" db:"estimated_departure_at"`
+	EstimatedArrivalAt   *time.Time `json:"estimated_arrival_at,omitempty" db:"estimated_arrival_at"`
+	DelayMinutes         int        `json:"delay_minutes" db:"delay_minutes"`
+	PreviousGate         *string    `json:"previous_gate,omitempty" db:"previous_gate"`
+	Gate                 *string    `json:"gate,omitempty" db:"gate"`
+	PreviousVesselID     *uuid.UUID `json:"previous_vessel_id,omitempty" db:"previous_vessel_id"`
+	VesselID             *uuid.UUID `json:"vessel_id,omitempty" db:"vessel_id"`
+	PreviousCapacity     *int       `json:"previous_capacity,omitempty" db:"previous_capacity"`
+	Capacity             *int       `json:"capacity,omitempty" db:"capacity"`
+	CapacityOverride     bool       `json:"capacity_override" db:"capacity_override"` // Passengers booked exceeded the new vessel's seats
+	RecordedBy           *uuid.UUID `json:"recorded_by,omitempty" db:"recorded_by"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+
+	// Filled in when the disruption is recorded
+	Notified        int `json:"notified,omitempty" db:"-"`
+	TicketsReissued int `json:"tickets_reissued,omitempty" db:"-"`
+}
+
+// CreateDisruptionRequest records a delay or gate change. A delay needs the
+// new departure; without a new arrival the sailing keeps its duration.
+type CreateDisruptionRequest struct {
+	Kind                 string     `json:"kind" binding:"required,oneof=delay gate_change"`
+	ReasonCode           string     `json:"reason_code" binding:"required"`
+	Description          *string    `json:"description,omitempty" binding:"omitempty,max=1000"`
+	EstimatedDepartureAt *time.Time `json:"estimated_departure_at,omitempty"`
+	EstimatedArrivalAt   *time.Time `json:"estimated_arrival_at,omitempty"`
+	Gate                 *string    `json:"gate,omitempty" binding:"omitempty,max=20"`
+}
+
+// ChangeVesselRequest substitutes another of the operator's vessels on a
+// sailing. A vessel with fewer seats than passengers booked is only taken
+// with OverrideCapacity.
+type ChangeVesselRequest struct {
+	VesselID         uuid.UUID `json:"vessel_id" binding:"required"`
+	ReasonCode       string    `json:"reason_code" binding:"required"`
+	Description      *string   `json:"description,omitempty" binding:"omitempty,max=1000"`
+	OverrideCapacity bool      `json:"override_capacity"`
+}
+
+// VesselChangeResult reports a vessel substitution and the passengers whose
+// seats changed
+type VesselChangeResult struct {
+	Disruption      *ScheduleDisruption `json:"disruption"`
+	Schedule        *Schedule           `json:"schedule"`
+	Passengers      int                 `json:"passengers"`
+	Oversold        int                 `json:"oversold"` // Passengers beyond the new vessel's seats
+	SeatChanges     []SeatChange        `json:"seat_changes"`
+	TicketsReissued int                 `json:"tickets_reissued"`
+}
+
+// MoveBookingsRequest moves confirmed bookings from a sailing to another
+// sailing of the same route. Without booking IDs every confirmed booking is
+// moved while there is room.
+type MoveBookingsRequest struct {
+	TargetScheduleID uuid.UUID   `json:"target_schedule_id" binding:"required"`
+	BookingIDs       []uuid.UUID `json:"booking_ids,omitempty"`
+	DisruptionID     *uuid.UUID  `json:"disruption_id,omitempty"`
+}
+
+// BookingMove records a booking moved from one sailing to another
+type BookingMove struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	BookingID      uuid.UUID  `json:"booking_id" db:"booking_id"`
+	FromScheduleID uuid.UUID  `json:"from_schedule_id" db:"from_schedule_id"`
+	ToScheduleID   uuid.UUID  `json:"to_schedule_id" db:"to_schedule_id"`
+	DisruptionID   *uuid.UUID `json:"disruption_id,omitempty" db:"disruption_id"`
+	PassengerCount int        `json:"passenger_count" db:"passenger_count"`
+	SeatsChanged   int        `json:"seats_changed" db:"seats_changed"`
+	MovedBy        *uuid.UUID `json:"moved_by,omitempty" db:"moved_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+
+	// Passengers who could not keep their seat number
+	SeatChanges []SeatChange `json:"seat_changes,omitempty" db:"-"`
+}
+
+// SeatChange is a passenger whose seat number changed, or who lost their
+// seat, when their sailing changed
+type SeatChange struct {
+	TicketID         uuid.UUID `json:"ticket_id"`
+	BookingReference string    `json:"booking_reference,omitempty"`
+	PassengerName    string    `json:"passenger_name"`
+	From             *string   `json:"from,omitempty"`
+	To               *string   `json:"to,omitempty"` // Unset when no seat could be given
+}
+
+// BookingNotMoved is a booking a bulk move left where it was, and why
+type BookingNotMoved struct {
+	BookingID uuid.UUID `json:"booking_id"`
+	Reason    string    `json:"reason"`
+}
+
+// MoveBookingsResult reports which bookings a bulk move moved
+type MoveBookingsResult struct {
+	FromScheduleID uuid.UUID         `json:"from_schedule_id"`
+	ToScheduleID   uuid.UUID         `json:"to_schedule_id"`
+	Moved          []*BookingMove    `json:"moved"`
+	NotMoved       []BookingNotMoved `json:"not_moved"`
+	Passengers     int               `json:"passengers"`
+}
+
+// OnTimeSailing is a sailing's outcome as on-time performance sees it
+type OnTimeSailing struct {
+	RouteID             uuid.UUID
+	RouteName           string
+	DepartureAt         time.Time
+	ExpectedDepartureAt time.Time
+	Cancelled           bool
+	ReasonCode          string // Of the latest delay recorded, if any
+}
+
+// OnTimeRate is the on-time performance of a route or of the sailings
+// delayed for one reason
+type OnTimeRate struct {
+	Key                 string  `json:"key"`
+	Name                string  `json:"name"`
+	Sailings            int     `json:"sailings"`
+	OnTime              int     `json:"on_time"`
+	Delayed             int     `json:"delayed"`
+	Cancelled           int     `json:"cancelled"`
+	Rate                float64 `json:"rate"`
+	AverageDelayMinutes float64 `json:"average_delay_minutes"`
+}
+
+// OnTimeReport represents the on-time performance of sailings departing in
+// a period. Sailings leaving within the threshold of their timetabled
+// departure are on time; the rate is their share of sailings that ran.
+type OnTimeReport struct {
+	PeriodStart         time.Time    `json:"period_start"`
+	PeriodEnd           time.Time    `json:"period_end"`
+	ThresholdMinutes    int          `json:"threshold_minutes"`
+	Sailings            int          `json:"sailings"`
+	OnTime              int          `json:"on_time"`
+	Delayed             int          `json:"delayed"`
+	Cancelled           int          `json:"cancelled"`
+	Rate                float64      `json:"rate"`
+	AverageDelayMinutes float64      `json:"average_delay_minutes"`
+	ByRoute             []OnTimeRate `json:"by_route"`
+	ByReason            []OnTimeRate `json:"by_reason"`
+}
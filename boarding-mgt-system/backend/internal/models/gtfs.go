@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// GTFSExport is an operator's timetable as a zipped GTFS feed
+type GTFSExport struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// GTFSImportRequest seeds an operator's routes and schedules from a GTFS
+// feed. Sailings are given the vessel of their trip's block or route in
+// Vessels, or VesselID, and the base price. Only sailings between From and
+// Until are imported; a dry run reports what would be created.
+type GTFSImportRequest struct {
+	OperatorID uuid.UUID
+	Feed       []byte
+	VesselID   uuid.UUID
+	Vessels    map[string]uuid.UUID // By GTFS block_id or route_id
+	BasePrice  money.Money
+	From       time.Time
+	Until      time.Time
+	DryRun     bool
+}
+
+// GTFSSailing is a schedule imported from a sailing of a GTFS trip
+type GTFSSailing struct {
+	TripID   string    `json:"trip_id"`
+	Schedule *Schedule `json:"schedule"`
+}
+
+// GTFSSkippedTrip is a trip of a GTFS feed that was not imported
+type GTFSSkippedTrip struct {
+	TripID string `json:"trip_id"`
+	Reason string `json:"reason"`
+}
+
+// GTFSImport reports the routes and schedules created from a GTFS feed.
+// Sailings imported before are counted as existing and left alone.
+type GTFSImport struct {
+	DryRun        bool              `json:"dry_run"`
+	From          time.Time         `json:"from"`
+	Until         time.Time         `json:"until"`
+	RoutesCreated []*Route          `json:"routes_created"`
+	Created       []GTFSSailing     `json:"created"`
+	Existing      int               `json:"existing"`
+	Skipped       []GTFSSkippedTrip `json:"skipped"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// InvoiceSeller is the operator's legal details as printed on an invoice
+type InvoiceSeller struct {
+	LegalName   string  `json:"legal_name"`
+	TradingName string  `json:"trading_name"`
+	TaxID       *string `json:"tax_id,omitempty"`
+	Address     *string `json:"address,omitempty"`
+	Email       string  `json:"email"`
+	Phone       *string `json:"phone,omitempty"`
+}
+
+// InvoiceBuyer is the customer's details as printed on an invoice
+type InvoiceBuyer struct {
+	Name  string  `json:"name"`
+	Email string  `json:"email"`
+	Phone *string `json:"phone,omitempty"`
+}
+
+// Invoice represents an issued invoice or credit note. Seller, buyer, lines
+// and taxes are snapshots taken when the document was issued.
+type Invoice struct {
+	ID                uuid.UUID     `json:"id" db:"id"`
+	OperatorID        uuid.UUID     `json:"operator_id" db:"operator_id"`
+	BookingID         uuid.UUID     `json:"booking_id" db:"booking_id"`
+	CustomerID        uuid.UUID     `json:"customer_id" db:"customer_id"`
+	DocumentType      string        `json:"document_type" db:"document_type"`
+	FiscalYear        int           `json:"fiscal_year" db:"fiscal_year"`
+	SequenceNumber    int           `json:"sequence_number" db:"sequence_number"`
+	InvoiceNumber     string        `json:"invoice_number" db:"invoice_number"`
+	OriginalInvoiceID *uuid.UUID    `json:"original_invoice_id,omitempty" db:"original_invoice_id"`
+	SourceID          uuid.UUID     `json:"source_id" db:"source_id"`
+	IssueDate         time.Time     `json:"issue_date" db:"issue_date"`
+	Currency          string        `json:"currency" db:"currency"`
+	Seller            InvoiceSeller `json:"seller" db:"seller"`
+	Buyer             InvoiceBuyer  `json:"buyer" db:"buyer"`
+	Subtotal          money.Money   `json:"subtotal" db:"subtotal"`
+	TaxTotal          money.Money   `json:"tax_total" db:"tax_total"`
+	Total             money.Money   `json:"total" db:"total"`
+	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+
+	Lines []InvoiceLine `json:"lines" db:"-"`
+
+	// Set by the issuer; the repository appends the fiscal year and sequence
+	NumberPrefix string `json:"-" db:"-"`
+	// Number of the invoice a credit note corrects
+	OriginalInvoiceNumber *string `json:"original_invoice_number,omitempty" db:"-"`
+}
+
+// InvoiceLine represents a priced line of an invoice. Unit prices include tax.
+type InvoiceLine struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	InvoiceID   uuid.UUID   `json:"invoice_id" db:"invoice_id"`
+	LineNumber  int         `json:"line_number" db:"line_number"`
+	Description string      `json:"description" db:"description"`
+	Quantity    int         `json:"quantity" db:"quantity"`
+	UnitPrice   money.Money `json:"unit_price" db:"unit_price"`
+	TaxRate     float64     `json:"tax_rate" db:"tax_rate"`
+	NetAmount   money.Money `json:"net_amount" db:"net_amount"`
+	TaxAmount   money.Money `json:"tax_amount" db:"tax_amount"`
+	TotalAmount money.Money `json:"total_amount" db:"total_amount"`
+}
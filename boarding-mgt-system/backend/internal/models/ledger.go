@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// LedgerAccount represents an account in an operator's chart of accounts
+type LedgerAccount struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	OperatorID  uuid.UUID `json:"operator_id" db:"operator_id"`
+	Code        string    `json:"code" db:"code"`
+	Name        string    `json:"name" db:"name"`
+	AccountType string    `json:"account_type" db:"account_type"`
+	SystemKey   *string   `json:"system_key,omitempty" db:"system_key"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// JournalEntry represents a balanced, append-only double-entry posting
+type JournalEntry struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	EntryNumber int64      `json:"entry_number" db:"entry_number"`
+	OperatorID  uuid.UUID  `json:"operator_id" db:"operator_id"`
+	EntryDate   time.Time  `json:"entry_date" db:"entry_date"`
+	EventType   string     `json:"event_type" db:"event_type"`
+	SourceID    uuid.UUID  `json:"source_id" db:"source_id"`
+	BookingID   *uuid.UUID `json:"booking_id,omitempty" db:"booking_id"`
+	ScheduleID  *uuid.UUID `json:"schedule_id,omitempty" db:"schedule_id"`
+	Description string     `json:"description" db:"description"`
+	Currency    string     `json:"currency" db:"currency"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+
+	Lines []JournalLine `json:"lines" db:"-"`
+}
+
+// JournalLine represents a single debit or credit within a journal entry
+type JournalLine struct {
+	ID         uuid.UUID   `json:"id" db:"id"`
+	EntryID    uuid.UUID   `json:"entry_id" db:"entry_id"`
+	LineNumber int         `json:"line_number" db:"line_number"`
+	AccountID  uuid.UUID   `json:"account_id" db:"account_id"`
+	BookingID  *uuid.UUID  `json:"booking_id,omitempty" db:"booking_id"`
+	Debit      money.Money `json:"debit" db:"debit"`
+	Credit     money.Money `json:"credit" db:"credit"`
+	Memo       *string     `json:"memo,omitempty" db:"memo"`
+
+	// Resolved from the account when posting or reading
+	AccountKey  string `json:"account_key,omitempty" db:"-"`
+	AccountCode string `json:"account_code,omitempty" db:"-"`
+	AccountName string `json:"account_name,omitempty" db:"-"`
+	AccountType string `json:"account_type,omitempty" db:"-"`
+}
+
+// TrialBalanceLine represents an account's debit and credit totals
+type TrialBalanceLine struct {
+	AccountID   uuid.UUID   `json:"account_id"`
+	Code        string      `json:"code"`
+	Name        string      `json:"name"`
+	AccountType string      `json:"account_type"`
+	Debit       money.Money `json:"debit"`
+	Credit      money.Money `json:"credit"`
+	Balance     money.Money `json:"balance"`
+}
+
+// TrialBalance represents account balances for an operator as of a date
+type TrialBalance struct {
+	OperatorID  uuid.UUID           `json:"operator_id"`
+	AsOf        time.Time           `json:"as_of"`
+	Accounts    []*TrialBalanceLine `json:"accounts"`
+	TotalDebit  money.Money         `json:"total_debit"`
+	TotalCredit money.Money         `json:"total_credit"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LifecycleRun reports one pass of the schedule lifecycle over the sailings
+// due to open boarding or depart
+type LifecycleRun struct {
+	At             time.Time          `json:"at"`
+	BoardingOpened int                `json:"boarding_opened"`
+	Departed       int                `json:"departed"`
+	Failures       []LifecycleFailure `json:"failures"`
+}
+
+// LifecycleFailure is a sailing the lifecycle could not move along, such as
+// one whose manifest blocks departure
+type LifecycleFailure struct {
+	ScheduleID uuid.UUID `json:"schedule_id"`
+	Status     string    `json:"status"` // The status it was due to move to
+	Error      string    `json:"error"`
+}
+
+// ConfirmDepartureRequest confirms a sailing has left. Without a time it
+// left now; a sailing already departed has its departure time corrected.
+type ConfirmDepartureRequest struct {
+	DepartedAt *time.Time `json:"departed_at,omitempty"`
+}
+
+// ConfirmArrivalRequest confirms a sailing has arrived, now unless a time
+// is given
+type ConfirmArrivalRequest struct {
+	ArrivedAt *time.Time `json:"arrived_at,omitempty"`
+}
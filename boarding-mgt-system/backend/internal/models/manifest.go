@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PassengerIdentity holds the travel document details port authorities
+// require on passenger lists. Countries are ISO 3166-1 alpha-2 codes and
+// dates are YYYY-MM-DD.
+type PassengerIdentity struct {
+	Nationality            string `json:"nationality,omitempty" binding:"omitempty,iso3166_1_alpha2"`
+	DateOfBirth            string `json:"date_of_birth,omitempty" binding:"omitempty,datetime=2006-01-02"`
+	Sex                    string `json:"sex,omitempty" binding:"omitempty,oneof=F M X"`
+	DocumentType           string `json:"document_type,omitempty" binding:"omitempty,oneof=passport id_card residence_permit other"`
+	DocumentNumber         string `json:"document_number,omitempty" binding:"omitempty,max=50"`
+	DocumentIssuingCountry string `json:"document_issuing_country,omitempty" binding:"omitempty,iso3166_1_alpha2"`
+	DocumentExpiry         string `json:"document_expiry,omitempty" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// ManifestVoyage describes the sailing a manifest is for, as the heading of
+// a port authority passenger list
+type ManifestVoyage struct {
+	OperatorName       string    `json:"operator_name"`
+	VesselName         string    `json:"vessel_name"`
+	VesselRegistration string    `json:"vessel_registration"`
+	DeparturePort      string    `json:"departure_port"`
+	DeparturePortCode  string    `json:"departure_port_code"`
+	DepartureCountry   string    `json:"departure_country"`
+	ArrivalPort        string    `json:"arrival_port"`
+	ArrivalPortCode    string    `json:"arrival_port_code"`
+	ArrivalCountry     string    `json:"arrival_country"`
+	Departure          time.Time `json:"departure"`
+}
+
+// ManifestCompleteness lists the passengers of a sailing missing mandatory
+// identity details
+type ManifestCompleteness struct {
+	ScheduleID      uuid.UUID     `json:"schedule_id"`
+	RequiredFields  []string      `json:"required_fields"`
+	Complete        bool          `json:"complete"`
+	BlocksDeparture bool          `json:"blocks_departure"`
+	Incomplete      []ManifestGap `json:"incomplete"`
+}
+
+// ManifestGap is a passenger with missing identity details
+type ManifestGap struct {
+	TicketID      uuid.UUID `json:"ticket_id"`
+	PassengerName string    `json:"passenger_name"`
+	BookingRef    string    `json:"booking_ref"`
+	Missing       []string  `json:"missing"`
+}
+
+// ManifestExport is a manifest rendered in a port authority format
+type ManifestExport struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
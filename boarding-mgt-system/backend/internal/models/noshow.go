@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// DepartureSummary holds a sailing's final passenger counts, recorded once
+// when it departs and its unboarded tickets are marked as no-shows
+type DepartureSummary struct {
+	ID              uuid.UUID   `json:"id" db:"id"`
+	ScheduleID      uuid.UUID   `json:"schedule_id" db:"schedule_id"`
+	TotalPassengers int         `json:"total_passengers" db:"total_passengers"`
+	CheckedIn       int         `json:"checked_in" db:"checked_in"`
+	Boarded         int         `json:"boarded" db:"boarded"`
+	NoShows         int         `json:"no_shows" db:"no_shows"`
+	NoShowPolicy    string      `json:"no_show_policy" db:"no_show_policy"`
+	ForfeitedAmount money.Money `json:"forfeited_amount" db:"forfeited_amount"`
+	CreditedAmount  money.Money `json:"credited_amount" db:"credited_amount"`
+	ProcessedAt     time.Time   `json:"processed_at" db:"processed_at"`
+
+	// Credits issued to no-show passengers by this run
+	Credits []*TravelCredit `json:"credits,omitempty" db:"-"`
+}
+
+// TravelCredit is fare value a customer can put towards a later booking
+type TravelCredit struct {
+	ID         uuid.UUID   `json:"id" db:"id"`
+	OperatorID uuid.UUID   `json:"operator_id" db:"operator_id"`
+	CustomerID uuid.UUID   `json:"customer_id" db:"customer_id"`
+	BookingID  uuid.UUID   `json:"booking_id" db:"booking_id"`
+	TicketID   uuid.UUID   `json:"ticket_id" db:"ticket_id"`
+	Code       string      `json:"code" db:"code"`
+	Amount     money.Money `json:"amount" db:"amount"`
+	Reason     string      `json:"reason" db:"reason"`
+	Status     string      `json:"status" db:"status"`
+	ExpiresAt  time.Time   `json:"expires_at" db:"expires_at"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
+
+// NoShowCount is the number of passengers and no-shows on departed sailings
+// of one route booked through one channel
+type NoShowCount struct {
+	RouteID        uuid.UUID
+	RouteName      string
+	BookingChannel string
+	Passengers     int
+	NoShows        int
+}
+
+// NoShowRate is the no-show rate of a route or booking channel
+type NoShowRate struct {
+	Key        string  `json:"key"`
+	Name       string  `json:"name"`
+	Passengers int     `json:"passengers"`
+	NoShows    int     `json:"no_shows"`
+	Rate       float64 `json:"rate"`
+}
+
+// NoShowReport represents no-show rates of departed sailings
+type NoShowReport struct {
+	PeriodStart time.Time    `json:"period_start"`
+	PeriodEnd   time.Time    `json:"period_end"`
+	Passengers  int          `json:"passengers"`
+	NoShows     int          `json:"no_shows"`
+	Rate        float64      `json:"rate"`
+	ByRoute     []NoShowRate `json:"by_route"`
+	ByChannel   []NoShowRate `json:"by_channel"`
+}
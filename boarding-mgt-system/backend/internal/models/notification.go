@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is a message to a user shown in their notification inbox
+type Notification struct {
+	ID        uuid.UUID              `json:"id" db:"id"`
+	UserID    uuid.UUID              `json:"user_id" db:"user_id"`
+	Kind      string                 `json:"kind" db:"kind"`
+	Subject   string                 `json:"subject" db:"subject"`
+	Body      string                 `json:"body" db:"body"`
+	Data      map[string]interface{} `json:"data,omitempty" db:"data"`
+	DedupeKey string                 `json:"-" db:"dedupe_key"` // Sending twice with the same key sends once
+	ReadAt    *time.Time             `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+}
@@ -14,6 +14,9 @@ type Operator struct {
 	ContactEmail string            `json:"contact_email" db:"contact_email"`
 	ContactPhone *string           `json:"contact_phone,omitempty" db:"contact_phone"`
 	Address      *string           `json:"address,omitempty" db:"address"`
+	LegalName    *string           `json:"legal_name,omitempty" db:"legal_name"`
+	TaxID        *string           `json:"tax_id,omitempty" db:"tax_id"`
+	FiscalYearStartMonth int       `json:"fiscal_year_start_month" db:"fiscal_year_start_month"`
 	IsActive     bool              `json:"is_active" db:"is_active"`
 	Settings     map[string]interface{} `json:"settings" db:"settings"`
 	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
@@ -52,6 +55,8 @@ type Vessel struct {
 	DeckCount        int                    `json:"deck_count" db:"deck_count"`
 	SeatConfiguration map[string]interface{} `json:"seat_configuration" db:"seat_configuration"`
 	Amenities        map[string]interface{} `json:"amenities" db:"amenities"`
+	TurnaroundMinutes int                   `json:"turnaround_minutes" db:"turnaround_minutes"`
+	WeatherLimits    WeatherLimits          `json:"weather_limits" db:"weather_limits"`
 	IsActive         bool                   `json:"is_active" db:"is_active"`
 	CreatedAt        time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time              `json:"updated_at" db:"updated_at"`
@@ -86,6 +91,9 @@ type CreateOperatorRequest struct {
 	ContactEmail string                 `json:"contact_email" binding:"required,email"`
 	ContactPhone string                 `json:"contact_phone,omitempty"`
 	Address      string                 `json:"address,omitempty"`
+	LegalName    string                 `json:"legal_name,omitempty"`
+	TaxID        string                 `json:"tax_id,omitempty"`
+	FiscalYearStartMonth int            `json:"fiscal_year_start_month,omitempty" binding:"omitempty,min=1,max=12"`
 	Settings     map[string]interface{} `json:"settings,omitempty"`
 }
 
@@ -95,6 +103,9 @@ type UpdateOperatorRequest struct {
 	ContactEmail *string                `json:"contact_email,omitempty"`
 	ContactPhone *string                `json:"contact_phone,omitempty"`
 	Address      *string                `json:"address,omitempty"`
+	LegalName    *string                `json:"legal_name,omitempty"`
+	TaxID        *string                `json:"tax_id,omitempty"`
+	FiscalYearStartMonth *int           `json:"fiscal_year_start_month,omitempty" binding:"omitempty,min=1,max=12"`
 	IsActive     *bool                  `json:"is_active,omitempty"`
 	Settings     map[string]interface{} `json:"settings,omitempty"`
 }
@@ -131,6 +142,8 @@ type CreateVesselRequest struct {
 	DeckCount          int                    `json:"deck_count" binding:"required,min=1"`
 	SeatConfiguration  map[string]interface{} `json:"seat_configuration" binding:"required"`
 	Amenities          map[string]interface{} `json:"amenities,omitempty"`
+	TurnaroundMinutes  int                    `json:"turnaround_minutes" binding:"min=0"` // Minutes at port between an arrival and the next departure
+	WeatherLimits      *WeatherLimits         `json:"weather_limits,omitempty"`
 }
 
 // UpdateVesselRequest represents vessel update data
@@ -141,6 +154,8 @@ type UpdateVesselRequest struct {
 	DeckCount         *int                   `json:"deck_count,omitempty"`
 	SeatConfiguration map[string]interface{} `json:"seat_configuration,omitempty"`
 	Amenities         map[string]interface{} `json:"amenities,omitempty"`
+	TurnaroundMinutes *int                   `json:"turnaround_minutes,omitempty" binding:"omitempty,min=0"`
+	WeatherLimits     *WeatherLimits         `json:"weather_limits,omitempty"` // Replaces all of the vessel's limits
 	IsActive          *bool                  `json:"is_active,omitempty"`
 }
 
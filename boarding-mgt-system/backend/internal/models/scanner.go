@@ -0,0 +1,141 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScannerDevice represents a gate or onboard scanner registered to an operator
+type ScannerDevice struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	OperatorID   uuid.UUID  `json:"operator_id" db:"operator_id"`
+	Name         string     `json:"name" db:"name"`
+	DeviceType   string     `json:"device_type" db:"device_type"`
+	IsActive     bool       `json:"is_active" db:"is_active"`
+	RegisteredBy *uuid.UUID `json:"registered_by,omitempty" db:"registered_by"`
+	LastSyncAt   *time.Time `json:"last_sync_at,omitempty" db:"last_sync_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	ScheduleIDs []uuid.UUID `json:"schedule_ids,omitempty" db:"-"`
+}
+
+// RegisteredDevice is returned when a device is registered or its key is
+// rotated. The key is only ever shown once.
+type RegisteredDevice struct {
+	Device    *ScannerDevice `json:"device"`
+	DeviceKey string         `json:"device_key"`
+}
+
+// RegisterDeviceRequest represents scanner device registration data
+type RegisterDeviceRequest struct {
+	Name       string `json:"name" binding:"required,max=100"`
+	DeviceType string `json:"device_type" binding:"required,oneof=gate onboard"`
+}
+
+// UpdateDeviceRequest represents scanner device update data
+type UpdateDeviceRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,max=100"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// AssignSchedulesRequest assigns schedules to a scanner device
+type AssignSchedulesRequest struct {
+	ScheduleIDs []uuid.UUID `json:"schedule_ids" binding:"required,min=1"`
+}
+
+// SignedBundle is a manifest bundle as downloaded by a scanner. Payload is the
+// base64url-encoded JSON of a ScannerBundle and Signature an Ed25519
+// signature over it by the ticket signing key KeyID.
+type SignedBundle struct {
+	KeyID     string `json:"key_id"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// ScannerBundle is everything a scanner needs to validate and record scans
+// for its schedules while offline
+type ScannerBundle struct {
+	DeviceID         uuid.UUID         `json:"device_id"`
+	OperatorID       uuid.UUID         `json:"operator_id"`
+	GeneratedAt      time.Time         `json:"generated_at"`
+	ExpiresAt        time.Time         `json:"expires_at"`
+	CheckInCloses    int               `json:"check_in_closes_minutes"`
+	BoardingCloses   int               `json:"boarding_closes_minutes"`
+	VerificationKeys []BundleKey       `json:"verification_keys"`
+	Schedules        []*BundleSchedule `json:"schedules"`
+}
+
+// BundleKey is a public key ticket codes may be signed with
+type BundleKey struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+}
+
+// BundleSchedule is a sailing in a scanner bundle with its passengers
+type BundleSchedule struct {
+	ScheduleID uuid.UUID          `json:"schedule_id"`
+	Status     string             `json:"status"`
+	RouteName  string             `json:"route_name"`
+	VesselName string             `json:"vessel_name"`
+	Departure  time.Time          `json:"departure"` // Expected departure, after any delay
+	Passengers []*BundlePassenger `json:"passengers"`
+}
+
+// BundlePassenger is a ticket in a scanner bundle, with the state a scanner
+// needs to refuse it locally
+type BundlePassenger struct {
+	TicketID         uuid.UUID `json:"ticket_id"`
+	BookingReference string    `json:"booking_reference"`
+	PassengerName    string    `json:"passenger_name"`
+	PassengerType    string    `json:"passenger_type"`
+	SeatNumber       *string   `json:"seat_number,omitempty"`
+	BookingStatus    string    `json:"booking_status"`
+	PaymentStatus    string    `json:"payment_status"`
+	CheckInStatus    string    `json:"check_in_status"`
+}
+
+// OfflineScanEvent is a scan a device recorded locally. EventID is generated
+// on the device and makes uploads idempotent.
+type OfflineScanEvent struct {
+	EventID    uuid.UUID  `json:"event_id" binding:"required"`
+	TicketID   uuid.UUID  `json:"ticket_id" binding:"required"`
+	ScheduleID uuid.UUID  `json:"schedule_id" binding:"required"`
+	Action     string     `json:"action" binding:"required,oneof=check_in board"`
+	Result     string     `json:"result" binding:"required,oneof=accepted denied"`
+	DenyReason string     `json:"deny_reason,omitempty"`
+	Gate       string     `json:"gate" binding:"required,max=50"`
+	ScannedAt  time.Time  `json:"scanned_at" binding:"required"`
+	ScannedBy  *uuid.UUID `json:"scanned_by,omitempty"`
+}
+
+// ScanUploadRequest is a batch of offline scan events
+type ScanUploadRequest struct {
+	Events []OfflineScanEvent `json:"events" binding:"required,min=1,max=500,dive"`
+}
+
+// ScanSyncResult reports how the server merged one uploaded event. Status is
+// applied, duplicate or rejected; Conflict is set when the event disagreed
+// with what the server already knew.
+type ScanSyncResult struct {
+	EventID        uuid.UUID     `json:"event_id"`
+	Status         string        `json:"status"`
+	Reason         string        `json:"reason,omitempty"`
+	Conflict       string        `json:"conflict,omitempty"`
+	ConflictDetail string        `json:"conflict_detail,omitempty"`
+	CheckInStatus  string        `json:"check_in_status,omitempty"`
+	Scan           *BoardingScan `json:"scan,omitempty"`
+}
+
+// ScanUploadResponse reports the outcome of every event in an upload
+type ScanUploadResponse struct {
+	ServerTime time.Time         `json:"server_time"`
+	Applied    int               `json:"applied"`
+	Duplicates int               `json:"duplicates"`
+	Rejected   int               `json:"rejected"`
+	Conflicts  int               `json:"conflicts"`
+	Results    []*ScanSyncResult `json:"results"`
+}
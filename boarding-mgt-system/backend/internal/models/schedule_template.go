@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// ScheduleTemplate is a recurring timetable, such as daily 07:00 and 15:00
+// sailings Monday to Saturday from June to September, that concrete
+// schedules are generated from
+type ScheduleTemplate struct {
+	ID             uuid.UUID           `json:"id" db:"id"`
+	OperatorID     uuid.UUID           `json:"operator_id" db:"operator_id"`
+	RouteID        uuid.UUID           `json:"route_id" db:"route_id"`
+	VesselID       uuid.UUID           `json:"vessel_id" db:"vessel_id"`
+	Name           string              `json:"name" db:"name"`
+	BasePrice      money.Money         `json:"base_price" db:"base_price"`
+	ValidFrom      time.Time           `json:"valid_from" db:"valid_from"`
+	ValidUntil     *time.Time          `json:"valid_until,omitempty" db:"valid_until"`
+	Weekdays       []int               `json:"weekdays" db:"weekdays"`
+	Departures     []TemplateDeparture `json:"departures" db:"departures"`
+	ExceptionDates []time.Time         `json:"exception_dates" db:"exception_dates"`
+	SkipHolidays   bool                `json:"skip_holidays" db:"skip_holidays"`
+	IsActive       bool                `json:"is_active" db:"is_active"`
+	GeneratedUntil *time.Time          `json:"generated_until,omitempty" db:"generated_until"`
+	Version        int                 `json:"version" db:"version"`
+	CreatedAt      time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// TemplateDeparture is one daily departure of a template, as "15:04" times
+type TemplateDeparture struct {
+	DepartureTime string `json:"departure_time" binding:"required"`
+	ArrivalTime   string `json:"arrival_time" binding:"required"`
+}
+
+// CreateScheduleTemplateRequest represents schedule template creation data.
+// Weekdays are ISO days of the week (1 is Monday); none means every day. The
+// operator is the one the request acts for.
+type CreateScheduleTemplateRequest struct {
+	OperatorID     uuid.UUID           `json:"-"`
+	RouteID        uuid.UUID           `json:"route_id" binding:"required"`
+	VesselID       uuid.UUID           `json:"vessel_id" binding:"required"`
+	Name           string              `json:"name" binding:"required,max=100"`
+	BasePrice      money.Money         `json:"base_price"`
+	ValidFrom      string              `json:"valid_from" binding:"required"` // Format: "2006-01-02"
+	ValidUntil     *string             `json:"valid_until,omitempty"`         // Format: "2006-01-02"
+	Weekdays       []int               `json:"weekdays" binding:"dive,min=1,max=7"`
+	Departures     []TemplateDeparture `json:"departures" binding:"required,min=1,dive"`
+	ExceptionDates []string            `json:"exception_dates,omitempty"` // Format: "2006-01-02"
+	SkipHolidays   bool                `json:"skip_holidays"`
+}
+
+// UpdateScheduleTemplateRequest represents schedule template update data
+type UpdateScheduleTemplateRequest struct {
+	VesselID       *uuid.UUID          `json:"vessel_id,omitempty"`
+	Name           *string             `json:"name,omitempty" binding:"omitempty,max=100"`
+	BasePrice      *money.Money        `json:"base_price,omitempty"`
+	ValidFrom      *string             `json:"valid_from,omitempty"`
+	ValidUntil     *string             `json:"valid_until,omitempty"`
+	Weekdays       []int               `json:"weekdays,omitempty" binding:"omitempty,dive,min=1,max=7"`
+	Departures     []TemplateDeparture `json:"departures,omitempty" binding:"omitempty,min=1,dive"`
+	ExceptionDates []string            `json:"exception_dates,omitempty"`
+	SkipHolidays   *bool               `json:"skip_holidays,omitempty"`
+	IsActive       *bool               `json:"is_active,omitempty"`
+}
+
+// GenerateSchedulesRequest asks for a template's schedules up to a date.
+// A dry run reports what would change without changing anything.
+type GenerateSchedulesRequest struct {
+	Until  string `json:"until" binding:"required"` // Format: "2006-01-02"
+	DryRun bool   `json:"dry_run"`
+}
+
+// TemplateInstance is a schedule generated from a template and whether it
+// has been booked
+type TemplateInstance struct {
+	Schedule *Schedule
+	Booked   bool
+}
+
+// ScheduleGeneration reports the schedules a template run created, updated
+// and removed, and the booked schedules it left alone
+type ScheduleGeneration struct {
+	TemplateID uuid.UUID   `json:"template_id"`
+	DryRun     bool        `json:"dry_run"`
+	From       time.Time   `json:"from"`
+	Until      time.Time   `json:"until"`
+	Created    []*Schedule `json:"created"`
+	Updated    []*Schedule `json:"updated"`
+	Removed    []*Schedule `json:"removed"`
+	Kept       []*Schedule `json:"kept"`
+	Unchanged  int         `json:"unchanged"`
+}
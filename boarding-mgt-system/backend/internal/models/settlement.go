@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// SettlementColumnMapping maps settlement fields to the CSV header names used by a provider.
+// Either GrossAmount or NetAmount must be mapped; the other is derived using FeeAmount.
+type SettlementColumnMapping struct {
+	TransactionID   string `json:"transaction_id" binding:"required"`
+	GrossAmount     string `json:"gross_amount,omitempty"`
+	FeeAmount       string `json:"fee_amount,omitempty"`
+	NetAmount       string `json:"net_amount,omitempty"`
+	Currency        string `json:"currency,omitempty"`
+	SettledAt       string `json:"settled_at,omitempty"`
+	PayoutReference string `json:"payout_reference,omitempty"`
+}
+
+// SettlementProvider represents a payment gateway that sends settlement files
+type SettlementProvider struct {
+	ID                  uuid.UUID               `json:"id" db:"id"`
+	Code                string                  `json:"code" db:"code"`
+	Name                string                  `json:"name" db:"name"`
+	ColumnMapping       SettlementColumnMapping `json:"column_mapping" db:"column_mapping"`
+	Delimiter           string                  `json:"delimiter" db:"delimiter"`
+	DateFormat          string                  `json:"date_format" db:"date_format"`
+	PaymentMethods      []string                `json:"payment_methods" db:"payment_methods"`
+	SettlementDelayDays int                     `json:"settlement_delay_days" db:"settlement_delay_days"`
+	IsActive            bool                    `json:"is_active" db:"is_active"`
+	CreatedAt           time.Time               `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time               `json:"updated_at" db:"updated_at"`
+}
+
+// SettlementImport represents an imported settlement file and its reconciliation totals
+type SettlementImport struct {
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	ProviderID          uuid.UUID  `json:"provider_id" db:"provider_id"`
+	FileName            string     `json:"file_name" db:"file_name"`
+	FileHash            string     `json:"file_hash" db:"file_hash"`
+	RowCount            int        `json:"row_count" db:"row_count"`
+	MatchedCount        int        `json:"matched_count" db:"matched_count"`
+	FeeDeductedCount    int        `json:"fee_deducted_count" db:"fee_deducted_count"`
+	AmountMismatchCount int        `json:"amount_mismatch_count" db:"amount_mismatch_count"`
+	UnknownCount        int        `json:"unknown_count" db:"unknown_count"`
+	DuplicateCount      int        `json:"duplicate_count" db:"duplicate_count"`
+	MissingPayoutCount  int        `json:"missing_payout_count" db:"missing_payout_count"`
+	PeriodStart         *time.Time `json:"period_start,omitempty" db:"period_start"`
+	PeriodEnd           *time.Time `json:"period_end,omitempty" db:"period_end"`
+	ImportedBy          *uuid.UUID `json:"imported_by,omitempty" db:"imported_by"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+
+	// Joined fields
+	Provider *SettlementProvider `json:"provider,omitempty" db:"-"`
+	Lines    []*SettlementLine   `json:"lines,omitempty" db:"-"`
+}
+
+// SettlementLine represents a single row of a settlement file
+type SettlementLine struct {
+	ID                   uuid.UUID   `json:"id" db:"id"`
+	ImportID             uuid.UUID   `json:"import_id" db:"import_id"`
+	RowNumber            int         `json:"row_number" db:"row_number"`
+	GatewayTransactionID string      `json:"gateway_transaction_id" db:"gateway_transaction_id"`
+	Currency             *string     `json:"currency,omitempty" db:"currency"`
+	GrossAmount          money.Money `json:"gross_amount" db:"gross_amount"`
+	FeeAmount            money.Money `json:"fee_amount" db:"fee_amount"`
+	NetAmount            money.Money `json:"net_amount" db:"net_amount"`
+	SettledAt            *time.Time  `json:"settled_at,omitempty" db:"settled_at"`
+	PayoutReference      *string     `json:"payout_reference,omitempty" db:"payout_reference"`
+	PaymentID            *uuid.UUID  `json:"payment_id,omitempty" db:"payment_id"`
+	MatchStatus          string      `json:"match_status" db:"match_status"`
+	AmountVariance       money.Money `json:"amount_variance" db:"amount_variance"`
+	ResolvedBy           *uuid.UUID  `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt           *time.Time  `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolutionNotes      *string     `json:"resolution_notes,omitempty" db:"resolution_notes"`
+	CreatedAt            time.Time   `json:"created_at" db:"created_at"`
+}
+
+// UnreconciledPayment represents a payment flagged during settlement reconciliation
+type UnreconciledPayment struct {
+	PaymentID            uuid.UUID    `json:"payment_id"`
+	BookingReference     string       `json:"booking_reference"`
+	OperatorID           uuid.UUID    `json:"operator_id"`
+	PaymentMethod        string       `json:"payment_method"`
+	Amount               money.Money  `json:"amount"`
+	Currency             string       `json:"currency"`
+	GatewayTransactionID *string      `json:"gateway_transaction_id,omitempty"`
+	ReconciliationStatus string       `json:"reconciliation_status"`
+	ProcessedAt          *time.Time   `json:"processed_at,omitempty"`
+	SettledGross         *money.Money `json:"settled_gross,omitempty"`
+	SettledFee           *money.Money `json:"settled_fee,omitempty"`
+	AmountVariance       *money.Money `json:"amount_variance,omitempty"`
+}
+
+// UnreconciledReport lists payments and settlement lines that need finance attention
+type UnreconciledReport struct {
+	GeneratedAt   time.Time              `json:"generated_at"`
+	Payments      []*UnreconciledPayment `json:"payments"`
+	UnknownLines  []*SettlementLine      `json:"unknown_lines"`
+	CountByStatus map[string]int         `json:"count_by_status"`
+	TotalVariance money.Money            `json:"total_variance"`
+	TotalFees     money.Money            `json:"total_fees"`
+}
+
+// CreateSettlementProviderRequest represents a request to register a settlement provider
+type CreateSettlementProviderRequest struct {
+	Code                string                  `json:"code" binding:"required,max=50"`
+	Name                string                  `json:"name" binding:"required,max=255"`
+	ColumnMapping       SettlementColumnMapping `json:"column_mapping" binding:"required"`
+	Delimiter           string                  `json:"delimiter" binding:"omitempty,len=1"`
+	DateFormat          string                  `json:"date_format" binding:"omitempty,max=50"`
+	PaymentMethods      []string                `json:"payment_methods" binding:"required,min=1,dive,oneof=credit_card debit_card bank_transfer mobile_money paypal"`
+	SettlementDelayDays int                     `json:"settlement_delay_days" binding:"min=0"`
+}
+
+// UpdateSettlementProviderRequest represents a request to update a settlement provider
+type UpdateSettlementProviderRequest struct {
+	Name                *string                  `json:"name" binding:"omitempty,max=255"`
+	ColumnMapping       *SettlementColumnMapping `json:"column_mapping"`
+	Delimiter           *string                  `json:"delimiter" binding:"omitempty,len=1"`
+	DateFormat          *string                  `json:"date_format" binding:"omitempty,max=50"`
+	PaymentMethods      []string                 `json:"payment_methods" binding:"omitempty,dive,oneof=credit_card debit_card bank_transfer mobile_money paypal"`
+	SettlementDelayDays *int                     `json:"settlement_delay_days" binding:"omitempty,min=0"`
+	IsActive            *bool                    `json:"is_active"`
+}
+
+// ResolveReconciliationRequest represents a request to close a flagged reconciliation item
+type ResolveReconciliationRequest struct {
+	Notes string `json:"notes" binding:"required"`
+}
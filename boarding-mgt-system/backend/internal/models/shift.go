@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+)
+
+// AgentShift represents an agent's cash drawer session at a POS terminal
+type AgentShift struct {
+	ID                  uuid.UUID    `json:"id" db:"id"`
+	OperatorID          uuid.UUID    `json:"operator_id" db:"operator_id"`
+	AgentID             uuid.UUID    `json:"agent_id" db:"agent_id"`
+	TerminalID          string       `json:"terminal_id" db:"terminal_id"`
+	Status              string       `json:"status" db:"status"`
+	OpeningFloat        money.Money  `json:"opening_float" db:"opening_float"`
+	ExpectedCash        *money.Money `json:"expected_cash,omitempty" db:"expected_cash"`
+	CountedCash         *money.Money `json:"counted_cash,omitempty" db:"counted_cash"`
+	CashVariance        *money.Money `json:"cash_variance,omitempty" db:"cash_variance"`
+	OpenedAt            time.Time    `json:"opened_at" db:"opened_at"`
+	ClosedAt            *time.Time   `json:"closed_at,omitempty" db:"closed_at"`
+	ClosingNotes        *string      `json:"closing_notes,omitempty" db:"closing_notes"`
+	ReconciledBy        *uuid.UUID   `json:"reconciled_by,omitempty" db:"reconciled_by"`
+	ReconciledAt        *time.Time   `json:"reconciled_at,omitempty" db:"reconciled_at"`
+	ReconciliationNotes *string      `json:"reconciliation_notes,omitempty" db:"reconciliation_notes"`
+	CreatedAt           time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time    `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	Agent *User `json:"agent,omitempty" db:"-"`
+}
+
+// ShiftCashTotals represents the cash movements recorded against a shift
+type ShiftCashTotals struct {
+	CashSales       money.Money            `json:"cash_sales"`
+	CashRefunds     money.Money            `json:"cash_refunds"`
+	BookingCount    int                    `json:"booking_count"`
+	RefundCount     int                    `json:"refund_count"`
+	ByPaymentMethod map[string]money.Money `json:"by_payment_method"`
+}
+
+// ShiftVarianceReport represents the cash reconciliation of a shift
+type ShiftVarianceReport struct {
+	Shift           *AgentShift            `json:"shift"`
+	OpeningFloat    money.Money            `json:"opening_float"`
+	CashSales       money.Money            `json:"cash_sales"`
+	CashRefunds     money.Money            `json:"cash_refunds"`
+	ExpectedCash    money.Money            `json:"expected_cash"`
+	CountedCash     *money.Money           `json:"counted_cash,omitempty"`
+	Variance        *money.Money           `json:"variance,omitempty"`
+	BookingCount    int                    `json:"booking_count"`
+	RefundCount     int                    `json:"refund_count"`
+	ByPaymentMethod map[string]money.Money `json:"by_payment_method"`
+}
+
+// OpenShiftRequest represents opening a cash drawer
+type OpenShiftRequest struct {
+	TerminalID   string      `json:"terminal_id" binding:"required,max=50"`
+	OpeningFloat money.Money `json:"opening_float"`
+}
+
+// CloseShiftRequest represents closing a cash drawer with the counted amount
+type CloseShiftRequest struct {
+	CountedCash money.Money `json:"counted_cash"`
+	Notes       string      `json:"notes,omitempty"`
+}
+
+// ReconcileShiftRequest represents an admin signing off a shift variance
+type ReconcileShiftRequest struct {
+	Notes string `json:"notes" binding:"required"`
+}
+
+// CreatePOSBookingRequest represents a counter sale made by an agent
+type CreatePOSBookingRequest struct {
+	CreateBookingRequest
+	CustomerID uuid.UUID `json:"customer_id" binding:"required"`
+}
+
+// POSRefundRequest represents a counter refund paid out of the drawer
+type POSRefundRequest struct {
+	Reason string `json:"reason" binding:"required,oneof=cancellation schedule_change no_show service_issue duplicate_payment other"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimetableVersion is a version of an operator's timetable for a season. It
+// is drafted, submitted for review and then published; its sailings are off
+// sale until it is. Publishing replaces the published versions it overlaps,
+// which are kept as superseded so that rolling it back can restore them.
+type TimetableVersion struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	OperatorID   uuid.UUID  `json:"operator_id" db:"operator_id"`
+	Name         string     `json:"name" db:"name"`
+	ValidFrom    time.Time  `json:"valid_from" db:"valid_from"`
+	ValidUntil   time.Time  `json:"valid_until" db:"valid_until"`
+	Status       string     `json:"status" db:"status"` // draft, review, published or superseded
+	SupersededBy *uuid.UUID `json:"superseded_by,omitempty" db:"superseded_by"`
+	SubmittedBy  *uuid.UUID `json:"submitted_by,omitempty" db:"submitted_by"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty" db:"submitted_at"`
+	PublishedBy  *uuid.UUID `json:"published_by,omitempty" db:"published_by"`
+	PublishedAt  *time.Time `json:"published_at,omitempty" db:"published_at"`
+	Version      int        `json:"version" db:"version"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+
+	Sailings []*Schedule `json:"sailings,omitempty" db:"-"`
+}
+
+// CreateTimetableVersionRequest starts a draft timetable for a season. A
+// draft copied from another version starts with that version's sailings in
+// the season. The operator is the one the request acts for.
+type CreateTimetableVersionRequest struct {
+	OperatorID uuid.UUID  `json:"-"`
+	Name       string     `json:"name" binding:"required,max=100"`
+	ValidFrom  string     `json:"valid_from" binding:"required"`  // Format: "2006-01-02"
+	ValidUntil string     `json:"valid_until" binding:"required"` // Format: "2006-01-02"
+	CopyFrom   *uuid.UUID `json:"copy_from,omitempty"`
+}
+
+// TimetableSailing is a sailing of a timetable version and whether it has
+// any live bookings
+type TimetableSailing struct {
+	Schedule *Schedule
+	Booked   bool
+}
+
+// TimetableChange is a departure whose sailing a new timetable version
+// changes
+type TimetableChange struct {
+	From *Schedule `json:"from"`
+	To   *Schedule `json:"to"`
+}
+
+// TimetableDiff compares a timetable version with the future sailings now
+// on sale for its season. Departures are matched by route, date and
+// departure time. Booked sailings stay on sale as they are and are listed as
+// kept, in place of the version's own sailing for the departure.
+type TimetableDiff struct {
+	VersionID uuid.UUID         `json:"version_id"`
+	Replaces  []uuid.UUID       `json:"replaces"` // Published versions it supersedes
+	Added     []*Schedule       `json:"added"`
+	Changed   []TimetableChange `json:"changed"`
+	Removed   []*Schedule       `json:"removed"`
+	Kept      []*Schedule       `json:"kept"`
+	Unchanged int               `json:"unchanged"`
+}
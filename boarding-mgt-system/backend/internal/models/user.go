@@ -18,6 +18,7 @@ type User struct {
 	Nationality  *string    `json:"nationality,omitempty" db:"nationality"`
 	UserType     string     `json:"user_type" db:"user_type"`
 	OperatorID   *uuid.UUID `json:"operator_id,omitempty" db:"operator_id"`
+	AgencyID     *uuid.UUID `json:"agency_id,omitempty" db:"agency_id"`
 	IsVerified   bool       `json:"is_verified" db:"is_verified"`
 	IsActive     bool       `json:"is_active" db:"is_active"`
 	LastLoginAt  *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalletPass represents the mobile wallet pass issued for a ticket. Version
+// goes up whenever the pass changes and wallets holding it are told to
+// download it again.
+type WalletPass struct {
+	ID                  uuid.UUID `json:"id" db:"id"`
+	TicketID            uuid.UUID `json:"ticket_id" db:"ticket_id"`
+	SerialNumber        string    `json:"serial_number" db:"serial_number"`
+	AuthenticationToken string    `json:"-" db:"authentication_token"`
+	Gate                *string   `json:"gate,omitempty" db:"gate"`
+	Notice              *string   `json:"notice,omitempty" db:"notice"`
+	Version             int       `json:"version" db:"version"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WalletPassFile is a built pass package
+type WalletPassFile struct {
+	SerialNumber string
+	Data         []byte
+	LastModified time.Time
+}
+
+// WalletPassUpdateRequest changes the passes of a sailing. Omitted fields
+// are left as they are; an empty string clears them.
+type WalletPassUpdateRequest struct {
+	Gate   *string `json:"gate,omitempty" binding:"omitempty,max=50"`
+	Notice *string `json:"notice,omitempty" binding:"omitempty,max=500"`
+}
+
+// WalletPassUpdateResult reports how many passes got a new version and how
+// many devices were notified
+type WalletPassUpdateResult struct {
+	Updated  int `json:"updated"`
+	Notified int `json:"notified"`
+	Failed   int `json:"failed"`
+}
+
+// WalletRegistrationRequest is sent by a wallet when a pass is added to it
+type WalletRegistrationRequest struct {
+	PushToken string `json:"pushToken" binding:"required,max=200"`
+}
+
+// WalletSerialNumbers lists the passes on a device that changed since a tag
+type WalletSerialNumbers struct {
+	SerialNumbers []string `json:"serialNumbers"`
+	LastUpdated   string   `json:"lastUpdated"`
+}
+
+// WalletLogRequest carries errors a wallet reports about the web service
+type WalletLogRequest struct {
+	Logs []string `json:"logs"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WeatherLimits are the conditions a vessel is cleared to sail in. A limit
+// left out is not checked.
+type WeatherLimits struct {
+	MaxWindKnots    *float64 `json:"max_wind_knots,omitempty" binding:"omitempty,gt=0"`
+	MaxWaveHeightM  *float64 `json:"max_wave_height_m,omitempty" binding:"omitempty,gt=0"`
+	MinVisibilityNM *float64 `json:"min_visibility_nm,omitempty" binding:"omitempty,gt=0"`
+}
+
+// WeatherExceedance is a forecast beyond one of a vessel's limits at one of
+// a sailing's ports or along its route
+type WeatherExceedance struct {
+	Measure  string    `json:"measure"`  // wind, wave_height or visibility
+	Location string    `json:"location"` // departure_port, arrival_port or route
+	Forecast float64   `json:"forecast"`
+	Limit    float64   `json:"limit"`
+	From     time.Time `json:"from"`
+	Until    time.Time `json:"until"`
+}
+
+// WeatherSailing is an upcoming sailing with what is needed to check its
+// forecast: its ports and the limits of its vessel
+type WeatherSailing struct {
+	Schedule      *Schedule     `json:"schedule"`
+	DeparturePort string        `json:"departure_port"`
+	ArrivalPort   string        `json:"arrival_port"`
+	Limits        WeatherLimits `json:"limits"`
+}
+
+// WeatherAdvisory flags a sailing whose forecast exceeds its vessel's limits
+// for operators to review. Each sailing has at most one advisory: later
+// forecast checks update an open advisory and clear it when the forecast
+// is back within limits, but leave reviewed advisories as they are.
+type WeatherAdvisory struct {
+	ID           uuid.UUID           `json:"id" db:"id"`
+	ScheduleID   uuid.UUID           `json:"schedule_id" db:"schedule_id"`
+	OperatorID   uuid.UUID           `json:"operator_id" db:"operator_id"`
+	VesselID     uuid.UUID           `json:"vessel_id" db:"vessel_id"`
+	Exceedances  []WeatherExceedance `json:"exceedances" db:"exceedances"`
+	Status       string              `json:"status" db:"status"`           // open, cleared, dismissed or actioned
+	Action       *string             `json:"action,omitempty" db:"action"` // delay or cancel
+	DisruptionID *uuid.UUID          `json:"disruption_id,omitempty" db:"disruption_id"`
+	Notes        *string             `json:"notes,omitempty" db:"notes"`
+	ReviewedBy   *uuid.UUID          `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt   *time.Time          `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CheckedAt    time.Time           `json:"checked_at" db:"checked_at"` // Last forecast check that found the limits exceeded
+	CreatedAt    time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	RouteID     uuid.UUID `json:"route_id" db:"-"`
+	DepartureAt time.Time `json:"departure_at" db:"-"`
+}
+
+// WeatherCheckResult reports a forecast check of the sailings departing in
+// a period
+type WeatherCheckResult struct {
+	From     time.Time `json:"from"`
+	Until    time.Time `json:"until"`
+	Checked  int       `json:"checked"`
+	NoLimits int       `json:"no_limits"` // Sailings whose vessel has no limits set
+	Opened   int       `json:"opened"`
+	Updated  int       `json:"updated"`
+	Cleared  int       `json:"cleared"`
+	Reviewed int       `json:"reviewed"` // Sailings exceeding limits whose advisory was already reviewed
+}
+
+// DismissWeatherAdvisoryRequest closes an advisory without acting on it
+type DismissWeatherAdvisoryRequest struct {
+	Notes *string `json:"notes,omitempty" binding:"omitempty,max=1000"`
+}
+
+// DelayForWeatherRequest delays an advisory's sailing for the weather.
+// Without a new arrival the sailing keeps its duration.
+type DelayForWeatherRequest struct {
+	EstimatedDepartureAt time.Time  `json:"estimated_departure_at" binding:"required"`
+	EstimatedArrivalAt   *time.Time `json:"estimated_arrival_at,omitempty"`
+	Description          *string    `json:"description,omitempty" binding:"omitempty,max=1000"`
+	Notes                *string    `json:"notes,omitempty" binding:"omitempty,max=1000"`
+}
+
+// CancelForWeatherRequest cancels an advisory's sailing for the weather
+type CancelForWeatherRequest struct {
+	Reason string  `json:"reason" binding:"required"`
+	Notes  *string `json:"notes,omitempty" binding:"omitempty,max=1000"`
+}
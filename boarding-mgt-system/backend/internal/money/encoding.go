@@ -0,0 +1,82 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MarshalJSON writes the amount as a decimal string such as "12.50", so
+// clients never see it as a binary float
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON reads a decimal string or, for older clients, a JSON number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// ScanNumeric reads a NUMERIC column. Values with more than two decimal
+// places, such as averages, are rounded half up to the cent.
+func (m *Money) ScanNumeric(v pgtype.Numeric) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into money")
+	}
+	if v.NaN || v.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("cannot scan non-finite numeric into money")
+	}
+
+	// Numeric is Int * 10^Exp; cents are Int * 10^(Exp+2)
+	value := new(big.Rat)
+	if v.Int != nil {
+		value.SetInt(v.Int)
+	}
+	exp := int64(v.Exp) + 2
+	power := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(exp)), nil)
+	if exp >= 0 {
+		value.Mul(value, new(big.Rat).SetInt(power))
+	} else {
+		value.Quo(value, new(big.Rat).SetInt(power))
+	}
+
+	cents := round(value, HalfUp)
+	currency := m.currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	*m = New(cents, currency)
+	return nil
+}
+
+// NumericValue writes the amount to a NUMERIC column
+func (m Money) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(m.cents), Exp: -2, Valid: true}, nil
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,305 @@
+// Package money holds exact monetary amounts. Amounts are kept as integer
+// minor units (cents) with their currency, so fares, totals and reports add
+// up without the drift of binary floating point.
+package money
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts read from columns that do not
+// carry their own currency
+const DefaultCurrency = "USD"
+
+// Money is an amount of a currency in minor units. All supported currencies
+// have two decimal places, matching the DECIMAL(10,2) columns amounts are
+// stored in. The zero value is zero in no particular currency and takes the
+// currency of whatever it is combined with.
+type Money struct {
+	cents    int64
+	currency string
+}
+
+// Rounding is the rule used when a calculation lands between two cents
+type Rounding int
+
+const (
+	// HalfUp rounds halves away from zero. It is used for fares, tax and
+	// commission.
+	HalfUp Rounding = iota
+	// HalfEven rounds halves to the nearest even cent
+	HalfEven
+	// Down truncates towards zero
+	Down
+)
+
+// New returns an amount of cents in a currency
+func New(cents int64, currency string) Money {
+	return Money{cents: cents, currency: currency}
+}
+
+// Cents returns an amount of cents in the default currency
+func Cents(cents int64) Money {
+	return New(cents, DefaultCurrency)
+}
+
+// Zero returns nothing of a currency
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Parse reads a decimal amount such as "12.50" or "-3" in the default
+// currency. Amounts with more than two decimal places are rejected rather
+// than rounded.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	unsigned := strings.TrimLeft(s, "+-")
+	if len(s)-len(unsigned) > 1 {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	whole, fraction, _ := strings.Cut(unsigned, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(fraction) > 2 {
+		return Money{}, fmt.Errorf("amount %q has more than two decimal places", s)
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	for _, r := range whole + fraction {
+		if r < '0' || r > '9' {
+			return Money{}, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+
+	cents, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	if strings.HasPrefix(s, "-") {
+		cents = -cents
+	}
+
+	return Cents(cents), nil
+}
+
+// MustParse is Parse for amounts known to be valid, such as constants
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Cents returns the amount in minor units
+func (m Money) Cents() int64 {
+	return m.cents
+}
+
+// Currency returns the amount's currency, or "" for a zero value
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// In returns the same amount in another currency
+func (m Money) In(currency string) Money {
+	return New(m.cents, currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.cents == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.cents > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.cents < 0
+}
+
+// CheckCurrency reports an error unless the amounts share one currency.
+// Cmp, Add, Sub, Sum and Prorate treat mixing currencies as a programming
+// error, so amounts read from storage or requests are checked with it first.
+func CheckCurrency(amounts ...Money) error {
+	var currency string
+	for _, amount := range amounts {
+		switch {
+		case amount.currency == "", amount.currency == currency:
+		case currency == "":
+			currency = amount.currency
+		default:
+			return fmt.Errorf("cannot combine %s and %s amounts", currency, amount.currency)
+		}
+	}
+	return nil
+}
+
+// CheckFactor reports an error unless f can scale an amount: a finite,
+// non-negative number. Rates read from settings are checked with it before
+// being passed to Mul or MulDiv.
+func CheckFactor(f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) || f < 0 {
+		return fmt.Errorf("invalid factor %v", f)
+	}
+	return nil
+}
+
+// Cmp compares two amounts of the same currency, returning -1, 0 or +1
+func (m Money) Cmp(o Money) int {
+	m.currencyWith(o)
+	switch {
+	case m.cents < o.cents:
+		return -1
+	case m.cents > o.cents:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m Money) Add(o Money) Money {
+	return New(m.cents+o.cents, m.currencyWith(o))
+}
+
+func (m Money) Sub(o Money) Money {
+	return New(m.cents-o.cents, m.currencyWith(o))
+}
+
+func (m Money) Neg() Money {
+	return New(-m.cents, m.currency)
+}
+
+// Times multiplies the amount by a whole quantity
+func (m Money) Times(quantity int) Money {
+	return New(m.cents*int64(quantity), m.currency)
+}
+
+// Mul multiplies the amount by a factor such as a fare multiplier or tax
+// rate. The factor is taken at its shortest decimal value, so 0.8 is exactly
+// four fifths, and the result is rounded to the cent with mode.
+func (m Money) Mul(factor float64, mode Rounding) Money {
+	return m.scale(decimal(factor), mode)
+}
+
+// MulDiv multiplies the amount by num/den, rounding once at the end. It is
+// used for tax-inclusive splits where the rate appears on both sides.
+func (m Money) MulDiv(num, den float64, mode Rounding) Money {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+	return m.scale(new(big.Rat).Quo(decimal(num), decimal(den)), mode)
+}
+
+// Prorate returns the share of the amount that part is of whole, for example
+// the tax on a partial refund of an invoice
+func (m Money) Prorate(part, whole Money, mode Rounding) Money {
+	part.currencyWith(whole)
+	if whole.cents == 0 {
+		panic("money: prorate over a zero amount")
+	}
+	return m.scale(big.NewRat(part.cents, whole.cents), mode)
+}
+
+// Min returns the smaller of two amounts
+func Min(a, b Money) Money {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// Sum adds up amounts of one currency
+func Sum(amounts ...Money) Money {
+	var total Money
+	for _, amount := range amounts {
+		total = total.Add(amount)
+	}
+	return total
+}
+
+// String formats the amount with two decimals, such as "-12.50"
+func (m Money) String() string {
+	sign := ""
+	cents := m.cents
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Format formats the amount with its currency, such as "USD 12.50"
+func (m Money) Format() string {
+	currency := m.currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return currency + " " + m.String()
+}
+
+// currencyWith returns the currency shared by two amounts. Combining
+// different currencies is a programming error.
+func (m Money) currencyWith(o Money) string {
+	switch {
+	case m.currency == o.currency, o.currency == "":
+		return m.currency
+	case m.currency == "":
+		return o.currency
+	default:
+		panic(fmt.Sprintf("money: mixing %s and %s", m.currency, o.currency))
+	}
+}
+
+func (m Money) scale(factor *big.Rat, mode Rounding) Money {
+	product := new(big.Rat).Mul(big.NewRat(m.cents, 1), factor)
+	return New(round(product, mode), m.currency)
+}
+
+// decimal converts a float to the exact value of its shortest decimal form
+func decimal(f float64) *big.Rat {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	if !ok {
+		panic(fmt.Sprintf("money: invalid factor %v", f))
+	}
+	return r
+}
+
+// round rounds a rational number of cents to a whole cent
+func round(r *big.Rat, mode Rounding) int64 {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() == 0 || mode == Down {
+		return quo.Int64()
+	}
+
+	// Compare twice the remainder with the denominator to find which side
+	// of the half the value is on
+	half := new(big.Int).Abs(rem)
+	half.Lsh(half, 1)
+	away := false
+	switch half.Cmp(r.Denom()) {
+	case 1:
+		away = true
+	case 0:
+		away = mode == HalfUp || quo.Bit(0) == 1
+	}
+
+	if away {
+		if r.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return quo.Int64()
+}
@@ -0,0 +1,148 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		cents int64
+	}{
+		{"12.50", 1250},
+		{"12.5", 1250},
+		{"12", 1200},
+		{"0.07", 7},
+		{".5", 50},
+		{"-3.10", -310},
+		{"+4.00", 400},
+	}
+	for _, tt := range tests {
+		m, err := Parse(tt.input)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.cents, m.Cents(), tt.input)
+		assert.Equal(t, DefaultCurrency, m.Currency())
+	}
+
+	for _, input := range []string{"", "abc", "1.234", "1,50", "--1", "1.2.3", "."} {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "12.50", Cents(1250).String())
+	assert.Equal(t, "0.05", Cents(5).String())
+	assert.Equal(t, "-0.05", Cents(-5).String())
+	assert.Equal(t, "0.00", Money{}.String())
+	assert.Equal(t, "EUR 3.00", New(300, "EUR").Format())
+}
+
+func TestArithmetic(t *testing.T) {
+	a := MustParse("10.10")
+	b := MustParse("0.20")
+
+	assert.Equal(t, Cents(1030), a.Add(b))
+	assert.Equal(t, Cents(990), a.Sub(b))
+	assert.Equal(t, Cents(-1010), a.Neg())
+	assert.Equal(t, Cents(3030), a.Times(3))
+	assert.Equal(t, Cents(1050), Sum(a, b, b))
+	assert.Equal(t, 1, a.Cmp(b))
+	assert.Equal(t, b, Min(a, b))
+
+	// The zero value takes the currency of the other amount
+	assert.Equal(t, "EUR", Money{}.Add(New(100, "EUR")).Currency())
+	assert.Panics(t, func() { New(100, "EUR").Add(New(100, "USD")) })
+}
+
+func TestCheckCurrency(t *testing.T) {
+	assert.NoError(t, CheckCurrency())
+	assert.NoError(t, CheckCurrency(New(100, "EUR"), Money{}, New(5, "EUR")))
+	assert.Error(t, CheckCurrency(New(100, "EUR"), Money{}, New(100, "USD")))
+}
+
+func TestCheckFactor(t *testing.T) {
+	assert.NoError(t, CheckFactor(0))
+	assert.NoError(t, CheckFactor(0.12))
+	assert.Error(t, CheckFactor(-0.1))
+	assert.Error(t, CheckFactor(math.NaN()))
+	assert.Error(t, CheckFactor(math.Inf(1)))
+}
+
+func TestMul(t *testing.T) {
+	// Child and senior fares used to drift as floats
+	assert.Equal(t, Cents(2298), MustParse("45.95").Mul(0.5, HalfUp))
+	assert.Equal(t, Cents(2298), MustParse("45.95").Mul(0.5, HalfEven))
+	assert.Equal(t, Cents(2298), MustParse("45.97").Mul(0.5, HalfEven))
+	assert.Equal(t, Cents(2297), MustParse("45.95").Mul(0.5, Down))
+	assert.Equal(t, Cents(3676), MustParse("45.95").Mul(0.8, HalfUp))
+	assert.Equal(t, Cents(-2298), MustParse("-45.95").Mul(0.5, HalfUp))
+
+	// Many discounted tickets add up exactly
+	total := Money{}
+	for i := 0; i < 1000; i++ {
+		total = total.Add(MustParse("0.30").Mul(0.5, HalfUp))
+	}
+	assert.Equal(t, Cents(15000), total)
+}
+
+func TestMulDiv(t *testing.T) {
+	// Tax included in a gross amount at 20%: 100 * 0.2 / 1.2 = 16.666...
+	assert.Equal(t, Cents(1667), MustParse("100.00").MulDiv(0.2, 1.2, HalfUp))
+	assert.Panics(t, func() { Cents(100).MulDiv(1, 0, HalfUp) })
+}
+
+func TestProrate(t *testing.T) {
+	tax := MustParse("16.67")
+	assert.Equal(t, Cents(417), tax.Prorate(MustParse("25.00"), MustParse("100.00"), HalfUp))
+	assert.Equal(t, tax, tax.Prorate(MustParse("100.00"), MustParse("100.00"), HalfUp))
+}
+
+func TestJSON(t *testing.T) {
+	type body struct {
+		Amount Money  `json:"amount"`
+		Fee    *Money `json:"fee,omitempty"`
+	}
+
+	data, err := json.Marshal(body{Amount: MustParse("19.90")})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"19.90"}`, string(data))
+
+	var decoded body
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"19.9","fee":0.35}`), &decoded))
+	assert.Equal(t, Cents(1990), decoded.Amount)
+	require.NotNil(t, decoded.Fee)
+	assert.Equal(t, Cents(35), *decoded.Fee)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"19.999"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":true}`), &decoded))
+}
+
+func TestNumeric(t *testing.T) {
+	var m Money
+	require.NoError(t, m.ScanNumeric(pgtype.Numeric{Int: big.NewInt(1250), Exp: -2, Valid: true}))
+	assert.Equal(t, Cents(1250), m)
+
+	require.NoError(t, m.ScanNumeric(pgtype.Numeric{Int: big.NewInt(3), Exp: 1, Valid: true}))
+	assert.Equal(t, Cents(3000), m)
+
+	// Averages carry extra digits and are rounded to the cent
+	require.NoError(t, m.ScanNumeric(pgtype.Numeric{Int: big.NewInt(123456), Exp: -4, Valid: true}))
+	assert.Equal(t, Cents(1235), m)
+
+	assert.Error(t, m.ScanNumeric(pgtype.Numeric{}))
+	assert.Error(t, m.ScanNumeric(pgtype.Numeric{NaN: true, Valid: true}))
+
+	v, err := MustParse("-7.05").NumericValue()
+	require.NoError(t, err)
+	assert.Equal(t, int64(-705), v.Int.Int64())
+	assert.Equal(t, int32(-2), v.Exp)
+	assert.True(t, v.Valid)
+}
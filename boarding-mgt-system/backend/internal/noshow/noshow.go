@@ -0,0 +1,140 @@
+// Package noshow decides what happens to the fares of passengers who never
+// board a sailing and summarizes no-show rates for reporting.
+package noshow
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+)
+
+// No-show fare policies
+const (
+	// PolicyForfeit keeps the whole fare
+	PolicyForfeit = "forfeit"
+	// PolicyCredit gives the passenger part of the fare back as travel credit
+	PolicyCredit = "credit"
+)
+
+// DefaultCreditValidity is how long travel credit can be used for
+const DefaultCreditValidity = 365 * 24 * time.Hour
+
+// Policy is an operator's no-show fare policy
+type Policy struct {
+	Mode string
+	// CreditRate is the fraction of the fare given back as credit (0.5 for half)
+	CreditRate     float64
+	CreditValidity time.Duration
+}
+
+// PolicyFromSettings reads the no-show policy from operator settings.
+// "no_show_policy" is "forfeit" (the default) or "credit";
+// "no_show_credit_rate" is the fraction of the fare credited and
+// "no_show_credit_days" how long credit lasts.
+func PolicyFromSettings(settings map[string]interface{}) Policy {
+	policy := Policy{Mode: PolicyForfeit, CreditValidity: DefaultCreditValidity}
+
+	if mode, _ := settings["no_show_policy"].(string); mode == PolicyCredit {
+		policy.Mode = PolicyCredit
+	}
+
+	rate := number(settings["no_show_credit_rate"])
+	if rate > 1 {
+		rate = 1
+	}
+	if policy.Mode == PolicyCredit && rate > 0 {
+		policy.CreditRate = rate
+	} else {
+		policy.Mode = PolicyForfeit
+	}
+
+	if days := number(settings["no_show_credit_days"]); days >= 1 {
+		policy.CreditValidity = time.Duration(days) * 24 * time.Hour
+	}
+
+	return policy
+}
+
+func number(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	default:
+		return 0
+	}
+}
+
+// Credit returns the travel credit a no-show passenger gets for a fare
+func (p Policy) Credit(fare money.Money) money.Money {
+	if p.Mode != PolicyCredit || !fare.IsPositive() {
+		return money.Zero(fare.Currency())
+	}
+	return fare.Mul(p.CreditRate, money.HalfUp)
+}
+
+// Rate is the share of passengers who did not board, from 0 to 1
+func Rate(noShows, passengers int) float64 {
+	if passengers == 0 {
+		return 0
+	}
+	return float64(noShows) / float64(passengers)
+}
+
+// Summarize builds a no-show report from per-route, per-channel counts
+func Summarize(counts []models.NoShowCount, start, end time.Time) *models.NoShowReport {
+	report := &models.NoShowReport{
+		PeriodStart: start,
+		PeriodEnd:   end,
+		ByRoute:     []models.NoShowRate{},
+		ByChannel:   []models.NoShowRate{},
+	}
+
+	routes := map[string]*models.NoShowRate{}
+	channels := map[string]*models.NoShowRate{}
+	add := func(rates map[string]*models.NoShowRate, key, name string, c models.NoShowCount) {
+		rate, ok := rates[key]
+		if !ok {
+			rate = &models.NoShowRate{Key: key, Name: name}
+			rates[key] = rate
+		}
+		rate.Passengers += c.Passengers
+		rate.NoShows += c.NoShows
+	}
+
+	for _, c := range counts {
+		report.Passengers += c.Passengers
+		report.NoShows += c.NoShows
+		add(routes, c.RouteID.String(), c.RouteName, c)
+		add(channels, c.BookingChannel, c.BookingChannel, c)
+	}
+	report.Rate = Rate(report.NoShows, report.Passengers)
+
+	report.ByRoute = rates(routes)
+	report.ByChannel = rates(channels)
+	return report
+}
+
+// rates sorts rates by name and fills in each rate
+func rates(byKey map[string]*models.NoShowRate) []models.NoShowRate {
+	list := make([]models.NoShowRate, 0, len(byKey))
+	for _, rate := range byKey {
+		rate.Rate = Rate(rate.NoShows, rate.Passengers)
+		list = append(list, *rate)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
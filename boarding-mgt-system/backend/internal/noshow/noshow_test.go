@@ -0,0 +1,86 @@
+package noshow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyFromSettings(t *testing.T) {
+	t.Run("Forfeit by default", func(t *testing.T) {
+		policy := PolicyFromSettings(nil)
+		assert.Equal(t, PolicyForfeit, policy.Mode)
+		assert.Equal(t, DefaultCreditValidity, policy.CreditValidity)
+	})
+
+	t.Run("Partial credit", func(t *testing.T) {
+		policy := PolicyFromSettings(map[string]interface{}{
+			"no_show_policy":      "credit",
+			"no_show_credit_rate": "0.5",
+			"no_show_credit_days": float64(90),
+		})
+		assert.Equal(t, PolicyCredit, policy.Mode)
+		assert.Equal(t, 0.5, policy.CreditRate)
+		assert.Equal(t, 90*24*time.Hour, policy.CreditValidity)
+	})
+
+	t.Run("Credit without a rate forfeits", func(t *testing.T) {
+		policy := PolicyFromSettings(map[string]interface{}{"no_show_policy": "credit"})
+		assert.Equal(t, PolicyForfeit, policy.Mode)
+	})
+
+	t.Run("Rate is capped at the full fare", func(t *testing.T) {
+		policy := PolicyFromSettings(map[string]interface{}{
+			"no_show_policy":      "credit",
+			"no_show_credit_rate": float64(2),
+		})
+		assert.Equal(t, 1.0, policy.CreditRate)
+	})
+}
+
+func TestCredit(t *testing.T) {
+	fare := money.MustParse("45.25")
+
+	forfeit := Policy{Mode: PolicyForfeit}
+	assert.False(t, forfeit.Credit(fare).IsPositive())
+
+	credit := Policy{Mode: PolicyCredit, CreditRate: 0.5}
+	assert.Equal(t, money.MustParse("22.63"), credit.Credit(fare))
+	assert.False(t, credit.Credit(money.MustParse("0.00")).IsPositive())
+}
+
+func TestSummarize(t *testing.T) {
+	north, south := uuid.New(), uuid.New()
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	report := Summarize([]models.NoShowCount{
+		{RouteID: north, RouteName: "North", BookingChannel: "online", Passengers: 80, NoShows: 8},
+		{RouteID: north, RouteName: "North", BookingChannel: "counter", Passengers: 20, NoShows: 0},
+		{RouteID: south, RouteName: "South", BookingChannel: "online", Passengers: 100, NoShows: 12},
+	}, start, end)
+
+	assert.Equal(t, 200, report.Passengers)
+	assert.Equal(t, 20, report.NoShows)
+	assert.InDelta(t, 0.1, report.Rate, 1e-9)
+
+	require.Len(t, report.ByRoute, 2)
+	assert.Equal(t, "North", report.ByRoute[0].Name)
+	assert.Equal(t, north.String(), report.ByRoute[0].Key)
+	assert.InDelta(t, 0.08, report.ByRoute[0].Rate, 1e-9)
+	assert.InDelta(t, 0.12, report.ByRoute[1].Rate, 1e-9)
+
+	require.Len(t, report.ByChannel, 2)
+	assert.Equal(t, "counter", report.ByChannel[0].Key)
+	assert.Equal(t, 0.0, report.ByChannel[0].Rate)
+	assert.InDelta(t, 20.0/180.0, report.ByChannel[1].Rate, 1e-9)
+
+	empty := Summarize(nil, start, end)
+	assert.Equal(t, 0.0, empty.Rate)
+	assert.Empty(t, empty.ByRoute)
+}
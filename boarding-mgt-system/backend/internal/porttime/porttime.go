@@ -0,0 +1,124 @@
+// Package porttime turns the local dates and clock times a sailing is
+// timetabled in into UTC instants using the IANA time zones of its ports.
+//
+// Departure times are local to the departure port and arrival times to the
+// arrival port. A local time skipped by a daylight saving change does not
+// exist and is rejected; a local time repeated by one is taken at its first
+// occurrence.
+package porttime
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// ErrNonexistentTime is returned for a local time that falls in a daylight
+// saving gap
+var ErrNonexistentTime = errors.New("local time does not exist")
+
+// LoadZone loads a port's IANA time zone
+func LoadZone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, fmt.Errorf("port has no time zone")
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return location, nil
+}
+
+// Instant returns the instant a local date and clock time stand for in a zone
+func Instant(date, clock time.Time, location *time.Location) (time.Time, error) {
+	wall := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+
+	// The zone's offset can only be one of those in force a day either side;
+	// each is tried and the earliest instant that reads back as the same wall
+	// clock time wins
+	var instant time.Time
+	found := false
+	for _, probe := range []time.Time{wall.Add(-24 * time.Hour), wall, wall.Add(24 * time.Hour)} {
+		_, offset := probe.In(location).Zone()
+		candidate := wall.Add(-time.Duration(offset) * time.Second)
+		if !sameWallClock(candidate.In(location), wall) {
+			continue
+		}
+		if !found || candidate.Before(instant) {
+			instant, found = candidate, true
+		}
+	}
+
+	if !found {
+		return time.Time{}, fmt.Errorf("%w: %s %s in %s is skipped by a daylight saving change",
+			ErrNonexistentTime, wall.Format("2006-01-02"), wall.Format("15:04"), location)
+	}
+	return instant.UTC(), nil
+}
+
+// Sailing works out a sailing's departure and arrival instants from its local
+// departure date and times. The arrival is the first time after departure
+// the arrival port's clocks show the arrival time, so overnight sailings
+// arrive the next day.
+func Sailing(date, departureTime, arrivalTime time.Time, departureZone, arrivalZone *time.Location) (time.Time, time.Time, error) {
+	departs, err := Instant(date, departureTime, departureZone)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("departure: %w", err)
+	}
+
+	// The arrival port's date can be a day either side of the departure
+	// port's when their zones are far apart
+	for days := -1; days <= 1; days++ {
+		day := date.AddDate(0, 0, days)
+		arrives, err := Instant(day, arrivalTime, arrivalZone)
+		if err != nil {
+			// Only a skipped time that would have been the arrival matters
+			approx := time.Date(day.Year(), day.Month(), day.Day(), arrivalTime.Hour(), arrivalTime.Minute(), 0, 0, arrivalZone)
+			if approx.After(departs) {
+				return time.Time{}, time.Time{}, fmt.Errorf("arrival: %w", err)
+			}
+			continue
+		}
+		if arrives.After(departs) {
+			return departs, arrives, nil
+		}
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("arrival time %s cannot be placed after the departure", arrivalTime.Format("15:04"))
+}
+
+// Localize sets a schedule's local departure and arrival times, and those of
+// any delay estimates, from its instants and its ports' zones. Unknown zones
+// leave them unset.
+func Localize(schedule *models.Schedule, departureZone, arrivalZone string) {
+	schedule.DepartureTimezone = departureZone
+	schedule.ArrivalTimezone = arrivalZone
+
+	if location, err := LoadZone(departureZone); err == nil {
+		if !schedule.DepartureAt.IsZero() {
+			local := schedule.DepartureAt.In(location)
+			schedule.DepartureLocal = &local
+		}
+		if schedule.EstimatedDepartureAt != nil {
+			local := schedule.EstimatedDepartureAt.In(location)
+			schedule.EstimatedDepartureLocal = &local
+		}
+	}
+	if location, err := LoadZone(arrivalZone); err == nil {
+		if !schedule.ArrivalAt.IsZero() {
+			local := schedule.ArrivalAt.In(location)
+			schedule.ArrivalLocal = &local
+		}
+		if schedule.EstimatedArrivalAt != nil {
+			local := schedule.EstimatedArrivalAt.In(location)
+			schedule.EstimatedArrivalLocal = &local
+		}
+	}
+}
+
+func sameWallClock(t, wall time.Time) bool {
+	return t.Year() == wall.Year() && t.YearDay() == wall.YearDay() &&
+		t.Hour() == wall.Hour() && t.Minute() == wall.Minute()
+}
@@ -0,0 +1,143 @@
+package porttime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func clock(s string) time.Time {
+	t, _ := time.Parse("15:04", s)
+	return t
+}
+
+func zone(t *testing.T, name string) *time.Location {
+	location, err := LoadZone(name)
+	require.NoError(t, err)
+	return location
+}
+
+func utc(s string) time.Time {
+	t, _ := time.Parse("2006-01-02 15:04", s)
+	return t
+}
+
+func TestLoadZone(t *testing.T) {
+	_, err := LoadZone("")
+	assert.Error(t, err)
+
+	_, err = LoadZone("Nowhere/Unknown")
+	assert.Error(t, err)
+}
+
+func TestInstant(t *testing.T) {
+	athens := zone(t, "Europe/Athens")
+
+	t.Run("Summer and winter offsets", func(t *testing.T) {
+		instant, err := Instant(date("2025-07-01"), clock("09:30"), athens)
+		require.NoError(t, err)
+		assert.Equal(t, utc("2025-07-01 06:30"), instant)
+
+		instant, err = Instant(date("2025-01-15"), clock("09:30"), athens)
+		require.NoError(t, err)
+		assert.Equal(t, utc("2025-01-15 07:30"), instant)
+	})
+
+	t.Run("Skipped time is rejected", func(t *testing.T) {
+		// Athens clocks go from 03:00 to 04:00 on 30 March 2025
+		_, err := Instant(date("2025-03-30"), clock("03:30"), athens)
+		assert.ErrorIs(t, err, ErrNonexistentTime)
+	})
+
+	t.Run("Repeated time takes the first", func(t *testing.T) {
+		// Athens clocks go back from 04:00 to 03:00 on 26 October 2025
+		instant, err := Instant(date("2025-10-26"), clock("03:30"), athens)
+		require.NoError(t, err)
+		assert.Equal(t, utc("2025-10-26 00:30"), instant)
+	})
+}
+
+func TestSailing(t *testing.T) {
+	london := zone(t, "Europe/London")
+	paris := zone(t, "Europe/Paris")
+
+	t.Run("Same day across zones", func(t *testing.T) {
+		departs, arrives, err := Sailing(date("2025-07-01"), clock("09:00"), clock("12:30"), london, paris)
+		require.NoError(t, err)
+		assert.Equal(t, utc("2025-07-01 08:00"), departs)
+		assert.Equal(t, utc("2025-07-01 10:30"), arrives)
+	})
+
+	t.Run("Overnight arrives the next day", func(t *testing.T) {
+		departs, arrives, err := Sailing(date("2025-07-01"), clock("22:00"), clock("06:00"), london, paris)
+		require.NoError(t, err)
+		assert.Equal(t, utc("2025-07-01 21:00"), departs)
+		assert.Equal(t, utc("2025-07-02 04:00"), arrives)
+	})
+
+	t.Run("Arrival clock earlier than departure clock on the same night", func(t *testing.T) {
+		// 23:30 in London is 00:30 the next day in Paris
+		departs, arrives, err := Sailing(date("2025-07-01"), clock("23:30"), clock("00:45"), london, paris)
+		require.NoError(t, err)
+		assert.Equal(t, utc("2025-07-01 22:30"), departs)
+		assert.Equal(t, 15*time.Minute, arrives.Sub(departs))
+	})
+
+	t.Run("Arrival into a zone behind the departure port", func(t *testing.T) {
+		// 00:30 in Paris is 23:30 the previous day in London
+		departs, arrives, err := Sailing(date("2025-07-02"), clock("00:30"), clock("23:45"), paris, london)
+		require.NoError(t, err)
+		assert.Equal(t, utc("2025-07-01 22:30"), departs)
+		assert.Equal(t, utc("2025-07-01 22:45"), arrives)
+	})
+
+	t.Run("Skipped departure time", func(t *testing.T) {
+		// London clocks go from 01:00 to 02:00 on 30 March 2025
+		_, _, err := Sailing(date("2025-03-30"), clock("01:30"), clock("05:00"), london, paris)
+		assert.ErrorIs(t, err, ErrNonexistentTime)
+	})
+
+	t.Run("Skipped arrival time", func(t *testing.T) {
+		// Paris clocks go from 02:00 to 03:00 on 30 March 2025
+		_, _, err := Sailing(date("2025-03-29"), clock("23:00"), clock("02:30"), london, paris)
+		assert.ErrorIs(t, err, ErrNonexistentTime)
+	})
+}
+
+func TestLocalize(t *testing.T) {
+	schedule := &models.Schedule{
+		DepartureAt: utc("2025-07-01 21:00"),
+		ArrivalAt:   utc("2025-07-02 04:00"),
+	}
+
+	Localize(schedule, "Europe/London", "Europe/Paris")
+	require.NotNil(t, schedule.DepartureLocal)
+	require.NotNil(t, schedule.ArrivalLocal)
+	assert.Equal(t, "2025-07-01T22:00:00+01:00", schedule.DepartureLocal.Format(time.RFC3339))
+	assert.Equal(t, "2025-07-02T06:00:00+02:00", schedule.ArrivalLocal.Format(time.RFC3339))
+	assert.Equal(t, "Europe/Paris", schedule.ArrivalTimezone)
+	assert.Nil(t, schedule.EstimatedDepartureLocal)
+
+	delayedDeparture := utc("2025-07-01 23:30")
+	delayedArrival := utc("2025-07-02 06:30")
+	schedule.EstimatedDepartureAt = &delayedDeparture
+	schedule.EstimatedArrivalAt = &delayedArrival
+	Localize(schedule, "Europe/London", "Europe/Paris")
+	require.NotNil(t, schedule.EstimatedDepartureLocal)
+	require.NotNil(t, schedule.EstimatedArrivalLocal)
+	assert.Equal(t, "2025-07-02T00:30:00+01:00", schedule.EstimatedDepartureLocal.Format(time.RFC3339))
+	assert.Equal(t, "2025-07-02T08:30:00+02:00", schedule.EstimatedArrivalLocal.Format(time.RFC3339))
+
+	unknown := &models.Schedule{DepartureAt: utc("2025-07-01 21:00")}
+	Localize(unknown, "Nowhere/Unknown", "Europe/Paris")
+	assert.Nil(t, unknown.DepartureLocal)
+	assert.Nil(t, unknown.ArrivalLocal)
+}
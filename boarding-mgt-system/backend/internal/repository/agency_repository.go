@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/agency"
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type AgencyRepository interface {
+	Create(ctx context.Context, agency *models.Agency) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Agency, error)
+	Update(ctx context.Context, agency *models.Agency) error
+	List(ctx context.Context, filter *models.AgencyFilter) ([]*models.Agency, int, error)
+
+	// Commission rules
+	CreateCommissionRule(ctx context.Context, rule *models.AgencyCommissionRule) error
+	DeleteCommissionRule(ctx context.Context, agencyID, ruleID uuid.UUID) error
+	ListCommissionRules(ctx context.Context, agencyID uuid.UUID) ([]models.AgencyCommissionRule, error)
+
+	// Agents
+	AssignAgent(ctx context.Context, agencyID, userID uuid.UUID) error
+	RemoveAgent(ctx context.Context, agencyID, userID uuid.UUID) error
+
+	// Account
+	PostTransactions(ctx context.Context, agencyID uuid.UUID, txns []*models.AgencyTransaction, enforceLimit bool) error
+	GetBalanceAt(ctx context.Context, agencyID uuid.UUID, at time.Time) (money.Money, error)
+	ListTransactions(ctx context.Context, agencyID uuid.UUID, start, end time.Time) ([]models.AgencyTransaction, error)
+}
+
+type agencyRepository struct {
+	db *database.DB
+}
+
+func NewAgencyRepository(db *database.DB) AgencyRepository {
+	return &agencyRepository{db: db}
+}
+
+const agencyColumns = `
+	id, operator_id, code, name, contact_email, contact_phone, account_type,
+	credit_limit, balance, default_commission_rate, is_active, created_at, updated_at
+`
+
+func scanAgency(row pgx.Row) (*models.Agency, error) {
+	a := &models.Agency{}
+	err := row.Scan(
+		&a.ID, &a.OperatorID, &a.Code, &a.Name, &a.ContactEmail, &a.ContactPhone, &a.AccountType,
+		&a.CreditLimit, &a.Balance, &a.DefaultCommissionRate, &a.IsActive, &a.CreatedAt, &a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	a.AvailableCredit = agency.Available(a)
+	return a, nil
+}
+
+func (r *agencyRepository) Create(ctx context.Context, a *models.Agency) error {
+	query := `
+		INSERT INTO agencies (
+			operator_id, code, name, contact_email, contact_phone,
+			account_type, credit_limit, default_commission_rate
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, balance, is_active, created_at, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		a.OperatorID, a.Code, a.Name, a.ContactEmail, a.ContactPhone,
+		a.AccountType, a.CreditLimit, a.DefaultCommissionRate,
+	).Scan(&a.ID, &a.Balance, &a.IsActive, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create agency: %w", err)
+	}
+
+	a.AvailableCredit = agency.Available(a)
+	return nil
+}
+
+func (r *agencyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Agency, error) {
+	query := `SELECT ` + agencyColumns + ` FROM agencies WHERE id = $1`
+
+	a, err := scanAgency(r.db.Pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("agency not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agency: %w", err)
+	}
+
+	return a, nil
+}
+
+// Update saves an agency's details. The balance is only changed by posting
+// transactions.
+func (r *agencyRepository) Update(ctx context.Context, a *models.Agency) error {
+	query := `
+		UPDATE agencies SET
+			name = $2,
+			contact_email = $3,
+			contact_phone = $4,
+			account_type = $5,
+			credit_limit = $6,
+			default_commission_rate = $7,
+			is_active = $8,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING balance, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		a.ID, a.Name, a.ContactEmail, a.ContactPhone, a.AccountType,
+		a.CreditLimit, a.DefaultCommissionRate, a.IsActive,
+	).Scan(&a.Balance, &a.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("agency not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update agency: %w", err)
+	}
+
+	a.AvailableCredit = agency.Available(a)
+	return nil
+}
+
+func (r *agencyRepository) List(ctx context.Context, filter *models.AgencyFilter) ([]*models.Agency, int, error) {
+	query := `SELECT ` + agencyColumns + ` FROM agencies WHERE 1=1`
+	countQuery := `SELECT COUNT(*) FROM agencies WHERE 1=1`
+
+	args := []interface{}{}
+	argCount := 0
+
+	if filter.OperatorID != nil {
+		argCount++
+		query += fmt.Sprintf(" AND operator_id = $%d", argCount)
+		countQuery += fmt.Sprintf(" AND operator_id = $%d", argCount)
+		args = append(args, *filter.OperatorID)
+	}
+
+	if filter.IsActive != nil {
+		argCount++
+		query += fmt.Sprintf(" AND is_active = $%d", argCount)
+		countQuery += fmt.Sprintf(" AND is_active = $%d", argCount)
+		args = append(args, *filter.IsActive)
+	}
+
+	// Agencies that owe the operator money
+	if filter.Outstanding {
+		query += " AND balance < 0"
+		countQuery += " AND balance < 0"
+	}
+
+	var totalCount int
+	if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count agencies: %w", err)
+	}
+
+	if filter.Outstanding {
+		query += " ORDER BY balance ASC, name ASC"
+	} else {
+		query += " ORDER BY name ASC"
+	}
+	if filter.Limit > 0 {
+		argCount++
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filter.Limit)
+	}
+	if filter.Offset > 0 {
+		argCount++
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list agencies: %w", err)
+	}
+	defer rows.Close()
+
+	agencies := []*models.Agency{}
+	for rows.Next() {
+		a, err := scanAgency(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan agency: %w", err)
+		}
+		agencies = append(agencies, a)
+	}
+
+	return agencies, totalCount, nil
+}
+
+func (r *agencyRepository) CreateCommissionRule(ctx context.Context, rule *models.AgencyCommissionRule) error {
+	query := `
+		INSERT INTO agency_commission_rules (agency_id, route_id, fare_class, commission_rate)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		rule.AgencyID, rule.RouteID, rule.FareClass, rule.CommissionRate,
+	).Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create commission rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *agencyRepository) DeleteCommissionRule(ctx context.Context, agencyID, ruleID uuid.UUID) error {
+	query := `DELETE FROM agency_commission_rules WHERE id = $1 AND agency_id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, ruleID, agencyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete commission rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("commission rule not found")
+	}
+
+	return nil
+}
+
+func (r *agencyRepository) ListCommissionRules(ctx context.Context, agencyID uuid.UUID) ([]models.AgencyCommissionRule, error) {
+	query := `
+		SELECT id, agency_id, route_id, fare_class, commission_rate, created_at
+		FROM agency_commission_rules
+		WHERE agency_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, agencyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commission rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.AgencyCommissionRule{}
+	for rows.Next() {
+		var rule models.AgencyCommissionRule
+		err := rows.Scan(
+			&rule.ID, &rule.AgencyID, &rule.RouteID, &rule.FareClass, &rule.CommissionRate, &rule.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan commission rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// AssignAgent links an agent of the agency's operator to the agency
+func (r *agencyRepository) AssignAgent(ctx context.Context, agencyID, userID uuid.UUID) error {
+	query := `
+		UPDATE users SET
+			agency_id = $1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+			AND user_type = 'agent'
+			AND operator_id = (SELECT operator_id FROM agencies WHERE id = $1)
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, agencyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to assign agent: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("agent not found for the agency's operator")
+	}
+
+	return nil
+}
+
+func (r *agencyRepository) RemoveAgent(ctx context.Context, agencyID, userID uuid.UUID) error {
+	query := `
+		UPDATE users SET
+			agency_id = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND agency_id = $1
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, agencyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove agent: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("agent not found in agency")
+	}
+
+	return nil
+}
+
+// PostTransactions appends movements to an agency's account and moves its
+// balance. The agency row is locked for the transaction so concurrent
+// bookings cannot both spend the same credit. With enforceLimit, movements
+// that charge the agency more than its available credit are rejected.
+func (r *agencyRepository) PostTransactions(ctx context.Context, agencyID uuid.UUID, txns []*models.AgencyTransaction, enforceLimit bool) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `SELECT ` + agencyColumns + ` FROM agencies WHERE id = $1 FOR UPDATE`
+
+	a, err := scanAgency(tx.QueryRow(ctx, query, agencyID))
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("agency not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock agency: %w", err)
+	}
+
+	var net money.Money
+	for _, txn := range txns {
+		net = net.Add(txn.Amount)
+	}
+	if enforceLimit && net.IsNegative() {
+		if err := agency.CheckCredit(a, net.Neg()); err != nil {
+			return err
+		}
+	}
+
+	insertQuery := `
+		INSERT INTO agency_transactions (
+			agency_id, booking_id, transaction_type, amount, balance_after,
+			reference, description, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	balance := a.Balance
+	for _, txn := range txns {
+		balance = balance.Add(txn.Amount)
+		txn.AgencyID = agencyID
+		txn.BalanceAfter = balance
+
+		err := tx.QueryRow(ctx, insertQuery,
+			txn.AgencyID, txn.BookingID, txn.TransactionType, txn.Amount, txn.BalanceAfter,
+			txn.Reference, txn.Description, txn.CreatedBy,
+		).Scan(&txn.ID, &txn.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create agency transaction: %w", err)
+		}
+	}
+
+	updateQuery := `
+		UPDATE agencies SET
+			balance = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	if _, err := tx.Exec(ctx, updateQuery, agencyID, balance); err != nil {
+		return fmt.Errorf("failed to update agency balance: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit agency transactions: %w", err)
+	}
+
+	return nil
+}
+
+// GetBalanceAt returns an agency's balance before the given time
+func (r *agencyRepository) GetBalanceAt(ctx context.Context, agencyID uuid.UUID, at time.Time) (money.Money, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM agency_transactions
+		WHERE agency_id = $1 AND created_at < $2
+	`
+
+	var balance money.Money
+	if err := r.db.Pool.QueryRow(ctx, query, agencyID, at).Scan(&balance); err != nil {
+		return money.Money{}, fmt.Errorf("failed to get agency balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// ListTransactions lists an agency's account movements in [start, end), oldest first
+func (r *agencyRepository) ListTransactions(ctx context.Context, agencyID uuid.UUID, start, end time.Time) ([]models.AgencyTransaction, error) {
+	query := `
+		SELECT
+			t.id, t.agency_id, t.booking_id, t.transaction_type, t.amount, t.balance_after,
+			t.reference, t.description, t.created_by, t.created_at, b.booking_reference
+		FROM agency_transactions t
+		LEFT JOIN bookings b ON t.booking_id = b.id
+		WHERE t.agency_id = $1 AND t.created_at >= $2 AND t.created_at < $3
+		ORDER BY t.created_at ASC, t.id ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, agencyID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agency transactions: %w", err)
+	}
+	defer rows.Close()
+
+	txns := []models.AgencyTransaction{}
+	for rows.Next() {
+		var txn models.AgencyTransaction
+		err := rows.Scan(
+			&txn.ID, &txn.AgencyID, &txn.BookingID, &txn.TransactionType, &txn.Amount, &txn.BalanceAfter,
+			&txn.Reference, &txn.Description, &txn.CreatedBy, &txn.CreatedAt, &txn.BookingReference,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan agency transaction: %w", err)
+		}
+		txns = append(txns, txn)
+	}
+
+	return txns, nil
+}
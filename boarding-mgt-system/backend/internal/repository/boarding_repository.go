@@ -0,0 +1,430 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/gate"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/scansync"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type BoardingRepository interface {
+	ApplyScan(ctx context.Context, scan *models.BoardingScan, policy gate.Policy, override bool) error
+	RecordScan(ctx context.Context, scan *models.BoardingScan) error
+	MergeDeviceScan(ctx context.Context, scan *models.BoardingScan, policy gate.Policy) (string, error)
+	ListScans(ctx context.Context, filter *models.BoardingScanFilter) ([]*models.BoardingScan, int, error)
+	GetBoardingCounts(ctx context.Context, scheduleID uuid.UUID) (*models.BoardingCounts, error)
+	ListPortBoardingCounts(ctx context.Context, portID uuid.UUID, operatorID *uuid.UUID, date time.Time) ([]*models.BoardingCounts, error)
+}
+
+type boardingRepository struct {
+	db *database.DB
+}
+
+func NewBoardingRepository(db *database.DB) BoardingRepository {
+	return &boardingRepository{db: db}
+}
+
+// ApplyScan decides a scan of scan.TicketID at scan.ScheduleID's gate while
+// holding the ticket row, moves the ticket on when the scan is accepted or
+// overridden and logs the scan. A denied scan is logged, not returned as an
+// error.
+func (r *boardingRepository) ApplyScan(ctx context.Context, scan *models.BoardingScan, policy gate.Policy, override bool) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT
+			t.check_in_status, t.passenger_name, t.passenger_type, t.seat_number,
+			b.schedule_id, b.booking_reference, b.booking_status, b.payment_status,
+			s.status, COALESCE(s.estimated_departure_at, s.departure_at),
+			CURRENT_TIMESTAMP
+		FROM tickets t
+		JOIN bookings b ON t.booking_id = b.id
+		JOIN schedules s ON b.schedule_id = s.id
+		WHERE t.id = $1
+		FOR UPDATE OF t
+	`
+
+	var ticket gate.Ticket
+	var now time.Time
+	err = tx.QueryRow(ctx, query, scan.TicketID).Scan(
+		&ticket.CheckInStatus, &scan.PassengerName, &scan.PassengerType, &scan.SeatNumber,
+		&ticket.ScheduleID, &scan.BookingReference, &ticket.BookingStatus, &ticket.PaymentStatus,
+		&ticket.ScheduleStatus, &ticket.Departure, &now,
+	)
+	if err == pgx.ErrNoRows {
+		scan.TicketID = nil
+		denyScan(scan, gate.ReasonTicketNotFound, nil)
+		if err := insertScan(ctx, tx, scan); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock ticket: %w", err)
+	}
+
+	decision := policy.Evaluate(ticket, scan.Action, scan.ScheduleID, now)
+	status := decision.Status
+
+	switch {
+	case decision.Allowed():
+		scan.Result = gate.ResultAccepted
+		scan.OverrideReason = nil
+	case override && gate.Overridable(decision.Reason):
+		scan.Result = gate.ResultOverridden
+		scan.DenyReason = &decision.Reason
+		status = gate.StatusAfter(scan.Action)
+	default:
+		denyScan(scan, decision.Reason, &status)
+	}
+
+	if scan.Result != gate.ResultDenied {
+		updateQuery := `
+			UPDATE tickets SET
+				check_in_status = $2,
+				check_in_time = COALESCE(check_in_time, CURRENT_TIMESTAMP),
+				boarding_time = CASE WHEN $2 = 'boarded' THEN CURRENT_TIMESTAMP ELSE boarding_time END,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+		if _, err := tx.Exec(ctx, updateQuery, scan.TicketID, status); err != nil {
+			return fmt.Errorf("failed to update ticket: %w", err)
+		}
+	}
+	scan.CheckInStatus = &status
+
+	if err := insertScan(ctx, tx, scan); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RecordScan logs a scan that was refused before a ticket could be looked up
+func (r *boardingRepository) RecordScan(ctx context.Context, scan *models.BoardingScan) error {
+	return insertScan(ctx, r.db.Pool, scan)
+}
+
+// MergeDeviceScan merges a scan uploaded by a scanner device into its
+// ticket while holding the ticket row and logs it with the merge's outcome.
+// It returns the upload status: duplicate when the device already uploaded
+// scan.EventID, in which case scan is filled from the logged scan, and
+// rejected when the ticket does not exist.
+func (r *boardingRepository) MergeDeviceScan(ctx context.Context, scan *models.BoardingScan, policy gate.Policy) (string, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The ticket's boarding is credited to the device of its earliest
+	// boarding scan
+	query := `
+		SELECT
+			t.check_in_status, t.boarding_time, t.passenger_name, t.passenger_type, t.seat_number,
+			b.schedule_id, b.booking_reference, b.booking_status, b.payment_status,
+			s.status, COALESCE(s.estimated_departure_at, s.departure_at),
+			(
+				SELECT bs.scanner_device_id FROM boarding_scans bs
+				WHERE bs.ticket_id = t.id AND bs.action = 'board' AND bs.result <> 'denied'
+				ORDER BY COALESCE(bs.device_scanned_at, bs.created_at)
+				LIMIT 1
+			)
+		FROM tickets t
+		JOIN bookings b ON t.booking_id = b.id
+		JOIN schedules s ON b.schedule_id = s.id
+		WHERE t.id = $1
+		FOR UPDATE OF t
+	`
+
+	var ticket scansync.Ticket
+	err = tx.QueryRow(ctx, query, scan.TicketID).Scan(
+		&ticket.CheckInStatus, &ticket.BoardedAt, &scan.PassengerName, &scan.PassengerType, &scan.SeatNumber,
+		&ticket.ScheduleID, &scan.BookingReference, &ticket.BookingStatus, &ticket.PaymentStatus,
+		&ticket.ScheduleStatus, &ticket.Departure, &ticket.BoardedBy,
+	)
+	if err == pgx.ErrNoRows {
+		return scansync.StatusRejected, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock ticket: %w", err)
+	}
+
+	duplicateQuery := `
+		SELECT id, result, deny_reason, check_in_status, conflict, conflict_detail, created_at
+		FROM boarding_scans
+		WHERE scanner_device_id = $1 AND event_id = $2
+	`
+	err = tx.QueryRow(ctx, duplicateQuery, scan.ScannerDeviceID, scan.EventID).Scan(
+		&scan.ID, &scan.Result, &scan.DenyReason, &scan.CheckInStatus, &scan.Conflict,
+		&scan.ConflictDetail, &scan.CreatedAt,
+	)
+	if err == nil {
+		return scansync.StatusDuplicate, nil
+	}
+	if err != pgx.ErrNoRows {
+		return "", fmt.Errorf("failed to check for duplicate scan: %w", err)
+	}
+
+	event := scansync.Event{
+		DeviceID:   *scan.ScannerDeviceID,
+		ScheduleID: scan.ScheduleID,
+		Action:     scan.Action,
+		Result:     scan.Result,
+		ScannedAt:  *scan.DeviceScannedAt,
+	}
+	if scan.DenyReason != nil {
+		event.DenyReason = *scan.DenyReason
+	}
+	outcome := scansync.Merge(ticket, event, policy)
+
+	if outcome.Changed {
+		updateQuery := `
+			UPDATE tickets SET
+				check_in_status = $2,
+				check_in_time = COALESCE(check_in_time, $3),
+				boarding_time = COALESCE($4, boarding_time),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+		_, err := tx.Exec(ctx, updateQuery, scan.TicketID, outcome.Status, scan.DeviceScannedAt, outcome.BoardedAt)
+		if err != nil {
+			return "", fmt.Errorf("failed to update ticket: %w", err)
+		}
+	}
+
+	scan.Result = outcome.Result
+	scan.DenyReason = nil
+	if outcome.DenyReason != "" {
+		scan.DenyReason = &outcome.DenyReason
+	}
+	scan.CheckInStatus = &outcome.Status
+	if outcome.Conflict != "" {
+		scan.Conflict = &outcome.Conflict
+		scan.ConflictDetail = &outcome.ConflictDetail
+	}
+
+	if err := insertScan(ctx, tx, scan); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return scansync.StatusApplied, nil
+}
+
+func (r *boardingRepository) ListScans(ctx context.Context, filter *models.BoardingScanFilter) ([]*models.BoardingScan, int, error) {
+	query := `
+		SELECT
+			bs.id, bs.ticket_id, bs.schedule_id, bs.action, bs.result, bs.deny_reason,
+			bs.check_in_status, bs.gate, bs.device_id, bs.scanned_by, bs.override_reason,
+			bs.created_at, bs.scanner_device_id, bs.event_id, bs.device_scanned_at, bs.conflict,
+			bs.conflict_detail, t.passenger_name, t.passenger_type, t.seat_number, b.booking_reference
+		FROM boarding_scans bs
+		LEFT JOIN tickets t ON bs.ticket_id = t.id
+		LEFT JOIN bookings b ON t.booking_id = b.id
+		WHERE bs.schedule_id = $1
+	`
+	countQuery := `SELECT COUNT(*) FROM boarding_scans bs WHERE bs.schedule_id = $1`
+
+	args := []interface{}{filter.ScheduleID}
+	argCount := 1
+	conditions := ""
+
+	if filter.Result != "" {
+		argCount++
+		conditions += fmt.Sprintf(" AND bs.result = $%d", argCount)
+		args = append(args, filter.Result)
+	}
+
+	if filter.Gate != "" {
+		argCount++
+		conditions += fmt.Sprintf(" AND bs.gate = $%d", argCount)
+		args = append(args, filter.Gate)
+	}
+
+	if filter.TicketID != nil {
+		argCount++
+		conditions += fmt.Sprintf(" AND bs.ticket_id = $%d", argCount)
+		args = append(args, *filter.TicketID)
+	}
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, countQuery+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count boarding scans: %w", err)
+	}
+
+	query += conditions + " ORDER BY bs.created_at DESC, bs.id"
+
+	if filter.Limit > 0 {
+		argCount++
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		argCount++
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list boarding scans: %w", err)
+	}
+	defer rows.Close()
+
+	scans := []*models.BoardingScan{}
+	for rows.Next() {
+		scan := &models.BoardingScan{}
+		var passengerName, passengerType, bookingReference *string
+		err := rows.Scan(
+			&scan.ID, &scan.TicketID, &scan.ScheduleID, &scan.Action, &scan.Result, &scan.DenyReason,
+			&scan.CheckInStatus, &scan.Gate, &scan.DeviceID, &scan.ScannedBy, &scan.OverrideReason,
+			&scan.CreatedAt, &scan.ScannerDeviceID, &scan.EventID, &scan.DeviceScannedAt, &scan.Conflict,
+			&scan.ConflictDetail, &passengerName, &passengerType, &scan.SeatNumber, &bookingReference,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan boarding scan: %w", err)
+		}
+		if passengerName != nil {
+			scan.PassengerName = *passengerName
+		}
+		if passengerType != nil {
+			scan.PassengerType = *passengerType
+		}
+		if bookingReference != nil {
+			scan.BookingReference = *bookingReference
+		}
+		scans = append(scans, scan)
+	}
+
+	return scans, total, nil
+}
+
+// boardingCountsQuery counts each sailing's confirmed passengers by how far
+// they have got through the gate
+const boardingCountsQuery = `
+	SELECT
+		s.id, s.operator_id, rt.departure_port_id,
+		COALESCE(s.estimated_departure_at, s.departure_at),
+		s.status, s.total_capacity,
+		COUNT(t.id),
+		COUNT(t.id) FILTER (WHERE t.check_in_status IN ('checked_in', 'boarded')),
+		COUNT(t.id) FILTER (WHERE t.check_in_status = 'boarded')
+	FROM schedules s
+	JOIN routes rt ON s.route_id = rt.id
+	LEFT JOIN bookings b ON b.schedule_id = s.id AND b.booking_status = 'confirmed'
+	LEFT JOIN tickets t ON t.booking_id = b.id
+`
+
+func scanBoardingCounts(row pgx.Row) (*models.BoardingCounts, error) {
+	counts := &models.BoardingCounts{}
+	err := row.Scan(
+		&counts.ScheduleID, &counts.OperatorID, &counts.PortID, &counts.Departure,
+		&counts.Status, &counts.Capacity, &counts.TotalPassengers, &counts.CheckedIn, &counts.Boarded,
+	)
+	return counts, err
+}
+
+// GetBoardingCounts gets a sailing's check-in and boarding counts
+func (r *boardingRepository) GetBoardingCounts(ctx context.Context, scheduleID uuid.UUID) (*models.BoardingCounts, error) {
+	query := boardingCountsQuery + `
+		WHERE s.id = $1
+		GROUP BY s.id, rt.departure_port_id
+	`
+
+	counts, err := scanBoardingCounts(r.db.Pool.QueryRow(ctx, query, scheduleID))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boarding counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ListPortBoardingCounts gets the counts of the sailings leaving a port on a
+// date that have not been cancelled, optionally for one operator
+func (r *boardingRepository) ListPortBoardingCounts(ctx context.Context, portID uuid.UUID, operatorID *uuid.UUID, date time.Time) ([]*models.BoardingCounts, error) {
+	query := boardingCountsQuery + `
+		WHERE rt.departure_port_id = $1
+			AND s.departure_date = $2
+			AND s.status <> 'cancelled'
+	`
+	args := []interface{}{portID, date}
+
+	if operatorID != nil {
+		query += ` AND s.operator_id = $3`
+		args = append(args, *operatorID)
+	}
+	query += ` GROUP BY s.id, rt.departure_port_id ORDER BY s.departure_at, s.id`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list boarding counts: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.BoardingCounts{}
+	for rows.Next() {
+		counts, err := scanBoardingCounts(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan boarding counts: %w", err)
+		}
+		list = append(list, counts)
+	}
+
+	return list, nil
+}
+
+// denyScan marks a scan refused for reason
+func denyScan(scan *models.BoardingScan, reason string, status *string) {
+	scan.Result = gate.ResultDenied
+	scan.DenyReason = &reason
+	scan.CheckInStatus = status
+	scan.OverrideReason = nil
+}
+
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertScan(ctx context.Context, db queryRower, scan *models.BoardingScan) error {
+	query := `
+		INSERT INTO boarding_scans (
+			ticket_id, schedule_id, action, result, deny_reason, check_in_status,
+			gate, device_id, scanned_by, override_reason, scanner_device_id, event_id,
+			device_scanned_at, conflict, conflict_detail
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, created_at
+	`
+
+	err := db.QueryRow(ctx, query,
+		scan.TicketID, scan.ScheduleID, scan.Action, scan.Result, scan.DenyReason, scan.CheckInStatus,
+		scan.Gate, scan.DeviceID, scan.ScannedBy, scan.OverrideReason, scan.ScannerDeviceID, scan.EventID,
+		scan.DeviceScannedAt, scan.Conflict, scan.ConflictDetail,
+	).Scan(&scan.ID, &scan.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record boarding scan: %w", err)
+	}
+
+	return nil
+}
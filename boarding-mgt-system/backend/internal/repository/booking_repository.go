@@ -43,8 +43,9 @@ func (r *bookingRepository) Create(ctx context.Context, booking *models.Booking)
 		INSERT INTO bookings (
 			booking_reference, schedule_id, customer_id, passenger_count,
 			total_amount, booking_status, payment_status, booking_channel,
-			special_requirements, booking_agent_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			special_requirements, booking_agent_id, shift_id, agency_id,
+			commission_amount
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, created_at, updated_at
 	`
 	
@@ -52,7 +53,8 @@ func (r *bookingRepository) Create(ctx context.Context, booking *models.Booking)
 		booking.BookingReference, booking.ScheduleID, booking.CustomerID,
 		booking.PassengerCount, booking.TotalAmount, booking.BookingStatus,
 		booking.PaymentStatus, booking.BookingChannel, booking.SpecialRequirements,
-		booking.BookingAgentID,
+		booking.BookingAgentID, booking.ShiftID, booking.AgencyID,
+		booking.CommissionAmount,
 	).Scan(&booking.ID, &booking.CreatedAt, &booking.UpdatedAt)
 	
 	if err != nil {
@@ -73,8 +75,8 @@ func (r *bookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 			b.id, b.booking_reference, b.schedule_id, b.customer_id,
 			b.passenger_count, b.total_amount, b.booking_status, b.payment_status,
 			b.booking_channel, b.special_requirements, b.booking_agent_id,
-			b.created_at, b.updated_at,
-			s.id, s.departure_date, s.departure_time, s.arrival_time, s.base_price,
+			b.shift_id, b.agency_id, b.commission_amount, b.created_at, b.updated_at,
+			s.id, s.operator_id, s.departure_date, s.departure_time, s.arrival_time, s.base_price,
 			u.id, u.email, u.first_name, u.last_name, u.phone
 		FROM bookings b
 		LEFT JOIN schedules s ON b.schedule_id = s.id
@@ -94,8 +96,8 @@ func (r *bookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 		&booking.ID, &booking.BookingReference, &booking.ScheduleID, &booking.CustomerID,
 		&booking.PassengerCount, &booking.TotalAmount, &booking.BookingStatus,
 		&booking.PaymentStatus, &booking.BookingChannel, &specialReq, &agentID,
-		&booking.CreatedAt, &booking.UpdatedAt,
-		&schedule.ID, &schedule.DepartureDate, &schedule.DepartureTime, &schedule.ArrivalTime, &schedule.BasePrice,
+		&booking.ShiftID, &booking.AgencyID, &booking.CommissionAmount, &booking.CreatedAt, &booking.UpdatedAt,
+		&schedule.ID, &schedule.OperatorID, &schedule.DepartureDate, &schedule.DepartureTime, &schedule.ArrivalTime, &schedule.BasePrice,
 		&customer.ID, &customer.Email, &customer.FirstName, &customer.LastName, &phone,
 	)
 	
@@ -338,6 +340,8 @@ func (r *bookingRepository) GetCustomerBookings(ctx context.Context, customerID
 	return bookings, nil
 }
 
+// GetScheduleBookings lists a sailing's confirmed bookings and the pending
+// ones something has already been paid on
 func (r *bookingRepository) GetScheduleBookings(ctx context.Context, scheduleID uuid.UUID) ([]*models.Booking, error) {
 	query := `
 		SELECT 
@@ -346,7 +350,8 @@ func (r *bookingRepository) GetScheduleBookings(ctx context.Context, scheduleID
 			booking_channel, special_requirements, booking_agent_id,
 			created_at, updated_at
 		FROM bookings
-		WHERE schedule_id = $1 AND booking_status = 'confirmed'
+		WHERE schedule_id = $1
+			AND (booking_status = 'confirmed' OR (booking_status = 'pending' AND payment_status = 'partially_paid'))
 		ORDER BY created_at ASC
 	`
 	
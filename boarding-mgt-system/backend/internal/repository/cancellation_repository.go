@@ -0,0 +1,464 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type CancellationRepository interface {
+	Start(ctx context.Context, scheduleID uuid.UUID, reason string, startedBy *uuid.UUID) (*models.ScheduleCancellation, error)
+	Get(ctx context.Context, scheduleID uuid.UUID) (*models.ScheduleCancellation, error)
+	Finish(ctx context.Context, id uuid.UUID, failure error) error
+	ListAlternatives(ctx context.Context, routeID uuid.UUID, from, to time.Time) ([]*models.Schedule, error)
+	CancelBooking(ctx context.Context, offer *models.CancellationOffer) (bool, error)
+	GetOffer(ctx context.Context, id uuid.UUID) (*models.CancellationOffer, error)
+	ListOffers(ctx context.Context, cancellationID uuid.UUID) ([]*models.CancellationOffer, error)
+	ListCustomerOffers(ctx context.Context, customerID uuid.UUID) ([]*models.CancellationOffer, error)
+	ListLapsedOffers(ctx context.Context, now time.Time) ([]*models.CancellationOffer, error)
+	Claim(ctx context.Context, offerID uuid.UUID, status string, chosenBy *uuid.UUID) error
+	Release(ctx context.Context, offerID uuid.UUID) error
+	Rebook(ctx context.Context, offerID, scheduleID uuid.UUID, chosenBy *uuid.UUID, ticketCodes map[uuid.UUID]string) error
+	IssueCredits(ctx context.Context, offerID uuid.UUID, chosenBy *uuid.UUID, credits []*models.TravelCredit) error
+}
+
+type cancellationRepository struct {
+	db *database.DB
+}
+
+func NewCancellationRepository(db *database.DB) CancellationRepository {
+	return &cancellationRepository{db: db}
+}
+
+const cancellationColumns = `
+	id, schedule_id, reason, status, started_by, last_error, started_at, completed_at, updated_at
+`
+
+func scanCancellation(row pgx.Row) (*models.ScheduleCancellation, error) {
+	run := &models.ScheduleCancellation{}
+	err := row.Scan(
+		&run.ID, &run.ScheduleID, &run.Reason, &run.Status, &run.StartedBy,
+		&run.LastError, &run.StartedAt, &run.CompletedAt, &run.UpdatedAt,
+	)
+	return run, err
+}
+
+// Start cancels a sailing and its unpaid pending bookings and opens, or
+// reopens, the run that cancels its confirmed and part-paid bookings. A
+// sailing that has already departed cannot be cancelled.
+func (r *cancellationRepository) Start(ctx context.Context, scheduleID uuid.UUID, reason string, startedBy *uuid.UUID) (*models.ScheduleCancellation, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM schedules WHERE id = $1 FOR UPDATE`, scheduleID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	if status == "departed" || status == "arrived" {
+		return nil, fmt.Errorf("a sailing that has departed cannot be cancelled")
+	}
+
+	if status != "cancelled" {
+		_, err = tx.Exec(ctx, `
+			UPDATE schedules SET
+				status = 'cancelled',
+				cancellation_reason = $2,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, scheduleID, reason)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel schedule: %w", err)
+		}
+	}
+
+	// Nothing has been collected on these, so there is nothing to offer.
+	// Part-paid bookings are left for the run, which refunds their deposit.
+	_, err = tx.Exec(ctx, `
+		UPDATE bookings SET booking_status = 'cancelled', updated_at = CURRENT_TIMESTAMP
+		WHERE schedule_id = $1 AND booking_status = 'pending' AND payment_status IN ('pending', 'failed')
+	`, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel pending bookings: %w", err)
+	}
+
+	run, err := scanCancellation(tx.QueryRow(ctx, `
+		INSERT INTO schedule_cancellations (schedule_id, reason, started_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (schedule_id) DO UPDATE SET
+			status = 'in_progress',
+			last_error = NULL,
+			completed_at = NULL
+		RETURNING `+cancellationColumns,
+		scheduleID, reason, startedBy,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start schedule cancellation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return run, nil
+}
+
+func (r *cancellationRepository) Get(ctx context.Context, scheduleID uuid.UUID) (*models.ScheduleCancellation, error) {
+	query := `SELECT ` + cancellationColumns + ` FROM schedule_cancellations WHERE schedule_id = $1`
+
+	run, err := scanCancellation(r.db.Pool.QueryRow(ctx, query, scheduleID))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule cancellation not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule cancellation: %w", err)
+	}
+
+	return run, nil
+}
+
+// Finish marks a run completed, or failed with the error that stopped it
+func (r *cancellationRepository) Finish(ctx context.Context, id uuid.UUID, failure error) error {
+	status := "completed"
+	var lastError *string
+	if failure != nil {
+		status = "failed"
+		message := failure.Error()
+		lastError = &message
+	}
+
+	query := `
+		UPDATE schedule_cancellations SET
+			status = $2,
+			last_error = $3,
+			completed_at = CASE WHEN $2 = 'completed' THEN CURRENT_TIMESTAMP END
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, id, status, lastError); err != nil {
+		return fmt.Errorf("failed to update schedule cancellation: %w", err)
+	}
+
+	return nil
+}
+
+// ListAlternatives lists the scheduled sailings of a route that are on sale
+// and depart between two instants
+func (r *cancellationRepository) ListAlternatives(ctx context.Context, routeID uuid.UUID, from, to time.Time) ([]*models.Schedule, error) {
+	query := `
+		SELECT id, operator_id, route_id, vessel_id, departure_date, departure_time, arrival_time,
+			departure_at, arrival_at, base_price, total_capacity, available_seats, status
+		FROM schedules
+		WHERE route_id = $1 AND status = 'scheduled' AND NOT unpublished AND departure_at BETWEEN $2 AND $3
+		ORDER BY departure_at
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, routeID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alternative sailings: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []*models.Schedule{}
+	for rows.Next() {
+		s := &models.Schedule{}
+		err := rows.Scan(
+			&s.ID, &s.OperatorID, &s.RouteID, &s.VesselID, &s.DepartureDate, &s.DepartureTime, &s.ArrivalTime,
+			&s.DepartureAt, &s.ArrivalAt, &s.BasePrice, &s.TotalCapacity, &s.AvailableSeats, &s.Status,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alternative sailing: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+
+	return schedules, nil
+}
+
+// CancelBooking cancels a confirmed or part-paid booking and records the
+// offer made for it in one step, so a run stopped partway resumes from the
+// next booking. A booking that is no longer either is left alone and false
+// is returned.
+func (r *cancellationRepository) CancelBooking(ctx context.Context, offer *models.CancellationOffer) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE bookings SET booking_status = 'cancelled', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+			AND (booking_status = 'confirmed' OR (booking_status = 'pending' AND payment_status = 'partially_paid'))
+	`, offer.BookingID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel booking: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO cancellation_offers (
+			cancellation_id, schedule_id, booking_id, customer_id, passenger_count,
+			amount_paid, alternative_schedule_ids, respond_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at
+	`,
+		offer.CancellationID, offer.ScheduleID, offer.BookingID, offer.CustomerID, offer.PassengerCount,
+		offer.AmountPaid, offer.Alternatives, offer.RespondBy,
+	).Scan(&offer.ID, &offer.Status, &offer.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to record cancellation offer: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+const offerColumns = `
+	o.id, o.cancellation_id, o.schedule_id, o.booking_id, b.booking_reference, o.customer_id,
+	o.passenger_count, o.amount_paid, o.alternative_schedule_ids, o.status, o.rebooked_schedule_id,
+	o.respond_by, o.chosen_by, o.chosen_at, o.created_at
+`
+
+func scanOffer(row pgx.Row) (*models.CancellationOffer, error) {
+	offer := &models.CancellationOffer{}
+	err := row.Scan(
+		&offer.ID, &offer.CancellationID, &offer.ScheduleID, &offer.BookingID, &offer.BookingReference,
+		&offer.CustomerID, &offer.PassengerCount, &offer.AmountPaid, &offer.Alternatives, &offer.Status,
+		&offer.RebookedScheduleID, &offer.RespondBy, &offer.ChosenBy, &offer.ChosenAt, &offer.CreatedAt,
+	)
+	return offer, err
+}
+
+func (r *cancellationRepository) GetOffer(ctx context.Context, id uuid.UUID) (*models.CancellationOffer, error) {
+	query := `
+		SELECT ` + offerColumns + `
+		FROM cancellation_offers o
+		JOIN bookings b ON o.booking_id = b.id
+		WHERE o.id = $1
+	`
+
+	offer, err := scanOffer(r.db.Pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("cancellation offer not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cancellation offer: %w", err)
+	}
+
+	return offer, nil
+}
+
+func (r *cancellationRepository) ListOffers(ctx context.Context, cancellationID uuid.UUID) ([]*models.CancellationOffer, error) {
+	query := `
+		SELECT ` + offerColumns + `
+		FROM cancellation_offers o
+		JOIN bookings b ON o.booking_id = b.id
+		WHERE o.cancellation_id = $1
+		ORDER BY o.created_at
+	`
+
+	return r.listOffers(ctx, query, cancellationID)
+}
+
+func (r *cancellationRepository) ListCustomerOffers(ctx context.Context, customerID uuid.UUID) ([]*models.CancellationOffer, error) {
+	query := `
+		SELECT ` + offerColumns + `
+		FROM cancellation_offers o
+		JOIN bookings b ON o.booking_id = b.id
+		WHERE o.customer_id = $1
+		ORDER BY o.created_at DESC
+	`
+
+	return r.listOffers(ctx, query, customerID)
+}
+
+// ListLapsedOffers lists the offers still waiting on a choice whose
+// response window closed at or before now, oldest first
+func (r *cancellationRepository) ListLapsedOffers(ctx context.Context, now time.Time) ([]*models.CancellationOffer, error) {
+	query := `
+		SELECT ` + offerColumns + `
+		FROM cancellation_offers o
+		JOIN bookings b ON o.booking_id = b.id
+		WHERE o.status = 'pending' AND o.respond_by <= $1
+		ORDER BY o.respond_by
+	`
+
+	return r.listOffers(ctx, query, now)
+}
+
+func (r *cancellationRepository) listOffers(ctx context.Context, query string, args ...interface{}) ([]*models.CancellationOffer, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cancellation offers: %w", err)
+	}
+	defer rows.Close()
+
+	offers := []*models.CancellationOffer{}
+	for rows.Next() {
+		offer, err := scanOffer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cancellation offer: %w", err)
+		}
+		offers = append(offers, offer)
+	}
+
+	return offers, nil
+}
+
+// execer is satisfied by both the pool and a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// claimOffer records the choice made on a pending offer
+func claimOffer(ctx context.Context, q execer, offerID uuid.UUID, status string, rebookedScheduleID, chosenBy *uuid.UUID) error {
+	result, err := q.Exec(ctx, `
+		UPDATE cancellation_offers SET
+			status = $2,
+			rebooked_schedule_id = $3,
+			chosen_by = $4,
+			chosen_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending'
+	`, offerID, status, rebookedScheduleID, chosenBy)
+	if err != nil {
+		return fmt.Errorf("failed to update cancellation offer: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("an option has already been chosen for this booking")
+	}
+	return nil
+}
+
+// Claim marks a pending offer with the outcome of the option chosen before
+// the option is carried out, so it cannot be taken up twice
+func (r *cancellationRepository) Claim(ctx context.Context, offerID uuid.UUID, status string, chosenBy *uuid.UUID) error {
+	return claimOffer(ctx, r.db.Pool, offerID, status, nil, chosenBy)
+}
+
+// Release puts a claimed offer back to pending when its option could not be
+// carried out
+func (r *cancellationRepository) Release(ctx context.Context, offerID uuid.UUID) error {
+	query := `
+		UPDATE cancellation_offers SET
+			status = 'pending',
+			rebooked_schedule_id = NULL,
+			chosen_by = NULL,
+			chosen_at = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, offerID); err != nil {
+		return fmt.Errorf("failed to release cancellation offer: %w", err)
+	}
+
+	return nil
+}
+
+// Rebook moves an offer's booking onto another sailing and confirms it again.
+// The seats are taken by the booking availability trigger, which refuses the
+// move when the sailing is full. Tickets get new codes and lose their seats.
+func (r *cancellationRepository) Rebook(ctx context.Context, offerID, scheduleID uuid.UUID, chosenBy *uuid.UUID, ticketCodes map[uuid.UUID]string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := claimOffer(ctx, tx, offerID, "rebooked", &scheduleID, chosenBy); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE bookings SET
+			schedule_id = $2,
+			booking_status = 'confirmed',
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT booking_id FROM cancellation_offers WHERE id = $1)
+	`, offerID, scheduleID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "P0001" {
+			return fmt.Errorf("the chosen sailing does not have enough seats")
+		}
+		return fmt.Errorf("failed to rebook booking: %w", err)
+	}
+
+	for ticketID, code := range ticketCodes {
+		_, err := tx.Exec(ctx, `
+			UPDATE tickets SET
+				qr_code = $2,
+				seat_number = NULL,
+				check_in_status = 'not_checked_in',
+				check_in_time = NULL,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, ticketID, code)
+		if err != nil {
+			return fmt.Errorf("failed to reissue ticket: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// IssueCredits marks an offer as credited and issues its travel credits
+func (r *cancellationRepository) IssueCredits(ctx context.Context, offerID uuid.UUID, chosenBy *uuid.UUID, credits []*models.TravelCredit) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := claimOffer(ctx, tx, offerID, "credited", nil, chosenBy); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO travel_credits (
+			operator_id, customer_id, booking_id, ticket_id, code, amount, reason, expires_at
+		) VALUES (
+			$1, $2, $3, $4, 'CR' || upper(substr(replace(gen_random_uuid()::text, '-', ''), 1, 10)),
+			$5, 'schedule_change', $6
+		)
+		RETURNING id, code, reason, status, created_at
+	`
+
+	for _, credit := range credits {
+		err := tx.QueryRow(ctx, query,
+			credit.OperatorID, credit.CustomerID, credit.BookingID, credit.TicketID,
+			credit.Amount, credit.ExpiresAt,
+		).Scan(&credit.ID, &credit.Code, &credit.Reason, &credit.Status, &credit.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to issue travel credit: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/config"
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelSailingWithPartPaidBooking(t *testing.T) {
+	cfg, err := config.LoadTest()
+	require.NoError(t, err, "Failed to load test config")
+
+	db, err := database.New(&cfg.Database)
+	require.NoError(t, err, "Failed to connect to database")
+	defer db.Close()
+
+	ctx := context.Background()
+
+	databaseURL := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.Database.Host,
+		cfg.Database.Port,
+		cfg.Database.Name,
+		cfg.Database.SSLMode,
+	)
+
+	migrator, err := database.NewMigrator(databaseURL)
+	require.NoError(t, err, "Failed to create migrator")
+	defer migrator.Close()
+
+	err = migrator.Up()
+	require.NoError(t, err, "Failed to run migrations")
+
+	suffix := time.Now().UnixNano() % 1000000
+
+	var operatorID, port1ID, port2ID, vesselID, routeID, customerID uuid.UUID
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO operators (name, code, contact_email)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, "Cancellation Test Operator", fmt.Sprintf("CTO%d", suffix), "cancel@test.com").Scan(&operatorID)
+	require.NoError(t, err)
+
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO ports (name, code, city, country, timezone)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, "Cancel Departure Port", fmt.Sprintf("CD%d", suffix), "City A", "Country", "UTC").Scan(&port1ID)
+	require.NoError(t, err)
+
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO ports (name, code, city, country, timezone)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, "Cancel Arrival Port", fmt.Sprintf("CA%d", suffix), "City B", "Country", "UTC").Scan(&port2ID)
+	require.NoError(t, err)
+
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO vessels (operator_id, name, registration_number, vessel_type, capacity, seat_configuration)
+		VALUES ($1, $2, $3, $4, $5, $6::jsonb)
+		RETURNING id
+	`, operatorID, "Cancel Ferry", fmt.Sprintf("CF%d", suffix), "passenger", 100, `{}`).Scan(&vesselID)
+	require.NoError(t, err)
+
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO routes (operator_id, name, departure_port_id, arrival_port_id, estimated_duration)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, operatorID, "Cancel Route", port1ID, port2ID, "2 hours").Scan(&routeID)
+	require.NoError(t, err)
+
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO users (email, password_hash, first_name, last_name, user_type)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, fmt.Sprintf("cancelcustomer%d@example.com", suffix), "$2a$10$hash", "Cancel", "Customer", "customer").Scan(&customerID)
+	require.NoError(t, err)
+
+	departs := time.Now().AddDate(0, 0, 7).UTC().Truncate(time.Hour)
+	var scheduleID uuid.UUID
+	err = db.Pool.QueryRow(ctx, `
+		INSERT INTO schedules (
+			operator_id, route_id, vessel_id,
+			departure_date, departure_time, arrival_time, departure_at, arrival_at,
+			base_price, total_capacity, available_seats
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, operatorID, routeID, vesselID,
+		departs.Format("2006-01-02"), departs.Format("15:04:05"), departs.Add(2*time.Hour).Format("15:04:05"),
+		departs, departs.Add(2*time.Hour),
+		50.00, 100, 100).Scan(&scheduleID)
+	require.NoError(t, err)
+
+	createBooking := func(reference string) uuid.UUID {
+		var id uuid.UUID
+		err := db.Pool.QueryRow(ctx, `
+			INSERT INTO bookings (
+				booking_reference, schedule_id, customer_id,
+				passenger_count, total_amount, booking_channel
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`, reference, scheduleID, customerID, 2, 100.00, "online").Scan(&id)
+		require.NoError(t, err)
+		return id
+	}
+
+	// A deposit taken in cash with the card tender still outstanding
+	partPaidID := createBooking(fmt.Sprintf("PP%d", suffix))
+	_, err = db.Pool.Exec(ctx, `
+		INSERT INTO payments (booking_id, payment_method, amount, payment_status)
+		VALUES ($1, 'cash', 40.00, 'completed'), ($1, 'credit_card', 60.00, 'pending')
+	`, partPaidID)
+	require.NoError(t, err)
+
+	unpaidID := createBooking(fmt.Sprintf("UP%d", suffix))
+
+	bookingStatus := func(id uuid.UUID) (string, string) {
+		var bookingStatus, paymentStatus string
+		err := db.Pool.QueryRow(ctx, `
+			SELECT booking_status, payment_status FROM bookings WHERE id = $1
+		`, id).Scan(&bookingStatus, &paymentStatus)
+		require.NoError(t, err)
+		return bookingStatus, paymentStatus
+	}
+
+	bookingStatusNow, paymentStatus := bookingStatus(partPaidID)
+	require.Equal(t, "pending", bookingStatusNow)
+	require.Equal(t, "partially_paid", paymentStatus)
+
+	cancellations := NewCancellationRepository(db)
+	run, err := cancellations.Start(ctx, scheduleID, "Engine failure", nil)
+	require.NoError(t, err)
+
+	t.Run("Unpaid pending bookings are cancelled outright", func(t *testing.T) {
+		status, _ := bookingStatus(unpaidID)
+		assert.Equal(t, "cancelled", status)
+	})
+
+	t.Run("Part-paid bookings are left for the run", func(t *testing.T) {
+		status, _ := bookingStatus(partPaidID)
+		assert.Equal(t, "pending", status)
+
+		bookings, err := NewBookingRepository(db).GetScheduleBookings(ctx, scheduleID)
+		require.NoError(t, err)
+		require.Len(t, bookings, 1)
+		assert.Equal(t, partPaidID, bookings[0].ID)
+	})
+
+	t.Run("Part-paid booking is cancelled with an offer for its deposit", func(t *testing.T) {
+		offer := &models.CancellationOffer{
+			CancellationID: run.ID,
+			ScheduleID:     scheduleID,
+			BookingID:      partPaidID,
+			CustomerID:     customerID,
+			PassengerCount: 2,
+			AmountPaid:     money.MustParse("40.00"),
+			Alternatives:   []uuid.UUID{},
+			RespondBy:      time.Now(),
+		}
+		cancelled, err := cancellations.CancelBooking(ctx, offer)
+		require.NoError(t, err)
+		assert.True(t, cancelled)
+
+		status, _ := bookingStatus(partPaidID)
+		assert.Equal(t, "cancelled", status)
+
+		offers, err := cancellations.ListOffers(ctx, run.ID)
+		require.NoError(t, err)
+		require.Len(t, offers, 1)
+		assert.Equal(t, money.MustParse("40.00"), offers[0].AmountPaid)
+	})
+
+	// Cleanup
+	_, err = db.Pool.Exec(ctx, "DELETE FROM cancellation_offers WHERE schedule_id = $1", scheduleID)
+	assert.NoError(t, err)
+	_, err = db.Pool.Exec(ctx, "DELETE FROM schedule_cancellations WHERE schedule_id = $1", scheduleID)
+	assert.NoError(t, err)
+	_, err = db.Pool.Exec(ctx, "DELETE FROM payments WHERE booking_id IN ($1, $2)", partPaidID, unpaidID)
+	assert.NoError(t, err)
+	_, err = db.Pool.Exec(ctx, "DELETE FROM bookings WHERE id IN ($1, $2)", partPaidID, unpaidID)
+	assert.NoError(t, err)
+	_, err = db.Pool.Exec(ctx, "DELETE FROM operators WHERE id = $1", operatorID)
+	assert.NoError(t, err)
+	_, err = db.Pool.Exec(ctx, "DELETE FROM ports WHERE id IN ($1, $2)", port1ID, port2ID)
+	assert.NoError(t, err)
+	_, err = db.Pool.Exec(ctx, "DELETE FROM users WHERE id = $1", customerID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,428 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type DisruptionRepository interface {
+	Record(ctx context.Context, disruption *models.ScheduleDisruption, schedule *models.Schedule, ticketCodes map[uuid.UUID]string) error
+	ChangeVessel(ctx context.Context, disruption *models.ScheduleDisruption, schedule *models.Schedule, seats map[uuid.UUID]*string, ticketCodes map[uuid.UUID]string) (int, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ScheduleDisruption, error)
+	ListBySchedule(ctx context.Context, scheduleID uuid.UUID) ([]*models.ScheduleDisruption, error)
+	ListConfirmedBookings(ctx context.Context, scheduleID uuid.UUID) ([]*models.Booking, error)
+	ListTakenSeats(ctx context.Context, scheduleID uuid.UUID) (map[string]bool, error)
+	MoveBooking(ctx context.Context, move *models.BookingMove, seats map[uuid.UUID]*string, ticketCodes map[uuid.UUID]string) error
+	ListOnTimeSailings(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]models.OnTimeSailing, error)
+}
+
+type disruptionRepository struct {
+	db *database.DB
+}
+
+func NewDisruptionRepository(db *database.DB) DisruptionRepository {
+	return &disruptionRepository{db: db}
+}
+
+const disruptionColumns = `
+	id, schedule_id, kind, reason_code, description, scheduled_departure_at,
+	scheduled_arrival_at, estimated_departure_at, estimated_arrival_at,
+	delay_minutes, previous_gate, gate, previous_vessel_id, vessel_id,
+	previous_capacity, capacity, capacity_override, recorded_by, created_at
+`
+
+func scanDisruption(row pgx.Row) (*models.ScheduleDisruption, error) {
+	d := &models.ScheduleDisruption{}
+	err := row.Scan(
+		&d.ID, &d.ScheduleID, &d.Kind, &d.ReasonCode, &d.Description, &d.ScheduledDepartureAt,
+		&d.ScheduledArrivalAt, &d.EstimatedDepartureAt, &d.EstimatedArrivalAt,
+		&d.DelayMinutes, &d.PreviousGate, &d.Gate, &d.PreviousVesselID, &d.VesselID,
+		&d.PreviousCapacity, &d.Capacity, &d.CapacityOverride, &d.RecordedBy, &d.CreatedAt,
+	)
+	return d, err
+}
+
+// Record records a disruption and puts its new estimates or gate on the
+// sailing, together with any ticket codes reissued for the new times. Only
+// sailings that have not departed can be disrupted.
+func (r *disruptionRepository) Record(ctx context.Context, disruption *models.ScheduleDisruption, schedule *models.Schedule, ticketCodes map[uuid.UUID]string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		SELECT departure_gate FROM schedules
+		WHERE id = $1 AND status IN ('scheduled', 'boarding')
+		FOR UPDATE
+	`, disruption.ScheduleID).Scan(&disruption.PreviousGate)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("only sailings that have not departed or been cancelled can be disrupted")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE schedules SET
+			estimated_departure_at = COALESCE($2, estimated_departure_at),
+			estimated_arrival_at = COALESCE($3, estimated_arrival_at),
+			departure_gate = COALESCE($4, departure_gate),
+			version = version + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, disruption.ScheduleID, disruption.EstimatedDepartureAt, disruption.EstimatedArrivalAt, disruption.Gate)
+	if err != nil {
+		// A longer sailing can run into the vessel's next one
+		delayed := *schedule
+		if disruption.EstimatedDepartureAt != nil && disruption.EstimatedArrivalAt != nil {
+			delayed.DepartureAt, delayed.ArrivalAt = *disruption.EstimatedDepartureAt, *disruption.EstimatedArrivalAt
+		}
+		if conflict := vesselConflict(ctx, r.db, err, &delayed); conflict != nil {
+			return conflict
+		}
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO schedule_disruptions (
+			schedule_id, kind, reason_code, description, scheduled_departure_at,
+			scheduled_arrival_at, estimated_departure_at, estimated_arrival_at,
+			delay_minutes, previous_gate, gate, recorded_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at
+	`,
+		disruption.ScheduleID, disruption.Kind, disruption.ReasonCode, disruption.Description,
+		disruption.ScheduledDepartureAt, disruption.ScheduledArrivalAt,
+		disruption.EstimatedDepartureAt, disruption.EstimatedArrivalAt,
+		disruption.DelayMinutes, disruption.PreviousGate, disruption.Gate, disruption.RecordedBy,
+	).Scan(&disruption.ID, &disruption.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record disruption: %w", err)
+	}
+
+	for ticketID, code := range ticketCodes {
+		_, err := tx.Exec(ctx, `
+			UPDATE tickets SET qr_code = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+		`, ticketID, code)
+		if err != nil {
+			return fmt.Errorf("failed to reissue ticket: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ChangeVessel substitutes disruption.VesselID on a sailing that has not
+// departed and sizes it to disruption.Capacity, keeping the seats already
+// held. Tickets whose seats were remapped get their new seats and codes.
+// Unless disruption.CapacityOverride is set, a vessel with fewer seats than
+// are held is rejected. It returns the number of seats held.
+func (r *disruptionRepository) ChangeVessel(ctx context.Context, disruption *models.ScheduleDisruption, schedule *models.Schedule, seats map[uuid.UUID]*string, ticketCodes map[uuid.UUID]string) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previousVesselID uuid.UUID
+	var previousCapacity, availableSeats int
+	err = tx.QueryRow(ctx, `
+		SELECT vessel_id, total_capacity, available_seats FROM schedules
+		WHERE id = $1 AND status IN ('scheduled', 'boarding')
+		FOR UPDATE
+	`, disruption.ScheduleID).Scan(&previousVesselID, &previousCapacity, &availableSeats)
+	if err == pgx.ErrNoRows {
+		return 0, fmt.Errorf("only sailings that have not departed or been cancelled can change vessel")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	disruption.PreviousVesselID = &previousVesselID
+	disruption.PreviousCapacity = &previousCapacity
+
+	held := previousCapacity - availableSeats
+	if held > *disruption.Capacity && !disruption.CapacityOverride {
+		return held, fmt.Errorf("the vessel has %d seats but %d are booked", *disruption.Capacity, held)
+	}
+	// Only recorded as overridden when it was needed
+	disruption.CapacityOverride = held > *disruption.Capacity
+
+	_, err = tx.Exec(ctx, `
+		UPDATE schedules SET
+			vessel_id = $2,
+			total_capacity = $3,
+			available_seats = $3 - $4,
+			version = version + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, disruption.ScheduleID, disruption.VesselID, *disruption.Capacity, held)
+	if err != nil {
+		// The new vessel can already be sailing at the time
+		substituted := *schedule
+		substituted.VesselID = *disruption.VesselID
+		substituted.DepartureAt, substituted.ArrivalAt = schedule.ExpectedDepartureAt(), schedule.ExpectedArrivalAt()
+		if conflict := vesselConflict(ctx, r.db, err, &substituted); conflict != nil {
+			return held, conflict
+		}
+		return held, fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	for ticketID, code := range ticketCodes {
+		_, err := tx.Exec(ctx, `
+			UPDATE tickets SET qr_code = $2, seat_number = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $1
+		`, ticketID, code, seats[ticketID])
+		if err != nil {
+			return held, fmt.Errorf("failed to reissue ticket: %w", err)
+		}
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO schedule_disruptions (
+			schedule_id, kind, reason_code, description, scheduled_departure_at,
+			scheduled_arrival_at, delay_minutes, previous_vessel_id, vessel_id,
+			previous_capacity, capacity, capacity_override, recorded_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at
+	`,
+		disruption.ScheduleID, disruption.Kind, disruption.ReasonCode, disruption.Description,
+		disruption.ScheduledDepartureAt, disruption.ScheduledArrivalAt, disruption.DelayMinutes,
+		disruption.PreviousVesselID, disruption.VesselID, disruption.PreviousCapacity,
+		disruption.Capacity, disruption.CapacityOverride, disruption.RecordedBy,
+	).Scan(&disruption.ID, &disruption.CreatedAt)
+	if err != nil {
+		return held, fmt.Errorf("failed to record vessel change: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return held, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return held, nil
+}
+
+func (r *disruptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ScheduleDisruption, error) {
+	query := `SELECT ` + disruptionColumns + ` FROM schedule_disruptions WHERE id = $1`
+
+	disruption, err := scanDisruption(r.db.Pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("disruption not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disruption: %w", err)
+	}
+
+	return disruption, nil
+}
+
+// ListBySchedule lists a sailing's disruptions, oldest first
+func (r *disruptionRepository) ListBySchedule(ctx context.Context, scheduleID uuid.UUID) ([]*models.ScheduleDisruption, error) {
+	query := `SELECT ` + disruptionColumns + ` FROM schedule_disruptions WHERE schedule_id = $1 ORDER BY created_at ASC`
+
+	rows, err := r.db.Pool.Query(ctx, query, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disruptions: %w", err)
+	}
+	defer rows.Close()
+
+	disruptions := []*models.ScheduleDisruption{}
+	for rows.Next() {
+		disruption, err := scanDisruption(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan disruption: %w", err)
+		}
+		disruptions = append(disruptions, disruption)
+	}
+
+	return disruptions, nil
+}
+
+// ListConfirmedBookings lists the confirmed bookings on a sailing with their
+// tickets, oldest booking first
+func (r *disruptionRepository) ListConfirmedBookings(ctx context.Context, scheduleID uuid.UUID) ([]*models.Booking, error) {
+	query := `
+		SELECT
+			b.id, b.booking_reference, b.schedule_id, b.customer_id, b.passenger_count,
+			b.booking_status, b.payment_status, b.created_at,
+			t.id, t.passenger_name, t.passenger_type, t.seat_number, t.ticket_price,
+			t.qr_code, t.check_in_status
+		FROM bookings b
+		JOIN tickets t ON t.booking_id = b.id
+		WHERE b.schedule_id = $1 AND b.booking_status = 'confirmed'
+		ORDER BY b.created_at ASC, b.id, t.created_at ASC, t.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bookings: %w", err)
+	}
+	defer rows.Close()
+
+	bookings := []*models.Booking{}
+	var current *models.Booking
+	for rows.Next() {
+		var b models.Booking
+		var t models.Ticket
+		err := rows.Scan(
+			&b.ID, &b.BookingReference, &b.ScheduleID, &b.CustomerID, &b.PassengerCount,
+			&b.BookingStatus, &b.PaymentStatus, &b.CreatedAt,
+			&t.ID, &t.PassengerName, &t.PassengerType, &t.SeatNumber, &t.TicketPrice,
+			&t.QRCode, &t.CheckInStatus,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan booking: %w", err)
+		}
+		if current == nil || current.ID != b.ID {
+			current = &b
+			bookings = append(bookings, current)
+		}
+		t.BookingID = b.ID
+		current.Tickets = append(current.Tickets, t)
+	}
+
+	return bookings, nil
+}
+
+// ListTakenSeats returns the seat numbers held on a sailing
+func (r *disruptionRepository) ListTakenSeats(ctx context.Context, scheduleID uuid.UUID) (map[string]bool, error) {
+	query := `
+		SELECT t.seat_number
+		FROM tickets t
+		JOIN bookings b ON t.booking_id = b.id
+		WHERE b.schedule_id = $1 AND b.booking_status != 'cancelled' AND t.seat_number IS NOT NULL
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seats: %w", err)
+	}
+	defer rows.Close()
+
+	taken := map[string]bool{}
+	for rows.Next() {
+		var seat string
+		if err := rows.Scan(&seat); err != nil {
+			return nil, fmt.Errorf("failed to scan seat: %w", err)
+		}
+		taken[seat] = true
+	}
+
+	return taken, nil
+}
+
+// MoveBooking moves a confirmed booking to another sailing, giving its
+// tickets their new seats and codes. The seat availability trigger takes the
+// seats on the new sailing and gives them back on the old one.
+func (r *disruptionRepository) MoveBooking(ctx context.Context, move *models.BookingMove, seats map[uuid.UUID]*string, ticketCodes map[uuid.UUID]string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE bookings SET
+			schedule_id = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND schedule_id = $2 AND booking_status = 'confirmed'
+	`, move.BookingID, move.FromScheduleID, move.ToScheduleID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "P0001" {
+			return fmt.Errorf("the sailing does not have enough seats")
+		}
+		return fmt.Errorf("failed to move booking: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("booking is no longer confirmed on this sailing")
+	}
+
+	for ticketID, code := range ticketCodes {
+		_, err := tx.Exec(ctx, `
+			UPDATE tickets SET
+				qr_code = $2,
+				seat_number = $3,
+				check_in_status = 'not_checked_in',
+				check_in_time = NULL,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, ticketID, code, seats[ticketID])
+		if err != nil {
+			return fmt.Errorf("failed to reissue ticket: %w", err)
+		}
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO booking_moves (
+			booking_id, from_schedule_id, to_schedule_id, disruption_id,
+			passenger_count, seats_changed, moved_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`,
+		move.BookingID, move.FromScheduleID, move.ToScheduleID, move.DisruptionID,
+		move.PassengerCount, move.SeatsChanged, move.MovedBy,
+	).Scan(&move.ID, &move.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record booking move: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListOnTimeSailings lists an operator's sailings departing between two
+// dates that have left or been cancelled, with how late each left and the
+// reason of its latest delay
+func (r *disruptionRepository) ListOnTimeSailings(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]models.OnTimeSailing, error) {
+	query := `
+		SELECT
+			rt.id, rt.name, s.departure_at,
+			COALESCE(s.estimated_departure_at, s.departure_at),
+			s.status = 'cancelled',
+			COALESCE((
+				SELECT d.reason_code FROM schedule_disruptions d
+				WHERE d.schedule_id = s.id AND d.kind = 'delay'
+				ORDER BY d.created_at DESC
+				LIMIT 1
+			), '')
+		FROM schedules s
+		JOIN routes rt ON s.route_id = rt.id
+		WHERE s.operator_id = $1
+			AND s.status IN ('departed', 'arrived', 'cancelled')
+			AND s.departure_date BETWEEN $2 AND $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, operatorID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sailings: %w", err)
+	}
+	defer rows.Close()
+
+	sailings := []models.OnTimeSailing{}
+	for rows.Next() {
+		var s models.OnTimeSailing
+		err := rows.Scan(&s.RouteID, &s.RouteName, &s.DepartureAt, &s.ExpectedDepartureAt, &s.Cancelled, &s.ReasonCode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sailing: %w", err)
+		}
+		sailings = append(sailings, s)
+	}
+
+	return sailings, nil
+}
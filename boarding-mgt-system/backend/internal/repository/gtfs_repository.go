@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type GTFSRepository interface {
+	ListSailings(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]*models.Schedule, error)
+	ListImported(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]models.GTFSSailing, error)
+	Import(ctx context.Context, routes []*models.Route, sailings []models.GTFSSailing) ([]models.GTFSSailing, error)
+}
+
+type gtfsRepository struct {
+	db *database.DB
+}
+
+func NewGTFSRepository(db *database.DB) GTFSRepository {
+	return &gtfsRepository{db: db}
+}
+
+// ListSailings lists an operator's sailings on sale departing between two
+// dates that have not been cancelled, with only the fields a feed carries
+func (r *gtfsRepository) ListSailings(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]*models.Schedule, error) {
+	query := `
+		SELECT id, operator_id, route_id, departure_date, status, departure_at, arrival_at
+		FROM schedules
+		WHERE operator_id = $1
+			AND departure_date BETWEEN $2 AND $3
+			AND status != 'cancelled'
+			AND NOT unpublished
+		ORDER BY departure_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, operatorID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []*models.Schedule{}
+	for rows.Next() {
+		s := &models.Schedule{}
+		err := rows.Scan(&s.ID, &s.OperatorID, &s.RouteID, &s.DepartureDate, &s.Status, &s.DepartureAt, &s.ArrivalAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+
+	return schedules, nil
+}
+
+// ListImported lists the sailings of an operator departing between two
+// dates that were imported from a GTFS feed, by trip, route and date
+func (r *gtfsRepository) ListImported(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]models.GTFSSailing, error) {
+	query := `
+		SELECT gtfs_trip_id, id, route_id, departure_date
+		FROM schedules
+		WHERE operator_id = $1
+			AND gtfs_trip_id IS NOT NULL
+			AND departure_date BETWEEN $2 AND $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, operatorID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list imported schedules: %w", err)
+	}
+	defer rows.Close()
+
+	sailings := []models.GTFSSailing{}
+	for rows.Next() {
+		sailing := models.GTFSSailing{Schedule: &models.Schedule{}}
+		err := rows.Scan(&sailing.TripID, &sailing.Schedule.ID, &sailing.Schedule.RouteID, &sailing.Schedule.DepartureDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		sailings = append(sailings, sailing)
+	}
+
+	return sailings, nil
+}
+
+// Import creates routes and the sailings imported onto them in one
+// transaction and returns the sailings created. Sailings already imported,
+// say by an import running alongside, are left alone; a sailing that takes
+// a vessel already sailing at the time fails the whole import.
+func (r *gtfsRepository) Import(ctx context.Context, routes []*models.Route, sailings []models.GTFSSailing) ([]models.GTFSSailing, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, route := range routes {
+		err := tx.QueryRow(ctx, `
+			INSERT INTO routes (
+				id, operator_id, name, departure_port_id, arrival_port_id, estimated_duration
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING is_active, created_at, updated_at
+		`,
+			route.ID, route.OperatorID, route.Name, route.DeparturePortID, route.ArrivalPortID, route.EstimatedDuration,
+		).Scan(&route.IsActive, &route.CreatedAt, &route.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create route %s: %w", route.Name, err)
+		}
+	}
+
+	createQuery := `
+		INSERT INTO schedules (
+			operator_id, route_id, vessel_id, departure_date, departure_time,
+			arrival_time, departure_at, arrival_at, base_price, total_capacity,
+			available_seats, gtfs_trip_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (operator_id, gtfs_trip_id, route_id, departure_date) WHERE gtfs_trip_id IS NOT NULL DO NOTHING
+		RETURNING id, status, version, created_at, updated_at
+	`
+
+	created := []models.GTFSSailing{}
+	for _, sailing := range sailings {
+		schedule := sailing.Schedule
+		err := tx.QueryRow(ctx, createQuery,
+			schedule.OperatorID, schedule.RouteID, schedule.VesselID,
+			schedule.DepartureDate, schedule.DepartureTime, schedule.ArrivalTime,
+			schedule.DepartureAt, schedule.ArrivalAt,
+			schedule.BasePrice, schedule.TotalCapacity, schedule.AvailableSeats,
+			sailing.TripID,
+		).Scan(&schedule.ID, &schedule.Status, &schedule.Version, &schedule.CreatedAt, &schedule.UpdatedAt)
+		if err == pgx.ErrNoRows {
+			continue
+		}
+		if conflict := vesselConflict(ctx, r.db, err, schedule); conflict != nil {
+			return nil, conflict
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create schedule for trip %s: %w", sailing.TripID, err)
+		}
+		created = append(created, sailing)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type InvoiceRepository interface {
+	Issue(ctx context.Context, invoice *models.Invoice) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Invoice, error)
+	GetBySource(ctx context.Context, documentType string, sourceID uuid.UUID) (*models.Invoice, error)
+	ListByBooking(ctx context.Context, bookingID uuid.UUID) ([]*models.Invoice, error)
+}
+
+type invoiceRepository struct {
+	db *database.DB
+}
+
+func NewInvoiceRepository(db *database.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+const invoiceColumns = `
+	i.id, i.operator_id, i.booking_id, i.customer_id, i.document_type,
+	i.fiscal_year, i.sequence_number, i.invoice_number, i.original_invoice_id,
+	i.source_id, i.issue_date, i.currency, i.seller, i.buyer,
+	i.subtotal, i.tax_total, i.total, i.created_at, o.invoice_number
+`
+
+// Issue numbers and stores an invoice or credit note. The number is taken
+// from the operator's counter for the fiscal year in the same transaction as
+// the insert, so the counter row stays locked until commit and a failed issue
+// rolls the counter back, keeping numbering gap-free. Issuing a document for
+// a source that already has one is a no-op and returns false.
+func (r *invoiceRepository) Issue(ctx context.Context, invoice *models.Invoice) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sequenceQuery := `
+		INSERT INTO invoice_sequences (operator_id, fiscal_year, document_type, last_number)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (operator_id, fiscal_year, document_type)
+		DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number
+	`
+
+	err = tx.QueryRow(ctx, sequenceQuery,
+		invoice.OperatorID, invoice.FiscalYear, invoice.DocumentType,
+	).Scan(&invoice.SequenceNumber)
+	if err != nil {
+		return false, fmt.Errorf("failed to allocate invoice number: %w", err)
+	}
+
+	invoice.InvoiceNumber = fmt.Sprintf("%s-%d-%06d", invoice.NumberPrefix, invoice.FiscalYear, invoice.SequenceNumber)
+
+	invoiceQuery := `
+		INSERT INTO invoices (
+			operator_id, booking_id, customer_id, document_type, fiscal_year,
+			sequence_number, invoice_number, original_invoice_id, source_id,
+			issue_date, currency, seller, buyer, subtotal, tax_total, total
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (document_type, source_id) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRow(ctx, invoiceQuery,
+		invoice.OperatorID, invoice.BookingID, invoice.CustomerID, invoice.DocumentType,
+		invoice.FiscalYear, invoice.SequenceNumber, invoice.InvoiceNumber,
+		invoice.OriginalInvoiceID, invoice.SourceID, invoice.IssueDate, invoice.Currency,
+		invoice.Seller, invoice.Buyer, invoice.Subtotal, invoice.TaxTotal, invoice.Total,
+	).Scan(&invoice.ID, &invoice.CreatedAt)
+
+	if err == pgx.ErrNoRows {
+		// Already issued; rolling back releases the number we took
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	lineQuery := `
+		INSERT INTO invoice_lines (
+			invoice_id, line_number, description, quantity, unit_price,
+			tax_rate, net_amount, tax_amount, total_amount
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	for i := range invoice.Lines {
+		line := &invoice.Lines[i]
+		line.InvoiceID = invoice.ID
+		line.LineNumber = i + 1
+
+		err := tx.QueryRow(ctx, lineQuery,
+			line.InvoiceID, line.LineNumber, line.Description, line.Quantity, line.UnitPrice,
+			line.TaxRate, line.NetAmount, line.TaxAmount, line.TotalAmount,
+		).Scan(&line.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to create invoice line: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit invoice: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices i
+		LEFT JOIN invoices o ON i.original_invoice_id = o.id
+		WHERE i.id = $1
+	`
+
+	return r.getInvoice(ctx, query, id)
+}
+
+func (r *invoiceRepository) GetBySource(ctx context.Context, documentType string, sourceID uuid.UUID) (*models.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices i
+		LEFT JOIN invoices o ON i.original_invoice_id = o.id
+		WHERE i.document_type = $1 AND i.source_id = $2
+	`
+
+	return r.getInvoice(ctx, query, documentType, sourceID)
+}
+
+func (r *invoiceRepository) ListByBooking(ctx context.Context, bookingID uuid.UUID) ([]*models.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices i
+		LEFT JOIN invoices o ON i.original_invoice_id = o.id
+		WHERE i.booking_id = $1
+		ORDER BY i.created_at
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+	defer rows.Close()
+
+	invoices := []*models.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invoice: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+
+	for _, invoice := range invoices {
+		if err := r.loadLines(ctx, invoice); err != nil {
+			return nil, err
+		}
+	}
+
+	return invoices, nil
+}
+
+func (r *invoiceRepository) getInvoice(ctx context.Context, query string, args ...interface{}) (*models.Invoice, error) {
+	invoice, err := scanInvoice(r.db.Pool.QueryRow(ctx, query, args...))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("invoice not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	if err := r.loadLines(ctx, invoice); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (r *invoiceRepository) loadLines(ctx context.Context, invoice *models.Invoice) error {
+	query := `
+		SELECT
+			id, invoice_id, line_number, description, quantity, unit_price,
+			tax_rate, net_amount, tax_amount, total_amount
+		FROM invoice_lines
+		WHERE invoice_id = $1
+		ORDER BY line_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, invoice.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get invoice lines: %w", err)
+	}
+	defer rows.Close()
+
+	invoice.Lines = []models.InvoiceLine{}
+	for rows.Next() {
+		var line models.InvoiceLine
+		err := rows.Scan(
+			&line.ID, &line.InvoiceID, &line.LineNumber, &line.Description, &line.Quantity,
+			&line.UnitPrice, &line.TaxRate, &line.NetAmount, &line.TaxAmount, &line.TotalAmount,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan invoice line: %w", err)
+		}
+		line.UnitPrice = line.UnitPrice.In(invoice.Currency)
+		line.NetAmount = line.NetAmount.In(invoice.Currency)
+		line.TaxAmount = line.TaxAmount.In(invoice.Currency)
+		line.TotalAmount = line.TotalAmount.In(invoice.Currency)
+		invoice.Lines = append(invoice.Lines, line)
+	}
+
+	return rows.Err()
+}
+
+func scanInvoice(row pgx.Row) (*models.Invoice, error) {
+	invoice := &models.Invoice{}
+	err := row.Scan(
+		&invoice.ID, &invoice.OperatorID, &invoice.BookingID, &invoice.CustomerID,
+		&invoice.DocumentType, &invoice.FiscalYear, &invoice.SequenceNumber,
+		&invoice.InvoiceNumber, &invoice.OriginalInvoiceID, &invoice.SourceID,
+		&invoice.IssueDate, &invoice.Currency, &invoice.Seller, &invoice.Buyer,
+		&invoice.Subtotal, &invoice.TaxTotal, &invoice.Total, &invoice.CreatedAt,
+		&invoice.OriginalInvoiceNumber,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invoice.Subtotal = invoice.Subtotal.In(invoice.Currency)
+	invoice.TaxTotal = invoice.TaxTotal.In(invoice.Currency)
+	invoice.Total = invoice.Total.In(invoice.Currency)
+	return invoice, nil
+}
@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type LedgerRepository interface {
+	EnsureAccounts(ctx context.Context, operatorID uuid.UUID, defaults []models.LedgerAccount) (map[string]*models.LedgerAccount, error)
+	ListAccounts(ctx context.Context, operatorID uuid.UUID) ([]*models.LedgerAccount, error)
+	PostEntry(ctx context.Context, entry *models.JournalEntry) (bool, error)
+	GetEntryBySource(ctx context.Context, eventType string, sourceID uuid.UUID) (*models.JournalEntry, error)
+	HasEntry(ctx context.Context, eventType string, sourceID uuid.UUID) (bool, error)
+	GetDeferredBalances(ctx context.Context, scheduleID uuid.UUID) (map[uuid.UUID]money.Money, error)
+	ListEntries(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]*models.JournalEntry, error)
+	GetTrialBalance(ctx context.Context, operatorID uuid.UUID, asOf time.Time) (*models.TrialBalance, error)
+}
+
+type ledgerRepository struct {
+	db *database.DB
+}
+
+func NewLedgerRepository(db *database.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+func (r *ledgerRepository) EnsureAccounts(ctx context.Context, operatorID uuid.UUID, defaults []models.LedgerAccount) (map[string]*models.LedgerAccount, error) {
+	insertQuery := `
+		INSERT INTO ledger_accounts (operator_id, code, name, account_type, system_key)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING
+	`
+
+	for _, account := range defaults {
+		_, err := r.db.Pool.Exec(ctx, insertQuery,
+			operatorID, account.Code, account.Name, account.AccountType, account.SystemKey,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ledger account %s: %w", account.Code, err)
+		}
+	}
+
+	accounts, err := r.ListAccounts(ctx, operatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*models.LedgerAccount, len(accounts))
+	for _, account := range accounts {
+		if account.SystemKey != nil {
+			byKey[*account.SystemKey] = account
+		}
+	}
+
+	return byKey, nil
+}
+
+func (r *ledgerRepository) ListAccounts(ctx context.Context, operatorID uuid.UUID) ([]*models.LedgerAccount, error) {
+	query := `
+		SELECT id, operator_id, code, name, account_type, system_key, is_active, created_at
+		FROM ledger_accounts
+		WHERE operator_id = $1
+		ORDER BY code
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, operatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []*models.LedgerAccount{}
+	for rows.Next() {
+		account := &models.LedgerAccount{}
+		err := rows.Scan(
+			&account.ID, &account.OperatorID, &account.Code, &account.Name,
+			&account.AccountType, &account.SystemKey, &account.IsActive, &account.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ledger account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// PostEntry writes a journal entry and its lines in one transaction. It
+// returns false without error when the event has already been posted.
+func (r *ledgerRepository) PostEntry(ctx context.Context, entry *models.JournalEntry) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	entryQuery := `
+		INSERT INTO journal_entries (
+			operator_id, entry_date, event_type, source_id, booking_id,
+			schedule_id, description, currency, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (event_type, source_id) DO NOTHING
+		RETURNING id, entry_number, created_at
+	`
+
+	err = tx.QueryRow(ctx, entryQuery,
+		entry.OperatorID, entry.EntryDate, entry.EventType, entry.SourceID, entry.BookingID,
+		entry.ScheduleID, entry.Description, entry.Currency, entry.CreatedBy,
+	).Scan(&entry.ID, &entry.EntryNumber, &entry.CreatedAt)
+
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	lineQuery := `
+		INSERT INTO journal_lines (
+			entry_id, line_number, account_id, booking_id, debit, credit, memo
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	for i := range entry.Lines {
+		line := &entry.Lines[i]
+		line.EntryID = entry.ID
+		line.LineNumber = i + 1
+
+		err := tx.QueryRow(ctx, lineQuery,
+			line.EntryID, line.LineNumber, line.AccountID, line.BookingID,
+			line.Debit, line.Credit, line.Memo,
+		).Scan(&line.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to create journal line: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit journal entry: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *ledgerRepository) GetEntryBySource(ctx context.Context, eventType string, sourceID uuid.UUID) (*models.JournalEntry, error) {
+	query := `
+		SELECT
+			id, entry_number, operator_id, entry_date, event_type, source_id,
+			booking_id, schedule_id, description, currency, created_by, created_at
+		FROM journal_entries
+		WHERE event_type = $1 AND source_id = $2
+	`
+
+	entry := &models.JournalEntry{}
+	err := r.db.Pool.QueryRow(ctx, query, eventType, sourceID).Scan(
+		&entry.ID, &entry.EntryNumber, &entry.OperatorID, &entry.EntryDate, &entry.EventType,
+		&entry.SourceID, &entry.BookingID, &entry.ScheduleID, &entry.Description,
+		&entry.Currency, &entry.CreatedBy, &entry.CreatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("journal entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal entry: %w", err)
+	}
+
+	linesQuery := `
+		SELECT
+			l.id, l.entry_id, l.line_number, l.account_id, l.booking_id,
+			l.debit, l.credit, l.memo,
+			COALESCE(a.system_key, ''), a.code, a.name, a.account_type
+		FROM journal_lines l
+		JOIN ledger_accounts a ON l.account_id = a.id
+		WHERE l.entry_id = $1
+		ORDER BY l.line_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, linesQuery, entry.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.JournalLine
+		err := rows.Scan(
+			&line.ID, &line.EntryID, &line.LineNumber, &line.AccountID, &line.BookingID,
+			&line.Debit, &line.Credit, &line.Memo,
+			&line.AccountKey, &line.AccountCode, &line.AccountName, &line.AccountType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan journal line: %w", err)
+		}
+		lineCurrency(&line, entry.Currency)
+		entry.Lines = append(entry.Lines, line)
+	}
+
+	return entry, nil
+}
+
+// HasEntry reports whether an event has been posted
+func (r *ledgerRepository) HasEntry(ctx context.Context, eventType string, sourceID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM journal_entries WHERE event_type = $1 AND source_id = $2
+		)
+	`
+
+	var exists bool
+	if err := r.db.Pool.QueryRow(ctx, query, eventType, sourceID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check journal entry: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *ledgerRepository) GetDeferredBalances(ctx context.Context, scheduleID uuid.UUID) (map[uuid.UUID]money.Money, error) {
+	query := `
+		SELECT l.booking_id, e.currency, SUM(l.credit - l.debit)
+		FROM journal_lines l
+		JOIN journal_entries e ON l.entry_id = e.id
+		JOIN ledger_accounts a ON l.account_id = a.id
+		JOIN bookings b ON l.booking_id = b.id
+		WHERE a.system_key = 'deferred_revenue' AND b.schedule_id = $1
+		GROUP BY l.booking_id, e.currency
+		HAVING SUM(l.credit - l.debit) > 0
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deferred balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := make(map[uuid.UUID]money.Money)
+	for rows.Next() {
+		var bookingID uuid.UUID
+		var currency string
+		var balance money.Money
+		if err := rows.Scan(&bookingID, &currency, &balance); err != nil {
+			return nil, fmt.Errorf("failed to scan deferred balance: %w", err)
+		}
+		if _, ok := balances[bookingID]; ok {
+			return nil, fmt.Errorf("booking %s has deferred revenue in more than one currency", bookingID)
+		}
+		balances[bookingID] = balance.In(currency)
+	}
+
+	return balances, nil
+}
+
+func (r *ledgerRepository) ListEntries(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]*models.JournalEntry, error) {
+	query := `
+		SELECT
+			e.id, e.entry_number, e.operator_id, e.entry_date, e.event_type, e.source_id,
+			e.booking_id, e.schedule_id, e.description, e.currency, e.created_by, e.created_at,
+			l.id, l.line_number, l.account_id, l.booking_id, l.debit, l.credit, l.memo,
+			COALESCE(a.system_key, ''), a.code, a.name, a.account_type
+		FROM journal_entries e
+		JOIN journal_lines l ON l.entry_id = e.id
+		JOIN ledger_accounts a ON l.account_id = a.id
+		WHERE e.operator_id = $1
+			AND e.entry_date >= $2
+			AND e.entry_date <= $3
+		ORDER BY e.entry_number, l.line_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, operatorID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journal entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.JournalEntry{}
+	var current *models.JournalEntry
+	for rows.Next() {
+		var entry models.JournalEntry
+		var line models.JournalLine
+		err := rows.Scan(
+			&entry.ID, &entry.EntryNumber, &entry.OperatorID, &entry.EntryDate, &entry.EventType,
+			&entry.SourceID, &entry.BookingID, &entry.ScheduleID, &entry.Description,
+			&entry.Currency, &entry.CreatedBy, &entry.CreatedAt,
+			&line.ID, &line.LineNumber, &line.AccountID, &line.BookingID,
+			&line.Debit, &line.Credit, &line.Memo,
+			&line.AccountKey, &line.AccountCode, &line.AccountName, &line.AccountType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan journal entry: %w", err)
+		}
+
+		if current == nil || current.ID != entry.ID {
+			current = &entry
+			entries = append(entries, current)
+		}
+		line.EntryID = current.ID
+		lineCurrency(&line, current.Currency)
+		current.Lines = append(current.Lines, line)
+	}
+
+	return entries, nil
+}
+
+func (r *ledgerRepository) GetTrialBalance(ctx context.Context, operatorID uuid.UUID, asOf time.Time) (*models.TrialBalance, error) {
+	query := `
+		SELECT
+			a.id, a.code, a.name, a.account_type,
+			COALESCE(SUM(l.debit), 0), COALESCE(SUM(l.credit), 0)
+		FROM ledger_accounts a
+		LEFT JOIN journal_lines l ON l.account_id = a.id
+			AND l.entry_id IN (
+				SELECT id FROM journal_entries WHERE operator_id = $1 AND entry_date <= $2
+			)
+		WHERE a.operator_id = $1
+		GROUP BY a.id, a.code, a.name, a.account_type
+		ORDER BY a.code
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, operatorID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trial balance: %w", err)
+	}
+	defer rows.Close()
+
+	balance := &models.TrialBalance{
+		OperatorID: operatorID,
+		AsOf:       asOf,
+		Accounts:   []*models.TrialBalanceLine{},
+	}
+
+	for rows.Next() {
+		line := &models.TrialBalanceLine{}
+		err := rows.Scan(&line.AccountID, &line.Code, &line.Name, &line.AccountType, &line.Debit, &line.Credit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trial balance: %w", err)
+		}
+
+		// Assets and expenses carry debit balances; everything else credit balances
+		if line.AccountType == "asset" || line.AccountType == "expense" {
+			line.Balance = line.Debit.Sub(line.Credit)
+		} else {
+			line.Balance = line.Credit.Sub(line.Debit)
+		}
+
+		balance.TotalDebit = balance.TotalDebit.Add(line.Debit)
+		balance.TotalCredit = balance.TotalCredit.Add(line.Credit)
+		balance.Accounts = append(balance.Accounts, line)
+	}
+
+	return balance, nil
+}
+
+// lineCurrency gives a scanned journal line's amounts its entry's currency
+func lineCurrency(line *models.JournalLine, currency string) {
+	line.Debit = line.Debit.In(currency)
+	line.Credit = line.Credit.In(currency)
+}
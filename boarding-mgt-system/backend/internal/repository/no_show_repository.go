@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/ferryflow/boarding-mgt-system/internal/noshow"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type NoShowRepository interface {
+	ProcessDeparture(ctx context.Context, scheduleID uuid.UUID, policy noshow.Policy, now time.Time) (*models.DepartureSummary, bool, error)
+	GetSummary(ctx context.Context, scheduleID uuid.UUID) (*models.DepartureSummary, error)
+	ListCustomerCredits(ctx context.Context, customerID uuid.UUID) ([]*models.TravelCredit, error)
+	GetNoShowCounts(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]models.NoShowCount, error)
+}
+
+type noShowRepository struct {
+	db *database.DB
+}
+
+func NewNoShowRepository(db *database.DB) NoShowRepository {
+	return &noShowRepository{db: db}
+}
+
+// ProcessDeparture marks the unboarded tickets of a departed sailing as
+// no-shows, issues travel credit as the policy allows and records the final
+// counts. A sailing is processed once; later calls return the recorded
+// summary and false.
+func (r *noShowRepository) ProcessDeparture(ctx context.Context, scheduleID uuid.UUID, policy noshow.Policy, now time.Time) (*models.DepartureSummary, bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var operatorID uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT operator_id FROM schedules WHERE id = $1`, scheduleID).Scan(&operatorID)
+	if err == pgx.ErrNoRows {
+		return nil, false, fmt.Errorf("schedule not found")
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	// Claim the sailing; a concurrent run waits here and then finds the
+	// summary already written
+	summary := &models.DepartureSummary{ScheduleID: scheduleID, NoShowPolicy: policy.Mode}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO departure_summaries (schedule_id, no_show_policy)
+		VALUES ($1, $2)
+		ON CONFLICT (schedule_id) DO NOTHING
+		RETURNING id
+	`, scheduleID, policy.Mode).Scan(&summary.ID)
+	if err == pgx.ErrNoRows {
+		tx.Rollback(ctx)
+		existing, err := r.GetSummary(ctx, scheduleID)
+		return existing, false, err
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create departure summary: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE tickets t SET
+			check_in_status = 'no_show',
+			updated_at = CURRENT_TIMESTAMP
+		FROM bookings b
+		WHERE t.booking_id = b.id
+			AND b.schedule_id = $1
+			AND b.booking_status = 'confirmed'
+			AND t.check_in_status <> 'boarded'
+		RETURNING t.id, t.ticket_price, b.id, b.customer_id
+	`, scheduleID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to mark no-shows: %w", err)
+	}
+
+	type noShowTicket struct {
+		ticketID, bookingID, customerID uuid.UUID
+		fare                            money.Money
+	}
+	tickets := []noShowTicket{}
+	for rows.Next() {
+		var t noShowTicket
+		if err := rows.Scan(&t.ticketID, &t.fare, &t.bookingID, &t.customerID); err != nil {
+			rows.Close()
+			return nil, false, fmt.Errorf("failed to scan no-show ticket: %w", err)
+		}
+		tickets = append(tickets, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to mark no-shows: %w", err)
+	}
+
+	creditQuery := `
+		INSERT INTO travel_credits (
+			operator_id, customer_id, booking_id, ticket_id, code, amount, reason, expires_at
+		) VALUES (
+			$1, $2, $3, $4, 'CR' || upper(substr(replace(gen_random_uuid()::text, '-', ''), 1, 10)),
+			$5, 'no_show', $6
+		)
+		RETURNING id, code, status, created_at
+	`
+
+	var fares money.Money
+	summary.Credits = []*models.TravelCredit{}
+	for _, t := range tickets {
+		fares = fares.Add(t.fare)
+
+		amount := policy.Credit(t.fare)
+		if !amount.IsPositive() {
+			continue
+		}
+
+		credit := &models.TravelCredit{
+			OperatorID: operatorID,
+			CustomerID: t.customerID,
+			BookingID:  t.bookingID,
+			TicketID:   t.ticketID,
+			Amount:     amount,
+			Reason:     "no_show",
+			ExpiresAt:  now.Add(policy.CreditValidity),
+		}
+		err := tx.QueryRow(ctx, creditQuery,
+			credit.OperatorID, credit.CustomerID, credit.BookingID, credit.TicketID,
+			credit.Amount, credit.ExpiresAt,
+		).Scan(&credit.ID, &credit.Code, &credit.Status, &credit.CreatedAt)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to issue travel credit: %w", err)
+		}
+
+		summary.CreditedAmount = summary.CreditedAmount.Add(amount)
+		summary.Credits = append(summary.Credits, credit)
+	}
+	summary.ForfeitedAmount = fares.Sub(summary.CreditedAmount)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE departure_summaries SET
+			total_passengers = c.total,
+			checked_in = c.checked_in,
+			boarded = c.boarded,
+			no_shows = c.no_shows,
+			forfeited_amount = $2,
+			credited_amount = $3,
+			processed_at = $4
+		FROM (
+			SELECT
+				COUNT(*) AS total,
+				COUNT(*) FILTER (WHERE t.check_in_time IS NOT NULL OR t.check_in_status = 'boarded') AS checked_in,
+				COUNT(*) FILTER (WHERE t.check_in_status = 'boarded') AS boarded,
+				COUNT(*) FILTER (WHERE t.check_in_status = 'no_show') AS no_shows
+			FROM tickets t
+			JOIN bookings b ON t.booking_id = b.id
+			WHERE b.schedule_id = $1 AND b.booking_status = 'confirmed'
+		) c
+		WHERE departure_summaries.schedule_id = $1
+		RETURNING total_passengers, checked_in, boarded, no_shows, processed_at
+	`, scheduleID, summary.ForfeitedAmount, summary.CreditedAmount, now).Scan(
+		&summary.TotalPassengers, &summary.CheckedIn, &summary.Boarded, &summary.NoShows, &summary.ProcessedAt,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record departure summary: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return summary, true, nil
+}
+
+func (r *noShowRepository) GetSummary(ctx context.Context, scheduleID uuid.UUID) (*models.DepartureSummary, error) {
+	query := `
+		SELECT id, schedule_id, total_passengers, checked_in, boarded, no_shows,
+			no_show_policy, forfeited_amount, credited_amount, processed_at
+		FROM departure_summaries
+		WHERE schedule_id = $1
+	`
+
+	summary := &models.DepartureSummary{}
+	err := r.db.Pool.QueryRow(ctx, query, scheduleID).Scan(
+		&summary.ID, &summary.ScheduleID, &summary.TotalPassengers, &summary.CheckedIn,
+		&summary.Boarded, &summary.NoShows, &summary.NoShowPolicy,
+		&summary.ForfeitedAmount, &summary.CreditedAmount, &summary.ProcessedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("departure summary not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get departure summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+func (r *noShowRepository) ListCustomerCredits(ctx context.Context, customerID uuid.UUID) ([]*models.TravelCredit, error) {
+	query := `
+		SELECT id, operator_id, customer_id, booking_id, ticket_id, code, amount,
+			reason, status, expires_at, created_at
+		FROM travel_credits
+		WHERE customer_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list travel credits: %w", err)
+	}
+	defer rows.Close()
+
+	credits := []*models.TravelCredit{}
+	for rows.Next() {
+		credit := &models.TravelCredit{}
+		err := rows.Scan(
+			&credit.ID, &credit.OperatorID, &credit.CustomerID, &credit.BookingID, &credit.TicketID,
+			&credit.Code, &credit.Amount, &credit.Reason, &credit.Status, &credit.ExpiresAt, &credit.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan travel credit: %w", err)
+		}
+		credits = append(credits, credit)
+	}
+
+	return credits, nil
+}
+
+// GetNoShowCounts counts passengers and no-shows on processed sailings
+// departing between two dates, by route and booking channel
+func (r *noShowRepository) GetNoShowCounts(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) ([]models.NoShowCount, error) {
+	query := `
+		SELECT
+			rt.id, rt.name, b.booking_channel,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE t.check_in_status = 'no_show')
+		FROM tickets t
+		JOIN bookings b ON t.booking_id = b.id
+		JOIN schedules s ON b.schedule_id = s.id
+		JOIN routes rt ON s.route_id = rt.id
+		JOIN departure_summaries ds ON ds.schedule_id = s.id
+		WHERE s.operator_id = $1
+			AND b.booking_status = 'confirmed'
+			AND s.departure_date BETWEEN $2 AND $3
+		GROUP BY rt.id, rt.name, b.booking_channel
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, operatorID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get no-show counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []models.NoShowCount{}
+	for rows.Next() {
+		var c models.NoShowCount
+		if err := rows.Scan(&c.RouteID, &c.RouteName, &c.BookingChannel, &c.Passengers, &c.NoShows); err != nil {
+			return nil, fmt.Errorf("failed to scan no-show count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, nil
+}
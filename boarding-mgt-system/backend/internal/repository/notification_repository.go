@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) (bool, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*models.Notification, error)
+	MarkRead(ctx context.Context, id, userID uuid.UUID) error
+}
+
+type notificationRepository struct {
+	db *database.DB
+}
+
+func NewNotificationRepository(db *database.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Create stores a notification. A notification whose dedupe key has been
+// used before is not stored again and false is returned.
+func (r *notificationRepository) Create(ctx context.Context, notification *models.Notification) (bool, error) {
+	var dedupeKey *string
+	if notification.DedupeKey != "" {
+		dedupeKey = &notification.DedupeKey
+	}
+	data := notification.Data
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	query := `
+		INSERT INTO notifications (user_id, kind, subject, body, data, dedupe_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (dedupe_key) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		notification.UserID, notification.Kind, notification.Subject, notification.Body, data, dedupeKey,
+	).Scan(&notification.ID, &notification.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *notificationRepository) ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	query := `
+		SELECT id, user_id, kind, subject, body, data, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		n := &models.Notification{}
+		err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Subject, &n.Body, &n.Data, &n.ReadAt, &n.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, id, userID uuid.UUID) error {
+	query := `
+		UPDATE notifications SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("notification not found")
+	}
+
+	return nil
+}
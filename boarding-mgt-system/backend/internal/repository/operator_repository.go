@@ -30,14 +30,16 @@ func NewOperatorRepository(db *database.DB) OperatorRepository {
 func (r *operatorRepository) Create(ctx context.Context, operator *models.Operator) error {
 	query := `
 		INSERT INTO operators (
-			name, code, contact_email, contact_phone, address, settings
-		) VALUES ($1, $2, $3, $4, $5, $6)
+			name, code, contact_email, contact_phone, address,
+			legal_name, tax_id, fiscal_year_start_month, settings
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, is_active, created_at, updated_at
 	`
 	
 	err := r.db.Pool.QueryRow(ctx, query,
 		operator.Name, operator.Code, operator.ContactEmail,
-		operator.ContactPhone, operator.Address, operator.Settings,
+		operator.ContactPhone, operator.Address, operator.LegalName,
+		operator.TaxID, operator.FiscalYearStartMonth, operator.Settings,
 	).Scan(&operator.ID, &operator.IsActive, &operator.CreatedAt, &operator.UpdatedAt)
 	
 	if err != nil {
@@ -51,6 +53,7 @@ func (r *operatorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	query := `
 		SELECT 
 			id, name, code, contact_email, contact_phone, address,
+			legal_name, tax_id, fiscal_year_start_month,
 			is_active, settings, created_at, updated_at
 		FROM operators
 		WHERE id = $1
@@ -59,7 +62,8 @@ func (r *operatorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	operator := &models.Operator{}
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&operator.ID, &operator.Name, &operator.Code, &operator.ContactEmail,
-		&operator.ContactPhone, &operator.Address, &operator.IsActive,
+		&operator.ContactPhone, &operator.Address, &operator.LegalName,
+		&operator.TaxID, &operator.FiscalYearStartMonth, &operator.IsActive,
 		&operator.Settings, &operator.CreatedAt, &operator.UpdatedAt,
 	)
 	
@@ -77,6 +81,7 @@ func (r *operatorRepository) GetByCode(ctx context.Context, code string) (*model
 	query := `
 		SELECT 
 			id, name, code, contact_email, contact_phone, address,
+			legal_name, tax_id, fiscal_year_start_month,
 			is_active, settings, created_at, updated_at
 		FROM operators
 		WHERE code = $1
@@ -85,7 +90,8 @@ func (r *operatorRepository) GetByCode(ctx context.Context, code string) (*model
 	operator := &models.Operator{}
 	err := r.db.Pool.QueryRow(ctx, query, code).Scan(
 		&operator.ID, &operator.Name, &operator.Code, &operator.ContactEmail,
-		&operator.ContactPhone, &operator.Address, &operator.IsActive,
+		&operator.ContactPhone, &operator.Address, &operator.LegalName,
+		&operator.TaxID, &operator.FiscalYearStartMonth, &operator.IsActive,
 		&operator.Settings, &operator.CreatedAt, &operator.UpdatedAt,
 	)
 	
@@ -106,8 +112,11 @@ func (r *operatorRepository) Update(ctx context.Context, operator *models.Operat
 			contact_email = $3,
 			contact_phone = $4,
 			address = $5,
-			is_active = $6,
-			settings = $7,
+			legal_name = $6,
+			tax_id = $7,
+			fiscal_year_start_month = $8,
+			is_active = $9,
+			settings = $10,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1
 		RETURNING updated_at
@@ -115,7 +124,8 @@ func (r *operatorRepository) Update(ctx context.Context, operator *models.Operat
 	
 	err := r.db.Pool.QueryRow(ctx, query,
 		operator.ID, operator.Name, operator.ContactEmail,
-		operator.ContactPhone, operator.Address, operator.IsActive, operator.Settings,
+		operator.ContactPhone, operator.Address, operator.LegalName, operator.TaxID,
+		operator.FiscalYearStartMonth, operator.IsActive, operator.Settings,
 	).Scan(&operator.UpdatedAt)
 	
 	if err == pgx.ErrNoRows {
@@ -147,6 +157,7 @@ func (r *operatorRepository) List(ctx context.Context, limit, offset int) ([]*mo
 	query := `
 		SELECT 
 			id, name, code, contact_email, contact_phone, address,
+			legal_name, tax_id, fiscal_year_start_month,
 			is_active, settings, created_at, updated_at
 		FROM operators
 		ORDER BY created_at DESC
@@ -186,7 +197,8 @@ func (r *operatorRepository) List(ctx context.Context, limit, offset int) ([]*mo
 		operator := &models.Operator{}
 		err := rows.Scan(
 			&operator.ID, &operator.Name, &operator.Code, &operator.ContactEmail,
-			&operator.ContactPhone, &operator.Address, &operator.IsActive,
+			&operator.ContactPhone, &operator.Address, &operator.LegalName,
+		&operator.TaxID, &operator.FiscalYearStartMonth, &operator.IsActive,
 			&operator.Settings, &operator.CreatedAt, &operator.UpdatedAt,
 		)
 		if err != nil {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ferryflow/boarding-mgt-system/internal/database"
 	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
@@ -14,9 +15,12 @@ import (
 type PaymentRepository interface {
 	Create(ctx context.Context, payment *models.Payment) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Payment, error)
-	GetByBooking(ctx context.Context, bookingID uuid.UUID) (*models.Payment, error)
+	ListByBooking(ctx context.Context, bookingID uuid.UUID) ([]models.Payment, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string, gatewayTransactionID *string) error
-	ProcessRefund(ctx context.Context, paymentID uuid.UUID, amount float64) error
+	CreateRefund(ctx context.Context, refund *models.Refund) error
+	GetRefund(ctx context.Context, id uuid.UUID) (*models.Refund, error)
+	MarkRefundProcessed(ctx context.Context, refund *models.Refund) error
+	HasPendingRefunds(ctx context.Context, bookingID uuid.UUID) (bool, error)
 	GetRevenueReport(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) (*models.RevenueReport, error)
 }
 
@@ -32,16 +36,17 @@ func (r *paymentRepository) Create(ctx context.Context, payment *models.Payment)
 	query := `
 		INSERT INTO payments (
 			booking_id, payment_method, amount, currency,
-			payment_status, gateway_transaction_id, gateway_response
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at, updated_at
+			payment_status, gateway_transaction_id, gateway_response,
+			tender_reference, shift_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, refunded_amount, reconciliation_status, created_at, updated_at
 	`
 	
 	err := r.db.Pool.QueryRow(ctx, query,
 		payment.BookingID, payment.PaymentMethod, payment.Amount,
 		payment.Currency, payment.PaymentStatus, payment.GatewayTransactionID,
-		payment.GatewayResponse,
-	).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+		payment.GatewayResponse, payment.TenderReference, payment.ShiftID,
+	).Scan(&payment.ID, &payment.RefundedAmount, &payment.ReconciliationStatus, &payment.CreatedAt, &payment.UpdatedAt)
 	
 	if err != nil {
 		return fmt.Errorf("failed to create payment: %w", err)
@@ -55,6 +60,8 @@ func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 		SELECT 
 			id, booking_id, payment_method, amount, currency,
 			payment_status, gateway_transaction_id, gateway_response,
+			tender_reference, refunded_amount, shift_id,
+			reconciliation_status, reconciled_at,
 			processed_at, created_at, updated_at
 		FROM payments
 		WHERE id = $1
@@ -65,6 +72,8 @@ func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 		&payment.ID, &payment.BookingID, &payment.PaymentMethod,
 		&payment.Amount, &payment.Currency, &payment.PaymentStatus,
 		&payment.GatewayTransactionID, &payment.GatewayResponse,
+		&payment.TenderReference, &payment.RefundedAmount, &payment.ShiftID,
+		&payment.ReconciliationStatus, &payment.ReconciledAt,
 		&payment.ProcessedAt, &payment.CreatedAt, &payment.UpdatedAt,
 	)
 	
@@ -74,38 +83,49 @@ func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 	if err != nil {
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
+	paymentCurrency(payment)
 	
 	return payment, nil
 }
 
-func (r *paymentRepository) GetByBooking(ctx context.Context, bookingID uuid.UUID) (*models.Payment, error) {
+func (r *paymentRepository) ListByBooking(ctx context.Context, bookingID uuid.UUID) ([]models.Payment, error) {
 	query := `
 		SELECT 
 			id, booking_id, payment_method, amount, currency,
 			payment_status, gateway_transaction_id, gateway_response,
+			tender_reference, refunded_amount, shift_id,
+			reconciliation_status, reconciled_at,
 			processed_at, created_at, updated_at
 		FROM payments
 		WHERE booking_id = $1
-		ORDER BY created_at DESC
-		LIMIT 1
+		ORDER BY created_at, id
 	`
 	
-	payment := &models.Payment{}
-	err := r.db.Pool.QueryRow(ctx, query, bookingID).Scan(
-		&payment.ID, &payment.BookingID, &payment.PaymentMethod,
-		&payment.Amount, &payment.Currency, &payment.PaymentStatus,
-		&payment.GatewayTransactionID, &payment.GatewayResponse,
-		&payment.ProcessedAt, &payment.CreatedAt, &payment.UpdatedAt,
-	)
-	
-	if err == pgx.ErrNoRows {
-		return nil, fmt.Errorf("payment not found")
-	}
+	rows, err := r.db.Pool.Query(ctx, query, bookingID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get payment: %w", err)
+		return nil, fmt.Errorf("failed to list payments: %w", err)
 	}
+	defer rows.Close()
 	
-	return payment, nil
+	payments := []models.Payment{}
+	for rows.Next() {
+		var payment models.Payment
+		err := rows.Scan(
+			&payment.ID, &payment.BookingID, &payment.PaymentMethod,
+			&payment.Amount, &payment.Currency, &payment.PaymentStatus,
+			&payment.GatewayTransactionID, &payment.GatewayResponse,
+			&payment.TenderReference, &payment.RefundedAmount, &payment.ShiftID,
+			&payment.ReconciliationStatus, &payment.ReconciledAt,
+			&payment.ProcessedAt, &payment.CreatedAt, &payment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		paymentCurrency(&payment)
+		payments = append(payments, payment)
+	}
+	
+	return payments, nil
 }
 
 func (r *paymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string, gatewayTransactionID *string) error {
@@ -126,42 +146,51 @@ func (r *paymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 	return nil
 }
 
-func (r *paymentRepository) ProcessRefund(ctx context.Context, paymentID uuid.UUID, amount float64) error {
+// CreateRefund records a refund against one payment tender and adds it to
+// the tender's refunded amount in the same transaction
+func (r *paymentRepository) CreateRefund(ctx context.Context, refund *models.Refund) error {
 	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 	
-	// Create refund record
-	refundQuery := `
-		INSERT INTO payments (
-			booking_id, payment_method, amount, currency,
-			payment_status, gateway_response
-		)
-		SELECT 
-			booking_id, payment_method, -$2, currency,
-			'refunded', jsonb_build_object('original_payment_id', $1, 'refund_amount', $2)
-		FROM payments
-		WHERE id = $1
+	query := `
+		INSERT INTO refunds (
+			booking_id, payment_id, refund_amount, refund_reason,
+			refund_status, processed_by, gateway_refund_id, shift_id, processed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
 	`
 	
-	_, err = tx.Exec(ctx, refundQuery, paymentID, amount)
+	err = tx.QueryRow(ctx, query,
+		refund.BookingID, refund.PaymentID, refund.RefundAmount, refund.RefundReason,
+		refund.RefundStatus, refund.ProcessedBy, refund.GatewayRefundID, refund.ShiftID,
+		refund.ProcessedAt,
+	).Scan(&refund.ID, &refund.CreatedAt, &refund.UpdatedAt)
+	
 	if err != nil {
-		return fmt.Errorf("failed to create refund record: %w", err)
+		return fmt.Errorf("failed to create refund: %w", err)
 	}
 	
-	// Update original payment status
+	// The amount check on payments rejects refunding more than the tender took
 	updateQuery := `
 		UPDATE payments SET
-			payment_status = 'refunded',
+			refunded_amount = refunded_amount + $2,
+			payment_status = CASE
+				WHEN refunded_amount + $2 >= amount THEN 'refunded'
+				ELSE 'partially_refunded'
+			END,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1
+		WHERE id = $1 AND payment_status IN ('completed', 'partially_refunded')
 	`
 	
-	_, err = tx.Exec(ctx, updateQuery, paymentID)
+	result, err := tx.Exec(ctx, updateQuery, refund.PaymentID, refund.RefundAmount)
 	if err != nil {
-		return fmt.Errorf("failed to update payment status: %w", err)
+		return fmt.Errorf("failed to update refunded amount: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("payment not found or not refundable")
 	}
 	
 	if err := tx.Commit(ctx); err != nil {
@@ -171,24 +200,111 @@ func (r *paymentRepository) ProcessRefund(ctx context.Context, paymentID uuid.UU
 	return nil
 }
 
+// GetRefund loads a refund with the currency of the payment it was made on
+func (r *paymentRepository) GetRefund(ctx context.Context, id uuid.UUID) (*models.Refund, error) {
+	query := `
+		SELECT 
+			rf.id, rf.booking_id, rf.payment_id, rf.refund_amount, p.currency,
+			rf.refund_reason, rf.refund_status, rf.processed_by, rf.gateway_refund_id,
+			rf.shift_id, rf.processed_at, rf.created_at, rf.updated_at
+		FROM refunds rf
+		JOIN payments p ON rf.payment_id = p.id
+		WHERE rf.id = $1
+	`
+	
+	refund := &models.Refund{}
+	var currency string
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&refund.ID, &refund.BookingID, &refund.PaymentID, &refund.RefundAmount, &currency,
+		&refund.RefundReason, &refund.RefundStatus, &refund.ProcessedBy, &refund.GatewayRefundID,
+		&refund.ShiftID, &refund.ProcessedAt, &refund.CreatedAt, &refund.UpdatedAt,
+	)
+	
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("refund not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+	refund.RefundAmount = refund.RefundAmount.In(currency)
+	
+	return refund, nil
+}
+
+// MarkRefundProcessed records that a pending refund was paid back. A refund
+// is only processed once, so concurrent calls cannot both succeed.
+func (r *paymentRepository) MarkRefundProcessed(ctx context.Context, refund *models.Refund) error {
+	query := `
+		UPDATE refunds SET
+			refund_status = 'processed',
+			processed_by = $2,
+			gateway_refund_id = COALESCE($3, gateway_refund_id),
+			processed_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND refund_status = 'pending'
+		RETURNING refund_status, gateway_refund_id, processed_at, updated_at
+	`
+	
+	err := r.db.Pool.QueryRow(ctx, query, refund.ID, refund.ProcessedBy, refund.GatewayRefundID).Scan(
+		&refund.RefundStatus, &refund.GatewayRefundID, &refund.ProcessedAt, &refund.UpdatedAt,
+	)
+	
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("refund is not pending")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to process refund: %w", err)
+	}
+	
+	return nil
+}
+
+// HasPendingRefunds reports whether any of a booking's refunds are still to be paid back
+func (r *paymentRepository) HasPendingRefunds(ctx context.Context, bookingID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM refunds WHERE booking_id = $1 AND refund_status = 'pending'
+		)
+	`
+	
+	var pending bool
+	if err := r.db.Pool.QueryRow(ctx, query, bookingID).Scan(&pending); err != nil {
+		return false, fmt.Errorf("failed to check pending refunds: %w", err)
+	}
+	
+	return pending, nil
+}
+
 func (r *paymentRepository) GetRevenueReport(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) (*models.RevenueReport, error) {
 	query := `
 		SELECT 
-			COALESCE(SUM(CASE WHEN p.amount > 0 THEN p.amount ELSE 0 END), 0) as total_revenue,
-			COALESCE(SUM(CASE WHEN p.amount < 0 THEN ABS(p.amount) ELSE 0 END), 0) as refunded_amount
-		FROM payments p
-		JOIN bookings b ON p.booking_id = b.id
-		JOIN schedules s ON b.schedule_id = s.id
-		WHERE s.operator_id = $1 
-			AND p.created_at >= $2 
-			AND p.created_at <= $3
-			AND p.payment_status IN ('completed', 'refunded')
+			COALESCE((
+				SELECT SUM(p.amount)
+				FROM payments p
+				JOIN bookings b ON p.booking_id = b.id
+				JOIN schedules s ON b.schedule_id = s.id
+				WHERE s.operator_id = $1 
+					AND p.created_at >= $2 
+					AND p.created_at <= $3
+					AND p.payment_status IN ('completed', 'partially_refunded', 'refunded')
+			), 0) as total_revenue,
+			COALESCE((
+				SELECT SUM(rf.refund_amount)
+				FROM refunds rf
+				JOIN bookings b ON rf.booking_id = b.id
+				JOIN schedules s ON b.schedule_id = s.id
+				WHERE s.operator_id = $1 
+					AND rf.created_at >= $2 
+					AND rf.created_at <= $3
+					AND rf.refund_status != 'failed'
+			), 0) as refunded_amount
 	`
 	
 	report := &models.RevenueReport{
 		PeriodStart:     startDate,
 		PeriodEnd:       endDate,
-		ByPaymentMethod: make(map[string]float64),
+		ByPaymentMethod: make(map[string]money.Money),
+		RefundsByPaymentMethod: make(map[string]money.Money),
 	}
 	
 	err := r.db.Pool.QueryRow(ctx, query, operatorID, startDate, endDate).Scan(
@@ -199,7 +315,7 @@ func (r *paymentRepository) GetRevenueReport(ctx context.Context, operatorID uui
 		return nil, fmt.Errorf("failed to get revenue report: %w", err)
 	}
 	
-	report.NetRevenue = report.TotalRevenue - report.RefundedAmount
+	report.NetRevenue = report.TotalRevenue.Sub(report.RefundedAmount)
 	
 	// Get breakdown by payment method
 	methodQuery := `
@@ -210,8 +326,7 @@ func (r *paymentRepository) GetRevenueReport(ctx context.Context, operatorID uui
 		WHERE s.operator_id = $1 
 			AND p.created_at >= $2 
 			AND p.created_at <= $3
-			AND p.payment_status = 'completed'
-			AND p.amount > 0
+			AND p.payment_status IN ('completed', 'partially_refunded', 'refunded')
 		GROUP BY payment_method
 	`
 	
@@ -223,12 +338,48 @@ func (r *paymentRepository) GetRevenueReport(ctx context.Context, operatorID uui
 	
 	for methodRows.Next() {
 		var method string
-		var amount float64
+		var amount money.Money
 		if err := methodRows.Scan(&method, &amount); err != nil {
 			return nil, fmt.Errorf("failed to scan method: %w", err)
 		}
 		report.ByPaymentMethod[method] = amount
 	}
 	
+	// Refunds are reported against the tender they were given back on
+	refundQuery := `
+		SELECT p.payment_method, SUM(rf.refund_amount)
+		FROM refunds rf
+		JOIN payments p ON rf.payment_id = p.id
+		JOIN bookings b ON rf.booking_id = b.id
+		JOIN schedules s ON b.schedule_id = s.id
+		WHERE s.operator_id = $1 
+			AND rf.created_at >= $2 
+			AND rf.created_at <= $3
+			AND rf.refund_status != 'failed'
+		GROUP BY p.payment_method
+	`
+	
+	refundRows, err := r.db.Pool.Query(ctx, refundQuery, operatorID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund breakdown: %w", err)
+	}
+	defer refundRows.Close()
+	
+	for refundRows.Next() {
+		var method string
+		var amount money.Money
+		if err := refundRows.Scan(&method, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan refund method: %w", err)
+		}
+		report.RefundsByPaymentMethod[method] = amount
+	}
+	
 	return report, nil
-}
\ No newline at end of file
+}
+
+// paymentCurrency gives a scanned payment's amounts the currency of its row,
+// as NUMERIC columns are read in the default currency
+func paymentCurrency(payment *models.Payment) {
+	payment.Amount = payment.Amount.In(payment.Currency)
+	payment.RefundedAmount = payment.RefundedAmount.In(payment.Currency)
+}
@@ -6,28 +6,60 @@ import (
 
 // Repositories holds all repository interfaces
 type Repositories struct {
-	User     UserRepository
-	Operator OperatorRepository
-	Port     PortRepository
-	Vessel   VesselRepository
-	Route    RouteRepository
-	Schedule ScheduleRepository
-	Booking  BookingRepository
-	Ticket   TicketRepository
-	Payment  PaymentRepository
+	User         UserRepository
+	Operator     OperatorRepository
+	Port         PortRepository
+	Vessel       VesselRepository
+	Route        RouteRepository
+	Schedule     ScheduleRepository
+	Template     ScheduleTemplateRepository
+	Timetable    TimetableVersionRepository
+	Booking      BookingRepository
+	Ticket       TicketRepository
+	Payment      PaymentRepository
+	Shift        ShiftRepository
+	Settlement   SettlementRepository
+	Ledger       LedgerRepository
+	Invoice      InvoiceRepository
+	Agency       AgencyRepository
+	Boarding     BoardingRepository
+	Scanner      ScannerDeviceRepository
+	Wallet       WalletPassRepository
+	NoShow       NoShowRepository
+	Cancellation CancellationRepository
+	Notification NotificationRepository
+	Disruption   DisruptionRepository
+	GTFS         GTFSRepository
+	Weather      WeatherRepository
 }
 
 // NewRepositories creates all repository instances
 func NewRepositories(db *database.DB) *Repositories {
 	return &Repositories{
-		User:     NewUserRepository(db),
-		Operator: NewOperatorRepository(db),
-		Port:     NewPortRepository(db),
-		Vessel:   NewVesselRepository(db),
-		Route:    NewRouteRepository(db),
-		Schedule: NewScheduleRepository(db),
-		Booking:  NewBookingRepository(db),
-		Ticket:   NewTicketRepository(db),
-		Payment:  NewPaymentRepository(db),
+		User:         NewUserRepository(db),
+		Operator:     NewOperatorRepository(db),
+		Port:         NewPortRepository(db),
+		Vessel:       NewVesselRepository(db),
+		Route:        NewRouteRepository(db),
+		Schedule:     NewScheduleRepository(db),
+		Template:     NewScheduleTemplateRepository(db),
+		Timetable:    NewTimetableVersionRepository(db),
+		Booking:      NewBookingRepository(db),
+		Ticket:       NewTicketRepository(db),
+		Payment:      NewPaymentRepository(db),
+		Shift:        NewShiftRepository(db),
+		Settlement:   NewSettlementRepository(db),
+		Ledger:       NewLedgerRepository(db),
+		Invoice:      NewInvoiceRepository(db),
+		Agency:       NewAgencyRepository(db),
+		Boarding:     NewBoardingRepository(db),
+		Scanner:      NewScannerDeviceRepository(db),
+		Wallet:       NewWalletPassRepository(db),
+		NoShow:       NewNoShowRepository(db),
+		Cancellation: NewCancellationRepository(db),
+		Notification: NewNotificationRepository(db),
+		Disruption:   NewDisruptionRepository(db),
+		GTFS:         NewGTFSRepository(db),
+		Weather:      NewWeatherRepository(db),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type ScannerDeviceRepository interface {
+	Create(ctx context.Context, device *models.ScannerDevice, keyHash string) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ScannerDevice, string, error)
+	Update(ctx context.Context, device *models.ScannerDevice) error
+	UpdateKey(ctx context.Context, id uuid.UUID, keyHash string) error
+	List(ctx context.Context, operatorID *uuid.UUID) ([]*models.ScannerDevice, error)
+	TouchSync(ctx context.Context, id uuid.UUID) error
+
+	// Schedule assignments
+	AssignSchedules(ctx context.Context, deviceID uuid.UUID, scheduleIDs []uuid.UUID, assignedBy uuid.UUID) error
+	UnassignSchedule(ctx context.Context, deviceID, scheduleID uuid.UUID) error
+	ListAssignedSchedules(ctx context.Context, deviceID uuid.UUID) ([]*models.BundleSchedule, error)
+	ListBundlePassengers(ctx context.Context, scheduleID uuid.UUID) ([]*models.BundlePassenger, error)
+}
+
+type scannerDeviceRepository struct {
+	db *database.DB
+}
+
+func NewScannerDeviceRepository(db *database.DB) ScannerDeviceRepository {
+	return &scannerDeviceRepository{db: db}
+}
+
+const scannerDeviceColumns = `
+	id, operator_id, name, device_type, is_active, registered_by,
+	last_sync_at, created_at, updated_at
+`
+
+func scanScannerDevice(row pgx.Row, extra ...any) (*models.ScannerDevice, error) {
+	d := &models.ScannerDevice{}
+	dest := []any{
+		&d.ID, &d.OperatorID, &d.Name, &d.DeviceType, &d.IsActive, &d.RegisteredBy,
+		&d.LastSyncAt, &d.CreatedAt, &d.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *scannerDeviceRepository) Create(ctx context.Context, device *models.ScannerDevice, keyHash string) error {
+	query := `
+		INSERT INTO scanner_devices (operator_id, name, device_type, key_hash, registered_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, is_active, created_at, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		device.OperatorID, device.Name, device.DeviceType, keyHash, device.RegisteredBy,
+	).Scan(&device.ID, &device.IsActive, &device.CreatedAt, &device.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create scanner device: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID returns a device with its assigned schedules and the hash of its key
+func (r *scannerDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ScannerDevice, string, error) {
+	query := `SELECT ` + scannerDeviceColumns + `, key_hash FROM scanner_devices WHERE id = $1`
+
+	var keyHash string
+	device, err := scanScannerDevice(r.db.Pool.QueryRow(ctx, query, id), &keyHash)
+	if err == pgx.ErrNoRows {
+		return nil, "", fmt.Errorf("scanner device not found")
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get scanner device: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT schedule_id FROM scanner_device_schedules
+		WHERE device_id = $1
+		ORDER BY created_at, schedule_id
+	`, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get device schedules: %w", err)
+	}
+	defer rows.Close()
+
+	device.ScheduleIDs = []uuid.UUID{}
+	for rows.Next() {
+		var scheduleID uuid.UUID
+		if err := rows.Scan(&scheduleID); err != nil {
+			return nil, "", fmt.Errorf("failed to scan device schedule: %w", err)
+		}
+		device.ScheduleIDs = append(device.ScheduleIDs, scheduleID)
+	}
+
+	return device, keyHash, nil
+}
+
+func (r *scannerDeviceRepository) Update(ctx context.Context, device *models.ScannerDevice) error {
+	query := `
+		UPDATE scanner_devices SET
+			name = $2,
+			is_active = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query, device.ID, device.Name, device.IsActive).Scan(&device.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("scanner device not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update scanner device: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateKey replaces a device's key, locking out the old one
+func (r *scannerDeviceRepository) UpdateKey(ctx context.Context, id uuid.UUID, keyHash string) error {
+	query := `UPDATE scanner_devices SET key_hash = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, keyHash)
+	if err != nil {
+		return fmt.Errorf("failed to update device key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("scanner device not found")
+	}
+
+	return nil
+}
+
+func (r *scannerDeviceRepository) List(ctx context.Context, operatorID *uuid.UUID) ([]*models.ScannerDevice, error) {
+	query := `SELECT ` + scannerDeviceColumns + ` FROM scanner_devices`
+	args := []interface{}{}
+
+	if operatorID != nil {
+		query += ` WHERE operator_id = $1`
+		args = append(args, *operatorID)
+	}
+	query += ` ORDER BY name`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scanner devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []*models.ScannerDevice{}
+	for rows.Next() {
+		device, err := scanScannerDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scanner device: %w", err)
+		}
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
+// TouchSync records that a device has just synced
+func (r *scannerDeviceRepository) TouchSync(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE scanner_devices SET last_sync_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	if _, err := r.db.Pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to record device sync: %w", err)
+	}
+
+	return nil
+}
+
+// AssignSchedules assigns schedules to a device. Only schedules run by the
+// device's operator are assigned; already assigned ones are left as they are.
+func (r *scannerDeviceRepository) AssignSchedules(ctx context.Context, deviceID uuid.UUID, scheduleIDs []uuid.UUID, assignedBy uuid.UUID) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO scanner_device_schedules (device_id, schedule_id, assigned_by)
+		SELECT d.id, s.id, $3
+		FROM scanner_devices d
+		JOIN schedules s ON s.operator_id = d.operator_id
+		WHERE d.id = $1 AND s.id = $2
+		ON CONFLICT (device_id, schedule_id) DO NOTHING
+	`
+
+	for _, scheduleID := range scheduleIDs {
+		result, err := tx.Exec(ctx, query, deviceID, scheduleID, assignedBy)
+		if err != nil {
+			return fmt.Errorf("failed to assign schedule: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			var exists bool
+			err := tx.QueryRow(ctx, `
+				SELECT EXISTS(SELECT 1 FROM scanner_device_schedules WHERE device_id = $1 AND schedule_id = $2)
+			`, deviceID, scheduleID).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("failed to check schedule assignment: %w", err)
+			}
+			if !exists {
+				return fmt.Errorf("schedule %s not found for this operator", scheduleID)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *scannerDeviceRepository) UnassignSchedule(ctx context.Context, deviceID, scheduleID uuid.UUID) error {
+	query := `DELETE FROM scanner_device_schedules WHERE device_id = $1 AND schedule_id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, deviceID, scheduleID)
+	if err != nil {
+		return fmt.Errorf("failed to unassign schedule: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("schedule is not assigned to this device")
+	}
+
+	return nil
+}
+
+// ListAssignedSchedules returns the sailings a device syncs, without their
+// passengers
+func (r *scannerDeviceRepository) ListAssignedSchedules(ctx context.Context, deviceID uuid.UUID) ([]*models.BundleSchedule, error) {
+	query := `
+		SELECT
+			s.id, s.status, rt.name, v.name,
+			COALESCE(s.estimated_departure_at, s.departure_at)
+		FROM scanner_device_schedules ds
+		JOIN schedules s ON ds.schedule_id = s.id
+		JOIN routes rt ON s.route_id = rt.id
+		JOIN vessels v ON s.vessel_id = v.id
+		WHERE ds.device_id = $1
+		ORDER BY COALESCE(s.estimated_departure_at, s.departure_at)
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []*models.BundleSchedule{}
+	for rows.Next() {
+		s := &models.BundleSchedule{}
+		if err := rows.Scan(&s.ScheduleID, &s.Status, &s.RouteName, &s.VesselName, &s.Departure); err != nil {
+			return nil, fmt.Errorf("failed to scan device schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+
+	return schedules, nil
+}
+
+// ListBundlePassengers returns every ticket of a sailing with the booking
+// state a scanner checks
+func (r *scannerDeviceRepository) ListBundlePassengers(ctx context.Context, scheduleID uuid.UUID) ([]*models.BundlePassenger, error) {
+	query := `
+		SELECT
+			t.id, b.booking_reference, t.passenger_name, t.passenger_type, t.seat_number,
+			b.booking_status, b.payment_status, t.check_in_status
+		FROM tickets t
+		JOIN bookings b ON t.booking_id = b.id
+		WHERE b.schedule_id = $1
+		ORDER BY t.passenger_name, t.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bundle passengers: %w", err)
+	}
+	defer rows.Close()
+
+	passengers := []*models.BundlePassenger{}
+	for rows.Next() {
+		p := &models.BundlePassenger{}
+		err := rows.Scan(
+			&p.TicketID, &p.BookingReference, &p.PassengerName, &p.PassengerType, &p.SeatNumber,
+			&p.BookingStatus, &p.PaymentStatus, &p.CheckInStatus,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bundle passenger: %w", err)
+		}
+		passengers = append(passengers, p)
+	}
+
+	return passengers, nil
+}
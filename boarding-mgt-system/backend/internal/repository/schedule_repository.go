@@ -2,13 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/ferryflow/boarding-mgt-system/internal/database"
 	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/porttime"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type ScheduleRepository interface {
@@ -20,6 +23,8 @@ type ScheduleRepository interface {
 	SearchSchedules(ctx context.Context, req *models.SearchScheduleRequest) ([]*models.Schedule, int, error)
 	GetByOperatorAndDate(ctx context.Context, operatorID uuid.UUID, date time.Time) ([]*models.Schedule, error)
 	GetUpcomingSchedules(ctx context.Context, limit int) ([]*models.Schedule, error)
+	ListLifecycleDue(ctx context.Context, from, until time.Time) ([]*models.Schedule, error)
+	Transition(ctx context.Context, schedule *models.Schedule, to string, at *time.Time) error
 }
 
 type scheduleRepository struct {
@@ -34,17 +39,23 @@ func (r *scheduleRepository) Create(ctx context.Context, schedule *models.Schedu
 	query := `
 		INSERT INTO schedules (
 			operator_id, route_id, vessel_id, departure_date, departure_time,
-			arrival_time, base_price, total_capacity, available_seats
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			arrival_time, departure_at, arrival_at, base_price, total_capacity,
+			available_seats, timetable_version_id, unpublished
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, status, version, created_at, updated_at
 	`
 	
 	err := r.db.Pool.QueryRow(ctx, query,
 		schedule.OperatorID, schedule.RouteID, schedule.VesselID,
 		schedule.DepartureDate, schedule.DepartureTime, schedule.ArrivalTime,
+		schedule.DepartureAt, schedule.ArrivalAt,
 		schedule.BasePrice, schedule.TotalCapacity, schedule.AvailableSeats,
+		schedule.TimetableVersionID, schedule.Unpublished,
 	).Scan(&schedule.ID, &schedule.Status, &schedule.Version, &schedule.CreatedAt, &schedule.UpdatedAt)
 	
+	if conflict := vesselConflict(ctx, r.db, err, schedule); conflict != nil {
+		return conflict
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create schedule: %w", err)
 	}
@@ -58,14 +69,19 @@ func (r *scheduleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 			s.id, s.operator_id, s.route_id, s.vessel_id, s.departure_date,
 			s.departure_time, s.arrival_time, s.base_price, s.total_capacity,
 			s.available_seats, s.status, s.cancellation_reason, s.version,
-			s.created_at, s.updated_at,
+			s.created_at, s.updated_at, s.departure_at, s.arrival_at,
+			s.estimated_departure_at, s.estimated_arrival_at, s.departure_gate,
+			s.timetable_version_id, s.unpublished, s.actual_departure_at, s.actual_arrival_at,
 			o.id, o.name, o.code,
 			r.id, r.name, r.departure_port_id, r.arrival_port_id,
-			v.id, v.name, v.registration_number, v.capacity
+			v.id, v.name, v.registration_number, v.capacity,
+			dp.timezone, ap.timezone
 		FROM schedules s
 		LEFT JOIN operators o ON s.operator_id = o.id
 		LEFT JOIN routes r ON s.route_id = r.id
 		LEFT JOIN vessels v ON s.vessel_id = v.id
+		LEFT JOIN ports dp ON r.departure_port_id = dp.id
+		LEFT JOIN ports ap ON r.arrival_port_id = ap.id
 		WHERE s.id = $1
 	`
 	
@@ -73,16 +89,20 @@ func (r *scheduleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	operator := &models.Operator{}
 	route := &models.Route{}
 	vessel := &models.Vessel{}
+	var departureTimezone, arrivalTimezone *string
 	
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&schedule.ID, &schedule.OperatorID, &schedule.RouteID, &schedule.VesselID,
 		&schedule.DepartureDate, &schedule.DepartureTime, &schedule.ArrivalTime,
 		&schedule.BasePrice, &schedule.TotalCapacity, &schedule.AvailableSeats,
 		&schedule.Status, &schedule.CancellationReason, &schedule.Version,
-		&schedule.CreatedAt, &schedule.UpdatedAt,
+		&schedule.CreatedAt, &schedule.UpdatedAt, &schedule.DepartureAt, &schedule.ArrivalAt,
+		&schedule.EstimatedDepartureAt, &schedule.EstimatedArrivalAt, &schedule.DepartureGate,
+		&schedule.TimetableVersionID, &schedule.Unpublished, &schedule.ActualDepartureAt, &schedule.ActualArrivalAt,
 		&operator.ID, &operator.Name, &operator.Code,
 		&route.ID, &route.Name, &route.DeparturePortID, &route.ArrivalPortID,
 		&vessel.ID, &vessel.Name, &vessel.RegistrationNumber, &vessel.Capacity,
+		&departureTimezone, &arrivalTimezone,
 	)
 	
 	if err == pgx.ErrNoRows {
@@ -95,6 +115,9 @@ func (r *scheduleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	schedule.Operator = operator
 	schedule.Route = route
 	schedule.Vessel = vessel
+	if departureTimezone != nil && arrivalTimezone != nil {
+		porttime.Localize(schedule, *departureTimezone, *arrivalTimezone)
+	}
 	
 	return schedule, nil
 }
@@ -107,6 +130,8 @@ func (r *scheduleRepository) Update(ctx context.Context, schedule *models.Schedu
 			arrival_time = $4,
 			base_price = $5,
 			status = $6,
+			departure_at = $8,
+			arrival_at = $9,
 			version = version + 1,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1 AND version = $7
@@ -116,11 +141,15 @@ func (r *scheduleRepository) Update(ctx context.Context, schedule *models.Schedu
 	err := r.db.Pool.QueryRow(ctx, query,
 		schedule.ID, schedule.DepartureDate, schedule.DepartureTime,
 		schedule.ArrivalTime, schedule.BasePrice, schedule.Status, schedule.Version,
+		schedule.DepartureAt, schedule.ArrivalAt,
 	).Scan(&schedule.Version, &schedule.UpdatedAt)
 	
 	if err == pgx.ErrNoRows {
 		return fmt.Errorf("schedule not found or version mismatch")
 	}
+	if conflict := vesselConflict(ctx, r.db, err, schedule); conflict != nil {
+		return conflict
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update schedule: %w", err)
 	}
@@ -166,15 +195,20 @@ func (r *scheduleRepository) SearchSchedules(ctx context.Context, req *models.Se
 			s.id, s.operator_id, s.route_id, s.vessel_id, s.departure_date,
 			s.departure_time, s.arrival_time, s.base_price, s.total_capacity,
 			s.available_seats, s.status, s.cancellation_reason, s.version,
-			s.created_at, s.updated_at
+			s.created_at, s.updated_at, s.departure_at, s.arrival_at,
+			s.estimated_departure_at, s.estimated_arrival_at, s.departure_gate,
+			dp.timezone, ap.timezone
 		FROM schedules s
 		JOIN routes r ON s.route_id = r.id
+		JOIN ports dp ON r.departure_port_id = dp.id
+		JOIN ports ap ON r.arrival_port_id = ap.id
 		WHERE r.departure_port_id = $1 
 			AND r.arrival_port_id = $2
 			AND s.departure_date = $3
 			AND s.status = 'scheduled'
+			AND NOT s.unpublished
 			AND s.available_seats >= $4
-		ORDER BY s.departure_time ASC
+		ORDER BY s.departure_at ASC
 	`
 	
 	passengerCount := req.PassengerCount
@@ -198,6 +232,7 @@ func (r *scheduleRepository) SearchSchedules(ctx context.Context, req *models.Se
 			AND r.arrival_port_id = $2
 			AND s.departure_date = $3
 			AND s.status = 'scheduled'
+			AND NOT s.unpublished
 			AND s.available_seats >= $4
 	`
 	
@@ -229,16 +264,20 @@ func (r *scheduleRepository) SearchSchedules(ctx context.Context, req *models.Se
 	schedules := []*models.Schedule{}
 	for rows.Next() {
 		schedule := &models.Schedule{}
+		var departureTimezone, arrivalTimezone string
 		err := rows.Scan(
 			&schedule.ID, &schedule.OperatorID, &schedule.RouteID, &schedule.VesselID,
 			&schedule.DepartureDate, &schedule.DepartureTime, &schedule.ArrivalTime,
 			&schedule.BasePrice, &schedule.TotalCapacity, &schedule.AvailableSeats,
 			&schedule.Status, &schedule.CancellationReason, &schedule.Version,
-			&schedule.CreatedAt, &schedule.UpdatedAt,
+			&schedule.CreatedAt, &schedule.UpdatedAt, &schedule.DepartureAt, &schedule.ArrivalAt,
+			&schedule.EstimatedDepartureAt, &schedule.EstimatedArrivalAt, &schedule.DepartureGate,
+			&departureTimezone, &arrivalTimezone,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan schedule: %w", err)
 		}
+		porttime.Localize(schedule, departureTimezone, arrivalTimezone)
 		schedules = append(schedules, schedule)
 	}
 	
@@ -248,13 +287,19 @@ func (r *scheduleRepository) SearchSchedules(ctx context.Context, req *models.Se
 func (r *scheduleRepository) GetByOperatorAndDate(ctx context.Context, operatorID uuid.UUID, date time.Time) ([]*models.Schedule, error) {
 	query := `
 		SELECT 
-			id, operator_id, route_id, vessel_id, departure_date,
-			departure_time, arrival_time, base_price, total_capacity,
-			available_seats, status, cancellation_reason, version,
-			created_at, updated_at
-		FROM schedules
-		WHERE operator_id = $1 AND departure_date = $2
-		ORDER BY departure_time ASC
+			s.id, s.operator_id, s.route_id, s.vessel_id, s.departure_date,
+			s.departure_time, s.arrival_time, s.base_price, s.total_capacity,
+			s.available_seats, s.status, s.cancellation_reason, s.version,
+			s.created_at, s.updated_at, s.departure_at, s.arrival_at,
+			s.estimated_departure_at, s.estimated_arrival_at, s.departure_gate,
+			s.timetable_version_id, s.unpublished,
+			dp.timezone, ap.timezone
+		FROM schedules s
+		JOIN routes r ON s.route_id = r.id
+		JOIN ports dp ON r.departure_port_id = dp.id
+		JOIN ports ap ON r.arrival_port_id = ap.id
+		WHERE s.operator_id = $1 AND s.departure_date = $2
+		ORDER BY s.departure_at ASC
 	`
 	
 	rows, err := r.db.Pool.Query(ctx, query, operatorID, date)
@@ -266,16 +311,21 @@ func (r *scheduleRepository) GetByOperatorAndDate(ctx context.Context, operatorI
 	schedules := []*models.Schedule{}
 	for rows.Next() {
 		schedule := &models.Schedule{}
+		var departureTimezone, arrivalTimezone string
 		err := rows.Scan(
 			&schedule.ID, &schedule.OperatorID, &schedule.RouteID, &schedule.VesselID,
 			&schedule.DepartureDate, &schedule.DepartureTime, &schedule.ArrivalTime,
 			&schedule.BasePrice, &schedule.TotalCapacity, &schedule.AvailableSeats,
 			&schedule.Status, &schedule.CancellationReason, &schedule.Version,
-			&schedule.CreatedAt, &schedule.UpdatedAt,
+			&schedule.CreatedAt, &schedule.UpdatedAt, &schedule.DepartureAt, &schedule.ArrivalAt,
+			&schedule.EstimatedDepartureAt, &schedule.EstimatedArrivalAt, &schedule.DepartureGate,
+			&schedule.TimetableVersionID, &schedule.Unpublished,
+			&departureTimezone, &arrivalTimezone,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan schedule: %w", err)
 		}
+		porttime.Localize(schedule, departureTimezone, arrivalTimezone)
 		schedules = append(schedules, schedule)
 	}
 	
@@ -285,14 +335,20 @@ func (r *scheduleRepository) GetByOperatorAndDate(ctx context.Context, operatorI
 func (r *scheduleRepository) GetUpcomingSchedules(ctx context.Context, limit int) ([]*models.Schedule, error) {
 	query := `
 		SELECT 
-			id, operator_id, route_id, vessel_id, departure_date,
-			departure_time, arrival_time, base_price, total_capacity,
-			available_seats, status, cancellation_reason, version,
-			created_at, updated_at
-		FROM schedules
-		WHERE departure_date >= CURRENT_DATE
-			AND status = 'scheduled'
-		ORDER BY departure_date ASC, departure_time ASC
+			s.id, s.operator_id, s.route_id, s.vessel_id, s.departure_date,
+			s.departure_time, s.arrival_time, s.base_price, s.total_capacity,
+			s.available_seats, s.status, s.cancellation_reason, s.version,
+			s.created_at, s.updated_at, s.departure_at, s.arrival_at,
+			s.estimated_departure_at, s.estimated_arrival_at, s.departure_gate,
+			dp.timezone, ap.timezone
+		FROM schedules s
+		JOIN routes r ON s.route_id = r.id
+		JOIN ports dp ON r.departure_port_id = dp.id
+		JOIN ports ap ON r.arrival_port_id = ap.id
+		WHERE s.departure_at >= CURRENT_TIMESTAMP
+			AND s.status = 'scheduled'
+			AND NOT s.unpublished
+		ORDER BY s.departure_at ASC
 		LIMIT $1
 	`
 	
@@ -305,18 +361,118 @@ func (r *scheduleRepository) GetUpcomingSchedules(ctx context.Context, limit int
 	schedules := []*models.Schedule{}
 	for rows.Next() {
 		schedule := &models.Schedule{}
+		var departureTimezone, arrivalTimezone string
 		err := rows.Scan(
 			&schedule.ID, &schedule.OperatorID, &schedule.RouteID, &schedule.VesselID,
 			&schedule.DepartureDate, &schedule.DepartureTime, &schedule.ArrivalTime,
 			&schedule.BasePrice, &schedule.TotalCapacity, &schedule.AvailableSeats,
 			&schedule.Status, &schedule.CancellationReason, &schedule.Version,
-			&schedule.CreatedAt, &schedule.UpdatedAt,
+			&schedule.CreatedAt, &schedule.UpdatedAt, &schedule.DepartureAt, &schedule.ArrivalAt,
+			&schedule.EstimatedDepartureAt, &schedule.EstimatedArrivalAt, &schedule.DepartureGate,
+			&departureTimezone, &arrivalTimezone,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan schedule: %w", err)
 		}
+		porttime.Localize(schedule, departureTimezone, arrivalTimezone)
 		schedules = append(schedules, schedule)
 	}
 	
 	return schedules, nil
-}
\ No newline at end of file
+}
+
+// ListLifecycleDue lists the sailings on sale that have not departed and
+// are expected to leave between from and until
+func (r *scheduleRepository) ListLifecycleDue(ctx context.Context, from, until time.Time) ([]*models.Schedule, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT
+			id, operator_id, status, departure_at, arrival_at,
+			estimated_departure_at, estimated_arrival_at, unpublished
+		FROM schedules
+		WHERE status IN ('scheduled', 'boarding') AND NOT unpublished
+			AND departure_at <= $2
+			AND COALESCE(estimated_departure_at, departure_at) BETWEEN $1 AND $2
+		ORDER BY COALESCE(estimated_departure_at, departure_at), id
+	`, from, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules due: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []*models.Schedule{}
+	for rows.Next() {
+		schedule := &models.Schedule{}
+		err := rows.Scan(
+			&schedule.ID, &schedule.OperatorID, &schedule.Status, &schedule.DepartureAt, &schedule.ArrivalAt,
+			&schedule.EstimatedDepartureAt, &schedule.EstimatedArrivalAt, &schedule.Unpublished,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+// Transition moves a sailing from its current status to another, recording
+// at as its actual departure or arrival when it departs or arrives. Moving
+// a sailing to the status it has corrects that time. It fails if the
+// sailing's status changed since it was read.
+func (r *scheduleRepository) Transition(ctx context.Context, schedule *models.Schedule, to string, at *time.Time) error {
+	err := r.db.Pool.QueryRow(ctx, `
+		UPDATE schedules SET
+			status = $3,
+			actual_departure_at = CASE WHEN $3 = 'departed' THEN COALESCE($4, actual_departure_at) ELSE actual_departure_at END,
+			actual_arrival_at = CASE WHEN $3 = 'arrived' THEN COALESCE($4, actual_arrival_at) ELSE actual_arrival_at END,
+			version = version + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $2
+		RETURNING status, actual_departure_at, actual_arrival_at, version, updated_at
+	`, schedule.ID, schedule.Status, to, at,
+	).Scan(&schedule.Status, &schedule.ActualDepartureAt, &schedule.ActualArrivalAt, &schedule.Version, &schedule.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("schedule status has changed, reload it and try again")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to change schedule status: %w", err)
+	}
+
+	return nil
+}
+
+// vesselAllocationConstraint keeps a vessel off overlapping sailings
+const vesselAllocationConstraint = "no_vessel_double_allocation"
+
+// vesselConflict turns a breach of the vessel allocation constraint by a
+// schedule write into a VesselConflictError naming the sailing the vessel is
+// already on. It returns nil for any other error.
+func vesselConflict(ctx context.Context, db *database.DB, err error, schedule *models.Schedule) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.ConstraintName != vesselAllocationConstraint {
+		return nil
+	}
+
+	conflict := &models.VesselConflictError{VesselID: schedule.VesselID}
+
+	// The failed write was rolled back, so this only finds committed
+	// sailings; without one the error just reports the clash
+	query := `
+		SELECT id, departure_date, departure_time, arrival_time
+		FROM schedules
+		WHERE vessel_id = $1 AND id <> $2 AND status <> 'cancelled' AND NOT unpublished
+			AND vessel_period && schedule_vessel_period($1, tstzrange($3, $4, '[)'))
+		ORDER BY vessel_period
+		LIMIT 1
+	`
+
+	conflicting := &models.Schedule{VesselID: schedule.VesselID}
+	err = db.Pool.QueryRow(ctx, query,
+		schedule.VesselID, schedule.ID, schedule.DepartureAt, schedule.ArrivalAt,
+	).Scan(&conflicting.ID, &conflicting.DepartureDate, &conflicting.DepartureTime, &conflicting.ArrivalTime)
+	if err == nil {
+		conflict.Conflicting = conflicting
+	}
+
+	return conflict
+}
@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/timetable"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type ScheduleTemplateRepository interface {
+	Create(ctx context.Context, template *models.ScheduleTemplate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ScheduleTemplate, error)
+	Update(ctx context.Context, template *models.ScheduleTemplate) error
+	List(ctx context.Context, operatorID *uuid.UUID) ([]*models.ScheduleTemplate, error)
+	ListInstances(ctx context.Context, templateID uuid.UUID, from, until time.Time) ([]models.TemplateInstance, error)
+	ApplyPlan(ctx context.Context, template *models.ScheduleTemplate, plan *timetable.Plan, until time.Time) error
+}
+
+type scheduleTemplateRepository struct {
+	db *database.DB
+}
+
+func NewScheduleTemplateRepository(db *database.DB) ScheduleTemplateRepository {
+	return &scheduleTemplateRepository{db: db}
+}
+
+const scheduleTemplateColumns = `
+	id, operator_id, route_id, vessel_id, name, base_price, valid_from, valid_until,
+	weekdays, departures, exception_dates, skip_holidays, is_active, generated_until,
+	version, created_at, updated_at
+`
+
+func scanScheduleTemplate(row pgx.Row) (*models.ScheduleTemplate, error) {
+	template := &models.ScheduleTemplate{}
+	err := row.Scan(
+		&template.ID, &template.OperatorID, &template.RouteID, &template.VesselID, &template.Name,
+		&template.BasePrice, &template.ValidFrom, &template.ValidUntil, &template.Weekdays,
+		&template.Departures, &template.ExceptionDates, &template.SkipHolidays, &template.IsActive,
+		&template.GeneratedUntil, &template.Version, &template.CreatedAt, &template.UpdatedAt,
+	)
+	return template, err
+}
+
+func (r *scheduleTemplateRepository) Create(ctx context.Context, template *models.ScheduleTemplate) error {
+	query := `
+		INSERT INTO schedule_templates (
+			operator_id, route_id, vessel_id, name, base_price, valid_from, valid_until,
+			weekdays, departures, exception_dates, skip_holidays
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, is_active, version, created_at, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		template.OperatorID, template.RouteID, template.VesselID, template.Name, template.BasePrice,
+		template.ValidFrom, template.ValidUntil, template.Weekdays, template.Departures,
+		template.ExceptionDates, template.SkipHolidays,
+	).Scan(&template.ID, &template.IsActive, &template.Version, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create schedule template: %w", err)
+	}
+
+	return nil
+}
+
+func (r *scheduleTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ScheduleTemplate, error) {
+	query := `SELECT ` + scheduleTemplateColumns + ` FROM schedule_templates WHERE id = $1`
+
+	template, err := scanScheduleTemplate(r.db.Pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("schedule template not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule template: %w", err)
+	}
+
+	return template, nil
+}
+
+// Update saves a template and moves it on to the next version, so that the
+// schedules generated from it are brought up to date on the next run
+func (r *scheduleTemplateRepository) Update(ctx context.Context, template *models.ScheduleTemplate) error {
+	query := `
+		UPDATE schedule_templates SET
+			vessel_id = $2,
+			name = $3,
+			base_price = $4,
+			valid_from = $5,
+			valid_until = $6,
+			weekdays = $7,
+			departures = $8,
+			exception_dates = $9,
+			skip_holidays = $10,
+			is_active = $11,
+			version = version + 1
+		WHERE id = $1 AND version = $12
+		RETURNING version, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		template.ID, template.VesselID, template.Name, template.BasePrice, template.ValidFrom,
+		template.ValidUntil, template.Weekdays, template.Departures, template.ExceptionDates,
+		template.SkipHolidays, template.IsActive, template.Version,
+	).Scan(&template.Version, &template.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("schedule template not found or version mismatch")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update schedule template: %w", err)
+	}
+
+	return nil
+}
+
+func (r *scheduleTemplateRepository) List(ctx context.Context, operatorID *uuid.UUID) ([]*models.ScheduleTemplate, error) {
+	query := `SELECT ` + scheduleTemplateColumns + ` FROM schedule_templates`
+	args := []interface{}{}
+
+	if operatorID != nil {
+		query += ` WHERE operator_id = $1`
+		args = append(args, *operatorID)
+	}
+	query += ` ORDER BY name, valid_from`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedule templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []*models.ScheduleTemplate{}
+	for rows.Next() {
+		template, err := scanScheduleTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+// ListInstances lists the schedules generated from a template departing
+// between two dates and whether each has any bookings
+func (r *scheduleTemplateRepository) ListInstances(ctx context.Context, templateID uuid.UUID, from, until time.Time) ([]models.TemplateInstance, error) {
+	query := `
+		SELECT
+			s.id, s.operator_id, s.route_id, s.vessel_id, s.departure_date,
+			s.departure_time, s.arrival_time, s.base_price, s.total_capacity,
+			s.available_seats, s.status, s.cancellation_reason, s.version,
+			s.created_at, s.updated_at, s.template_id, s.template_version,
+			s.departure_at, s.arrival_at,
+			EXISTS (SELECT 1 FROM bookings b WHERE b.schedule_id = s.id)
+		FROM schedules s
+		WHERE s.template_id = $1 AND s.departure_date BETWEEN $2 AND $3
+		ORDER BY s.departure_date, s.departure_time
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, templateID, from, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list template schedules: %w", err)
+	}
+	defer rows.Close()
+
+	instances := []models.TemplateInstance{}
+	for rows.Next() {
+		schedule := &models.Schedule{}
+		var booked bool
+		err := rows.Scan(
+			&schedule.ID, &schedule.OperatorID, &schedule.RouteID, &schedule.VesselID,
+			&schedule.DepartureDate, &schedule.DepartureTime, &schedule.ArrivalTime,
+			&schedule.BasePrice, &schedule.TotalCapacity, &schedule.AvailableSeats,
+			&schedule.Status, &schedule.CancellationReason, &schedule.Version,
+			&schedule.CreatedAt, &schedule.UpdatedAt, &schedule.TemplateID, &schedule.TemplateVersion,
+			&schedule.DepartureAt, &schedule.ArrivalAt, &booked,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template schedule: %w", err)
+		}
+		instances = append(instances, models.TemplateInstance{Schedule: schedule, Booked: booked})
+	}
+
+	return instances, nil
+}
+
+// ApplyPlan carries out a template run in one transaction and records how
+// far the template has been generated. Updates and removals only go ahead
+// while the schedule is still scheduled and unbooked, and departures another
+// run has already created are skipped; the plan is trimmed to what was done.
+// A departure that would put the vessel on two sailings at once fails the
+// whole run with a VesselConflictError.
+func (r *scheduleTemplateRepository) ApplyPlan(ctx context.Context, template *models.ScheduleTemplate, plan *timetable.Plan, until time.Time) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	createQuery := `
+		INSERT INTO schedules (
+			operator_id, route_id, vessel_id, departure_date, departure_time,
+			arrival_time, departure_at, arrival_at, base_price, total_capacity,
+			available_seats, template_id, template_version
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (template_id, departure_date, departure_time) WHERE template_id IS NOT NULL DO NOTHING
+		RETURNING id, status, version, created_at, updated_at
+	`
+
+	created := []*models.Schedule{}
+	for _, schedule := range plan.Create {
+		err := tx.QueryRow(ctx, createQuery,
+			schedule.OperatorID, schedule.RouteID, schedule.VesselID,
+			schedule.DepartureDate, schedule.DepartureTime, schedule.ArrivalTime,
+			schedule.DepartureAt, schedule.ArrivalAt,
+			schedule.BasePrice, schedule.TotalCapacity, schedule.AvailableSeats,
+			schedule.TemplateID, schedule.TemplateVersion,
+		).Scan(&schedule.ID, &schedule.Status, &schedule.Version, &schedule.CreatedAt, &schedule.UpdatedAt)
+		if err == pgx.ErrNoRows {
+			continue
+		}
+		if conflict := vesselConflict(ctx, r.db, err, schedule); conflict != nil {
+			return conflict
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create schedule: %w", err)
+		}
+		created = append(created, schedule)
+	}
+	plan.Create = created
+
+	unbooked := `
+		status = 'scheduled'
+		AND NOT EXISTS (SELECT 1 FROM bookings b WHERE b.schedule_id = schedules.id)
+	`
+
+	updateQuery := `
+		UPDATE schedules SET
+			vessel_id = $2,
+			arrival_time = $3,
+			base_price = $4,
+			total_capacity = $5,
+			available_seats = $5,
+			template_version = $6,
+			departure_at = $7,
+			arrival_at = $8,
+			version = version + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND ` + unbooked + `
+		RETURNING version, updated_at
+	`
+
+	updated := []*models.Schedule{}
+	for _, schedule := range plan.Update {
+		err := tx.QueryRow(ctx, updateQuery,
+			schedule.ID, schedule.VesselID, schedule.ArrivalTime, schedule.BasePrice,
+			schedule.TotalCapacity, schedule.TemplateVersion, schedule.DepartureAt, schedule.ArrivalAt,
+		).Scan(&schedule.Version, &schedule.UpdatedAt)
+		if err == pgx.ErrNoRows {
+			plan.Keep = append(plan.Keep, schedule)
+			continue
+		}
+		if conflict := vesselConflict(ctx, r.db, err, schedule); conflict != nil {
+			return conflict
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update schedule: %w", err)
+		}
+		updated = append(updated, schedule)
+	}
+	plan.Update = updated
+
+	removed := []*models.Schedule{}
+	for _, schedule := range plan.Remove {
+		tag, err := tx.Exec(ctx, `DELETE FROM schedules WHERE id = $1 AND `+unbooked, schedule.ID)
+		if err != nil {
+			return fmt.Errorf("failed to remove schedule: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			plan.Keep = append(plan.Keep, schedule)
+			continue
+		}
+		removed = append(removed, schedule)
+	}
+	plan.Remove = removed
+
+	_, err = tx.Exec(ctx, `
+		UPDATE schedule_templates SET generated_until = GREATEST(COALESCE(generated_until, $2), $2)
+		WHERE id = $1
+	`, template.ID, until)
+	if err != nil {
+		return fmt.Errorf("failed to record template generation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,507 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type SettlementRepository interface {
+	CreateProvider(ctx context.Context, provider *models.SettlementProvider) error
+	UpdateProvider(ctx context.Context, provider *models.SettlementProvider) error
+	GetProviderByCode(ctx context.Context, code string) (*models.SettlementProvider, error)
+	ListProviders(ctx context.Context) ([]*models.SettlementProvider, error)
+	ImportExists(ctx context.Context, providerID uuid.UUID, fileHash string) (bool, error)
+	GetPaymentsByGatewayIDs(ctx context.Context, gatewayIDs []string) (map[string]*models.Payment, error)
+	SaveImport(ctx context.Context, imp *models.SettlementImport, lines []*models.SettlementLine, paymentMethods []string, missingCutoff *time.Time) error
+	GetImport(ctx context.Context, id uuid.UUID) (*models.SettlementImport, error)
+	ListImports(ctx context.Context, limit, offset int) ([]*models.SettlementImport, error)
+	GetUnreconciledPayments(ctx context.Context) ([]*models.UnreconciledPayment, error)
+	GetUnresolvedLines(ctx context.Context) ([]*models.SettlementLine, error)
+	ResolvePayment(ctx context.Context, paymentID uuid.UUID, notes string) error
+	ResolveLine(ctx context.Context, lineID, resolvedBy uuid.UUID, notes string) error
+}
+
+type settlementRepository struct {
+	db *database.DB
+}
+
+func NewSettlementRepository(db *database.DB) SettlementRepository {
+	return &settlementRepository{db: db}
+}
+
+const settlementProviderColumns = `
+	id, code, name, column_mapping, delimiter, date_format, payment_methods,
+	settlement_delay_days, is_active, created_at, updated_at
+`
+
+func scanSettlementProvider(row pgx.Row, provider *models.SettlementProvider) error {
+	return row.Scan(
+		&provider.ID, &provider.Code, &provider.Name, &provider.ColumnMapping,
+		&provider.Delimiter, &provider.DateFormat, &provider.PaymentMethods,
+		&provider.SettlementDelayDays, &provider.IsActive, &provider.CreatedAt, &provider.UpdatedAt,
+	)
+}
+
+const settlementLineColumns = `
+	id, import_id, row_number, gateway_transaction_id, currency,
+	gross_amount, fee_amount, net_amount, settled_at, payout_reference,
+	payment_id, match_status, amount_variance, resolved_by, resolved_at,
+	resolution_notes, created_at
+`
+
+func scanSettlementLine(row pgx.Row, line *models.SettlementLine) error {
+	return row.Scan(
+		&line.ID, &line.ImportID, &line.RowNumber, &line.GatewayTransactionID, &line.Currency,
+		&line.GrossAmount, &line.FeeAmount, &line.NetAmount, &line.SettledAt, &line.PayoutReference,
+		&line.PaymentID, &line.MatchStatus, &line.AmountVariance, &line.ResolvedBy, &line.ResolvedAt,
+		&line.ResolutionNotes, &line.CreatedAt,
+	)
+}
+
+func (r *settlementRepository) CreateProvider(ctx context.Context, provider *models.SettlementProvider) error {
+	query := `
+		INSERT INTO settlement_providers (
+			code, name, column_mapping, delimiter, date_format,
+			payment_methods, settlement_delay_days
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, is_active, created_at, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		provider.Code, provider.Name, provider.ColumnMapping, provider.Delimiter,
+		provider.DateFormat, provider.PaymentMethods, provider.SettlementDelayDays,
+	).Scan(&provider.ID, &provider.IsActive, &provider.CreatedAt, &provider.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create settlement provider: %w", err)
+	}
+
+	return nil
+}
+
+func (r *settlementRepository) UpdateProvider(ctx context.Context, provider *models.SettlementProvider) error {
+	query := `
+		UPDATE settlement_providers SET
+			name = $2,
+			column_mapping = $3,
+			delimiter = $4,
+			date_format = $5,
+			payment_methods = $6,
+			settlement_delay_days = $7,
+			is_active = $8,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		provider.ID, provider.Name, provider.ColumnMapping, provider.Delimiter,
+		provider.DateFormat, provider.PaymentMethods, provider.SettlementDelayDays,
+		provider.IsActive,
+	).Scan(&provider.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("settlement provider not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update settlement provider: %w", err)
+	}
+
+	return nil
+}
+
+func (r *settlementRepository) GetProviderByCode(ctx context.Context, code string) (*models.SettlementProvider, error) {
+	query := `SELECT ` + settlementProviderColumns + ` FROM settlement_providers WHERE code = $1`
+
+	provider := &models.SettlementProvider{}
+	err := scanSettlementProvider(r.db.Pool.QueryRow(ctx, query, code), provider)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("settlement provider not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement provider: %w", err)
+	}
+
+	return provider, nil
+}
+
+func (r *settlementRepository) ListProviders(ctx context.Context) ([]*models.SettlementProvider, error) {
+	query := `SELECT ` + settlementProviderColumns + ` FROM settlement_providers ORDER BY name`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlement providers: %w", err)
+	}
+	defer rows.Close()
+
+	providers := []*models.SettlementProvider{}
+	for rows.Next() {
+		provider := &models.SettlementProvider{}
+		if err := scanSettlementProvider(rows, provider); err != nil {
+			return nil, fmt.Errorf("failed to scan settlement provider: %w", err)
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
+
+func (r *settlementRepository) ImportExists(ctx context.Context, providerID uuid.UUID, fileHash string) (bool, error) {
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM settlement_imports WHERE provider_id = $1 AND file_hash = $2
+		)
+	`, providerID, fileHash).Scan(&exists)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check settlement import: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *settlementRepository) GetPaymentsByGatewayIDs(ctx context.Context, gatewayIDs []string) (map[string]*models.Payment, error) {
+	query := `
+		SELECT
+			id, booking_id, payment_method, amount, currency,
+			payment_status, gateway_transaction_id, reconciliation_status,
+			reconciled_at, processed_at, created_at, updated_at
+		FROM payments
+		WHERE gateway_transaction_id = ANY($1)
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, gatewayIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := make(map[string]*models.Payment, len(gatewayIDs))
+	for rows.Next() {
+		payment := &models.Payment{}
+		err := rows.Scan(
+			&payment.ID, &payment.BookingID, &payment.PaymentMethod, &payment.Amount,
+			&payment.Currency, &payment.PaymentStatus, &payment.GatewayTransactionID,
+			&payment.ReconciliationStatus, &payment.ReconciledAt, &payment.ProcessedAt,
+			&payment.CreatedAt, &payment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		paymentCurrency(payment)
+		// Later attempts with the same gateway reference replace earlier ones
+		payments[*payment.GatewayTransactionID] = payment
+	}
+
+	return payments, nil
+}
+
+func (r *settlementRepository) SaveImport(ctx context.Context, imp *models.SettlementImport, lines []*models.SettlementLine, paymentMethods []string, missingCutoff *time.Time) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	importQuery := `
+		INSERT INTO settlement_imports (
+			provider_id, file_name, file_hash, row_count, matched_count,
+			fee_deducted_count, amount_mismatch_count, unknown_count,
+			duplicate_count, period_start, period_end, imported_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRow(ctx, importQuery,
+		imp.ProviderID, imp.FileName, imp.FileHash, imp.RowCount, imp.MatchedCount,
+		imp.FeeDeductedCount, imp.AmountMismatchCount, imp.UnknownCount,
+		imp.DuplicateCount, imp.PeriodStart, imp.PeriodEnd, imp.ImportedBy,
+	).Scan(&imp.ID, &imp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create settlement import: %w", err)
+	}
+
+	lineQuery := `
+		INSERT INTO settlement_lines (
+			import_id, row_number, gateway_transaction_id, currency,
+			gross_amount, fee_amount, net_amount, settled_at, payout_reference,
+			payment_id, match_status, amount_variance
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at
+	`
+
+	paymentQuery := `
+		UPDATE payments SET
+			reconciliation_status = $2,
+			settlement_line_id = $3,
+			reconciled_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	for _, line := range lines {
+		line.ImportID = imp.ID
+		err := tx.QueryRow(ctx, lineQuery,
+			line.ImportID, line.RowNumber, line.GatewayTransactionID, line.Currency,
+			line.GrossAmount, line.FeeAmount, line.NetAmount, line.SettledAt,
+			line.PayoutReference, line.PaymentID, line.MatchStatus, line.AmountVariance,
+		).Scan(&line.ID, &line.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create settlement line %d: %w", line.RowNumber, err)
+		}
+
+		if line.PaymentID == nil || line.MatchStatus == "duplicate" {
+			continue
+		}
+
+		if _, err := tx.Exec(ctx, paymentQuery, *line.PaymentID, line.MatchStatus, line.ID); err != nil {
+			return fmt.Errorf("failed to update payment reconciliation: %w", err)
+		}
+	}
+
+	// Payments that should have been settled by the end of this file but were not
+	if missingCutoff != nil && len(paymentMethods) > 0 {
+		missingQuery := `
+			UPDATE payments SET
+				reconciliation_status = 'missing_payout',
+				updated_at = CURRENT_TIMESTAMP
+			WHERE reconciliation_status = 'unreconciled'
+				AND payment_status IN ('completed', 'partially_refunded', 'refunded')
+				AND gateway_transaction_id IS NOT NULL
+				AND payment_method = ANY($1)
+				AND processed_at < $2
+		`
+
+		result, err := tx.Exec(ctx, missingQuery, paymentMethods, *missingCutoff)
+		if err != nil {
+			return fmt.Errorf("failed to flag missing payouts: %w", err)
+		}
+		imp.MissingPayoutCount = int(result.RowsAffected())
+
+		if _, err := tx.Exec(ctx, `
+			UPDATE settlement_imports SET missing_payout_count = $2 WHERE id = $1
+		`, imp.ID, imp.MissingPayoutCount); err != nil {
+			return fmt.Errorf("failed to update settlement import: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *settlementRepository) GetImport(ctx context.Context, id uuid.UUID) (*models.SettlementImport, error) {
+	query := `
+		SELECT
+			i.id, i.provider_id, i.file_name, i.file_hash, i.row_count,
+			i.matched_count, i.fee_deducted_count, i.amount_mismatch_count,
+			i.unknown_count, i.duplicate_count, i.missing_payout_count,
+			i.period_start, i.period_end, i.imported_by, i.created_at,
+			p.code, p.name
+		FROM settlement_imports i
+		JOIN settlement_providers p ON i.provider_id = p.id
+		WHERE i.id = $1
+	`
+
+	imp := &models.SettlementImport{Provider: &models.SettlementProvider{}}
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&imp.ID, &imp.ProviderID, &imp.FileName, &imp.FileHash, &imp.RowCount,
+		&imp.MatchedCount, &imp.FeeDeductedCount, &imp.AmountMismatchCount,
+		&imp.UnknownCount, &imp.DuplicateCount, &imp.MissingPayoutCount,
+		&imp.PeriodStart, &imp.PeriodEnd, &imp.ImportedBy, &imp.CreatedAt,
+		&imp.Provider.Code, &imp.Provider.Name,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("settlement import not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement import: %w", err)
+	}
+	imp.Provider.ID = imp.ProviderID
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+settlementLineColumns+`
+		FROM settlement_lines
+		WHERE import_id = $1
+		ORDER BY row_number
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settlement lines: %w", err)
+	}
+	defer rows.Close()
+
+	imp.Lines = []*models.SettlementLine{}
+	for rows.Next() {
+		line := &models.SettlementLine{}
+		if err := scanSettlementLine(rows, line); err != nil {
+			return nil, fmt.Errorf("failed to scan settlement line: %w", err)
+		}
+		imp.Lines = append(imp.Lines, line)
+	}
+
+	return imp, nil
+}
+
+func (r *settlementRepository) ListImports(ctx context.Context, limit, offset int) ([]*models.SettlementImport, error) {
+	query := `
+		SELECT
+			i.id, i.provider_id, i.file_name, i.file_hash, i.row_count,
+			i.matched_count, i.fee_deducted_count, i.amount_mismatch_count,
+			i.unknown_count, i.duplicate_count, i.missing_payout_count,
+			i.period_start, i.period_end, i.imported_by, i.created_at,
+			p.code, p.name
+		FROM settlement_imports i
+		JOIN settlement_providers p ON i.provider_id = p.id
+		ORDER BY i.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlement imports: %w", err)
+	}
+	defer rows.Close()
+
+	imports := []*models.SettlementImport{}
+	for rows.Next() {
+		imp := &models.SettlementImport{Provider: &models.SettlementProvider{}}
+		err := rows.Scan(
+			&imp.ID, &imp.ProviderID, &imp.FileName, &imp.FileHash, &imp.RowCount,
+			&imp.MatchedCount, &imp.FeeDeductedCount, &imp.AmountMismatchCount,
+			&imp.UnknownCount, &imp.DuplicateCount, &imp.MissingPayoutCount,
+			&imp.PeriodStart, &imp.PeriodEnd, &imp.ImportedBy, &imp.CreatedAt,
+			&imp.Provider.Code, &imp.Provider.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan settlement import: %w", err)
+		}
+		imp.Provider.ID = imp.ProviderID
+		imports = append(imports, imp)
+	}
+
+	return imports, nil
+}
+
+func (r *settlementRepository) GetUnreconciledPayments(ctx context.Context) ([]*models.UnreconciledPayment, error) {
+	query := `
+		SELECT
+			p.id, b.booking_reference, s.operator_id, p.payment_method,
+			p.amount, p.currency, p.gateway_transaction_id,
+			p.reconciliation_status, p.processed_at,
+			sl.gross_amount, sl.fee_amount, sl.amount_variance
+		FROM payments p
+		JOIN bookings b ON p.booking_id = b.id
+		JOIN schedules s ON b.schedule_id = s.id
+		LEFT JOIN settlement_lines sl ON p.settlement_line_id = sl.id
+		WHERE p.reconciliation_status IN ('fee_deducted', 'amount_mismatch', 'missing_payout')
+		ORDER BY p.reconciliation_status, p.processed_at
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unreconciled payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []*models.UnreconciledPayment{}
+	for rows.Next() {
+		item := &models.UnreconciledPayment{}
+		err := rows.Scan(
+			&item.PaymentID, &item.BookingReference, &item.OperatorID, &item.PaymentMethod,
+			&item.Amount, &item.Currency, &item.GatewayTransactionID,
+			&item.ReconciliationStatus, &item.ProcessedAt,
+			&item.SettledGross, &item.SettledFee, &item.AmountVariance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan unreconciled payment: %w", err)
+		}
+		payments = append(payments, item)
+	}
+
+	return payments, nil
+}
+
+func (r *settlementRepository) GetUnresolvedLines(ctx context.Context) ([]*models.SettlementLine, error) {
+	query := `
+		SELECT ` + settlementLineColumns + `
+		FROM settlement_lines
+		WHERE match_status IN ('unknown_transaction', 'duplicate')
+			AND resolved_at IS NULL
+		ORDER BY created_at, row_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unresolved settlement lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []*models.SettlementLine{}
+	for rows.Next() {
+		line := &models.SettlementLine{}
+		if err := scanSettlementLine(rows, line); err != nil {
+			return nil, fmt.Errorf("failed to scan settlement line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func (r *settlementRepository) ResolvePayment(ctx context.Context, paymentID uuid.UUID, notes string) error {
+	query := `
+		UPDATE payments SET
+			reconciliation_status = 'resolved',
+			reconciliation_notes = $2,
+			reconciled_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+			AND reconciliation_status IN ('fee_deducted', 'amount_mismatch', 'missing_payout')
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, paymentID, notes)
+	if err != nil {
+		return fmt.Errorf("failed to resolve payment: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("payment not found or not flagged")
+	}
+
+	return nil
+}
+
+func (r *settlementRepository) ResolveLine(ctx context.Context, lineID, resolvedBy uuid.UUID, notes string) error {
+	query := `
+		UPDATE settlement_lines SET
+			resolved_by = $2,
+			resolved_at = CURRENT_TIMESTAMP,
+			resolution_notes = $3
+		WHERE id = $1
+			AND match_status IN ('unknown_transaction', 'duplicate')
+			AND resolved_at IS NULL
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, lineID, resolvedBy, notes)
+	if err != nil {
+		return fmt.Errorf("failed to resolve settlement line: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("settlement line not found or not flagged")
+	}
+
+	return nil
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type ShiftRepository interface {
+	Create(ctx context.Context, shift *models.AgentShift) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.AgentShift, error)
+	GetByOperator(ctx context.Context, operatorID, id uuid.UUID) (*models.AgentShift, error)
+	GetOpenByAgent(ctx context.Context, agentID uuid.UUID) (*models.AgentShift, error)
+	Close(ctx context.Context, shift *models.AgentShift) error
+	Reconcile(ctx context.Context, operatorID, id, reconciledBy uuid.UUID, notes string) error
+	ListOpen(ctx context.Context, operatorID uuid.UUID) ([]*models.AgentShift, error)
+	ListUnreconciled(ctx context.Context, operatorID uuid.UUID) ([]*models.AgentShift, error)
+	GetCashTotals(ctx context.Context, shiftID uuid.UUID) (*models.ShiftCashTotals, error)
+}
+
+type shiftRepository struct {
+	db *database.DB
+}
+
+func NewShiftRepository(db *database.DB) ShiftRepository {
+	return &shiftRepository{db: db}
+}
+
+const shiftColumns = `
+	s.id, s.operator_id, s.agent_id, s.terminal_id, s.status,
+	s.opening_float, s.expected_cash, s.counted_cash, s.cash_variance,
+	s.opened_at, s.closed_at, s.closing_notes, s.reconciled_by,
+	s.reconciled_at, s.reconciliation_notes, s.created_at, s.updated_at
+`
+
+func scanShift(row pgx.Row, shift *models.AgentShift) error {
+	return row.Scan(
+		&shift.ID, &shift.OperatorID, &shift.AgentID, &shift.TerminalID, &shift.Status,
+		&shift.OpeningFloat, &shift.ExpectedCash, &shift.CountedCash, &shift.CashVariance,
+		&shift.OpenedAt, &shift.ClosedAt, &shift.ClosingNotes, &shift.ReconciledBy,
+		&shift.ReconciledAt, &shift.ReconciliationNotes, &shift.CreatedAt, &shift.UpdatedAt,
+	)
+}
+
+func (r *shiftRepository) Create(ctx context.Context, shift *models.AgentShift) error {
+	query := `
+		INSERT INTO agent_shifts (
+			operator_id, agent_id, terminal_id, opening_float
+		) VALUES ($1, $2, $3, $4)
+		RETURNING id, status, opened_at, created_at, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		shift.OperatorID, shift.AgentID, shift.TerminalID, shift.OpeningFloat,
+	).Scan(&shift.ID, &shift.Status, &shift.OpenedAt, &shift.CreatedAt, &shift.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create shift: %w", err)
+	}
+
+	return nil
+}
+
+func (r *shiftRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AgentShift, error) {
+	query := `SELECT ` + shiftColumns + ` FROM agent_shifts s WHERE s.id = $1`
+
+	shift := &models.AgentShift{}
+	err := scanShift(r.db.Pool.QueryRow(ctx, query, id), shift)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("shift not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift: %w", err)
+	}
+
+	return shift, nil
+}
+
+// GetByOperator gets a shift only if it belongs to the operator
+func (r *shiftRepository) GetByOperator(ctx context.Context, operatorID, id uuid.UUID) (*models.AgentShift, error) {
+	query := `SELECT ` + shiftColumns + ` FROM agent_shifts s WHERE s.id = $1 AND s.operator_id = $2`
+
+	shift := &models.AgentShift{}
+	err := scanShift(r.db.Pool.QueryRow(ctx, query, id, operatorID), shift)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("shift not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift: %w", err)
+	}
+
+	return shift, nil
+}
+
+func (r *shiftRepository) GetOpenByAgent(ctx context.Context, agentID uuid.UUID) (*models.AgentShift, error) {
+	query := `SELECT ` + shiftColumns + ` FROM agent_shifts s WHERE s.agent_id = $1 AND s.status = 'open'`
+
+	shift := &models.AgentShift{}
+	err := scanShift(r.db.Pool.QueryRow(ctx, query, agentID), shift)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("no open shift")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open shift: %w", err)
+	}
+
+	return shift, nil
+}
+
+func (r *shiftRepository) Close(ctx context.Context, shift *models.AgentShift) error {
+	query := `
+		UPDATE agent_shifts SET
+			status = 'closed',
+			expected_cash = $2,
+			counted_cash = $3,
+			cash_variance = $4,
+			closing_notes = $5,
+			closed_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'open'
+		RETURNING status, closed_at, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		shift.ID, shift.ExpectedCash, shift.CountedCash, shift.CashVariance, shift.ClosingNotes,
+	).Scan(&shift.Status, &shift.ClosedAt, &shift.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("shift not found or already closed")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close shift: %w", err)
+	}
+
+	return nil
+}
+
+func (r *shiftRepository) Reconcile(ctx context.Context, operatorID, id, reconciledBy uuid.UUID, notes string) error {
+	query := `
+		UPDATE agent_shifts SET
+			status = 'reconciled',
+			reconciled_by = $2,
+			reconciled_at = CURRENT_TIMESTAMP,
+			reconciliation_notes = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND operator_id = $4 AND status = 'closed'
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, reconciledBy, notes, operatorID)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile shift: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("shift not found or not closed")
+	}
+
+	return nil
+}
+
+func (r *shiftRepository) ListOpen(ctx context.Context, operatorID uuid.UUID) ([]*models.AgentShift, error) {
+	query := `
+		SELECT ` + shiftColumns + `,
+			u.id, u.email, u.first_name, u.last_name
+		FROM agent_shifts s
+		JOIN users u ON s.agent_id = u.id
+		WHERE s.operator_id = $1 AND s.status = 'open'
+		ORDER BY s.opened_at ASC
+	`
+
+	return r.listWithAgent(ctx, query, operatorID)
+}
+
+func (r *shiftRepository) ListUnreconciled(ctx context.Context, operatorID uuid.UUID) ([]*models.AgentShift, error) {
+	query := `
+		SELECT ` + shiftColumns + `,
+			u.id, u.email, u.first_name, u.last_name
+		FROM agent_shifts s
+		JOIN users u ON s.agent_id = u.id
+		WHERE s.operator_id = $1
+			AND s.status = 'closed'
+			AND s.cash_variance != 0
+		ORDER BY s.closed_at ASC
+	`
+
+	return r.listWithAgent(ctx, query, operatorID)
+}
+
+func (r *shiftRepository) listWithAgent(ctx context.Context, query string, args ...interface{}) ([]*models.AgentShift, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shifts: %w", err)
+	}
+	defer rows.Close()
+
+	shifts := []*models.AgentShift{}
+	for rows.Next() {
+		shift := &models.AgentShift{}
+		agent := &models.User{}
+		err := rows.Scan(
+			&shift.ID, &shift.OperatorID, &shift.AgentID, &shift.TerminalID, &shift.Status,
+			&shift.OpeningFloat, &shift.ExpectedCash, &shift.CountedCash, &shift.CashVariance,
+			&shift.OpenedAt, &shift.ClosedAt, &shift.ClosingNotes, &shift.ReconciledBy,
+			&shift.ReconciledAt, &shift.ReconciliationNotes, &shift.CreatedAt, &shift.UpdatedAt,
+			&agent.ID, &agent.Email, &agent.FirstName, &agent.LastName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shift: %w", err)
+		}
+		shift.Agent = agent
+		shifts = append(shifts, shift)
+	}
+
+	return shifts, nil
+}
+
+func (r *shiftRepository) GetCashTotals(ctx context.Context, shiftID uuid.UUID) (*models.ShiftCashTotals, error) {
+	totals := &models.ShiftCashTotals{
+		ByPaymentMethod: make(map[string]money.Money),
+	}
+
+	// Sales taken during the shift, broken down by tender
+	salesQuery := `
+		SELECT p.payment_method, COALESCE(SUM(p.amount), 0)
+		FROM payments p
+		WHERE p.shift_id = $1 AND p.payment_status IN ('completed', 'partially_refunded', 'refunded')
+		GROUP BY p.payment_method
+	`
+
+	rows, err := r.db.Pool.Query(ctx, salesQuery, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift sales: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var method string
+		var amount money.Money
+		if err := rows.Scan(&method, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan shift sales: %w", err)
+		}
+		totals.ByPaymentMethod[method] = amount
+		if method == "cash" {
+			totals.CashSales = amount
+		}
+	}
+	rows.Close()
+
+	// A split tender booking is counted once however many tenders it took
+	countQuery := `
+		SELECT COUNT(DISTINCT p.booking_id)
+		FROM payments p
+		WHERE p.shift_id = $1 AND p.payment_status IN ('completed', 'partially_refunded', 'refunded')
+	`
+
+	if err := r.db.Pool.QueryRow(ctx, countQuery, shiftID).Scan(&totals.BookingCount); err != nil {
+		return nil, fmt.Errorf("failed to count shift bookings: %w", err)
+	}
+
+	// Cash paid back out of the drawer during the shift
+	refundQuery := `
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN p.payment_method = 'cash' THEN rf.refund_amount ELSE 0 END), 0)
+		FROM refunds rf
+		JOIN payments p ON rf.payment_id = p.id
+		WHERE rf.shift_id = $1 AND rf.refund_status = 'processed'
+	`
+
+	err = r.db.Pool.QueryRow(ctx, refundQuery, shiftID).Scan(&totals.RefundCount, &totals.CashRefunds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift refunds: %w", err)
+	}
+
+	return totals, nil
+}
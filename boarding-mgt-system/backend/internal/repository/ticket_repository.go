@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/ferryflow/boarding-mgt-system/internal/database"
 	"github.com/ferryflow/boarding-mgt-system/internal/models"
@@ -15,7 +16,9 @@ type TicketRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Ticket, error)
 	GetByQRCode(ctx context.Context, qrCode string) (*models.Ticket, error)
 	GetByBooking(ctx context.Context, bookingID uuid.UUID) ([]*models.Ticket, error)
+	GetByCustomer(ctx context.Context, customerID uuid.UUID, limit int) ([]*models.Ticket, error)
 	CheckIn(ctx context.Context, ticketID uuid.UUID) error
+	UpdateIdentity(ctx context.Context, ticketID uuid.UUID, identity *models.PassengerIdentity) error
 	GetManifest(ctx context.Context, scheduleID uuid.UUID) (*models.Manifest, error)
 }
 
@@ -36,16 +39,30 @@ func (r *ticketRepository) CreateBatch(ctx context.Context, tickets []*models.Ti
 	
 	query := `
 		INSERT INTO tickets (
-			booking_id, passenger_name, passenger_type, seat_number,
-			ticket_price, qr_code, check_in_status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+			id, booking_id, passenger_name, passenger_type, seat_number,
+			ticket_price, qr_code, check_in_status,
+			nationality, date_of_birth, sex, document_type, document_number,
+			document_issuing_country, document_expiry
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8,
+			NULLIF($9, ''), NULLIF($10, '')::date, NULLIF($11, ''), NULLIF($12, ''), NULLIF($13, ''),
+			NULLIF($14, ''), NULLIF($15, '')::date
+		)
 		RETURNING id, created_at, updated_at
 	`
 	
 	for _, ticket := range tickets {
+		// Signed QR codes embed the ticket ID, so it may be chosen up front
+		if ticket.ID == uuid.Nil {
+			ticket.ID = uuid.New()
+		}
+		
 		err := tx.QueryRow(ctx, query,
-			ticket.BookingID, ticket.PassengerName, ticket.PassengerType,
+			ticket.ID, ticket.BookingID, ticket.PassengerName, ticket.PassengerType,
 			ticket.SeatNumber, ticket.TicketPrice, ticket.QRCode, ticket.CheckInStatus,
+			ticket.Identity.Nationality, ticket.Identity.DateOfBirth, ticket.Identity.Sex,
+			ticket.Identity.DocumentType, ticket.Identity.DocumentNumber,
+			ticket.Identity.DocumentIssuingCountry, ticket.Identity.DocumentExpiry,
 		).Scan(&ticket.ID, &ticket.CreatedAt, &ticket.UpdatedAt)
 		
 		if err != nil {
@@ -65,7 +82,7 @@ func (r *ticketRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.T
 		SELECT 
 			t.id, t.booking_id, t.passenger_name, t.passenger_type,
 			t.seat_number, t.ticket_price, t.qr_code, t.check_in_status,
-			t.check_in_time, t.created_at, t.updated_at,
+			t.check_in_time, t.boarding_time, t.created_at, t.updated_at,
 			b.id, b.booking_reference, b.schedule_id, b.customer_id,
 			b.total_amount, b.booking_status
 		FROM tickets t
@@ -79,7 +96,7 @@ func (r *ticketRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.T
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&ticket.ID, &ticket.BookingID, &ticket.PassengerName, &ticket.PassengerType,
 		&ticket.SeatNumber, &ticket.TicketPrice, &ticket.QRCode, &ticket.CheckInStatus,
-		&ticket.CheckInTime, &ticket.CreatedAt, &ticket.UpdatedAt,
+		&ticket.CheckInTime, &ticket.BoardingTime, &ticket.CreatedAt, &ticket.UpdatedAt,
 		&booking.ID, &booking.BookingReference, &booking.ScheduleID, &booking.CustomerID,
 		&booking.TotalAmount, &booking.BookingStatus,
 	)
@@ -100,7 +117,7 @@ func (r *ticketRepository) GetByQRCode(ctx context.Context, qrCode string) (*mod
 		SELECT 
 			id, booking_id, passenger_name, passenger_type,
 			seat_number, ticket_price, qr_code, check_in_status,
-			check_in_time, created_at, updated_at
+			check_in_time, boarding_time, created_at, updated_at
 		FROM tickets
 		WHERE qr_code = $1
 	`
@@ -109,7 +126,7 @@ func (r *ticketRepository) GetByQRCode(ctx context.Context, qrCode string) (*mod
 	err := r.db.Pool.QueryRow(ctx, query, qrCode).Scan(
 		&ticket.ID, &ticket.BookingID, &ticket.PassengerName, &ticket.PassengerType,
 		&ticket.SeatNumber, &ticket.TicketPrice, &ticket.QRCode, &ticket.CheckInStatus,
-		&ticket.CheckInTime, &ticket.CreatedAt, &ticket.UpdatedAt,
+		&ticket.CheckInTime, &ticket.BoardingTime, &ticket.CreatedAt, &ticket.UpdatedAt,
 	)
 	
 	if err == pgx.ErrNoRows {
@@ -127,7 +144,7 @@ func (r *ticketRepository) GetByBooking(ctx context.Context, bookingID uuid.UUID
 		SELECT 
 			id, booking_id, passenger_name, passenger_type,
 			seat_number, ticket_price, qr_code, check_in_status,
-			check_in_time, created_at, updated_at
+			check_in_time, boarding_time, created_at, updated_at
 		FROM tickets
 		WHERE booking_id = $1
 		ORDER BY created_at ASC
@@ -145,7 +162,7 @@ func (r *ticketRepository) GetByBooking(ctx context.Context, bookingID uuid.UUID
 		err := rows.Scan(
 			&ticket.ID, &ticket.BookingID, &ticket.PassengerName, &ticket.PassengerType,
 			&ticket.SeatNumber, &ticket.TicketPrice, &ticket.QRCode, &ticket.CheckInStatus,
-			&ticket.CheckInTime, &ticket.CreatedAt, &ticket.UpdatedAt,
+			&ticket.CheckInTime, &ticket.BoardingTime, &ticket.CreatedAt, &ticket.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan ticket: %w", err)
@@ -156,13 +173,56 @@ func (r *ticketRepository) GetByBooking(ctx context.Context, bookingID uuid.UUID
 	return tickets, nil
 }
 
+func (r *ticketRepository) GetByCustomer(ctx context.Context, customerID uuid.UUID, limit int) ([]*models.Ticket, error) {
+	query := `
+		SELECT 
+			t.id, t.booking_id, t.passenger_name, t.passenger_type,
+			t.seat_number, t.ticket_price, t.qr_code, t.check_in_status,
+			t.check_in_time, t.boarding_time, t.created_at, t.updated_at,
+			b.id, b.booking_reference, b.schedule_id, b.customer_id,
+			b.total_amount, b.booking_status
+		FROM tickets t
+		JOIN bookings b ON t.booking_id = b.id
+		JOIN schedules s ON b.schedule_id = s.id
+		WHERE b.customer_id = $1
+		ORDER BY s.departure_date DESC, s.departure_time DESC, t.created_at ASC
+		LIMIT $2
+	`
+	
+	rows, err := r.db.Pool.Query(ctx, query, customerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	}
+	defer rows.Close()
+	
+	tickets := []*models.Ticket{}
+	for rows.Next() {
+		ticket := &models.Ticket{}
+		booking := &models.Booking{}
+		err := rows.Scan(
+			&ticket.ID, &ticket.BookingID, &ticket.PassengerName, &ticket.PassengerType,
+			&ticket.SeatNumber, &ticket.TicketPrice, &ticket.QRCode, &ticket.CheckInStatus,
+			&ticket.CheckInTime, &ticket.BoardingTime, &ticket.CreatedAt, &ticket.UpdatedAt,
+			&booking.ID, &booking.BookingReference, &booking.ScheduleID, &booking.CustomerID,
+			&booking.TotalAmount, &booking.BookingStatus,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		ticket.Booking = booking
+		tickets = append(tickets, ticket)
+	}
+	
+	return tickets, nil
+}
+
 func (r *ticketRepository) CheckIn(ctx context.Context, ticketID uuid.UUID) error {
 	query := `
 		UPDATE tickets SET
 			check_in_status = 'checked_in',
 			check_in_time = CURRENT_TIMESTAMP,
 			updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND check_in_status = 'pending'
+		WHERE id = $1 AND check_in_status = 'not_checked_in'
 	`
 	
 	result, err := r.db.Pool.Exec(ctx, query, ticketID)
@@ -177,35 +237,88 @@ func (r *ticketRepository) CheckIn(ctx context.Context, ticketID uuid.UUID) erro
 	return nil
 }
 
+// UpdateIdentity replaces a passenger's travel document details
+func (r *ticketRepository) UpdateIdentity(ctx context.Context, ticketID uuid.UUID, identity *models.PassengerIdentity) error {
+	query := `
+		UPDATE tickets SET
+			nationality = NULLIF($2, ''),
+			date_of_birth = NULLIF($3, '')::date,
+			sex = NULLIF($4, ''),
+			document_type = NULLIF($5, ''),
+			document_number = NULLIF($6, ''),
+			document_issuing_country = NULLIF($7, ''),
+			document_expiry = NULLIF($8, '')::date,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	
+	result, err := r.db.Pool.Exec(ctx, query, ticketID,
+		identity.Nationality, identity.DateOfBirth, identity.Sex, identity.DocumentType,
+		identity.DocumentNumber, identity.DocumentIssuingCountry, identity.DocumentExpiry,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update passenger identity: %w", err)
+	}
+	
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("ticket not found")
+	}
+	
+	return nil
+}
+
 func (r *ticketRepository) GetManifest(ctx context.Context, scheduleID uuid.UUID) (*models.Manifest, error) {
 	// Get schedule details
 	scheduleQuery := `
 		SELECT 
-			s.id, s.departure_date, s.departure_time, s.arrival_time,
-			s.total_capacity, s.available_seats
+			s.id, s.operator_id, s.departure_date, s.departure_time, s.arrival_time,
+			s.total_capacity, s.available_seats, s.status,
+			o.name, v.name, v.registration_number,
+			dp.name, dp.code, dp.country, ap.name, ap.code, ap.country,
+			s.departure_at, dp.timezone
 		FROM schedules s
+		JOIN operators o ON s.operator_id = o.id
+		JOIN vessels v ON s.vessel_id = v.id
+		JOIN routes r ON s.route_id = r.id
+		JOIN ports dp ON r.departure_port_id = dp.id
+		JOIN ports ap ON r.arrival_port_id = ap.id
 		WHERE s.id = $1
 	`
 	
 	manifest := &models.Manifest{
 		Schedule: &models.Schedule{},
+		Voyage:   &models.ManifestVoyage{},
 	}
+	voyage := manifest.Voyage
+	var departureTimezone string
 	
 	err := r.db.Pool.QueryRow(ctx, scheduleQuery, scheduleID).Scan(
-		&manifest.Schedule.ID, &manifest.Schedule.DepartureDate,
+		&manifest.Schedule.ID, &manifest.Schedule.OperatorID, &manifest.Schedule.DepartureDate,
 		&manifest.Schedule.DepartureTime, &manifest.Schedule.ArrivalTime,
-		&manifest.Schedule.TotalCapacity, &manifest.Schedule.AvailableSeats,
+		&manifest.Schedule.TotalCapacity, &manifest.Schedule.AvailableSeats, &manifest.Schedule.Status,
+		&voyage.OperatorName, &voyage.VesselName, &voyage.VesselRegistration,
+		&voyage.DeparturePort, &voyage.DeparturePortCode, &voyage.DepartureCountry,
+		&voyage.ArrivalPort, &voyage.ArrivalPortCode, &voyage.ArrivalCountry,
+		&voyage.Departure, &departureTimezone,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get schedule: %w", err)
 	}
 	
+	// Show the departure in the port's local time
+	if location, err := time.LoadLocation(departureTimezone); err == nil {
+		voyage.Departure = voyage.Departure.In(location)
+	}
+	
 	// Get passenger details
 	passengerQuery := `
 		SELECT 
 			t.id, t.passenger_name, t.passenger_type, t.seat_number,
 			b.booking_reference, t.check_in_status,
-			u.email, u.phone
+			u.email, u.phone,
+			COALESCE(t.nationality, ''), COALESCE(to_char(t.date_of_birth, 'YYYY-MM-DD'), ''),
+			COALESCE(t.sex, ''), COALESCE(t.document_type, ''), COALESCE(t.document_number, ''),
+			COALESCE(t.document_issuing_country, ''), COALESCE(to_char(t.document_expiry, 'YYYY-MM-DD'), '')
 		FROM tickets t
 		JOIN bookings b ON t.booking_id = b.id
 		JOIN users u ON b.customer_id = u.id
@@ -221,6 +334,7 @@ func (r *ticketRepository) GetManifest(ctx context.Context, scheduleID uuid.UUID
 	
 	manifest.Passengers = []models.ManifestEntry{}
 	checkedInCount := 0
+	boardedCount := 0
 	
 	for rows.Next() {
 		entry := models.ManifestEntry{}
@@ -230,6 +344,9 @@ func (r *ticketRepository) GetManifest(ctx context.Context, scheduleID uuid.UUID
 			&entry.TicketID, &entry.PassengerName, &entry.PassengerType,
 			&seatNumber, &entry.BookingRef, &entry.CheckInStatus,
 			&entry.CustomerEmail, &phone,
+			&entry.Nationality, &entry.DateOfBirth,
+			&entry.Sex, &entry.DocumentType, &entry.DocumentNumber,
+			&entry.DocumentIssuingCountry, &entry.DocumentExpiry,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan passenger: %w", err)
@@ -242,15 +359,22 @@ func (r *ticketRepository) GetManifest(ctx context.Context, scheduleID uuid.UUID
 			entry.CustomerPhone = *phone
 		}
 		
-		if entry.CheckInStatus == "checked_in" {
+		if entry.CheckInStatus == "checked_in" || entry.CheckInStatus == "boarded" {
 			checkedInCount++
 		}
+		if entry.CheckInStatus == "boarded" {
+			boardedCount++
+		}
+		if entry.CheckInStatus == "no_show" {
+			manifest.NoShows++
+		}
 		
 		manifest.Passengers = append(manifest.Passengers, entry)
 	}
 	
 	manifest.TotalPassengers = len(manifest.Passengers)
 	manifest.CheckedIn = checkedInCount
+	manifest.Boarded = boardedCount
 	
 	return manifest, nil
 }
\ No newline at end of file
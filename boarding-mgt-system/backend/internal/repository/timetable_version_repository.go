@@ -0,0 +1,576 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/timetable"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type TimetableVersionRepository interface {
+	Create(ctx context.Context, version *models.TimetableVersion, copyFrom *models.TimetableVersion) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.TimetableVersion, error)
+	List(ctx context.Context, operatorID *uuid.UUID) ([]*models.TimetableVersion, error)
+	ListSailings(ctx context.Context, id uuid.UUID) ([]*models.Schedule, error)
+	Submit(ctx context.Context, version *models.TimetableVersion, submittedBy uuid.UUID) error
+	ReturnToDraft(ctx context.Context, version *models.TimetableVersion) error
+	Compare(ctx context.Context, version *models.TimetableVersion) (*models.TimetableDiff, error)
+	Publish(ctx context.Context, version *models.TimetableVersion, publishedBy uuid.UUID) (*models.TimetableDiff, error)
+	Rollback(ctx context.Context, version *models.TimetableVersion) ([]uuid.UUID, error)
+}
+
+type timetableVersionRepository struct {
+	db *database.DB
+}
+
+func NewTimetableVersionRepository(db *database.DB) TimetableVersionRepository {
+	return &timetableVersionRepository{db: db}
+}
+
+// publishedTimetablesConstraint keeps an operator to one published
+// timetable for any day
+const publishedTimetablesConstraint = "no_overlapping_published_timetables"
+
+const timetableVersionColumns = `
+	id, operator_id, name, valid_from, valid_until, status, superseded_by,
+	submitted_by, submitted_at, published_by, published_at, version,
+	created_at, updated_at
+`
+
+func scanTimetableVersion(row pgx.Row) (*models.TimetableVersion, error) {
+	v := &models.TimetableVersion{}
+	err := row.Scan(
+		&v.ID, &v.OperatorID, &v.Name, &v.ValidFrom, &v.ValidUntil, &v.Status, &v.SupersededBy,
+		&v.SubmittedBy, &v.SubmittedAt, &v.PublishedBy, &v.PublishedAt, &v.Version,
+		&v.CreatedAt, &v.UpdatedAt,
+	)
+	return v, err
+}
+
+// Create starts a draft timetable version, with the sailings copyFrom has
+// in its season when it is given
+func (r *timetableVersionRepository) Create(ctx context.Context, version *models.TimetableVersion, copyFrom *models.TimetableVersion) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO timetable_versions (operator_id, name, valid_from, valid_until)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, status, version, created_at, updated_at
+	`,
+		version.OperatorID, version.Name, version.ValidFrom, version.ValidUntil,
+	).Scan(&version.ID, &version.Status, &version.Version, &version.CreatedAt, &version.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create timetable version: %w", err)
+	}
+
+	if copyFrom != nil {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO schedules (
+				operator_id, route_id, vessel_id, departure_date, departure_time,
+				arrival_time, departure_at, arrival_at, base_price, total_capacity,
+				available_seats, timetable_version_id, unpublished
+			)
+			SELECT
+				operator_id, route_id, vessel_id, departure_date, departure_time,
+				arrival_time, departure_at, arrival_at, base_price, total_capacity,
+				total_capacity, $2, true
+			FROM schedules
+			WHERE timetable_version_id = $1
+				AND status <> 'cancelled'
+				AND departure_date BETWEEN $3 AND $4
+		`, copyFrom.ID, version.ID, version.ValidFrom, version.ValidUntil)
+		if err != nil {
+			return fmt.Errorf("failed to copy timetable sailings: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *timetableVersionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TimetableVersion, error) {
+	query := `SELECT ` + timetableVersionColumns + ` FROM timetable_versions WHERE id = $1`
+
+	version, err := scanTimetableVersion(r.db.Pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("timetable version not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timetable version: %w", err)
+	}
+
+	return version, nil
+}
+
+func (r *timetableVersionRepository) List(ctx context.Context, operatorID *uuid.UUID) ([]*models.TimetableVersion, error) {
+	query := `SELECT ` + timetableVersionColumns + ` FROM timetable_versions`
+	args := []interface{}{}
+
+	if operatorID != nil {
+		query += ` WHERE operator_id = $1`
+		args = append(args, *operatorID)
+	}
+	query += ` ORDER BY valid_from DESC, created_at DESC`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timetable versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []*models.TimetableVersion{}
+	for rows.Next() {
+		version, err := scanTimetableVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan timetable version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// ListSailings lists all the sailings of a timetable version, on sale or not
+func (r *timetableVersionRepository) ListSailings(ctx context.Context, id uuid.UUID) ([]*models.Schedule, error) {
+	sailings, err := querySailings(ctx, r.db.Pool, `s.timetable_version_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedulesOf(sailings), nil
+}
+
+// Submit puts a draft timetable version up for review
+func (r *timetableVersionRepository) Submit(ctx context.Context, version *models.TimetableVersion, submittedBy uuid.UUID) error {
+	err := r.db.Pool.QueryRow(ctx, `
+		UPDATE timetable_versions SET
+			status = 'review',
+			submitted_by = $2,
+			submitted_at = CURRENT_TIMESTAMP,
+			version = version + 1
+		WHERE id = $1 AND status = 'draft' AND version = $3
+		RETURNING status, submitted_by, submitted_at, version, updated_at
+	`, version.ID, submittedBy, version.Version,
+	).Scan(&version.Status, &version.SubmittedBy, &version.SubmittedAt, &version.Version, &version.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("timetable version is no longer a draft or was changed meanwhile")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to submit timetable version: %w", err)
+	}
+
+	return nil
+}
+
+// ReturnToDraft sends a timetable version in review back for changes
+func (r *timetableVersionRepository) ReturnToDraft(ctx context.Context, version *models.TimetableVersion) error {
+	err := r.db.Pool.QueryRow(ctx, `
+		UPDATE timetable_versions SET
+			status = 'draft',
+			version = version + 1
+		WHERE id = $1 AND status = 'review' AND version = $2
+		RETURNING status, version, updated_at
+	`, version.ID, version.Version,
+	).Scan(&version.Status, &version.Version, &version.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("timetable version is no longer in review or was changed meanwhile")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to return timetable version to draft: %w", err)
+	}
+
+	return nil
+}
+
+// Compare works out what publishing a timetable version would change,
+// without changing anything
+func (r *timetableVersionRepository) Compare(ctx context.Context, version *models.TimetableVersion) (*models.TimetableDiff, error) {
+	replaces, err := replacedVersions(ctx, r.db.Pool, version, false)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := querySailings(ctx, r.db.Pool, liveSailings, version.OperatorID, version.ID, replaces, version.ValidFrom, version.ValidUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err := querySailings(ctx, r.db.Pool, draftSailings, version.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, _ := timetable.CompareVersion(version.ID, replaces, live, schedulesOf(draft))
+	return diff, nil
+}
+
+// Publish puts a timetable version in review on sale in one transaction and
+// reports what changed. The published versions it overlaps are superseded
+// and their unbooked future sailings taken off sale; booked ones stay on sale
+// in place of the version's own sailings for their departures, which are
+// dropped. A sailing that would put its vessel on two sailings at once fails
+// the whole publication with a VesselConflictError.
+func (r *timetableVersionRepository) Publish(ctx context.Context, version *models.TimetableVersion, publishedBy uuid.UUID) (*models.TimetableDiff, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM timetable_versions WHERE id = $1 FOR UPDATE`, version.ID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("timetable version not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock timetable version: %w", err)
+	}
+	if status != "review" {
+		return nil, fmt.Errorf("only timetable versions in review can be published, this one is %s", status)
+	}
+
+	replaces, err := replacedVersions(ctx, tx, version, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bookings update their sailing's seats, so once the sailings on sale
+	// are locked none can be booked before they are compared
+	args := []interface{}{version.OperatorID, version.ID, replaces, version.ValidFrom, version.ValidUntil}
+	if _, err := tx.Exec(ctx, `SELECT s.id FROM schedules s WHERE `+liveSailings+` FOR UPDATE`, args...); err != nil {
+		return nil, fmt.Errorf("failed to lock sailings on sale: %w", err)
+	}
+
+	live, err := querySailings(ctx, tx, liveSailings, args...)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := querySailings(ctx, tx, draftSailings, version.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, dropped := timetable.CompareVersion(version.ID, replaces, live, schedulesOf(draft))
+
+	retired := []uuid.UUID{}
+	for _, sailing := range live {
+		if !sailing.Booked {
+			retired = append(retired, sailing.Schedule.ID)
+		}
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE schedules SET unpublished = true, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($1)
+	`, retired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take sailings off sale: %w", err)
+	}
+
+	skip := map[uuid.UUID]bool{}
+	droppedIDs := []uuid.UUID{}
+	for _, schedule := range dropped {
+		skip[schedule.ID] = true
+		droppedIDs = append(droppedIDs, schedule.ID)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM schedules WHERE id = ANY($1)`, droppedIDs); err != nil {
+		return nil, fmt.Errorf("failed to drop sailings: %w", err)
+	}
+
+	for _, sailing := range draft {
+		if skip[sailing.Schedule.ID] {
+			continue
+		}
+		if err := putOnSale(ctx, tx, r.db, sailing.Schedule); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE timetable_versions SET status = 'superseded', superseded_by = $1, version = version + 1
+		WHERE id = ANY($2)
+	`, version.ID, replaces)
+	if err != nil {
+		return nil, fmt.Errorf("failed to supersede timetable versions: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE timetable_versions SET
+			status = 'published',
+			published_by = $2,
+			published_at = CURRENT_TIMESTAMP,
+			version = version + 1
+		WHERE id = $1
+		RETURNING status, published_by, published_at, version, updated_at
+	`, version.ID, publishedBy,
+	).Scan(&version.Status, &version.PublishedBy, &version.PublishedAt, &version.Version, &version.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish timetable version: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return diff, nil
+}
+
+// Rollback takes a published timetable version's future sailings off sale,
+// returns it to draft and publishes again the versions it superseded, with
+// their future sailings, in one transaction. It is refused while any of the
+// version's future departures have bookings. The restored versions are
+// returned.
+func (r *timetableVersionRepository) Rollback(ctx context.Context, version *models.TimetableVersion) ([]uuid.UUID, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM timetable_versions WHERE id = $1 FOR UPDATE`, version.ID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("timetable version not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock timetable version: %w", err)
+	}
+	if status != "published" {
+		return nil, fmt.Errorf("only published timetable versions can be rolled back, this one is %s", status)
+	}
+
+	upcoming := `
+		s.timetable_version_id = $1
+		AND NOT s.unpublished
+		AND s.status <> 'cancelled'
+		AND s.departure_at > CURRENT_TIMESTAMP
+	`
+	if _, err := tx.Exec(ctx, `SELECT s.id FROM schedules s WHERE `+upcoming+` FOR UPDATE`, version.ID); err != nil {
+		return nil, fmt.Errorf("failed to lock sailings on sale: %w", err)
+	}
+
+	var booked int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM schedules s
+		WHERE `+upcoming+` AND `+liveBooking,
+		version.ID,
+	).Scan(&booked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count booked sailings: %w", err)
+	}
+	if booked > 0 {
+		return nil, fmt.Errorf("cannot roll back: %d upcoming departures of this timetable have bookings", booked)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE schedules s SET unpublished = true, updated_at = CURRENT_TIMESTAMP
+		WHERE `+upcoming, version.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take sailings off sale: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE timetable_versions SET
+			status = 'draft',
+			submitted_by = NULL,
+			submitted_at = NULL,
+			published_by = NULL,
+			published_at = NULL,
+			version = version + 1
+		WHERE id = $1
+		RETURNING status, submitted_by, submitted_at, published_by, published_at, version, updated_at
+	`, version.ID).Scan(
+		&version.Status, &version.SubmittedBy, &version.SubmittedAt,
+		&version.PublishedBy, &version.PublishedAt, &version.Version, &version.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll back timetable version: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE timetable_versions SET status = 'published', superseded_by = NULL, version = version + 1
+		WHERE superseded_by = $1
+		RETURNING id
+	`, version.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore timetable versions: %w", err)
+	}
+	restored, err := scanIDs(rows)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == publishedTimetablesConstraint {
+		return nil, fmt.Errorf("cannot roll back: a timetable version published since overlaps the version it replaced")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore timetable versions: %w", err)
+	}
+
+	sailings, err := querySailings(ctx, tx, `
+		s.timetable_version_id = ANY($1)
+		AND s.unpublished
+		AND s.status = 'scheduled'
+		AND s.departure_at > CURRENT_TIMESTAMP
+	`, restored)
+	if err != nil {
+		return nil, err
+	}
+	for _, sailing := range sailings {
+		if err := putOnSale(ctx, tx, r.db, sailing.Schedule); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return restored, nil
+}
+
+// liveBooking holds for a sailing s with pending or confirmed bookings
+const liveBooking = `EXISTS (
+	SELECT 1 FROM bookings b
+	WHERE b.schedule_id = s.id AND b.booking_status IN ('pending', 'confirmed')
+)`
+
+// liveSailings selects the future sailings on sale that publishing a
+// timetable version ($2) of an operator ($1) replaces: those of the
+// published versions it supersedes ($3), and those of other versions still
+// on sale in its season ($4 to $5)
+const liveSailings = `
+	s.operator_id = $1
+	AND s.timetable_version_id <> $2
+	AND NOT s.unpublished
+	AND s.status = 'scheduled'
+	AND s.departure_at > CURRENT_TIMESTAMP
+	AND (s.timetable_version_id = ANY($3) OR s.departure_date BETWEEN $4 AND $5)
+`
+
+// draftSailings selects the sailings of a timetable version ($1) not yet on
+// sale
+const draftSailings = `s.timetable_version_id = $1 AND s.unpublished`
+
+// querier is satisfied by both the pool and a transaction
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// querySailings lists the sailings matching a condition on schedules s and
+// whether each has live bookings, in departure order
+func querySailings(ctx context.Context, q querier, condition string, args ...interface{}) ([]models.TimetableSailing, error) {
+	query := `
+		SELECT
+			s.id, s.operator_id, s.route_id, s.vessel_id, s.departure_date,
+			s.departure_time, s.arrival_time, s.base_price, s.total_capacity,
+			s.available_seats, s.status, s.cancellation_reason, s.version,
+			s.created_at, s.updated_at, s.departure_at, s.arrival_at,
+			s.timetable_version_id, s.unpublished, ` + liveBooking + `
+		FROM schedules s
+		WHERE ` + condition + `
+		ORDER BY s.departure_at, s.route_id
+	`
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list timetable sailings: %w", err)
+	}
+	defer rows.Close()
+
+	sailings := []models.TimetableSailing{}
+	for rows.Next() {
+		schedule := &models.Schedule{}
+		var booked bool
+		err := rows.Scan(
+			&schedule.ID, &schedule.OperatorID, &schedule.RouteID, &schedule.VesselID,
+			&schedule.DepartureDate, &schedule.DepartureTime, &schedule.ArrivalTime,
+			&schedule.BasePrice, &schedule.TotalCapacity, &schedule.AvailableSeats,
+			&schedule.Status, &schedule.CancellationReason, &schedule.Version,
+			&schedule.CreatedAt, &schedule.UpdatedAt, &schedule.DepartureAt, &schedule.ArrivalAt,
+			&schedule.TimetableVersionID, &schedule.Unpublished, &booked,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan timetable sailing: %w", err)
+		}
+		sailings = append(sailings, models.TimetableSailing{Schedule: schedule, Booked: booked})
+	}
+
+	return sailings, nil
+}
+
+func schedulesOf(sailings []models.TimetableSailing) []*models.Schedule {
+	schedules := make([]*models.Schedule, 0, len(sailings))
+	for _, sailing := range sailings {
+		schedules = append(schedules, sailing.Schedule)
+	}
+	return schedules
+}
+
+// replacedVersions lists the published versions of an operator whose season
+// overlaps a timetable version's, locking them when asked to
+func replacedVersions(ctx context.Context, q querier, version *models.TimetableVersion, lock bool) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM timetable_versions
+		WHERE operator_id = $1 AND id <> $2 AND status = 'published'
+			AND daterange(valid_from, valid_until, '[]') && daterange($3, $4, '[]')
+		ORDER BY valid_from
+	`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+
+	rows, err := q.Query(ctx, query, version.OperatorID, version.ID, version.ValidFrom, version.ValidUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list published timetable versions: %w", err)
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan published timetable versions: %w", err)
+	}
+
+	return ids, nil
+}
+
+// scanIDs reads and closes rows of IDs
+func scanIDs(rows pgx.Rows) ([]uuid.UUID, error) {
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// putOnSale puts a sailing on sale, failing with a VesselConflictError when
+// its vessel is already on another sailing at the time
+func putOnSale(ctx context.Context, tx pgx.Tx, db *database.DB, schedule *models.Schedule) error {
+	err := tx.QueryRow(ctx, `
+		UPDATE schedules SET unpublished = false, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING unpublished, updated_at
+	`, schedule.ID).Scan(&schedule.Unpublished, &schedule.UpdatedAt)
+	if conflict := vesselConflict(ctx, db, err, schedule); conflict != nil {
+		return conflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put sailing on sale: %w", err)
+	}
+
+	return nil
+}
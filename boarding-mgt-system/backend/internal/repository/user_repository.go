@@ -59,7 +59,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	query := `
 		SELECT 
 			id, email, password_hash, first_name, last_name, phone,
-			date_of_birth, nationality, user_type, operator_id,
+			date_of_birth, nationality, user_type, operator_id, agency_id,
 			is_verified, is_active, last_login_at, created_at, updated_at
 		FROM users
 		WHERE id = $1
@@ -68,7 +68,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	user := &models.User{}
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Phone,
-		&user.DateOfBirth, &user.Nationality, &user.UserType, &user.OperatorID,
+		&user.DateOfBirth, &user.Nationality, &user.UserType, &user.OperatorID, &user.AgencyID,
 		&user.IsVerified, &user.IsActive, &user.LastLoginAt, &user.CreatedAt, &user.UpdatedAt,
 	)
 	
@@ -86,7 +86,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	query := `
 		SELECT 
 			id, email, password_hash, first_name, last_name, phone,
-			date_of_birth, nationality, user_type, operator_id,
+			date_of_birth, nationality, user_type, operator_id, agency_id,
 			is_verified, is_active, last_login_at, created_at, updated_at
 		FROM users
 		WHERE email = $1
@@ -95,7 +95,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	user := &models.User{}
 	err := r.db.Pool.QueryRow(ctx, query, email).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Phone,
-		&user.DateOfBirth, &user.Nationality, &user.UserType, &user.OperatorID,
+		&user.DateOfBirth, &user.Nationality, &user.UserType, &user.OperatorID, &user.AgencyID,
 		&user.IsVerified, &user.IsActive, &user.LastLoginAt, &user.CreatedAt, &user.UpdatedAt,
 	)
 	
@@ -158,7 +158,7 @@ func (r *userRepository) List(ctx context.Context, filter *models.UserFilter) ([
 	query := `
 		SELECT 
 			id, email, password_hash, first_name, last_name, phone,
-			date_of_birth, nationality, user_type, operator_id,
+			date_of_birth, nationality, user_type, operator_id, agency_id,
 			is_verified, is_active, last_login_at, created_at, updated_at
 		FROM users
 		WHERE 1=1
@@ -230,7 +230,7 @@ func (r *userRepository) List(ctx context.Context, filter *models.UserFilter) ([
 		user := &models.User{}
 		err := rows.Scan(
 			&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.Phone,
-			&user.DateOfBirth, &user.Nationality, &user.UserType, &user.OperatorID,
+			&user.DateOfBirth, &user.Nationality, &user.UserType, &user.OperatorID, &user.AgencyID,
 			&user.IsVerified, &user.IsActive, &user.LastLoginAt, &user.CreatedAt, &user.UpdatedAt,
 		)
 		if err != nil {
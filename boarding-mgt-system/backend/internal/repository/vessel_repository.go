@@ -2,12 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ferryflow/boarding-mgt-system/internal/database"
 	"github.com/ferryflow/boarding-mgt-system/internal/models"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type VesselRepository interface {
@@ -32,14 +34,16 @@ func (r *vesselRepository) Create(ctx context.Context, vessel *models.Vessel) er
 	query := `
 		INSERT INTO vessels (
 			operator_id, name, registration_number, vessel_type,
-			capacity, deck_count, seat_configuration, amenities
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			capacity, deck_count, seat_configuration, amenities, turnaround_minutes,
+			weather_limits
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, is_active, created_at, updated_at
 	`
 	
 	err := r.db.Pool.QueryRow(ctx, query,
 		vessel.OperatorID, vessel.Name, vessel.RegistrationNumber, vessel.VesselType,
 		vessel.Capacity, vessel.DeckCount, vessel.SeatConfiguration, vessel.Amenities,
+		vessel.TurnaroundMinutes, vessel.WeatherLimits,
 	).Scan(&vessel.ID, &vessel.IsActive, &vessel.CreatedAt, &vessel.UpdatedAt)
 	
 	if err != nil {
@@ -53,7 +57,7 @@ func (r *vesselRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.V
 	query := `
 		SELECT 
 			v.id, v.operator_id, v.name, v.registration_number, v.vessel_type,
-			v.capacity, v.deck_count, v.seat_configuration, v.amenities,
+			v.capacity, v.deck_count, v.seat_configuration, v.amenities, v.turnaround_minutes, v.weather_limits,
 			v.is_active, v.created_at, v.updated_at,
 			o.id, o.name, o.code, o.contact_email, o.is_active
 		FROM vessels v
@@ -67,7 +71,7 @@ func (r *vesselRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.V
 	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
 		&vessel.ID, &vessel.OperatorID, &vessel.Name, &vessel.RegistrationNumber,
 		&vessel.VesselType, &vessel.Capacity, &vessel.DeckCount,
-		&vessel.SeatConfiguration, &vessel.Amenities,
+		&vessel.SeatConfiguration, &vessel.Amenities, &vessel.TurnaroundMinutes, &vessel.WeatherLimits,
 		&vessel.IsActive, &vessel.CreatedAt, &vessel.UpdatedAt,
 		&operator.ID, &operator.Name, &operator.Code, &operator.ContactEmail, &operator.IsActive,
 	)
@@ -87,7 +91,7 @@ func (r *vesselRepository) GetByRegistration(ctx context.Context, regNumber stri
 	query := `
 		SELECT 
 			id, operator_id, name, registration_number, vessel_type,
-			capacity, deck_count, seat_configuration, amenities,
+			capacity, deck_count, seat_configuration, amenities, turnaround_minutes, weather_limits,
 			is_active, created_at, updated_at
 		FROM vessels
 		WHERE registration_number = $1
@@ -97,7 +101,7 @@ func (r *vesselRepository) GetByRegistration(ctx context.Context, regNumber stri
 	err := r.db.Pool.QueryRow(ctx, query, regNumber).Scan(
 		&vessel.ID, &vessel.OperatorID, &vessel.Name, &vessel.RegistrationNumber,
 		&vessel.VesselType, &vessel.Capacity, &vessel.DeckCount,
-		&vessel.SeatConfiguration, &vessel.Amenities,
+		&vessel.SeatConfiguration, &vessel.Amenities, &vessel.TurnaroundMinutes, &vessel.WeatherLimits,
 		&vessel.IsActive, &vessel.CreatedAt, &vessel.UpdatedAt,
 	)
 	
@@ -121,6 +125,8 @@ func (r *vesselRepository) Update(ctx context.Context, vessel *models.Vessel) er
 			seat_configuration = $6,
 			amenities = $7,
 			is_active = $8,
+			turnaround_minutes = $9,
+			weather_limits = $10,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1
 		RETURNING updated_at
@@ -129,11 +135,18 @@ func (r *vesselRepository) Update(ctx context.Context, vessel *models.Vessel) er
 	err := r.db.Pool.QueryRow(ctx, query,
 		vessel.ID, vessel.Name, vessel.VesselType, vessel.Capacity,
 		vessel.DeckCount, vessel.SeatConfiguration, vessel.Amenities, vessel.IsActive,
+		vessel.TurnaroundMinutes, vessel.WeatherLimits,
 	).Scan(&vessel.UpdatedAt)
 	
 	if err == pgx.ErrNoRows {
 		return fmt.Errorf("vessel not found")
 	}
+	// A longer turnaround reallocates the vessel's sailings, which can make
+	// them overlap
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == vesselAllocationConstraint {
+		return &models.VesselConflictError{VesselID: vessel.ID}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update vessel: %w", err)
 	}
@@ -160,7 +173,7 @@ func (r *vesselRepository) ListByOperator(ctx context.Context, operatorID uuid.U
 	query := `
 		SELECT 
 			id, operator_id, name, registration_number, vessel_type,
-			capacity, deck_count, seat_configuration, amenities,
+			capacity, deck_count, seat_configuration, amenities, turnaround_minutes, weather_limits,
 			is_active, created_at, updated_at
 		FROM vessels
 		WHERE operator_id = $1
@@ -202,7 +215,7 @@ func (r *vesselRepository) ListByOperator(ctx context.Context, operatorID uuid.U
 		err := rows.Scan(
 			&vessel.ID, &vessel.OperatorID, &vessel.Name, &vessel.RegistrationNumber,
 			&vessel.VesselType, &vessel.Capacity, &vessel.DeckCount,
-			&vessel.SeatConfiguration, &vessel.Amenities,
+			&vessel.SeatConfiguration, &vessel.Amenities, &vessel.TurnaroundMinutes, &vessel.WeatherLimits,
 			&vessel.IsActive, &vessel.CreatedAt, &vessel.UpdatedAt,
 		)
 		if err != nil {
@@ -218,7 +231,7 @@ func (r *vesselRepository) GetAvailableVessels(ctx context.Context, operatorID u
 	query := `
 		SELECT 
 			id, operator_id, name, registration_number, vessel_type,
-			capacity, deck_count, seat_configuration, amenities,
+			capacity, deck_count, seat_configuration, amenities, turnaround_minutes, weather_limits,
 			is_active, created_at, updated_at
 		FROM vessels
 		WHERE operator_id = $1 AND is_active = true
@@ -237,7 +250,7 @@ func (r *vesselRepository) GetAvailableVessels(ctx context.Context, operatorID u
 		err := rows.Scan(
 			&vessel.ID, &vessel.OperatorID, &vessel.Name, &vessel.RegistrationNumber,
 			&vessel.VesselType, &vessel.Capacity, &vessel.DeckCount,
-			&vessel.SeatConfiguration, &vessel.Amenities,
+			&vessel.SeatConfiguration, &vessel.Amenities, &vessel.TurnaroundMinutes, &vessel.WeatherLimits,
 			&vessel.IsActive, &vessel.CreatedAt, &vessel.UpdatedAt,
 		)
 		if err != nil {
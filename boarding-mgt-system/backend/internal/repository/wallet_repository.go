@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type WalletPassRepository interface {
+	GetOrCreate(ctx context.Context, pass *models.WalletPass) (*models.WalletPass, error)
+	GetBySerial(ctx context.Context, serialNumber string) (*models.WalletPass, error)
+	UpdateSchedulePasses(ctx context.Context, scheduleID uuid.UUID, gate, notice *string) (int, error)
+	ListSchedulePushTokens(ctx context.Context, scheduleID uuid.UUID) ([]string, error)
+
+	// Device registrations
+	Register(ctx context.Context, deviceLibraryID string, passID uuid.UUID, pushToken string) (bool, error)
+	Unregister(ctx context.Context, deviceLibraryID string, passID uuid.UUID) error
+	ListUpdatedSerials(ctx context.Context, deviceLibraryID string, since *time.Time) ([]string, time.Time, error)
+}
+
+type walletPassRepository struct {
+	db *database.DB
+}
+
+func NewWalletPassRepository(db *database.DB) WalletPassRepository {
+	return &walletPassRepository{db: db}
+}
+
+const walletPassColumns = `
+	id, ticket_id, serial_number, authentication_token, gate, notice,
+	version, created_at, updated_at
+`
+
+func scanWalletPass(row pgx.Row) (*models.WalletPass, error) {
+	p := &models.WalletPass{}
+	err := row.Scan(
+		&p.ID, &p.TicketID, &p.SerialNumber, &p.AuthenticationToken, &p.Gate, &p.Notice,
+		&p.Version, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetOrCreate returns the ticket's pass, creating it from pass if the ticket
+// has none yet
+func (r *walletPassRepository) GetOrCreate(ctx context.Context, pass *models.WalletPass) (*models.WalletPass, error) {
+	insertQuery := `
+		INSERT INTO wallet_passes (ticket_id, serial_number, authentication_token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (ticket_id) DO NOTHING
+	`
+	if _, err := r.db.Pool.Exec(ctx, insertQuery, pass.TicketID, pass.SerialNumber, pass.AuthenticationToken); err != nil {
+		return nil, fmt.Errorf("failed to create wallet pass: %w", err)
+	}
+
+	query := `SELECT ` + walletPassColumns + ` FROM wallet_passes WHERE ticket_id = $1`
+
+	existing, err := scanWalletPass(r.db.Pool.QueryRow(ctx, query, pass.TicketID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet pass: %w", err)
+	}
+
+	return existing, nil
+}
+
+func (r *walletPassRepository) GetBySerial(ctx context.Context, serialNumber string) (*models.WalletPass, error) {
+	query := `SELECT ` + walletPassColumns + ` FROM wallet_passes WHERE serial_number = $1`
+
+	pass, err := scanWalletPass(r.db.Pool.QueryRow(ctx, query, serialNumber))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("wallet pass not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet pass: %w", err)
+	}
+
+	return pass, nil
+}
+
+// UpdateSchedulePasses gives every pass of a sailing a new version. Nil
+// values are left as they are and empty strings clear them.
+func (r *walletPassRepository) UpdateSchedulePasses(ctx context.Context, scheduleID uuid.UUID, gate, notice *string) (int, error) {
+	query := `
+		UPDATE wallet_passes wp SET
+			gate = CASE WHEN $2::text IS NULL THEN wp.gate ELSE NULLIF($2, '') END,
+			notice = CASE WHEN $3::text IS NULL THEN wp.notice ELSE NULLIF($3, '') END,
+			version = wp.version + 1,
+			updated_at = CURRENT_TIMESTAMP
+		FROM tickets t
+		JOIN bookings b ON t.booking_id = b.id
+		WHERE wp.ticket_id = t.id AND b.schedule_id = $1
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, scheduleID, gate, notice)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update wallet passes: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// ListSchedulePushTokens returns the push tokens of devices holding a pass
+// for a sailing
+func (r *walletPassRepository) ListSchedulePushTokens(ctx context.Context, scheduleID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT DISTINCT wr.push_token
+		FROM wallet_pass_registrations wr
+		JOIN wallet_passes wp ON wr.pass_id = wp.id
+		JOIN tickets t ON wp.ticket_id = t.id
+		JOIN bookings b ON t.booking_id = b.id
+		WHERE b.schedule_id = $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list push tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []string{}
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			return nil, fmt.Errorf("failed to scan push token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// Register records that a device holds a pass. It reports whether the
+// registration is new; a repeated registration refreshes the push token.
+func (r *walletPassRepository) Register(ctx context.Context, deviceLibraryID string, passID uuid.UUID, pushToken string) (bool, error) {
+	query := `
+		INSERT INTO wallet_pass_registrations (device_library_id, pass_id, push_token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (device_library_id, pass_id) DO UPDATE SET push_token = EXCLUDED.push_token
+		RETURNING (xmax = 0)
+	`
+
+	var created bool
+	if err := r.db.Pool.QueryRow(ctx, query, deviceLibraryID, passID, pushToken).Scan(&created); err != nil {
+		return false, fmt.Errorf("failed to register wallet device: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *walletPassRepository) Unregister(ctx context.Context, deviceLibraryID string, passID uuid.UUID) error {
+	query := `DELETE FROM wallet_pass_registrations WHERE device_library_id = $1 AND pass_id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, deviceLibraryID, passID)
+	if err != nil {
+		return fmt.Errorf("failed to unregister wallet device: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("wallet registration not found")
+	}
+
+	return nil
+}
+
+// ListUpdatedSerials returns the serial numbers of a device's passes changed
+// after since, or all of them when since is nil, with the latest change
+func (r *walletPassRepository) ListUpdatedSerials(ctx context.Context, deviceLibraryID string, since *time.Time) ([]string, time.Time, error) {
+	query := `
+		SELECT wp.serial_number, wp.updated_at
+		FROM wallet_pass_registrations wr
+		JOIN wallet_passes wp ON wr.pass_id = wp.id
+		WHERE wr.device_library_id = $1 AND ($2::timestamptz IS NULL OR wp.updated_at > $2)
+		ORDER BY wp.updated_at
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, deviceLibraryID, since)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to list updated passes: %w", err)
+	}
+	defer rows.Close()
+
+	serials := []string{}
+	var lastUpdated time.Time
+	for rows.Next() {
+		var serial string
+		var updatedAt time.Time
+		if err := rows.Scan(&serial, &updatedAt); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to scan updated pass: %w", err)
+		}
+		serials = append(serials, serial)
+		lastUpdated = updatedAt
+	}
+
+	return serials, lastUpdated, nil
+}
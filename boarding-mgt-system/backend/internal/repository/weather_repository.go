@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/database"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type WeatherRepository interface {
+	ListSailings(ctx context.Context, from, until time.Time) ([]*models.WeatherSailing, error)
+	Raise(ctx context.Context, advisory *models.WeatherAdvisory) (string, error)
+	Clear(ctx context.Context, scheduleID uuid.UUID) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.WeatherAdvisory, error)
+	List(ctx context.Context, operatorID *uuid.UUID, status string) ([]*models.WeatherAdvisory, error)
+	Review(ctx context.Context, advisory *models.WeatherAdvisory) error
+}
+
+type weatherRepository struct {
+	db *database.DB
+}
+
+func NewWeatherRepository(db *database.DB) WeatherRepository {
+	return &weatherRepository{db: db}
+}
+
+const weatherAdvisoryColumns = `
+	a.id, a.schedule_id, a.operator_id, a.vessel_id, a.exceedances, a.status,
+	a.action, a.disruption_id, a.notes, a.reviewed_by, a.reviewed_at,
+	a.checked_at, a.created_at, a.updated_at, s.route_id, s.departure_at
+`
+
+func scanWeatherAdvisory(row pgx.Row) (*models.WeatherAdvisory, error) {
+	a := &models.WeatherAdvisory{}
+	err := row.Scan(
+		&a.ID, &a.ScheduleID, &a.OperatorID, &a.VesselID, &a.Exceedances, &a.Status,
+		&a.Action, &a.DisruptionID, &a.Notes, &a.ReviewedBy, &a.ReviewedAt,
+		&a.CheckedAt, &a.CreatedAt, &a.UpdatedAt, &a.RouteID, &a.DepartureAt,
+	)
+	return a, err
+}
+
+// ListSailings lists the sailings on sale and not yet boarding that are
+// expected to leave between from and until, with their ports and the limits
+// of their vessel
+func (r *weatherRepository) ListSailings(ctx context.Context, from, until time.Time) ([]*models.WeatherSailing, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT
+			s.id, s.operator_id, s.route_id, s.vessel_id, s.departure_date,
+			s.departure_at, s.arrival_at, s.estimated_departure_at, s.estimated_arrival_at,
+			s.status, dp.code, ap.code, v.weather_limits
+		FROM schedules s
+		JOIN routes rt ON rt.id = s.route_id
+		JOIN ports dp ON dp.id = rt.departure_port_id
+		JOIN ports ap ON ap.id = rt.arrival_port_id
+		JOIN vessels v ON v.id = s.vessel_id
+		WHERE s.status = 'scheduled' AND NOT s.unpublished
+			AND COALESCE(s.estimated_departure_at, s.departure_at) >= $1
+			AND COALESCE(s.estimated_departure_at, s.departure_at) < $2
+		ORDER BY COALESCE(s.estimated_departure_at, s.departure_at), s.id
+	`, from, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sailings: %w", err)
+	}
+	defer rows.Close()
+
+	sailings := []*models.WeatherSailing{}
+	for rows.Next() {
+		schedule := &models.Schedule{}
+		sailing := &models.WeatherSailing{Schedule: schedule}
+		err := rows.Scan(
+			&schedule.ID, &schedule.OperatorID, &schedule.RouteID, &schedule.VesselID, &schedule.DepartureDate,
+			&schedule.DepartureAt, &schedule.ArrivalAt, &schedule.EstimatedDepartureAt, &schedule.EstimatedArrivalAt,
+			&schedule.Status, &sailing.DeparturePort, &sailing.ArrivalPort, &sailing.Limits,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sailing: %w", err)
+		}
+		sailings = append(sailings, sailing)
+	}
+
+	return sailings, nil
+}
+
+// Raise opens an advisory for a sailing, or updates the forecast of its
+// advisory if it is open or was cleared, reopening it. Advisories that have
+// been reviewed are left as they are. The advisory's previous status is
+// returned, or "" if the sailing had none.
+func (r *weatherRepository) Raise(ctx context.Context, advisory *models.WeatherAdvisory) (string, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previous string
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM weather_advisories WHERE schedule_id = $1 FOR UPDATE
+	`, advisory.ScheduleID).Scan(&previous)
+	if err != nil && err != pgx.ErrNoRows {
+		return "", fmt.Errorf("failed to get weather advisory: %w", err)
+	}
+
+	switch previous {
+	case "":
+		err = tx.QueryRow(ctx, `
+			INSERT INTO weather_advisories (schedule_id, operator_id, vessel_id, exceedances)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, status, checked_at, created_at, updated_at
+		`, advisory.ScheduleID, advisory.OperatorID, advisory.VesselID, advisory.Exceedances,
+		).Scan(&advisory.ID, &advisory.Status, &advisory.CheckedAt, &advisory.CreatedAt, &advisory.UpdatedAt)
+	case "open", "cleared":
+		err = tx.QueryRow(ctx, `
+			UPDATE weather_advisories SET
+				vessel_id = $2,
+				exceedances = $3,
+				status = 'open',
+				checked_at = CURRENT_TIMESTAMP
+			WHERE schedule_id = $1
+			RETURNING id, status, checked_at, created_at, updated_at
+		`, advisory.ScheduleID, advisory.VesselID, advisory.Exceedances,
+		).Scan(&advisory.ID, &advisory.Status, &advisory.CheckedAt, &advisory.CreatedAt, &advisory.UpdatedAt)
+	default:
+		return previous, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to raise weather advisory: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return previous, nil
+}
+
+// Clear clears a sailing's open advisory once its forecast is back within
+// limits, reporting whether it had one
+func (r *weatherRepository) Clear(ctx context.Context, scheduleID uuid.UUID) (bool, error) {
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE weather_advisories SET status = 'cleared'
+		WHERE schedule_id = $1 AND status = 'open'
+	`, scheduleID)
+	if err != nil {
+		return false, fmt.Errorf("failed to clear weather advisory: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *weatherRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.WeatherAdvisory, error) {
+	advisory, err := scanWeatherAdvisory(r.db.Pool.QueryRow(ctx, `
+		SELECT `+weatherAdvisoryColumns+`
+		FROM weather_advisories a
+		JOIN schedules s ON s.id = a.schedule_id
+		WHERE a.id = $1
+	`, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("weather advisory not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weather advisory: %w", err)
+	}
+
+	return advisory, nil
+}
+
+// List lists advisories, soonest departure first, optionally only an
+// operator's and only those with a status
+func (r *weatherRepository) List(ctx context.Context, operatorID *uuid.UUID, status string) ([]*models.WeatherAdvisory, error) {
+	query := `
+		SELECT ` + weatherAdvisoryColumns + `
+		FROM weather_advisories a
+		JOIN schedules s ON s.id = a.schedule_id
+		WHERE true
+	`
+	args := []interface{}{}
+
+	if operatorID != nil {
+		args = append(args, *operatorID)
+		query += fmt.Sprintf(` AND a.operator_id = $%d`, len(args))
+	}
+	if status != "" {
+		args = append(args, status)
+		query += fmt.Sprintf(` AND a.status = $%d`, len(args))
+	}
+	query += ` ORDER BY s.departure_at, a.created_at`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list weather advisories: %w", err)
+	}
+	defer rows.Close()
+
+	advisories := []*models.WeatherAdvisory{}
+	for rows.Next() {
+		advisory, err := scanWeatherAdvisory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan weather advisory: %w", err)
+		}
+		advisories = append(advisories, advisory)
+	}
+
+	return advisories, nil
+}
+
+// Review records how an open advisory was dealt with: its status, action,
+// disruption, notes and reviewer
+func (r *weatherRepository) Review(ctx context.Context, advisory *models.WeatherAdvisory) error {
+	err := r.db.Pool.QueryRow(ctx, `
+		UPDATE weather_advisories SET
+			status = $2,
+			action = $3,
+			disruption_id = $4,
+			notes = $5,
+			reviewed_by = $6,
+			reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'open'
+		RETURNING reviewed_at, updated_at
+	`, advisory.ID, advisory.Status, advisory.Action, advisory.DisruptionID, advisory.Notes, advisory.ReviewedBy,
+	).Scan(&advisory.ReviewedAt, &advisory.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("only open weather advisories can be reviewed")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to review weather advisory: %w", err)
+	}
+
+	return nil
+}
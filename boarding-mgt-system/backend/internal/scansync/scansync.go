@@ -0,0 +1,199 @@
+// Package scansync merges scans recorded offline by gate and onboard scanners
+// into the server's view of who has checked in and boarded.
+//
+// A scanner decides each scan locally from its bundle and uploads the
+// decisions later. The passenger has already been let through or turned away
+// by then, so the server records what happened rather than re-deciding it,
+// and reports where that disagrees with what it knew.
+package scansync
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/gate"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/ticketqr"
+	"github.com/google/uuid"
+)
+
+// Upload statuses
+const (
+	StatusApplied   = "applied"
+	StatusDuplicate = "duplicate"
+	StatusRejected  = "rejected"
+)
+
+// Conflicts between an uploaded scan and the server's state
+const (
+	// The ticket was also boarded by another scan
+	ConflictAlreadyBoarded = "already_boarded"
+	// The device let the passenger through but the server would have refused
+	ConflictServerDenied = "server_denied"
+	// A check-in arrived after the ticket had boarded and changed nothing
+	ConflictSuperseded = "superseded"
+)
+
+// Reasons an uploaded event is rejected without being recorded
+const (
+	RejectInvalidTimestamp = "invalid_timestamp"
+	RejectNotAssigned      = "schedule_not_assigned"
+	RejectTicketNotFound   = "ticket_not_found"
+)
+
+// BundlePurpose is what bundle signatures are made for
+const BundlePurpose = "scanner-bundle"
+
+// MaxEventAge is how old an uploaded scan may be
+const MaxEventAge = 7 * 24 * time.Hour
+
+// CheckTimestamp rejects device times in the future, allowing for clock
+// skew, or too old to belong to a current sailing
+func CheckTimestamp(scannedAt, now time.Time, leeway time.Duration) error {
+	if scannedAt.After(now.Add(leeway)) {
+		return fmt.Errorf("scan time %s is in the future", scannedAt.Format(time.RFC3339))
+	}
+	if scannedAt.Before(now.Add(-MaxEventAge)) {
+		return fmt.Errorf("scan time %s is too old", scannedAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Ticket is a ticket's server state when an upload is merged
+type Ticket struct {
+	gate.Ticket
+	BoardedAt *time.Time
+	// Device that recorded the boarding, nil when boarded online
+	BoardedBy *uuid.UUID
+}
+
+// Event is an uploaded scan
+type Event struct {
+	DeviceID   uuid.UUID
+	ScheduleID uuid.UUID
+	Action     string
+	Result     string
+	DenyReason string
+	ScannedAt  time.Time
+}
+
+// Outcome is how an event changes a ticket and what is logged for it
+type Outcome struct {
+	Status         string
+	Result         string
+	DenyReason     string
+	Conflict       string
+	ConflictDetail string
+	// Changed reports whether the ticket's status or boarding time moves
+	Changed   bool
+	BoardedAt *time.Time
+}
+
+// Merge applies an uploaded event to a ticket. Passengers a device let
+// through are recorded as through even when the server disagrees, so the
+// manifest matches who is aboard. When two scans board the same ticket the
+// earliest by device time is kept as the boarding and the other is reported.
+func Merge(t Ticket, e Event, policy gate.Policy) Outcome {
+	unchanged := Outcome{Status: t.CheckInStatus, Result: gate.ResultAccepted, BoardedAt: t.BoardedAt}
+
+	if e.Result == gate.ResultDenied {
+		// Devices may not send a reason, or one this server does not know
+		reason := e.DenyReason
+		if gate.Message(reason) == "" {
+			reason = policy.Evaluate(t.Ticket, e.Action, e.ScheduleID, e.ScannedAt).Reason
+		}
+		if reason == "" {
+			reason = gate.ReasonInvalidCode
+		}
+		unchanged.Result = gate.ResultDenied
+		unchanged.DenyReason = reason
+		return unchanged
+	}
+
+	if t.CheckInStatus == gate.StatusBoarded {
+		if e.Action == gate.ActionCheckIn {
+			unchanged.Conflict = ConflictSuperseded
+			unchanged.ConflictDetail = "ticket had already boarded"
+			return unchanged
+		}
+
+		outcome := unchanged
+		outcome.Conflict = ConflictAlreadyBoarded
+		outcome.ConflictDetail = boardedDetail(t)
+		if t.BoardedAt == nil || e.ScannedAt.Before(*t.BoardedAt) {
+			at := e.ScannedAt
+			outcome.BoardedAt = &at
+			outcome.Changed = true
+		}
+		return outcome
+	}
+
+	if e.Action == gate.ActionCheckIn && t.CheckInStatus == gate.StatusCheckedIn {
+		return unchanged
+	}
+
+	outcome := Outcome{
+		Status:  gate.StatusAfter(e.Action),
+		Result:  gate.ResultAccepted,
+		Changed: true,
+	}
+	if e.Action == gate.ActionBoard {
+		at := e.ScannedAt
+		outcome.BoardedAt = &at
+	}
+
+	decision := policy.Evaluate(t.Ticket, e.Action, e.ScheduleID, e.ScannedAt)
+	if !decision.Allowed() {
+		outcome.Conflict = ConflictServerDenied
+		outcome.ConflictDetail = decision.Reason
+	}
+
+	return outcome
+}
+
+func boardedDetail(t Ticket) string {
+	detail := "ticket was also boarded"
+	if t.BoardedBy != nil {
+		detail += " by device " + t.BoardedBy.String()
+	} else {
+		detail += " at an online gate"
+	}
+	if t.BoardedAt != nil {
+		detail += " at " + t.BoardedAt.UTC().Format(time.RFC3339)
+	}
+	return detail
+}
+
+// SignBundle encodes a bundle and signs it with the ticket signing key
+func SignBundle(bundle *models.ScannerBundle, signer *ticketqr.Signer) (*models.SignedBundle, error) {
+	payload, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode scanner bundle: %w", err)
+	}
+
+	return &models.SignedBundle{
+		KeyID:     signer.KeyID(),
+		Payload:   base64.RawURLEncoding.EncodeToString(payload),
+		Signature: signer.SignMessage(BundlePurpose, payload),
+	}, nil
+}
+
+// OpenBundle verifies a signed bundle and decodes it, as a scanner does
+func OpenBundle(signed *models.SignedBundle, verifier *ticketqr.Verifier) (*models.ScannerBundle, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(signed.Payload)
+	if err != nil {
+		return nil, ticketqr.ErrMalformed
+	}
+
+	if err := verifier.VerifyMessage(signed.KeyID, BundlePurpose, payload, signed.Signature); err != nil {
+		return nil, err
+	}
+
+	bundle := &models.ScannerBundle{}
+	if err := json.Unmarshal(payload, bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode scanner bundle: %w", err)
+	}
+	return bundle, nil
+}
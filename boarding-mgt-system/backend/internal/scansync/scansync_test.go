@@ -0,0 +1,150 @@
+package scansync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/gate"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/ticketqr"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	scheduleID := uuid.New()
+	departure := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	ticket := Ticket{Ticket: gate.Ticket{
+		ScheduleID:     scheduleID,
+		CheckInStatus:  gate.StatusNotCheckedIn,
+		BookingStatus:  "confirmed",
+		PaymentStatus:  "paid",
+		ScheduleStatus: "scheduled",
+		Departure:      departure,
+	}}
+	board := Event{
+		DeviceID:   uuid.New(),
+		ScheduleID: scheduleID,
+		Action:     gate.ActionBoard,
+		Result:     gate.ResultAccepted,
+		ScannedAt:  departure.Add(-20 * time.Minute),
+	}
+
+	t.Run("Boarding offline", func(t *testing.T) {
+		outcome := Merge(ticket, board, gate.DefaultPolicy)
+		assert.Equal(t, gate.StatusBoarded, outcome.Status)
+		assert.Equal(t, gate.ResultAccepted, outcome.Result)
+		assert.True(t, outcome.Changed)
+		assert.Empty(t, outcome.Conflict)
+		require.NotNil(t, outcome.BoardedAt)
+		assert.True(t, board.ScannedAt.Equal(*outcome.BoardedAt), "boarding time is the device's")
+	})
+
+	t.Run("Two devices board the same ticket", func(t *testing.T) {
+		otherDevice := uuid.New()
+		firstAt := board.ScannedAt.Add(-5 * time.Minute)
+		boarded := ticket
+		boarded.CheckInStatus = gate.StatusBoarded
+		boarded.BoardedAt = &firstAt
+		boarded.BoardedBy = &otherDevice
+
+		outcome := Merge(boarded, board, gate.DefaultPolicy)
+		assert.Equal(t, ConflictAlreadyBoarded, outcome.Conflict)
+		assert.Contains(t, outcome.ConflictDetail, otherDevice.String())
+		assert.False(t, outcome.Changed)
+		assert.True(t, firstAt.Equal(*outcome.BoardedAt))
+
+		// Uploaded later but scanned first: the earlier boarding time wins
+		earlier := board
+		earlier.ScannedAt = firstAt.Add(-time.Minute)
+		outcome = Merge(boarded, earlier, gate.DefaultPolicy)
+		assert.Equal(t, ConflictAlreadyBoarded, outcome.Conflict)
+		assert.True(t, outcome.Changed)
+		assert.True(t, earlier.ScannedAt.Equal(*outcome.BoardedAt))
+		assert.Equal(t, gate.StatusBoarded, outcome.Status)
+	})
+
+	t.Run("Check-in after boarding", func(t *testing.T) {
+		boarded := ticket
+		boarded.CheckInStatus = gate.StatusBoarded
+		checkIn := board
+		checkIn.Action = gate.ActionCheckIn
+
+		outcome := Merge(boarded, checkIn, gate.DefaultPolicy)
+		assert.Equal(t, ConflictSuperseded, outcome.Conflict)
+		assert.Equal(t, gate.StatusBoarded, outcome.Status)
+		assert.False(t, outcome.Changed)
+	})
+
+	t.Run("Device let through a passenger the server would refuse", func(t *testing.T) {
+		cancelled := ticket
+		cancelled.BookingStatus = "cancelled"
+
+		outcome := Merge(cancelled, board, gate.DefaultPolicy)
+		assert.Equal(t, gate.StatusBoarded, outcome.Status, "the passenger is aboard")
+		assert.Equal(t, ConflictServerDenied, outcome.Conflict)
+		assert.Equal(t, gate.ReasonCancelledBooking, outcome.ConflictDetail)
+	})
+
+	t.Run("Policy is judged at the device time", func(t *testing.T) {
+		late := board
+		late.ScannedAt = departure.Add(-time.Minute)
+		outcome := Merge(ticket, late, gate.DefaultPolicy)
+		assert.Equal(t, ConflictServerDenied, outcome.Conflict)
+		assert.Equal(t, gate.ReasonBoardingClosed, outcome.ConflictDetail)
+	})
+
+	t.Run("Denied locally", func(t *testing.T) {
+		denied := board
+		denied.Result = gate.ResultDenied
+		denied.DenyReason = gate.ReasonUnpaid
+
+		outcome := Merge(ticket, denied, gate.DefaultPolicy)
+		assert.Equal(t, gate.ResultDenied, outcome.Result)
+		assert.Equal(t, gate.ReasonUnpaid, outcome.DenyReason)
+		assert.Equal(t, gate.StatusNotCheckedIn, outcome.Status)
+		assert.False(t, outcome.Changed)
+
+		denied.DenyReason = "battery_low"
+		outcome = Merge(ticket, denied, gate.DefaultPolicy)
+		assert.Equal(t, gate.ReasonInvalidCode, outcome.DenyReason, "unknown reasons are replaced")
+	})
+}
+
+func TestCheckTimestamp(t *testing.T) {
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, CheckTimestamp(now.Add(-time.Hour), now, time.Minute))
+	assert.NoError(t, CheckTimestamp(now.Add(30*time.Second), now, time.Minute))
+	assert.Error(t, CheckTimestamp(now.Add(time.Hour), now, time.Minute))
+	assert.Error(t, CheckTimestamp(now.Add(-MaxEventAge-time.Hour), now, time.Minute))
+}
+
+func TestSignBundle(t *testing.T) {
+	keyring, err := ticketqr.GenerateKeyring("k1")
+	require.NoError(t, err)
+
+	bundle := &models.ScannerBundle{
+		DeviceID:    uuid.New(),
+		OperatorID:  uuid.New(),
+		GeneratedAt: time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC),
+		Schedules: []*models.BundleSchedule{{
+			ScheduleID: uuid.New(),
+			Passengers: []*models.BundlePassenger{{TicketID: uuid.New(), PassengerName: "Ana"}},
+		}},
+	}
+
+	signed, err := SignBundle(bundle, keyring.Signer())
+	require.NoError(t, err)
+	assert.Equal(t, "k1", signed.KeyID)
+
+	opened, err := OpenBundle(signed, keyring.Verifier(0))
+	require.NoError(t, err)
+	assert.Equal(t, bundle.DeviceID, opened.DeviceID)
+	assert.Equal(t, "Ana", opened.Schedules[0].Passengers[0].PassengerName)
+
+	other, err := ticketqr.GenerateKeyring("k1")
+	require.NoError(t, err)
+	_, err = OpenBundle(signed, other.Verifier(0))
+	assert.ErrorIs(t, err, ticketqr.ErrBadSignature)
+}
@@ -0,0 +1,215 @@
+// Package seating carries passengers' seats over when they move to another
+// sailing or their sailing changes vessel.
+//
+// A vessel's seat classes are read from its seat configuration:
+//
+//	{"classes": [{"name": "premium", "prefix": "P", "seats": 40},
+//	             {"name": "standard", "prefix": "S", "seats": 200}]}
+//
+// numbers the premium seats P1 to P40 and the standard seats S1 to S200.
+// Vessels without classes have free seating: any seat number can be held,
+// once per sailing.
+package seating
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrClassFull is returned when a passenger's seat class has no free seat
+var ErrClassFull = errors.New("no free seat in class")
+
+// Class is a vessel's seat class
+type Class struct {
+	Name   string
+	Prefix string
+	Seats  int
+}
+
+// Layout is a vessel's seat classes
+type Layout struct {
+	Classes []Class
+}
+
+// LayoutFromConfiguration reads the seat classes from a vessel's seat
+// configuration. Vessels without classes have no layout.
+func LayoutFromConfiguration(config map[string]interface{}) *Layout {
+	entries, _ := config["classes"].([]interface{})
+
+	layout := &Layout{}
+	for _, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := fields["name"].(string)
+		prefix, _ := fields["prefix"].(string)
+		seats, _ := fields["seats"].(float64)
+		if name == "" || seats < 1 {
+			continue
+		}
+		layout.Classes = append(layout.Classes, Class{Name: name, Prefix: prefix, Seats: int(seats)})
+	}
+
+	if len(layout.Classes) == 0 {
+		return nil
+	}
+	return layout
+}
+
+// Capacity is the number of seats across every class
+func (l *Layout) Capacity() int {
+	total := 0
+	for _, class := range l.Classes {
+		total += class.Seats
+	}
+	return total
+}
+
+// ClassOf returns the class a seat number belongs to. Seat numbers are
+// matched on the longest prefix, so "PX1" is not taken for a "P" seat.
+func (l *Layout) ClassOf(seat string) (Class, bool) {
+	var found Class
+	ok := false
+	for _, class := range l.Classes {
+		if !strings.HasPrefix(seat, class.Prefix) || (ok && len(class.Prefix) <= len(found.Prefix)) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(seat, class.Prefix))
+		if err != nil || n < 1 || n > class.Seats {
+			continue
+		}
+		found, ok = class, true
+	}
+	return found, ok
+}
+
+// Seat is the seat number of a class's nth seat
+func (c Class) Seat(n int) string {
+	return c.Prefix + strconv.Itoa(n)
+}
+
+// Assign works out the seats a party gets on a sailing whose seats already
+// taken are marked in taken. Each passenger keeps their seat number when it
+// is free. Otherwise they get the first free seat in the same class, or,
+// without a layout or for a seat the layout does not have, no seat.
+// Passengers without a seat stay without one.
+//
+// A class with no free seat fails the whole party with ErrClassFull and
+// leaves taken alone; otherwise the seats given are marked in taken.
+func Assign(layout *Layout, seats []*string, taken map[string]bool) ([]*string, error) {
+	assigned := make([]*string, len(seats))
+	held := map[string]bool{}
+	free := func(seat string) bool { return !taken[seat] && !held[seat] }
+
+	for i, seat := range seats {
+		if seat == nil || *seat == "" {
+			continue
+		}
+
+		if free(*seat) && (layout == nil || inLayout(layout, *seat)) {
+			assigned[i] = give(*seat, held)
+			continue
+		}
+		if layout == nil {
+			continue
+		}
+
+		class, ok := layout.ClassOf(*seat)
+		if !ok {
+			continue
+		}
+		found := false
+		for n := 1; n <= class.Seats; n++ {
+			if candidate := class.Seat(n); free(candidate) {
+				assigned[i] = give(candidate, held)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w %s", ErrClassFull, class.Name)
+		}
+	}
+
+	for seat := range held {
+		taken[seat] = true
+	}
+	return assigned, nil
+}
+
+// Remap carries every seat on a sailing from a vessel with layout from over
+// to one with layout to, for when the sailing changes vessel. Seat numbers
+// the new vessel has are kept, first come first served. The rest move to the
+// first free seat in the class of the same name, or lose their seat when that
+// class is full or the new vessel does not have it.
+func Remap(from, to *Layout, seats []*string) []*string {
+	remapped := make([]*string, len(seats))
+	taken := map[string]bool{}
+
+	var moving []int
+	for i, seat := range seats {
+		if seat == nil || *seat == "" {
+			continue
+		}
+		if !taken[*seat] && (to == nil || inLayout(to, *seat)) {
+			remapped[i] = give(*seat, taken)
+			continue
+		}
+		moving = append(moving, i)
+	}
+	if to == nil {
+		return remapped
+	}
+
+	// Kept seats are placed first so nobody moving takes one of them
+	for _, i := range moving {
+		class, ok := to.ClassOf(*seats[i])
+		if from != nil {
+			var old Class
+			if old, ok = from.ClassOf(*seats[i]); ok {
+				class, ok = to.class(old.Name)
+			}
+		}
+		if !ok {
+			continue
+		}
+		for n := 1; n <= class.Seats; n++ {
+			if candidate := class.Seat(n); !taken[candidate] {
+				remapped[i] = give(candidate, taken)
+				break
+			}
+		}
+	}
+
+	return remapped
+}
+
+func (l *Layout) class(name string) (Class, bool) {
+	for _, class := range l.Classes {
+		if class.Name == name {
+			return class, true
+		}
+	}
+	return Class{}, false
+}
+
+func inLayout(layout *Layout, seat string) bool {
+	_, ok := layout.ClassOf(seat)
+	return ok
+}
+
+func give(seat string, held map[string]bool) *string {
+	held[seat] = true
+	return &seat
+}
+
+// Changed reports whether a passenger's seat differs after assignment
+func Changed(from, to *string) bool {
+	if from == nil || *from == "" {
+		return false
+	}
+	return to == nil || *to != *from
+}
@@ -0,0 +1,152 @@
+package seating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seat(s string) *string {
+	return &s
+}
+
+func numbers(seats []*string) []string {
+	list := make([]string, len(seats))
+	for i, s := range seats {
+		if s != nil {
+			list[i] = *s
+		}
+	}
+	return list
+}
+
+var twoClasses = &Layout{Classes: []Class{
+	{Name: "premium", Prefix: "P", Seats: 2},
+	{Name: "standard", Prefix: "S", Seats: 3},
+}}
+
+func TestLayoutFromConfiguration(t *testing.T) {
+	assert.Nil(t, LayoutFromConfiguration(map[string]interface{}{"decks": float64(2), "seats_per_deck": float64(100)}))
+	assert.Nil(t, LayoutFromConfiguration(nil))
+
+	layout := LayoutFromConfiguration(map[string]interface{}{
+		"classes": []interface{}{
+			map[string]interface{}{"name": "premium", "prefix": "P", "seats": float64(40)},
+			map[string]interface{}{"name": "standard", "prefix": "S", "seats": float64(200)},
+			map[string]interface{}{"name": "broken"},
+		},
+	})
+	require.NotNil(t, layout)
+	assert.Equal(t, []Class{{"premium", "P", 40}, {"standard", "S", 200}}, layout.Classes)
+	assert.Equal(t, 240, layout.Capacity())
+}
+
+func TestClassOf(t *testing.T) {
+	layout := &Layout{Classes: []Class{
+		{Name: "premium", Prefix: "P", Seats: 10},
+		{Name: "premium extra", Prefix: "PX", Seats: 5},
+	}}
+
+	class, ok := layout.ClassOf("P7")
+	require.True(t, ok)
+	assert.Equal(t, "premium", class.Name)
+
+	class, ok = layout.ClassOf("PX3")
+	require.True(t, ok)
+	assert.Equal(t, "premium extra", class.Name)
+
+	_, ok = layout.ClassOf("P11")
+	assert.False(t, ok)
+	_, ok = layout.ClassOf("Q1")
+	assert.False(t, ok)
+}
+
+func TestAssign(t *testing.T) {
+	t.Run("Free seating keeps free seats and drops taken ones", func(t *testing.T) {
+		taken := map[string]bool{"12A": true}
+		assigned, err := Assign(nil, []*string{seat("12A"), seat("12B"), nil}, taken)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"", "12B", ""}, numbers(assigned))
+		assert.True(t, taken["12B"])
+	})
+
+	t.Run("Keeps seats that are free in the class", func(t *testing.T) {
+		taken := map[string]bool{}
+		assigned, err := Assign(twoClasses, []*string{seat("P2"), seat("S1")}, taken)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"P2", "S1"}, numbers(assigned))
+	})
+
+	t.Run("Moves to the next free seat in the same class", func(t *testing.T) {
+		taken := map[string]bool{"S1": true, "S2": true}
+		assigned, err := Assign(twoClasses, []*string{seat("S1"), seat("S2")}, taken)
+		require.ErrorIs(t, err, ErrClassFull)
+		assert.Nil(t, assigned)
+		assert.Len(t, taken, 2, "a failed party holds no seats")
+
+		assigned, err = Assign(twoClasses, []*string{seat("S1")}, taken)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"S3"}, numbers(assigned))
+	})
+
+	t.Run("Does not move passengers to another class", func(t *testing.T) {
+		taken := map[string]bool{"P1": true, "P2": true}
+		_, err := Assign(twoClasses, []*string{seat("P1")}, taken)
+		assert.ErrorIs(t, err, ErrClassFull)
+	})
+
+	t.Run("Seats the layout does not have are dropped", func(t *testing.T) {
+		assigned, err := Assign(twoClasses, []*string{seat("12A")}, map[string]bool{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{""}, numbers(assigned))
+	})
+
+	t.Run("A party does not share seats", func(t *testing.T) {
+		assigned, err := Assign(twoClasses, []*string{seat("P1"), seat("P1")}, map[string]bool{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"P1", "P2"}, numbers(assigned))
+	})
+}
+
+func TestRemap(t *testing.T) {
+	larger := &Layout{Classes: []Class{
+		{Name: "premium", Prefix: "P", Seats: 2},
+		{Name: "standard", Prefix: "S", Seats: 4},
+	}}
+
+	t.Run("Kept seats win over seats moving into the class", func(t *testing.T) {
+		// S4 does not exist on the new vessel; S1 and S2 are kept before it
+		// moves, so it gets S3
+		remapped := Remap(larger, twoClasses, []*string{seat("S4"), seat("S1"), seat("S2"), nil})
+		assert.Equal(t, []string{"S3", "S1", "S2", ""}, numbers(remapped))
+	})
+
+	t.Run("Passengers lose their seat when the class is full", func(t *testing.T) {
+		remapped := Remap(larger, twoClasses, []*string{seat("S4"), seat("S1"), seat("S2"), seat("S3")})
+		assert.Equal(t, []string{"", "S1", "S2", "S3"}, numbers(remapped))
+	})
+
+	t.Run("Classes are matched by name", func(t *testing.T) {
+		renumbered := &Layout{Classes: []Class{{Name: "premium", Prefix: "A", Seats: 2}}}
+		remapped := Remap(twoClasses, renumbered, []*string{seat("P2"), seat("S1")})
+		assert.Equal(t, []string{"A1", ""}, numbers(remapped))
+	})
+
+	t.Run("Free seating keeps every seat once", func(t *testing.T) {
+		remapped := Remap(twoClasses, nil, []*string{seat("P1"), seat("12A"), seat("12A")})
+		assert.Equal(t, []string{"P1", "12A", ""}, numbers(remapped))
+	})
+
+	t.Run("Seats from free seating without a class are dropped", func(t *testing.T) {
+		remapped := Remap(nil, twoClasses, []*string{seat("12A"), seat("S1"), seat("S1")})
+		assert.Equal(t, []string{"", "S1", "S2"}, numbers(remapped))
+	})
+}
+
+func TestChanged(t *testing.T) {
+	assert.False(t, Changed(nil, nil))
+	assert.False(t, Changed(seat("P1"), seat("P1")))
+	assert.True(t, Changed(seat("P1"), seat("P2")))
+	assert.True(t, Changed(seat("P1"), nil))
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/agency"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type AgencyService interface {
+	CreateAgency(ctx context.Context, operatorID uuid.UUID, req *models.CreateAgencyRequest) (*models.Agency, error)
+	GetAgency(ctx context.Context, id uuid.UUID) (*models.Agency, error)
+	GetAgentAgency(ctx context.Context, agentID uuid.UUID) (*models.Agency, error)
+	UpdateAgency(ctx context.Context, id uuid.UUID, req *models.UpdateAgencyRequest) (*models.Agency, error)
+	ListAgencies(ctx context.Context, filter *models.AgencyFilter) ([]*models.Agency, int, error)
+	AddCommissionRule(ctx context.Context, agencyID uuid.UUID, req *models.CreateCommissionRuleRequest) (*models.AgencyCommissionRule, error)
+	DeleteCommissionRule(ctx context.Context, agencyID, ruleID uuid.UUID) error
+	AssignAgent(ctx context.Context, agencyID, userID uuid.UUID) error
+	RemoveAgent(ctx context.Context, agencyID, userID uuid.UUID) error
+	RecordTransaction(ctx context.Context, agencyID, createdBy uuid.UUID, req *models.AgencyTransactionRequest) (*models.AgencyTransaction, error)
+	ListTransactions(ctx context.Context, agencyID uuid.UUID, start, end time.Time) ([]models.AgencyTransaction, error)
+	GetStatement(ctx context.Context, agencyID uuid.UUID, month string) (*models.AgencyStatement, error)
+}
+
+type agencyService struct {
+	agencyRepo repository.AgencyRepository
+	userRepo   repository.UserRepository
+}
+
+func NewAgencyService(agencyRepo repository.AgencyRepository, userRepo repository.UserRepository) AgencyService {
+	return &agencyService{
+		agencyRepo: agencyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (s *agencyService) CreateAgency(ctx context.Context, operatorID uuid.UUID, req *models.CreateAgencyRequest) (*models.Agency, error) {
+	a := &models.Agency{
+		OperatorID:            operatorID,
+		Code:                  strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:                  req.Name,
+		AccountType:           req.AccountType,
+		CreditLimit:           req.CreditLimit,
+		DefaultCommissionRate: req.DefaultCommissionRate,
+	}
+	if req.ContactEmail != "" {
+		a.ContactEmail = &req.ContactEmail
+	}
+	if req.ContactPhone != "" {
+		a.ContactPhone = &req.ContactPhone
+	}
+
+	if err := validateCreditLimit(a); err != nil {
+		return nil, err
+	}
+
+	if err := s.agencyRepo.Create(ctx, a); err != nil {
+		return nil, fmt.Errorf("failed to create agency: %w", err)
+	}
+
+	return a, nil
+}
+
+func (s *agencyService) GetAgency(ctx context.Context, id uuid.UUID) (*models.Agency, error) {
+	a, err := s.agencyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.agencyRepo.ListCommissionRules(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	a.CommissionRules = rules
+
+	return a, nil
+}
+
+// GetAgentAgency returns the agency an agent sells for
+func (s *agencyService) GetAgentAgency(ctx context.Context, agentID uuid.UUID) (*models.Agency, error) {
+	agent, err := s.userRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("agent not found: %w", err)
+	}
+
+	if agent.AgencyID == nil {
+		return nil, fmt.Errorf("agent does not belong to an agency")
+	}
+
+	return s.GetAgency(ctx, *agent.AgencyID)
+}
+
+func (s *agencyService) UpdateAgency(ctx context.Context, id uuid.UUID, req *models.UpdateAgencyRequest) (*models.Agency, error) {
+	a, err := s.agencyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		a.Name = *req.Name
+	}
+	if req.ContactEmail != nil {
+		a.ContactEmail = req.ContactEmail
+	}
+	if req.ContactPhone != nil {
+		a.ContactPhone = req.ContactPhone
+	}
+	if req.AccountType != nil {
+		a.AccountType = *req.AccountType
+		if a.AccountType == "prepaid" && req.CreditLimit == nil {
+			a.CreditLimit = money.Zero(a.CreditLimit.Currency())
+		}
+	}
+	if req.CreditLimit != nil {
+		a.CreditLimit = *req.CreditLimit
+	}
+	if req.DefaultCommissionRate != nil {
+		a.DefaultCommissionRate = *req.DefaultCommissionRate
+	}
+	if req.IsActive != nil {
+		a.IsActive = *req.IsActive
+	}
+
+	if err := validateCreditLimit(a); err != nil {
+		return nil, err
+	}
+
+	if err := s.agencyRepo.Update(ctx, a); err != nil {
+		return nil, fmt.Errorf("failed to update agency: %w", err)
+	}
+
+	return a, nil
+}
+
+func (s *agencyService) ListAgencies(ctx context.Context, filter *models.AgencyFilter) ([]*models.Agency, int, error) {
+	agencies, total, err := s.agencyRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list agencies: %w", err)
+	}
+
+	return agencies, total, nil
+}
+
+func (s *agencyService) AddCommissionRule(ctx context.Context, agencyID uuid.UUID, req *models.CreateCommissionRuleRequest) (*models.AgencyCommissionRule, error) {
+	if req.RouteID == nil && req.FareClass == nil {
+		return nil, fmt.Errorf("a commission rule needs a route or a fare class")
+	}
+
+	rule := &models.AgencyCommissionRule{
+		AgencyID:       agencyID,
+		RouteID:        req.RouteID,
+		FareClass:      req.FareClass,
+		CommissionRate: req.CommissionRate,
+	}
+
+	if err := s.agencyRepo.CreateCommissionRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to add commission rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *agencyService) DeleteCommissionRule(ctx context.Context, agencyID, ruleID uuid.UUID) error {
+	return s.agencyRepo.DeleteCommissionRule(ctx, agencyID, ruleID)
+}
+
+func (s *agencyService) AssignAgent(ctx context.Context, agencyID, userID uuid.UUID) error {
+	return s.agencyRepo.AssignAgent(ctx, agencyID, userID)
+}
+
+func (s *agencyService) RemoveAgent(ctx context.Context, agencyID, userID uuid.UUID) error {
+	return s.agencyRepo.RemoveAgent(ctx, agencyID, userID)
+}
+
+// RecordTransaction records money received from an agency or a manual
+// correction to its account. Bookings, commission and refunds are posted by
+// the booking service.
+func (s *agencyService) RecordTransaction(ctx context.Context, agencyID, createdBy uuid.UUID, req *models.AgencyTransactionRequest) (*models.AgencyTransaction, error) {
+	switch {
+	case req.Amount.IsZero():
+		return nil, fmt.Errorf("amount cannot be zero")
+	case req.TransactionType != "adjustment" && req.Amount.IsNegative():
+		return nil, fmt.Errorf("%s amount must be positive", req.TransactionType)
+	}
+
+	txn := &models.AgencyTransaction{
+		TransactionType: req.TransactionType,
+		Amount:          req.Amount,
+		CreatedBy:       &createdBy,
+	}
+	if req.Reference != "" {
+		txn.Reference = &req.Reference
+	}
+	if req.Description != "" {
+		txn.Description = &req.Description
+	}
+
+	if err := s.agencyRepo.PostTransactions(ctx, agencyID, []*models.AgencyTransaction{txn}, false); err != nil {
+		return nil, fmt.Errorf("failed to record agency transaction: %w", err)
+	}
+
+	return txn, nil
+}
+
+func (s *agencyService) ListTransactions(ctx context.Context, agencyID uuid.UUID, start, end time.Time) ([]models.AgencyTransaction, error) {
+	return s.agencyRepo.ListTransactions(ctx, agencyID, start, end)
+}
+
+// GetStatement builds an agency's commission statement for a month given as YYYY-MM
+func (s *agencyService) GetStatement(ctx context.Context, agencyID uuid.UUID, month string) (*models.AgencyStatement, error) {
+	start, end, err := agency.MonthBounds(month)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := s.agencyRepo.GetByID(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	opening, err := s.agencyRepo.GetBalanceAt(ctx, agencyID, start)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, err := s.agencyRepo.ListTransactions(ctx, agencyID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return agency.Statement(a, start, end, opening, txns), nil
+}
+
+// validateCreditLimit checks an agency's limit suits its account type
+func validateCreditLimit(a *models.Agency) error {
+	switch {
+	case a.CreditLimit.IsNegative():
+		return fmt.Errorf("credit limit cannot be negative")
+	case a.AccountType == "prepaid" && a.CreditLimit.IsPositive():
+		return fmt.Errorf("prepaid agencies cannot have a credit limit")
+	case a.DefaultCommissionRate < 0 || a.DefaultCommissionRate > 1:
+		return fmt.Errorf("commission rate must be between 0 and 1")
+	}
+	return nil
+}
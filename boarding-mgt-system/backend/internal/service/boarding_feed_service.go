@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/boardingfeed"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type BoardingFeedService interface {
+	ScheduleCounts(ctx context.Context, scheduleID uuid.UUID) (*models.BoardingCounts, error)
+	PortCounts(ctx context.Context, portID uuid.UUID, operatorID *uuid.UUID) ([]*models.BoardingCounts, error)
+	Subscribe(filter boardingfeed.Filter) *boardingfeed.Subscription
+	PublishScan(ctx context.Context, scan *models.BoardingScan, boarded bool)
+}
+
+type boardingFeedService struct {
+	boardingRepo repository.BoardingRepository
+	portRepo     repository.PortRepository
+	broker       boardingfeed.Broker
+}
+
+func NewBoardingFeedService(
+	boardingRepo repository.BoardingRepository,
+	portRepo repository.PortRepository,
+	broker boardingfeed.Broker,
+) BoardingFeedService {
+	return &boardingFeedService{
+		boardingRepo: boardingRepo,
+		portRepo:     portRepo,
+		broker:       broker,
+	}
+}
+
+func (s *boardingFeedService) ScheduleCounts(ctx context.Context, scheduleID uuid.UUID) (*models.BoardingCounts, error) {
+	return s.boardingRepo.GetBoardingCounts(ctx, scheduleID)
+}
+
+// PortCounts gets the counts of today's sailings from a port, today being the
+// port's local date
+func (s *boardingFeedService) PortCounts(ctx context.Context, portID uuid.UUID, operatorID *uuid.UUID) ([]*models.BoardingCounts, error) {
+	port, err := s.portRepo.GetByID(ctx, portID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now()
+	if location, err := time.LoadLocation(port.Timezone); err == nil {
+		today = today.In(location)
+	}
+	date := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	return s.boardingRepo.ListPortBoardingCounts(ctx, port.ID, operatorID, date)
+}
+
+func (s *boardingFeedService) Subscribe(filter boardingfeed.Filter) *boardingfeed.Subscription {
+	return s.broker.Subscribe(filter)
+}
+
+// PublishScan pushes a gate scan, its sailing's new counts and any alerts the
+// scan raised to dashboards. boarded reports whether the scan put its
+// passenger aboard. Failures are logged; the scan has already been made.
+func (s *boardingFeedService) PublishScan(ctx context.Context, scan *models.BoardingScan, boarded bool) {
+	counts, err := s.boardingRepo.GetBoardingCounts(ctx, scan.ScheduleID)
+	if err != nil {
+		fmt.Printf("failed to get boarding counts: %v\n", err)
+		return
+	}
+
+	now := time.Now()
+	events := []*models.BoardingEvent{
+		boardingfeed.ScanEvent(counts, scan, now),
+		boardingfeed.CountsEvent(counts, now),
+	}
+
+	before := *counts
+	if boarded {
+		before.Boarded--
+	}
+	for _, alert := range boardingfeed.Alerts(before, *counts) {
+		events = append(events, boardingfeed.AlertEvent(counts, alert, now))
+	}
+
+	for _, event := range events {
+		if err := s.broker.Publish(ctx, event); err != nil {
+			fmt.Printf("failed to publish boarding event: %v\n", err)
+		}
+	}
+}
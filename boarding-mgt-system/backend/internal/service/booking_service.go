@@ -8,16 +8,29 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ferryflow/boarding-mgt-system/internal/agency"
+	"github.com/ferryflow/boarding-mgt-system/internal/gate"
 	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
 	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/tender"
+	"github.com/ferryflow/boarding-mgt-system/internal/ticketqr"
 	"github.com/google/uuid"
 )
 
 type BookingService interface {
 	CreateBooking(ctx context.Context, customerID uuid.UUID, req *models.CreateBookingRequest) (*models.Booking, error)
+	CreatePOSBooking(ctx context.Context, agentID uuid.UUID, req *models.CreatePOSBookingRequest) (*models.Booking, error)
+	CreateAgencyBooking(ctx context.Context, agentID uuid.UUID, req *models.CreateAgencyBookingRequest) (*models.Booking, error)
 	GetBooking(ctx context.Context, id uuid.UUID) (*models.Booking, error)
 	GetBookingByReference(ctx context.Context, reference string) (*models.Booking, error)
 	CancelBooking(ctx context.Context, id uuid.UUID, reason string) error
+	RefundCancelledBooking(ctx context.Context, id uuid.UUID, reason string) error
+	ReissueTicketCodes(tickets []*models.Ticket, schedule *models.Schedule) (map[uuid.UUID]string, error)
+	RefundPOSBooking(ctx context.Context, operatorID, agentID, id uuid.UUID, reason string) ([]*models.Refund, error)
+	ProcessRefund(ctx context.Context, operatorID, processedBy, id uuid.UUID, req *models.ProcessRefundRequest) (*models.Refund, error)
+	AddPayment(ctx context.Context, id uuid.UUID, takenBy *uuid.UUID, req *models.PaymentTender) (*models.Booking, error)
+	GetBookingBalance(ctx context.Context, id uuid.UUID) (*models.BookingBalance, error)
 	ListBookings(ctx context.Context, filter *models.BookingFilter) ([]*models.Booking, int, error)
 	GetCustomerBookings(ctx context.Context, customerID uuid.UUID, limit int) ([]*models.Booking, error)
 	GetScheduleManifest(ctx context.Context, scheduleID uuid.UUID) (*models.Manifest, error)
@@ -30,31 +43,111 @@ type bookingService struct {
 	scheduleRepo repository.ScheduleRepository
 	ticketRepo   repository.TicketRepository
 	paymentRepo  repository.PaymentRepository
+	shiftRepo    repository.ShiftRepository
+	agencyRepo   repository.AgencyRepository
+	userRepo     repository.UserRepository
+
+	ledgerService  LedgerService
+	invoiceService InvoiceService
+
+	qrSigner *ticketqr.Signer
 }
 
+// ticketCodeGrace keeps ticket codes valid after the scheduled arrival so
+// delayed sailings can still be boarded
+const ticketCodeGrace = 6 * time.Hour
+
 func NewBookingService(
 	bookingRepo repository.BookingRepository,
 	scheduleRepo repository.ScheduleRepository,
 	ticketRepo repository.TicketRepository,
 	paymentRepo repository.PaymentRepository,
+	shiftRepo repository.ShiftRepository,
+	agencyRepo repository.AgencyRepository,
+	userRepo repository.UserRepository,
+	ledgerService LedgerService,
+	invoiceService InvoiceService,
+	qrSigner *ticketqr.Signer,
 ) BookingService {
 	return &bookingService{
-		bookingRepo:  bookingRepo,
-		scheduleRepo: scheduleRepo,
-		ticketRepo:   ticketRepo,
-		paymentRepo:  paymentRepo,
+		bookingRepo:    bookingRepo,
+		scheduleRepo:   scheduleRepo,
+		ticketRepo:     ticketRepo,
+		paymentRepo:    paymentRepo,
+		shiftRepo:      shiftRepo,
+		agencyRepo:     agencyRepo,
+		userRepo:       userRepo,
+		ledgerService:  ledgerService,
+		invoiceService: invoiceService,
+		qrSigner:       qrSigner,
 	}
 }
 
 func (s *bookingService) CreateBooking(ctx context.Context, customerID uuid.UUID, req *models.CreateBookingRequest) (*models.Booking, error) {
+	booking := &models.Booking{
+		CustomerID:     customerID,
+		BookingChannel: "online",
+	}
+
+	return s.createBooking(ctx, booking, req)
+}
+
+func (s *bookingService) CreatePOSBooking(ctx context.Context, agentID uuid.UUID, req *models.CreatePOSBookingRequest) (*models.Booking, error) {
+	// Counter sales must be taken against an open cash drawer
+	shift, err := s.shiftRepo.GetOpenByAgent(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("an open shift is required for POS sales: %w", err)
+	}
+
+	booking := &models.Booking{
+		CustomerID:     req.CustomerID,
+		BookingChannel: "pos",
+		BookingAgentID: &agentID,
+		ShiftID:        &shift.ID,
+	}
+
+	return s.createBooking(ctx, booking, &req.CreateBookingRequest)
+}
+
+// CreateAgencyBooking sells a booking through the agent's travel agency. The
+// fare is charged to the agency's account, less the agency's commission.
+func (s *bookingService) CreateAgencyBooking(ctx context.Context, agentID uuid.UUID, req *models.CreateAgencyBookingRequest) (*models.Booking, error) {
+	agent, err := s.userRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("agent not found: %w", err)
+	}
+
+	if agent.AgencyID == nil {
+		return nil, fmt.Errorf("agent does not belong to an agency")
+	}
+
+	booking := &models.Booking{
+		CustomerID:     req.CustomerID,
+		BookingChannel: "agent",
+		BookingAgentID: &agentID,
+		AgencyID:       agent.AgencyID,
+	}
+
+	return s.createBooking(ctx, booking, &models.CreateBookingRequest{
+		ScheduleID:          req.ScheduleID,
+		Passengers:          req.Passengers,
+		PaymentMethod:       "agency_account",
+		SpecialRequirements: req.SpecialRequirements,
+	})
+}
+
+// createBooking prices and persists a booking whose customer and channel
+// fields have already been filled in by the caller
+func (s *bookingService) createBooking(ctx context.Context, booking *models.Booking, req *models.CreateBookingRequest) (*models.Booking, error) {
 	// Get schedule
 	schedule, err := s.scheduleRepo.GetByID(ctx, req.ScheduleID)
 	if err != nil {
 		return nil, fmt.Errorf("schedule not found: %w", err)
 	}
 
-	// Check if schedule is available
-	if schedule.Status != "scheduled" {
+	// Check if schedule is available; sailings of unpublished timetables
+	// are not on sale
+	if schedule.Status != "scheduled" || schedule.Unpublished {
 		return nil, fmt.Errorf("schedule is not available for booking")
 	}
 
@@ -65,23 +158,49 @@ func (s *bookingService) CreateBooking(ctx context.Context, customerID uuid.UUID
 			passengerCount, schedule.AvailableSeats)
 	}
 
-	// Calculate total amount
-	totalAmount := float64(passengerCount) * schedule.BasePrice
+	// Calculate total amount from each passenger's fare so the charge
+	// matches the priced tickets
+	var totalAmount money.Money
+	for _, passenger := range req.Passengers {
+		totalAmount = totalAmount.Add(ticketPrice(schedule.BasePrice, passenger.Type))
+	}
+
+	// Without split tenders the booking is paid in full with one method
+	tenders := req.Payments
+	if len(tenders) == 0 && totalAmount.IsPositive() {
+		tenders = []models.PaymentTender{{PaymentMethod: req.PaymentMethod, Amount: totalAmount}}
+	}
+	if err := tender.CheckTenders(totalAmount, tenders); err != nil {
+		return nil, err
+	}
+	for _, t := range tenders {
+		if t.PaymentMethod == "cash" && booking.ShiftID == nil {
+			return nil, fmt.Errorf("cash payments must be taken against an open shift")
+		}
+		if t.PaymentMethod == "agency_account" && booking.AgencyID == nil {
+			return nil, fmt.Errorf("agency account payments are only available to agency bookings")
+		}
+	}
+
+	// Agency sales earn commission and must fit within the agency's credit
+	if booking.AgencyID != nil {
+		commission, err := s.agencyCommission(ctx, *booking.AgencyID, schedule, req.Passengers)
+		if err != nil {
+			return nil, err
+		}
+		booking.CommissionAmount = commission
+	}
 
 	// Generate booking reference
 	bookingRef := s.generateBookingReference()
 
 	// Create booking
-	booking := &models.Booking{
-		BookingReference:    bookingRef,
-		ScheduleID:          req.ScheduleID,
-		CustomerID:          customerID,
-		PassengerCount:      passengerCount,
-		TotalAmount:         totalAmount,
-		BookingStatus:       "pending",
-		PaymentStatus:       "pending",
-		BookingChannel:      "online",
-	}
+	booking.BookingReference = bookingRef
+	booking.ScheduleID = req.ScheduleID
+	booking.PassengerCount = passengerCount
+	booking.TotalAmount = totalAmount
+	booking.BookingStatus = "pending"
+	booking.PaymentStatus = "pending"
 
 	if req.SpecialRequirements != "" {
 		booking.SpecialRequirements = &req.SpecialRequirements
@@ -94,30 +213,26 @@ func (s *bookingService) CreateBooking(ctx context.Context, customerID uuid.UUID
 	// Create tickets for each passenger
 	tickets := make([]*models.Ticket, 0, passengerCount)
 	for _, passenger := range req.Passengers {
-		// Calculate ticket price based on passenger type
-		ticketPrice := schedule.BasePrice
-		switch passenger.Type {
-		case "child":
-			ticketPrice = schedule.BasePrice * 0.5
-		case "infant":
-			ticketPrice = 0
-		case "senior":
-			ticketPrice = schedule.BasePrice * 0.8
-		}
-
 		ticket := &models.Ticket{
+			ID:             uuid.New(),
 			BookingID:      booking.ID,
 			PassengerName:  passenger.Name,
 			PassengerType:  passenger.Type,
-			TicketPrice:    ticketPrice,
-			QRCode:         s.generateQRCode(booking.ID, passenger.Name),
-			CheckInStatus:  "pending",
+			TicketPrice:    ticketPrice(schedule.BasePrice, passenger.Type),
+			CheckInStatus:  gate.StatusNotCheckedIn,
+			Identity:       passenger.PassengerIdentity,
 		}
 
 		if passenger.SeatNumber != "" {
 			ticket.SeatNumber = &passenger.SeatNumber
 		}
 
+		qrCode, err := s.generateQRCode(ticket, schedule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign ticket code: %w", err)
+		}
+		ticket.QRCode = qrCode
+
 		tickets = append(tickets, ticket)
 	}
 
@@ -125,43 +240,176 @@ func (s *bookingService) CreateBooking(ctx context.Context, customerID uuid.UUID
 		return nil, fmt.Errorf("failed to create tickets: %w", err)
 	}
 
-	// Create payment record
-	payment := &models.Payment{
-		BookingID:     booking.ID,
-		PaymentMethod: req.PaymentMethod,
-		Amount:        totalAmount,
-		Currency:      "USD",
-		PaymentStatus: "pending",
+	// Attach related data
+	booking.Schedule = schedule
+	booking.Tickets = make([]models.Ticket, len(tickets))
+	for i, ticket := range tickets {
+		booking.Tickets[i] = *ticket
 	}
 
-	if err := s.paymentRepo.Create(ctx, payment); err != nil {
-		return nil, fmt.Errorf("failed to create payment record: %w", err)
+	// Post the sale to the ledger; payments are posted as they are taken
+	if err := s.ledgerService.PostBooking(ctx, booking); err != nil {
+		// Non-critical error, log but don't fail
+		fmt.Printf("failed to post booking to ledger: %v\n", err)
 	}
 
-	// TODO: Process payment through gateway
+	if err := s.takePayments(ctx, booking, tenders, booking.ShiftID); err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
 
-	// For now, simulate successful payment
-	booking.BookingStatus = "confirmed"
-	booking.PaymentStatus = "completed"
-	if err := s.bookingRepo.UpdateStatus(ctx, booking.ID, "confirmed", "completed"); err != nil {
-		return nil, fmt.Errorf("failed to update booking status: %w", err)
+// AddPayment takes a further tender against a booking's balance due. Staff
+// taking a payment have it recorded against their open shift.
+func (s *bookingService) AddPayment(ctx context.Context, id uuid.UUID, takenBy *uuid.UUID, req *models.PaymentTender) (*models.Booking, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("booking not found: %w", err)
 	}
 
-	// Update payment status
-	if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, "completed", nil); err != nil {
-		// Non-critical error, log but don't fail
-		fmt.Printf("failed to update payment status: %v\n", err)
+	if booking.BookingStatus == "cancelled" {
+		return nil, fmt.Errorf("booking is cancelled")
 	}
 
-	// Attach related data
-	booking.Schedule = schedule
-	booking.Tickets = make([]models.Ticket, len(tickets))
-	for i, ticket := range tickets {
-		booking.Tickets[i] = *ticket
+	payments, err := s.listPayments(ctx, booking)
+	if err != nil {
+		return nil, err
 	}
-	booking.Payment = payment
 
-	return booking, nil
+	balanceDue := tender.BalanceDue(booking.TotalAmount, payments)
+	if balanceDue.IsZero() {
+		return nil, fmt.Errorf("booking is already fully paid")
+	}
+
+	tenders := []models.PaymentTender{*req}
+	if err := tender.CheckTenders(balanceDue, tenders); err != nil {
+		return nil, err
+	}
+
+	var shiftID *uuid.UUID
+	if takenBy != nil {
+		if shift, err := s.shiftRepo.GetOpenByAgent(ctx, *takenBy); err == nil {
+			shiftID = &shift.ID
+		}
+	}
+	if req.PaymentMethod == "cash" && shiftID == nil {
+		return nil, fmt.Errorf("cash payments must be taken against an open shift")
+	}
+
+	if err := s.takePayments(ctx, booking, tenders, shiftID); err != nil {
+		return nil, err
+	}
+
+	return s.GetBooking(ctx, id)
+}
+
+func (s *bookingService) GetBookingBalance(ctx context.Context, id uuid.UUID) (*models.BookingBalance, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("booking not found: %w", err)
+	}
+
+	payments, err := s.listPayments(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BookingBalance{
+		BookingID:      booking.ID,
+		TotalAmount:    booking.TotalAmount,
+		AmountPaid:     tender.Paid(payments),
+		AmountRefunded: tender.Refunded(payments),
+		BalanceDue:     tender.BalanceDue(booking.TotalAmount, payments),
+		Payments:       payments,
+	}, nil
+}
+
+// takePayments records and processes tenders against a booking, then settles
+// the booking's payment status
+func (s *bookingService) takePayments(ctx context.Context, booking *models.Booking, tenders []models.PaymentTender, shiftID *uuid.UUID) error {
+	for _, t := range tenders {
+		payment := &models.Payment{
+			BookingID:     booking.ID,
+			PaymentMethod: t.PaymentMethod,
+			Amount:        t.Amount,
+			Currency:      t.Amount.Currency(),
+			PaymentStatus: "pending",
+			ShiftID:       shiftID,
+		}
+		if t.Reference != "" {
+			reference := t.Reference
+			payment.TenderReference = &reference
+		}
+
+		if err := s.paymentRepo.Create(ctx, payment); err != nil {
+			return fmt.Errorf("failed to create payment record: %w", err)
+		}
+
+		// Agency payments are drawn from the agency's account
+		if t.PaymentMethod == "agency_account" {
+			if err := s.chargeAgency(ctx, booking, payment); err != nil {
+				if updateErr := s.paymentRepo.UpdateStatus(ctx, payment.ID, "failed", nil); updateErr != nil {
+					fmt.Printf("failed to update payment status: %v\n", updateErr)
+				}
+				return err
+			}
+		}
+
+		// TODO: Process payment through gateway
+
+		// For now, simulate successful payment
+		if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, "completed", nil); err != nil {
+			// Non-critical error, log but don't fail
+			fmt.Printf("failed to update payment status: %v\n", err)
+			continue
+		}
+		payment.PaymentStatus = "completed"
+
+		if err := s.ledgerService.PostPayment(ctx, booking, payment); err != nil {
+			fmt.Printf("failed to post payment to ledger: %v\n", err)
+		}
+	}
+
+	return s.settle(ctx, booking)
+}
+
+// settle works out a booking's balance from its payments and confirms the
+// booking once it is fully paid
+func (s *bookingService) settle(ctx context.Context, booking *models.Booking) error {
+	payments, err := s.listPayments(ctx, booking)
+	if err != nil {
+		return err
+	}
+
+	booking.Payments = payments
+	booking.AmountPaid = tender.Paid(payments)
+	booking.BalanceDue = tender.BalanceDue(booking.TotalAmount, payments)
+
+	// The status is always written: the booking loaded before the payments
+	// were taken may no longer match what the database holds
+	bookingStatus, paymentStatus := booking.BookingStatus, "pending"
+	switch {
+	case booking.BalanceDue.IsZero():
+		bookingStatus, paymentStatus = "confirmed", "paid"
+	case booking.AmountPaid.IsPositive():
+		paymentStatus = "partially_paid"
+	}
+
+	if err := s.bookingRepo.UpdateStatus(ctx, booking.ID, bookingStatus, paymentStatus); err != nil {
+		return fmt.Errorf("failed to update booking status: %w", err)
+	}
+	booking.BookingStatus = bookingStatus
+	booking.PaymentStatus = paymentStatus
+
+	if paymentStatus == "paid" {
+		if _, err := s.invoiceService.IssueInvoice(ctx, booking); err != nil {
+			// Non-critical error, the invoice is issued on first download
+			fmt.Printf("failed to issue invoice: %v\n", err)
+		}
+	}
+
+	return nil
 }
 
 func (s *bookingService) GetBooking(ctx context.Context, id uuid.UUID) (*models.Booking, error) {
@@ -182,13 +430,15 @@ func (s *bookingService) GetBooking(ctx context.Context, id uuid.UUID) (*models.
 		}
 	}
 
-	// Get payment
-	payment, err := s.paymentRepo.GetByBooking(ctx, id)
+	// Get payments
+	payments, err := s.listPayments(ctx, booking)
 	if err != nil {
-		// Non-critical, continue without payment
-		fmt.Printf("failed to get payment: %v\n", err)
+		// Non-critical, continue without payments
+		fmt.Printf("failed to get payments: %v\n", err)
 	} else {
-		booking.Payment = payment
+		booking.Payments = payments
+		booking.AmountPaid = tender.Paid(payments)
+		booking.BalanceDue = tender.BalanceDue(booking.TotalAmount, payments)
 	}
 
 	return booking, nil
@@ -215,25 +465,234 @@ func (s *bookingService) CancelBooking(ctx context.Context, id uuid.UUID, reason
 		return fmt.Errorf("booking is already cancelled")
 	}
 
-	// Update booking status
-	if err := s.bookingRepo.UpdateStatus(ctx, id, "cancelled", "refund_pending"); err != nil {
+	return s.refundAndCancel(ctx, booking, "cancellation")
+}
+
+// RefundCancelledBooking refunds what was paid for a booking that was
+// cancelled without a refund, such as one on a cancelled sailing whose
+// passenger chose a refund
+func (s *bookingService) RefundCancelledBooking(ctx context.Context, id uuid.UUID, reason string) error {
+	booking, err := s.bookingRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("booking not found: %w", err)
+	}
+
+	if booking.BookingStatus != "cancelled" {
+		return fmt.Errorf("booking is not cancelled")
+	}
+	if booking.PaymentStatus == "refund_pending" || booking.PaymentStatus == "refunded" {
+		return fmt.Errorf("booking has already been refunded")
+	}
+
+	return s.refundAndCancel(ctx, booking, reason)
+}
+
+// refundAndCancel cancels a booking and refunds everything paid for it
+func (s *bookingService) refundAndCancel(ctx context.Context, booking *models.Booking, reason string) error {
+	id := booking.ID
+
+	payments, err := s.listPayments(ctx, booking)
+	if err != nil {
+		return err
+	}
+	paid := tender.Paid(payments)
+
+	if paid.IsZero() {
+		if err := s.bookingRepo.UpdateStatus(ctx, id, "cancelled", booking.PaymentStatus); err != nil {
+			return fmt.Errorf("failed to cancel booking: %w", err)
+		}
+		return nil
+	}
+
+	// Refund everything paid, spread back over the tenders that paid it
+	allocations, err := tender.AllocateRefund(payments, paid)
+	if err != nil {
+		return err
+	}
+
+	// Agency account refunds are credited back straight away; everything
+	// else stays pending until the gateway pays it out
+	paidOut := refundedNow(allocations, "agency_account")
+
+	paymentStatus := "refund_pending"
+	if paidOut.Cmp(paid) == 0 {
+		paymentStatus = "refunded"
+	}
+	if err := s.bookingRepo.UpdateStatus(ctx, id, "cancelled", paymentStatus); err != nil {
 		return fmt.Errorf("failed to cancel booking: %w", err)
 	}
 
-	// Process refund if payment was completed
-	if booking.PaymentStatus == "completed" {
-		payment, err := s.paymentRepo.GetByBooking(ctx, id)
-		if err == nil && payment != nil {
-			if err := s.paymentRepo.ProcessRefund(ctx, payment.ID, payment.Amount); err != nil {
-				// Non-critical error, log but don't fail
-				fmt.Printf("failed to process refund: %v\n", err)
-			}
+	now := time.Now()
+	for _, allocation := range allocations {
+		refund := &models.Refund{
+			BookingID:    id,
+			PaymentID:    allocation.Payment.ID,
+			RefundAmount: allocation.Amount,
+			RefundReason: reason,
+			RefundStatus: "pending",
+		}
+		if allocation.Payment.PaymentMethod == "agency_account" {
+			refund.RefundStatus = "processed"
+			refund.ProcessedAt = &now
 		}
+		if err := s.paymentRepo.CreateRefund(ctx, refund); err != nil {
+			// Non-critical error, log but don't fail
+			fmt.Printf("failed to process refund: %v\n", err)
+		}
+	}
+
+	if err := s.creditAgency(ctx, booking, allocations, nil); err != nil {
+		fmt.Printf("failed to credit agency: %v\n", err)
+	}
+
+	if err := s.ledgerService.PostRefund(ctx, booking, booking.ID, paid, paidOut); err != nil {
+		fmt.Printf("failed to post refund to ledger: %v\n", err)
+	}
+
+	// The credit note goes against the booking's invoice, if it was invoiced
+	if _, err := s.invoiceService.IssueCreditNote(ctx, booking, booking.ID, paid); err != nil {
+		fmt.Printf("failed to issue credit note: %v\n", err)
 	}
 
 	return nil
 }
 
+// RefundPOSBooking cancels and refunds a booking at the counter. Both the
+// agent's drawer and the booking's sailing must belong to operatorID.
+func (s *bookingService) RefundPOSBooking(ctx context.Context, operatorID, agentID, id uuid.UUID, reason string) ([]*models.Refund, error) {
+	// Cash refunds are paid out of the agent's open drawer
+	shift, err := s.shiftRepo.GetOpenByAgent(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("an open shift is required for POS refunds: %w", err)
+	}
+	if shift.OperatorID != operatorID {
+		return nil, fmt.Errorf("shift belongs to another operator")
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("booking not found: %w", err)
+	}
+
+	if booking.Schedule.OperatorID != operatorID {
+		return nil, fmt.Errorf("booking belongs to another operator")
+	}
+
+	if booking.BookingStatus == "cancelled" {
+		return nil, fmt.Errorf("booking is already cancelled")
+	}
+
+	payments, err := s.listPayments(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	paid := tender.Paid(payments)
+	if paid.IsZero() {
+		return nil, fmt.Errorf("booking has no completed payment to refund")
+	}
+
+	// Each tender gets back its share of the refund
+	allocations, err := tender.AllocateRefund(payments, paid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cash leaves the drawer and agency accounts are credited straight away;
+	// card and other tenders stay pending until the gateway pays them out
+	paidOut := refundedNow(allocations, "cash", "agency_account")
+
+	paymentStatus := "refund_pending"
+	if paidOut.Cmp(paid) == 0 {
+		paymentStatus = "refunded"
+	}
+	if err := s.bookingRepo.UpdateStatus(ctx, id, "cancelled", paymentStatus); err != nil {
+		return nil, fmt.Errorf("failed to cancel booking: %w", err)
+	}
+
+	now := time.Now()
+	refunds := make([]*models.Refund, 0, len(allocations))
+	for _, allocation := range allocations {
+		refund := &models.Refund{
+			BookingID:    id,
+			PaymentID:    allocation.Payment.ID,
+			RefundAmount: allocation.Amount,
+			RefundReason: reason,
+			RefundStatus: "pending",
+			ShiftID:      &shift.ID,
+		}
+		if method := allocation.Payment.PaymentMethod; method == "cash" || method == "agency_account" {
+			refund.RefundStatus = "processed"
+			refund.ProcessedBy = &agentID
+			refund.ProcessedAt = &now
+		}
+
+		if err := s.paymentRepo.CreateRefund(ctx, refund); err != nil {
+			return nil, fmt.Errorf("failed to record refund: %w", err)
+		}
+		refunds = append(refunds, refund)
+	}
+
+	if err := s.creditAgency(ctx, booking, allocations, &agentID); err != nil {
+		fmt.Printf("failed to credit agency: %v\n", err)
+	}
+
+	if err := s.ledgerService.PostRefund(ctx, booking, booking.ID, paid, paidOut); err != nil {
+		// Non-critical error, log but don't fail
+		fmt.Printf("failed to post refund to ledger: %v\n", err)
+	}
+
+	// The credit note goes against the booking's invoice, if it was invoiced
+	if _, err := s.invoiceService.IssueCreditNote(ctx, booking, booking.ID, paid); err != nil {
+		fmt.Printf("failed to issue credit note: %v\n", err)
+	}
+
+	return refunds, nil
+}
+
+// ProcessRefund records that a pending refund, such as one to a card, was
+// paid back and posts the payout to the ledger. The booking is marked
+// refunded once none of its refunds are pending.
+func (s *bookingService) ProcessRefund(ctx context.Context, operatorID, processedBy, id uuid.UUID, req *models.ProcessRefundRequest) (*models.Refund, error) {
+	refund, err := s.paymentRepo.GetRefund(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, refund.BookingID)
+	if err != nil {
+		return nil, fmt.Errorf("booking not found: %w", err)
+	}
+
+	if booking.Schedule.OperatorID != operatorID {
+		return nil, fmt.Errorf("refund belongs to another operator")
+	}
+
+	refund.ProcessedBy = &processedBy
+	refund.GatewayRefundID = req.GatewayRefundID
+	if err := s.paymentRepo.MarkRefundProcessed(ctx, refund); err != nil {
+		return nil, err
+	}
+
+	if err := s.ledgerService.PostRefundPayment(ctx, booking, refund); err != nil {
+		// Non-critical error, log but don't fail
+		fmt.Printf("failed to post refund payment to ledger: %v\n", err)
+	}
+
+	if booking.PaymentStatus == "refund_pending" {
+		pending, err := s.paymentRepo.HasPendingRefunds(ctx, booking.ID)
+		if err != nil {
+			fmt.Printf("failed to check pending refunds: %v\n", err)
+		} else if !pending {
+			if err := s.bookingRepo.UpdateStatus(ctx, booking.ID, booking.BookingStatus, "refunded"); err != nil {
+				fmt.Printf("failed to mark booking refunded: %v\n", err)
+			}
+		}
+	}
+
+	return refund, nil
+}
+
 func (s *bookingService) ListBookings(ctx context.Context, filter *models.BookingFilter) ([]*models.Booking, int, error) {
 	bookings, total, err := s.bookingRepo.List(ctx, filter)
 	if err != nil {
@@ -269,7 +728,7 @@ func (s *bookingService) CheckInTicket(ctx context.Context, qrCode string) error
 	}
 
 	// Check if already checked in
-	if ticket.CheckInStatus == "checked_in" {
+	if ticket.CheckInStatus != gate.StatusNotCheckedIn {
 		return fmt.Errorf("ticket already checked in")
 	}
 
@@ -314,9 +773,186 @@ func (s *bookingService) generateBookingReference() string {
 	return fmt.Sprintf("FF%s", ref) // FF prefix for FerryFlow
 }
 
-func (s *bookingService) generateQRCode(bookingID uuid.UUID, passengerName string) string {
-	// Generate unique QR code for ticket
-	data := fmt.Sprintf("%s:%s:%d", bookingID.String(), passengerName, time.Now().Unix())
-	hash := base64.URLEncoding.EncodeToString([]byte(data))
-	return strings.ReplaceAll(hash, "=", "")
-}
\ No newline at end of file
+// ReissueTicketCodes signs new QR codes for tickets on a sailing, for when
+// they are moved onto it or it is delayed past their codes' validity. The
+// codes carry the seat numbers the tickets are given.
+func (s *bookingService) ReissueTicketCodes(tickets []*models.Ticket, schedule *models.Schedule) (map[uuid.UUID]string, error) {
+	codes := make(map[uuid.UUID]string, len(tickets))
+	for _, ticket := range tickets {
+		code, err := s.generateQRCode(ticket, schedule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign ticket code: %w", err)
+		}
+		codes[ticket.ID] = code
+	}
+
+	return codes, nil
+}
+
+// generateQRCode signs a ticket's QR code. The code is valid from issue until
+// after the sailing arrives, so gates can accept it without the database.
+func (s *bookingService) generateQRCode(ticket *models.Ticket, schedule *models.Schedule) (string, error) {
+	claims := ticketqr.Claims{
+		TicketID:      ticket.ID,
+		ScheduleID:    schedule.ID,
+		PassengerType: ticket.PassengerType,
+		NotBefore:     time.Now().Truncate(time.Second),
+		NotAfter:      schedule.ExpectedArrivalAt().Add(ticketCodeGrace),
+	}
+	if ticket.SeatNumber != nil {
+		claims.SeatNumber = *ticket.SeatNumber
+	}
+
+	return s.qrSigner.Sign(claims)
+}
+
+// agencyCommission works out an agency's commission on a booking's fares and
+// checks the agency can pay for the booking net of it
+func (s *bookingService) agencyCommission(ctx context.Context, agencyID uuid.UUID, schedule *models.Schedule, passengers []models.PassengerInfo) (money.Money, error) {
+	a, err := s.agencyRepo.GetByID(ctx, agencyID)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	if a.OperatorID != schedule.OperatorID {
+		return money.Money{}, fmt.Errorf("agency %s does not sell this operator's sailings", a.Code)
+	}
+
+	rules, err := s.agencyRepo.ListCommissionRules(ctx, agencyID)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	var total, commission money.Money
+	for _, passenger := range passengers {
+		fare := ticketPrice(schedule.BasePrice, passenger.Type)
+		rate := agency.CommissionRate(a, rules, schedule.RouteID, passenger.Type)
+		total = total.Add(fare)
+		commission = commission.Add(agency.Commission(fare, rate))
+	}
+
+	if err := agency.CheckCredit(a, total.Sub(commission)); err != nil {
+		return money.Money{}, err
+	}
+
+	return commission, nil
+}
+
+// chargeAgency draws an agency account payment from the agency and credits
+// the booking's commission, in one step so the limit is checked on the net
+func (s *bookingService) chargeAgency(ctx context.Context, booking *models.Booking, payment *models.Payment) error {
+	if booking.AgencyID == nil {
+		return fmt.Errorf("agency account payments are only available to agency bookings")
+	}
+
+	description := fmt.Sprintf("Booking %s", booking.BookingReference)
+	txns := []*models.AgencyTransaction{{
+		BookingID:       &booking.ID,
+		TransactionType: "booking_charge",
+		Amount:          payment.Amount.Neg(),
+		Description:     &description,
+		CreatedBy:       booking.BookingAgentID,
+	}}
+
+	// Commission is earned on the share of the booking paid from the account
+	commission := booking.CommissionAmount.Prorate(payment.Amount, booking.TotalAmount, money.HalfUp)
+	if commission.IsPositive() {
+		commissionDescription := fmt.Sprintf("Commission on booking %s", booking.BookingReference)
+		txns = append(txns, &models.AgencyTransaction{
+			BookingID:       &booking.ID,
+			TransactionType: "commission",
+			Amount:          commission,
+			Description:     &commissionDescription,
+			CreatedBy:       booking.BookingAgentID,
+		})
+	}
+
+	if err := s.agencyRepo.PostTransactions(ctx, *booking.AgencyID, txns, true); err != nil {
+		return fmt.Errorf("failed to charge agency: %w", err)
+	}
+
+	return nil
+}
+
+// creditAgency gives refunds of agency account payments back to the agency,
+// less the commission it earned on them
+func (s *bookingService) creditAgency(ctx context.Context, booking *models.Booking, allocations []tender.Allocation, createdBy *uuid.UUID) error {
+	var refunded money.Money
+	for _, allocation := range allocations {
+		if allocation.Payment.PaymentMethod == "agency_account" {
+			refunded = refunded.Add(allocation.Amount)
+		}
+	}
+	if refunded.IsZero() {
+		return nil
+	}
+
+	if booking.AgencyID == nil {
+		return fmt.Errorf("booking %s has agency payments but no agency", booking.BookingReference)
+	}
+
+	description := fmt.Sprintf("Refund of booking %s", booking.BookingReference)
+	txns := []*models.AgencyTransaction{{
+		BookingID:       &booking.ID,
+		TransactionType: "refund",
+		Amount:          refunded,
+		Description:     &description,
+		CreatedBy:       createdBy,
+	}}
+
+	commission := booking.CommissionAmount.Prorate(refunded, booking.TotalAmount, money.HalfUp)
+	if commission.IsPositive() {
+		reversalDescription := fmt.Sprintf("Commission reversed on booking %s", booking.BookingReference)
+		txns = append(txns, &models.AgencyTransaction{
+			BookingID:       &booking.ID,
+			TransactionType: "commission_reversal",
+			Amount:          commission.Neg(),
+			Description:     &reversalDescription,
+			CreatedBy:       createdBy,
+		})
+	}
+
+	return s.agencyRepo.PostTransactions(ctx, *booking.AgencyID, txns, false)
+}
+
+// listPayments returns a booking's payments, refusing any taken in another
+// currency than the booking total, which could not be added up against it
+func (s *bookingService) listPayments(ctx context.Context, booking *models.Booking) ([]models.Payment, error) {
+	payments, err := s.paymentRepo.ListByBooking(ctx, booking.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments: %w", err)
+	}
+	if err := tender.CheckCurrency(booking.TotalAmount, payments); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// refundedNow totals the refund allocations to tenders paid back straight
+// away by one of methods
+func refundedNow(allocations []tender.Allocation, methods ...string) money.Money {
+	var total money.Money
+	for _, allocation := range allocations {
+		for _, method := range methods {
+			if allocation.Payment.PaymentMethod == method {
+				total = total.Add(allocation.Amount)
+			}
+		}
+	}
+	return total
+}
+
+// ticketPrice returns the fare for a passenger type. Discounted fares are
+// rounded half up to the cent.
+func ticketPrice(basePrice money.Money, passengerType string) money.Money {
+	switch passengerType {
+	case "child":
+		return basePrice.Mul(0.5, money.HalfUp)
+	case "infant":
+		return money.Zero(basePrice.Currency())
+	case "senior":
+		return basePrice.Mul(0.8, money.HalfUp)
+	default:
+		return basePrice
+	}
+}
@@ -0,0 +1,579 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/disruption"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/seating"
+	"github.com/google/uuid"
+)
+
+type DisruptionService interface {
+	RecordDisruption(ctx context.Context, schedule *models.Schedule, req *models.CreateDisruptionRequest, recordedBy *uuid.UUID) (*models.ScheduleDisruption, error)
+	ChangeVessel(ctx context.Context, schedule *models.Schedule, req *models.ChangeVesselRequest, changedBy *uuid.UUID) (*models.VesselChangeResult, error)
+	ListDisruptions(ctx context.Context, scheduleID uuid.UUID) ([]*models.ScheduleDisruption, error)
+	MoveBookings(ctx context.Context, schedule *models.Schedule, req *models.MoveBookingsRequest, movedBy *uuid.UUID) (*models.MoveBookingsResult, error)
+	GetOnTimeReport(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) (*models.OnTimeReport, error)
+}
+
+type disruptionService struct {
+	disruptionRepo repository.DisruptionRepository
+	scheduleRepo   repository.ScheduleRepository
+	vesselRepo     repository.VesselRepository
+	operatorRepo   repository.OperatorRepository
+
+	bookingService      BookingService
+	notificationService NotificationService
+	walletService       WalletService
+}
+
+func NewDisruptionService(
+	disruptionRepo repository.DisruptionRepository,
+	scheduleRepo repository.ScheduleRepository,
+	vesselRepo repository.VesselRepository,
+	operatorRepo repository.OperatorRepository,
+	bookingService BookingService,
+	notificationService NotificationService,
+	walletService WalletService,
+) DisruptionService {
+	return &disruptionService{
+		disruptionRepo:      disruptionRepo,
+		scheduleRepo:        scheduleRepo,
+		vesselRepo:          vesselRepo,
+		operatorRepo:        operatorRepo,
+		bookingService:      bookingService,
+		notificationService: notificationService,
+		walletService:       walletService,
+	}
+}
+
+// RecordDisruption records a delay or gate change against a sailing that has
+// not departed, shows the new times or gate to its passengers and notifies
+// them. Ticket codes that would run out before a delayed sailing arrives are
+// reissued.
+func (s *disruptionService) RecordDisruption(ctx context.Context, schedule *models.Schedule, req *models.CreateDisruptionRequest, recordedBy *uuid.UUID) (*models.ScheduleDisruption, error) {
+	if !disruption.ValidReason(req.ReasonCode) {
+		return nil, fmt.Errorf("unknown reason code %q, expected one of %s", req.ReasonCode, strings.Join(disruption.Reasons, ", "))
+	}
+	if schedule.Status != "scheduled" && schedule.Status != "boarding" {
+		return nil, fmt.Errorf("only sailings that have not departed or been cancelled can be disrupted")
+	}
+
+	d := &models.ScheduleDisruption{
+		ScheduleID:           schedule.ID,
+		Kind:                 req.Kind,
+		ReasonCode:           req.ReasonCode,
+		Description:          req.Description,
+		ScheduledDepartureAt: schedule.DepartureAt,
+		ScheduledArrivalAt:   schedule.ArrivalAt,
+		DelayMinutes:         disruption.DelayMinutes(schedule.DepartureAt, schedule.ExpectedDepartureAt()),
+		RecordedBy:           recordedBy,
+	}
+	if req.Gate != nil {
+		gate := strings.TrimSpace(*req.Gate)
+		if gate == "" {
+			return nil, fmt.Errorf("gate must not be empty")
+		}
+		d.Gate = &gate
+	}
+
+	delayed := *schedule
+	switch req.Kind {
+	case disruption.KindDelay:
+		if req.EstimatedDepartureAt == nil {
+			return nil, fmt.Errorf("a delay needs the estimated departure")
+		}
+		departure, arrival, err := disruption.Estimate(schedule.DepartureAt, schedule.ArrivalAt, *req.EstimatedDepartureAt, req.EstimatedArrivalAt)
+		if err != nil {
+			return nil, err
+		}
+		d.EstimatedDepartureAt, d.EstimatedArrivalAt = &departure, &arrival
+		d.DelayMinutes = disruption.DelayMinutes(schedule.DepartureAt, departure)
+		delayed.EstimatedDepartureAt, delayed.EstimatedArrivalAt = &departure, &arrival
+	case disruption.KindGateChange:
+		if d.Gate == nil {
+			return nil, fmt.Errorf("a gate change needs the new gate")
+		}
+		if req.EstimatedDepartureAt != nil || req.EstimatedArrivalAt != nil {
+			return nil, fmt.Errorf("a gate change cannot change the sailing's times; record a delay")
+		}
+	}
+
+	bookings, err := s.disruptionRepo.ListConfirmedBookings(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Codes stop working ticketCodeGrace after the arrival they were signed
+	// for; once a delay takes the sailing past that, passengers get new ones
+	var codes map[uuid.UUID]string
+	arrival := delayed.ExpectedArrivalAt()
+	if arrival.After(schedule.ExpectedArrivalAt()) && arrival.After(schedule.ArrivalAt.Add(ticketCodeGrace)) {
+		var tickets []*models.Ticket
+		for _, booking := range bookings {
+			for i := range booking.Tickets {
+				tickets = append(tickets, &booking.Tickets[i])
+			}
+		}
+		codes, err = s.bookingService.ReissueTicketCodes(tickets, &delayed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.disruptionRepo.Record(ctx, d, schedule, codes); err != nil {
+		return nil, err
+	}
+	d.TicketsReissued = len(codes)
+
+	// Notices show the new times at the ports
+	if updated, err := s.scheduleRepo.GetByID(ctx, schedule.ID); err == nil {
+		delayed = *updated
+	}
+
+	for _, booking := range bookings {
+		if err := s.notificationService.Notify(ctx, disruptionNotice(&delayed, d, booking, len(codes) > 0)); err != nil {
+			// Non-critical error, log but don't fail
+			fmt.Printf("failed to notify customer: %v\n", err)
+			continue
+		}
+		d.Notified++
+	}
+
+	notice := disruptionSummary(&delayed, d)
+	update := &models.WalletPassUpdateRequest{Gate: d.Gate, Notice: &notice}
+	if _, err := s.walletService.UpdateSchedulePasses(ctx, schedule.ID, update); err != nil {
+		// Non-critical error, log but don't fail
+		fmt.Printf("failed to update wallet passes: %v\n", err)
+	}
+
+	return d, nil
+}
+
+// ChangeVessel substitutes another of the operator's vessels on a sailing
+// that has not departed and sizes the sailing to it. A vessel with fewer
+// seats than are booked is rejected unless the request overrides capacity.
+// Seats are carried over to the new vessel's seat map where it has them,
+// otherwise to the first free seat in the same class; passengers whose seats
+// changed get new ticket codes and are reported. Every customer is notified.
+func (s *disruptionService) ChangeVessel(ctx context.Context, schedule *models.Schedule, req *models.ChangeVesselRequest, changedBy *uuid.UUID) (*models.VesselChangeResult, error) {
+	if !disruption.ValidReason(req.ReasonCode) {
+		return nil, fmt.Errorf("unknown reason code %q, expected one of %s", req.ReasonCode, strings.Join(disruption.Reasons, ", "))
+	}
+	if schedule.Status != "scheduled" && schedule.Status != "boarding" {
+		return nil, fmt.Errorf("only sailings that have not departed or been cancelled can change vessel")
+	}
+	if req.VesselID == schedule.VesselID {
+		return nil, fmt.Errorf("the sailing is already operated by this vessel")
+	}
+
+	vessel, err := s.vesselRepo.GetByID(ctx, req.VesselID)
+	if err != nil {
+		return nil, err
+	}
+	if vessel.OperatorID != schedule.OperatorID {
+		return nil, fmt.Errorf("vessel does not belong to the operator")
+	}
+	if !vessel.IsActive {
+		return nil, fmt.Errorf("vessel is not active")
+	}
+
+	var from *seating.Layout
+	if schedule.Vessel != nil {
+		from = seating.LayoutFromConfiguration(schedule.Vessel.SeatConfiguration)
+	}
+	layout := seating.LayoutFromConfiguration(vessel.SeatConfiguration)
+
+	bookings, err := s.disruptionRepo.ListConfirmedBookings(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []*models.Ticket
+	for _, booking := range bookings {
+		for i := range booking.Tickets {
+			tickets = append(tickets, &booking.Tickets[i])
+		}
+	}
+
+	current := make([]*string, len(tickets))
+	for i, ticket := range tickets {
+		current[i] = ticket.SeatNumber
+	}
+	remapped := seating.Remap(from, layout, current)
+
+	substituted := *schedule
+	substituted.VesselID = vessel.ID
+
+	result := &models.VesselChangeResult{SeatChanges: []models.SeatChange{}}
+	changedBookings := map[uuid.UUID][]models.SeatChange{}
+	seats := map[uuid.UUID]*string{}
+	var reseated []*models.Ticket
+	references := make(map[uuid.UUID]string, len(bookings))
+	for _, booking := range bookings {
+		references[booking.ID] = booking.BookingReference
+		result.Passengers += booking.PassengerCount
+	}
+	for i, ticket := range tickets {
+		if !seating.Changed(current[i], remapped[i]) {
+			continue
+		}
+		change := models.SeatChange{
+			TicketID: ticket.ID, BookingReference: references[ticket.BookingID],
+			PassengerName: ticket.PassengerName, From: current[i], To: remapped[i],
+		}
+		result.SeatChanges = append(result.SeatChanges, change)
+		changedBookings[ticket.BookingID] = append(changedBookings[ticket.BookingID], change)
+
+		moved := *ticket
+		moved.SeatNumber = remapped[i]
+		reseated = append(reseated, &moved)
+		seats[moved.ID] = remapped[i]
+	}
+
+	// Ticket codes carry the seat number
+	codes, err := s.bookingService.ReissueTicketCodes(reseated, &substituted)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := vessel.Capacity
+	d := &models.ScheduleDisruption{
+		ScheduleID:           schedule.ID,
+		Kind:                 disruption.KindVesselChange,
+		ReasonCode:           req.ReasonCode,
+		Description:          req.Description,
+		ScheduledDepartureAt: schedule.DepartureAt,
+		ScheduledArrivalAt:   schedule.ArrivalAt,
+		DelayMinutes:         disruption.DelayMinutes(schedule.DepartureAt, schedule.ExpectedDepartureAt()),
+		VesselID:             &vessel.ID,
+		Capacity:             &capacity,
+		CapacityOverride:     req.OverrideCapacity,
+		RecordedBy:           changedBy,
+	}
+
+	held, err := s.disruptionRepo.ChangeVessel(ctx, d, schedule, seats, codes)
+	if err != nil {
+		return nil, err
+	}
+	d.TicketsReissued = len(codes)
+
+	if updated, err := s.scheduleRepo.GetByID(ctx, schedule.ID); err == nil {
+		substituted = *updated
+	} else {
+		substituted.Vessel = vessel
+		substituted.TotalCapacity = capacity
+		substituted.AvailableSeats = capacity - held
+	}
+
+	for _, booking := range bookings {
+		notification := disruptionNotice(&substituted, d, booking, len(changedBookings[booking.ID]) > 0)
+		for _, change := range changedBookings[booking.ID] {
+			notification.Body += " " + seatChangeNotice(change)
+		}
+		if err := s.notificationService.Notify(ctx, notification); err != nil {
+			// Non-critical error, log but don't fail
+			fmt.Printf("failed to notify customer: %v\n", err)
+			continue
+		}
+		d.Notified++
+	}
+
+	notice := disruptionSummary(&substituted, d)
+	if _, err := s.walletService.UpdateSchedulePasses(ctx, schedule.ID, &models.WalletPassUpdateRequest{Notice: &notice}); err != nil {
+		// Non-critical error, log but don't fail
+		fmt.Printf("failed to update wallet passes: %v\n", err)
+	}
+
+	result.Disruption = d
+	result.Schedule = &substituted
+	result.TicketsReissued = len(codes)
+	if held > capacity {
+		result.Oversold = held - capacity
+	}
+
+	return result, nil
+}
+
+func (s *disruptionService) ListDisruptions(ctx context.Context, scheduleID uuid.UUID) ([]*models.ScheduleDisruption, error) {
+	return s.disruptionRepo.ListBySchedule(ctx, scheduleID)
+}
+
+// MoveBookings moves confirmed bookings from a sailing to another sailing of
+// the same route, booking by booking while the new sailing has room.
+// Passengers keep their seat numbers where they are free, otherwise get the
+// next free seat in the same class; a booking whose class is full stays
+// where it is. Each moved customer gets new ticket codes and a notification.
+func (s *disruptionService) MoveBookings(ctx context.Context, schedule *models.Schedule, req *models.MoveBookingsRequest, movedBy *uuid.UUID) (*models.MoveBookingsResult, error) {
+	if schedule.Status == "departed" || schedule.Status == "arrived" {
+		return nil, fmt.Errorf("passengers cannot be moved off a sailing that has departed")
+	}
+
+	target, err := s.scheduleRepo.GetByID(ctx, req.TargetScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if target.ID == schedule.ID {
+		return nil, fmt.Errorf("passengers must be moved to another sailing")
+	}
+	if target.RouteID != schedule.RouteID || target.OperatorID != schedule.OperatorID {
+		return nil, fmt.Errorf("passengers can only be moved to a sailing of the same route")
+	}
+	if target.Status != "scheduled" || target.Unpublished || !target.ExpectedDepartureAt().After(time.Now()) {
+		return nil, fmt.Errorf("the sailing to move passengers to is not open for booking")
+	}
+
+	if req.DisruptionID != nil {
+		d, err := s.disruptionRepo.GetByID(ctx, *req.DisruptionID)
+		if err != nil {
+			return nil, err
+		}
+		if d.ScheduleID != schedule.ID {
+			return nil, fmt.Errorf("the disruption was not recorded against this sailing")
+		}
+	}
+
+	vessel, err := s.vesselRepo.GetByID(ctx, target.VesselID)
+	if err != nil {
+		return nil, err
+	}
+	layout := seating.LayoutFromConfiguration(vessel.SeatConfiguration)
+
+	taken, err := s.disruptionRepo.ListTakenSeats(ctx, target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.disruptionRepo.ListConfirmedBookings(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.MoveBookingsResult{
+		FromScheduleID: schedule.ID,
+		ToScheduleID:   target.ID,
+		Moved:          []*models.BookingMove{},
+		NotMoved:       []models.BookingNotMoved{},
+	}
+
+	selected := bookings
+	if len(req.BookingIDs) > 0 {
+		byID := make(map[uuid.UUID]*models.Booking, len(bookings))
+		for _, booking := range bookings {
+			byID[booking.ID] = booking
+		}
+		selected = nil
+		for _, id := range req.BookingIDs {
+			booking, ok := byID[id]
+			if !ok {
+				result.NotMoved = append(result.NotMoved, models.BookingNotMoved{
+					BookingID: id, Reason: "booking is not confirmed on this sailing",
+				})
+				continue
+			}
+			delete(byID, id)
+			selected = append(selected, booking)
+		}
+	}
+
+	available := target.AvailableSeats
+	for _, booking := range selected {
+		if booking.PassengerCount > available {
+			result.NotMoved = append(result.NotMoved, models.BookingNotMoved{
+				BookingID: booking.ID, Reason: "the sailing does not have enough seats",
+			})
+			continue
+		}
+
+		move, err := s.moveBooking(ctx, schedule, target, booking, layout, taken, req.DisruptionID, movedBy)
+		if err != nil {
+			result.NotMoved = append(result.NotMoved, models.BookingNotMoved{BookingID: booking.ID, Reason: err.Error()})
+			continue
+		}
+
+		available -= booking.PassengerCount
+		result.Moved = append(result.Moved, move)
+		result.Passengers += move.PassengerCount
+	}
+
+	return result, nil
+}
+
+// moveBooking seats a booking's passengers on the target sailing, moves it
+// there and notifies the customer
+func (s *disruptionService) moveBooking(ctx context.Context, from, target *models.Schedule, booking *models.Booking, layout *seating.Layout, taken map[string]bool, disruptionID, movedBy *uuid.UUID) (*models.BookingMove, error) {
+	current := make([]*string, len(booking.Tickets))
+	for i := range booking.Tickets {
+		current[i] = booking.Tickets[i].SeatNumber
+	}
+
+	assigned, err := seating.Assign(layout, current, taken)
+	if err != nil {
+		return nil, err
+	}
+
+	move := &models.BookingMove{
+		BookingID:      booking.ID,
+		FromScheduleID: from.ID,
+		ToScheduleID:   target.ID,
+		DisruptionID:   disruptionID,
+		PassengerCount: booking.PassengerCount,
+		MovedBy:        movedBy,
+	}
+
+	seats := make(map[uuid.UUID]*string, len(booking.Tickets))
+	tickets := make([]*models.Ticket, len(booking.Tickets))
+	for i := range booking.Tickets {
+		moved := booking.Tickets[i]
+		moved.SeatNumber = assigned[i]
+		tickets[i] = &moved
+		seats[moved.ID] = assigned[i]
+
+		if seating.Changed(current[i], assigned[i]) {
+			move.SeatChanges = append(move.SeatChanges, models.SeatChange{
+				TicketID: moved.ID, PassengerName: moved.PassengerName, From: current[i], To: assigned[i],
+			})
+		}
+	}
+	move.SeatsChanged = len(move.SeatChanges)
+
+	codes, err := s.bookingService.ReissueTicketCodes(tickets, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.disruptionRepo.MoveBooking(ctx, move, seats, codes); err != nil {
+		// The seats were never taken
+		for _, seat := range assigned {
+			if seat != nil {
+				delete(taken, *seat)
+			}
+		}
+		return nil, err
+	}
+
+	notification := &models.Notification{
+		UserID:  booking.CustomerID,
+		Kind:    "booking_moved",
+		Subject: fmt.Sprintf("Booking %s has moved to the sailing of %s", booking.BookingReference, expectedSailingTime(target)),
+		Body: fmt.Sprintf("Your booking %s has been moved from the sailing of %s to the sailing of %s and new tickets have been issued.",
+			booking.BookingReference, sailingTime(from), expectedSailingTime(target)),
+		Data: map[string]interface{}{
+			"booking_id":       booking.ID,
+			"from_schedule_id": from.ID,
+			"schedule_id":      target.ID,
+		},
+		DedupeKey: fmt.Sprintf("booking_move:%s", move.ID),
+	}
+	if move.SeatsChanged > 0 {
+		notification.Body += " Some of your seats have changed; please check your new boarding passes."
+	}
+	if err := s.notificationService.Notify(ctx, notification); err != nil {
+		// Non-critical error, log but don't fail
+		fmt.Printf("failed to notify customer: %v\n", err)
+	}
+
+	return move, nil
+}
+
+// GetOnTimeReport returns the on-time performance of an operator's sailings
+// departing between two dates, by route and by delay reason
+func (s *disruptionService) GetOnTimeReport(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) (*models.OnTimeReport, error) {
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+
+	operator, err := s.operatorRepo.GetByID(ctx, operatorID)
+	if err != nil {
+		return nil, fmt.Errorf("operator not found: %w", err)
+	}
+
+	sailings, err := s.disruptionRepo.ListOnTimeSailings(ctx, operatorID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return disruption.Summarize(sailings, disruption.PolicyFromSettings(operator.Settings), startDate, endDate), nil
+}
+
+// disruptionNotice tells a customer about a delay or gate change to their
+// sailing
+func disruptionNotice(schedule *models.Schedule, d *models.ScheduleDisruption, booking *models.Booking, reissued bool) *models.Notification {
+	subject := fmt.Sprintf("Your sailing of %s is delayed", sailingTime(schedule))
+	switch d.Kind {
+	case disruption.KindGateChange:
+		subject = fmt.Sprintf("Your sailing of %s now boards from gate %s", sailingTime(schedule), *d.Gate)
+	case disruption.KindVesselChange:
+		subject = fmt.Sprintf("Your sailing of %s has changed vessel", sailingTime(schedule))
+	}
+
+	body := fmt.Sprintf("Booking %s: %s", booking.BookingReference, disruptionSummary(schedule, d))
+	if d.Description != nil && *d.Description != "" {
+		body += " " + *d.Description
+	}
+	if reissued {
+		body += " New tickets have been issued; please use your updated boarding passes."
+	}
+
+	return &models.Notification{
+		UserID:  booking.CustomerID,
+		Kind:    "schedule_" + d.Kind,
+		Subject: subject,
+		Body:    body,
+		Data: map[string]interface{}{
+			"disruption_id": d.ID,
+			"booking_id":    booking.ID,
+			"schedule_id":   schedule.ID,
+			"reason_code":   d.ReasonCode,
+		},
+		DedupeKey: fmt.Sprintf("schedule_disruption:%s:%s", d.ID, booking.ID),
+	}
+}
+
+// disruptionSummary describes a disruption in a sentence or two
+func disruptionSummary(schedule *models.Schedule, d *models.ScheduleDisruption) string {
+	var summary string
+	switch d.Kind {
+	case disruption.KindDelay:
+		summary = fmt.Sprintf("The sailing is now expected to depart at %s, %d minutes late, because of %s.",
+			expectedSailingTime(schedule), d.DelayMinutes, strings.ReplaceAll(d.ReasonCode, "_", " "))
+	case disruption.KindVesselChange:
+		vessel := "another vessel"
+		if schedule.Vessel != nil {
+			vessel = schedule.Vessel.Name
+		}
+		summary = fmt.Sprintf("The sailing will now be operated by %s because of %s.",
+			vessel, strings.ReplaceAll(d.ReasonCode, "_", " "))
+	default:
+		summary = fmt.Sprintf("Boarding is now from gate %s.", *d.Gate)
+	}
+	if d.Kind == disruption.KindDelay && d.Gate != nil {
+		summary += fmt.Sprintf(" Boarding is now from gate %s.", *d.Gate)
+	}
+	return summary
+}
+
+// seatChangeNotice tells a passenger where they now sit
+func seatChangeNotice(change models.SeatChange) string {
+	if change.To == nil {
+		return fmt.Sprintf("%s no longer has seat %s; a seat will be given at boarding.", change.PassengerName, *change.From)
+	}
+	return fmt.Sprintf("%s has moved from seat %s to seat %s.", change.PassengerName, *change.From, *change.To)
+}
+
+// expectedSailingTime formats when a sailing is now expected to depart in
+// its departure port's time
+func expectedSailingTime(schedule *models.Schedule) string {
+	if schedule.EstimatedDepartureLocal != nil {
+		return schedule.EstimatedDepartureLocal.Format("Mon 2 Jan 2006 15:04")
+	}
+	if schedule.EstimatedDepartureAt != nil {
+		return schedule.EstimatedDepartureAt.Format("Mon 2 Jan 2006 15:04")
+	}
+	return sailingTime(schedule)
+}
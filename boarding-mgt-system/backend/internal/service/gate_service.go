@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/gate"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/ticketqr"
+	"github.com/google/uuid"
+)
+
+type GateService interface {
+	Scan(ctx context.Context, scannedBy uuid.UUID, action string, req *models.GateScanRequest) (*models.BoardingScan, error)
+	ListScans(ctx context.Context, filter *models.BoardingScanFilter) ([]*models.BoardingScan, int, error)
+}
+
+type gateService struct {
+	boardingRepo repository.BoardingRepository
+	feedService  BoardingFeedService
+
+	qrVerifier *ticketqr.Verifier
+}
+
+func NewGateService(
+	boardingRepo repository.BoardingRepository,
+	feedService BoardingFeedService,
+	qrKeys *ticketqr.Keyring,
+) GateService {
+	return &gateService{
+		boardingRepo: boardingRepo,
+		feedService:  feedService,
+		qrVerifier:   qrKeys.Verifier(ticketqr.DefaultLeeway),
+	}
+}
+
+// Scan checks in or boards the ticket a scanned code belongs to. Refused
+// scans are logged and returned with their deny reason rather than as errors.
+func (s *gateService) Scan(ctx context.Context, scannedBy uuid.UUID, action string, req *models.GateScanRequest) (*models.BoardingScan, error) {
+	if action != gate.ActionCheckIn && action != gate.ActionBoard {
+		return nil, fmt.Errorf("invalid scan action: %s", action)
+	}
+
+	scan := &models.BoardingScan{
+		ScheduleID: req.ScheduleID,
+		Action:     action,
+		Gate:       req.Gate,
+		DeviceID:   req.DeviceID,
+		ScannedBy:  &scannedBy,
+	}
+	if req.Override {
+		scan.OverrideReason = &req.OverrideReason
+	}
+
+	claims, err := s.qrVerifier.Verify(req.QRCode, time.Now())
+	if err != nil {
+		reason := gate.ReasonInvalidCode
+		scan.Result = gate.ResultDenied
+		scan.DenyReason = &reason
+		scan.OverrideReason = nil
+		if err := s.boardingRepo.RecordScan(ctx, scan); err != nil {
+			return nil, err
+		}
+		scan.Message = fmt.Sprintf("%s: %v", gate.Message(reason), err)
+		s.feedService.PublishScan(ctx, scan, false)
+		return scan, nil
+	}
+
+	scan.TicketID = &claims.TicketID
+	if err := s.boardingRepo.ApplyScan(ctx, scan, gate.DefaultPolicy, req.Override); err != nil {
+		return nil, err
+	}
+
+	switch scan.Result {
+	case gate.ResultAccepted:
+		scan.Message = "Checked in"
+		if action == gate.ActionBoard {
+			scan.Message = "Boarded"
+		}
+	case gate.ResultOverridden:
+		scan.Message = "Overridden: " + gate.Message(*scan.DenyReason)
+	default:
+		scan.Message = gate.Message(*scan.DenyReason)
+		scan.Overridable = gate.Overridable(*scan.DenyReason)
+	}
+
+	s.feedService.PublishScan(ctx, scan, scan.Result != gate.ResultDenied && action == gate.ActionBoard)
+
+	return scan, nil
+}
+
+func (s *gateService) ListScans(ctx context.Context, filter *models.BoardingScanFilter) ([]*models.BoardingScan, int, error) {
+	return s.boardingRepo.ListScans(ctx, filter)
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/gtfs"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/porttime"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/timetable"
+	"github.com/google/uuid"
+)
+
+type GTFSService interface {
+	Export(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) (*models.GTFSExport, error)
+	Import(ctx context.Context, req *models.GTFSImportRequest) (*models.GTFSImport, error)
+}
+
+type gtfsService struct {
+	gtfsRepo     repository.GTFSRepository
+	routeRepo    repository.RouteRepository
+	portRepo     repository.PortRepository
+	vesselRepo   repository.VesselRepository
+	operatorRepo repository.OperatorRepository
+}
+
+func NewGTFSService(
+	gtfsRepo repository.GTFSRepository,
+	routeRepo repository.RouteRepository,
+	portRepo repository.PortRepository,
+	vesselRepo repository.VesselRepository,
+	operatorRepo repository.OperatorRepository,
+) GTFSService {
+	return &gtfsService{
+		gtfsRepo:     gtfsRepo,
+		routeRepo:    routeRepo,
+		portRepo:     portRepo,
+		vesselRepo:   vesselRepo,
+		operatorRepo: operatorRepo,
+	}
+}
+
+// Export builds the GTFS feed of an operator's routes and the sailings
+// departing between two dates. The feed's agency URL is the operator's
+// "website" setting and its time zone the "timezone" setting, or else that
+// of the operator's first route's departure port.
+func (s *gtfsService) Export(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) (*models.GTFSExport, error) {
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if endDate.After(startDate.AddDate(0, 0, maxGenerationDays)) {
+		return nil, fmt.Errorf("a feed can cover at most %d days", maxGenerationDays)
+	}
+
+	operator, err := s.operatorRepo.GetByID(ctx, operatorID)
+	if err != nil {
+		return nil, fmt.Errorf("operator not found: %w", err)
+	}
+
+	routes, err := s.routeRepo.ListByOperator(ctx, operatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := []*models.Port{}
+	seen := map[uuid.UUID]bool{}
+	for _, route := range routes {
+		for _, id := range []uuid.UUID{route.DeparturePortID, route.ArrivalPortID} {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			port, err := s.portRepo.GetByID(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get port: %w", err)
+			}
+			ports = append(ports, port)
+		}
+	}
+
+	schedules, err := s.gtfsRepo.ListSailings(ctx, operatorID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	agency := gtfs.Agency{ID: operator.Code, Name: operator.Name}
+	agency.URL, _ = operator.Settings["website"].(string)
+	if agency.URL == "" {
+		return nil, fmt.Errorf("the operator's website setting is needed as the feed's agency URL")
+	}
+	agency.Timezone, _ = operator.Settings["timezone"].(string)
+	if agency.Timezone == "" && len(routes) > 0 {
+		for _, port := range ports {
+			if port.ID == routes[0].DeparturePortID {
+				agency.Timezone = port.Timezone
+			}
+		}
+	}
+	if operator.ContactPhone != nil {
+		agency.Phone = *operator.ContactPhone
+	}
+
+	feed, err := gtfs.Export(agency, ports, routes, schedules)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := gtfs.Write(&buf, feed); err != nil {
+		return nil, err
+	}
+
+	return &models.GTFSExport{
+		FileName: fmt.Sprintf("gtfs_%s_%s_%s.zip",
+			strings.ToLower(operator.Code), startDate.Format(gtfs.DateFormat), endDate.Format(gtfs.DateFormat)),
+		ContentType: gtfs.ContentType,
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// Import seeds an operator's routes and schedules from the sailings of a
+// GTFS feed's ferry trips between two dates, from today at the earliest.
+// Stops are matched to ports by code, stop_code first; a route is created
+// for each pair of ports the operator does not sail between yet. Each trip's
+// sailings are imported once, however often the feed is imported.
+func (s *gtfsService) Import(ctx context.Context, req *models.GTFSImportRequest) (*models.GTFSImport, error) {
+	from := today()
+	if req.From.After(from) {
+		from = req.From
+	}
+	if req.Until.Before(from) {
+		return nil, fmt.Errorf("until must not be before %s", from.Format(timetable.DateFormat))
+	}
+	if req.Until.After(today().AddDate(0, 0, maxGenerationDays)) {
+		return nil, fmt.Errorf("schedules can be imported at most %d days ahead", maxGenerationDays)
+	}
+	if req.BasePrice.IsNegative() {
+		return nil, fmt.Errorf("base price cannot be negative")
+	}
+
+	feed, err := gtfs.Read(req.Feed)
+	if err != nil {
+		return nil, err
+	}
+
+	legs, skippedTrips, err := feed.Legs(from, req.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.GTFSImport{
+		DryRun:        req.DryRun,
+		From:          from,
+		Until:         req.Until,
+		RoutesCreated: []*models.Route{},
+		Created:       []models.GTFSSailing{},
+		Skipped:       []models.GTFSSkippedTrip{},
+	}
+	skipped := map[string]bool{}
+	skip := func(tripID, reason string) {
+		if !skipped[tripID] {
+			skipped[tripID] = true
+			result.Skipped = append(result.Skipped, models.GTFSSkippedTrip{TripID: tripID, Reason: reason})
+		}
+	}
+	for _, trip := range skippedTrips {
+		skip(trip.TripID, trip.Reason)
+	}
+
+	ports := s.stopPorts(ctx, feed)
+	for _, leg := range legs {
+		for _, stopID := range []string{leg.FromStopID, leg.ToStopID} {
+			if ports[stopID] == nil {
+				skip(leg.TripID, fmt.Sprintf("stop %q is not a port on the platform", stopID))
+			}
+		}
+		if ports[leg.FromStopID] != nil && ports[leg.FromStopID] == ports[leg.ToStopID] {
+			skip(leg.TripID, fmt.Sprintf("trip calls at port %s twice in a row", ports[leg.FromStopID].Code))
+		}
+	}
+
+	vessels, err := s.importVessels(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := s.routeRepo.ListByOperator(ctx, req.OperatorID)
+	if err != nil {
+		return nil, err
+	}
+	byPorts := make(map[string]*models.Route, len(routes))
+	for _, route := range routes {
+		byPorts[route.DeparturePortID.String()+route.ArrivalPortID.String()] = route
+	}
+
+	// Sailings leaving after midnight are dated the day after their service
+	imported, err := s.gtfsRepo.ListImported(ctx, req.OperatorID, from, req.Until.AddDate(0, 0, 2))
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, sailing := range imported {
+		existing[sailingKey(sailing.TripID, sailing.Schedule.RouteID, sailing.Schedule.DepartureDate)] = true
+	}
+
+	for _, leg := range legs {
+		if skipped[leg.TripID] {
+			continue
+		}
+		departurePort, arrivalPort := ports[leg.FromStopID], ports[leg.ToStopID]
+		departureZone, err := porttime.LoadZone(departurePort.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("port %s: %w", departurePort.Code, err)
+		}
+		arrivalZone, err := porttime.LoadZone(arrivalPort.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("port %s: %w", arrivalPort.Code, err)
+		}
+
+		route, ok := byPorts[departurePort.ID.String()+arrivalPort.ID.String()]
+		if !ok {
+			route = &models.Route{
+				ID:                uuid.New(),
+				OperatorID:        req.OperatorID,
+				Name:              fmt.Sprintf("%s - %s", departurePort.Name, arrivalPort.Name),
+				DeparturePortID:   departurePort.ID,
+				ArrivalPortID:     arrivalPort.ID,
+				EstimatedDuration: leg.ArrivalAt.Sub(leg.DepartureAt),
+				IsActive:          true,
+			}
+			byPorts[departurePort.ID.String()+arrivalPort.ID.String()] = route
+			result.RoutesCreated = append(result.RoutesCreated, route)
+		}
+
+		departs, arrives := leg.DepartureAt.In(departureZone), leg.ArrivalAt.In(arrivalZone)
+		departureDate := time.Date(departs.Year(), departs.Month(), departs.Day(), 0, 0, 0, 0, time.UTC)
+		key := sailingKey(leg.TripID, route.ID, departureDate)
+		if existing[key] {
+			result.Existing++
+			continue
+		}
+		existing[key] = true
+
+		vessel := vessels[leg.BlockID]
+		if vessel == nil {
+			vessel = vessels[leg.RouteID]
+		}
+		if vessel == nil {
+			vessel = vessels[""]
+		}
+
+		schedule := &models.Schedule{
+			OperatorID:     req.OperatorID,
+			RouteID:        route.ID,
+			VesselID:       vessel.ID,
+			DepartureDate:  departureDate,
+			DepartureTime:  time.Date(0, 1, 1, departs.Hour(), departs.Minute(), 0, 0, time.UTC),
+			ArrivalTime:    time.Date(0, 1, 1, arrives.Hour(), arrives.Minute(), 0, 0, time.UTC),
+			DepartureAt:    leg.DepartureAt,
+			ArrivalAt:      leg.ArrivalAt,
+			BasePrice:      req.BasePrice,
+			TotalCapacity:  vessel.Capacity,
+			AvailableSeats: vessel.Capacity,
+			Status:         "scheduled",
+		}
+		porttime.Localize(schedule, departureZone.String(), arrivalZone.String())
+		result.Created = append(result.Created, models.GTFSSailing{TripID: leg.TripID, Schedule: schedule})
+	}
+
+	if err := checkVesselOverlaps(result.Created); err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		return result, nil
+	}
+
+	created, err := s.gtfsRepo.Import(ctx, result.RoutesCreated, result.Created)
+	if err != nil {
+		return nil, err
+	}
+	result.Existing += len(result.Created) - len(created)
+	result.Created = created
+
+	return result, nil
+}
+
+// stopPorts matches the feed's stops to ports by stop_code, or else stop_id
+func (s *gtfsService) stopPorts(ctx context.Context, feed *gtfs.Feed) map[string]*models.Port {
+	ports := map[string]*models.Port{}
+	for _, stop := range feed.Stops {
+		for _, code := range []string{stop.Code, stop.ID} {
+			if code == "" {
+				continue
+			}
+			if port, err := s.portRepo.GetByCode(ctx, strings.ToUpper(code)); err == nil {
+				ports[stop.ID] = port
+				break
+			}
+		}
+	}
+	return ports
+}
+
+// importVessels loads the vessels an import puts on its sailings, keyed by
+// GTFS block or route ID; the default vessel is under "". Each must be an
+// active vessel of the operator.
+func (s *gtfsService) importVessels(ctx context.Context, req *models.GTFSImportRequest) (map[string]*models.Vessel, error) {
+	ids := map[string]uuid.UUID{"": req.VesselID}
+	for key, id := range req.Vessels {
+		if key != "" {
+			ids[key] = id
+		}
+	}
+
+	loaded := map[uuid.UUID]*models.Vessel{}
+	vessels := make(map[string]*models.Vessel, len(ids))
+	for key, id := range ids {
+		vessel, ok := loaded[id]
+		if !ok {
+			var err error
+			if vessel, err = s.vesselRepo.GetByID(ctx, id); err != nil {
+				return nil, fmt.Errorf("vessel not found: %w", err)
+			}
+			if vessel.OperatorID != req.OperatorID {
+				return nil, fmt.Errorf("vessel %s does not belong to the operator", vessel.Name)
+			}
+			if !vessel.IsActive {
+				return nil, fmt.Errorf("vessel %s is not active", vessel.Name)
+			}
+			loaded[id] = vessel
+		}
+		vessels[key] = vessel
+	}
+	return vessels, nil
+}
+
+// checkVesselOverlaps rejects sailings that would have a vessel in two
+// places at once, naming the trips so their blocks or routes can be given
+// vessels of their own
+func checkVesselOverlaps(sailings []models.GTFSSailing) error {
+	ordered := append([]models.GTFSSailing{}, sailings...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Schedule.VesselID != ordered[j].Schedule.VesselID {
+			return ordered[i].Schedule.VesselID.String() < ordered[j].Schedule.VesselID.String()
+		}
+		return ordered[i].Schedule.DepartureAt.Before(ordered[j].Schedule.DepartureAt)
+	})
+
+	for i := 1; i < len(ordered); i++ {
+		previous, current := ordered[i-1], ordered[i]
+		if previous.Schedule.VesselID == current.Schedule.VesselID && current.Schedule.DepartureAt.Before(previous.Schedule.ArrivalAt) {
+			return fmt.Errorf("trips %s and %s overlap on %s; give their blocks or routes different vessels",
+				previous.TripID, current.TripID, current.Schedule.DepartureAt.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// sailingKey identifies a trip's sailing on a route and date
+func sailingKey(tripID string, routeID uuid.UUID, departureDate time.Time) string {
+	return tripID + "/" + routeID.String() + "/" + departureDate.Format(timetable.DateFormat)
+}
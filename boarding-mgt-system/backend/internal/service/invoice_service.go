@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/invoice"
+	"github.com/ferryflow/boarding-mgt-system/internal/ledger"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type InvoiceService interface {
+	IssueInvoice(ctx context.Context, booking *models.Booking) (*models.Invoice, error)
+	IssueCreditNote(ctx context.Context, booking *models.Booking, sourceID uuid.UUID, amount money.Money) (*models.Invoice, error)
+	GetBookingInvoice(ctx context.Context, bookingID uuid.UUID) (*models.Invoice, error)
+	ListBookingInvoices(ctx context.Context, bookingID uuid.UUID) ([]*models.Invoice, error)
+	GetInvoice(ctx context.Context, id uuid.UUID) (*models.Invoice, error)
+	WritePDF(w io.Writer, doc *models.Invoice) error
+}
+
+type invoiceService struct {
+	invoiceRepo  repository.InvoiceRepository
+	bookingRepo  repository.BookingRepository
+	scheduleRepo repository.ScheduleRepository
+	ticketRepo   repository.TicketRepository
+	operatorRepo repository.OperatorRepository
+	userRepo     repository.UserRepository
+}
+
+func NewInvoiceService(
+	invoiceRepo repository.InvoiceRepository,
+	bookingRepo repository.BookingRepository,
+	scheduleRepo repository.ScheduleRepository,
+	ticketRepo repository.TicketRepository,
+	operatorRepo repository.OperatorRepository,
+	userRepo repository.UserRepository,
+) InvoiceService {
+	return &invoiceService{
+		invoiceRepo:  invoiceRepo,
+		bookingRepo:  bookingRepo,
+		scheduleRepo: scheduleRepo,
+		ticketRepo:   ticketRepo,
+		operatorRepo: operatorRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// IssueInvoice issues the invoice for a paid booking. A booking is invoiced
+// once; later calls return the invoice already issued.
+func (s *invoiceService) IssueInvoice(ctx context.Context, booking *models.Booking) (*models.Invoice, error) {
+	if existing, err := s.invoiceRepo.GetBySource(ctx, invoice.TypeInvoice, booking.ID); err == nil {
+		return existing, nil
+	}
+
+	if booking.PaymentStatus != "paid" {
+		return nil, fmt.Errorf("booking has not been paid in full")
+	}
+
+	return s.issueInvoice(ctx, booking)
+}
+
+// IssueCreditNote issues a credit note against a booking's invoice for a
+// refund. sourceID identifies the refund so each refund is credited once.
+// A booking that was never invoiced has nothing to credit, so no credit note
+// is issued and nil is returned.
+func (s *invoiceService) IssueCreditNote(ctx context.Context, booking *models.Booking, sourceID uuid.UUID, amount money.Money) (*models.Invoice, error) {
+	if existing, err := s.invoiceRepo.GetBySource(ctx, invoice.TypeCreditNote, sourceID); err == nil {
+		return existing, nil
+	}
+
+	documents, err := s.invoiceRepo.ListByBooking(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var original *models.Invoice
+	for _, doc := range documents {
+		if doc.DocumentType == invoice.TypeInvoice {
+			original = doc
+		}
+	}
+	if original == nil {
+		return nil, nil
+	}
+
+	var credited money.Money
+	for _, doc := range documents {
+		if doc.DocumentType == invoice.TypeCreditNote && doc.OriginalInvoiceID != nil && *doc.OriginalInvoiceID == original.ID {
+			credited = credited.Add(doc.Total)
+		}
+	}
+	if remaining := original.Total.Sub(credited); amount.Cmp(remaining) > 0 {
+		return nil, fmt.Errorf("credit amount %s exceeds the %s left to credit on invoice %s",
+			amount, remaining, original.InvoiceNumber)
+	}
+
+	lines, err := invoice.CreditLines(original, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	operator, err := s.operatorRepo.GetByID(ctx, original.OperatorID)
+	if err != nil {
+		return nil, fmt.Errorf("operator not found: %w", err)
+	}
+
+	issueDate := entryDate(time.Now())
+	doc := &models.Invoice{
+		OperatorID:            original.OperatorID,
+		BookingID:             booking.ID,
+		CustomerID:            original.CustomerID,
+		DocumentType:          invoice.TypeCreditNote,
+		FiscalYear:            invoice.FiscalYear(issueDate, operator.FiscalYearStartMonth),
+		OriginalInvoiceID:     &original.ID,
+		OriginalInvoiceNumber: &original.InvoiceNumber,
+		SourceID:              sourceID,
+		IssueDate:             issueDate,
+		Currency:              original.Currency,
+		Seller:                invoice.Seller(operator),
+		Buyer:                 original.Buyer,
+		Lines:                 lines,
+		NumberPrefix:          invoice.NumberPrefix(operator.Code, invoice.TypeCreditNote),
+	}
+	invoice.SetTotals(doc)
+
+	return s.issue(ctx, doc)
+}
+
+// GetBookingInvoice returns a booking's invoice, issuing it on first request
+// for bookings that were paid before invoicing was enabled
+func (s *invoiceService) GetBookingInvoice(ctx context.Context, bookingID uuid.UUID) (*models.Invoice, error) {
+	if existing, err := s.invoiceRepo.GetBySource(ctx, invoice.TypeInvoice, bookingID); err == nil {
+		return existing, nil
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("booking not found: %w", err)
+	}
+
+	return s.IssueInvoice(ctx, booking)
+}
+
+func (s *invoiceService) ListBookingInvoices(ctx context.Context, bookingID uuid.UUID) ([]*models.Invoice, error) {
+	return s.invoiceRepo.ListByBooking(ctx, bookingID)
+}
+
+func (s *invoiceService) GetInvoice(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
+	return s.invoiceRepo.GetByID(ctx, id)
+}
+
+func (s *invoiceService) WritePDF(w io.Writer, doc *models.Invoice) error {
+	return invoice.WritePDF(w, doc)
+}
+
+// issueInvoice snapshots the operator, customer and priced tickets of a
+// booking into a new invoice
+func (s *invoiceService) issueInvoice(ctx context.Context, booking *models.Booking) (*models.Invoice, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, booking.ScheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("schedule not found: %w", err)
+	}
+
+	operator, err := s.operatorRepo.GetByID(ctx, schedule.OperatorID)
+	if err != nil {
+		return nil, fmt.Errorf("operator not found: %w", err)
+	}
+
+	customer := booking.Customer
+	if customer == nil || customer.Email == "" {
+		customer, err = s.userRepo.GetByID(ctx, booking.CustomerID)
+		if err != nil {
+			return nil, fmt.Errorf("customer not found: %w", err)
+		}
+	}
+
+	tickets := booking.Tickets
+	if len(tickets) == 0 {
+		loaded, err := s.ticketRepo.GetByBooking(ctx, booking.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tickets: %w", err)
+		}
+		for _, ticket := range loaded {
+			tickets = append(tickets, *ticket)
+		}
+	}
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("booking has no tickets to invoice")
+	}
+
+	taxRate, _ := ledger.Rates(operator.Settings)
+
+	issueDate := entryDate(time.Now())
+	doc := &models.Invoice{
+		OperatorID:   operator.ID,
+		BookingID:    booking.ID,
+		CustomerID:   customer.ID,
+		DocumentType: invoice.TypeInvoice,
+		FiscalYear:   invoice.FiscalYear(issueDate, operator.FiscalYearStartMonth),
+		SourceID:     booking.ID,
+		IssueDate:    issueDate,
+		Currency:     booking.TotalAmount.Currency(),
+		Seller:       invoice.Seller(operator),
+		Buyer:        invoice.Buyer(customer),
+		Lines:        invoice.TicketLines(schedule, tickets, taxRate),
+		NumberPrefix: invoice.NumberPrefix(operator.Code, invoice.TypeInvoice),
+	}
+	invoice.SetTotals(doc)
+
+	return s.issue(ctx, doc)
+}
+
+// issue stores a document, returning the one already issued for the same
+// source if another request got there first
+func (s *invoiceService) issue(ctx context.Context, doc *models.Invoice) (*models.Invoice, error) {
+	issued, err := s.invoiceRepo.Issue(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	if !issued {
+		return s.invoiceRepo.GetBySource(ctx, doc.DocumentType, doc.SourceID)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/ledger"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type LedgerService interface {
+	PostBooking(ctx context.Context, booking *models.Booking) error
+	PostPayment(ctx context.Context, booking *models.Booking, payment *models.Payment) error
+	PostRefund(ctx context.Context, booking *models.Booking, sourceID uuid.UUID, amount, paidOut money.Money) error
+	PostRefundPayment(ctx context.Context, booking *models.Booking, refund *models.Refund) error
+	PostDeparture(ctx context.Context, schedule *models.Schedule) error
+	PostNoShowCredit(ctx context.Context, schedule *models.Schedule, credit *models.TravelCredit) error
+	PostCancellationCredit(ctx context.Context, booking *models.Booking, credit *models.TravelCredit) error
+	ListAccounts(ctx context.Context, operatorID uuid.UUID) ([]*models.LedgerAccount, error)
+	GetTrialBalance(ctx context.Context, operatorID uuid.UUID, asOf time.Time) (*models.TrialBalance, error)
+	ExportJournal(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time, format string, w io.Writer) error
+}
+
+type ledgerService struct {
+	ledgerRepo   repository.LedgerRepository
+	operatorRepo repository.OperatorRepository
+}
+
+func NewLedgerService(
+	ledgerRepo repository.LedgerRepository,
+	operatorRepo repository.OperatorRepository,
+) LedgerService {
+	return &ledgerService{
+		ledgerRepo:   ledgerRepo,
+		operatorRepo: operatorRepo,
+	}
+}
+
+func (s *ledgerService) PostBooking(ctx context.Context, booking *models.Booking) error {
+	if !booking.TotalAmount.IsPositive() {
+		return nil
+	}
+
+	operatorID := booking.Schedule.OperatorID
+
+	operator, err := s.operatorRepo.GetByID(ctx, operatorID)
+	if err != nil {
+		return fmt.Errorf("operator not found: %w", err)
+	}
+
+	taxRate, commissionRate := ledger.Rates(operator.Settings)
+
+	// Commission is only earned on sales made by external agents. Agencies
+	// have their own rates, worked out when the booking was priced.
+	var commission money.Money
+	switch {
+	case booking.AgencyID != nil:
+		commission = booking.CommissionAmount
+	case booking.BookingChannel == "agent" && booking.BookingAgentID != nil:
+		commission = booking.TotalAmount.Mul(commissionRate, money.HalfUp)
+	}
+
+	entry := &models.JournalEntry{
+		OperatorID:  operatorID,
+		EntryDate:   entryDate(booking.CreatedAt),
+		EventType:   "booking",
+		SourceID:    booking.ID,
+		BookingID:   &booking.ID,
+		ScheduleID:  &booking.ScheduleID,
+		Description: fmt.Sprintf("Booking %s", booking.BookingReference),
+		Currency:    booking.TotalAmount.Currency(),
+		Lines:       ledger.BookingLines(booking.ID, booking.TotalAmount, taxRate, commission),
+	}
+
+	return s.post(ctx, entry)
+}
+
+func (s *ledgerService) PostPayment(ctx context.Context, booking *models.Booking, payment *models.Payment) error {
+	operatorID := booking.Schedule.OperatorID
+
+	entry := &models.JournalEntry{
+		OperatorID:  operatorID,
+		EntryDate:   entryDate(time.Now()),
+		EventType:   "payment",
+		SourceID:    payment.ID,
+		BookingID:   &booking.ID,
+		Description: fmt.Sprintf("Payment for booking %s (%s)", booking.BookingReference, payment.PaymentMethod),
+		Currency:    payment.Currency,
+		Lines:       ledger.PaymentLines(booking.ID, payment.Amount),
+	}
+
+	return s.post(ctx, entry)
+}
+
+// PostRefund moves the refunded amount of a booking into refunds payable and
+// posts paidOut, the part of it already paid back, as paid. The rest is
+// posted by PostRefundPayment as each pending refund is processed.
+func (s *ledgerService) PostRefund(ctx context.Context, booking *models.Booking, sourceID uuid.UUID, amount, paidOut money.Money) error {
+	operatorID := booking.Schedule.OperatorID
+
+	sale, err := s.ledgerRepo.GetEntryBySource(ctx, "booking", booking.ID)
+	if err != nil {
+		return fmt.Errorf("booking sale not posted: %w", err)
+	}
+
+	if err := money.CheckCurrency(money.Zero(sale.Currency), amount, paidOut); err != nil {
+		return fmt.Errorf("refund does not match the sale: %w", err)
+	}
+
+	// Fares are recognized as revenue when their sailing departs
+	recognized, err := s.ledgerRepo.HasEntry(ctx, "departure", booking.ScheduleID)
+	if err != nil {
+		return err
+	}
+
+	lines, err := ledger.RefundLines(booking.ID, sale.Lines, amount, recognized)
+	if err != nil {
+		return err
+	}
+
+	entry := &models.JournalEntry{
+		OperatorID:  operatorID,
+		EntryDate:   entryDate(time.Now()),
+		EventType:   "refund",
+		SourceID:    sourceID,
+		BookingID:   &booking.ID,
+		Description: fmt.Sprintf("Refund for booking %s", booking.BookingReference),
+		Currency:    sale.Currency,
+		Lines:       lines,
+	}
+
+	if err := s.post(ctx, entry); err != nil {
+		return err
+	}
+
+	if !paidOut.IsPositive() {
+		return nil
+	}
+
+	return s.postPayout(ctx, operatorID, booking, sourceID, entry.EntryDate, paidOut)
+}
+
+// PostRefundPayment posts a pending refund paid back once it is processed
+func (s *ledgerService) PostRefundPayment(ctx context.Context, booking *models.Booking, refund *models.Refund) error {
+	operatorID := booking.Schedule.OperatorID
+
+	paidAt := time.Now()
+	if refund.ProcessedAt != nil {
+		paidAt = *refund.ProcessedAt
+	}
+
+	return s.postPayout(ctx, operatorID, booking, refund.ID, entryDate(paidAt), refund.RefundAmount)
+}
+
+// postPayout posts money paid back to a customer out of refunds payable
+func (s *ledgerService) postPayout(ctx context.Context, operatorID uuid.UUID, booking *models.Booking, sourceID uuid.UUID, date time.Time, amount money.Money) error {
+	payout := &models.JournalEntry{
+		OperatorID:  operatorID,
+		EntryDate:   date,
+		EventType:   "refund_payment",
+		SourceID:    sourceID,
+		BookingID:   &booking.ID,
+		Description: fmt.Sprintf("Refund paid for booking %s", booking.BookingReference),
+		Currency:    amount.Currency(),
+		Lines:       ledger.RefundPayoutLines(booking.ID, amount),
+	}
+
+	return s.post(ctx, payout)
+}
+
+func (s *ledgerService) PostDeparture(ctx context.Context, schedule *models.Schedule) error {
+	balances, err := s.ledgerRepo.GetDeferredBalances(ctx, schedule.ID)
+	if err != nil {
+		return err
+	}
+
+	// One entry recognizes the whole sailing, so its fares must share a
+	// currency
+	amounts := make([]money.Money, 0, len(balances))
+	for _, balance := range balances {
+		amounts = append(amounts, balance)
+	}
+	if err := money.CheckCurrency(amounts...); err != nil {
+		return fmt.Errorf("cannot recognize revenue: %w", err)
+	}
+
+	lines := ledger.DepartureLines(balances)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	entry := &models.JournalEntry{
+		OperatorID:  schedule.OperatorID,
+		EntryDate:   entryDate(time.Now()),
+		EventType:   "departure",
+		SourceID:    schedule.ID,
+		ScheduleID:  &schedule.ID,
+		Description: fmt.Sprintf("Revenue recognized for departure on %s", schedule.DepartureDate.Format("2006-01-02")),
+		Currency:    money.Sum(amounts...).Currency(),
+		Lines:       lines,
+	}
+
+	return s.post(ctx, entry)
+}
+
+func (s *ledgerService) PostNoShowCredit(ctx context.Context, schedule *models.Schedule, credit *models.TravelCredit) error {
+	entry := &models.JournalEntry{
+		OperatorID:  schedule.OperatorID,
+		EntryDate:   entryDate(credit.CreatedAt),
+		EventType:   "no_show_credit",
+		SourceID:    credit.ID,
+		BookingID:   &credit.BookingID,
+		ScheduleID:  &schedule.ID,
+		Description: fmt.Sprintf("Travel credit %s for no-show on departure %s", credit.Code, schedule.DepartureDate.Format("2006-01-02")),
+		Currency:    credit.Amount.Currency(),
+		Lines:       ledger.NoShowCreditLines(credit.BookingID, credit.Amount),
+	}
+
+	return s.post(ctx, entry)
+}
+
+// PostCancellationCredit moves fare paid for a cancelled sailing that the
+// passenger took as travel credit out of deferred revenue
+func (s *ledgerService) PostCancellationCredit(ctx context.Context, booking *models.Booking, credit *models.TravelCredit) error {
+	sale, err := s.ledgerRepo.GetEntryBySource(ctx, "booking", booking.ID)
+	if err != nil {
+		return fmt.Errorf("booking sale not posted: %w", err)
+	}
+
+	if err := money.CheckCurrency(money.Zero(sale.Currency), credit.Amount); err != nil {
+		return fmt.Errorf("credit does not match the sale: %w", err)
+	}
+
+	lines, err := ledger.CancellationCreditLines(booking.ID, sale.Lines, credit.Amount)
+	if err != nil {
+		return err
+	}
+
+	entry := &models.JournalEntry{
+		OperatorID:  credit.OperatorID,
+		EntryDate:   entryDate(credit.CreatedAt),
+		EventType:   "cancellation_credit",
+		SourceID:    credit.ID,
+		BookingID:   &booking.ID,
+		ScheduleID:  &booking.ScheduleID,
+		Description: fmt.Sprintf("Travel credit %s for cancelled booking %s", credit.Code, booking.BookingReference),
+		Currency:    sale.Currency,
+		Lines:       lines,
+	}
+
+	return s.post(ctx, entry)
+}
+
+func (s *ledgerService) ListAccounts(ctx context.Context, operatorID uuid.UUID) ([]*models.LedgerAccount, error) {
+	if _, err := s.ledgerRepo.EnsureAccounts(ctx, operatorID, ledger.DefaultAccounts); err != nil {
+		return nil, err
+	}
+
+	return s.ledgerRepo.ListAccounts(ctx, operatorID)
+}
+
+func (s *ledgerService) GetTrialBalance(ctx context.Context, operatorID uuid.UUID, asOf time.Time) (*models.TrialBalance, error) {
+	return s.ledgerRepo.GetTrialBalance(ctx, operatorID, asOf)
+}
+
+func (s *ledgerService) ExportJournal(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time, format string, w io.Writer) error {
+	if endDate.Before(startDate) {
+		return fmt.Errorf("end date must not be before start date")
+	}
+
+	entries, err := s.ledgerRepo.ListEntries(ctx, operatorID, startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "csv":
+		return ledger.WriteCSV(w, entries)
+	case "iif":
+		accounts, err := s.ListAccounts(ctx, operatorID)
+		if err != nil {
+			return err
+		}
+		return ledger.WriteIIF(w, accounts, entries)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// post resolves account keys to the operator's accounts and writes the entry.
+// Posting an event twice is a no-op.
+func (s *ledgerService) post(ctx context.Context, entry *models.JournalEntry) error {
+	if err := ledger.Validate(entry.Lines); err != nil {
+		return fmt.Errorf("invalid %s posting: %w", entry.EventType, err)
+	}
+
+	accounts, err := s.ledgerRepo.EnsureAccounts(ctx, entry.OperatorID, ledger.DefaultAccounts)
+	if err != nil {
+		return err
+	}
+
+	for i := range entry.Lines {
+		account, ok := accounts[entry.Lines[i].AccountKey]
+		if !ok {
+			return fmt.Errorf("ledger account %s not configured", entry.Lines[i].AccountKey)
+		}
+		entry.Lines[i].AccountID = account.ID
+	}
+
+	if _, err := s.ledgerRepo.PostEntry(ctx, entry); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// entryDate truncates a timestamp to the UTC accounting date
+func entryDate(t time.Time) time.Time {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/manifest"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ManifestService interface {
+	Export(ctx context.Context, scheduleID uuid.UUID, format string) (*models.ManifestExport, error)
+	CheckCompleteness(ctx context.Context, scheduleID uuid.UUID) (*models.ManifestCompleteness, error)
+}
+
+type manifestService struct {
+	ticketRepo   repository.TicketRepository
+	scheduleRepo repository.ScheduleRepository
+	operatorRepo repository.OperatorRepository
+}
+
+func NewManifestService(ticketRepo repository.TicketRepository, scheduleRepo repository.ScheduleRepository, operatorRepo repository.OperatorRepository) ManifestService {
+	return &manifestService{
+		ticketRepo:   ticketRepo,
+		scheduleRepo: scheduleRepo,
+		operatorRepo: operatorRepo,
+	}
+}
+
+// Export renders a sailing's passenger manifest with the formatter
+// registered for format
+func (s *manifestService) Export(ctx context.Context, scheduleID uuid.UUID, format string) (*models.ManifestExport, error) {
+	formatter, err := manifest.Lookup(format)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.ticketRepo.GetManifest(ctx, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Write(&buf, m); err != nil {
+		return nil, err
+	}
+
+	return &models.ManifestExport{
+		FileName: fmt.Sprintf("manifest_%s_%s.%s",
+			m.Voyage.Departure.Format("20060102_1504"), strings.ToUpper(m.Schedule.ID.String()[:8]), formatter.Extension()),
+		ContentType: formatter.ContentType(),
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// CheckCompleteness lists the passengers missing identity details the
+// operator's manifest policy makes mandatory
+func (s *manifestService) CheckCompleteness(ctx context.Context, scheduleID uuid.UUID) (*models.ManifestCompleteness, error) {
+	m, err := s.ticketRepo.GetManifest(ctx, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest: %w", err)
+	}
+
+	operator, err := s.operatorRepo.GetByID(ctx, m.Schedule.OperatorID)
+	if err != nil {
+		return nil, fmt.Errorf("operator not found: %w", err)
+	}
+
+	return manifest.Check(m, manifest.PolicyFromSettings(operator.Settings)), nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/noshow"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type NoShowService interface {
+	ProcessDeparture(ctx context.Context, schedule *models.Schedule) (*models.DepartureSummary, error)
+	GetDepartureSummary(ctx context.Context, scheduleID uuid.UUID) (*models.DepartureSummary, error)
+	GetReport(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) (*models.NoShowReport, error)
+	GetCustomerCredits(ctx context.Context, customerID uuid.UUID) ([]*models.TravelCredit, error)
+}
+
+type noShowService struct {
+	noShowRepo    repository.NoShowRepository
+	scheduleRepo  repository.ScheduleRepository
+	operatorRepo  repository.OperatorRepository
+	ledgerService LedgerService
+}
+
+func NewNoShowService(
+	noShowRepo repository.NoShowRepository,
+	scheduleRepo repository.ScheduleRepository,
+	operatorRepo repository.OperatorRepository,
+	ledgerService LedgerService,
+) NoShowService {
+	return &noShowService{
+		noShowRepo:    noShowRepo,
+		scheduleRepo:  scheduleRepo,
+		operatorRepo:  operatorRepo,
+		ledgerService: ledgerService,
+	}
+}
+
+// ProcessDeparture marks a departed sailing's unboarded tickets as no-shows
+// under the operator's no-show policy and records its final counts. Running
+// it again returns the counts recorded the first time.
+func (s *noShowService) ProcessDeparture(ctx context.Context, schedule *models.Schedule) (*models.DepartureSummary, error) {
+	if schedule.Status != "departed" && schedule.Status != "arrived" {
+		return nil, fmt.Errorf("no-shows can only be processed once a sailing has departed")
+	}
+
+	operator, err := s.operatorRepo.GetByID(ctx, schedule.OperatorID)
+	if err != nil {
+		return nil, fmt.Errorf("operator not found: %w", err)
+	}
+
+	summary, processed, err := s.noShowRepo.ProcessDeparture(ctx, schedule.ID, noshow.PolicyFromSettings(operator.Settings), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !processed {
+		return summary, nil
+	}
+
+	for _, credit := range summary.Credits {
+		if err := s.ledgerService.PostNoShowCredit(ctx, schedule, credit); err != nil {
+			// Non-critical error, log but don't fail
+			fmt.Printf("failed to post no-show credit to ledger: %v\n", err)
+		}
+	}
+
+	return summary, nil
+}
+
+func (s *noShowService) GetDepartureSummary(ctx context.Context, scheduleID uuid.UUID) (*models.DepartureSummary, error) {
+	return s.noShowRepo.GetSummary(ctx, scheduleID)
+}
+
+// GetReport returns no-show rates by route and booking channel for sailings
+// departing between two dates
+func (s *noShowService) GetReport(ctx context.Context, operatorID uuid.UUID, startDate, endDate time.Time) (*models.NoShowReport, error) {
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+
+	counts, err := s.noShowRepo.GetNoShowCounts(ctx, operatorID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return noshow.Summarize(counts, startDate, endDate), nil
+}
+
+func (s *noShowService) GetCustomerCredits(ctx context.Context, customerID uuid.UUID) ([]*models.TravelCredit, error) {
+	return s.noShowRepo.ListCustomerCredits(ctx, customerID)
+}
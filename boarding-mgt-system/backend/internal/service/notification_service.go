@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type NotificationService interface {
+	Notify(ctx context.Context, notification *models.Notification) error
+	ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*models.Notification, error)
+	MarkRead(ctx context.Context, id, userID uuid.UUID) error
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository) NotificationService {
+	return &notificationService{notificationRepo: notificationRepo}
+}
+
+// Notify sends a notification to a user's inbox. Notifications with a dedupe
+// key already sent are skipped, so callers can safely notify again after a
+// retry.
+func (s *notificationService) Notify(ctx context.Context, notification *models.Notification) error {
+	_, err := s.notificationRepo.Create(ctx, notification)
+	return err
+}
+
+func (s *notificationService) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	return s.notificationRepo.ListByUser(ctx, userID, unreadOnly, limit)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, id, userID uuid.UUID) error {
+	return s.notificationRepo.MarkRead(ctx, id, userID)
+}
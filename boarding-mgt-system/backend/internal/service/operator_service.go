@@ -38,6 +38,7 @@ func (s *operatorService) CreateOperator(ctx context.Context, req *models.Create
 		Name:         req.Name,
 		Code:         req.Code,
 		ContactEmail: req.ContactEmail,
+		FiscalYearStartMonth: 1,
 		IsActive:     true,
 	}
 
@@ -47,6 +48,15 @@ func (s *operatorService) CreateOperator(ctx context.Context, req *models.Create
 	if req.Address != "" {
 		operator.Address = &req.Address
 	}
+	if req.LegalName != "" {
+		operator.LegalName = &req.LegalName
+	}
+	if req.TaxID != "" {
+		operator.TaxID = &req.TaxID
+	}
+	if req.FiscalYearStartMonth != 0 {
+		operator.FiscalYearStartMonth = req.FiscalYearStartMonth
+	}
 	if req.Settings != nil {
 		operator.Settings = req.Settings
 	} else {
@@ -89,6 +99,15 @@ func (s *operatorService) UpdateOperator(ctx context.Context, id uuid.UUID, req
 	if req.Address != nil {
 		operator.Address = req.Address
 	}
+	if req.LegalName != nil {
+		operator.LegalName = req.LegalName
+	}
+	if req.TaxID != nil {
+		operator.TaxID = req.TaxID
+	}
+	if req.FiscalYearStartMonth != nil {
+		operator.FiscalYearStartMonth = *req.FiscalYearStartMonth
+	}
 	if req.IsActive != nil {
 		operator.IsActive = *req.IsActive
 	}
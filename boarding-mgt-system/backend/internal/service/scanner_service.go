@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/auth"
+	"github.com/ferryflow/boarding-mgt-system/internal/gate"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/scansync"
+	"github.com/ferryflow/boarding-mgt-system/internal/ticketqr"
+	"github.com/google/uuid"
+)
+
+// bundleTTL is how long a scanner may work from a downloaded bundle
+const bundleTTL = 24 * time.Hour
+
+type ScannerService interface {
+	RegisterDevice(ctx context.Context, operatorID, registeredBy uuid.UUID, req *models.RegisterDeviceRequest) (*models.RegisteredDevice, error)
+	GetDevice(ctx context.Context, id uuid.UUID) (*models.ScannerDevice, error)
+	ListDevices(ctx context.Context, operatorID *uuid.UUID) ([]*models.ScannerDevice, error)
+	UpdateDevice(ctx context.Context, id uuid.UUID, req *models.UpdateDeviceRequest) (*models.ScannerDevice, error)
+	RotateKey(ctx context.Context, id uuid.UUID) (*models.RegisteredDevice, error)
+	AssignSchedules(ctx context.Context, id, assignedBy uuid.UUID, req *models.AssignSchedulesRequest) (*models.ScannerDevice, error)
+	UnassignSchedule(ctx context.Context, id, scheduleID uuid.UUID) error
+
+	// Device sync
+	Authenticate(ctx context.Context, id uuid.UUID, key string) (*models.ScannerDevice, error)
+	GetBundle(ctx context.Context, device *models.ScannerDevice) (*models.SignedBundle, error)
+	UploadScans(ctx context.Context, device *models.ScannerDevice, req *models.ScanUploadRequest) (*models.ScanUploadResponse, error)
+}
+
+type scannerService struct {
+	scannerRepo  repository.ScannerDeviceRepository
+	boardingRepo repository.BoardingRepository
+	userRepo     repository.UserRepository
+	feedService  BoardingFeedService
+
+	qrKeys *ticketqr.Keyring
+}
+
+func NewScannerService(
+	scannerRepo repository.ScannerDeviceRepository,
+	boardingRepo repository.BoardingRepository,
+	userRepo repository.UserRepository,
+	feedService BoardingFeedService,
+	qrKeys *ticketqr.Keyring,
+) ScannerService {
+	return &scannerService{
+		scannerRepo:  scannerRepo,
+		boardingRepo: boardingRepo,
+		userRepo:     userRepo,
+		feedService:  feedService,
+		qrKeys:       qrKeys,
+	}
+}
+
+func (s *scannerService) RegisterDevice(ctx context.Context, operatorID, registeredBy uuid.UUID, req *models.RegisterDeviceRequest) (*models.RegisteredDevice, error) {
+	key, err := generateDeviceKey()
+	if err != nil {
+		return nil, err
+	}
+
+	device := &models.ScannerDevice{
+		OperatorID:   operatorID,
+		Name:         req.Name,
+		DeviceType:   req.DeviceType,
+		RegisteredBy: &registeredBy,
+		ScheduleIDs:  []uuid.UUID{},
+	}
+
+	if err := s.scannerRepo.Create(ctx, device, auth.HashToken(key)); err != nil {
+		return nil, err
+	}
+
+	return &models.RegisteredDevice{Device: device, DeviceKey: key}, nil
+}
+
+func (s *scannerService) GetDevice(ctx context.Context, id uuid.UUID) (*models.ScannerDevice, error) {
+	device, _, err := s.scannerRepo.GetByID(ctx, id)
+	return device, err
+}
+
+func (s *scannerService) ListDevices(ctx context.Context, operatorID *uuid.UUID) ([]*models.ScannerDevice, error) {
+	return s.scannerRepo.List(ctx, operatorID)
+}
+
+func (s *scannerService) UpdateDevice(ctx context.Context, id uuid.UUID, req *models.UpdateDeviceRequest) (*models.ScannerDevice, error) {
+	device, _, err := s.scannerRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		device.Name = *req.Name
+	}
+	if req.IsActive != nil {
+		device.IsActive = *req.IsActive
+	}
+
+	if err := s.scannerRepo.Update(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// RotateKey issues a device a new key. The old key stops working at once.
+func (s *scannerService) RotateKey(ctx context.Context, id uuid.UUID) (*models.RegisteredDevice, error) {
+	device, _, err := s.scannerRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := generateDeviceKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scannerRepo.UpdateKey(ctx, id, auth.HashToken(key)); err != nil {
+		return nil, err
+	}
+
+	return &models.RegisteredDevice{Device: device, DeviceKey: key}, nil
+}
+
+func (s *scannerService) AssignSchedules(ctx context.Context, id, assignedBy uuid.UUID, req *models.AssignSchedulesRequest) (*models.ScannerDevice, error) {
+	if err := s.scannerRepo.AssignSchedules(ctx, id, req.ScheduleIDs, assignedBy); err != nil {
+		return nil, err
+	}
+
+	return s.GetDevice(ctx, id)
+}
+
+func (s *scannerService) UnassignSchedule(ctx context.Context, id, scheduleID uuid.UUID) error {
+	return s.scannerRepo.UnassignSchedule(ctx, id, scheduleID)
+}
+
+// Authenticate returns the active device a key belongs to
+func (s *scannerService) Authenticate(ctx context.Context, id uuid.UUID, key string) (*models.ScannerDevice, error) {
+	device, keyHash, err := s.scannerRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid device credentials")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(auth.HashToken(key)), []byte(keyHash)) != 1 {
+		return nil, fmt.Errorf("invalid device credentials")
+	}
+
+	if !device.IsActive {
+		return nil, fmt.Errorf("device is deactivated")
+	}
+
+	return device, nil
+}
+
+// GetBundle builds and signs the manifests of a device's schedules
+func (s *scannerService) GetBundle(ctx context.Context, device *models.ScannerDevice) (*models.SignedBundle, error) {
+	schedules, err := s.scannerRepo.ListAssignedSchedules(ctx, device.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, schedule := range schedules {
+		schedule.Passengers, err = s.scannerRepo.ListBundlePassengers(ctx, schedule.ScheduleID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys := []models.BundleKey{}
+	for _, key := range s.qrKeys.PublicKeys() {
+		keys = append(keys, models.BundleKey{
+			KeyID:     key.KeyID,
+			Algorithm: key.Algorithm,
+			PublicKey: key.PublicKey,
+		})
+	}
+
+	now := time.Now().UTC()
+	bundle := &models.ScannerBundle{
+		DeviceID:         device.ID,
+		OperatorID:       device.OperatorID,
+		GeneratedAt:      now,
+		ExpiresAt:        now.Add(bundleTTL),
+		CheckInCloses:    int(gate.DefaultPolicy.CheckInClosesBefore / time.Minute),
+		BoardingCloses:   int(gate.DefaultPolicy.BoardingClosesBefore / time.Minute),
+		VerificationKeys: keys,
+		Schedules:        schedules,
+	}
+
+	signed, err := scansync.SignBundle(bundle, s.qrKeys.Signer())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scannerRepo.TouchSync(ctx, device.ID); err != nil {
+		fmt.Printf("failed to record device sync: %v\n", err)
+	}
+
+	return signed, nil
+}
+
+// UploadScans merges a device's offline scans. Events are applied in the
+// order they were scanned and reported in the order they were sent.
+func (s *scannerService) UploadScans(ctx context.Context, device *models.ScannerDevice, req *models.ScanUploadRequest) (*models.ScanUploadResponse, error) {
+	assigned := map[uuid.UUID]bool{}
+	for _, scheduleID := range device.ScheduleIDs {
+		assigned[scheduleID] = true
+	}
+
+	now := time.Now()
+	response := &models.ScanUploadResponse{
+		ServerTime: now.UTC(),
+		Results:    make([]*models.ScanSyncResult, len(req.Events)),
+	}
+
+	order := make([]int, len(req.Events))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return req.Events[order[a]].ScannedAt.Before(req.Events[order[b]].ScannedAt)
+	})
+
+	staff := map[uuid.UUID]bool{}
+	for _, i := range order {
+		event := req.Events[i]
+		result := &models.ScanSyncResult{EventID: event.EventID}
+		response.Results[i] = result
+
+		if err := scansync.CheckTimestamp(event.ScannedAt, now, ticketqr.DefaultLeeway); err != nil {
+			result.Status = scansync.StatusRejected
+			result.Reason = scansync.RejectInvalidTimestamp
+			response.Rejected++
+			continue
+		}
+
+		if !assigned[event.ScheduleID] {
+			result.Status = scansync.StatusRejected
+			result.Reason = scansync.RejectNotAssigned
+			response.Rejected++
+			continue
+		}
+
+		scan := s.deviceScan(ctx, device, &event, staff)
+		status, err := s.boardingRepo.MergeDeviceScan(ctx, scan, gate.DefaultPolicy)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Status = status
+		switch status {
+		case scansync.StatusRejected:
+			result.Reason = scansync.RejectTicketNotFound
+			response.Rejected++
+			continue
+		case scansync.StatusDuplicate:
+			response.Duplicates++
+		default:
+			response.Applied++
+		}
+
+		result.Scan = scan
+		if scan.CheckInStatus != nil {
+			result.CheckInStatus = *scan.CheckInStatus
+		}
+		if scan.Conflict != nil {
+			result.Conflict = *scan.Conflict
+			result.ConflictDetail = *scan.ConflictDetail
+			if status == scansync.StatusApplied {
+				response.Conflicts++
+			}
+		}
+
+		if status == scansync.StatusApplied {
+			boarded := scan.Action == gate.ActionBoard && scan.Result != gate.ResultDenied && scan.Conflict == nil
+			s.feedService.PublishScan(ctx, scan, boarded)
+		}
+	}
+
+	if err := s.scannerRepo.TouchSync(ctx, device.ID); err != nil {
+		fmt.Printf("failed to record device sync: %v\n", err)
+	}
+
+	return response, nil
+}
+
+// deviceScan builds the scan to log for an uploaded event. The staff member
+// who scanned is kept only when they work for the device's operator.
+func (s *scannerService) deviceScan(ctx context.Context, device *models.ScannerDevice, event *models.OfflineScanEvent, staff map[uuid.UUID]bool) *models.BoardingScan {
+	scannedAt := event.ScannedAt
+	scan := &models.BoardingScan{
+		TicketID:        &event.TicketID,
+		ScheduleID:      event.ScheduleID,
+		Action:          event.Action,
+		Result:          event.Result,
+		Gate:            event.Gate,
+		DeviceID:        device.Name,
+		ScannerDeviceID: &device.ID,
+		EventID:         &event.EventID,
+		DeviceScannedAt: &scannedAt,
+	}
+	if event.DenyReason != "" {
+		scan.DenyReason = &event.DenyReason
+	}
+
+	if event.ScannedBy != nil {
+		known, ok := staff[*event.ScannedBy]
+		if !ok {
+			user, err := s.userRepo.GetByID(ctx, *event.ScannedBy)
+			known = err == nil && user.OperatorID != nil && *user.OperatorID == device.OperatorID
+			staff[*event.ScannedBy] = known
+		}
+		if known {
+			scan.ScannedBy = event.ScannedBy
+		}
+	}
+
+	return scan
+}
+
+// generateDeviceKey returns a random device key
+func generateDeviceKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate device key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,453 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/cancellation"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/tender"
+	"github.com/google/uuid"
+)
+
+type ScheduleCancellationService interface {
+	CancelSchedule(ctx context.Context, scheduleID uuid.UUID, reason string, startedBy *uuid.UUID) (*models.ScheduleCancellationReport, error)
+	GetReport(ctx context.Context, scheduleID uuid.UUID) (*models.ScheduleCancellationReport, error)
+	GetOffer(ctx context.Context, id uuid.UUID) (*models.CancellationOffer, error)
+	GetCustomerOffers(ctx context.Context, customerID uuid.UUID) ([]*models.CancellationOffer, error)
+	ChooseOption(ctx context.Context, offer *models.CancellationOffer, chosenBy *uuid.UUID, req *models.ChooseCancellationOptionRequest) (*models.CancellationOffer, error)
+	RefundLapsedOffers(ctx context.Context, now time.Time) (int, error)
+}
+
+type scheduleCancellationService struct {
+	cancellationRepo repository.CancellationRepository
+	scheduleRepo     repository.ScheduleRepository
+	bookingRepo      repository.BookingRepository
+	paymentRepo      repository.PaymentRepository
+	ticketRepo       repository.TicketRepository
+	operatorRepo     repository.OperatorRepository
+
+	bookingService      BookingService
+	ledgerService       LedgerService
+	notificationService NotificationService
+}
+
+func NewScheduleCancellationService(
+	cancellationRepo repository.CancellationRepository,
+	scheduleRepo repository.ScheduleRepository,
+	bookingRepo repository.BookingRepository,
+	paymentRepo repository.PaymentRepository,
+	ticketRepo repository.TicketRepository,
+	operatorRepo repository.OperatorRepository,
+	bookingService BookingService,
+	ledgerService LedgerService,
+	notificationService NotificationService,
+) ScheduleCancellationService {
+	return &scheduleCancellationService{
+		cancellationRepo:    cancellationRepo,
+		scheduleRepo:        scheduleRepo,
+		bookingRepo:         bookingRepo,
+		paymentRepo:         paymentRepo,
+		ticketRepo:          ticketRepo,
+		operatorRepo:        operatorRepo,
+		bookingService:      bookingService,
+		ledgerService:       ledgerService,
+		notificationService: notificationService,
+	}
+}
+
+// CancelSchedule cancels a sailing and each of its confirmed bookings, offers
+// every passenger a refund, rebooking or travel credit and notifies them.
+// Part-paid bookings are cancelled too and their deposit is refunded.
+// Each booking is cancelled with its offer in one step, so a run that fails
+// partway is resumed by cancelling again; running it again also refunds
+// passengers whose offer has lapsed.
+func (s *scheduleCancellationService) CancelSchedule(ctx context.Context, scheduleID uuid.UUID, reason string, startedBy *uuid.UUID) (*models.ScheduleCancellationReport, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("schedule not found: %w", err)
+	}
+
+	operator, err := s.operatorRepo.GetByID(ctx, schedule.OperatorID)
+	if err != nil {
+		return nil, fmt.Errorf("operator not found: %w", err)
+	}
+	policy := cancellation.PolicyFromSettings(operator.Settings)
+
+	run, err := s.cancellationRepo.Start(ctx, scheduleID, reason, startedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.process(ctx, run, schedule, policy); err != nil {
+		if finishErr := s.cancellationRepo.Finish(ctx, run.ID, err); finishErr != nil {
+			fmt.Printf("failed to record schedule cancellation failure: %v\n", finishErr)
+		}
+		return nil, fmt.Errorf("schedule cancellation stopped before every booking was handled; cancel again to resume: %w", err)
+	}
+
+	if err := s.cancellationRepo.Finish(ctx, run.ID, nil); err != nil {
+		return nil, err
+	}
+
+	return s.GetReport(ctx, scheduleID)
+}
+
+// process cancels the bookings a run has not reached yet, refunds lapsed
+// offers and notifies every passenger who can still choose and was not yet
+// notified
+func (s *scheduleCancellationService) process(ctx context.Context, run *models.ScheduleCancellation, schedule *models.Schedule, policy cancellation.Policy) error {
+	bookings, err := s.bookingRepo.GetScheduleBookings(ctx, schedule.ID)
+	if err != nil {
+		return err
+	}
+
+	candidates, err := s.cancellationRepo.ListAlternatives(ctx, schedule.RouteID,
+		schedule.DepartureAt.Add(-policy.RebookWindow), schedule.DepartureAt.Add(policy.RebookWindow))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, booking := range bookings {
+		payments, err := s.paymentRepo.ListByBooking(ctx, booking.ID)
+		if err != nil {
+			return fmt.Errorf("booking %s: failed to get payments: %w", booking.BookingReference, err)
+		}
+		if err := tender.CheckCurrency(booking.TotalAmount, payments); err != nil {
+			return fmt.Errorf("booking %s: %w", booking.BookingReference, err)
+		}
+
+		// A booking only part paid for was never confirmed, so it cannot be
+		// moved or credited; its offer lapses at once and the deposit is
+		// refunded below
+		alternatives := []uuid.UUID{}
+		respondBy := now
+		if booking.BookingStatus == "confirmed" {
+			for _, alternative := range cancellation.Alternatives(schedule, candidates, booking.PassengerCount, policy.Alternatives, now) {
+				alternatives = append(alternatives, alternative.ID)
+			}
+			respondBy = now.Add(policy.ResponseWindow)
+		}
+
+		offer := &models.CancellationOffer{
+			CancellationID: run.ID,
+			ScheduleID:     schedule.ID,
+			BookingID:      booking.ID,
+			CustomerID:     booking.CustomerID,
+			PassengerCount: booking.PassengerCount,
+			AmountPaid:     tender.Paid(payments),
+			Alternatives:   alternatives,
+			RespondBy:      respondBy,
+		}
+		if _, err := s.cancellationRepo.CancelBooking(ctx, offer); err != nil {
+			return fmt.Errorf("booking %s: %w", booking.BookingReference, err)
+		}
+	}
+
+	offers, err := s.cancellationRepo.ListOffers(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, offer := range offers {
+		if offer.Status != cancellation.StatusPending || now.Before(offer.RespondBy) {
+			continue
+		}
+		if err := s.refund(ctx, offer, nil); err != nil {
+			return fmt.Errorf("booking %s: failed to refund lapsed offer: %w", offer.BookingReference, err)
+		}
+		offer.Status = cancellation.StatusRefunded
+		s.notifyChoice(ctx, schedule, offer.ID)
+	}
+
+	// Only passengers who can still choose are asked to
+	for _, offer := range offers {
+		if offer.Status != cancellation.StatusPending {
+			continue
+		}
+		if err := s.notificationService.Notify(ctx, cancellationNotice(schedule, run, offer)); err != nil {
+			return fmt.Errorf("booking %s: failed to notify customer: %w", offer.BookingReference, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *scheduleCancellationService) GetReport(ctx context.Context, scheduleID uuid.UUID) (*models.ScheduleCancellationReport, error) {
+	run, err := s.cancellationRepo.Get(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := s.cancellationRepo.ListOffers(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cancellation.Summarize(run, offers), nil
+}
+
+func (s *scheduleCancellationService) GetOffer(ctx context.Context, id uuid.UUID) (*models.CancellationOffer, error) {
+	return s.cancellationRepo.GetOffer(ctx, id)
+}
+
+func (s *scheduleCancellationService) GetCustomerOffers(ctx context.Context, customerID uuid.UUID) ([]*models.CancellationOffer, error) {
+	return s.cancellationRepo.ListCustomerOffers(ctx, customerID)
+}
+
+// ChooseOption carries out the option a passenger chose for their booking on
+// a cancelled sailing. Once the offer has lapsed only a refund can be chosen.
+func (s *scheduleCancellationService) ChooseOption(ctx context.Context, offer *models.CancellationOffer, chosenBy *uuid.UUID, req *models.ChooseCancellationOptionRequest) (*models.CancellationOffer, error) {
+	if offer.Status != cancellation.StatusPending {
+		return nil, fmt.Errorf("an option has already been chosen for this booking")
+	}
+	if req.Option != cancellation.OptionRefund && time.Now().After(offer.RespondBy) {
+		return nil, fmt.Errorf("the offer has lapsed; only a refund is available")
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(ctx, offer.ScheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("schedule not found: %w", err)
+	}
+
+	switch req.Option {
+	case cancellation.OptionRefund:
+		err = s.refund(ctx, offer, chosenBy)
+	case cancellation.OptionCredit:
+		err = s.credit(ctx, offer, schedule, chosenBy)
+	case cancellation.OptionRebook:
+		if req.ScheduleID == nil {
+			return nil, fmt.Errorf("schedule_id is required to rebook")
+		}
+		err = s.rebook(ctx, offer, schedule, *req.ScheduleID, chosenBy)
+	default:
+		err = fmt.Errorf("unknown option %q", req.Option)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.notifyChoice(ctx, schedule, offer.ID), nil
+}
+
+// RefundLapsedOffers refunds the passengers who did not choose before their
+// offer lapsed, as the cancellation notice promised, and tells them. Offers
+// that cannot be refunded are reported and tried again on the next pass;
+// passes may run concurrently since each offer is only claimed once.
+func (s *scheduleCancellationService) RefundLapsedOffers(ctx context.Context, now time.Time) (int, error) {
+	offers, err := s.cancellationRepo.ListLapsedOffers(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	refunded := 0
+	failures := []error{}
+	schedules := map[uuid.UUID]*models.Schedule{}
+	for _, offer := range offers {
+		schedule, ok := schedules[offer.ScheduleID]
+		if !ok {
+			schedule, err = s.scheduleRepo.GetByID(ctx, offer.ScheduleID)
+			if err != nil {
+				failures = append(failures, fmt.Errorf("booking %s: schedule not found: %w", offer.BookingReference, err))
+				continue
+			}
+			schedules[offer.ScheduleID] = schedule
+		}
+
+		if err := s.refund(ctx, offer, nil); err != nil {
+			failures = append(failures, fmt.Errorf("booking %s: %w", offer.BookingReference, err))
+			continue
+		}
+		s.notifyChoice(ctx, schedule, offer.ID)
+		refunded++
+	}
+
+	return refunded, errors.Join(failures...)
+}
+
+// refund refunds everything paid for the booking with reason schedule_change
+func (s *scheduleCancellationService) refund(ctx context.Context, offer *models.CancellationOffer, chosenBy *uuid.UUID) error {
+	if err := s.cancellationRepo.Claim(ctx, offer.ID, cancellation.StatusRefunded, chosenBy); err != nil {
+		return err
+	}
+
+	if err := s.bookingService.RefundCancelledBooking(ctx, offer.BookingID, "schedule_change"); err != nil {
+		if releaseErr := s.cancellationRepo.Release(ctx, offer.ID); releaseErr != nil {
+			fmt.Printf("failed to release cancellation offer: %v\n", releaseErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// credit gives each passenger their share of what was paid as travel credit
+func (s *scheduleCancellationService) credit(ctx context.Context, offer *models.CancellationOffer, schedule *models.Schedule, chosenBy *uuid.UUID) error {
+	operator, err := s.operatorRepo.GetByID(ctx, schedule.OperatorID)
+	if err != nil {
+		return fmt.Errorf("operator not found: %w", err)
+	}
+	policy := cancellation.PolicyFromSettings(operator.Settings)
+
+	tickets, err := s.ticketRepo.GetByBooking(ctx, offer.BookingID)
+	if err != nil {
+		return err
+	}
+
+	fares := make([]money.Money, len(tickets))
+	for i, ticket := range tickets {
+		fares[i] = ticket.TicketPrice
+	}
+
+	expiresAt := time.Now().Add(policy.CreditValidity)
+	credits := []*models.TravelCredit{}
+	for i, amount := range cancellation.SplitCredit(offer.AmountPaid, fares) {
+		if !amount.IsPositive() {
+			continue
+		}
+		credits = append(credits, &models.TravelCredit{
+			OperatorID: schedule.OperatorID,
+			CustomerID: offer.CustomerID,
+			BookingID:  offer.BookingID,
+			TicketID:   tickets[i].ID,
+			Amount:     amount,
+			ExpiresAt:  expiresAt,
+		})
+	}
+	if len(credits) == 0 {
+		return fmt.Errorf("nothing was paid for this booking, so there is no credit to give")
+	}
+
+	if err := s.cancellationRepo.IssueCredits(ctx, offer.ID, chosenBy, credits); err != nil {
+		return err
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, offer.BookingID)
+	if err != nil {
+		fmt.Printf("failed to post cancellation credit to ledger: %v\n", err)
+		return nil
+	}
+	for _, credit := range credits {
+		if err := s.ledgerService.PostCancellationCredit(ctx, booking, credit); err != nil {
+			// Non-critical error, log but don't fail
+			fmt.Printf("failed to post cancellation credit to ledger: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// rebook moves the booking onto another sailing of the same route at no
+// extra charge. Tickets are reissued for the new sailing without seats.
+func (s *scheduleCancellationService) rebook(ctx context.Context, offer *models.CancellationOffer, cancelled *models.Schedule, scheduleID uuid.UUID, chosenBy *uuid.UUID) error {
+	target, err := s.scheduleRepo.GetByID(ctx, scheduleID)
+	if err != nil {
+		return fmt.Errorf("schedule not found: %w", err)
+	}
+
+	if target.RouteID != cancelled.RouteID || target.OperatorID != cancelled.OperatorID {
+		return fmt.Errorf("bookings can only be moved to a sailing on the same route")
+	}
+	if target.Status != "scheduled" || target.Unpublished || !target.DepartureAt.After(time.Now()) {
+		return fmt.Errorf("the chosen sailing is not open for booking")
+	}
+
+	tickets, err := s.ticketRepo.GetByBooking(ctx, offer.BookingID)
+	if err != nil {
+		return err
+	}
+
+	// Rebooked passengers lose their seats
+	for _, ticket := range tickets {
+		ticket.SeatNumber = nil
+	}
+
+	codes, err := s.bookingService.ReissueTicketCodes(tickets, target)
+	if err != nil {
+		return err
+	}
+
+	return s.cancellationRepo.Rebook(ctx, offer.ID, target.ID, chosenBy, codes)
+}
+
+// notifyChoice tells the passenger what was done with their booking and
+// returns the offer as it now stands
+func (s *scheduleCancellationService) notifyChoice(ctx context.Context, schedule *models.Schedule, offerID uuid.UUID) *models.CancellationOffer {
+	offer, err := s.cancellationRepo.GetOffer(ctx, offerID)
+	if err != nil {
+		fmt.Printf("failed to get cancellation offer: %v\n", err)
+		return nil
+	}
+
+	var outcome string
+	switch offer.Status {
+	case cancellation.StatusRefunded:
+		outcome = fmt.Sprintf("%s is being refunded to the way you paid.", offer.AmountPaid.Format())
+	case cancellation.StatusCredited:
+		outcome = fmt.Sprintf("%s has been given to you as travel credit.", offer.AmountPaid.Format())
+	case cancellation.StatusRebooked:
+		outcome = "It has been moved to the sailing you chose and new tickets have been issued."
+	default:
+		return offer
+	}
+
+	notification := &models.Notification{
+		UserID:  offer.CustomerID,
+		Kind:    "cancellation_option_chosen",
+		Subject: fmt.Sprintf("Booking %s: your cancelled sailing", offer.BookingReference),
+		Body: fmt.Sprintf("Your booking %s was on the sailing of %s, which was cancelled. %s",
+			offer.BookingReference, sailingTime(schedule), outcome),
+		Data: map[string]interface{}{
+			"offer_id":   offer.ID,
+			"booking_id": offer.BookingID,
+			"status":     offer.Status,
+		},
+		DedupeKey: fmt.Sprintf("cancellation_offer:%s:%s", offer.ID, offer.Status),
+	}
+	if offer.RebookedScheduleID != nil {
+		notification.Data["schedule_id"] = *offer.RebookedScheduleID
+	}
+
+	if err := s.notificationService.Notify(ctx, notification); err != nil {
+		// Non-critical error, log but don't fail
+		fmt.Printf("failed to notify customer: %v\n", err)
+	}
+
+	return offer
+}
+
+// cancellationNotice tells a passenger their sailing was cancelled and what
+// they can choose
+func cancellationNotice(schedule *models.Schedule, run *models.ScheduleCancellation, offer *models.CancellationOffer) *models.Notification {
+	return &models.Notification{
+		UserID:  offer.CustomerID,
+		Kind:    "schedule_cancelled",
+		Subject: fmt.Sprintf("Your sailing of %s has been cancelled", sailingTime(schedule)),
+		Body: fmt.Sprintf("Your booking %s has been cancelled because the sailing was cancelled: %s. "+
+			"Choose a refund, a place on another sailing or travel credit by %s. "+
+			"If you have not chosen by then, you will be refunded.",
+			offer.BookingReference, run.Reason, offer.RespondBy.UTC().Format("2 Jan 2006 15:04 MST")),
+		Data: map[string]interface{}{
+			"offer_id":     offer.ID,
+			"booking_id":   offer.BookingID,
+			"schedule_id":  schedule.ID,
+			"alternatives": offer.Alternatives,
+			"respond_by":   offer.RespondBy,
+		},
+		DedupeKey: fmt.Sprintf("schedule_cancelled:%s", offer.ID),
+	}
+}
+
+// sailingTime formats a sailing's departure in its departure port's time
+func sailingTime(schedule *models.Schedule) string {
+	departs := schedule.DepartureAt
+	if schedule.DepartureLocal != nil {
+		departs = *schedule.DepartureLocal
+	}
+	return departs.Format("Mon 2 Jan 2006 15:04")
+}
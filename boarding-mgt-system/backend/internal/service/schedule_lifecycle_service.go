@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/lifecycle"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ScheduleLifecycleService interface {
+	Advance(ctx context.Context, now time.Time) (*models.LifecycleRun, error)
+	OpenBoarding(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error)
+	ConfirmDeparture(ctx context.Context, schedule *models.Schedule, departedAt *time.Time) (*models.Schedule, error)
+	ConfirmArrival(ctx context.Context, schedule *models.Schedule, arrivedAt *time.Time) (*models.Schedule, error)
+	Transition(ctx context.Context, schedule *models.Schedule, to string) error
+}
+
+type scheduleLifecycleService struct {
+	scheduleRepo    repository.ScheduleRepository
+	operatorRepo    repository.OperatorRepository
+	manifestService ManifestService
+	ledgerService   LedgerService
+	noShowService   NoShowService
+}
+
+func NewScheduleLifecycleService(
+	scheduleRepo repository.ScheduleRepository,
+	operatorRepo repository.OperatorRepository,
+	manifestService ManifestService,
+	ledgerService LedgerService,
+	noShowService NoShowService,
+) ScheduleLifecycleService {
+	return &scheduleLifecycleService{
+		scheduleRepo:    scheduleRepo,
+		operatorRepo:    operatorRepo,
+		manifestService: manifestService,
+		ledgerService:   ledgerService,
+		noShowService:   noShowService,
+	}
+}
+
+// Advance opens boarding on and departs the sailings due at now under their
+// operator's lifecycle policy. A sailing that cannot move, such as one whose
+// manifest blocks departure, is reported and tried again on the next pass.
+// Passes may run concurrently: each move only happens once.
+func (s *scheduleLifecycleService) Advance(ctx context.Context, now time.Time) (*models.LifecycleRun, error) {
+	due, err := s.scheduleRepo.ListLifecycleDue(ctx, now.Add(-lifecycle.CatchUp), now.Add(lifecycle.MaxBoardingOpensBefore))
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.LifecycleRun{At: now, Failures: []models.LifecycleFailure{}}
+	policies := map[uuid.UUID]lifecycle.Policy{}
+	for _, candidate := range due {
+		policy, ok := policies[candidate.OperatorID]
+		if !ok {
+			operator, err := s.operatorRepo.GetByID(ctx, candidate.OperatorID)
+			if err != nil {
+				return nil, fmt.Errorf("operator not found: %w", err)
+			}
+			policy = lifecycle.PolicyFromSettings(operator.Settings)
+			policies[candidate.OperatorID] = policy
+		}
+
+		to := policy.Due(candidate, now)
+		if to == "" {
+			continue
+		}
+
+		schedule, err := s.scheduleRepo.GetByID(ctx, candidate.ID)
+		if err == nil {
+			err = s.Transition(ctx, schedule, to)
+		}
+		if err != nil {
+			run.Failures = append(run.Failures, models.LifecycleFailure{ScheduleID: candidate.ID, Status: to, Error: err.Error()})
+			continue
+		}
+
+		if to == lifecycle.StatusBoarding {
+			run.BoardingOpened++
+		} else {
+			run.Departed++
+		}
+	}
+
+	return run, nil
+}
+
+// OpenBoarding opens boarding on a scheduled sailing ahead of its operator's
+// policy
+func (s *scheduleLifecycleService) OpenBoarding(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	if err := s.Transition(ctx, schedule, lifecycle.StatusBoarding); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// ConfirmDeparture records when a sailing left, departing it if it has not
+// departed yet or correcting its departure time if it has
+func (s *scheduleLifecycleService) ConfirmDeparture(ctx context.Context, schedule *models.Schedule, departedAt *time.Time) (*models.Schedule, error) {
+	at, err := confirmedAt(departedAt)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.ActualArrivalAt != nil && !at.Before(*schedule.ActualArrivalAt) {
+		return nil, fmt.Errorf("departure must be before the sailing's arrival")
+	}
+
+	if schedule.Status == lifecycle.StatusDeparted {
+		if err := s.scheduleRepo.Transition(ctx, schedule, lifecycle.StatusDeparted, &at); err != nil {
+			return nil, err
+		}
+		return schedule, nil
+	}
+
+	if err := s.transition(ctx, schedule, lifecycle.StatusDeparted, &at); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// ConfirmArrival records when a departed sailing arrived
+func (s *scheduleLifecycleService) ConfirmArrival(ctx context.Context, schedule *models.Schedule, arrivedAt *time.Time) (*models.Schedule, error) {
+	at, err := confirmedAt(arrivedAt)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.ActualDepartureAt != nil && !at.After(*schedule.ActualDepartureAt) {
+		return nil, fmt.Errorf("arrival must be after the sailing's departure")
+	}
+
+	if err := s.transition(ctx, schedule, lifecycle.StatusArrived, &at); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// Transition moves a sailing to another status, running the side effects
+// of the move. A move the lifecycle does not allow is refused with a
+// lifecycle.TransitionError.
+func (s *scheduleLifecycleService) Transition(ctx context.Context, schedule *models.Schedule, to string) error {
+	return s.transition(ctx, schedule, to, nil)
+}
+
+func (s *scheduleLifecycleService) transition(ctx context.Context, schedule *models.Schedule, to string, at *time.Time) error {
+	if err := lifecycle.Check(schedule.Status, to); err != nil {
+		return err
+	}
+
+	// Port authorities need identity details for everyone aboard; operators
+	// may refuse to let a sailing depart without them
+	if to == lifecycle.StatusDeparted {
+		completeness, err := s.manifestService.CheckCompleteness(ctx, schedule.ID)
+		if err != nil {
+			return err
+		}
+		if completeness.BlocksDeparture {
+			return fmt.Errorf("cannot depart: %d passengers are missing mandatory manifest details", len(completeness.Incomplete))
+		}
+	}
+
+	// Sales close once a sailing leaves the scheduled status, as bookings
+	// are only taken on scheduled sailings
+	if err := s.scheduleRepo.Transition(ctx, schedule, to, at); err != nil {
+		return err
+	}
+
+	// Fares held as deferred revenue are earned once the sailing departs
+	if to == lifecycle.StatusDeparted {
+		if err := s.ledgerService.PostDeparture(ctx, schedule); err != nil {
+			// Non-critical error, log but don't fail
+			fmt.Printf("failed to post departure to ledger: %v\n", err)
+		}
+
+		// Tickets that never boarded become no-shows and the final counts
+		// are recorded; this can be rerun from the schedule if it fails
+		if _, err := s.noShowService.ProcessDeparture(ctx, schedule); err != nil {
+			fmt.Printf("failed to process no-shows: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// confirmedAt is the time staff confirmed a departure or arrival at, now
+// unless given. It cannot be in the future.
+func confirmedAt(at *time.Time) (time.Time, error) {
+	now := time.Now()
+	if at == nil {
+		return now, nil
+	}
+	if at.After(now) {
+		return time.Time{}, fmt.Errorf("a sailing cannot be confirmed to have moved in the future")
+	}
+	return *at, nil
+}
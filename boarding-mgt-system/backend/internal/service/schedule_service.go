@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ferryflow/boarding-mgt-system/internal/lifecycle"
 	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/porttime"
 	"github.com/ferryflow/boarding-mgt-system/internal/repository"
 	"github.com/google/uuid"
 )
@@ -21,16 +23,24 @@ type ScheduleService interface {
 }
 
 type scheduleService struct {
-	scheduleRepo repository.ScheduleRepository
-	routeRepo    repository.RouteRepository
-	vesselRepo   repository.VesselRepository
+	scheduleRepo        repository.ScheduleRepository
+	timetableRepo       repository.TimetableVersionRepository
+	routeRepo           repository.RouteRepository
+	vesselRepo          repository.VesselRepository
+	portRepo            repository.PortRepository
+	lifecycleService    ScheduleLifecycleService
+	cancellationService ScheduleCancellationService
 }
 
-func NewScheduleService(scheduleRepo repository.ScheduleRepository, routeRepo repository.RouteRepository, vesselRepo repository.VesselRepository) ScheduleService {
+func NewScheduleService(scheduleRepo repository.ScheduleRepository, timetableRepo repository.TimetableVersionRepository, routeRepo repository.RouteRepository, vesselRepo repository.VesselRepository, portRepo repository.PortRepository, lifecycleService ScheduleLifecycleService, cancellationService ScheduleCancellationService) ScheduleService {
 	return &scheduleService{
-		scheduleRepo: scheduleRepo,
-		routeRepo:    routeRepo,
-		vesselRepo:   vesselRepo,
+		scheduleRepo:        scheduleRepo,
+		timetableRepo:       timetableRepo,
+		routeRepo:           routeRepo,
+		vesselRepo:          vesselRepo,
+		portRepo:            portRepo,
+		lifecycleService:    lifecycleService,
+		cancellationService: cancellationService,
 	}
 }
 
@@ -51,6 +61,10 @@ func (s *scheduleService) CreateSchedule(ctx context.Context, req *models.Create
 		return nil, fmt.Errorf("vessel does not belong to operator")
 	}
 
+	if req.BasePrice.IsNegative() {
+		return nil, fmt.Errorf("base price cannot be negative")
+	}
+
 	// Parse dates and times
 	departureDate, err := time.Parse("2006-01-02", req.DepartureDate)
 	if err != nil {
@@ -80,6 +94,24 @@ func (s *scheduleService) CreateSchedule(ctx context.Context, req *models.Create
 		Status:         "scheduled",
 	}
 
+	// Sailings drafted in a timetable version stay off sale until it is
+	// published
+	if req.TimetableVersionID != nil {
+		version, err := s.draftVersion(ctx, *req.TimetableVersionID, departureDate)
+		if err != nil {
+			return nil, err
+		}
+		if version.OperatorID != req.OperatorID {
+			return nil, fmt.Errorf("timetable version does not belong to operator")
+		}
+		schedule.TimetableVersionID = &version.ID
+		schedule.Unpublished = true
+	}
+
+	if err := s.setInstants(ctx, schedule, route); err != nil {
+		return nil, err
+	}
+
 	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
 		return nil, fmt.Errorf("failed to create schedule: %w", err)
 	}
@@ -112,6 +144,12 @@ func (s *scheduleService) UpdateSchedule(ctx context.Context, id uuid.UUID, req
 		return nil, fmt.Errorf("cannot modify schedule with status %s", schedule.Status)
 	}
 
+	if req.Status != nil && *req.Status != schedule.Status {
+		if err := lifecycle.Check(schedule.Status, *req.Status); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update fields if provided
 	if req.DepartureDate != nil {
 		departureDate, err := time.Parse("2006-01-02", *req.DepartureDate)
@@ -121,6 +159,14 @@ func (s *scheduleService) UpdateSchedule(ctx context.Context, id uuid.UUID, req
 		schedule.DepartureDate = departureDate
 	}
 
+	// Sailings off sale can only be changed while their timetable version
+	// is a draft
+	if schedule.Unpublished && schedule.TimetableVersionID != nil {
+		if _, err := s.draftVersion(ctx, *schedule.TimetableVersionID, schedule.DepartureDate); err != nil {
+			return nil, err
+		}
+	}
+
 	if req.DepartureTime != nil {
 		departureTime, err := time.Parse("15:04", *req.DepartureTime)
 		if err != nil {
@@ -138,27 +184,40 @@ func (s *scheduleService) UpdateSchedule(ctx context.Context, id uuid.UUID, req
 	}
 
 	if req.BasePrice != nil {
+		if req.BasePrice.IsNegative() {
+			return nil, fmt.Errorf("base price cannot be negative")
+		}
 		schedule.BasePrice = *req.BasePrice
 	}
 
-	if req.Status != nil {
-		schedule.Status = *req.Status
+	if req.DepartureDate != nil || req.DepartureTime != nil || req.ArrivalTime != nil {
+		if err := s.setInstants(ctx, schedule, schedule.Route); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
 		return nil, fmt.Errorf("failed to update schedule: %w", err)
 	}
 
+	// Status changes go through the lifecycle, which refuses illegal moves
+	// and departs the sailing as staff confirming its departure would
+	if req.Status != nil && *req.Status != schedule.Status {
+		if err := s.lifecycleService.Transition(ctx, schedule, *req.Status); err != nil {
+			return nil, err
+		}
+	}
+
 	return schedule, nil
 }
 
 func (s *scheduleService) CancelSchedule(ctx context.Context, id uuid.UUID, reason string) error {
-	if err := s.scheduleRepo.UpdateStatus(ctx, id, "cancelled", &reason); err != nil {
+	// Bookings are cancelled and their passengers offered a refund,
+	// rebooking or credit as part of cancelling the sailing
+	if _, err := s.cancellationService.CancelSchedule(ctx, id, reason, nil); err != nil {
 		return fmt.Errorf("failed to cancel schedule: %w", err)
 	}
 
-	// TODO: Notify affected passengers and process refunds
-
 	return nil
 }
 
@@ -202,4 +261,64 @@ func (s *scheduleService) GetUpcomingSchedules(ctx context.Context, limit int) (
 	}
 
 	return schedules, nil
-}
\ No newline at end of file
+}
+
+// setInstants works out a schedule's departure and arrival instants from its
+// local times in its route's port time zones
+func (s *scheduleService) setInstants(ctx context.Context, schedule *models.Schedule, route *models.Route) error {
+	departureZone, arrivalZone, err := routeZones(ctx, s.portRepo, route)
+	if err != nil {
+		return err
+	}
+
+	schedule.DepartureAt, schedule.ArrivalAt, err = porttime.Sailing(
+		schedule.DepartureDate, schedule.DepartureTime, schedule.ArrivalTime, departureZone, arrivalZone,
+	)
+	if err != nil {
+		return err
+	}
+
+	porttime.Localize(schedule, departureZone.String(), arrivalZone.String())
+	return nil
+}
+
+// draftVersion loads a timetable version sailings are being drafted in and
+// checks it is still a draft and its season covers the departure date
+func (s *scheduleService) draftVersion(ctx context.Context, id uuid.UUID, departureDate time.Time) (*models.TimetableVersion, error) {
+	version, err := s.timetableRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version.Status != "draft" {
+		return nil, fmt.Errorf("sailings can only be changed while their timetable version is a draft, this one is %s", version.Status)
+	}
+	if departureDate.Before(version.ValidFrom) || departureDate.After(version.ValidUntil) {
+		return nil, fmt.Errorf("departure date is outside the timetable version's season")
+	}
+
+	return version, nil
+}
+
+// routeZones loads the time zones of a route's departure and arrival ports
+func routeZones(ctx context.Context, portRepo repository.PortRepository, route *models.Route) (*time.Location, *time.Location, error) {
+	departurePort, err := portRepo.GetByID(ctx, route.DeparturePortID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get departure port: %w", err)
+	}
+	departureZone, err := porttime.LoadZone(departurePort.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("departure port %s: %w", departurePort.Code, err)
+	}
+
+	arrivalPort, err := portRepo.GetByID(ctx, route.ArrivalPortID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get arrival port: %w", err)
+	}
+	arrivalZone, err := porttime.LoadZone(arrivalPort.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("arrival port %s: %w", arrivalPort.Code, err)
+	}
+
+	return departureZone, arrivalZone, nil
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/porttime"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/timetable"
+	"github.com/google/uuid"
+)
+
+// maxGenerationDays limits how far ahead a template run may generate schedules
+const maxGenerationDays = 400
+
+type ScheduleTemplateService interface {
+	CreateTemplate(ctx context.Context, req *models.CreateScheduleTemplateRequest) (*models.ScheduleTemplate, error)
+	GetTemplate(ctx context.Context, id uuid.UUID) (*models.ScheduleTemplate, error)
+	ListTemplates(ctx context.Context, operatorID *uuid.UUID) ([]*models.ScheduleTemplate, error)
+	UpdateTemplate(ctx context.Context, id uuid.UUID, req *models.UpdateScheduleTemplateRequest) (*models.ScheduleTemplate, *models.ScheduleGeneration, error)
+	Generate(ctx context.Context, id uuid.UUID, until time.Time, dryRun bool) (*models.ScheduleGeneration, error)
+}
+
+type scheduleTemplateService struct {
+	templateRepo repository.ScheduleTemplateRepository
+	routeRepo    repository.RouteRepository
+	vesselRepo   repository.VesselRepository
+	portRepo     repository.PortRepository
+	operatorRepo repository.OperatorRepository
+}
+
+func NewScheduleTemplateService(
+	templateRepo repository.ScheduleTemplateRepository,
+	routeRepo repository.RouteRepository,
+	vesselRepo repository.VesselRepository,
+	portRepo repository.PortRepository,
+	operatorRepo repository.OperatorRepository,
+) ScheduleTemplateService {
+	return &scheduleTemplateService{
+		templateRepo: templateRepo,
+		routeRepo:    routeRepo,
+		vesselRepo:   vesselRepo,
+		portRepo:     portRepo,
+		operatorRepo: operatorRepo,
+	}
+}
+
+func (s *scheduleTemplateService) CreateTemplate(ctx context.Context, req *models.CreateScheduleTemplateRequest) (*models.ScheduleTemplate, error) {
+	if _, err := s.routeRepo.GetByID(ctx, req.RouteID); err != nil {
+		return nil, fmt.Errorf("route not found: %w", err)
+	}
+
+	if err := s.checkVessel(ctx, req.VesselID, req.OperatorID); err != nil {
+		return nil, err
+	}
+
+	if req.BasePrice.IsNegative() {
+		return nil, fmt.Errorf("base price cannot be negative")
+	}
+
+	template := &models.ScheduleTemplate{
+		OperatorID:   req.OperatorID,
+		RouteID:      req.RouteID,
+		VesselID:     req.VesselID,
+		Name:         req.Name,
+		BasePrice:    req.BasePrice,
+		Weekdays:     req.Weekdays,
+		Departures:   req.Departures,
+		SkipHolidays: req.SkipHolidays,
+	}
+	if template.Weekdays == nil {
+		template.Weekdays = []int{}
+	}
+
+	var err error
+	if template.ValidFrom, err = time.Parse(timetable.DateFormat, req.ValidFrom); err != nil {
+		return nil, fmt.Errorf("invalid valid_from date format: %w", err)
+	}
+	if req.ValidUntil != nil {
+		validUntil, err := time.Parse(timetable.DateFormat, *req.ValidUntil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid valid_until date format: %w", err)
+		}
+		template.ValidUntil = &validUntil
+	}
+	if template.ExceptionDates, err = parseDates(req.ExceptionDates); err != nil {
+		return nil, err
+	}
+
+	if err := timetable.Validate(template); err != nil {
+		return nil, err
+	}
+
+	if err := s.templateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *scheduleTemplateService) GetTemplate(ctx context.Context, id uuid.UUID) (*models.ScheduleTemplate, error) {
+	return s.templateRepo.GetByID(ctx, id)
+}
+
+func (s *scheduleTemplateService) ListTemplates(ctx context.Context, operatorID *uuid.UUID) ([]*models.ScheduleTemplate, error) {
+	return s.templateRepo.List(ctx, operatorID)
+}
+
+// UpdateTemplate changes a template and brings the future, unbooked schedules
+// already generated from it in line
+func (s *scheduleTemplateService) UpdateTemplate(ctx context.Context, id uuid.UUID, req *models.UpdateScheduleTemplateRequest) (*models.ScheduleTemplate, *models.ScheduleGeneration, error) {
+	template, err := s.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if req.VesselID != nil {
+		if err := s.checkVessel(ctx, *req.VesselID, template.OperatorID); err != nil {
+			return nil, nil, err
+		}
+		template.VesselID = *req.VesselID
+	}
+	if req.Name != nil {
+		template.Name = *req.Name
+	}
+	if req.BasePrice != nil {
+		if req.BasePrice.IsNegative() {
+			return nil, nil, fmt.Errorf("base price cannot be negative")
+		}
+		template.BasePrice = *req.BasePrice
+	}
+	if req.ValidFrom != nil {
+		if template.ValidFrom, err = time.Parse(timetable.DateFormat, *req.ValidFrom); err != nil {
+			return nil, nil, fmt.Errorf("invalid valid_from date format: %w", err)
+		}
+	}
+	if req.ValidUntil != nil {
+		template.ValidUntil = nil
+		if *req.ValidUntil != "" {
+			validUntil, err := time.Parse(timetable.DateFormat, *req.ValidUntil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid valid_until date format: %w", err)
+			}
+			template.ValidUntil = &validUntil
+		}
+	}
+	if req.Weekdays != nil {
+		template.Weekdays = req.Weekdays
+	}
+	if req.Departures != nil {
+		template.Departures = req.Departures
+	}
+	if req.ExceptionDates != nil {
+		if template.ExceptionDates, err = parseDates(req.ExceptionDates); err != nil {
+			return nil, nil, err
+		}
+	}
+	if req.SkipHolidays != nil {
+		template.SkipHolidays = *req.SkipHolidays
+	}
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+
+	if err := timetable.Validate(template); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.templateRepo.Update(ctx, template); err != nil {
+		return nil, nil, err
+	}
+
+	if template.GeneratedUntil == nil || template.GeneratedUntil.Before(today()) {
+		return template, nil, nil
+	}
+
+	generation, err := s.generate(ctx, template, *template.GeneratedUntil, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("template saved but its schedules could not be updated: %w", err)
+	}
+
+	return template, generation, nil
+}
+
+// Generate creates a template's schedules from today up to a date, brings
+// existing unbooked ones up to date and removes those the template no longer
+// calls for. Running it again changes nothing. A dry run only reports what
+// would change.
+func (s *scheduleTemplateService) Generate(ctx context.Context, id uuid.UUID, until time.Time, dryRun bool) (*models.ScheduleGeneration, error) {
+	template, err := s.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if until.After(today().AddDate(0, 0, maxGenerationDays)) {
+		return nil, fmt.Errorf("schedules can be generated at most %d days ahead", maxGenerationDays)
+	}
+
+	return s.generate(ctx, template, until, dryRun)
+}
+
+func (s *scheduleTemplateService) generate(ctx context.Context, template *models.ScheduleTemplate, until time.Time, dryRun bool) (*models.ScheduleGeneration, error) {
+	from := today()
+	if template.ValidFrom.After(from) {
+		from = template.ValidFrom
+	}
+	if until.Before(from) {
+		return nil, fmt.Errorf("until must not be before %s", from.Format(timetable.DateFormat))
+	}
+
+	vessel, err := s.vesselRepo.GetByID(ctx, template.VesselID)
+	if err != nil {
+		return nil, fmt.Errorf("vessel not found: %w", err)
+	}
+
+	holidays := map[string]bool{}
+	if template.SkipHolidays {
+		operator, err := s.operatorRepo.GetByID(ctx, template.OperatorID)
+		if err != nil {
+			return nil, fmt.Errorf("operator not found: %w", err)
+		}
+		holidays = timetable.HolidaysFromSettings(operator.Settings)
+	}
+
+	// An inactive template calls for no departures, so its unbooked
+	// schedules are removed
+	occurrences := []timetable.Occurrence{}
+	if template.IsActive {
+		occurrences, err = timetable.Occurrences(template, from, until, holidays)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	instances, err := s.templateRepo.ListInstances(ctx, template.ID, from, until)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := timetable.Diff(template, vessel.Capacity, occurrences, instances)
+
+	route, err := s.routeRepo.GetByID(ctx, template.RouteID)
+	if err != nil {
+		return nil, fmt.Errorf("route not found: %w", err)
+	}
+	departureZone, arrivalZone, err := routeZones(ctx, s.portRepo, route)
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range append(append([]*models.Schedule{}, plan.Create...), plan.Update...) {
+		schedule.DepartureAt, schedule.ArrivalAt, err = porttime.Sailing(
+			schedule.DepartureDate, schedule.DepartureTime, schedule.ArrivalTime, departureZone, arrivalZone,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("departure on %s: %w", schedule.DepartureDate.Format(timetable.DateFormat), err)
+		}
+		porttime.Localize(schedule, departureZone.String(), arrivalZone.String())
+	}
+
+	if !dryRun {
+		if err := s.templateRepo.ApplyPlan(ctx, template, plan, until); err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.ScheduleGeneration{
+		TemplateID: template.ID,
+		DryRun:     dryRun,
+		From:       from,
+		Until:      until,
+		Created:    plan.Create,
+		Updated:    plan.Update,
+		Removed:    plan.Remove,
+		Kept:       plan.Keep,
+		Unchanged:  plan.Unchanged,
+	}, nil
+}
+
+// checkVessel checks a vessel exists and belongs to the operator
+func (s *scheduleTemplateService) checkVessel(ctx context.Context, vesselID, operatorID uuid.UUID) error {
+	vessel, err := s.vesselRepo.GetByID(ctx, vesselID)
+	if err != nil {
+		return fmt.Errorf("vessel not found: %w", err)
+	}
+
+	if vessel.OperatorID != operatorID {
+		return fmt.Errorf("vessel does not belong to operator")
+	}
+
+	return nil
+}
+
+// today is the current UTC date
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func parseDates(values []string) ([]time.Time, error) {
+	dates := []time.Time{}
+	for _, value := range values {
+		date, err := time.Parse(timetable.DateFormat, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", value)
+		}
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
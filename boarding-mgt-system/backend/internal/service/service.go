@@ -2,31 +2,91 @@ package service
 
 import (
 	"github.com/ferryflow/boarding-mgt-system/internal/auth"
+	"github.com/ferryflow/boarding-mgt-system/internal/boardingfeed"
 	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/ticketqr"
+	"github.com/ferryflow/boarding-mgt-system/internal/walletpass"
+	"github.com/ferryflow/boarding-mgt-system/internal/weather"
 )
 
 // Services holds all service interfaces
 type Services struct {
-	Auth     AuthService
-	User     UserService
-	Operator OperatorService
-	Port     PortService
-	Vessel   VesselService
-	Route    RouteService
-	Schedule ScheduleService
-	Booking  BookingService
+	Auth         AuthService
+	User         UserService
+	Operator     OperatorService
+	Port         PortService
+	Vessel       VesselService
+	Route        RouteService
+	Schedule     ScheduleService
+	Lifecycle    ScheduleLifecycleService
+	Template     ScheduleTemplateService
+	Timetable    TimetableVersionService
+	Booking      BookingService
+	Ticket       TicketService
+	Gate         GateService
+	Feed         BoardingFeedService
+	Scanner      ScannerService
+	Wallet       WalletService
+	Manifest     ManifestService
+	NoShow       NoShowService
+	Cancellation ScheduleCancellationService
+	Notification NotificationService
+	Disruption   DisruptionService
+	GTFS         GTFSService
+	Shift        ShiftService
+	Settlement   SettlementService
+	Ledger       LedgerService
+	Invoice      InvoiceService
+	Agency       AgencyService
+	Weather      WeatherService
 }
 
-// NewServices creates all service instances
-func NewServices(repos *repository.Repositories, jwtUtil *auth.JWTUtil) *Services {
+// NewServices creates all service instances. Ticket QR codes are signed and
+// verified with qrKeys, wallet passes issued by wallet, if it is not nil,
+// live boarding events sent through broker and sailings checked against the
+// forecasts, if it is not nil.
+func NewServices(repos *repository.Repositories, jwtUtil *auth.JWTUtil, qrKeys *ticketqr.Keyring, wallet *walletpass.Issuer, broker boardingfeed.Broker, forecasts weather.Provider) *Services {
+	ledger := NewLedgerService(repos.Ledger, repos.Operator)
+	invoice := NewInvoiceService(repos.Invoice, repos.Booking, repos.Schedule, repos.Ticket, repos.Operator, repos.User)
+	manifest := NewManifestService(repos.Ticket, repos.Schedule, repos.Operator)
+	noShow := NewNoShowService(repos.NoShow, repos.Schedule, repos.Operator, ledger)
+	feed := NewBoardingFeedService(repos.Boarding, repos.Port, broker)
+	ticket := NewTicketService(repos.Ticket, repos.Booking, repos.Schedule, repos.Port, repos.Operator, qrKeys)
+	booking := NewBookingService(repos.Booking, repos.Schedule, repos.Ticket, repos.Payment, repos.Shift, repos.Agency, repos.User, ledger, invoice, qrKeys.Signer())
+	notification := NewNotificationService(repos.Notification)
+	cancellation := NewScheduleCancellationService(repos.Cancellation, repos.Schedule, repos.Booking, repos.Payment, repos.Ticket, repos.Operator, booking, ledger, notification)
+	walletPasses := NewWalletService(repos.Wallet, ticket, wallet)
+	lifecycle := NewScheduleLifecycleService(repos.Schedule, repos.Operator, manifest, ledger, noShow)
+	disruption := NewDisruptionService(repos.Disruption, repos.Schedule, repos.Vessel, repos.Operator, booking, notification, walletPasses)
+
 	return &Services{
-		Auth:     NewAuthService(repos.User, jwtUtil),
-		User:     NewUserService(repos.User),
-		Operator: NewOperatorService(repos.Operator),
-		Port:     NewPortService(repos.Port),
-		Vessel:   NewVesselService(repos.Vessel, repos.Operator),
-		Route:    NewRouteService(repos.Route, repos.Port),
-		Schedule: NewScheduleService(repos.Schedule, repos.Route, repos.Vessel),
-		Booking:  NewBookingService(repos.Booking, repos.Schedule, repos.Ticket, repos.Payment),
+		Auth:         NewAuthService(repos.User, jwtUtil),
+		User:         NewUserService(repos.User),
+		Operator:     NewOperatorService(repos.Operator),
+		Port:         NewPortService(repos.Port),
+		Vessel:       NewVesselService(repos.Vessel, repos.Operator),
+		Route:        NewRouteService(repos.Route, repos.Port),
+		Schedule:     NewScheduleService(repos.Schedule, repos.Timetable, repos.Route, repos.Vessel, repos.Port, lifecycle, cancellation),
+		Lifecycle:    lifecycle,
+		Template:     NewScheduleTemplateService(repos.Template, repos.Route, repos.Vessel, repos.Port, repos.Operator),
+		Timetable:    NewTimetableVersionService(repos.Timetable),
+		Booking:      booking,
+		Ticket:       ticket,
+		Gate:         NewGateService(repos.Boarding, feed, qrKeys),
+		Feed:         feed,
+		Scanner:      NewScannerService(repos.Scanner, repos.Boarding, repos.User, feed, qrKeys),
+		Wallet:       walletPasses,
+		Manifest:     manifest,
+		NoShow:       noShow,
+		Cancellation: cancellation,
+		Notification: notification,
+		Disruption:   disruption,
+		GTFS:         NewGTFSService(repos.GTFS, repos.Route, repos.Port, repos.Vessel, repos.Operator),
+		Shift:        NewShiftService(repos.Shift, repos.User),
+		Settlement:   NewSettlementService(repos.Settlement),
+		Ledger:       ledger,
+		Invoice:      invoice,
+		Agency:       NewAgencyService(repos.Agency, repos.User),
+		Weather:      NewWeatherService(repos.Weather, repos.Schedule, forecasts, disruption, cancellation),
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/settlement"
+	"github.com/google/uuid"
+)
+
+type SettlementService interface {
+	ListProviders(ctx context.Context) ([]*models.SettlementProvider, error)
+	CreateProvider(ctx context.Context, req *models.CreateSettlementProviderRequest) (*models.SettlementProvider, error)
+	UpdateProvider(ctx context.Context, code string, req *models.UpdateSettlementProviderRequest) (*models.SettlementProvider, error)
+	ImportSettlement(ctx context.Context, providerCode, fileName string, data []byte, importedBy *uuid.UUID) (*models.SettlementImport, error)
+	ListImports(ctx context.Context, page, limit int) ([]*models.SettlementImport, error)
+	GetImport(ctx context.Context, id uuid.UUID) (*models.SettlementImport, error)
+	GetUnreconciledReport(ctx context.Context) (*models.UnreconciledReport, error)
+	ResolvePayment(ctx context.Context, paymentID uuid.UUID, req *models.ResolveReconciliationRequest) error
+	ResolveLine(ctx context.Context, lineID, resolvedBy uuid.UUID, req *models.ResolveReconciliationRequest) error
+}
+
+type settlementService struct {
+	settlementRepo repository.SettlementRepository
+}
+
+func NewSettlementService(settlementRepo repository.SettlementRepository) SettlementService {
+	return &settlementService{
+		settlementRepo: settlementRepo,
+	}
+}
+
+func (s *settlementService) ListProviders(ctx context.Context) ([]*models.SettlementProvider, error) {
+	return s.settlementRepo.ListProviders(ctx)
+}
+
+func (s *settlementService) CreateProvider(ctx context.Context, req *models.CreateSettlementProviderRequest) (*models.SettlementProvider, error) {
+	if err := validateColumnMapping(&req.ColumnMapping); err != nil {
+		return nil, err
+	}
+
+	provider := &models.SettlementProvider{
+		Code:                req.Code,
+		Name:                req.Name,
+		ColumnMapping:       req.ColumnMapping,
+		Delimiter:           req.Delimiter,
+		DateFormat:          req.DateFormat,
+		PaymentMethods:      req.PaymentMethods,
+		SettlementDelayDays: req.SettlementDelayDays,
+	}
+
+	if provider.Delimiter == "" {
+		provider.Delimiter = ","
+	}
+	if provider.DateFormat == "" {
+		provider.DateFormat = "2006-01-02"
+	}
+
+	if err := s.settlementRepo.CreateProvider(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+func (s *settlementService) UpdateProvider(ctx context.Context, code string, req *models.UpdateSettlementProviderRequest) (*models.SettlementProvider, error) {
+	provider, err := s.settlementRepo.GetProviderByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		provider.Name = *req.Name
+	}
+	if req.ColumnMapping != nil {
+		if err := validateColumnMapping(req.ColumnMapping); err != nil {
+			return nil, err
+		}
+		provider.ColumnMapping = *req.ColumnMapping
+	}
+	if req.Delimiter != nil {
+		provider.Delimiter = *req.Delimiter
+	}
+	if req.DateFormat != nil {
+		provider.DateFormat = *req.DateFormat
+	}
+	if req.PaymentMethods != nil {
+		provider.PaymentMethods = req.PaymentMethods
+	}
+	if req.SettlementDelayDays != nil {
+		provider.SettlementDelayDays = *req.SettlementDelayDays
+	}
+	if req.IsActive != nil {
+		provider.IsActive = *req.IsActive
+	}
+
+	if err := s.settlementRepo.UpdateProvider(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+func (s *settlementService) ImportSettlement(ctx context.Context, providerCode, fileName string, data []byte, importedBy *uuid.UUID) (*models.SettlementImport, error) {
+	provider, err := s.settlementRepo.GetProviderByCode(ctx, providerCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if !provider.IsActive {
+		return nil, fmt.Errorf("settlement provider %s is not active", provider.Code)
+	}
+
+	// Refuse to import the same file twice
+	sum := sha256.Sum256(data)
+	fileHash := hex.EncodeToString(sum[:])
+
+	exists, err := s.settlementRepo.ImportExists(ctx, provider.ID, fileHash)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("settlement file has already been imported")
+	}
+
+	lines, err := settlement.Parse(bytes.NewReader(data), provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse settlement file: %w", err)
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("settlement file has no rows")
+	}
+
+	gatewayIDs := make([]string, 0, len(lines))
+	for _, line := range lines {
+		gatewayIDs = append(gatewayIDs, line.GatewayTransactionID)
+	}
+
+	payments, err := s.settlementRepo.GetPaymentsByGatewayIDs(ctx, gatewayIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &models.SettlementImport{
+		ProviderID: provider.ID,
+		FileName:   fileName,
+		FileHash:   fileHash,
+		RowCount:   len(lines),
+		ImportedBy: importedBy,
+		Provider:   provider,
+	}
+
+	for _, line := range lines {
+		payment := payments[line.GatewayTransactionID]
+		settlement.Match(line, payment)
+
+		// Later rows for the same transaction in this file see it as settled
+		if status := settlement.PaymentStatus(line); status != "" {
+			payment.ReconciliationStatus = status
+		}
+
+		switch line.MatchStatus {
+		case settlement.StatusMatched:
+			imp.MatchedCount++
+		case settlement.StatusFeeDeducted:
+			imp.FeeDeductedCount++
+		case settlement.StatusAmountMismatch:
+			imp.AmountMismatchCount++
+		case settlement.StatusUnknownTransaction:
+			imp.UnknownCount++
+		case settlement.StatusDuplicate:
+			imp.DuplicateCount++
+		}
+
+		if line.SettledAt != nil {
+			if imp.PeriodStart == nil || line.SettledAt.Before(*imp.PeriodStart) {
+				imp.PeriodStart = line.SettledAt
+			}
+			if imp.PeriodEnd == nil || line.SettledAt.After(*imp.PeriodEnd) {
+				imp.PeriodEnd = line.SettledAt
+			}
+		}
+	}
+
+	// Anything captured before the file's last settlement date minus the
+	// provider's settlement delay should have been paid out by now
+	var missingCutoff *time.Time
+	if imp.PeriodEnd != nil {
+		cutoff := imp.PeriodEnd.AddDate(0, 0, -provider.SettlementDelayDays)
+		missingCutoff = &cutoff
+	}
+
+	if err := s.settlementRepo.SaveImport(ctx, imp, lines, provider.PaymentMethods, missingCutoff); err != nil {
+		return nil, err
+	}
+
+	// Only return the rows that need attention
+	imp.Lines = []*models.SettlementLine{}
+	for _, line := range lines {
+		if line.MatchStatus != settlement.StatusMatched {
+			imp.Lines = append(imp.Lines, line)
+		}
+	}
+
+	return imp, nil
+}
+
+func (s *settlementService) ListImports(ctx context.Context, page, limit int) ([]*models.SettlementImport, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	return s.settlementRepo.ListImports(ctx, limit, (page-1)*limit)
+}
+
+func (s *settlementService) GetImport(ctx context.Context, id uuid.UUID) (*models.SettlementImport, error) {
+	return s.settlementRepo.GetImport(ctx, id)
+}
+
+func (s *settlementService) GetUnreconciledReport(ctx context.Context) (*models.UnreconciledReport, error) {
+	payments, err := s.settlementRepo.GetUnreconciledPayments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.settlementRepo.GetUnresolvedLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.UnreconciledReport{
+		GeneratedAt:   time.Now(),
+		Payments:      payments,
+		UnknownLines:  lines,
+		CountByStatus: make(map[string]int),
+	}
+
+	for _, p := range payments {
+		report.CountByStatus[p.ReconciliationStatus]++
+		switch p.ReconciliationStatus {
+		case "missing_payout":
+			report.TotalVariance = report.TotalVariance.Sub(p.Amount)
+		case "amount_mismatch":
+			if p.AmountVariance != nil {
+				report.TotalVariance = report.TotalVariance.Add(*p.AmountVariance)
+			}
+		}
+		if p.SettledFee != nil {
+			report.TotalFees = report.TotalFees.Add(*p.SettledFee)
+		}
+	}
+
+	for _, line := range lines {
+		report.CountByStatus[line.MatchStatus]++
+		report.TotalVariance = report.TotalVariance.Add(line.GrossAmount)
+	}
+
+	return report, nil
+}
+
+func (s *settlementService) ResolvePayment(ctx context.Context, paymentID uuid.UUID, req *models.ResolveReconciliationRequest) error {
+	return s.settlementRepo.ResolvePayment(ctx, paymentID, req.Notes)
+}
+
+func (s *settlementService) ResolveLine(ctx context.Context, lineID, resolvedBy uuid.UUID, req *models.ResolveReconciliationRequest) error {
+	return s.settlementRepo.ResolveLine(ctx, lineID, resolvedBy, req.Notes)
+}
+
+// validateColumnMapping checks a provider mapping can produce an amount for every row
+func validateColumnMapping(mapping *models.SettlementColumnMapping) error {
+	if mapping.TransactionID == "" {
+		return fmt.Errorf("column mapping must include transaction_id")
+	}
+	if mapping.GrossAmount == "" && mapping.NetAmount == "" {
+		return fmt.Errorf("column mapping must include gross_amount or net_amount")
+	}
+	return nil
+}
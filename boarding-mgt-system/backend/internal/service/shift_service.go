@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type ShiftService interface {
+	OpenShift(ctx context.Context, agentID uuid.UUID, req *models.OpenShiftRequest) (*models.AgentShift, error)
+	CloseShift(ctx context.Context, agentID, shiftID uuid.UUID, req *models.CloseShiftRequest) (*models.ShiftVarianceReport, error)
+	GetCurrentShift(ctx context.Context, agentID uuid.UUID) (*models.AgentShift, error)
+	GetShiftReport(ctx context.Context, operatorID, shiftID uuid.UUID) (*models.ShiftVarianceReport, error)
+	ListOpenShifts(ctx context.Context, operatorID uuid.UUID) ([]*models.AgentShift, error)
+	ListUnreconciledShifts(ctx context.Context, operatorID uuid.UUID) ([]*models.AgentShift, error)
+	ReconcileShift(ctx context.Context, operatorID, shiftID, adminID uuid.UUID, req *models.ReconcileShiftRequest) (*models.AgentShift, error)
+}
+
+type shiftService struct {
+	shiftRepo repository.ShiftRepository
+	userRepo  repository.UserRepository
+}
+
+func NewShiftService(shiftRepo repository.ShiftRepository, userRepo repository.UserRepository) ShiftService {
+	return &shiftService{
+		shiftRepo: shiftRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (s *shiftService) OpenShift(ctx context.Context, agentID uuid.UUID, req *models.OpenShiftRequest) (*models.AgentShift, error) {
+	if req.OpeningFloat.IsNegative() {
+		return nil, fmt.Errorf("opening float cannot be negative")
+	}
+
+	// Shifts belong to the agent's operator
+	agent, err := s.userRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("agent not found: %w", err)
+	}
+
+	if agent.OperatorID == nil {
+		return nil, fmt.Errorf("only operator staff can open a shift")
+	}
+
+	// Check agent does not already hold a drawer
+	if existing, _ := s.shiftRepo.GetOpenByAgent(ctx, agentID); existing != nil {
+		return nil, fmt.Errorf("agent already has an open shift on terminal %s", existing.TerminalID)
+	}
+
+	shift := &models.AgentShift{
+		OperatorID:   *agent.OperatorID,
+		AgentID:      agentID,
+		TerminalID:   req.TerminalID,
+		OpeningFloat: req.OpeningFloat,
+	}
+
+	if err := s.shiftRepo.Create(ctx, shift); err != nil {
+		return nil, fmt.Errorf("failed to open shift: %w", err)
+	}
+
+	return shift, nil
+}
+
+func (s *shiftService) CloseShift(ctx context.Context, agentID, shiftID uuid.UUID, req *models.CloseShiftRequest) (*models.ShiftVarianceReport, error) {
+	if req.CountedCash.IsNegative() {
+		return nil, fmt.Errorf("counted cash cannot be negative")
+	}
+
+	shift, err := s.shiftRepo.GetByID(ctx, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("shift not found: %w", err)
+	}
+
+	if shift.AgentID != agentID {
+		return nil, fmt.Errorf("shift belongs to another agent")
+	}
+
+	if shift.Status != "open" {
+		return nil, fmt.Errorf("shift is already %s", shift.Status)
+	}
+
+	totals, err := s.shiftRepo.GetCashTotals(ctx, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total shift: %w", err)
+	}
+
+	expected := shift.OpeningFloat.Add(totals.CashSales).Sub(totals.CashRefunds)
+	counted := req.CountedCash
+	variance := counted.Sub(expected)
+
+	shift.ExpectedCash = &expected
+	shift.CountedCash = &counted
+	shift.CashVariance = &variance
+	if req.Notes != "" {
+		shift.ClosingNotes = &req.Notes
+	}
+
+	if err := s.shiftRepo.Close(ctx, shift); err != nil {
+		return nil, fmt.Errorf("failed to close shift: %w", err)
+	}
+
+	return buildVarianceReport(shift, totals), nil
+}
+
+func (s *shiftService) GetCurrentShift(ctx context.Context, agentID uuid.UUID) (*models.AgentShift, error) {
+	shift, err := s.shiftRepo.GetOpenByAgent(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("no open shift: %w", err)
+	}
+
+	return shift, nil
+}
+
+// GetShiftReport reports on one of an operator's shifts
+func (s *shiftService) GetShiftReport(ctx context.Context, operatorID, shiftID uuid.UUID) (*models.ShiftVarianceReport, error) {
+	shift, err := s.shiftRepo.GetByOperator(ctx, operatorID, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("shift not found: %w", err)
+	}
+
+	totals, err := s.shiftRepo.GetCashTotals(ctx, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total shift: %w", err)
+	}
+
+	return buildVarianceReport(shift, totals), nil
+}
+
+func (s *shiftService) ListOpenShifts(ctx context.Context, operatorID uuid.UUID) ([]*models.AgentShift, error) {
+	shifts, err := s.shiftRepo.ListOpen(ctx, operatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open shifts: %w", err)
+	}
+
+	return shifts, nil
+}
+
+func (s *shiftService) ListUnreconciledShifts(ctx context.Context, operatorID uuid.UUID) ([]*models.AgentShift, error) {
+	shifts, err := s.shiftRepo.ListUnreconciled(ctx, operatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unreconciled shifts: %w", err)
+	}
+
+	return shifts, nil
+}
+
+// ReconcileShift signs off the variance of one of an operator's closed shifts
+func (s *shiftService) ReconcileShift(ctx context.Context, operatorID, shiftID, adminID uuid.UUID, req *models.ReconcileShiftRequest) (*models.AgentShift, error) {
+	if err := s.shiftRepo.Reconcile(ctx, operatorID, shiftID, adminID, req.Notes); err != nil {
+		return nil, fmt.Errorf("failed to reconcile shift: %w", err)
+	}
+
+	return s.shiftRepo.GetByOperator(ctx, operatorID, shiftID)
+}
+
+// Helper functions
+func buildVarianceReport(shift *models.AgentShift, totals *models.ShiftCashTotals) *models.ShiftVarianceReport {
+	report := &models.ShiftVarianceReport{
+		Shift:           shift,
+		OpeningFloat:    shift.OpeningFloat,
+		CashSales:       totals.CashSales,
+		CashRefunds:     totals.CashRefunds,
+		ExpectedCash:    shift.OpeningFloat.Add(totals.CashSales).Sub(totals.CashRefunds),
+		CountedCash:     shift.CountedCash,
+		BookingCount:    totals.BookingCount,
+		RefundCount:     totals.RefundCount,
+		ByPaymentMethod: totals.ByPaymentMethod,
+	}
+
+	if shift.CountedCash != nil {
+		variance := shift.CountedCash.Sub(report.ExpectedCash)
+		report.Variance = &variance
+	}
+
+	return report
+}
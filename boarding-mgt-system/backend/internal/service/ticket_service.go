@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/boardingpass"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/ticketqr"
+	"github.com/google/uuid"
+)
+
+type TicketService interface {
+	GetTicket(ctx context.Context, id uuid.UUID) (*models.Ticket, error)
+	GetCustomerTickets(ctx context.Context, customerID uuid.UUID, limit int) ([]*models.Ticket, error)
+	UpdatePassengerIdentity(ctx context.Context, ticket *models.Ticket, identity *models.PassengerIdentity) error
+	RenderQRCode(ticket *models.Ticket, format string, size int) ([]byte, error)
+	GetBoardingPasses(ctx context.Context, booking *models.Booking, tickets []models.Ticket) ([]*models.BoardingPass, error)
+	WriteBoardingPassPDF(w io.Writer, passes []*models.BoardingPass) error
+	VerifyQRCode(ctx context.Context, qrCode string) *models.QRCodeVerification
+	ListVerificationKeys(ctx context.Context) []ticketqr.PublicKey
+}
+
+type ticketService struct {
+	ticketRepo   repository.TicketRepository
+	bookingRepo  repository.BookingRepository
+	scheduleRepo repository.ScheduleRepository
+	portRepo     repository.PortRepository
+	operatorRepo repository.OperatorRepository
+
+	qrKeys     *ticketqr.Keyring
+	qrVerifier *ticketqr.Verifier
+}
+
+func NewTicketService(
+	ticketRepo repository.TicketRepository,
+	bookingRepo repository.BookingRepository,
+	scheduleRepo repository.ScheduleRepository,
+	portRepo repository.PortRepository,
+	operatorRepo repository.OperatorRepository,
+	qrKeys *ticketqr.Keyring,
+) TicketService {
+	return &ticketService{
+		ticketRepo:   ticketRepo,
+		bookingRepo:  bookingRepo,
+		scheduleRepo: scheduleRepo,
+		portRepo:     portRepo,
+		operatorRepo: operatorRepo,
+		qrKeys:       qrKeys,
+		qrVerifier:   qrKeys.Verifier(ticketqr.DefaultLeeway),
+	}
+}
+
+// GetTicket returns a ticket with its booking, including the booking's
+// schedule operator so callers can authorize access
+func (s *ticketService) GetTicket(ctx context.Context, id uuid.UUID) (*models.Ticket, error) {
+	ticket, err := s.ticketRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, ticket.BookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket booking: %w", err)
+	}
+	ticket.Booking = booking
+
+	return ticket, nil
+}
+
+func (s *ticketService) GetCustomerTickets(ctx context.Context, customerID uuid.UUID, limit int) ([]*models.Ticket, error) {
+	return s.ticketRepo.GetByCustomer(ctx, customerID, limit)
+}
+
+// UpdatePassengerIdentity records the travel document details of a ticket's
+// passenger, which are needed on the manifest until the sailing departs
+func (s *ticketService) UpdatePassengerIdentity(ctx context.Context, ticket *models.Ticket, identity *models.PassengerIdentity) error {
+	schedule, err := s.scheduleRepo.GetByID(ctx, ticket.Booking.ScheduleID)
+	if err != nil {
+		return fmt.Errorf("schedule not found: %w", err)
+	}
+	if schedule.Status == "departed" || schedule.Status == "arrived" || schedule.Status == "cancelled" {
+		return fmt.Errorf("cannot update passenger details of a %s sailing", schedule.Status)
+	}
+
+	return s.ticketRepo.UpdateIdentity(ctx, ticket.ID, identity)
+}
+
+func (s *ticketService) RenderQRCode(ticket *models.Ticket, format string, size int) ([]byte, error) {
+	return boardingpass.QRCode(ticket.QRCode, format, size)
+}
+
+// GetBoardingPasses builds the boarding passes of a booking's tickets, with
+// departure and arrival in the local time of their ports
+func (s *ticketService) GetBoardingPasses(ctx context.Context, booking *models.Booking, tickets []models.Ticket) ([]*models.BoardingPass, error) {
+	if booking.BookingStatus != "confirmed" {
+		return nil, fmt.Errorf("boarding passes are only available for confirmed bookings")
+	}
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("booking has no tickets")
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(ctx, booking.ScheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	operator, err := s.operatorRepo.GetByID(ctx, schedule.OperatorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator: %w", err)
+	}
+
+	departurePort, err := s.portRepo.GetByID(ctx, schedule.Route.DeparturePortID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get departure port: %w", err)
+	}
+
+	arrivalPort, err := s.portRepo.GetByID(ctx, schedule.Route.ArrivalPortID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get arrival port: %w", err)
+	}
+
+	// Passes show each time at its own port, as now expected if the sailing
+	// is delayed
+	departure, arrival := schedule.ExpectedDepartureAt(), schedule.ExpectedArrivalAt()
+	if schedule.EstimatedDepartureLocal != nil {
+		departure = *schedule.EstimatedDepartureLocal
+	} else if schedule.DepartureLocal != nil {
+		departure = *schedule.DepartureLocal
+	}
+	if schedule.EstimatedArrivalLocal != nil {
+		arrival = *schedule.EstimatedArrivalLocal
+	} else if schedule.ArrivalLocal != nil {
+		arrival = *schedule.ArrivalLocal
+	}
+
+	passes := make([]*models.BoardingPass, 0, len(tickets))
+	for _, ticket := range tickets {
+		pass := &models.BoardingPass{
+			TicketID:          ticket.ID,
+			BookingReference:  booking.BookingReference,
+			OperatorName:      operator.Name,
+			OperatorCode:      operator.Code,
+			BrandColor:        boardingpass.BrandColor(operator.Settings),
+			RouteName:         schedule.Route.Name,
+			DeparturePort:     departurePort.Name,
+			DeparturePortCode: departurePort.Code,
+			ArrivalPort:       arrivalPort.Name,
+			ArrivalPortCode:   arrivalPort.Code,
+			VesselName:        schedule.Vessel.Name,
+			Departure:         departure,
+			Arrival:           arrival,
+			PassengerName:     ticket.PassengerName,
+			PassengerType:     ticket.PassengerType,
+			QRCode:            ticket.QRCode,
+		}
+		if ticket.SeatNumber != nil {
+			pass.SeatNumber = *ticket.SeatNumber
+		}
+		passes = append(passes, pass)
+	}
+
+	return passes, nil
+}
+
+func (s *ticketService) WriteBoardingPassPDF(w io.Writer, passes []*models.BoardingPass) error {
+	return boardingpass.WritePDF(w, passes)
+}
+
+// VerifyQRCode checks a ticket code's signature and validity window the same
+// way an offline scanner does, without looking the ticket up
+func (s *ticketService) VerifyQRCode(ctx context.Context, qrCode string) *models.QRCodeVerification {
+	now := time.Now()
+	result := &models.QRCodeVerification{CheckedAt: now}
+
+	claims, err := s.qrVerifier.Verify(qrCode, now)
+	if claims != nil {
+		result.KeyID = claims.KeyID
+		result.TicketID = &claims.TicketID
+		result.ScheduleID = &claims.ScheduleID
+		result.PassengerType = claims.PassengerType
+		result.SeatNumber = claims.SeatNumber
+		result.ValidFrom = &claims.NotBefore
+		result.ValidUntil = &claims.NotAfter
+	}
+
+	if err != nil {
+		result.Reason = qrCodeRejection(err)
+		result.Message = err.Error()
+		return result
+	}
+
+	result.Valid = true
+	return result
+}
+
+func (s *ticketService) ListVerificationKeys(ctx context.Context) []ticketqr.PublicKey {
+	return s.qrKeys.PublicKeys()
+}
+
+// qrCodeRejection maps a verification error to its reason code
+func qrCodeRejection(err error) string {
+	switch {
+	case errors.Is(err, ticketqr.ErrUnknownKey):
+		return "unknown_key"
+	case errors.Is(err, ticketqr.ErrBadSignature):
+		return "bad_signature"
+	case errors.Is(err, ticketqr.ErrNotYetValid):
+		return "not_yet_valid"
+	case errors.Is(err, ticketqr.ErrExpired):
+		return "expired"
+	default:
+		return "malformed"
+	}
+}
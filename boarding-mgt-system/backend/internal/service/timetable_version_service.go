@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/timetable"
+	"github.com/google/uuid"
+)
+
+type TimetableVersionService interface {
+	CreateVersion(ctx context.Context, req *models.CreateTimetableVersionRequest) (*models.TimetableVersion, error)
+	GetVersion(ctx context.Context, id uuid.UUID) (*models.TimetableVersion, error)
+	ListVersions(ctx context.Context, operatorID *uuid.UUID) ([]*models.TimetableVersion, error)
+	ListSailings(ctx context.Context, id uuid.UUID) ([]*models.Schedule, error)
+	Submit(ctx context.Context, id, submittedBy uuid.UUID) (*models.TimetableVersion, error)
+	ReturnToDraft(ctx context.Context, id uuid.UUID) (*models.TimetableVersion, error)
+	Diff(ctx context.Context, id uuid.UUID) (*models.TimetableDiff, error)
+	Publish(ctx context.Context, id, publishedBy uuid.UUID) (*models.TimetableVersion, *models.TimetableDiff, error)
+	Rollback(ctx context.Context, id uuid.UUID) (*models.TimetableVersion, []uuid.UUID, error)
+}
+
+type timetableVersionService struct {
+	timetableRepo repository.TimetableVersionRepository
+}
+
+func NewTimetableVersionService(timetableRepo repository.TimetableVersionRepository) TimetableVersionService {
+	return &timetableVersionService{timetableRepo: timetableRepo}
+}
+
+// CreateVersion starts a draft timetable for a season, copying the sailings
+// of another of the operator's versions in the season when asked to
+func (s *timetableVersionService) CreateVersion(ctx context.Context, req *models.CreateTimetableVersionRequest) (*models.TimetableVersion, error) {
+	version := &models.TimetableVersion{
+		OperatorID: req.OperatorID,
+		Name:       req.Name,
+	}
+
+	var err error
+	if version.ValidFrom, err = time.Parse(timetable.DateFormat, req.ValidFrom); err != nil {
+		return nil, fmt.Errorf("invalid valid_from date format: %w", err)
+	}
+	if version.ValidUntil, err = time.Parse(timetable.DateFormat, req.ValidUntil); err != nil {
+		return nil, fmt.Errorf("invalid valid_until date format: %w", err)
+	}
+	if version.ValidUntil.Before(version.ValidFrom) {
+		return nil, fmt.Errorf("valid_until must not be before valid_from")
+	}
+	if version.ValidUntil.After(version.ValidFrom.AddDate(0, 0, maxGenerationDays)) {
+		return nil, fmt.Errorf("a timetable season can cover at most %d days", maxGenerationDays)
+	}
+
+	var copyFrom *models.TimetableVersion
+	if req.CopyFrom != nil {
+		if copyFrom, err = s.timetableRepo.GetByID(ctx, *req.CopyFrom); err != nil {
+			return nil, err
+		}
+		if copyFrom.OperatorID != req.OperatorID {
+			return nil, fmt.Errorf("timetable version to copy does not belong to operator")
+		}
+	}
+
+	if err := s.timetableRepo.Create(ctx, version, copyFrom); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+func (s *timetableVersionService) GetVersion(ctx context.Context, id uuid.UUID) (*models.TimetableVersion, error) {
+	return s.timetableRepo.GetByID(ctx, id)
+}
+
+func (s *timetableVersionService) ListVersions(ctx context.Context, operatorID *uuid.UUID) ([]*models.TimetableVersion, error) {
+	return s.timetableRepo.List(ctx, operatorID)
+}
+
+func (s *timetableVersionService) ListSailings(ctx context.Context, id uuid.UUID) ([]*models.Schedule, error) {
+	return s.timetableRepo.ListSailings(ctx, id)
+}
+
+// Submit puts a draft with at least one sailing up for review. Its sailings
+// can no longer be changed unless it is returned to draft.
+func (s *timetableVersionService) Submit(ctx context.Context, id, submittedBy uuid.UUID) (*models.TimetableVersion, error) {
+	version, err := s.timetableRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version.Status != "draft" {
+		return nil, fmt.Errorf("only draft timetable versions can be submitted for review, this one is %s", version.Status)
+	}
+
+	sailings, err := s.timetableRepo.ListSailings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sailings) == 0 {
+		return nil, fmt.Errorf("timetable version has no sailings")
+	}
+
+	if err := s.timetableRepo.Submit(ctx, version, submittedBy); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// ReturnToDraft sends a version in review back to its authors for changes
+func (s *timetableVersionService) ReturnToDraft(ctx context.Context, id uuid.UUID) (*models.TimetableVersion, error) {
+	version, err := s.timetableRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version.Status != "review" {
+		return nil, fmt.Errorf("only timetable versions in review can be returned to draft, this one is %s", version.Status)
+	}
+
+	if err := s.timetableRepo.ReturnToDraft(ctx, version); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// Diff compares a draft or version in review with the timetable on sale
+// for its season: what publishing it would add, change, remove and keep
+func (s *timetableVersionService) Diff(ctx context.Context, id uuid.UUID) (*models.TimetableDiff, error) {
+	version, err := s.timetableRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version.Status != "draft" && version.Status != "review" {
+		return nil, fmt.Errorf("timetable version is already %s", version.Status)
+	}
+
+	return s.timetableRepo.Compare(ctx, version)
+}
+
+// Publish puts a reviewed version on sale in place of the published
+// versions its season overlaps and reports the changes against them
+func (s *timetableVersionService) Publish(ctx context.Context, id, publishedBy uuid.UUID) (*models.TimetableVersion, *models.TimetableDiff, error) {
+	version, err := s.timetableRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diff, err := s.timetableRepo.Publish(ctx, version, publishedBy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return version, diff, nil
+}
+
+// Rollback withdraws a published version that none of its upcoming
+// departures have been booked on, returning it to draft and restoring the
+// versions it replaced. The restored versions are returned.
+func (s *timetableVersionService) Rollback(ctx context.Context, id uuid.UUID) (*models.TimetableVersion, []uuid.UUID, error) {
+	version, err := s.timetableRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	restored, err := s.timetableRepo.Rollback(ctx, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return version, restored, nil
+}
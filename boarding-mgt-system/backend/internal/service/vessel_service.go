@@ -51,6 +51,7 @@ func (s *vesselService) CreateVessel(ctx context.Context, req *models.CreateVess
 		Capacity:           req.Capacity,
 		DeckCount:          req.DeckCount,
 		SeatConfiguration:  req.SeatConfiguration,
+		TurnaroundMinutes:  req.TurnaroundMinutes,
 		IsActive:           true,
 	}
 
@@ -60,6 +61,10 @@ func (s *vesselService) CreateVessel(ctx context.Context, req *models.CreateVess
 		vessel.Amenities = make(map[string]interface{})
 	}
 
+	if req.WeatherLimits != nil {
+		vessel.WeatherLimits = *req.WeatherLimits
+	}
+
 	if err := s.vesselRepo.Create(ctx, vessel); err != nil {
 		return nil, fmt.Errorf("failed to create vessel: %w", err)
 	}
@@ -102,6 +107,15 @@ func (s *vesselService) UpdateVessel(ctx context.Context, id uuid.UUID, req *mod
 	if req.Amenities != nil {
 		vessel.Amenities = req.Amenities
 	}
+	// Reallocates the vessel's sailings whose turnaround is not over yet,
+	// failing with a VesselConflictError if they would then overlap
+	if req.TurnaroundMinutes != nil {
+		vessel.TurnaroundMinutes = *req.TurnaroundMinutes
+	}
+	// Applies from the next forecast check
+	if req.WeatherLimits != nil {
+		vessel.WeatherLimits = *req.WeatherLimits
+	}
 	if req.IsActive != nil {
 		vessel.IsActive = *req.IsActive
 	}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/walletpass"
+	"github.com/google/uuid"
+)
+
+type WalletService interface {
+	GetTicketPass(ctx context.Context, ticket *models.Ticket) (*models.WalletPassFile, error)
+	UpdateSchedulePasses(ctx context.Context, scheduleID uuid.UUID, req *models.WalletPassUpdateRequest) (*models.WalletPassUpdateResult, error)
+
+	// Wallet web service
+	AuthorizePass(ctx context.Context, passTypeID, serialNumber, token string) (*models.WalletPass, error)
+	GetPass(ctx context.Context, pass *models.WalletPass) (*models.WalletPassFile, error)
+	RegisterDevice(ctx context.Context, deviceLibraryID string, pass *models.WalletPass, pushToken string) (bool, error)
+	UnregisterDevice(ctx context.Context, deviceLibraryID string, pass *models.WalletPass) error
+	ListUpdatedPasses(ctx context.Context, deviceLibraryID, passTypeID, passesUpdatedSince string) (*models.WalletSerialNumbers, error)
+}
+
+type walletService struct {
+	walletRepo    repository.WalletPassRepository
+	ticketService TicketService
+
+	issuer *walletpass.Issuer
+}
+
+// NewWalletService creates the wallet pass service. Passes cannot be issued
+// when issuer is nil.
+func NewWalletService(
+	walletRepo repository.WalletPassRepository,
+	ticketService TicketService,
+	issuer *walletpass.Issuer,
+) WalletService {
+	return &walletService{
+		walletRepo:    walletRepo,
+		ticketService: ticketService,
+		issuer:        issuer,
+	}
+}
+
+// GetTicketPass builds a ticket's wallet pass, issuing it on first download
+func (s *walletService) GetTicketPass(ctx context.Context, ticket *models.Ticket) (*models.WalletPassFile, error) {
+	if s.issuer == nil {
+		return nil, fmt.Errorf("wallet passes are not configured")
+	}
+
+	token, err := generateWalletToken()
+	if err != nil {
+		return nil, err
+	}
+
+	pass, err := s.walletRepo.GetOrCreate(ctx, &models.WalletPass{
+		TicketID:            ticket.ID,
+		SerialNumber:        ticket.ID.String(),
+		AuthenticationToken: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.build(ctx, pass, ticket)
+}
+
+// UpdateSchedulePasses sets the gate or notice on every pass of a sailing,
+// giving each a new version, and notifies the wallets holding them
+func (s *walletService) UpdateSchedulePasses(ctx context.Context, scheduleID uuid.UUID, req *models.WalletPassUpdateRequest) (*models.WalletPassUpdateResult, error) {
+	updated, err := s.walletRepo.UpdateSchedulePasses(ctx, scheduleID, req.Gate, req.Notice)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.WalletPassUpdateResult{Updated: updated}
+	if updated == 0 || s.issuer == nil || s.issuer.Notifier == nil {
+		return result, nil
+	}
+
+	tokens, err := s.walletRepo.ListSchedulePushTokens(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		if err := s.issuer.Notifier.Notify(ctx, token); err != nil {
+			fmt.Printf("failed to notify wallet device: %v\n", err)
+			result.Failed++
+			continue
+		}
+		result.Notified++
+	}
+
+	return result, nil
+}
+
+// AuthorizePass returns the pass a wallet asks about when it presents the
+// pass's authentication token
+func (s *walletService) AuthorizePass(ctx context.Context, passTypeID, serialNumber, token string) (*models.WalletPass, error) {
+	if s.issuer == nil || passTypeID != s.issuer.PassTypeID {
+		return nil, fmt.Errorf("unknown pass type")
+	}
+
+	pass, err := s.walletRepo.GetBySerial(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(pass.AuthenticationToken)) != 1 {
+		return nil, fmt.Errorf("invalid authentication token")
+	}
+
+	return pass, nil
+}
+
+// GetPass builds the latest version of a pass
+func (s *walletService) GetPass(ctx context.Context, pass *models.WalletPass) (*models.WalletPassFile, error) {
+	ticket, err := s.ticketService.GetTicket(ctx, pass.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.build(ctx, pass, ticket)
+}
+
+func (s *walletService) RegisterDevice(ctx context.Context, deviceLibraryID string, pass *models.WalletPass, pushToken string) (bool, error) {
+	return s.walletRepo.Register(ctx, deviceLibraryID, pass.ID, pushToken)
+}
+
+func (s *walletService) UnregisterDevice(ctx context.Context, deviceLibraryID string, pass *models.WalletPass) error {
+	return s.walletRepo.Unregister(ctx, deviceLibraryID, pass.ID)
+}
+
+// ListUpdatedPasses returns the passes on a device changed since the tag the
+// device got last time. The tag is the latest change in microseconds.
+func (s *walletService) ListUpdatedPasses(ctx context.Context, deviceLibraryID, passTypeID, passesUpdatedSince string) (*models.WalletSerialNumbers, error) {
+	if s.issuer == nil || passTypeID != s.issuer.PassTypeID {
+		return nil, fmt.Errorf("unknown pass type")
+	}
+
+	var since *time.Time
+	if passesUpdatedSince != "" {
+		micros, err := strconv.ParseInt(passesUpdatedSince, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid passesUpdatedSince tag")
+		}
+		t := time.UnixMicro(micros)
+		since = &t
+	}
+
+	serials, lastUpdated, err := s.walletRepo.ListUpdatedSerials(ctx, deviceLibraryID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WalletSerialNumbers{
+		SerialNumbers: serials,
+		LastUpdated:   strconv.FormatInt(lastUpdated.UnixMicro(), 10),
+	}, nil
+}
+
+// build renders and signs the pass package of a ticket
+func (s *walletService) build(ctx context.Context, pass *models.WalletPass, ticket *models.Ticket) (*models.WalletPassFile, error) {
+	if s.issuer == nil {
+		return nil, fmt.Errorf("wallet passes are not configured")
+	}
+
+	boardingPasses, err := s.ticketService.GetBoardingPasses(ctx, ticket.Booking, []models.Ticket{*ticket})
+	if err != nil {
+		return nil, err
+	}
+	boardingPass := boardingPasses[0]
+
+	icons, err := walletpass.Icons(boardingPass.BrandColor)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := walletpass.Package(walletpass.New(*s.issuer, boardingPass, pass), icons, s.issuer.Certificate, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WalletPassFile{
+		SerialNumber: pass.SerialNumber,
+		Data:         data,
+		LastModified: pass.UpdatedAt,
+	}, nil
+}
+
+// generateWalletToken returns a random pass authentication token
+func generateWalletToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate pass token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/disruption"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/repository"
+	"github.com/ferryflow/boarding-mgt-system/internal/weather"
+	"github.com/google/uuid"
+)
+
+type WeatherService interface {
+	CheckForecasts(ctx context.Context, from, until time.Time) (*models.WeatherCheckResult, error)
+	GetAdvisory(ctx context.Context, id uuid.UUID) (*models.WeatherAdvisory, error)
+	ListAdvisories(ctx context.Context, operatorID *uuid.UUID, status string) ([]*models.WeatherAdvisory, error)
+	Dismiss(ctx context.Context, advisory *models.WeatherAdvisory, req *models.DismissWeatherAdvisoryRequest, reviewedBy uuid.UUID) (*models.WeatherAdvisory, error)
+	Delay(ctx context.Context, advisory *models.WeatherAdvisory, req *models.DelayForWeatherRequest, reviewedBy uuid.UUID) (*models.WeatherAdvisory, *models.ScheduleDisruption, error)
+	Cancel(ctx context.Context, advisory *models.WeatherAdvisory, req *models.CancelForWeatherRequest, reviewedBy uuid.UUID) (*models.WeatherAdvisory, *models.ScheduleCancellationReport, error)
+}
+
+type weatherService struct {
+	weatherRepo  repository.WeatherRepository
+	scheduleRepo repository.ScheduleRepository
+	provider     weather.Provider
+
+	disruptionService   DisruptionService
+	cancellationService ScheduleCancellationService
+}
+
+// NewWeatherService creates the weather service. Forecasts are only checked
+// when provider is not nil.
+func NewWeatherService(
+	weatherRepo repository.WeatherRepository,
+	scheduleRepo repository.ScheduleRepository,
+	provider weather.Provider,
+	disruptionService DisruptionService,
+	cancellationService ScheduleCancellationService,
+) WeatherService {
+	return &weatherService{
+		weatherRepo:         weatherRepo,
+		scheduleRepo:        scheduleRepo,
+		provider:            provider,
+		disruptionService:   disruptionService,
+		cancellationService: cancellationService,
+	}
+}
+
+// CheckForecasts checks the sailings expected to leave between from and
+// until against the forecasts for their ports and route. Sailings beyond
+// their vessel's limits get an advisory, or have their open advisory
+// updated; open advisories of sailings back within limits are cleared.
+func (s *weatherService) CheckForecasts(ctx context.Context, from, until time.Time) (*models.WeatherCheckResult, error) {
+	if s.provider == nil {
+		return nil, fmt.Errorf("no weather provider is configured")
+	}
+	if !until.After(from) {
+		return nil, fmt.Errorf("until must be after from")
+	}
+
+	sailings, err := s.weatherRepo.ListSailings(ctx, from, until)
+	if err != nil {
+		return nil, err
+	}
+
+	// Forecasts are fetched once per port and route, for a period covering
+	// every sailing's port windows and crossing
+	latest := until
+	for _, sailing := range sailings {
+		if arrival := sailing.Schedule.ExpectedArrivalAt(); arrival.After(latest) {
+			latest = arrival
+		}
+	}
+	forecasts := &forecastCache{
+		provider: s.provider,
+		from:     from.Add(-weather.PortWindow),
+		until:    latest.Add(weather.PortWindow),
+		ports:    map[string][]weather.Forecast{},
+		routes:   map[uuid.UUID][]weather.Forecast{},
+	}
+
+	result := &models.WeatherCheckResult{From: from, Until: until}
+	for _, sailing := range sailings {
+		schedule := sailing.Schedule
+		result.Checked++
+
+		exceedances := []models.WeatherExceedance{}
+		if weather.HasLimits(sailing.Limits) {
+			conditions, err := forecasts.conditions(ctx, sailing)
+			if err != nil {
+				return nil, err
+			}
+			exceedances = weather.CheckSailing(schedule.ExpectedDepartureAt(), schedule.ExpectedArrivalAt(), conditions, sailing.Limits)
+		} else {
+			result.NoLimits++
+		}
+
+		if len(exceedances) == 0 {
+			cleared, err := s.weatherRepo.Clear(ctx, schedule.ID)
+			if err != nil {
+				return nil, err
+			}
+			if cleared {
+				result.Cleared++
+			}
+			continue
+		}
+
+		previous, err := s.weatherRepo.Raise(ctx, &models.WeatherAdvisory{
+			ScheduleID:  schedule.ID,
+			OperatorID:  schedule.OperatorID,
+			VesselID:    schedule.VesselID,
+			Exceedances: exceedances,
+		})
+		if err != nil {
+			return nil, err
+		}
+		switch previous {
+		case "", "cleared":
+			result.Opened++
+		case "open":
+			result.Updated++
+		default:
+			result.Reviewed++
+		}
+	}
+
+	return result, nil
+}
+
+func (s *weatherService) GetAdvisory(ctx context.Context, id uuid.UUID) (*models.WeatherAdvisory, error) {
+	return s.weatherRepo.GetByID(ctx, id)
+}
+
+func (s *weatherService) ListAdvisories(ctx context.Context, operatorID *uuid.UUID, status string) ([]*models.WeatherAdvisory, error) {
+	return s.weatherRepo.List(ctx, operatorID, status)
+}
+
+// Dismiss closes an open advisory without acting on it
+func (s *weatherService) Dismiss(ctx context.Context, advisory *models.WeatherAdvisory, req *models.DismissWeatherAdvisoryRequest, reviewedBy uuid.UUID) (*models.WeatherAdvisory, error) {
+	if err := reviewable(advisory); err != nil {
+		return nil, err
+	}
+
+	advisory.Status = "dismissed"
+	advisory.Notes = req.Notes
+	advisory.ReviewedBy = &reviewedBy
+	if err := s.weatherRepo.Review(ctx, advisory); err != nil {
+		return nil, err
+	}
+
+	return advisory, nil
+}
+
+// Delay delays an advisory's sailing for the weather through the disruption
+// workflow, which notifies its passengers, and closes the advisory
+func (s *weatherService) Delay(ctx context.Context, advisory *models.WeatherAdvisory, req *models.DelayForWeatherRequest, reviewedBy uuid.UUID) (*models.WeatherAdvisory, *models.ScheduleDisruption, error) {
+	if err := reviewable(advisory); err != nil {
+		return nil, nil, err
+	}
+
+	schedule, err := s.scheduleRepo.GetByID(ctx, advisory.ScheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	delay, err := s.disruptionService.RecordDisruption(ctx, schedule, &models.CreateDisruptionRequest{
+		Kind:                 disruption.KindDelay,
+		ReasonCode:           "weather",
+		Description:          req.Description,
+		EstimatedDepartureAt: &req.EstimatedDepartureAt,
+		EstimatedArrivalAt:   req.EstimatedArrivalAt,
+	}, &reviewedBy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	action := disruption.KindDelay
+	advisory.Status = "actioned"
+	advisory.Action = &action
+	advisory.DisruptionID = &delay.ID
+	advisory.Notes = req.Notes
+	advisory.ReviewedBy = &reviewedBy
+	if err := s.weatherRepo.Review(ctx, advisory); err != nil {
+		return nil, nil, err
+	}
+
+	return advisory, delay, nil
+}
+
+// Cancel cancels an advisory's sailing for the weather through the
+// cancellation workflow, which offers its passengers a refund, rebooking or
+// credit, and closes the advisory
+func (s *weatherService) Cancel(ctx context.Context, advisory *models.WeatherAdvisory, req *models.CancelForWeatherRequest, reviewedBy uuid.UUID) (*models.WeatherAdvisory, *models.ScheduleCancellationReport, error) {
+	if err := reviewable(advisory); err != nil {
+		return nil, nil, err
+	}
+
+	report, err := s.cancellationService.CancelSchedule(ctx, advisory.ScheduleID, req.Reason, &reviewedBy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	action := "cancel"
+	advisory.Status = "actioned"
+	advisory.Action = &action
+	advisory.Notes = req.Notes
+	advisory.ReviewedBy = &reviewedBy
+	if err := s.weatherRepo.Review(ctx, advisory); err != nil {
+		return nil, nil, err
+	}
+
+	return advisory, report, nil
+}
+
+func reviewable(advisory *models.WeatherAdvisory) error {
+	if advisory.Status != "open" {
+		return fmt.Errorf("only open weather advisories can be reviewed, this one is %s", advisory.Status)
+	}
+	return nil
+}
+
+// forecastCache fetches each port's and route's forecast for a period once
+type forecastCache struct {
+	provider    weather.Provider
+	from, until time.Time
+	ports       map[string][]weather.Forecast
+	routes      map[uuid.UUID][]weather.Forecast
+}
+
+func (c *forecastCache) conditions(ctx context.Context, sailing *models.WeatherSailing) (weather.Conditions, error) {
+	var conditions weather.Conditions
+	var err error
+
+	if conditions.DeparturePort, err = c.port(ctx, sailing.DeparturePort); err != nil {
+		return conditions, err
+	}
+	if conditions.ArrivalPort, err = c.port(ctx, sailing.ArrivalPort); err != nil {
+		return conditions, err
+	}
+	if conditions.Route, err = c.route(ctx, sailing.Schedule.RouteID); err != nil {
+		return conditions, err
+	}
+
+	return conditions, nil
+}
+
+func (c *forecastCache) port(ctx context.Context, code string) ([]weather.Forecast, error) {
+	if forecasts, ok := c.ports[code]; ok {
+		return forecasts, nil
+	}
+
+	forecasts, err := c.provider.PortForecast(ctx, code, c.from, c.until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast for port %s: %w", code, err)
+	}
+	c.ports[code] = forecasts
+	return forecasts, nil
+}
+
+func (c *forecastCache) route(ctx context.Context, routeID uuid.UUID) ([]weather.Forecast, error) {
+	if forecasts, ok := c.routes[routeID]; ok {
+		return forecasts, nil
+	}
+
+	forecasts, err := c.provider.RouteForecast(ctx, routeID, c.from, c.until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast for route %s: %w", routeID, err)
+	}
+	c.routes[routeID] = forecasts
+	return forecasts, nil
+}
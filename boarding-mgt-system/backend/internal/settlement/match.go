@@ -0,0 +1,63 @@
+package settlement
+
+import (
+	"strings"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+)
+
+// Match statuses recorded on settlement lines
+const (
+	StatusMatched            = "matched"
+	StatusFeeDeducted        = "fee_deducted"
+	StatusAmountMismatch     = "amount_mismatch"
+	StatusUnknownTransaction = "unknown_transaction"
+	StatusDuplicate          = "duplicate"
+)
+
+// Match compares a settlement line against the payment with the same gateway
+// transaction ID and records the outcome on the line. A nil payment marks the
+// line as an unknown transaction.
+func Match(line *models.SettlementLine, payment *models.Payment) {
+	if payment == nil {
+		line.PaymentID = nil
+		line.MatchStatus = StatusUnknownTransaction
+		line.AmountVariance = money.Money{}
+		return
+	}
+
+	paymentID := payment.ID
+	line.PaymentID = &paymentID
+
+	// A payment already matched by an earlier settlement file was paid out twice
+	if payment.ReconciliationStatus == StatusMatched || payment.ReconciliationStatus == StatusFeeDeducted {
+		line.MatchStatus = StatusDuplicate
+		line.AmountVariance = line.GrossAmount
+		return
+	}
+
+	line.AmountVariance = line.GrossAmount.Sub(payment.Amount)
+
+	switch {
+	case line.Currency != nil && !strings.EqualFold(*line.Currency, payment.Currency):
+		line.MatchStatus = StatusAmountMismatch
+	case line.GrossAmount.Cmp(payment.Amount) != 0:
+		line.MatchStatus = StatusAmountMismatch
+	case !line.FeeAmount.IsZero():
+		line.MatchStatus = StatusFeeDeducted
+	default:
+		line.MatchStatus = StatusMatched
+	}
+}
+
+// PaymentStatus returns the reconciliation status a payment takes from a
+// matched settlement line, or an empty string if the line should not change it.
+func PaymentStatus(line *models.SettlementLine) string {
+	switch line.MatchStatus {
+	case StatusMatched, StatusFeeDeducted, StatusAmountMismatch:
+		return line.MatchStatus
+	default:
+		return ""
+	}
+}
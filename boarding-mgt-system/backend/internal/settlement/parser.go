@@ -0,0 +1,213 @@
+// Package settlement parses gateway settlement files and matches their rows to payments.
+package settlement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+)
+
+// Parse reads a settlement CSV using the provider's column mapping.
+// Rows are returned in file order; RowNumber counts records with the header as row 1.
+func Parse(r io.Reader, provider *models.SettlementProvider) ([]*models.SettlementLine, error) {
+	mapping := provider.ColumnMapping
+	if mapping.TransactionID == "" {
+		return nil, fmt.Errorf("column mapping must include transaction_id")
+	}
+	if mapping.GrossAmount == "" && mapping.NetAmount == "" {
+		return nil, fmt.Errorf("column mapping must include gross_amount or net_amount")
+	}
+
+	reader := csv.NewReader(r)
+	if provider.Delimiter != "" {
+		reader.Comma = rune(provider.Delimiter[0])
+	}
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("settlement file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalizeHeader(name)] = i
+	}
+
+	index := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		i, ok := columns[normalizeHeader(name)]
+		if !ok {
+			return -1, fmt.Errorf("column %q not found in settlement file", name)
+		}
+		return i, nil
+	}
+
+	var idx struct{ txn, gross, fee, net, currency, settledAt, payout int }
+	for _, col := range []struct {
+		name string
+		dst  *int
+	}{
+		{mapping.TransactionID, &idx.txn},
+		{mapping.GrossAmount, &idx.gross},
+		{mapping.FeeAmount, &idx.fee},
+		{mapping.NetAmount, &idx.net},
+		{mapping.Currency, &idx.currency},
+		{mapping.SettledAt, &idx.settledAt},
+		{mapping.PayoutReference, &idx.payout},
+	} {
+		if *col.dst, err = index(col.name); err != nil {
+			return nil, err
+		}
+	}
+
+	dateFormat := provider.DateFormat
+	if dateFormat == "" {
+		dateFormat = "2006-01-02"
+	}
+
+	lines := []*models.SettlementLine{}
+	row := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		if isBlank(record) {
+			continue
+		}
+
+		field := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		line := &models.SettlementLine{
+			RowNumber:            row,
+			GatewayTransactionID: field(idx.txn),
+		}
+		if line.GatewayTransactionID == "" {
+			return nil, fmt.Errorf("row %d: missing transaction id", row)
+		}
+
+		gross, hasGross, err := parseAmount(field(idx.gross))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid gross amount: %w", row, err)
+		}
+		fee, hasFee, err := parseAmount(field(idx.fee))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid fee amount: %w", row, err)
+		}
+		net, hasNet, err := parseAmount(field(idx.net))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid net amount: %w", row, err)
+		}
+
+		// Gateways report fees as either positive deductions or negative amounts
+		if fee.IsNegative() {
+			fee = fee.Neg()
+		}
+
+		switch {
+		case hasGross && hasNet:
+			if !hasFee {
+				fee = gross.Sub(net)
+			}
+		case hasGross:
+			net = gross.Sub(fee)
+		case hasNet:
+			gross = net.Add(fee)
+		default:
+			return nil, fmt.Errorf("row %d: missing amount", row)
+		}
+
+		line.GrossAmount = gross
+		line.FeeAmount = fee
+		line.NetAmount = net
+
+		if currency := field(idx.currency); currency != "" {
+			currency = strings.ToUpper(currency)
+			line.Currency = &currency
+		}
+
+		if value := field(idx.settledAt); value != "" {
+			settledAt, err := time.Parse(dateFormat, value)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: invalid settlement date %q", row, value)
+			}
+			line.SettledAt = &settledAt
+		}
+
+		if payout := field(idx.payout); payout != "" {
+			line.PayoutReference = &payout
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// normalizeHeader makes header matching insensitive to case, spacing and a UTF-8 BOM
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAmount parses a decimal amount, ignoring thousands separators and currency symbols
+func parseAmount(value string) (money.Money, bool, error) {
+	if value == "" {
+		return money.Money{}, false, nil
+	}
+
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return -1
+		}
+	}, value)
+
+	// Accounting style negatives, e.g. (1.50)
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") && !strings.HasPrefix(cleaned, "-") {
+		cleaned = "-" + cleaned
+	}
+
+	// Some gateways pad amounts to more decimal places than they settle in
+	if whole, fraction, ok := strings.Cut(cleaned, "."); ok && len(fraction) > 2 {
+		cleaned = whole + "." + fraction[:2] + strings.TrimRight(fraction[2:], "0")
+	}
+
+	amount, err := money.Parse(cleaned)
+	if err != nil {
+		return money.Money{}, false, fmt.Errorf("%q is not an amount in cents", value)
+	}
+
+	return amount, true, nil
+}
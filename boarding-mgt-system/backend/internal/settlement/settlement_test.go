@@ -0,0 +1,142 @@
+package settlement
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	provider := &models.SettlementProvider{
+		Code: "testpay",
+		ColumnMapping: models.SettlementColumnMapping{
+			TransactionID:   "Charge ID",
+			GrossAmount:     "Gross",
+			FeeAmount:       "Fee",
+			Currency:        "Currency",
+			SettledAt:       "Payout Date",
+			PayoutReference: "Payout",
+		},
+		Delimiter:  ",",
+		DateFormat: "2006-01-02",
+	}
+
+	t.Run("Parse mapped columns", func(t *testing.T) {
+		csv := "Payout Date,Charge ID,Gross,Fee,Currency,Payout\n" +
+			"2024-03-01,ch_001,\"1,250.00\",36.55,usd,po_1\n" +
+			"\n" +
+			"2024-03-02,ch_002,45.00,(1.61),USD,po_1\n"
+
+		lines, err := Parse(strings.NewReader(csv), provider)
+		require.NoError(t, err)
+		require.Len(t, lines, 2)
+
+		assert.Equal(t, "ch_001", lines[0].GatewayTransactionID)
+		assert.Equal(t, 2, lines[0].RowNumber)
+		assert.Equal(t, money.MustParse("1250.00"), lines[0].GrossAmount)
+		assert.Equal(t, money.MustParse("36.55"), lines[0].FeeAmount)
+		assert.Equal(t, money.MustParse("1213.45"), lines[0].NetAmount)
+		assert.Equal(t, "USD", *lines[0].Currency)
+		assert.Equal(t, "po_1", *lines[0].PayoutReference)
+		assert.Equal(t, 2024, lines[0].SettledAt.Year())
+
+		// Accounting style negative fee is treated as a deduction
+		assert.Equal(t, money.MustParse("1.61"), lines[1].FeeAmount)
+		assert.Equal(t, money.MustParse("43.39"), lines[1].NetAmount)
+	})
+
+	t.Run("Derive gross from net and fee", func(t *testing.T) {
+		netProvider := &models.SettlementProvider{
+			ColumnMapping: models.SettlementColumnMapping{
+				TransactionID: "reference",
+				NetAmount:     "net",
+				FeeAmount:     "fee",
+			},
+			Delimiter: ";",
+		}
+
+		lines, err := Parse(strings.NewReader("REFERENCE;NET;FEE\nabc;97.10;2.90\n"), netProvider)
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		assert.Equal(t, money.MustParse("100.00"), lines[0].GrossAmount)
+	})
+
+	t.Run("Missing mapped column", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("Charge ID,Gross\nch_001,10.00\n"), provider)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("Invalid amount reports row", func(t *testing.T) {
+		csv := "Payout Date,Charge ID,Gross,Fee,Currency,Payout\n" +
+			"2024-03-01,ch_001,abc,0,USD,po_1\n"
+
+		_, err := Parse(strings.NewReader(csv), provider)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "row 2")
+	})
+}
+
+func TestMatch(t *testing.T) {
+	usd := "USD"
+	payment := func(amount string) *models.Payment {
+		return &models.Payment{
+			ID:                   uuid.New(),
+			Amount:               money.MustParse(amount),
+			Currency:             "USD",
+			ReconciliationStatus: "unreconciled",
+		}
+	}
+
+	t.Run("Exact match", func(t *testing.T) {
+		line := &models.SettlementLine{GrossAmount: money.MustParse("120.00"), NetAmount: money.MustParse("120.00"), Currency: &usd}
+		p := payment("120.00")
+		Match(line, p)
+		assert.Equal(t, StatusMatched, line.MatchStatus)
+		assert.Equal(t, p.ID, *line.PaymentID)
+		assert.True(t, line.AmountVariance.IsZero())
+	})
+
+	t.Run("Fee deducted", func(t *testing.T) {
+		line := &models.SettlementLine{GrossAmount: money.MustParse("120.00"), FeeAmount: money.MustParse("3.78"), NetAmount: money.MustParse("116.22")}
+		Match(line, payment("120.00"))
+		assert.Equal(t, StatusFeeDeducted, line.MatchStatus)
+		assert.Equal(t, StatusFeeDeducted, PaymentStatus(line))
+	})
+
+	t.Run("Amount difference", func(t *testing.T) {
+		line := &models.SettlementLine{GrossAmount: money.MustParse("100.00"), NetAmount: money.MustParse("100.00")}
+		Match(line, payment("120.00"))
+		assert.Equal(t, StatusAmountMismatch, line.MatchStatus)
+		assert.Equal(t, money.MustParse("-20.00"), line.AmountVariance)
+	})
+
+	t.Run("Currency difference", func(t *testing.T) {
+		eur := "EUR"
+		line := &models.SettlementLine{GrossAmount: money.MustParse("120.00"), NetAmount: money.MustParse("120.00"), Currency: &eur}
+		Match(line, payment("120.00"))
+		assert.Equal(t, StatusAmountMismatch, line.MatchStatus)
+	})
+
+	t.Run("Unknown transaction", func(t *testing.T) {
+		line := &models.SettlementLine{GrossAmount: money.MustParse("50.00"), NetAmount: money.MustParse("50.00")}
+		Match(line, nil)
+		assert.Equal(t, StatusUnknownTransaction, line.MatchStatus)
+		assert.Nil(t, line.PaymentID)
+		assert.Empty(t, PaymentStatus(line))
+	})
+
+	t.Run("Already settled payment", func(t *testing.T) {
+		p := payment("120.00")
+		p.ReconciliationStatus = "matched"
+		line := &models.SettlementLine{GrossAmount: money.MustParse("120.00"), NetAmount: money.MustParse("120.00")}
+		Match(line, p)
+		assert.Equal(t, StatusDuplicate, line.MatchStatus)
+		assert.Empty(t, PaymentStatus(line))
+	})
+}
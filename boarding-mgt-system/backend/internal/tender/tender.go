@@ -0,0 +1,124 @@
+// Package tender works out what has been paid on a booking across several
+// payment tenders and how refunds are spread back over them.
+package tender
+
+import (
+	"fmt"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+)
+
+// Settled reports whether a payment's money was collected. Refunded payments
+// were collected too; what was given back is tracked in RefundedAmount.
+func Settled(payment *models.Payment) bool {
+	switch payment.PaymentStatus {
+	case "completed", "partially_refunded", "refunded":
+		return true
+	default:
+		return false
+	}
+}
+
+// Refundable returns what can still be refunded on a payment
+func Refundable(payment *models.Payment) money.Money {
+	if !Settled(payment) {
+		return money.Zero(payment.Amount.Currency())
+	}
+	return payment.Amount.Sub(payment.RefundedAmount)
+}
+
+// Paid returns the amount collected across settled payments, net of refunds
+func Paid(payments []models.Payment) money.Money {
+	var paid money.Money
+	for i := range payments {
+		paid = paid.Add(Refundable(&payments[i]))
+	}
+	return paid
+}
+
+// Refunded returns the amount refunded across all payments
+func Refunded(payments []models.Payment) money.Money {
+	var refunded money.Money
+	for _, payment := range payments {
+		refunded = refunded.Add(payment.RefundedAmount)
+	}
+	return refunded
+}
+
+// BalanceDue returns what is still owed on a booking total
+func BalanceDue(total money.Money, payments []models.Payment) money.Money {
+	due := total.Sub(Paid(payments))
+	if due.IsNegative() {
+		return money.Zero(total.Currency())
+	}
+	return due
+}
+
+// CheckCurrency rejects payments taken in a currency other than the booking
+// total's, which could not be added up against it
+func CheckCurrency(total money.Money, payments []models.Payment) error {
+	for _, payment := range payments {
+		if err := money.CheckCurrency(total, payment.Amount, payment.RefundedAmount); err != nil {
+			return fmt.Errorf("payment %s: %w", payment.ID, err)
+		}
+	}
+	return nil
+}
+
+// CheckTenders rejects tenders that would take more than the balance due
+func CheckTenders(balanceDue money.Money, tenders []models.PaymentTender) error {
+	var total money.Money
+	for _, t := range tenders {
+		if !t.Amount.IsPositive() {
+			return fmt.Errorf("payment amounts must be positive")
+		}
+		if err := money.CheckCurrency(balanceDue, t.Amount); err != nil {
+			return fmt.Errorf("payment in the wrong currency: %w", err)
+		}
+		total = total.Add(t.Amount)
+	}
+
+	if total.Cmp(balanceDue) > 0 {
+		return fmt.Errorf("payments of %s exceed the balance due of %s", total, balanceDue)
+	}
+	return nil
+}
+
+// Allocation is the share of a refund given back on one payment
+type Allocation struct {
+	Payment *models.Payment
+	Amount  money.Money
+}
+
+// AllocateRefund spreads a refund over a booking's settled payments. Tenders
+// are refunded in the reverse order they were taken, so the last tender used
+// is refunded first and an earlier voucher or cash tender last. Payments must
+// be ordered oldest first.
+func AllocateRefund(payments []models.Payment, amount money.Money) ([]Allocation, error) {
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("refund amount must be positive")
+	}
+
+	remaining := amount
+	allocations := []Allocation{}
+	for i := len(payments) - 1; i >= 0 && remaining.IsPositive(); i-- {
+		available := Refundable(&payments[i])
+		if !available.IsPositive() {
+			continue
+		}
+
+		share := money.Min(available, remaining)
+		allocations = append(allocations, Allocation{
+			Payment: &payments[i],
+			Amount:  share,
+		})
+		remaining = remaining.Sub(share)
+	}
+
+	if remaining.IsPositive() {
+		return nil, fmt.Errorf("refund of %s exceeds the %s paid", amount, Paid(payments))
+	}
+
+	return allocations, nil
+}
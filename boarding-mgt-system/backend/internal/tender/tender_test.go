@@ -0,0 +1,105 @@
+package tender
+
+import (
+	"testing"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func payment(method string, amount string, status string) models.Payment {
+	return models.Payment{
+		ID:            uuid.New(),
+		PaymentMethod: method,
+		Amount:        money.MustParse(amount),
+		PaymentStatus: status,
+	}
+}
+
+func TestBalance(t *testing.T) {
+	payments := []models.Payment{
+		payment("voucher", "20.00", "completed"),
+		payment("cash", "30.00", "completed"),
+		payment("credit_card", "50.00", "failed"),
+		payment("credit_card", "25.00", "pending"),
+	}
+
+	assert.Equal(t, money.MustParse("50.00"), Paid(payments))
+	assert.Equal(t, money.MustParse("70.00"), BalanceDue(money.MustParse("120.00"), payments))
+	assert.Equal(t, money.MustParse("0.00"), BalanceDue(money.MustParse("40.00"), payments), "overpayment never gives a negative balance")
+
+	payments[1].RefundedAmount = money.MustParse("10.00")
+	payments[1].PaymentStatus = "partially_refunded"
+	assert.Equal(t, money.MustParse("40.00"), Paid(payments))
+	assert.Equal(t, money.MustParse("10.00"), Refunded(payments))
+}
+
+func TestCheckTenders(t *testing.T) {
+	tenders := []models.PaymentTender{
+		{PaymentMethod: "cash", Amount: money.MustParse("40.10")},
+		{PaymentMethod: "credit_card", Amount: money.MustParse("59.90")},
+	}
+
+	assert.NoError(t, CheckTenders(money.MustParse("100.00"), tenders))
+	assert.Error(t, CheckTenders(money.MustParse("99.99"), tenders))
+	assert.Error(t, CheckTenders(money.MustParse("100.00"), []models.PaymentTender{{PaymentMethod: "cash", Amount: money.Money{}}}))
+	assert.Error(t, CheckTenders(money.MustParse("100.00"), []models.PaymentTender{{PaymentMethod: "cash", Amount: money.New(1000, "EUR")}}))
+}
+
+func TestCheckCurrency(t *testing.T) {
+	payments := []models.Payment{payment("cash", "30.00", "completed")}
+	assert.NoError(t, CheckCurrency(money.MustParse("100.00"), payments))
+
+	payments = append(payments, payment("credit_card", "20.00", "completed"))
+	payments[1].Amount = payments[1].Amount.In("EUR")
+	assert.Error(t, CheckCurrency(money.MustParse("100.00"), payments))
+}
+
+func TestAllocateRefund(t *testing.T) {
+	t.Run("Last tender is refunded first", func(t *testing.T) {
+		payments := []models.Payment{
+			payment("voucher", "20.00", "completed"),
+			payment("cash", "30.00", "completed"),
+			payment("credit_card", "50.00", "completed"),
+		}
+
+		allocations, err := AllocateRefund(payments, money.MustParse("70.00"))
+		require.NoError(t, err)
+		require.Len(t, allocations, 2)
+		assert.Equal(t, "credit_card", allocations[0].Payment.PaymentMethod)
+		assert.Equal(t, money.MustParse("50.00"), allocations[0].Amount)
+		assert.Equal(t, "cash", allocations[1].Payment.PaymentMethod)
+		assert.Equal(t, money.MustParse("20.00"), allocations[1].Amount)
+	})
+
+	t.Run("Unsettled and refunded tenders are skipped", func(t *testing.T) {
+		payments := []models.Payment{
+			payment("cash", "30.00", "completed"),
+			payment("credit_card", "50.00", "partially_refunded"),
+			payment("credit_card", "50.00", "failed"),
+		}
+		payments[1].RefundedAmount = money.MustParse("45.00")
+
+		allocations, err := AllocateRefund(payments, money.MustParse("35.00"))
+		require.NoError(t, err)
+		require.Len(t, allocations, 2)
+		assert.Equal(t, money.MustParse("5.00"), allocations[0].Amount)
+		assert.Equal(t, payments[1].ID, allocations[0].Payment.ID)
+		assert.Equal(t, money.MustParse("30.00"), allocations[1].Amount)
+	})
+
+	t.Run("Refund larger than paid is rejected", func(t *testing.T) {
+		payments := []models.Payment{payment("cash", "30.00", "completed")}
+		_, err := AllocateRefund(payments, money.MustParse("30.01"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Zero refund is rejected", func(t *testing.T) {
+		payments := []models.Payment{payment("cash", "30.00", "completed")}
+		_, err := AllocateRefund(payments, money.Money{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,185 @@
+package ticketqr
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultLeeway is the clock skew allowed between the server and scanners
+const DefaultLeeway = 5 * time.Minute
+
+// PublicKey is a verification key as published to scanners
+type PublicKey struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+	Active    bool   `json:"active"`
+}
+
+// Keyring holds the key new codes are signed with and every key codes are
+// still accepted from. Rotating means adding a new signing key, making it
+// active and keeping the old public key until the codes it signed expire.
+type Keyring struct {
+	signer *Signer
+	public map[string]ed25519.PublicKey
+}
+
+// LoadKeyring builds a keyring from configuration. signingKeys and
+// verifyKeys are comma-separated "keyid:base64" lists; signing keys are
+// Ed25519 seeds or private keys and verify keys are public keys of retired
+// signing keys. activeKeyID picks the signing key, defaulting to the first.
+func LoadKeyring(signingKeys, activeKeyID, verifyKeys string) (*Keyring, error) {
+	private, order, err := parseKeyList(signingKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("no ticket signing key configured")
+	}
+	if activeKeyID == "" {
+		activeKeyID = order[0]
+	}
+
+	keyring := &Keyring{public: map[string]ed25519.PublicKey{}}
+	for id, raw := range private {
+		var key ed25519.PrivateKey
+		switch len(raw) {
+		case ed25519.SeedSize:
+			key = ed25519.NewKeyFromSeed(raw)
+		case ed25519.PrivateKeySize:
+			key = ed25519.PrivateKey(raw)
+		default:
+			return nil, fmt.Errorf("signing key %q must be a 32-byte seed or 64-byte private key", id)
+		}
+		keyring.public[id] = key.Public().(ed25519.PublicKey)
+
+		if id == activeKeyID {
+			if keyring.signer, err = NewSigner(id, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if keyring.signer == nil {
+		return nil, fmt.Errorf("active ticket signing key %q is not configured", activeKeyID)
+	}
+
+	public, _, err := parseKeyList(verifyKeys)
+	if err != nil {
+		return nil, err
+	}
+	for id, raw := range public {
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("verify key %q must be a 32-byte public key", id)
+		}
+		if _, ok := keyring.public[id]; ok {
+			return nil, fmt.Errorf("key ID %q is configured twice", id)
+		}
+		keyring.public[id] = ed25519.PublicKey(raw)
+	}
+
+	return keyring, nil
+}
+
+// GenerateKeyring returns a keyring with a fresh random key. Codes it signs
+// stop verifying when the process exits, so it is only for development.
+func GenerateKeyring(keyID string) (*Keyring, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ticket signing key: %w", err)
+	}
+
+	signer, err := NewSigner(keyID, private)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keyring{
+		signer: signer,
+		public: map[string]ed25519.PublicKey{keyID: public},
+	}, nil
+}
+
+// Signer returns the signer for the active key
+func (k *Keyring) Signer() *Signer {
+	return k.signer
+}
+
+// Verifier returns a verifier trusting every key in the keyring
+func (k *Keyring) Verifier(leeway time.Duration) *Verifier {
+	return NewVerifier(k.public, leeway)
+}
+
+// PublicKeys lists the keyring's verification keys for scanners, ordered by key ID
+func (k *Keyring) PublicKeys() []PublicKey {
+	keys := make([]PublicKey, 0, len(k.public))
+	for id, key := range k.public {
+		keys = append(keys, PublicKey{
+			KeyID:     id,
+			Algorithm: "Ed25519",
+			PublicKey: base64.StdEncoding.EncodeToString(key),
+			Active:    id == k.signer.keyID,
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].KeyID < keys[j].KeyID
+	})
+	return keys
+}
+
+// ParsePublicKeys reads a "keyid:base64" list of public keys, as a scanner
+// configured offline would
+func ParsePublicKeys(list string) (map[string]ed25519.PublicKey, error) {
+	raw, _, err := parseKeyList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(raw))
+	for id, key := range raw {
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("public key %q must be 32 bytes", id)
+		}
+		keys[id] = ed25519.PublicKey(key)
+	}
+	return keys, nil
+}
+
+// parseKeyList reads "keyid:base64" entries, returning the decoded keys and
+// their IDs in the order given
+func parseKeyList(list string) (map[string][]byte, []string, error) {
+	keys := map[string][]byte{}
+	order := []string{}
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("key entry %q must be keyid:base64", entry)
+		}
+		if err := checkKeyID(id); err != nil {
+			return nil, nil, err
+		}
+		if _, exists := keys[id]; exists {
+			return nil, nil, fmt.Errorf("key ID %q is configured twice", id)
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %q is not valid base64: %w", id, err)
+		}
+
+		keys[id] = raw
+		order = append(order, id)
+	}
+
+	return keys, order, nil
+}
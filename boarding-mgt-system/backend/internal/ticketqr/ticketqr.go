@@ -0,0 +1,257 @@
+// Package ticketqr encodes tickets as compact Ed25519-signed tokens for QR
+// codes. A token carries everything a gate needs to accept a ticket, so a
+// scanner holding only the public keys can verify it without the database.
+//
+// A token is three dot-separated parts:
+//
+//	<key id>.<base64url payload>.<base64url signature>
+//
+// The signature covers the key ID and the encoded payload. The payload is a
+// fixed binary layout: a version byte, the ticket and schedule IDs, the
+// passenger type, the validity window in Unix seconds and the seat.
+package ticketqr
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const payloadVersion = 1
+
+// Verification failures. Scanners can map these to deny reasons.
+var (
+	ErrMalformed    = errors.New("ticket code is malformed")
+	ErrUnknownKey   = errors.New("ticket code is signed with an unknown key")
+	ErrBadSignature = errors.New("ticket code signature is invalid")
+	ErrNotYetValid  = errors.New("ticket code is not valid yet")
+	ErrExpired      = errors.New("ticket code has expired")
+)
+
+var passengerTypes = []string{"adult", "child", "infant", "senior"}
+
+var encoding = base64.RawURLEncoding
+
+// Claims is what a ticket code asserts
+type Claims struct {
+	KeyID         string    `json:"key_id"`
+	TicketID      uuid.UUID `json:"ticket_id"`
+	ScheduleID    uuid.UUID `json:"schedule_id"`
+	PassengerType string    `json:"passenger_type"`
+	SeatNumber    string    `json:"seat_number,omitempty"`
+	NotBefore     time.Time `json:"not_before"`
+	NotAfter      time.Time `json:"not_after"`
+}
+
+// Signer issues ticket codes with one private key
+type Signer struct {
+	keyID string
+	key   ed25519.PrivateKey
+}
+
+// NewSigner returns a signer for a key. Key IDs are short so codes stay small.
+func NewSigner(keyID string, key ed25519.PrivateKey) (*Signer, error) {
+	if err := checkKeyID(keyID); err != nil {
+		return nil, err
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 private key for %q", keyID)
+	}
+	return &Signer{keyID: keyID, key: key}, nil
+}
+
+// KeyID returns the ID of the key codes are signed with
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign encodes and signs claims. The claims' KeyID is ignored.
+func (s *Signer) Sign(claims Claims) (string, error) {
+	payload, err := encodePayload(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := s.keyID + "." + encoding.EncodeToString(payload)
+	signature := ed25519.Sign(s.key, []byte(signed))
+	return signed + "." + encoding.EncodeToString(signature), nil
+}
+
+// SignMessage signs arbitrary data, such as a scanner bundle, with the
+// signer's key. The purpose is signed along with the data so a signature made
+// for one kind of message cannot be passed off as another, or as a ticket code.
+func (s *Signer) SignMessage(purpose string, message []byte) string {
+	return encoding.EncodeToString(ed25519.Sign(s.key, messageToSign(purpose, message)))
+}
+
+// Verifier checks ticket codes against a set of public keys
+type Verifier struct {
+	keys   map[string]ed25519.PublicKey
+	leeway time.Duration
+}
+
+// NewVerifier returns a verifier trusting the given public keys by key ID.
+// Leeway allows for scanner clocks that are slightly off.
+func NewVerifier(keys map[string]ed25519.PublicKey, leeway time.Duration) *Verifier {
+	trusted := make(map[string]ed25519.PublicKey, len(keys))
+	for id, key := range keys {
+		trusted[id] = key
+	}
+	return &Verifier{keys: trusted, leeway: leeway}
+}
+
+// Parse checks a code's signature and returns its claims without looking at
+// the validity window
+func (v *Verifier) Parse(token string) (*Claims, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	key, ok := v.keys[parts[0]]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	payload, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, ErrMalformed
+	}
+
+	if !ed25519.Verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrBadSignature
+	}
+
+	claims, err := decodePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	claims.KeyID = parts[0]
+	return claims, nil
+}
+
+// Verify checks a code's signature and that it is valid at now. The claims
+// are returned alongside ErrNotYetValid and ErrExpired so callers can report
+// which ticket was refused.
+func (v *Verifier) Verify(token string, now time.Time) (*Claims, error) {
+	claims, err := v.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if now.Add(v.leeway).Before(claims.NotBefore) {
+		return claims, ErrNotYetValid
+	}
+	if now.Add(-v.leeway).After(claims.NotAfter) {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+// VerifyMessage checks a signature made with SignMessage
+func (v *Verifier) VerifyMessage(keyID, purpose string, message []byte, signature string) error {
+	key, ok := v.keys[keyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+
+	raw, err := encoding.DecodeString(signature)
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return ErrMalformed
+	}
+
+	if !ed25519.Verify(key, messageToSign(purpose, message), raw) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// messageToSign prefixes a message with its purpose. Ticket codes start with
+// a key ID, which cannot contain the '/' the prefix does.
+func messageToSign(purpose string, message []byte) []byte {
+	signed := make([]byte, 0, len(purpose)+len(message)+len("ticketqr/")+1)
+	signed = append(signed, "ticketqr/"...)
+	signed = append(signed, purpose...)
+	signed = append(signed, 0)
+	return append(signed, message...)
+}
+
+func encodePayload(claims Claims) ([]byte, error) {
+	typeCode := -1
+	for i, passengerType := range passengerTypes {
+		if passengerType == claims.PassengerType {
+			typeCode = i
+		}
+	}
+	if typeCode < 0 {
+		return nil, fmt.Errorf("unknown passenger type %q", claims.PassengerType)
+	}
+	if len(claims.SeatNumber) > 255 {
+		return nil, fmt.Errorf("seat number is too long")
+	}
+	if claims.NotAfter.Before(claims.NotBefore) {
+		return nil, fmt.Errorf("ticket code validity ends before it starts")
+	}
+
+	payload := make([]byte, 0, 51+len(claims.SeatNumber))
+	payload = append(payload, payloadVersion)
+	payload = append(payload, claims.TicketID[:]...)
+	payload = append(payload, claims.ScheduleID[:]...)
+	payload = append(payload, byte(typeCode))
+	payload = binary.BigEndian.AppendUint64(payload, uint64(claims.NotBefore.Unix()))
+	payload = binary.BigEndian.AppendUint64(payload, uint64(claims.NotAfter.Unix()))
+	payload = append(payload, byte(len(claims.SeatNumber)))
+	payload = append(payload, claims.SeatNumber...)
+	return payload, nil
+}
+
+func decodePayload(payload []byte) (*Claims, error) {
+	const fixed = 1 + 16 + 16 + 1 + 8 + 8 + 1
+	if len(payload) < fixed || payload[0] != payloadVersion {
+		return nil, ErrMalformed
+	}
+
+	claims := &Claims{}
+	copy(claims.TicketID[:], payload[1:17])
+	copy(claims.ScheduleID[:], payload[17:33])
+
+	typeCode := int(payload[33])
+	if typeCode >= len(passengerTypes) {
+		return nil, ErrMalformed
+	}
+	claims.PassengerType = passengerTypes[typeCode]
+
+	claims.NotBefore = time.Unix(int64(binary.BigEndian.Uint64(payload[34:42])), 0).UTC()
+	claims.NotAfter = time.Unix(int64(binary.BigEndian.Uint64(payload[42:50])), 0).UTC()
+
+	seatLength := int(payload[50])
+	if len(payload) != fixed+seatLength {
+		return nil, ErrMalformed
+	}
+	claims.SeatNumber = string(payload[fixed:])
+
+	return claims, nil
+}
+
+// checkKeyID keeps key IDs short and free of the token separator
+func checkKeyID(keyID string) error {
+	if keyID == "" || len(keyID) > 16 {
+		return fmt.Errorf("key ID %q must be 1 to 16 characters", keyID)
+	}
+	for _, r := range keyID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("key ID %q may only contain letters, digits, '-' and '_'", keyID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package ticketqr
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seed(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), ed25519.SeedSize)))
+}
+
+func testClaims() Claims {
+	return Claims{
+		TicketID:      uuid.New(),
+		ScheduleID:    uuid.New(),
+		PassengerType: "child",
+		SeatNumber:    "12A",
+		NotBefore:     time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC),
+		NotAfter:      time.Date(2024, 6, 1, 14, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	keyring, err := LoadKeyring("k1:"+seed(1), "", "")
+	require.NoError(t, err)
+
+	claims := testClaims()
+	token, err := keyring.Signer().Sign(claims)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "k1."))
+	assert.Less(t, len(token), 255, "code must fit the qr_code column")
+
+	verifier := keyring.Verifier(DefaultLeeway)
+	got, err := verifier.Verify(token, time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "k1", got.KeyID)
+	assert.Equal(t, claims.TicketID, got.TicketID)
+	assert.Equal(t, claims.ScheduleID, got.ScheduleID)
+	assert.Equal(t, "child", got.PassengerType)
+	assert.Equal(t, "12A", got.SeatNumber)
+	assert.True(t, claims.NotAfter.Equal(got.NotAfter))
+
+	t.Run("Validity window", func(t *testing.T) {
+		_, err := verifier.Verify(token, claims.NotBefore.Add(-time.Hour))
+		assert.ErrorIs(t, err, ErrNotYetValid)
+
+		expired, err := verifier.Verify(token, claims.NotAfter.Add(time.Hour))
+		assert.ErrorIs(t, err, ErrExpired)
+		assert.Equal(t, claims.TicketID, expired.TicketID)
+
+		// Scanner clocks a little off are tolerated
+		_, err = verifier.Verify(token, claims.NotAfter.Add(time.Minute))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Tampering is detected", func(t *testing.T) {
+		parts := strings.Split(token, ".")
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		payload[33] = 0 // child -> adult
+		forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+
+		_, err = verifier.Parse(forged)
+		assert.ErrorIs(t, err, ErrBadSignature)
+
+		_, err = verifier.Parse("k1." + parts[1])
+		assert.ErrorIs(t, err, ErrMalformed)
+
+		_, err = verifier.Parse("FF" + base64.URLEncoding.EncodeToString([]byte("booking:name:123")))
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+}
+
+func TestSignMessage(t *testing.T) {
+	keyring, err := LoadKeyring("k1:"+seed(1), "", "")
+	require.NoError(t, err)
+	signer := keyring.Signer()
+	verifier := keyring.Verifier(0)
+
+	message := []byte(`{"schedules":[]}`)
+	signature := signer.SignMessage("bundle", message)
+	assert.NoError(t, verifier.VerifyMessage("k1", "bundle", message, signature))
+
+	assert.ErrorIs(t, verifier.VerifyMessage("k1", "bundle", []byte(`{"schedules":[1]}`), signature), ErrBadSignature)
+	assert.ErrorIs(t, verifier.VerifyMessage("k1", "other", message, signature), ErrBadSignature)
+	assert.ErrorIs(t, verifier.VerifyMessage("k9", "bundle", message, signature), ErrUnknownKey)
+	assert.ErrorIs(t, verifier.VerifyMessage("k1", "bundle", message, "not base64!"), ErrMalformed)
+}
+
+func TestKeyRotation(t *testing.T) {
+	old, err := LoadKeyring("k1:"+seed(1), "", "")
+	require.NoError(t, err)
+	oldToken, err := old.Signer().Sign(testClaims())
+	require.NoError(t, err)
+
+	// k2 signs new codes; k1 is kept as a verify-only key
+	k1Public := old.PublicKeys()[0].PublicKey
+	rotated, err := LoadKeyring("k2:"+seed(2), "k2", "k1:"+k1Public)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rotated.Signer().KeyID())
+
+	keys := rotated.PublicKeys()
+	require.Len(t, keys, 2)
+	assert.Equal(t, "k1", keys[0].KeyID)
+	assert.False(t, keys[0].Active)
+	assert.True(t, keys[1].Active)
+
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	_, err = rotated.Verifier(0).Verify(oldToken, now)
+	assert.NoError(t, err)
+
+	// A scanner configured with only the published public keys
+	scannerKeys, err := ParsePublicKeys("k1:" + keys[0].PublicKey + ",k2:" + keys[1].PublicKey)
+	require.NoError(t, err)
+	newToken, err := rotated.Signer().Sign(testClaims())
+	require.NoError(t, err)
+	_, err = NewVerifier(scannerKeys, 0).Verify(newToken, now)
+	assert.NoError(t, err)
+
+	// Once k1 is dropped its codes are refused
+	_, err = NewVerifier(map[string]ed25519.PublicKey{"k2": scannerKeys["k2"]}, 0).Verify(oldToken, now)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestLoadKeyringErrors(t *testing.T) {
+	_, err := LoadKeyring("", "", "")
+	assert.Error(t, err)
+
+	_, err = LoadKeyring("k1:"+seed(1), "k9", "")
+	assert.Error(t, err)
+
+	_, err = LoadKeyring("bad.id:"+seed(1), "", "")
+	assert.Error(t, err)
+
+	_, err = LoadKeyring("k1:"+base64.StdEncoding.EncodeToString([]byte("short")), "", "")
+	assert.Error(t, err)
+
+	_, err = LoadKeyring("k1:"+seed(1)+",k1:"+seed(2), "", "")
+	assert.Error(t, err)
+}
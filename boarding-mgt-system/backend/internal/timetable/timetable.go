@@ -0,0 +1,244 @@
+// Package timetable expands recurring schedule templates into the departures
+// they call for and works out how the schedules generated from a template
+// have to change when the template does, and what publishing a seasonal
+// timetable version changes against the sailings on sale.
+package timetable
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// DateFormat is the layout of template dates
+const DateFormat = "2006-01-02"
+
+// TimeFormat is the layout of template departure and arrival times
+const TimeFormat = "15:04"
+
+// Occurrence is one departure a template calls for
+type Occurrence struct {
+	Date          time.Time
+	DepartureTime time.Time
+	ArrivalTime   time.Time
+}
+
+// Key identifies a departure by its date and departure time
+func (o Occurrence) Key() string {
+	return key(o.Date, o.DepartureTime)
+}
+
+func key(date, departureTime time.Time) string {
+	return date.Format(DateFormat) + " " + departureTime.Format(TimeFormat)
+}
+
+// Validate checks a template's weekdays are ISO days of the week, its
+// departures are distinct valid times and its period is not reversed
+func Validate(t *models.ScheduleTemplate) error {
+	for _, day := range t.Weekdays {
+		if day < 1 || day > 7 {
+			return fmt.Errorf("invalid weekday %d, use 1 (Monday) to 7 (Sunday)", day)
+		}
+	}
+
+	if len(t.Departures) == 0 {
+		return fmt.Errorf("template needs at least one departure")
+	}
+	seen := map[string]bool{}
+	for _, d := range t.Departures {
+		if _, err := time.Parse(TimeFormat, d.DepartureTime); err != nil {
+			return fmt.Errorf("invalid departure time %q", d.DepartureTime)
+		}
+		if _, err := time.Parse(TimeFormat, d.ArrivalTime); err != nil {
+			return fmt.Errorf("invalid arrival time %q", d.ArrivalTime)
+		}
+		if seen[d.DepartureTime] {
+			return fmt.Errorf("departure time %s is listed twice", d.DepartureTime)
+		}
+		seen[d.DepartureTime] = true
+	}
+
+	if t.ValidUntil != nil && t.ValidUntil.Before(t.ValidFrom) {
+		return fmt.Errorf("valid_until must not be before valid_from")
+	}
+
+	return nil
+}
+
+// HolidaysFromSettings reads the "holidays" operator setting, a list of
+// "2006-01-02" dates. Entries that are not dates are ignored.
+func HolidaysFromSettings(settings map[string]interface{}) map[string]bool {
+	holidays := map[string]bool{}
+
+	list, _ := settings["holidays"].([]interface{})
+	for _, v := range list {
+		s, _ := v.(string)
+		if date, err := time.Parse(DateFormat, s); err == nil {
+			holidays[date.Format(DateFormat)] = true
+		}
+	}
+
+	return holidays
+}
+
+// Runs reports whether a template runs on a date
+func Runs(t *models.ScheduleTemplate, date time.Time, holidays map[string]bool) bool {
+	day := date.Format(DateFormat)
+
+	if day < t.ValidFrom.Format(DateFormat) {
+		return false
+	}
+	if t.ValidUntil != nil && day > t.ValidUntil.Format(DateFormat) {
+		return false
+	}
+
+	if len(t.Weekdays) > 0 {
+		weekday := int(date.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		found := false
+		for _, d := range t.Weekdays {
+			if d == weekday {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, exception := range t.ExceptionDates {
+		if exception.Format(DateFormat) == day {
+			return false
+		}
+	}
+
+	return !(t.SkipHolidays && holidays[day])
+}
+
+// Occurrences lists the departures a template calls for from one date to
+// another, both included, in order
+func Occurrences(t *models.ScheduleTemplate, from, to time.Time, holidays map[string]bool) ([]Occurrence, error) {
+	if err := Validate(t); err != nil {
+		return nil, err
+	}
+
+	departures := make([]Occurrence, 0, len(t.Departures))
+	for _, d := range t.Departures {
+		departureTime, _ := time.Parse(TimeFormat, d.DepartureTime)
+		arrivalTime, _ := time.Parse(TimeFormat, d.ArrivalTime)
+		departures = append(departures, Occurrence{DepartureTime: departureTime, ArrivalTime: arrivalTime})
+	}
+	sort.Slice(departures, func(i, j int) bool {
+		return departures[i].DepartureTime.Before(departures[j].DepartureTime)
+	})
+
+	occurrences := []Occurrence{}
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		if !Runs(t, date, holidays) {
+			continue
+		}
+		for _, d := range departures {
+			d.Date = date
+			occurrences = append(occurrences, d)
+		}
+	}
+
+	return occurrences, nil
+}
+
+// Plan is what a template run does to the template's schedules
+type Plan struct {
+	Create    []*models.Schedule
+	Update    []*models.Schedule
+	Remove    []*models.Schedule
+	Keep      []*models.Schedule
+	Unchanged int
+}
+
+// Diff works out how to bring a template's existing schedules in line with
+// the departures it now calls for. capacity is the template vessel's.
+//
+// Missing departures are created. Scheduled, unbooked schedules generated
+// from an older template version are updated, and those the template no
+// longer calls for are removed. Booked schedules are never touched; the
+// ones that no longer match the template are returned in Keep for staff to
+// deal with. Schedules that have been cancelled or have sailed are left
+// alone and not recreated.
+func Diff(t *models.ScheduleTemplate, capacity int, occurrences []Occurrence, instances []models.TemplateInstance) *Plan {
+	plan := &Plan{
+		Create: []*models.Schedule{},
+		Update: []*models.Schedule{},
+		Remove: []*models.Schedule{},
+		Keep:   []*models.Schedule{},
+	}
+
+	wanted := map[string]Occurrence{}
+	for _, o := range occurrences {
+		wanted[o.Key()] = o
+	}
+
+	existing := map[string]bool{}
+	for _, instance := range instances {
+		schedule := instance.Schedule
+		k := key(schedule.DepartureDate, schedule.DepartureTime)
+		existing[k] = true
+
+		o, ok := wanted[k]
+		current := schedule.TemplateVersion != nil && *schedule.TemplateVersion == t.Version
+		editable := schedule.Status == "scheduled" && !instance.Booked
+
+		switch {
+		case schedule.Status != "scheduled":
+			plan.Unchanged++
+		case ok && current:
+			plan.Unchanged++
+		case !editable:
+			plan.Keep = append(plan.Keep, schedule)
+		case ok:
+			updated := *schedule
+			apply(&updated, t, capacity)
+			updated.ArrivalTime = o.ArrivalTime
+			plan.Update = append(plan.Update, &updated)
+		default:
+			plan.Remove = append(plan.Remove, schedule)
+		}
+	}
+
+	for _, o := range occurrences {
+		if existing[o.Key()] {
+			continue
+		}
+		schedule := &models.Schedule{
+			DepartureDate: o.Date,
+			DepartureTime: o.DepartureTime,
+			ArrivalTime:   o.ArrivalTime,
+			Status:        "scheduled",
+		}
+		apply(schedule, t, capacity)
+		plan.Create = append(plan.Create, schedule)
+	}
+
+	return plan
+}
+
+// apply copies a template's fields onto one of its unbooked schedules
+func apply(schedule *models.Schedule, t *models.ScheduleTemplate, capacity int) {
+	templateID := t.ID
+	version := t.Version
+
+	schedule.OperatorID = t.OperatorID
+	schedule.RouteID = t.RouteID
+	schedule.VesselID = t.VesselID
+	schedule.BasePrice = t.BasePrice
+	schedule.TotalCapacity = capacity
+	schedule.AvailableSeats = capacity
+	schedule.TemplateID = &templateID
+	schedule.TemplateVersion = &version
+}
@@ -0,0 +1,203 @@
+package timetable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse(DateFormat, s)
+	return d
+}
+
+func clock(s string) time.Time {
+	t, _ := time.Parse(TimeFormat, s)
+	return t
+}
+
+// weekdayTemplate runs at 07:00 and 15:00 Monday to Friday through June 2025
+func weekdayTemplate() *models.ScheduleTemplate {
+	until := date("2025-06-30")
+	return &models.ScheduleTemplate{
+		ID:         uuid.New(),
+		OperatorID: uuid.New(),
+		RouteID:    uuid.New(),
+		VesselID:   uuid.New(),
+		BasePrice:  money.MustParse("25.00"),
+		ValidFrom:  date("2025-06-01"),
+		ValidUntil: &until,
+		Weekdays:   []int{1, 2, 3, 4, 5},
+		Departures: []models.TemplateDeparture{
+			{DepartureTime: "15:00", ArrivalTime: "16:30"},
+			{DepartureTime: "07:00", ArrivalTime: "08:30"},
+		},
+		IsActive: true,
+		Version:  1,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(weekdayTemplate()))
+
+	tmpl := weekdayTemplate()
+	tmpl.Weekdays = []int{0}
+	assert.Error(t, Validate(tmpl))
+
+	tmpl = weekdayTemplate()
+	tmpl.Departures = nil
+	assert.Error(t, Validate(tmpl))
+
+	tmpl = weekdayTemplate()
+	tmpl.Departures[1].DepartureTime = "25:00"
+	assert.Error(t, Validate(tmpl))
+
+	tmpl = weekdayTemplate()
+	tmpl.Departures[1].DepartureTime = "15:00"
+	assert.Error(t, Validate(tmpl))
+
+	tmpl = weekdayTemplate()
+	before := date("2025-05-01")
+	tmpl.ValidUntil = &before
+	assert.Error(t, Validate(tmpl))
+}
+
+func TestHolidaysFromSettings(t *testing.T) {
+	holidays := HolidaysFromSettings(map[string]interface{}{
+		"holidays": []interface{}{"2025-06-09", "not a date", float64(3)},
+	})
+	assert.Equal(t, map[string]bool{"2025-06-09": true}, holidays)
+
+	assert.Empty(t, HolidaysFromSettings(nil))
+}
+
+func TestRuns(t *testing.T) {
+	tmpl := weekdayTemplate()
+	holidays := map[string]bool{"2025-06-09": true}
+
+	// 2025-06-02 is a Monday
+	assert.True(t, Runs(tmpl, date("2025-06-02"), holidays))
+	assert.False(t, Runs(tmpl, date("2025-06-07"), holidays), "Saturday")
+	assert.False(t, Runs(tmpl, date("2025-05-30"), holidays), "before the period")
+	assert.False(t, Runs(tmpl, date("2025-07-01"), holidays), "after the period")
+
+	t.Run("Holidays only skipped when asked", func(t *testing.T) {
+		assert.True(t, Runs(tmpl, date("2025-06-09"), holidays))
+		tmpl.SkipHolidays = true
+		assert.False(t, Runs(tmpl, date("2025-06-09"), holidays))
+	})
+
+	t.Run("Exception dates", func(t *testing.T) {
+		tmpl := weekdayTemplate()
+		tmpl.ExceptionDates = []time.Time{date("2025-06-03")}
+		assert.False(t, Runs(tmpl, date("2025-06-03"), nil))
+	})
+
+	t.Run("No weekdays means every day", func(t *testing.T) {
+		tmpl := weekdayTemplate()
+		tmpl.Weekdays = []int{}
+		assert.True(t, Runs(tmpl, date("2025-06-08"), nil))
+	})
+}
+
+func TestOccurrences(t *testing.T) {
+	tmpl := weekdayTemplate()
+
+	// Friday to Monday: two running days, two departures each
+	occurrences, err := Occurrences(tmpl, date("2025-06-06"), date("2025-06-09"), nil)
+	require.NoError(t, err)
+	require.Len(t, occurrences, 4)
+
+	assert.Equal(t, "2025-06-06 07:00", occurrences[0].Key())
+	assert.Equal(t, clock("08:30"), occurrences[0].ArrivalTime)
+	assert.Equal(t, "2025-06-06 15:00", occurrences[1].Key())
+	assert.Equal(t, "2025-06-09 07:00", occurrences[2].Key())
+	assert.Equal(t, "2025-06-09 15:00", occurrences[3].Key())
+}
+
+func instance(tmpl *models.ScheduleTemplate, day, departure string, version int, status string, booked bool) models.TemplateInstance {
+	templateID := tmpl.ID
+	return models.TemplateInstance{
+		Schedule: &models.Schedule{
+			ID:              uuid.New(),
+			DepartureDate:   date(day),
+			DepartureTime:   clock(departure),
+			ArrivalTime:     clock(departure).Add(90 * time.Minute),
+			Status:          status,
+			TemplateID:      &templateID,
+			TemplateVersion: &version,
+		},
+		Booked: booked,
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tmpl := weekdayTemplate()
+	occurrences, err := Occurrences(tmpl, date("2025-06-02"), date("2025-06-02"), nil)
+	require.NoError(t, err)
+
+	t.Run("Creates missing departures", func(t *testing.T) {
+		plan := Diff(tmpl, 200, occurrences, nil)
+		require.Len(t, plan.Create, 2)
+
+		schedule := plan.Create[0]
+		assert.Equal(t, tmpl.RouteID, schedule.RouteID)
+		assert.Equal(t, tmpl.VesselID, schedule.VesselID)
+		assert.Equal(t, 200, schedule.TotalCapacity)
+		assert.Equal(t, 200, schedule.AvailableSeats)
+		assert.Equal(t, tmpl.BasePrice, schedule.BasePrice)
+		assert.Equal(t, tmpl.ID, *schedule.TemplateID)
+		assert.Equal(t, 1, *schedule.TemplateVersion)
+	})
+
+	t.Run("Re-running changes nothing", func(t *testing.T) {
+		instances := []models.TemplateInstance{
+			instance(tmpl, "2025-06-02", "07:00", 1, "scheduled", false),
+			instance(tmpl, "2025-06-02", "15:00", 1, "scheduled", true),
+		}
+		plan := Diff(tmpl, 200, occurrences, instances)
+		assert.Empty(t, plan.Create)
+		assert.Empty(t, plan.Update)
+		assert.Empty(t, plan.Remove)
+		assert.Empty(t, plan.Keep)
+		assert.Equal(t, 2, plan.Unchanged)
+	})
+
+	t.Run("Template change updates unbooked and keeps booked", func(t *testing.T) {
+		changed := weekdayTemplate()
+		changed.ID = tmpl.ID
+		changed.Version = 2
+		changed.Departures = []models.TemplateDeparture{{DepartureTime: "07:00", ArrivalTime: "08:45"}}
+		occurrences, err := Occurrences(changed, date("2025-06-02"), date("2025-06-03"), nil)
+		require.NoError(t, err)
+
+		instances := []models.TemplateInstance{
+			instance(tmpl, "2025-06-02", "07:00", 1, "scheduled", false),
+			instance(tmpl, "2025-06-02", "15:00", 1, "scheduled", true),
+			instance(tmpl, "2025-06-03", "07:00", 1, "cancelled", false),
+			instance(tmpl, "2025-06-03", "15:00", 1, "scheduled", false),
+		}
+		plan := Diff(changed, 150, occurrences, instances)
+
+		require.Len(t, plan.Update, 1)
+		assert.Equal(t, instances[0].Schedule.ID, plan.Update[0].ID)
+		assert.Equal(t, clock("08:45"), plan.Update[0].ArrivalTime)
+		assert.Equal(t, 2, *plan.Update[0].TemplateVersion)
+		assert.Equal(t, 150, plan.Update[0].TotalCapacity)
+		assert.Equal(t, 1, *instances[0].Schedule.TemplateVersion, "existing schedule left as read")
+
+		require.Len(t, plan.Keep, 1)
+		assert.Equal(t, instances[1].Schedule.ID, plan.Keep[0].ID)
+
+		require.Len(t, plan.Remove, 1)
+		assert.Equal(t, instances[3].Schedule.ID, plan.Remove[0].ID)
+
+		assert.Empty(t, plan.Create, "cancelled departure is not recreated")
+		assert.Equal(t, 1, plan.Unchanged)
+	})
+}
@@ -0,0 +1,87 @@
+package timetable
+
+import (
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+)
+
+// sailingKey identifies a departure across timetable versions by its route,
+// date and departure time
+func sailingKey(s *models.Schedule) string {
+	return s.RouteID.String() + " " + key(s.DepartureDate, s.DepartureTime)
+}
+
+// CompareVersion works out what publishing a timetable version does to the
+// sailings on sale for its season. live are those sailings, replaces the
+// published versions they belong to and draft the version's own sailings.
+//
+// Unbooked live sailings are taken off sale: the version's sailing for the
+// same departure takes over, as changed or unchanged, and the rest are
+// removed. Booked live sailings stay on sale as they are and are kept; the
+// version's sailings for their departures are returned to be dropped.
+// Cancelled draft sailings are left out of the comparison.
+func CompareVersion(versionID uuid.UUID, replaces []uuid.UUID, live []models.TimetableSailing, draft []*models.Schedule) (*models.TimetableDiff, []*models.Schedule) {
+	diff := &models.TimetableDiff{
+		VersionID: versionID,
+		Replaces:  replaces,
+		Added:     []*models.Schedule{},
+		Changed:   []models.TimetableChange{},
+		Removed:   []*models.Schedule{},
+		Kept:      []*models.Schedule{},
+	}
+	if diff.Replaces == nil {
+		diff.Replaces = []uuid.UUID{}
+	}
+
+	pending := map[string][]*models.Schedule{}
+	for _, schedule := range draft {
+		if schedule.Status == "cancelled" {
+			continue
+		}
+		k := sailingKey(schedule)
+		pending[k] = append(pending[k], schedule)
+	}
+
+	matched := map[*models.Schedule]bool{}
+	dropped := []*models.Schedule{}
+	for _, sailing := range live {
+		k := sailingKey(sailing.Schedule)
+
+		var replacement *models.Schedule
+		if candidates := pending[k]; len(candidates) > 0 {
+			replacement = candidates[0]
+			pending[k] = candidates[1:]
+			matched[replacement] = true
+		}
+
+		switch {
+		case sailing.Booked:
+			diff.Kept = append(diff.Kept, sailing.Schedule)
+			if replacement != nil {
+				dropped = append(dropped, replacement)
+			}
+		case replacement == nil:
+			diff.Removed = append(diff.Removed, sailing.Schedule)
+		case sameSailing(sailing.Schedule, replacement):
+			diff.Unchanged++
+		default:
+			diff.Changed = append(diff.Changed, models.TimetableChange{From: sailing.Schedule, To: replacement})
+		}
+	}
+
+	for _, schedule := range draft {
+		if schedule.Status != "cancelled" && !matched[schedule] {
+			diff.Added = append(diff.Added, schedule)
+		}
+	}
+
+	return diff, dropped
+}
+
+// sameSailing reports whether two sailings of a departure differ in nothing
+// a passenger would notice
+func sameSailing(a, b *models.Schedule) bool {
+	return a.VesselID == b.VesselID &&
+		a.ArrivalTime.Format(TimeFormat) == b.ArrivalTime.Format(TimeFormat) &&
+		a.BasePrice == b.BasePrice
+}
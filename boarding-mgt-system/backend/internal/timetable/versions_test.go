@@ -0,0 +1,70 @@
+package timetable
+
+import (
+	"testing"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/ferryflow/boarding-mgt-system/internal/money"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersion(t *testing.T) {
+	routeID, vesselID := uuid.New(), uuid.New()
+	sailing := func(day, departure, arrival string) *models.Schedule {
+		return &models.Schedule{
+			ID:            uuid.New(),
+			RouteID:       routeID,
+			VesselID:      vesselID,
+			DepartureDate: date(day),
+			DepartureTime: clock(departure),
+			ArrivalTime:   clock(arrival),
+			BasePrice:     money.MustParse("25.00"),
+			Status:        "scheduled",
+		}
+	}
+
+	unchanged := sailing("2025-06-02", "07:00", "08:30")
+	retimed := sailing("2025-06-02", "15:00", "16:30")
+	dropped := sailing("2025-06-03", "07:00", "08:30")
+	booked := sailing("2025-06-03", "15:00", "16:30")
+	live := []models.TimetableSailing{
+		{Schedule: unchanged},
+		{Schedule: retimed},
+		{Schedule: dropped},
+		{Schedule: booked, Booked: true},
+	}
+
+	draftUnchanged := sailing("2025-06-02", "07:00", "08:30")
+	draftRetimed := sailing("2025-06-02", "15:00", "17:00")
+	draftBooked := sailing("2025-06-03", "15:00", "16:00")
+	added := sailing("2025-06-04", "07:00", "08:30")
+	cancelled := sailing("2025-06-05", "07:00", "08:30")
+	cancelled.Status = "cancelled"
+	draft := []*models.Schedule{draftUnchanged, draftRetimed, draftBooked, added, cancelled}
+
+	versionID, replaces := uuid.New(), []uuid.UUID{uuid.New()}
+	diff, drop := CompareVersion(versionID, replaces, live, draft)
+
+	assert.Equal(t, versionID, diff.VersionID)
+	assert.Equal(t, replaces, diff.Replaces)
+	assert.Equal(t, 1, diff.Unchanged)
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, models.TimetableChange{From: retimed, To: draftRetimed}, diff.Changed[0])
+	assert.Equal(t, []*models.Schedule{dropped}, diff.Removed)
+	assert.Equal(t, []*models.Schedule{booked}, diff.Kept, "booked sailings stay on sale")
+	assert.Equal(t, []*models.Schedule{draftBooked}, drop, "the booked sailing stands in for the draft one")
+	assert.Equal(t, []*models.Schedule{added}, diff.Added)
+}
+
+func TestCompareVersionFirstPublication(t *testing.T) {
+	draft := []*models.Schedule{{RouteID: uuid.New(), DepartureDate: date("2025-06-02"), DepartureTime: clock("07:00"), Status: "scheduled"}}
+
+	diff, drop := CompareVersion(uuid.New(), nil, nil, draft)
+
+	assert.Equal(t, draft, diff.Added)
+	assert.Empty(t, diff.Replaces)
+	assert.NotNil(t, diff.Replaces)
+	assert.Empty(t, drop)
+}
@@ -0,0 +1,69 @@
+package walletpass
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// APNsHost is the push service wallet update notifications are sent through
+const APNsHost = "https://api.push.apple.com"
+
+// Notifier tells a device that passes it holds have changed. The device then
+// asks the web service which ones and downloads them.
+type Notifier interface {
+	Notify(ctx context.Context, pushToken string) error
+}
+
+// APNsNotifier sends wallet update notifications, authenticating with the
+// pass type certificate
+type APNsNotifier struct {
+	client *http.Client
+	host   string
+}
+
+// NewAPNsNotifier returns a notifier that pushes through host with cert
+func NewAPNsNotifier(cert *Certificate, host string) *APNsNotifier {
+	clientCert := tls.Certificate{
+		Certificate: [][]byte{cert.Cert.Raw},
+		PrivateKey:  cert.Key,
+		Leaf:        cert.Cert,
+	}
+	if cert.Intermediate != nil {
+		clientCert.Certificate = append(clientCert.Certificate, cert.Intermediate.Raw)
+	}
+
+	return &APNsNotifier{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig:   &tls.Config{Certificates: []tls.Certificate{clientCert}},
+				ForceAttemptHTTP2: true,
+			},
+		},
+		host: host,
+	}
+}
+
+// Notify sends the empty payload wallet updates use to a device
+func (n *APNsNotifier) Notify(ctx context.Context, pushToken string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.host+"/3/device/"+pushToken, bytes.NewReader([]byte("{}")))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("push notification rejected with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,394 @@
+package walletpass
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+)
+
+var (
+	oidData               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidAttrContentType    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttrMessageDigest  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttrSigningTime    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+	oidSHA256             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSAEncryption      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidECDSAWithSHA256    = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	errMalformedSignature = errors.New("malformed pass signature")
+	errUnsupportedKeyType = errors.New("pass signing key must be RSA or ECDSA")
+)
+
+// Certificate is the pass type certificate passes are signed with, its
+// private key and the intermediate certificate that issued it
+type Certificate struct {
+	Cert         *x509.Certificate
+	Key          crypto.Signer
+	Intermediate *x509.Certificate
+}
+
+// LoadCertificate reads a PEM pass type certificate, its unencrypted private
+// key and optionally the issuing intermediate (Apple's WWDR certificate)
+func LoadCertificate(certFile, keyFile, intermediateFile string) (*Certificate, error) {
+	cert, err := readCertificate(certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pass signing key: %w", err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("pass signing key %s is not PEM encoded", keyFile)
+	}
+	key, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Certificate{Cert: cert, Key: key}
+	if intermediateFile != "" {
+		if c.Intermediate, err = readCertificate(intermediateFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// SelfSigned creates a throwaway self-signed pass certificate. Wallet apps
+// refuse passes signed with it, but it lets packages be built and verified
+// offline.
+func SelfSigned(passTypeID, teamID string) (*Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate pass signing key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificate serial: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:         "Pass Type ID: " + passTypeID,
+			OrganizationalUnit: []string{teamID},
+		},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pass certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pass certificate: %w", err)
+	}
+
+	return &Certificate{Cert: cert, Key: key}, nil
+}
+
+func readCertificate(file string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+
+	der := data
+	if block, _ := pem.Decode(data); block != nil {
+		der = block.Bytes
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate %s: %w", file, err)
+	}
+	return cert, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errUnsupportedKeyType
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("failed to parse pass signing key")
+}
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+// Sign makes the detached PKCS#7 signature of a pass manifest
+func (c *Certificate) Sign(manifest []byte, now time.Time) ([]byte, error) {
+	signatureAlgorithm, err := c.signatureAlgorithm()
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(manifest)
+	attributes, err := signedAttributes(digest[:], now)
+	if err != nil {
+		return nil, err
+	}
+
+	attributesDigest := sha256.Sum256(attributes)
+	signature, err := c.Key.Sign(rand.Reader, attributesDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign pass manifest: %w", err)
+	}
+
+	sha256Algorithm := algorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}
+	signerInfo, err := asn1.Marshal(struct {
+		Version            int
+		IssuerAndSerial    issuerAndSerial
+		DigestAlgorithm    algorithmIdentifier
+		SignedAttributes   asn1.RawValue
+		SignatureAlgorithm algorithmIdentifier
+		Signature          []byte
+	}{
+		Version:            1,
+		IssuerAndSerial:    issuerAndSerial{Issuer: asn1.RawValue{FullBytes: c.Cert.RawIssuer}, Serial: c.Cert.SerialNumber},
+		DigestAlgorithm:    sha256Algorithm,
+		SignedAttributes:   implicit(0, attributes),
+		SignatureAlgorithm: signatureAlgorithm,
+		Signature:          signature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signer info: %w", err)
+	}
+
+	digestAlgorithm, err := asn1.Marshal(sha256Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	certificates := append([]byte{}, c.Cert.Raw...)
+	if c.Intermediate != nil {
+		certificates = append(certificates, c.Intermediate.Raw...)
+	}
+
+	signedData, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      struct{ ContentType asn1.ObjectIdentifier }
+		Certificates     asn1.RawValue
+		SignerInfos      asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{FullBytes: set(digestAlgorithm)},
+		ContentInfo:      struct{ ContentType asn1.ObjectIdentifier }{oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certificates},
+		SignerInfos:      asn1.RawValue{FullBytes: set(signerInfo)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signed data: %w", err)
+	}
+
+	return asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+}
+
+func (c *Certificate) signatureAlgorithm() (algorithmIdentifier, error) {
+	switch c.Key.Public().(type) {
+	case *rsa.PublicKey:
+		return algorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue}, nil
+	case *ecdsa.PublicKey:
+		return algorithmIdentifier{Algorithm: oidECDSAWithSHA256}, nil
+	}
+	return algorithmIdentifier{}, errUnsupportedKeyType
+}
+
+// signedAttributes encodes the content type, signing time and manifest
+// digest as the DER SET the signature is made over
+func signedAttributes(digest []byte, now time.Time) ([]byte, error) {
+	values := []struct {
+		oid   asn1.ObjectIdentifier
+		value interface{}
+	}{
+		{oidAttrContentType, oidData},
+		{oidAttrSigningTime, now.UTC()},
+		{oidAttrMessageDigest, digest},
+	}
+
+	attributes := make([][]byte, 0, len(values))
+	for _, v := range values {
+		value, err := asn1.Marshal(v.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode signed attribute: %w", err)
+		}
+		attribute, err := asn1.Marshal(struct {
+			Type   asn1.ObjectIdentifier
+			Values asn1.RawValue
+		}{v.oid, asn1.RawValue{FullBytes: set(value)}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode signed attribute: %w", err)
+		}
+		attributes = append(attributes, attribute)
+	}
+
+	return set(attributes...), nil
+}
+
+// set encodes DER elements as a SET OF, which DER orders by encoding
+func set(elements ...[]byte) []byte {
+	sort.Slice(elements, func(i, j int) bool {
+		return bytes.Compare(elements[i], elements[j]) < 0
+	})
+	encoded, _ := asn1.Marshal(asn1.RawValue{
+		Class:      asn1.ClassUniversal,
+		Tag:        asn1.TagSet,
+		IsCompound: true,
+		Bytes:      bytes.Join(elements, nil),
+	})
+	return encoded
+}
+
+// implicit retags an encoded SET as [tag] IMPLICIT
+func implicit(tag int, encodedSet []byte) asn1.RawValue {
+	var raw asn1.RawValue
+	asn1.Unmarshal(encodedSet, &raw)
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: tag, IsCompound: true, Bytes: raw.Bytes}
+}
+
+// Verify checks a detached manifest signature the way a wallet app does:
+// the manifest digest must match, the signature must be made by the
+// embedded signing certificate and that certificate must chain to roots.
+func Verify(manifest, signature []byte, roots *x509.CertPool, now time.Time) (*x509.Certificate, error) {
+	var contentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue `asn1:"explicit,tag:0"`
+	}
+	if _, err := asn1.Unmarshal(signature, &contentInfo); err != nil || !contentInfo.ContentType.Equal(oidSignedData) {
+		return nil, errMalformedSignature
+	}
+
+	var signedData struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      asn1.RawValue
+		Certificates     asn1.RawValue `asn1:"tag:0"`
+		SignerInfos      []struct {
+			Version            int
+			IssuerAndSerial    issuerAndSerial
+			DigestAlgorithm    algorithmIdentifier
+			SignedAttributes   asn1.RawValue `asn1:"tag:0"`
+			SignatureAlgorithm algorithmIdentifier
+			Signature          []byte
+		} `asn1:"set"`
+	}
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil || len(signedData.SignerInfos) != 1 {
+		return nil, errMalformedSignature
+	}
+
+	certificates, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil || len(certificates) == 0 {
+		return nil, errMalformedSignature
+	}
+
+	signer := signedData.SignerInfos[0]
+	var cert *x509.Certificate
+	intermediates := x509.NewCertPool()
+	for _, candidate := range certificates {
+		if bytes.Equal(candidate.RawIssuer, signer.IssuerAndSerial.Issuer.FullBytes) &&
+			candidate.SerialNumber.Cmp(signer.IssuerAndSerial.Serial) == 0 {
+			cert = candidate
+			continue
+		}
+		intermediates.AddCert(candidate)
+	}
+	if cert == nil {
+		return nil, fmt.Errorf("pass signature has no signing certificate")
+	}
+
+	// The signature covers the attributes encoded as a SET, not as [0]
+	attributes, _ := asn1.Marshal(asn1.RawValue{
+		Class:      asn1.ClassUniversal,
+		Tag:        asn1.TagSet,
+		IsCompound: true,
+		Bytes:      signer.SignedAttributes.Bytes,
+	})
+
+	var parsed []struct {
+		Type   asn1.ObjectIdentifier
+		Values []asn1.RawValue `asn1:"set"`
+	}
+	if _, err := asn1.UnmarshalWithParams(attributes, &parsed, "set"); err != nil {
+		return nil, errMalformedSignature
+	}
+
+	digest := sha256.Sum256(manifest)
+	matched := false
+	for _, attribute := range parsed {
+		if attribute.Type.Equal(oidAttrMessageDigest) && len(attribute.Values) == 1 {
+			var value []byte
+			if _, err := asn1.Unmarshal(attribute.Values[0].FullBytes, &value); err == nil {
+				matched = bytes.Equal(value, digest[:])
+			}
+		}
+	}
+	if !matched {
+		return nil, fmt.Errorf("pass manifest does not match its signature")
+	}
+
+	algorithm := x509.SHA256WithRSA
+	if signer.SignatureAlgorithm.Algorithm.Equal(oidECDSAWithSHA256) {
+		algorithm = x509.ECDSAWithSHA256
+	}
+	if err := cert.CheckSignature(algorithm, attributes, signer.Signature); err != nil {
+		return nil, fmt.Errorf("invalid pass signature: %w", err)
+	}
+
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("untrusted pass certificate: %w", err)
+	}
+
+	return cert, nil
+}
@@ -0,0 +1,254 @@
+// Package walletpass builds signed mobile wallet passes (.pkpass) for tickets.
+//
+// A pass package is a zip of pass.json, its images, a manifest.json of every
+// file's SHA-1 hash and a detached PKCS#7 signature of the manifest made with
+// the pass type certificate. Wallet apps fetch newer versions of a pass from
+// webServiceURL when they are told it has changed.
+package walletpass
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"sort"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/boardingpass"
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+)
+
+// ContentType is the media type of a pass package
+const ContentType = "application/vnd.apple.pkpass"
+
+// Issuer identifies who passes are issued by, where wallets fetch updates
+// and how passes are signed and wallets notified
+type Issuer struct {
+	PassTypeID    string
+	TeamID        string
+	WebServiceURL string
+	Certificate   *Certificate
+	// Notifier is nil when update notifications cannot be sent
+	Notifier Notifier
+}
+
+// Pass is the pass.json of a boarding pass
+type Pass struct {
+	FormatVersion       int        `json:"formatVersion"`
+	PassTypeIdentifier  string     `json:"passTypeIdentifier"`
+	SerialNumber        string     `json:"serialNumber"`
+	TeamIdentifier      string     `json:"teamIdentifier"`
+	OrganizationName    string     `json:"organizationName"`
+	Description         string     `json:"description"`
+	LogoText            string     `json:"logoText,omitempty"`
+	ForegroundColor     string     `json:"foregroundColor"`
+	BackgroundColor     string     `json:"backgroundColor"`
+	LabelColor          string     `json:"labelColor"`
+	WebServiceURL       string     `json:"webServiceURL,omitempty"`
+	AuthenticationToken string     `json:"authenticationToken,omitempty"`
+	RelevantDate        string     `json:"relevantDate,omitempty"`
+	ExpirationDate      string     `json:"expirationDate,omitempty"`
+	Barcodes            []Barcode  `json:"barcodes"`
+	BoardingPass        *Structure `json:"boardingPass"`
+}
+
+// Barcode is a barcode shown on a pass
+type Barcode struct {
+	Format          string `json:"format"`
+	Message         string `json:"message"`
+	MessageEncoding string `json:"messageEncoding"`
+	AltText         string `json:"altText,omitempty"`
+}
+
+// Structure lays out a boarding pass's fields
+type Structure struct {
+	TransitType     string  `json:"transitType"`
+	HeaderFields    []Field `json:"headerFields,omitempty"`
+	PrimaryFields   []Field `json:"primaryFields"`
+	SecondaryFields []Field `json:"secondaryFields,omitempty"`
+	AuxiliaryFields []Field `json:"auxiliaryFields,omitempty"`
+	BackFields      []Field `json:"backFields,omitempty"`
+}
+
+// Field is a labelled value on a pass. ChangeMessage is shown in the
+// notification when the value changes, with %@ replaced by the new value.
+type Field struct {
+	Key           string `json:"key"`
+	Label         string `json:"label,omitempty"`
+	Value         string `json:"value"`
+	ChangeMessage string `json:"changeMessage,omitempty"`
+	DateStyle     string `json:"dateStyle,omitempty"`
+	TimeStyle     string `json:"timeStyle,omitempty"`
+}
+
+// New builds the pass of a ticket's boarding pass. The departure and gate
+// fields carry change messages so a new version notifies the passenger.
+func New(issuer Issuer, bp *models.BoardingPass, wp *models.WalletPass) *Pass {
+	departure := bp.Departure.Format(time.RFC3339)
+
+	header := []Field{}
+	if wp.Gate != nil && *wp.Gate != "" {
+		header = append(header, Field{Key: "gate", Label: "GATE", Value: *wp.Gate, ChangeMessage: "Boarding gate changed to %@"})
+	}
+
+	secondary := []Field{
+		{Key: "passenger", Label: "PASSENGER", Value: bp.PassengerName},
+		{Key: "departure", Label: "DEPARTS", Value: departure, DateStyle: "PKDateStyleMedium", TimeStyle: "PKDateStyleShort", ChangeMessage: "Departure changed to %@"},
+	}
+
+	auxiliary := []Field{
+		{Key: "vessel", Label: "VESSEL", Value: bp.VesselName},
+		{Key: "type", Label: "TYPE", Value: bp.PassengerType},
+	}
+	if bp.SeatNumber != "" {
+		auxiliary = append(auxiliary, Field{Key: "seat", Label: "SEAT", Value: bp.SeatNumber, ChangeMessage: "Seat changed to %@"})
+	}
+	auxiliary = append(auxiliary, Field{Key: "reference", Label: "BOOKING", Value: bp.BookingReference})
+
+	back := []Field{
+		{Key: "route", Label: "Route", Value: bp.RouteName},
+		{Key: "arrival", Label: "Arrives", Value: bp.Arrival.Format(time.RFC3339), DateStyle: "PKDateStyleMedium", TimeStyle: "PKDateStyleShort"},
+		{Key: "operator", Label: "Operator", Value: bp.OperatorName},
+	}
+	if wp.Notice != nil && *wp.Notice != "" {
+		back = append([]Field{{Key: "notice", Label: "Notice", Value: *wp.Notice, ChangeMessage: "%@"}}, back...)
+	}
+
+	background := rgb(bp.BrandColor)
+	return &Pass{
+		FormatVersion:       1,
+		PassTypeIdentifier:  issuer.PassTypeID,
+		SerialNumber:        wp.SerialNumber,
+		TeamIdentifier:      issuer.TeamID,
+		OrganizationName:    bp.OperatorName,
+		Description:         fmt.Sprintf("Ferry ticket %s to %s", bp.DeparturePort, bp.ArrivalPort),
+		LogoText:            bp.OperatorName,
+		ForegroundColor:     "rgb(255, 255, 255)",
+		BackgroundColor:     background,
+		LabelColor:          "rgb(220, 226, 235)",
+		WebServiceURL:       issuer.WebServiceURL,
+		AuthenticationToken: wp.AuthenticationToken,
+		RelevantDate:        departure,
+		ExpirationDate:      bp.Arrival.Add(24 * time.Hour).Format(time.RFC3339),
+		Barcodes: []Barcode{{
+			Format:          "PKBarcodeFormatQR",
+			Message:         bp.QRCode,
+			MessageEncoding: "iso-8859-1",
+			AltText:         bp.BookingReference,
+		}},
+		BoardingPass: &Structure{
+			TransitType:  "PKTransitTypeBoat",
+			HeaderFields: header,
+			PrimaryFields: []Field{
+				{Key: "origin", Label: bp.DeparturePort, Value: bp.DeparturePortCode},
+				{Key: "destination", Label: bp.ArrivalPort, Value: bp.ArrivalPortCode},
+			},
+			SecondaryFields: secondary,
+			AuxiliaryFields: auxiliary,
+			BackFields:      back,
+		},
+	}
+}
+
+// rgb converts a "#RRGGBB" brand color to the rgb() form passes use
+func rgb(hex string) string {
+	red, green, blue, err := boardingpass.ParseColor(hex)
+	if err != nil {
+		red, green, blue, _ = boardingpass.ParseColor(boardingpass.DefaultBrandColor)
+	}
+	return fmt.Sprintf("rgb(%d, %d, %d)", red, green, blue)
+}
+
+// Icons renders the icon images a pass needs in the brand color
+func Icons(brandColor string) (map[string][]byte, error) {
+	red, green, blue, err := boardingpass.ParseColor(brandColor)
+	if err != nil {
+		red, green, blue, _ = boardingpass.ParseColor(boardingpass.DefaultBrandColor)
+	}
+	fill := color.RGBA{R: uint8(red), G: uint8(green), B: uint8(blue), A: 0xFF}
+
+	icons := map[string][]byte{}
+	for name, size := range map[string]int{"icon.png": 29, "icon@2x.png": 58, "icon@3x.png": 87} {
+		img := image.NewRGBA(image.Rect(0, 0, size, size))
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = fill.R, fill.G, fill.B, fill.A
+		}
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to render pass icon: %w", err)
+		}
+		icons[name] = buf.Bytes()
+	}
+
+	return icons, nil
+}
+
+// Manifest lists the SHA-1 hash of every file in a pass package
+func Manifest(files map[string][]byte) ([]byte, error) {
+	hashes := make(map[string]string, len(files))
+	for name, data := range files {
+		sum := sha1.Sum(data)
+		hashes[name] = hex.EncodeToString(sum[:])
+	}
+
+	manifest, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pass manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// Package builds the signed .pkpass archive of a pass and its images
+func Package(pass *Pass, images map[string][]byte, cert *Certificate, now time.Time) ([]byte, error) {
+	passJSON, err := json.Marshal(pass)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pass: %w", err)
+	}
+
+	files := map[string][]byte{"pass.json": passJSON}
+	for name, data := range images {
+		files[name] = data
+	}
+
+	manifest, err := Manifest(files)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := cert.Sign(manifest, now)
+	if err != nil {
+		return nil, err
+	}
+
+	files["manifest.json"] = manifest
+	files["signature"] = signature
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write pass package: %w", err)
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return nil, fmt.Errorf("failed to write pass package: %w", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write pass package: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,202 @@
+package walletpass
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testIssuer = Issuer{
+	PassTypeID:    "pass.com.ferryflow.test",
+	TeamID:        "TEAM123",
+	WebServiceURL: "https://api.example.com/api/v1/wallet",
+}
+
+func testPass() (*models.BoardingPass, *models.WalletPass) {
+	departure := time.Date(2024, 6, 1, 9, 30, 0, 0, time.FixedZone("EEST", 3*60*60))
+	gate := "B2"
+	return &models.BoardingPass{
+		TicketID:          uuid.New(),
+		BookingReference:  "FFABC123",
+		OperatorName:      "Blue Star",
+		BrandColor:        "#FF0000",
+		RouteName:         "Piraeus - Naxos",
+		DeparturePort:     "Piraeus",
+		DeparturePortCode: "PIR",
+		ArrivalPort:       "Naxos",
+		ArrivalPortCode:   "NAX",
+		VesselName:        "Delos",
+		Departure:         departure,
+		Arrival:           departure.Add(5 * time.Hour),
+		PassengerName:     "Ana Pappas",
+		PassengerType:     "adult",
+		QRCode:            "v1.k1.payload.signature",
+	}, &models.WalletPass{
+		SerialNumber:        "serial-1",
+		AuthenticationToken: "token-0123456789abcdef",
+		Gate:                &gate,
+	}
+}
+
+func TestNew(t *testing.T) {
+	bp, wp := testPass()
+	pass := New(testIssuer, bp, wp)
+
+	assert.Equal(t, "pass.com.ferryflow.test", pass.PassTypeIdentifier)
+	assert.Equal(t, "serial-1", pass.SerialNumber)
+	assert.Equal(t, "token-0123456789abcdef", pass.AuthenticationToken)
+	assert.Equal(t, "rgb(255, 0, 0)", pass.BackgroundColor)
+	assert.Equal(t, "2024-06-01T09:30:00+03:00", pass.RelevantDate)
+
+	require.Len(t, pass.Barcodes, 1)
+	assert.Equal(t, "PKBarcodeFormatQR", pass.Barcodes[0].Format)
+	assert.Equal(t, bp.QRCode, pass.Barcodes[0].Message)
+
+	require.Len(t, pass.BoardingPass.HeaderFields, 1)
+	assert.Equal(t, "B2", pass.BoardingPass.HeaderFields[0].Value)
+	assert.Contains(t, pass.BoardingPass.HeaderFields[0].ChangeMessage, "%@")
+	assert.Equal(t, "PIR", pass.BoardingPass.PrimaryFields[0].Value)
+
+	t.Run("Notice and no gate", func(t *testing.T) {
+		notice := "Delayed by 30 minutes"
+		wp.Gate = nil
+		wp.Notice = &notice
+
+		pass := New(testIssuer, bp, wp)
+		assert.Empty(t, pass.BoardingPass.HeaderFields)
+		assert.Equal(t, notice, pass.BoardingPass.BackFields[0].Value)
+	})
+}
+
+func TestPackage(t *testing.T) {
+	cert, err := SelfSigned(testIssuer.PassTypeID, testIssuer.TeamID)
+	require.NoError(t, err)
+
+	bp, wp := testPass()
+	icons, err := Icons(bp.BrandColor)
+	require.NoError(t, err)
+
+	now := time.Now()
+	data, err := Package(New(testIssuer, bp, wp), icons, cert, now)
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+	}
+	for _, name := range []string{"pass.json", "icon.png", "icon@2x.png", "manifest.json", "signature"} {
+		assert.Contains(t, files, name)
+	}
+
+	var manifest map[string]string
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Len(t, manifest, len(files)-2, "every file but the manifest and signature is listed")
+	for name, hash := range manifest {
+		sum := sha1.Sum(files[name])
+		assert.Equal(t, hex.EncodeToString(sum[:]), hash, name)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.Cert)
+
+	signer, err := Verify(files["manifest.json"], files["signature"], roots, now)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Cert.SerialNumber, signer.SerialNumber)
+
+	t.Run("Tampered manifest", func(t *testing.T) {
+		tampered := bytes.Replace(files["manifest.json"], []byte("pass.json"), []byte("pass.jsoN"), 1)
+		_, err := Verify(tampered, files["signature"], roots, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("Untrusted certificate", func(t *testing.T) {
+		other, err := SelfSigned(testIssuer.PassTypeID, testIssuer.TeamID)
+		require.NoError(t, err)
+		otherRoots := x509.NewCertPool()
+		otherRoots.AddCert(other.Cert)
+
+		_, err = Verify(files["manifest.json"], files["signature"], otherRoots, now)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadCertificate(t *testing.T) {
+	// An RSA certificate issued by an intermediate, as Apple's are
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test WWDR"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	certDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Pass Type ID: " + testIssuer.PassTypeID},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	write := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return path
+	}
+	certFile := write("pass.pem", "CERTIFICATE", certDER)
+	keyFile := write("pass.key", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	caFile := write("wwdr.pem", "CERTIFICATE", caDER)
+
+	cert, err := LoadCertificate(certFile, keyFile, caFile)
+	require.NoError(t, err)
+	require.NotNil(t, cert.Intermediate)
+
+	manifest := []byte(`{"pass.json":"0000"}`)
+	signature, err := cert.Sign(manifest, time.Now())
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	_, err = Verify(manifest, signature, roots, time.Now())
+	assert.NoError(t, err, "the intermediate travels in the signature")
+
+	_, err = LoadCertificate(certFile, filepath.Join(dir, "missing.key"), "")
+	assert.Error(t, err)
+}
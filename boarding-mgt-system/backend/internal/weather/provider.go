@@ -0,0 +1,136 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/config"
+	"github.com/google/uuid"
+)
+
+// NewProvider returns the provider configured: "file", "http", or nil for
+// "none" when forecasts are not checked
+func NewProvider(cfg *config.WeatherConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "", "none":
+		return nil, nil
+	case "file":
+		if cfg.File == "" {
+			return nil, fmt.Errorf("WEATHER_FILE is required for the file weather provider")
+		}
+		return NewFileProvider(cfg.File), nil
+	case "http":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("WEATHER_URL is required for the http weather provider")
+		}
+		return NewHTTPProvider(cfg.URL), nil
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q", cfg.Provider)
+	}
+}
+
+// FileForecasts is the forecast file format: forecasts by port code and by
+// route ID
+type FileForecasts struct {
+	Ports  map[string][]Forecast `json:"ports"`
+	Routes map[string][]Forecast `json:"routes"`
+}
+
+// FileProvider reads forecasts from a JSON file, for local use. The file is
+// read on every call so it can be edited while the API runs.
+type FileProvider struct {
+	path string
+}
+
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+func (p *FileProvider) PortForecast(ctx context.Context, portCode string, from, until time.Time) ([]Forecast, error) {
+	forecasts, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	return overlapping(forecasts.Ports[portCode], from, until), nil
+}
+
+func (p *FileProvider) RouteForecast(ctx context.Context, routeID uuid.UUID, from, until time.Time) ([]Forecast, error) {
+	forecasts, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	return overlapping(forecasts.Routes[routeID.String()], from, until), nil
+}
+
+func (p *FileProvider) load() (*FileForecasts, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read weather file: %w", err)
+	}
+
+	forecasts := &FileForecasts{}
+	if err := json.Unmarshal(data, forecasts); err != nil {
+		return nil, fmt.Errorf("invalid weather file: %w", err)
+	}
+	return forecasts, nil
+}
+
+// HTTPProvider gets forecasts from a forecast service. It asks for
+// GET {base}/ports/{code}/forecast or GET {base}/routes/{id}/forecast with
+// from and until query parameters in RFC 3339, and expects
+// {"forecasts": [...]} back in the forecast file's format.
+type HTTPProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPProvider(baseURL string) *HTTPProvider {
+	return &HTTPProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *HTTPProvider) PortForecast(ctx context.Context, portCode string, from, until time.Time) ([]Forecast, error) {
+	return p.get(ctx, "/ports/"+url.PathEscape(portCode)+"/forecast", from, until)
+}
+
+func (p *HTTPProvider) RouteForecast(ctx context.Context, routeID uuid.UUID, from, until time.Time) ([]Forecast, error) {
+	return p.get(ctx, "/routes/"+routeID.String()+"/forecast", from, until)
+}
+
+func (p *HTTPProvider) get(ctx context.Context, path string, from, until time.Time) ([]Forecast, error) {
+	query := url.Values{}
+	query.Set("from", from.UTC().Format(time.RFC3339))
+	query.Set("until", until.UTC().Format(time.RFC3339))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build forecast request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("forecast service returned %s for %s", resp.Status, path)
+	}
+
+	var body struct {
+		Forecasts []Forecast `json:"forecasts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid forecast response: %w", err)
+	}
+	return overlapping(body.Forecasts, from, until), nil
+}
@@ -0,0 +1,135 @@
+// Package weather checks sailings against the wind, wave and visibility
+// forecasts for their ports and route and the operating limits of the
+// vessels sailing them.
+//
+// Forecasts come from a Provider: a JSON file for local use, or a forecast
+// service over HTTP.
+package weather
+
+import (
+	"context"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+)
+
+// Measures a vessel's limits cover
+const (
+	MeasureWind       = "wind"
+	MeasureWaveHeight = "wave_height"
+	MeasureVisibility = "visibility"
+)
+
+// Locations a sailing's forecast is checked at
+const (
+	LocationDeparturePort = "departure_port"
+	LocationArrivalPort   = "arrival_port"
+	LocationRoute         = "route"
+)
+
+// DefaultHorizon is how far ahead forecasts are checked by default
+const DefaultHorizon = 72 * time.Hour
+
+// PortWindow is how long before and after a sailing leaves or arrives the
+// weather at the port is checked
+const PortWindow = time.Hour
+
+// Forecast is the weather expected at a port or along a route over a period.
+// A measure the forecast does not cover is left out.
+type Forecast struct {
+	From         time.Time `json:"from"`
+	Until        time.Time `json:"until"`
+	WindKnots    *float64  `json:"wind_knots,omitempty"`
+	WaveHeightM  *float64  `json:"wave_height_m,omitempty"`
+	VisibilityNM *float64  `json:"visibility_nm,omitempty"`
+}
+
+// Provider supplies the forecasts for ports, by port code, and for routes
+// over a period
+type Provider interface {
+	PortForecast(ctx context.Context, portCode string, from, until time.Time) ([]Forecast, error)
+	RouteForecast(ctx context.Context, routeID uuid.UUID, from, until time.Time) ([]Forecast, error)
+}
+
+// Conditions are the forecasts for a sailing's ports and route
+type Conditions struct {
+	DeparturePort []Forecast
+	ArrivalPort   []Forecast
+	Route         []Forecast
+}
+
+// HasLimits reports whether any limit is set
+func HasLimits(limits models.WeatherLimits) bool {
+	return limits.MaxWindKnots != nil || limits.MaxWaveHeightM != nil || limits.MinVisibilityNM != nil
+}
+
+// CheckSailing checks the forecasts for a sailing leaving and arriving at
+// the given times against its vessel's limits. The ports are checked
+// PortWindow either side of leaving and arriving, the route for the
+// crossing.
+func CheckSailing(departureAt, arrivalAt time.Time, conditions Conditions, limits models.WeatherLimits) []models.WeatherExceedance {
+	exceedances := []models.WeatherExceedance{}
+	exceedances = append(exceedances, Check(LocationDeparturePort, conditions.DeparturePort,
+		departureAt.Add(-PortWindow), departureAt.Add(PortWindow), limits)...)
+	exceedances = append(exceedances, Check(LocationArrivalPort, conditions.ArrivalPort,
+		arrivalAt.Add(-PortWindow), arrivalAt.Add(PortWindow), limits)...)
+	exceedances = append(exceedances, Check(LocationRoute, conditions.Route,
+		departureAt, arrivalAt, limits)...)
+	return exceedances
+}
+
+// Check returns, for each limit, the worst of the forecasts overlapping
+// from..until that is beyond it
+func Check(location string, forecasts []Forecast, from, until time.Time, limits models.WeatherLimits) []models.WeatherExceedance {
+	var wind, wave, visibility *models.WeatherExceedance
+
+	for _, f := range forecasts {
+		if !f.From.Before(until) || !f.Until.After(from) {
+			continue
+		}
+
+		if limits.MaxWindKnots != nil && f.WindKnots != nil && *f.WindKnots > *limits.MaxWindKnots &&
+			(wind == nil || *f.WindKnots > wind.Forecast) {
+			wind = exceedance(MeasureWind, location, *f.WindKnots, *limits.MaxWindKnots, f)
+		}
+		if limits.MaxWaveHeightM != nil && f.WaveHeightM != nil && *f.WaveHeightM > *limits.MaxWaveHeightM &&
+			(wave == nil || *f.WaveHeightM > wave.Forecast) {
+			wave = exceedance(MeasureWaveHeight, location, *f.WaveHeightM, *limits.MaxWaveHeightM, f)
+		}
+		if limits.MinVisibilityNM != nil && f.VisibilityNM != nil && *f.VisibilityNM < *limits.MinVisibilityNM &&
+			(visibility == nil || *f.VisibilityNM < visibility.Forecast) {
+			visibility = exceedance(MeasureVisibility, location, *f.VisibilityNM, *limits.MinVisibilityNM, f)
+		}
+	}
+
+	exceedances := []models.WeatherExceedance{}
+	for _, e := range []*models.WeatherExceedance{wind, wave, visibility} {
+		if e != nil {
+			exceedances = append(exceedances, *e)
+		}
+	}
+	return exceedances
+}
+
+func exceedance(measure, location string, forecast, limit float64, f Forecast) *models.WeatherExceedance {
+	return &models.WeatherExceedance{
+		Measure:  measure,
+		Location: location,
+		Forecast: forecast,
+		Limit:    limit,
+		From:     f.From,
+		Until:    f.Until,
+	}
+}
+
+// overlapping returns the forecasts overlapping from..until
+func overlapping(forecasts []Forecast, from, until time.Time) []Forecast {
+	result := []Forecast{}
+	for _, f := range forecasts {
+		if f.From.Before(until) && f.Until.After(from) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
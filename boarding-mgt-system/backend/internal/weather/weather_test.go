@@ -0,0 +1,95 @@
+package weather
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ferryflow/boarding-mgt-system/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func value(v float64) *float64 {
+	return &v
+}
+
+func at(clock string) time.Time {
+	t, err := time.Parse(time.RFC3339, "2025-06-02T"+clock+":00Z")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCheckSailing(t *testing.T) {
+	limits := models.WeatherLimits{
+		MaxWindKnots:    value(30),
+		MaxWaveHeightM:  value(2.5),
+		MinVisibilityNM: value(1),
+	}
+	conditions := Conditions{
+		DeparturePort: []Forecast{
+			{From: at("06:00"), Until: at("09:00"), WindKnots: value(25), VisibilityNM: value(0.5)},
+			{From: at("09:00"), Until: at("12:00"), WindKnots: value(45)},
+		},
+		ArrivalPort: []Forecast{
+			// Two hours after arrival, outside the port window
+			{From: at("11:30"), Until: at("14:00"), WindKnots: value(50)},
+		},
+		Route: []Forecast{
+			{From: at("07:00"), Until: at("08:00"), WaveHeightM: value(3), WindKnots: value(32)},
+			{From: at("08:00"), Until: at("09:00"), WaveHeightM: value(3.5), WindKnots: value(35)},
+		},
+	}
+
+	exceedances := CheckSailing(at("08:00"), at("09:30"), conditions, limits)
+
+	assert.Equal(t, []models.WeatherExceedance{
+		{Measure: MeasureVisibility, Location: LocationDeparturePort, Forecast: 0.5, Limit: 1, From: at("06:00"), Until: at("09:00")},
+		{Measure: MeasureWind, Location: LocationRoute, Forecast: 35, Limit: 30, From: at("08:00"), Until: at("09:00")},
+		{Measure: MeasureWaveHeight, Location: LocationRoute, Forecast: 3.5, Limit: 2.5, From: at("08:00"), Until: at("09:00")},
+	}, exceedances)
+}
+
+func TestCheckWithoutLimits(t *testing.T) {
+	forecasts := []Forecast{{From: at("06:00"), Until: at("09:00"), WindKnots: value(60), VisibilityNM: value(0)}}
+
+	assert.Empty(t, Check(LocationRoute, forecasts, at("07:00"), at("08:00"), models.WeatherLimits{}))
+	assert.False(t, HasLimits(models.WeatherLimits{}))
+	assert.True(t, HasLimits(models.WeatherLimits{MinVisibilityNM: value(1)}))
+}
+
+func TestFileProvider(t *testing.T) {
+	routeID := uuid.New()
+	path := filepath.Join(t.TempDir(), "weather.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"ports": {"DOV": [
+			{"from": "2025-06-02T06:00:00Z", "until": "2025-06-02T09:00:00Z", "wind_knots": 28},
+			{"from": "2025-06-02T18:00:00Z", "until": "2025-06-02T21:00:00Z", "wind_knots": 40}
+		]},
+		"routes": {"`+routeID.String()+`": [
+			{"from": "2025-06-02T06:00:00Z", "until": "2025-06-02T12:00:00Z", "wave_height_m": 1.5}
+		]}
+	}`), 0o600))
+
+	provider := NewFileProvider(path)
+	ctx := context.Background()
+
+	port, err := provider.PortForecast(ctx, "DOV", at("07:00"), at("10:00"))
+	require.NoError(t, err)
+	require.Len(t, port, 1)
+	assert.Equal(t, 28.0, *port[0].WindKnots)
+	assert.Nil(t, port[0].VisibilityNM)
+
+	route, err := provider.RouteForecast(ctx, routeID, at("07:00"), at("10:00"))
+	require.NoError(t, err)
+	assert.Len(t, route, 1)
+
+	unknown, err := provider.PortForecast(ctx, "CAL", at("07:00"), at("10:00"))
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+}